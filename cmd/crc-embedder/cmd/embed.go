@@ -14,6 +14,7 @@ import (
 
 	"github.com/code-ready/crc/pkg/crc/machine/hyperkit"
 	"github.com/code-ready/crc/pkg/crc/machine/libvirt"
+	"github.com/code-ready/crc/pkg/crc/machine/vfkit"
 
 	"github.com/YourFin/binappend"
 	"github.com/spf13/cobra"
@@ -101,6 +102,7 @@ var (
 			{hyperkit.MachineDriverDownloadURL, 0755},
 			{hyperkit.HyperKitDownloadURL, 0755},
 			{hyperkit.QcowToolDownloadURL, 0755},
+			{vfkit.VfkitDownloadURL, 0755},
 			{constants.GetCRCMacTrayDownloadURL(), 0644},
 			{constants.GetAdminHelperURLForOs("darwin"), 0755},
 		},
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addOutputFormatFlag(adminPasswordRotateCmd)
+	adminPasswordCmd.AddCommand(adminPasswordRotateCmd)
+	rootCmd.AddCommand(adminPasswordCmd)
+}
+
+var adminPasswordCmd = &cobra.Command{
+	Use:   "admin-password SUBCOMMAND [flags]",
+	Short: "Manage the password of the kubeadmin user",
+	Long:  "Manage the password of the kubeadmin user",
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var adminPasswordRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Generate a new password for the kubeadmin user",
+	Long: "Generate a new password for the kubeadmin user and update it in the cluster. " +
+		fmt.Sprintf("Use 'crc config set %s true' to do this on every start.", crcConfig.RotateKubeAdminPassword),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAdminPasswordRotate(os.Stdout, newMachine(), config, outputFormat)
+	},
+}
+
+type adminPasswordResult struct {
+	Success bool `json:"success"`
+	errorResult
+	Credentials *credentials `json:"credentials,omitempty"`
+}
+
+func runAdminPasswordRotate(writer io.Writer, client machine.Client, cfg crcConfig.Storage, outputFormat string) error {
+	password, err := rotateAdminPassword(client, cfg)
+	result := &adminPasswordResult{
+		Success:     err == nil,
+		errorResult: newErrorResult(err),
+	}
+	if err == nil {
+		result.Credentials = &credentials{
+			Username: "kubeadmin",
+			Password: password,
+		}
+	}
+	return render(result, writer, outputFormat)
+}
+
+func rotateAdminPassword(client machine.Client, cfg crcConfig.Storage) (string, error) {
+	if !cfg.Get(crcConfig.KubeAdminPassword).IsDefault {
+		return "", fmt.Errorf("The kubeadmin password is set with the %s configuration property, use 'crc config unset %s' to allow its rotation",
+			crcConfig.KubeAdminPassword, crcConfig.KubeAdminPassword)
+	}
+	runner, err := runningOpenShiftSSHRunner(client)
+	if err != nil {
+		return "", err
+	}
+	defer runner.Close()
+
+	return cluster.RotateKubeAdminUserPassword(context.Background(), oc.UseOCWithSSH(runner))
+}
+
+func (s *adminPasswordResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	_, err := fmt.Fprintf(writer, "The new password of the %s user is: %s\n", s.Credentials.Username, s.Credentials.Password)
+	return err
+}
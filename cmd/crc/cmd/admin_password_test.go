@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAdminPasswordConfig() *crcConfig.Config {
+	cfg := crcConfig.New(crcConfig.NewEmptyInMemoryStorage())
+	crcConfig.RegisterSettings(cfg)
+	return cfg
+}
+
+func TestAdminPasswordRotateWithConfiguredPassword(t *testing.T) {
+	cfg := newTestAdminPasswordConfig()
+	_, err := cfg.Set(crcConfig.KubeAdminPassword, "secret")
+	require.NoError(t, err)
+
+	assert.EqualError(t, runAdminPasswordRotate(new(bytes.Buffer), fakemachine.NewClient(), cfg, ""),
+		"The kubeadmin password is set with the kubeadmin-password configuration property, use 'crc config unset kubeadmin-password' to allow its rotation")
+}
+
+func TestAdminPasswordResultPrettyPrint(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, render(&adminPasswordResult{
+		Success:     true,
+		Credentials: &credentials{Username: "kubeadmin", Password: "abcde-fghij-klmno-pqrst"},
+	}, out, ""))
+	assert.Equal(t, "The new password of the kubeadmin user is: abcde-fghij-klmno-pqrst\n", out.String())
+}
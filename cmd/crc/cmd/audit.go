@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/audit"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/spf13/cobra"
+)
+
+// auditedCommands are the commands changing the state of the instance, of
+// the cluster or of the configuration. Their first argument is recorded as
+// the details of the entry, it is never a secret.
+var auditedCommands = map[string]audit.Operation{
+	"crc start":                 audit.Start,
+	"crc stop":                  audit.Stop,
+	"crc delete":                audit.Delete,
+	"crc upgrade":               audit.Upgrade,
+	"crc config set":            audit.ConfigSet,
+	"crc config unset":          audit.ConfigUnset,
+	"crc config import":         audit.ConfigImport,
+	"crc config use-preset":     audit.ConfigUsePreset,
+	"crc users add":             audit.UserAdd,
+	"crc users remove":          audit.UserRemove,
+	"crc users set-password":    audit.UserSetPassword,
+	"crc users grant-admin":     audit.UserGrantAdmin,
+	"crc users revoke-admin":    audit.UserRevokeAdmin,
+	"crc admin-password rotate": audit.AdminPasswordRotate,
+	"crc ssh rotate-key":        audit.SSHKeyRotate,
+	"crc self-update":           audit.SelfUpdate,
+}
+
+type auditOptions struct {
+	operation string
+	since     time.Duration
+	limit     int
+}
+
+var auditFlags auditOptions
+
+func init() {
+	addOutputFormatFlag(auditCmd)
+	auditCmd.Flags().StringVar(&auditFlags.operation, "operation", "", "Only show the entries of this operation, like start or config-set")
+	auditCmd.Flags().DurationVar(&auditFlags.since, "since", 0, "Only show the entries of this duration, like 24h")
+	auditCmd.Flags().IntVar(&auditFlags.limit, "limit", 0, "Only show this number of entries, the most recent ones")
+	rootCmd.AddCommand(auditCmd)
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Show the operations which changed the instance or the configuration",
+	Long: fmt.Sprintf(`Show the operations which changed the instance or the configuration
+
+The start, stop and deletion of the instance, the changes of the
+configuration and of the users of the cluster are recorded with their time,
+the user who ran them and their result in %s, by the crc
+commands and by the daemon. Only the names of the configuration properties
+are recorded, not their values.`, constants.AuditLogPath),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runAudit(os.Stdout, audit.NewLog(constants.AuditLogPath), auditFlags, time.Now(), outputFormat)
+	},
+}
+
+// recordAudit records the run of fullCmd when it changes the state
+func recordAudit(log *audit.Log, fullCmd string, args []string, err error) {
+	operation, ok := auditedCommands[fullCmd]
+	if !ok {
+		return
+	}
+	details := ""
+	if len(args) > 0 {
+		details = args[0]
+	}
+	log.Record(audit.CLI, operation, details, err)
+}
+
+type auditResult struct {
+	Success bool `json:"success"`
+	errorResult
+	Entries []audit.Entry `json:"entries"`
+}
+
+func runAudit(writer io.Writer, log *audit.Log, options auditOptions, now time.Time, outputFormat string) error {
+	entries, err := log.List()
+	if err == nil {
+		entries = filterAuditEntries(entries, options, now)
+	}
+	return render(&auditResult{
+		Success:     err == nil,
+		errorResult: newErrorResult(err),
+		Entries:     entries,
+	}, writer, outputFormat)
+}
+
+func filterAuditEntries(entries []audit.Entry, options auditOptions, now time.Time) []audit.Entry {
+	filtered := []audit.Entry{}
+	for _, entry := range entries {
+		if options.operation != "" && string(entry.Operation) != options.operation {
+			continue
+		}
+		if options.since > 0 && entry.Time.Before(now.Add(-options.since)) {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+	if options.limit > 0 && len(filtered) > options.limit {
+		filtered = filtered[len(filtered)-options.limit:]
+	}
+	return filtered
+}
+
+func (s *auditResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	if len(s.Entries) == 0 {
+		_, err := fmt.Fprintln(writer, "No operation was recorded")
+		return err
+	}
+	w := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "TIME\tUSER\tSOURCE\tOPERATION\tDETAILS\tRESULT"); err != nil {
+		return err
+	}
+	for _, entry := range s.Entries {
+		result := "ok"
+		if entry.Error != "" {
+			result = fmt.Sprintf("failed: %s", entry.Error)
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", entry.Time.Local().Format("2006-01-02 15:04:05"),
+			entry.User, entry.Source, entry.Operation, entry.Details, result); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
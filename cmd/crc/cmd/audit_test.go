@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const auditLogContent = `{"time":"2022-03-01T10:00:00Z","user":"alice","source":"cli","operation":"start"}
+{"time":"2022-03-02T10:00:00Z","user":"alice","source":"cli","operation":"config-set","details":"memory"}
+{"time":"2022-03-03T10:00:00Z","user":"alice","source":"daemon","operation":"stop","error":"instance is not running"}
+`
+
+func auditLog(t *testing.T) *audit.Log {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(auditLogContent), 0600))
+	return audit.NewLog(path)
+}
+
+func TestAuditFilters(t *testing.T) {
+	now := time.Date(2022, 3, 3, 12, 0, 0, 0, time.UTC)
+	out := new(bytes.Buffer)
+	assert.NoError(t, runAudit(out, auditLog(t), auditOptions{since: 48 * time.Hour}, now, jsonFormat))
+	assert.Contains(t, out.String(), `"operation": "config-set"`)
+	assert.NotContains(t, out.String(), `"operation": "start"`)
+
+	out.Reset()
+	assert.NoError(t, runAudit(out, auditLog(t), auditOptions{operation: "start"}, now, jsonFormat))
+	assert.Contains(t, out.String(), `"operation": "start"`)
+	assert.NotContains(t, out.String(), `"operation": "stop"`)
+
+	out.Reset()
+	assert.NoError(t, runAudit(out, auditLog(t), auditOptions{limit: 1}, now, jsonFormat))
+	assert.Contains(t, out.String(), `"operation": "stop"`)
+	assert.NotContains(t, out.String(), `"operation": "config-set"`)
+}
+
+func TestAuditPlainOutput(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runAudit(out, auditLog(t), auditOptions{}, time.Now(), ""))
+	assert.Regexp(t, `config-set\s+memory\s+ok`, out.String())
+	assert.Contains(t, out.String(), "failed: instance is not running")
+}
+
+func TestAuditEmpty(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runAudit(out, audit.NewLog(filepath.Join(t.TempDir(), "audit.jsonl")), auditOptions{}, time.Now(), ""))
+	assert.Equal(t, "No operation was recorded\n", out.String())
+}
+
+func TestRecordAudit(t *testing.T) {
+	log := audit.NewLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	recordAudit(log, "crc status", nil, nil)
+	recordAudit(log, "crc config set", []string{"proxy-password", "secret"}, nil)
+	recordAudit(log, "crc stop", nil, errors.New("failed"))
+
+	entries, err := log.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, audit.ConfigSet, entries[0].Operation)
+	assert.Equal(t, "proxy-password", entries[0].Details)
+	assert.Equal(t, audit.CLI, entries[0].Source)
+	assert.Equal(t, audit.Stop, entries[1].Operation)
+	assert.Equal(t, "failed", entries[1].Error)
+}
@@ -15,5 +15,7 @@ func GetBundleCmd(config *config.Config) *cobra.Command {
 		},
 	}
 	bundleCmd.AddCommand(getGenerateCmd(config))
+	bundleCmd.AddCommand(getUpdateCmd(config))
+	bundleCmd.AddCommand(getChunkCmd())
 	return bundleCmd
 }
@@ -0,0 +1,25 @@
+package bundle
+
+import (
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/spf13/cobra"
+)
+
+func getChunkCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "chunk BUNDLE-DIR OUTPUT-DIR",
+		Short: "Split an extracted bundle in chunks for 'crc bundle update'",
+		Long: "Split an extracted bundle in chunks for 'crc bundle update'. The chunks and the index of the bundle are written " +
+			"to OUTPUT-DIR, to be published in the directory of the .crcbundle file. OUTPUT-DIR can be shared by several bundles.",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			indexPath, err := bundle.WriteChunkStore(args[0], args[1])
+			if err != nil {
+				return err
+			}
+			logging.Infof("Chunk index written to %s", indexPath)
+			return nil
+		},
+	}
+}
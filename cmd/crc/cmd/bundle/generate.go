@@ -13,7 +13,8 @@ func getGenerateCmd(config *config.Config) *cobra.Command {
 	generateCmd := &cobra.Command{
 		Use:   "generate",
 		Short: "Generate a custom bundle from the running OpenShift cluster",
-		Long:  "Generate a custom bundle from the running OpenShift cluster",
+		Long: "Generate a custom bundle from the running OpenShift cluster. The pull secret, the worker nodes and the " +
+			"configuration specific to this host are removed from the instance, which is then stopped to copy its disk image.",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runGenerate(config, forceStop)
 		},
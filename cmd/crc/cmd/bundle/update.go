@@ -0,0 +1,59 @@
+package bundle
+
+import (
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/cache"
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+)
+
+func getUpdateCmd(config *crcConfig.Config) *cobra.Command {
+	var clearChunks bool
+	updateCmd := &cobra.Command{
+		Use:   "update [URL]",
+		Short: "Update to a newer bundle by only downloading the changed chunks",
+		Long: "Update to the default bundle of this crc version, or to the bundle of URL, by only downloading the chunks " +
+			"which are not in the bundles already extracted in the cache. The chunks downloaded by an interrupted update " +
+			"are kept until the update succeeds.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if clearChunks {
+				return runClearChunks()
+			}
+			var bundleURL string
+			if len(args) == 1 {
+				bundleURL = args[0]
+			}
+			return runUpdate(config, bundleURL)
+		},
+	}
+	updateCmd.Flags().BoolVar(&clearChunks, "clear-chunks", false, "Remove the chunks kept by an interrupted update")
+	return updateCmd
+}
+
+func runUpdate(config *crcConfig.Config, bundleURL string) error {
+	verify := !config.Get(crcConfig.SkipBundleVerification).AsBool()
+	bundleInfo, err := bundle.UpdateFromChunks(crcConfig.GetPreset(config), bundleURL, verify)
+	if err != nil {
+		return err
+	}
+	logging.Infof("%s is ready to use", bundleInfo.GetBundleName())
+	return nil
+}
+
+func runClearChunks() error {
+	chunks := cache.NewChunkCache()
+	count, size, err := chunks.Size()
+	if err != nil {
+		return err
+	}
+	if err := chunks.Clear(); err != nil {
+		return err
+	}
+	fmt.Printf("Removed %d chunks, %s\n", count, units.HumanSize(float64(size)))
+	return nil
+}
@@ -4,43 +4,124 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"text/tabwriter"
 
-	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine"
 	"github.com/code-ready/crc/pkg/crc/preflight"
+	"github.com/docker/go-units"
 	"github.com/spf13/cobra"
 )
 
 func init() {
 	addOutputFormatFlag(cleanupCmd)
+	cleanupCmd.Flags().BoolVar(&compactDisk, "compact-disk", false, "Shrink the disk image of the stopped instance instead of undoing the config changes")
+	cleanupCmd.Flags().StringSliceVar(&cleanupOnly, "only", nil, fmt.Sprintf("Only undo the changes of these areas: [%s]", strings.Join(preflight.CleanupAreas(), ", ")))
+	cleanupCmd.Flags().BoolVar(&cleanupDryRun, "dry-run", false, "Print the changes which would be undone, without undoing them")
+	_ = cleanupCmd.RegisterFlagCompletionFunc("only", completeValues(preflight.CleanupAreas()...))
 	rootCmd.AddCommand(cleanupCmd)
 }
 
+var (
+	compactDisk   bool
+	cleanupOnly   []string
+	cleanupDryRun bool
+)
+
 var cleanupCmd = &cobra.Command{
 	Use:   "cleanup",
 	Short: "Undo config changes",
-	Long:  "Undo all the configuration changes done by 'crc setup' command",
+	Long: "Undo the configuration changes done by 'crc setup' command, they are recorded in " + constants.SetupManifestPath +
+		". The changes of the releases which did not record them are all undone.",
+	Example: `  crc cleanup --dry-run
+  crc cleanup --only dns,vsock`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runCleanup()
+		if compactDisk {
+			return runCompactDisk(newMachine(), os.Stdout, outputFormat)
+		}
+		return runCleanup(os.Stdout, cleanupOnly, cleanupDryRun, outputFormat)
 	},
 }
 
-func runCleanup() error {
-	err := preflight.CleanUpHost()
+func runCleanup(writer io.Writer, only []string, dryRun bool, outputFormat string) error {
+	areas, err := preflight.ParseCleanupAreas(only)
+	if err != nil {
+		return err
+	}
+	var actions []preflight.CleanupAction
+	if dryRun {
+		actions = preflight.PlanCleanUpHost(areas)
+	} else {
+		actions, err = preflight.CleanUpHost(areas)
+	}
 	return render(&cleanupResult{
-		Success: err == nil,
-		Error:   crcErrors.ToSerializableError(err),
-	}, os.Stdout, outputFormat)
+		Success:     err == nil,
+		errorResult: newErrorResult(err),
+		DryRun:      dryRun,
+		Actions:     actions,
+	}, writer, outputFormat)
+}
+
+func runCompactDisk(client machine.Client, writer io.Writer, outputFormat string) error {
+	if err := checkIfMachineMissing(client); err != nil {
+		return err
+	}
+	result, err := client.CompactDisk()
+	compactResult := &compactDiskResult{
+		Success:     err == nil,
+		errorResult: newErrorResult(err),
+	}
+	if result != nil {
+		compactResult.SizeBefore = result.SizeBefore
+		compactResult.SizeAfter = result.SizeAfter
+	}
+	return render(compactResult, writer, outputFormat)
 }
 
 type cleanupResult struct {
-	Success bool                         `json:"success"`
-	Error   *crcErrors.SerializableError `json:"error,omitempty"`
+	Success bool `json:"success"`
+	errorResult
+	DryRun  bool                      `json:"dryRun,omitempty"`
+	Actions []preflight.CleanupAction `json:"actions,omitempty"`
 }
 
 func (s *cleanupResult) prettyPrintTo(writer io.Writer) error {
 	if s.Error != nil {
 		return s.Error
 	}
-	_, err := fmt.Fprintln(writer, "Cleanup finished")
+	if !s.DryRun {
+		_, err := fmt.Fprintln(writer, "Cleanup finished")
+		return err
+	}
+	if len(s.Actions) == 0 {
+		_, err := fmt.Fprintln(writer, "There is nothing to undo")
+		return err
+	}
+	w := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "AREA\tACTION"); err != nil {
+		return err
+	}
+	for _, action := range s.Actions {
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", action.Area, action.Description); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+type compactDiskResult struct {
+	Success bool `json:"success"`
+	errorResult
+	SizeBefore int64 `json:"sizeBefore,omitempty"`
+	SizeAfter  int64 `json:"sizeAfter,omitempty"`
+}
+
+func (s *compactDiskResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	_, err := fmt.Fprintf(writer, "Disk image compacted from %s to %s\n",
+		units.HumanSize(float64(s.SizeBefore)), units.HumanSize(float64(s.SizeAfter)))
 	return err
 }
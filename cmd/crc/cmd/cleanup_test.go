@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompactDiskPlainSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runCompactDisk(fakemachine.NewClient(), out, ""))
+	assert.Equal(t, "Disk image compacted from 35GB to 20GB\n", out.String())
+}
+
+func TestCompactDiskPlainError(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runCompactDisk(fakemachine.NewFailingClient(), out, ""), "disk compaction failed")
+}
+
+func TestCompactDiskJSONSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runCompactDisk(fakemachine.NewClient(), out, jsonFormat))
+	assert.JSONEq(t, `{"success": true, "sizeBefore": 35000000000, "sizeAfter": 20000000000}`, out.String())
+}
+
+func TestCompactDiskJSONError(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runCompactDisk(fakemachine.NewFailingClient(), out, jsonFormat), "disk compaction failed")
+	assert.JSONEq(t, `{"success": false, "error": "disk compaction failed", "exitCode": 1}`, out.String())
+}
+
+func TestCleanupUnknownArea(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runCleanup(out, []string{"dns", "printer"}, true, ""),
+		"Unknown cleanup area 'printer', valid areas are: dns, network, vsock, daemon, vm, shell, pull-secret, logs, apparmor, firewall, shared-dirs")
+	assert.Empty(t, out.String())
+}
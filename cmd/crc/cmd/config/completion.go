@@ -0,0 +1,62 @@
+package config
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/config"
+	"github.com/spf13/cobra"
+)
+
+// completeKeys completes the first argument with the configuration keys,
+// restricted to the keys which are set when onlySet is true
+func completeKeys(cfg config.Storage, onlySet bool) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) != 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return configKeys(cfg, onlySet, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func configKeys(cfg config.Storage, onlySet bool, prefix string) []string {
+	var keys []string
+	for key, value := range cfg.AllConfigs() {
+		if onlySet && value.IsDefault {
+			continue
+		}
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return less(keys[i], keys[j])
+	})
+	return keys
+}
+
+// completeKeyAndValue completes the key, then the value of 'crc config set'
+func completeKeyAndValue(cfg *config.Config) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		switch len(args) {
+		case 0:
+			return configKeys(cfg, false, toComplete), cobra.ShellCompDirectiveNoFileComp
+		case 1:
+			return completeValue(cfg, args[0])
+		default:
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+}
+
+func completeValue(cfg *config.Config, key string) ([]string, cobra.ShellCompDirective) {
+	switch key {
+	case config.Bundle:
+		return []string{"crcbundle"}, cobra.ShellCompDirectiveFilterFileExt
+	case config.PullSecretFile, config.ProxyCAFile, config.CAFile:
+		return nil, cobra.ShellCompDirectiveDefault
+	case config.SharedDirs:
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	}
+	return cfg.SuggestedValues(key), cobra.ShellCompDirectiveNoFileComp
+}
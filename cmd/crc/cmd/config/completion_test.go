@@ -0,0 +1,41 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompleteKeys(t *testing.T) {
+	cfg := newTestConfig()
+	_, err := cfg.Set(config.HTTPProxy, "http://proxy:3128")
+	require.NoError(t, err)
+
+	keys, directive := completeKeys(cfg, false)(nil, nil, "")
+	assert.Equal(t, []string{"cpus", "http-proxy", "skip-check-foo"}, keys)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	keys, _ = completeKeys(cfg, false)(nil, nil, "h")
+	assert.Equal(t, []string{"http-proxy"}, keys)
+
+	keys, _ = completeKeys(cfg, true)(nil, nil, "")
+	assert.Equal(t, []string{"http-proxy"}, keys)
+
+	keys, _ = completeKeys(cfg, false)(nil, []string{"cpus"}, "")
+	assert.Empty(t, keys)
+}
+
+func TestCompleteKeyAndValue(t *testing.T) {
+	cfg := newTestConfig()
+
+	values, directive := completeKeyAndValue(cfg)(nil, []string{"skip-check-foo"}, "")
+	assert.Equal(t, []string{"true", "false"}, values)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	values, directive = completeKeyAndValue(cfg)(nil, []string{config.Bundle}, "")
+	assert.Equal(t, []string{"crcbundle"}, values)
+	assert.Equal(t, cobra.ShellCompDirectiveFilterFileExt, directive)
+}
@@ -12,16 +12,19 @@ import (
 )
 
 func isPreflightKey(key string) bool {
-	return strings.HasPrefix(key, "skip-")
+	return strings.HasPrefix(key, "skip-") || strings.HasPrefix(key, "warn-")
 }
 
 // less is used to sort the config keys. We want to sort first the regular keys, and
-// then the keys related to preflight starting with a skip- prefix.
+// then the keys related to preflight starting with a skip- or warn- prefix.
 func less(lhsKey, rhsKey string) bool {
 	if isPreflightKey(lhsKey) {
 		if isPreflightKey(rhsKey) {
-			// ignore skip prefix
-			return lhsKey[4:] < rhsKey[4:]
+			// ignore skip/warn prefix, both have the same length
+			if lhsKey[4:] != rhsKey[4:] {
+				return lhsKey[4:] < rhsKey[4:]
+			}
+			return lhsKey < rhsKey
 		}
 		// lhs is preflight, rhs is not preflight
 		return false
@@ -57,6 +60,8 @@ func GetConfigCmd(config *config.Config) *cobra.Command {
 		Use:   "config SUBCOMMAND [flags]",
 		Short: "Modify crc configuration",
 		Long: `Modifies crc configuration properties.
+Each property can also be set with a CRC_<KEY> environment variable, for example CRC_HTTP_PROXY for http-proxy.
+Command line flags take precedence over environment variables, which take precedence over the configuration file.
 Properties: ` + "\n\n" + configurableFields(config),
 		Run: func(cmd *cobra.Command, args []string) {
 			_ = cmd.Help()
@@ -66,5 +71,11 @@ Properties: ` + "\n\n" + configurableFields(config),
 	configCmd.AddCommand(configSetCmd(config))
 	configCmd.AddCommand(configUnsetCmd(config))
 	configCmd.AddCommand(configViewCmd(config))
+	configCmd.AddCommand(configExportCmd(config))
+	configCmd.AddCommand(configImportCmd(config))
+	configCmd.AddCommand(configUsePresetCmd(config))
+	configCmd.AddCommand(configDiffPresetCmd(config))
+	configCmd.AddCommand(configDocsCmd(config))
+	configCmd.AddCommand(configGetPreflightsCmd())
 	return configCmd
 }
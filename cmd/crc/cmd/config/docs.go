@@ -0,0 +1,79 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/config"
+	"github.com/spf13/cobra"
+)
+
+const (
+	markdownFormat = "markdown"
+	jsonFormat     = "json"
+)
+
+func configDocsCmd(config *config.Config) *cobra.Command {
+	var format string
+	configDocsCmd := &cobra.Command{
+		Use:   "docs",
+		Short: "Generate the reference documentation of the configuration properties",
+		Long: "Generate the reference documentation of the configuration properties, including their type, " +
+			"default value, and when a change is applied",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigDocs(os.Stdout, config, format)
+		},
+	}
+	configDocsCmd.Flags().StringVar(&format, "format", markdownFormat, fmt.Sprintf("Output format. One of: %s, %s", markdownFormat, jsonFormat))
+	return configDocsCmd
+}
+
+func runConfigDocs(writer io.Writer, cfg *config.Config, format string) error {
+	docs := cfg.Docs()
+	sort.Slice(docs, func(i, j int) bool {
+		return less(docs[i].Name, docs[j].Name)
+	})
+	switch format {
+	case jsonFormat:
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(docs)
+	case markdownFormat:
+		return writeMarkdownDocs(writer, docs)
+	default:
+		return fmt.Errorf("Invalid format '%s', must be one of: %s, %s", format, markdownFormat, jsonFormat)
+	}
+}
+
+func writeMarkdownDocs(writer io.Writer, docs []config.SettingDoc) error {
+	if _, err := fmt.Fprint(writer, "| Property | Type | Default | Applied | Since | Description |\n"+
+		"|----------|------|---------|---------|-------|-------------|\n"); err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		since := doc.Since
+		if since == "" {
+			since = "-"
+		}
+		if _, err := fmt.Fprintf(writer, "| `%s` | %s | %s | %s | %s | %s |\n",
+			doc.Name, doc.Type, markdownDefault(doc.Default), doc.AppliedWhen, since, escapeMarkdownCell(doc.Description)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func markdownDefault(value interface{}) string {
+	if value == "" {
+		return "-"
+	}
+	return fmt.Sprintf("`%v`", value)
+}
+
+func escapeMarkdownCell(value string) string {
+	return strings.ReplaceAll(value, "|", `\|`)
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConfig() *config.Config {
+	cfg := config.New(config.NewEmptyInMemoryStorage())
+	cfg.AddSetting(config.CPUs, 4, config.ValidateBool, config.RequiresRestartMsg, "Number of CPU cores")
+	cfg.AddSetting(config.HTTPProxy, "", config.ValidateString, config.SuccessfullyApplied, "HTTP proxy URL | scheme://host:port")
+	cfg.AddSetting("skip-check-foo", false, config.ValidateBool, config.SuccessfullyApplied, "Skip foo check")
+	return cfg
+}
+
+func TestConfigDocsMarkdown(t *testing.T) {
+	out := new(bytes.Buffer)
+	require.NoError(t, runConfigDocs(out, newTestConfig(), markdownFormat))
+	assert.Equal(t, "| Property | Type | Default | Applied | Since | Description |\n"+
+		"|----------|------|---------|---------|-------|-------------|\n"+
+		"| `cpus` | integer | `4` | on the next start | - | Number of CPU cores |\n"+
+		"| `http-proxy` | string | - | immediately | - | HTTP proxy URL \\| scheme://host:port |\n"+
+		"| `skip-check-foo` | boolean | `false` | immediately | - | Skip foo check |\n", out.String())
+}
+
+func TestConfigDocsJSON(t *testing.T) {
+	out := new(bytes.Buffer)
+	require.NoError(t, runConfigDocs(out, newTestConfig(), jsonFormat))
+	var docs []config.SettingDoc
+	require.NoError(t, json.Unmarshal(out.Bytes(), &docs))
+	assert.Len(t, docs, 3)
+	assert.Equal(t, config.SettingDoc{
+		Name:        "cpus",
+		Type:        "integer",
+		Default:     float64(4),
+		Description: "Number of CPU cores",
+		AppliedWhen: "on the next start",
+	}, docs[0])
+}
+
+func TestConfigDocsInvalidFormat(t *testing.T) {
+	assert.EqualError(t, runConfigDocs(new(bytes.Buffer), newTestConfig(), "yaml"), "Invalid format 'yaml', must be one of: markdown, json")
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"io"
+	"os"
+
+	"github.com/code-ready/crc/pkg/crc/config"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// secretSettings are left out of the exported configuration unless
+// --include-secrets is used, the exported file is meant to be shared
+var secretSettings = map[string]bool{
+	config.KubeAdminPassword: true,
+}
+
+var includeSecrets bool
+
+func configExportCmd(config config.Storage) *cobra.Command {
+	configExportCmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the crc configuration as YAML",
+		Long: `Exports all assigned crc configuration properties as YAML on the standard output.
+The result can be applied on another host with 'crc config import' or used with --config-file.`,
+		Example: "crc config export > crc.yaml",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runConfigExport(config.AllConfigs(), includeSecrets, os.Stdout)
+		},
+	}
+	configExportCmd.Flags().BoolVar(&includeSecrets, "include-secrets", false, "Also export the passwords")
+	return configExportCmd
+}
+
+func runConfigExport(cfg map[string]config.SettingValue, includeSecrets bool, writer io.Writer) error {
+	exported := make(map[string]interface{})
+	for key, value := range cfg {
+		if value.IsDefault || (secretSettings[key] && !includeSecrets) {
+			continue
+		}
+		exported[key] = value.Value
+	}
+	bin, err := yaml.Marshal(exported)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(bin)
+	return err
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigExport(t *testing.T) {
+	cfg := map[string]config.SettingValue{
+		"memory":                 {Value: 12288},
+		"http-proxy":             {Value: "http://proxy:3128"},
+		"cpus":                   {Value: 4, IsDefault: true},
+		config.KubeAdminPassword: {Value: "secret"},
+	}
+
+	out := new(bytes.Buffer)
+	require.NoError(t, runConfigExport(cfg, false, out))
+	assert.Equal(t, "http-proxy: http://proxy:3128\nmemory: 12288\n", out.String())
+
+	out.Reset()
+	require.NoError(t, runConfigExport(cfg, true, out))
+	assert.Equal(t, "http-proxy: http://proxy:3128\nkubeadmin-password: secret\nmemory: 12288\n", out.String())
+}
+
+func TestConfigExportImport(t *testing.T) {
+	source := newTestConfig()
+	_, err := source.Set(config.HTTPProxy, "http://proxy:3128")
+	require.NoError(t, err)
+	_, err = source.Set("skip-check-foo", true)
+	require.NoError(t, err)
+
+	exported := new(bytes.Buffer)
+	require.NoError(t, runConfigExport(source.AllConfigs(), false, exported))
+
+	target := newTestConfig()
+	keys, err := runConfigImport(target, exported.Bytes(), false, new(bytes.Buffer))
+	require.NoError(t, err)
+	assert.Equal(t, []string{config.HTTPProxy, "skip-check-foo"}, keys)
+	assert.Equal(t, source.AllConfigs(), target.AllConfigs())
+}
@@ -3,18 +3,31 @@ package config
 import (
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"sort"
 
 	"github.com/code-ready/crc/pkg/crc/config"
 	"github.com/code-ready/crc/pkg/crc/telemetry"
 	"github.com/spf13/cobra"
 )
 
-func configGetCmd(config config.Storage) *cobra.Command {
-	return &cobra.Command{
+var showOrigin bool
+
+func configGetCmd(config *config.Config) *cobra.Command {
+	configGetCmd := &cobra.Command{
 		Use:   "get CONFIG-KEY",
 		Short: "Get a crc configuration property",
-		Long:  `Gets a crc configuration property.`,
+		Long: `Gets a crc configuration property.
+With --show-origin, the effective value is shown together with where it comes from. When no property
+is given, all the properties are shown. A command line flag overrides the CRC_<KEY> environment variable,
+which overrides the configuration file, which overrides the default value.`,
+		Example:           "crc config get --show-origin memory",
+		ValidArgsFunction: completeKeys(config, false),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if showOrigin {
+				return runConfigGetOrigin(config, args, os.Stdout)
+			}
 			if len(args) < 1 {
 				return errors.New("Please provide a configuration property to get")
 			}
@@ -28,11 +41,40 @@ func configGetCmd(config config.Storage) *cobra.Command {
 			telemetry.SetConfigurationKey(cmd.Context(), args[0])
 
 			if v.IsDefault {
-				return fmt.Errorf("Configuration property '%s' is not set. Default value is '%s'", key, v.AsString())
+				return fmt.Errorf("Configuration property '%s' is not set. Default value is '%s'", key, v.String())
 
 			}
-			fmt.Println(key, ":", v.AsString())
+			fmt.Println(key, ":", v.String())
 			return nil
 		},
 	}
+	configGetCmd.Flags().BoolVar(&showOrigin, "show-origin", false, "Show where the effective value comes from")
+	return configGetCmd
+}
+
+func runConfigGetOrigin(cfg *config.Config, keys []string, writer io.Writer) error {
+	if len(keys) == 0 {
+		for _, setting := range cfg.AllSettings() {
+			keys = append(keys, setting.Name)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return less(keys[i], keys[j])
+		})
+	}
+	for _, key := range keys {
+		origin, source, err := cfg.Origin(key)
+		if err != nil {
+			return err
+		}
+		v := cfg.Get(key)
+		if v.Invalid {
+			return fmt.Errorf("Invalid value for configuration property '%s' from the %s %s", key, origin, source)
+		}
+		if source != "" {
+			fmt.Fprintf(writer, "%s : %s (%s %s)\n", key, v.String(), origin, source)
+		} else {
+			fmt.Fprintf(writer, "%s : %s (%s)\n", key, v.String(), origin)
+		}
+	}
+	return nil
 }
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigGetOrigin(t *testing.T) {
+	cfg := newTestConfig()
+	_, err := cfg.Set(config.HTTPProxy, "http://proxy:3128")
+	require.NoError(t, err)
+
+	out := new(bytes.Buffer)
+	require.NoError(t, runConfigGetOrigin(cfg, nil, out))
+	assert.Equal(t, "cpus : 4 (default)\n"+
+		"http-proxy : http://proxy:3128 (configuration file)\n"+
+		"skip-check-foo : false (default)\n", out.String())
+
+	out.Reset()
+	require.NoError(t, runConfigGetOrigin(cfg, []string{config.HTTPProxy}, out))
+	assert.Equal(t, "http-proxy : http://proxy:3128 (configuration file)\n", out.String())
+
+	assert.EqualError(t, runConfigGetOrigin(cfg, []string{"foo"}, out), "Configuration property 'foo' does not exist")
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/telemetry"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+var importReplace bool
+
+func configImportCmd(config *config.Config) *cobra.Command {
+	configImportCmd := &cobra.Command{
+		Use:   "import FILE",
+		Short: "Import crc configuration properties from a file",
+		Long: `Imports crc configuration properties from a YAML or JSON file, as written by 'crc config export'.
+All the properties are validated before any of them is set.`,
+		Example: "crc config import crc.yaml",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("Please provide the file to import as in 'crc config import FILE'")
+			}
+			in, err := ioutil.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+			keys, err := runConfigImport(config, in, importReplace, os.Stdout)
+			for _, key := range keys {
+				telemetry.SetConfigurationKey(cmd.Context(), key)
+			}
+			return err
+		},
+	}
+	configImportCmd.Flags().BoolVar(&importReplace, "replace", false, "Unset the assigned properties which are not in the file")
+	return configImportCmd
+}
+
+// runConfigImport sets the properties of the YAML or JSON document in, and
+// returns the keys which were changed
+func runConfigImport(cfg *config.Config, in []byte, replace bool, writer io.Writer) ([]string, error) {
+	var imported map[string]interface{}
+	if err := yaml.Unmarshal(in, &imported); err != nil {
+		return nil, fmt.Errorf("Invalid configuration file: %w", err)
+	}
+
+	keys := make([]string, 0, len(imported))
+	for key := range imported {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return less(keys[i], keys[j])
+	})
+
+	var errs []string
+	for _, key := range keys {
+		if err := cfg.Validate(key, imported[key]); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("Configuration file not imported:\n%s", strings.Join(errs, "\n"))
+	}
+
+	var unset []string
+	if replace {
+		for key, value := range cfg.AllConfigs() {
+			if _, ok := imported[key]; !ok && !value.IsDefault {
+				unset = append(unset, key)
+			}
+		}
+		sort.Strings(unset)
+	}
+
+	var changed []string
+	messages := make(map[string]bool)
+	printMessage := func(message string) {
+		if message != "" && !messages[message] {
+			messages[message] = true
+			fmt.Fprintln(writer, message)
+		}
+	}
+	for _, key := range unset {
+		message, err := cfg.Unset(key)
+		if err != nil {
+			return changed, err
+		}
+		changed = append(changed, key)
+		printMessage(message)
+	}
+	for _, key := range keys {
+		message, err := cfg.Set(key, imported[key])
+		if err != nil {
+			return changed, err
+		}
+		changed = append(changed, key)
+		printMessage(message)
+	}
+	return changed, nil
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigImport(t *testing.T) {
+	cfg := newTestConfig()
+	out := new(bytes.Buffer)
+	keys, err := runConfigImport(cfg, []byte("skip-check-foo: true\nhttp-proxy: http://proxy:3128\n"), false, out)
+	require.NoError(t, err)
+	assert.Equal(t, []string{config.HTTPProxy, "skip-check-foo"}, keys)
+	assert.Equal(t, "http://proxy:3128", cfg.Get(config.HTTPProxy).AsString())
+	assert.True(t, cfg.Get("skip-check-foo").AsBool())
+	assert.Equal(t, "Successfully configured http-proxy to http://proxy:3128\nSuccessfully configured skip-check-foo to true\n", out.String())
+}
+
+func TestConfigImportJSON(t *testing.T) {
+	cfg := newTestConfig()
+	_, err := runConfigImport(cfg, []byte(`{"http-proxy": "http://proxy:3128"}`), false, new(bytes.Buffer))
+	require.NoError(t, err)
+	assert.Equal(t, "http://proxy:3128", cfg.Get(config.HTTPProxy).AsString())
+}
+
+func TestConfigImportInvalid(t *testing.T) {
+	cfg := newTestConfig()
+	_, err := runConfigImport(cfg, []byte("http-proxy: http://proxy:3128\nfoo: bar\nskip-check-foo: maybe\n"), false, new(bytes.Buffer))
+	assert.EqualError(t, err, "Configuration file not imported:\n"+
+		"Configuration property 'foo' does not exist\n"+
+		"Value 'maybe' for configuration property 'skip-check-foo' is invalid, reason: must be true or false")
+	// nothing is set when a property is invalid
+	assert.True(t, cfg.Get(config.HTTPProxy).IsDefault)
+}
+
+func TestConfigImportReplace(t *testing.T) {
+	cfg := newTestConfig()
+	_, err := cfg.Set("skip-check-foo", true)
+	require.NoError(t, err)
+
+	keys, err := runConfigImport(cfg, []byte("http-proxy: http://proxy:3128\n"), true, new(bytes.Buffer))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"skip-check-foo", config.HTTPProxy}, keys)
+	assert.True(t, cfg.Get("skip-check-foo").IsDefault)
+	assert.Equal(t, "http://proxy:3128", cfg.Get(config.HTTPProxy).AsString())
+}
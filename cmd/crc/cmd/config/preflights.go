@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/code-ready/crc/pkg/crc/preflight"
+	"github.com/spf13/cobra"
+)
+
+func configGetPreflightsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get-preflights",
+		Short: "List the preflight checks",
+		Long: `Lists the IDs and descriptions of the preflight checks of this platform.
+A check can be skipped with 'crc config set skip-<ID> true', or its failure
+can be turned into a warning with 'crc config set warn-<ID> true'.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return printPreflights(os.Stdout, preflight.ListChecks())
+		},
+	}
+}
+
+func printPreflights(writer io.Writer, checks []preflight.CheckInfo) error {
+	w := tabwriter.NewWriter(writer, 0, 8, 1, ' ', 0)
+	if _, err := fmt.Fprintln(w, "ID\tDESCRIPTION"); err != nil {
+		return err
+	}
+	for _, check := range checks {
+		if _, err := fmt.Fprintf(w, "%s\t%s\n", check.ID, check.Description); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/preflight"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintPreflights(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, printPreflights(out, []preflight.CheckInfo{
+		{
+			ID:          "check-ram",
+			Description: "Checking minimum RAM requirements",
+		},
+		{
+			ID:          "check-libvirt-running",
+			Description: "Checking if libvirt daemon is running",
+		},
+	}))
+	assert.Equal(t, `ID                    DESCRIPTION
+check-ram             Checking minimum RAM requirements
+check-libvirt-running Checking if libvirt daemon is running
+`, out.String())
+}
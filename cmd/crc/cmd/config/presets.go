@@ -0,0 +1,115 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/telemetry"
+	"github.com/spf13/cobra"
+)
+
+func settingsPresetsHelp() string {
+	var buf strings.Builder
+	writer := tabwriter.NewWriter(&buf, 0, 8, 1, ' ', tabwriter.TabIndent)
+	for _, p := range config.SettingsPresets() {
+		fmt.Fprintf(writer, "* %s\t%s\n", p.Name, p.Description)
+	}
+	writer.Flush()
+	return buf.String()
+}
+
+func completeSettingsPresets(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) != 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return config.SettingsPresetNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+func configUsePresetCmd(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "use-preset NAME",
+		Short: "Apply a set of crc configuration properties",
+		Long: `Applies the properties of a settings preset at once. When one of them
+cannot be set, the ones already changed are reverted.
+Use 'crc config diff-preset NAME' to review the changes first.
+Presets: ` + "\n\n" + settingsPresetsHelp(),
+		Example:           "crc config use-preset low-memory",
+		ValidArgsFunction: completeSettingsPresets,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("Please provide the name of the preset as in 'crc config use-preset NAME'")
+			}
+			keys, err := runConfigUsePreset(cfg, args[0], os.Stdout)
+			for _, key := range keys {
+				telemetry.SetConfigurationKey(cmd.Context(), key)
+			}
+			return err
+		},
+	}
+}
+
+func configDiffPresetCmd(cfg config.Storage) *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff-preset NAME",
+		Short: "Show how the crc configuration differs from a settings preset",
+		Long: `Shows the properties whose values differ from the ones of a settings preset.
+Presets: ` + "\n\n" + settingsPresetsHelp(),
+		Example:           "crc config diff-preset demo",
+		ValidArgsFunction: completeSettingsPresets,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return errors.New("Please provide the name of the preset as in 'crc config diff-preset NAME'")
+			}
+			return runConfigDiffPreset(cfg, args[0], os.Stdout)
+		},
+	}
+}
+
+// runConfigUsePreset applies the settings preset name, and returns the keys
+// which were changed
+func runConfigUsePreset(cfg config.Storage, name string, writer io.Writer) ([]string, error) {
+	p, err := config.GetSettingsPreset(name)
+	if err != nil {
+		return nil, err
+	}
+	keys, messages, err := config.ApplySettingsPreset(cfg, p)
+	if err != nil {
+		return nil, err
+	}
+	for _, message := range messages {
+		fmt.Fprintln(writer, message)
+	}
+	fmt.Fprintf(writer, "Settings preset '%s' applied\n", p.Name)
+	return keys, nil
+}
+
+func runConfigDiffPreset(cfg config.Storage, name string, writer io.Writer) error {
+	p, err := config.GetSettingsPreset(name)
+	if err != nil {
+		return err
+	}
+	diff := config.DiffSettingsPreset(cfg, p)
+	if len(diff) == 0 {
+		_, err := fmt.Fprintf(writer, "The configuration matches the settings preset '%s'\n", p.Name)
+		return err
+	}
+	w := tabwriter.NewWriter(writer, 0, 8, 1, ' ', 0)
+	if _, err := fmt.Fprintln(w, "PROPERTY\tCURRENT\tPRESET"); err != nil {
+		return err
+	}
+	for _, d := range diff {
+		presetValue := fmt.Sprint(d.Preset)
+		if d.UsesDefault {
+			presetValue = "default"
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%v\t%s\n", d.Key, d.Current, presetValue); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
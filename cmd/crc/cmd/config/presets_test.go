@@ -0,0 +1,56 @@
+package config
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPresetTestConfig() *config.Config {
+	cfg := config.New(config.NewEmptyInMemoryStorage())
+	cfg.AddSetting(config.ConsentTelemetry, "", config.ValidateYesNo, config.SuccessfullyApplied, "")
+	cfg.AddSetting(config.DisableUpdateCheck, false, config.ValidateBool, config.SuccessfullyApplied, "")
+	cfg.AddSetting(config.EnableClusterMonitoring, false, config.ValidateBool, config.RequiresRestartMsg, "")
+	cfg.AddSetting(config.CPUs, 4, config.ValidateString, config.RequiresRestartMsg, "")
+	cfg.AddSetting(config.Memory, 9216, config.ValidateString, config.RequiresRestartMsg, "")
+	cfg.AddSetting(config.AutoStopAfter, "", config.ValidateString, config.SuccessfullyApplied, "")
+	return cfg
+}
+
+func TestConfigDiffPreset(t *testing.T) {
+	cfg := newPresetTestConfig()
+	_, err := cfg.Set(config.AutoStopAfter, "2h")
+	require.NoError(t, err)
+
+	out := new(bytes.Buffer)
+	require.NoError(t, runConfigDiffPreset(cfg, "ci", out))
+	assert.Equal(t, `PROPERTY             CURRENT PRESET
+consent-telemetry            no
+disable-update-check false   true
+cpus                 4       auto
+memory               9216    auto
+auto-stop-after      2h      default
+`, out.String())
+}
+
+func TestConfigUsePreset(t *testing.T) {
+	cfg := newPresetTestConfig()
+	out := new(bytes.Buffer)
+	keys, err := runConfigUsePreset(cfg, "ci", out)
+	require.NoError(t, err)
+	assert.Equal(t, []string{config.ConsentTelemetry, config.DisableUpdateCheck, config.EnableClusterMonitoring, config.CPUs, config.Memory}, keys)
+	assert.Equal(t, "auto", cfg.Get(config.Memory).AsString())
+	assert.Contains(t, out.String(), "Settings preset 'ci' applied\n")
+
+	out.Reset()
+	require.NoError(t, runConfigDiffPreset(cfg, "ci", out))
+	assert.Equal(t, "The configuration matches the settings preset 'ci'\n", out.String())
+}
+
+func TestConfigUnknownPreset(t *testing.T) {
+	_, err := runConfigUsePreset(newPresetTestConfig(), "fast", new(bytes.Buffer))
+	assert.EqualError(t, err, "Unknown settings preset 'fast', valid presets are: low-memory, ci, demo")
+}
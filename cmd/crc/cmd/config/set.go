@@ -15,6 +15,7 @@ func configSetCmd(config *config.Config) *cobra.Command {
 		Short: "Set a crc configuration property",
 		Long: `Sets a crc configuration property.
 CONFIG-KEYS: ` + "\n\n" + configurableFields(config),
+		ValidArgsFunction: completeKeyAndValue(config),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) < 2 {
 				return errors.New("Please provide a configuration property and its value as in 'crc config set KEY VALUE'")
@@ -11,9 +11,10 @@ import (
 
 func configUnsetCmd(config config.Storage) *cobra.Command {
 	return &cobra.Command{
-		Use:   "unset CONFIG-KEY",
-		Short: "Unset a crc configuration property",
-		Long:  `Unsets a crc configuration property.`,
+		Use:               "unset CONFIG-KEY",
+		Short:             "Unset a crc configuration property",
+		Long:              `Unsets a crc configuration property.`,
+		ValidArgsFunction: completeKeys(config, true),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) != 1 {
 				return errors.New("Please provide a configuration property to unset")
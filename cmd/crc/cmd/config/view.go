@@ -55,7 +55,7 @@ func runConfigView(cfg map[string]config.SettingValue, tmpl *template.Template,
 		if v.IsDefault {
 			continue
 		}
-		viewTmplt := configViewTemplate{k, v.AsString()}
+		viewTmplt := configViewTemplate{k, v.String()}
 		var buffer bytes.Buffer
 		if err := tmpl.Execute(&buffer, viewTmplt); err != nil {
 			return err
@@ -6,7 +6,7 @@ import (
 	"io"
 	"os"
 
-	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine"
 	"github.com/code-ready/crc/pkg/crc/machine/state"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
@@ -22,7 +22,7 @@ var (
 func init() {
 	addOutputFormatFlag(consoleCmd)
 	consoleCmd.Flags().BoolVar(&consolePrintURL, "url", false, "Print the URL for the OpenShift Web Console")
-	consoleCmd.Flags().BoolVar(&consolePrintCredentials, "credentials", false, "Print the credentials for the OpenShift Web Console")
+	consoleCmd.Flags().BoolVar(&consolePrintCredentials, "credentials", false, "Print the credentials and a kubeadmin bearer token for the OpenShift Web Console")
 	rootCmd.AddCommand(consoleCmd)
 }
 
@@ -49,21 +49,30 @@ func showConsole(client machine.Client) (*types.ConsoleResult, error) {
 
 func runConsole(writer io.Writer, client machine.Client, consolePrintURL, consolePrintCredentials bool, outputFormat string) error {
 	result, err := showConsole(client)
+	clusterConfig := toConsoleClusterConfig(result)
+	if err == nil && consolePrintCredentials && result.State == state.Running {
+		token, err := client.GetKubeAdminToken()
+		if err != nil {
+			logging.Warnf("Cannot get a bearer token for kubeadmin: %v", err)
+		} else {
+			clusterConfig.AdminCredentials.Token = token
+		}
+	}
 	return render(&consoleResult{
 		Success:                 err == nil,
 		state:                   toState(result),
-		ClusterConfig:           toConsoleClusterConfig(result),
-		Error:                   crcErrors.ToSerializableError(err),
+		ClusterConfig:           clusterConfig,
+		errorResult:             newErrorResult(err),
 		consolePrintURL:         consolePrintURL,
 		consolePrintCredentials: consolePrintCredentials,
 	}, writer, outputFormat)
 }
 
 type consoleResult struct {
-	Success                 bool `json:"success"`
-	state                   state.State
-	Error                   *crcErrors.SerializableError `json:"error,omitempty"`
-	ClusterConfig           *clusterConfig               `json:"clusterConfig,omitempty"`
+	Success bool `json:"success"`
+	state   state.State
+	errorResult
+	ClusterConfig           *clusterConfig `json:"clusterConfig,omitempty"`
 	consolePrintURL         bool
 	consolePrintCredentials bool
 }
@@ -87,6 +96,12 @@ func (s *consoleResult) prettyPrintTo(writer io.Writer) error {
 			s.ClusterConfig.AdminCredentials.Username, s.ClusterConfig.AdminCredentials.Password, s.ClusterConfig.URL); err != nil {
 			return err
 		}
+		if s.ClusterConfig.AdminCredentials.Token != "" {
+			if _, err := fmt.Fprintf(writer, "To login as an admin with a token, run 'oc login --token=%s %s'\n",
+				s.ClusterConfig.AdminCredentials.Token, s.ClusterConfig.URL); err != nil {
+				return err
+			}
+		}
 	}
 	if s.consolePrintURL || s.consolePrintCredentials {
 		return nil
@@ -23,7 +23,9 @@ func TestConsolePlainError(t *testing.T) {
 func TestConsoleWithPrintCredentialsPlainSuccess(t *testing.T) {
 	expectedOut := fmt.Sprintf(`To login as a regular user, run 'oc login -u developer -p developer %s'.
 To login as an admin, run 'oc login -u kubeadmin -p %s %s'
-`, fakemachine.DummyClusterConfig.ClusterAPI, fakemachine.DummyClusterConfig.KubeAdminPass, fakemachine.DummyClusterConfig.ClusterAPI)
+To login as an admin with a token, run 'oc login --token=%s %s'
+`, fakemachine.DummyClusterConfig.ClusterAPI, fakemachine.DummyClusterConfig.KubeAdminPass, fakemachine.DummyClusterConfig.ClusterAPI,
+		fakemachine.DummyKubeAdminToken, fakemachine.DummyClusterConfig.ClusterAPI)
 	out := new(bytes.Buffer)
 	assert.NoError(t, runConsole(out, fakemachine.NewClient(), false, true, ""))
 	assert.Equal(t, expectedOut, out.String())
@@ -33,7 +35,9 @@ func TestConsoleWithPrintCredentialsAndURLPlainSuccess(t *testing.T) {
 	expectedOut := fmt.Sprintf(`%s
 To login as a regular user, run 'oc login -u developer -p developer %s'.
 To login as an admin, run 'oc login -u kubeadmin -p %s %s'
-`, fakemachine.DummyClusterConfig.WebConsoleURL, fakemachine.DummyClusterConfig.ClusterAPI, fakemachine.DummyClusterConfig.KubeAdminPass, fakemachine.DummyClusterConfig.ClusterAPI)
+To login as an admin with a token, run 'oc login --token=%s %s'
+`, fakemachine.DummyClusterConfig.WebConsoleURL, fakemachine.DummyClusterConfig.ClusterAPI, fakemachine.DummyClusterConfig.KubeAdminPass, fakemachine.DummyClusterConfig.ClusterAPI,
+		fakemachine.DummyKubeAdminToken, fakemachine.DummyClusterConfig.ClusterAPI)
 	out := new(bytes.Buffer)
 	assert.NoError(t, runConsole(out, fakemachine.NewClient(), true, true, ""))
 	assert.Equal(t, expectedOut, out.String())
@@ -62,8 +66,33 @@ func TestConsoleJSONSuccess(t *testing.T) {
 	assert.JSONEq(t, expectedJSONOut, out.String())
 }
 
+func TestConsoleWithPrintCredentialsJSONSuccess(t *testing.T) {
+	expectedJSONOut := fmt.Sprintf(`{
+  "success": true,
+  "clusterConfig": {
+    "clusterType": "openshift",
+    "cacert": "%s",
+    "webConsoleUrl": "%s",
+    "url": "%s",
+    "adminCredentials": {
+      "username": "kubeadmin",
+      "password": "%s",
+      "token": "%s"
+    },
+    "developerCredentials": {
+      "username": "developer",
+      "password": "developer"
+    }
+  }
+}`, fakemachine.DummyClusterConfig.ClusterCACert, fakemachine.DummyClusterConfig.WebConsoleURL, fakemachine.DummyClusterConfig.ClusterAPI,
+		fakemachine.DummyClusterConfig.KubeAdminPass, fakemachine.DummyKubeAdminToken)
+	out := new(bytes.Buffer)
+	assert.NoError(t, runConsole(out, fakemachine.NewClient(), false, true, jsonFormat))
+	assert.JSONEq(t, expectedJSONOut, out.String())
+}
+
 func TestConsoleJSONError(t *testing.T) {
 	out := new(bytes.Buffer)
-	assert.NoError(t, runConsole(out, fakemachine.NewFailingClient(), false, false, jsonFormat))
-	assert.JSONEq(t, `{"error":"console failed", "success":false}`, out.String())
+	assert.EqualError(t, runConsole(out, fakemachine.NewFailingClient(), false, false, jsonFormat), "console failed")
+	assert.JSONEq(t, `{"error": "console failed", "exitCode": 1, "success":false}`, out.String())
 }
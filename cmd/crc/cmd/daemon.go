@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -10,20 +11,29 @@ import (
 	"os/signal"
 	"regexp"
 	"runtime"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/code-ready/crc/pkg/crc/adminhelper"
 	"github.com/code-ready/crc/pkg/crc/api"
+	"github.com/code-ready/crc/pkg/crc/autostop"
+	"github.com/code-ready/crc/pkg/crc/cluster"
 	crcConfig "github.com/code-ready/crc/pkg/crc/config"
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/daemonclient"
 	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/power"
 	"github.com/code-ready/crc/pkg/crc/preflight"
+	vmwatchdog "github.com/code-ready/crc/pkg/crc/watchdog"
+	"github.com/code-ready/crc/pkg/crc/watchdog/events"
 	"github.com/containers/gvisor-tap-vsock/pkg/types"
 	"github.com/containers/gvisor-tap-vsock/pkg/virtualnetwork"
 	"github.com/docker/go-units"
 	"github.com/gorilla/handlers"
+	"github.com/miekg/dns"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -37,7 +47,7 @@ func init() {
 	rootCmd.AddCommand(daemonCmd)
 }
 
-const hostVirtualIP = "192.168.127.254"
+const hostVirtualIP = constants.VSockHostVirtualIP
 
 func checkDaemonVersion() (bool, error) {
 	if _, err := daemonclient.New().APIClient.Version(); err == nil {
@@ -47,10 +57,9 @@ func checkDaemonVersion() (bool, error) {
 }
 
 var daemonCmd = &cobra.Command{
-	Use:    "daemon",
-	Short:  "Run the crc daemon",
-	Long:   "Run the crc daemon",
-	Hidden: true,
+	Use:   "daemon",
+	Short: "Run the crc daemon",
+	Long:  "Run the crc daemon in the foreground, use 'crc daemon install' to start it automatically instead",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if running, _ := checkIfDaemonIsRunning(); running {
 			return errors.New("daemon is already running")
@@ -109,7 +118,13 @@ var daemonCmd = &cobra.Command{
 			},
 			Protocol: types.HyperKitProtocol,
 		}
-		if config.Get(crcConfig.HostNetworkAccess).AsBool() {
+		if clusterDomain := config.Get(crcConfig.ClusterDomain).AsString(); clusterDomain != "" {
+			// the first matching zone is used, they must be before crc.testing
+			virtualNetworkConfig.DNS = append(clusterDomainZones(clusterDomain, "192.168.127.2"), virtualNetworkConfig.DNS...)
+		}
+		// the shared directories are mounted from the SMB server of the host on Windows
+		sharesDirs := runtime.GOOS == "windows" && len(crcConfig.GetSharedDirs(config)) != 0
+		if config.Get(crcConfig.HostNetworkAccess).AsBool() || sharesDirs {
 			log.Debugf("Enabling host network access")
 			if virtualNetworkConfig.NAT == nil {
 				virtualNetworkConfig.NAT = make(map[string]string)
@@ -122,6 +137,26 @@ var daemonCmd = &cobra.Command{
 	},
 }
 
+// clusterDomainZones resolves the names of the custom cluster domain to ip,
+// like the crc.testing zones do for the domain of the bundle
+func clusterDomainZones(clusterDomain string, ip string) []types.Zone {
+	return []types.Zone{
+		{
+			Name:      fmt.Sprintf("apps.%s.", clusterDomain),
+			DefaultIP: net.ParseIP(ip),
+		},
+		{
+			Name: fmt.Sprintf("%s.", clusterDomain),
+			Records: []types.Record{
+				{
+					Name: "api",
+					IP:   net.ParseIP(ip),
+				},
+			},
+		},
+	}
+}
+
 func mtu() int {
 	if runtime.GOOS == "darwin" {
 		return 1500
@@ -147,18 +182,54 @@ func run(configuration *types.Configuration) error {
 		return err
 	}
 
+	machineClient := newMachine()
+	autoStop := autostop.NewMonitor(machineClient, config)
+	go autoStop.Run()
+	go renewCertificates(machineClient)
+	go syncClock(machineClient)
+	startInstance := func(ctx context.Context) error {
+		_, err := machineClient.Start(ctx, newStartConfig(cluster.NewNonInteractivePullSecretLoader(config, ""), false))
+		return err
+	}
+	go vmwatchdog.NewMonitor(machineClient, config, events.NewStore(constants.WatchdogEventsPath), startInstance).Run()
+	go func() {
+		if err := power.Watch(context.Background(), power.NewHandler(machineClient, config, startInstance).Handle); err != nil {
+			logging.Warnf("Cannot watch the sleep of the host: %v", err)
+		}
+	}()
+
+	restartCh := make(chan string, 1)
+	mux := http.NewServeMux()
+	mux.Handle("/network/", http.StripPrefix("/network", vn.Mux()))
+	apiMux, metricsMux := api.NewMux(config, machineClient, logging.Memory, segmentClient, func(executable string) error {
+		return requestRestart(restartCh, executable)
+	})
+	mux.Handle("/api/", http.StripPrefix("/api", autoStop.Wrap(apiMux)))
+	apiServer := &http.Server{Handler: handlers.LoggingHandler(os.Stderr, mux), ConnContext: api.ConnContext}
 	go func() {
 		if listener == nil {
 			return
 		}
-		mux := http.NewServeMux()
-		mux.Handle("/network/", http.StripPrefix("/network", vn.Mux()))
-		mux.Handle("/api/", http.StripPrefix("/api", api.NewMux(config, newMachine(), logging.Memory, segmentClient)))
-		if err := http.Serve(listener, handlers.LoggingHandler(os.Stderr, mux)); err != nil {
+		if err := apiServer.Serve(listener); err != nil && err != http.ErrServerClosed {
 			errCh <- errors.Wrap(err, "api http.Serve failed")
 		}
 	}()
 
+	// the metrics are only served on the API socket, unless a TCP port is
+	// configured for a monitoring system which cannot use it
+	if port := crcConfig.GetMetricsPort(config); port != 0 {
+		metricsListener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+		if err != nil {
+			logging.Warnf("Cannot serve the metrics on port %d: %v", port, err)
+		} else {
+			go func() {
+				if err := http.Serve(metricsListener, metricsMux); err != nil {
+					errCh <- errors.Wrap(err, "metrics http.Serve failed")
+				}
+			}()
+		}
+	}
+
 	ln, err := vn.Listen("tcp", fmt.Sprintf("%s:80", configuration.GatewayIP))
 	if err != nil {
 		return err
@@ -181,8 +252,56 @@ func run(configuration *types.Configuration) error {
 		}
 	}()
 
+	dnsHandler := &network.QueryLogger{
+		Server: "forwarder",
+		Handler: &network.DNSForwarder{
+			Zones:     configuration.DNS,
+			Upstreams: configuredNameServers,
+			Transport: network.HTTPTransport(),
+		},
+		Enabled: dnsQueryLogging,
+	}
+	dnsListener, err := vn.Listen("tcp", fmt.Sprintf("%s:53", hostVirtualIP))
+	if err != nil {
+		return err
+	}
+	go func() {
+		server := &dns.Server{Listener: dnsListener, Handler: dnsHandler}
+		if err := server.ActivateAndServe(); err != nil {
+			errCh <- errors.Wrap(err, "dns forwarder failed")
+		}
+	}()
+	dnsPacketConn, err := network.ListenUDP(vn, fmt.Sprintf("%s:53", hostVirtualIP))
+	if err != nil {
+		return err
+	}
+	go func() {
+		server := &dns.Server{PacketConn: dnsPacketConn, Handler: dnsHandler}
+		if err := server.ActivateAndServe(); err != nil {
+			errCh <- errors.Wrap(err, "dns forwarder failed")
+		}
+	}()
+
+	// OpenShift does not support SOCKS5 proxies and the proxies which need
+	// Kerberos or NTLM, the cluster sends its requests to this relay
+	if proxyConfig, err := network.NewProxyConfig(); err == nil && proxyConfig.NeedsRelay() {
+		relayListener, err := vn.Listen("tcp", fmt.Sprintf("%s:%d", hostVirtualIP, constants.ProxyRelayPort))
+		if err != nil {
+			return err
+		}
+		go func() {
+			if err := http.Serve(relayListener, network.NewProxyRelay(proxyConfig)); err != nil {
+				errCh <- errors.Wrap(err, "proxy relay failed")
+			}
+		}()
+	}
+
+	runHostDNSResolver(hostZones(config.Get(crcConfig.ClusterDomain).AsString()))
+
 	go func() {
-		if runtime.GOOS == "darwin" {
+		// hyperkit connects the network interface of the instance to the
+		// socket, vfkit forwards the vsock connections of the instance
+		if runtime.GOOS == "darwin" && crcConfig.GetVMDriver(config) != crcConfig.VfkitVMDriver {
 			for {
 				conn, err := vsockListener.Accept()
 				if err != nil {
@@ -234,7 +353,78 @@ func run(configuration *types.Configuration) error {
 		return nil
 	case err := <-errCh:
 		return err
+	case executable := <-restartCh:
+		// let the restart request, and the other ones, complete
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = apiServer.Shutdown(ctx)
+		_ = vsockListener.Close()
+		logging.Infof("Restarting the daemon with %s", executable)
+		return execDaemon(executable)
+	}
+}
+
+// requestRestart schedules the restart of the daemon with executable, once
+// the API requests in flight are done
+func requestRestart(restartCh chan<- string, executable string) error {
+	if watchdog {
+		return errors.New("The daemon is managed by the tray application, it cannot restart itself")
+	}
+	if daemonManagedByServiceManager() {
+		return errors.New("The daemon is managed by the service manager, it must be restarted with it")
+	}
+	if info, err := os.Stat(executable); err != nil || info.IsDir() {
+		return fmt.Errorf("Invalid executable %s", executable)
+	}
+	select {
+	case restartCh <- executable:
+		return nil
+	default:
+		return errors.New("The daemon is already restarting")
+	}
+}
+
+const certRenewalInterval = time.Hour
+
+// renewCertificates renews the kubelet certificates of the running cluster
+// ahead of their expiry, crc start only renews the expired ones
+func renewCertificates(client machine.Client) {
+	for {
+		time.Sleep(certRenewalInterval)
+		if err := client.RenewExpiringCertificates(context.Background()); err != nil {
+			logging.Debugf("Cannot renew the certificates: %v", err)
+		}
+	}
+}
+
+const clockSyncInterval = 10 * time.Minute
+
+// syncClock sets the clock of the running instance when it drifted from the
+// clock of the host, in case chronyd of the instance cannot reach any time
+// source
+func syncClock(client machine.Client) {
+	for {
+		time.Sleep(clockSyncInterval)
+		skew, err := client.SyncTime()
+		if err != nil {
+			logging.Debugf("Cannot set the clock of the instance: %v", err)
+		} else if skew != 0 {
+			logging.Infof("Set the clock of the instance, which was off by %s", skew)
+		}
+	}
+}
+
+func dnsQueryLogging() bool {
+	return config.Get(crcConfig.DNSQueryLogging).AsBool()
+}
+
+func configuredNameServers() []network.Upstream {
+	nameServers, err := network.ParseNameServers(config.Get(crcConfig.NameServer).AsString())
+	if err != nil {
+		logging.Warnf("Ignoring invalid %s configuration: %v", crcConfig.NameServer, err)
+		return nil
 	}
+	return nameServers
 }
 
 // This API is only exposed in the virtual network (only the VM can reach this).
@@ -243,7 +433,18 @@ func gatewayAPIMux() *http.ServeMux {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/hosts/add", func(w http.ResponseWriter, r *http.Request) {
 		acceptJSONStringArray(w, r, func(hostnames []string) error {
-			return adminhelper.AddToHostsFile("127.0.0.1", hostnames...)
+			// the names served by the DNS resolver of the host do not
+			// need to be in the hosts file
+			var missing []string
+			for _, hostname := range hostnames {
+				if !network.ResolvesTo(hostname, "127.0.0.1") {
+					missing = append(missing, hostname)
+				}
+			}
+			if len(missing) == 0 {
+				return nil
+			}
+			return adminhelper.AddToHostsFile("127.0.0.1", missing...)
 		})
 	})
 	mux.HandleFunc("/hosts/remove", func(w http.ResponseWriter, r *http.Request) {
@@ -254,6 +455,53 @@ func gatewayAPIMux() *http.ServeMux {
 	return mux
 }
 
+// hostZones resolves the names of the cluster for the host, the ports of the
+// instance are forwarded on the loopback address
+func hostZones(clusterDomain string) []types.Zone {
+	zones := []types.Zone{
+		{
+			Name:      "apps-crc.testing.",
+			DefaultIP: net.ParseIP("127.0.0.1"),
+		},
+		{
+			Name: "crc.testing.",
+			Records: []types.Record{
+				{
+					Name: "api",
+					IP:   net.ParseIP("127.0.0.1"),
+				},
+				{
+					Name: "podman",
+					IP:   net.ParseIP("127.0.0.1"),
+				},
+			},
+		},
+	}
+	if clusterDomain != "" {
+		zones = append(clusterDomainZones(clusterDomain, "127.0.0.1"), zones...)
+	}
+	return zones
+}
+
+// runHostDNSResolver serves the names of the cluster to the host. When it
+// cannot listen, crc start adds the names to the hosts file instead.
+func runHostDNSResolver(zones []types.Zone) {
+	address := net.JoinHostPort("127.0.0.1", strconv.Itoa(constants.HostDNSResolverPort))
+	handler := &network.QueryLogger{
+		Server:  "host resolver",
+		Handler: &network.HostDNSResolver{Zones: zones},
+		Enabled: dnsQueryLogging,
+	}
+	for _, protocol := range []string{"udp", "tcp"} {
+		server := &dns.Server{Addr: address, Net: protocol, Handler: handler}
+		go func(protocol string) {
+			if err := server.ListenAndServe(); err != nil {
+				logging.Warnf("Cannot run the DNS resolver for the host on %s/%s: %v", address, protocol, err)
+			}
+		}(protocol)
+	}
+}
+
 func networkAPIMux(vn *virtualnetwork.VirtualNetwork) *http.ServeMux {
 	mux := http.NewServeMux()
 	mux.Handle("/", vn.Mux())
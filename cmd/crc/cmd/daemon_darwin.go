@@ -33,6 +33,10 @@ func checkIfDaemonIsRunning() (bool, error) {
 	return checkDaemonVersion()
 }
 
+func daemonManagedByServiceManager() bool {
+	return false
+}
+
 func daemonNotRunningMessage() string {
 	if crcversion.IsInstaller() {
 		return "Is '/Applications/CodeReady Containers.app' running? Cannot reach daemon API"
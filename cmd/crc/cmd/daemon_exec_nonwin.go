@@ -0,0 +1,15 @@
+//go:build !windows
+// +build !windows
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+)
+
+// execDaemon replaces the daemon with executable, keeping its pid so that
+// launchd and the shell running it keep track of it
+func execDaemon(executable string) error {
+	return syscall.Exec(executable, append([]string{executable}, os.Args[1:]...), os.Environ())
+}
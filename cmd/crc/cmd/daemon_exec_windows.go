@@ -0,0 +1,14 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+)
+
+// execDaemon starts executable as the new daemon, there is no exec on Windows.
+// The current daemon exits once it is started.
+func execDaemon(executable string) error {
+	// #nosec G204
+	cmd := exec.Command(executable, os.Args[1:]...)
+	return cmd.Start()
+}
@@ -124,6 +124,12 @@ func httpListener() (net.Listener, error) {
 	return ln, nil
 }
 
+// daemonManagedByServiceManager returns true when the sockets were created by
+// systemd, which restarts the daemon
+func daemonManagedByServiceManager() bool {
+	return len(systemdListeners) > 0
+}
+
 func daemonNotRunningMessage() string {
 	return genericDaemonNotRunningMessage
 }
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/api/client"
+	"github.com/code-ready/crc/pkg/crc/daemonclient"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/preflight"
+	crcversion "github.com/code-ready/crc/pkg/crc/version"
+	"github.com/spf13/cobra"
+)
+
+// daemonStartTimeout is how long to wait for the API of a daemon started by
+// the service manager
+const daemonStartTimeout = 30 * time.Second
+
+func init() {
+	addOutputFormatFlag(daemonStatusCmd)
+	daemonCmd.AddCommand(daemonInstallCmd)
+	daemonCmd.AddCommand(daemonUninstallCmd)
+	daemonCmd.AddCommand(daemonStatusCmd)
+}
+
+var daemonInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Start the daemon automatically with the user session",
+	Long: "Register the daemon with the service manager of the host so that it is started automatically: " +
+		"a systemd user service with socket activation on Linux, a launchd agent on macOS, " +
+		"and a scheduled task started at logon on Windows",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := preflight.InstallDaemonService(); err != nil {
+			return err
+		}
+		if _, err := waitForDaemon(daemonclient.New(), ""); err != nil {
+			return fmt.Errorf("The daemon service was installed but its API is not reachable: %w", err)
+		}
+		fmt.Println("The crc daemon is running, it will be started automatically with the user session")
+		return nil
+	},
+}
+
+var daemonUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Stop starting the daemon automatically",
+	Long:  "Stop the daemon and remove its registration from the service manager of the host",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := preflight.UninstallDaemonService(); err != nil {
+			return err
+		}
+		fmt.Println("The crc daemon service was removed")
+		return nil
+	},
+}
+
+var daemonStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show if the daemon is installed and reachable",
+	Long:  "Show how the daemon is registered with the service manager of the host, and if its API is reachable",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		version, err := daemonclient.New().APIClient.Version()
+		if err != nil {
+			logging.Debugf("Cannot reach the daemon API: %v", err)
+		}
+		return render(newDaemonStatusResult(preflight.GetDaemonServiceStatus(), version, err == nil), os.Stdout, outputFormat)
+	},
+}
+
+type daemonStatusResult struct {
+	Success bool `json:"success"`
+	errorResult
+	Manager          string `json:"manager"`
+	Path             string `json:"path"`
+	Installed        bool   `json:"installed"`
+	SocketActivation bool   `json:"socketActivation"`
+	Running          bool   `json:"running"`
+	Reachable        bool   `json:"reachable"`
+	Version          string `json:"version,omitempty"`
+}
+
+func newDaemonStatusResult(status *preflight.DaemonServiceStatus, version client.VersionResult, reachable bool) *daemonStatusResult {
+	result := &daemonStatusResult{
+		Success:          true,
+		Manager:          status.Manager,
+		Path:             status.Path,
+		Installed:        status.Installed,
+		SocketActivation: status.SocketActivation,
+		Running:          status.Running,
+		Reachable:        reachable,
+	}
+	if reachable {
+		result.Version = version.CrcVersion
+	}
+	return result
+}
+
+func (s *daemonStatusResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	w := tabwriter.NewWriter(writer, 0, 0, 1, ' ', 0)
+	installed := fmt.Sprintf("Not installed, run 'crc daemon install' to start it automatically (%s)", s.Manager)
+	if s.Installed {
+		installed = fmt.Sprintf("Installed (%s, %s)", s.Manager, s.Path)
+		if s.SocketActivation {
+			installed += ", started on the first request"
+		}
+	}
+	api := "Not reachable"
+	if s.Reachable {
+		api = fmt.Sprintf("Reachable, version %s", s.Version)
+	}
+	for _, line := range [][2]string{
+		{"Service", installed},
+		{"Running", yesNo(s.Running || s.Reachable)},
+		{"API", api},
+	} {
+		if _, err := fmt.Fprintf(w, "%s:\t%s\n", line[0], line[1]); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "Yes"
+	}
+	return "No"
+}
+
+// startInstalledDaemon starts the daemon through the service manager when it
+// is installed but not reachable, for instance after it was stopped
+func startInstalledDaemon(daemonClient *daemonclient.Client) (client.VersionResult, error) {
+	status := preflight.GetDaemonServiceStatus()
+	if !status.Installed {
+		return client.VersionResult{}, fmt.Errorf("the daemon is not running, and it is not installed as a %s service", status.Manager)
+	}
+	logging.Infof("Starting the crc daemon with %s", status.Manager)
+	if err := preflight.StartDaemonService(); err != nil {
+		return client.VersionResult{}, err
+	}
+	return waitForDaemon(daemonClient, "")
+}
+
+// restartOutdatedDaemon restarts the daemon when its version doesn't match the
+// version of the executable, for instance after crc was upgraded
+func restartOutdatedDaemon(daemonClient *daemonclient.Client) (client.VersionResult, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return client.VersionResult{}, err
+	}
+	return restartDaemon(daemonClient, executable, crcversion.GetCRCVersion())
+}
+
+// restartDaemon restarts the daemon with executable, and waits for the API
+// of expectedVersion
+func restartDaemon(daemonClient *daemonclient.Client, executable, expectedVersion string) (client.VersionResult, error) {
+	if running, _ := newMachine().IsRunning(); running {
+		return client.VersionResult{}, errors.New("the daemon cannot be restarted while the instance is running, stop it with 'crc stop' first")
+	}
+	status := preflight.GetDaemonServiceStatus()
+	if status.Installed && status.Running {
+		logging.Infof("Restarting the crc daemon with %s", status.Manager)
+		if err := preflight.RestartDaemonService(); err != nil {
+			return client.VersionResult{}, err
+		}
+	} else {
+		logging.Info("Restarting the crc daemon")
+		if err := daemonClient.APIClient.Restart(executable); err != nil {
+			return client.VersionResult{}, err
+		}
+	}
+	return waitForDaemon(daemonClient, expectedVersion)
+}
+
+// waitForDaemon waits for the API of the daemon to be reachable, and to be
+// served by expectedVersion when it is not empty
+func waitForDaemon(daemonClient *daemonclient.Client, expectedVersion string) (client.VersionResult, error) {
+	deadline := time.Now().Add(daemonStartTimeout)
+	for {
+		version, err := daemonClient.APIClient.Version()
+		if err == nil && expectedVersion != "" && version.CrcVersion != expectedVersion {
+			err = fmt.Errorf("the daemon version is still %s", version.CrcVersion)
+		}
+		if err == nil || time.Now().After(deadline) {
+			return version, err
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/api/client"
+	"github.com/code-ready/crc/pkg/crc/preflight"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDaemonStatusInstalled(t *testing.T) {
+	status := &preflight.DaemonServiceStatus{
+		Manager:          "systemd",
+		Path:             "/home/user/.config/systemd/user/crc-daemon.service",
+		SocketActivation: true,
+		Installed:        true,
+	}
+	out := new(bytes.Buffer)
+	assert.NoError(t, render(newDaemonStatusResult(status, client.VersionResult{CrcVersion: "2.1.0"}, true), out, ""))
+	assert.Equal(t, `Service: Installed (systemd, /home/user/.config/systemd/user/crc-daemon.service), started on the first request
+Running: Yes
+API:     Reachable, version 2.1.0
+`, out.String())
+}
+
+func TestDaemonStatusNotInstalledJSON(t *testing.T) {
+	status := &preflight.DaemonServiceStatus{
+		Manager: "launchd",
+		Path:    "com.redhat.crc.daemon",
+	}
+	out := new(bytes.Buffer)
+	assert.NoError(t, render(newDaemonStatusResult(status, client.VersionResult{CrcVersion: "2.1.0"}, false), out, jsonFormat))
+	assert.JSONEq(t, `{
+  "success": true,
+  "manager": "launchd",
+  "path": "com.redhat.crc.daemon",
+  "installed": false,
+  "socketActivation": false,
+  "running": false,
+  "reachable": false
+}`, out.String())
+}
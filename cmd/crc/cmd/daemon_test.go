@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestRestart(t *testing.T) {
+	dir := t.TempDir()
+	executable := filepath.Join(dir, "crc")
+	require.NoError(t, os.WriteFile(executable, []byte{}, 0700))
+	restartCh := make(chan string, 1)
+
+	assert.EqualError(t, requestRestart(restartCh, filepath.Join(dir, "missing")), "Invalid executable "+filepath.Join(dir, "missing"))
+	assert.NoError(t, requestRestart(restartCh, executable))
+	assert.EqualError(t, requestRestart(restartCh, executable), "The daemon is already restarting")
+	assert.Equal(t, executable, <-restartCh)
+}
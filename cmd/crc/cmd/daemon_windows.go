@@ -36,6 +36,10 @@ func checkIfDaemonIsRunning() (bool, error) {
 	return checkDaemonVersion()
 }
 
+func daemonManagedByServiceManager() bool {
+	return false
+}
+
 func daemonNotRunningMessage() string {
 	if crcversion.IsInstaller() {
 		return "Is CodeReady Containers tray application running? Cannot reach daemon API"
@@ -5,20 +5,33 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/code-ready/crc/pkg/crc/constants"
-	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/daemonclient"
 	"github.com/code-ready/crc/pkg/crc/input"
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
 	"github.com/spf13/cobra"
 )
 
-var clearCache bool
+var (
+	clearCache      bool
+	deleteScope     string
+	keepBundleCache bool
+	deleteDryRun    bool
+)
 
 func init() {
 	deleteCmd.Flags().BoolVarP(&clearCache, "clear-cache", "", false,
 		fmt.Sprintf("Clear the instance cache at: %s", constants.MachineCacheDir))
+	deleteCmd.Flags().StringVar(&deleteScope, "scope", string(types.DeleteInstance),
+		fmt.Sprintf("What to delete: %s (the virtual machine), %s (also the machine state, port forwards, upgrade backups and start statistics) "+
+			"or %s (also the cached bundles, binaries and images, and the daemon socket)", types.DeleteInstance, types.DeleteState, types.DeleteAll))
+	deleteCmd.Flags().BoolVar(&keepBundleCache, "keep-bundle-cache", false, fmt.Sprintf("Keep the cached bundles with --scope %s", types.DeleteAll))
+	deleteCmd.Flags().BoolVar(&deleteDryRun, "dry-run", false, "List the files and directories which would be deleted, without deleting them")
+	_ = deleteCmd.RegisterFlagCompletionFunc("scope", completeValues(string(types.DeleteInstance), string(types.DeleteState), string(types.DeleteAll)))
 	addOutputFormatFlag(deleteCmd)
 	addForceFlag(deleteCmd)
 	rootCmd.AddCommand(deleteCmd)
@@ -27,52 +40,148 @@ func init() {
 var deleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Delete the instance",
-	Long:  "Delete the instance",
+	Long: `Delete the instance
+
+With --scope, the state kept across instances and the cached files can also
+be deleted. Use --dry-run to list them first.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runDelete(os.Stdout, newMachine(), clearCache, constants.MachineCacheDir, outputFormat != jsonFormat, globalForce, outputFormat)
+		client := newMachine()
+		options, err := newDeleteOptions(client.GetName())
+		if err != nil {
+			return err
+		}
+		return runDelete(os.Stdout, client, options, !isMachineReadable(outputFormat), globalForce, outputFormat)
 	},
 }
 
-func deleteMachine(client machine.Client, clearCache bool, cacheDir string, interactive, force bool) (bool, error) {
-	if clearCache {
+type deleteOptions struct {
+	clearCache bool
+	cacheDir   string
+	scope      types.DeleteScope
+	// paths are the files and directories of the scope
+	paths  []string
+	dryRun bool
+}
+
+func newDeleteOptions(name string) (deleteOptions, error) {
+	deleteConfig := types.DeleteConfig{
+		Scope:           types.DeleteScope(deleteScope),
+		KeepBundleCache: keepBundleCache,
+	}
+	if !deleteConfig.Scope.IsValid() {
+		return deleteOptions{}, fmt.Errorf("Unknown scope '%s', use one of %s, %s or %s", deleteScope, types.DeleteInstance, types.DeleteState, types.DeleteAll)
+	}
+	if keepBundleCache && (deleteConfig.Scope != types.DeleteAll || clearCache) {
+		return deleteOptions{}, fmt.Errorf("--keep-bundle-cache can only be used with --scope %s and without --clear-cache", types.DeleteAll)
+	}
+	paths, err := machine.DeletePaths(name, deleteConfig)
+	if err != nil {
+		return deleteOptions{}, err
+	}
+	// a running daemon keeps listening on the removed socket, and cannot be
+	// reached anymore
+	if _, err := daemonclient.New().APIClient.Version(); err == nil {
+		paths = withoutPath(paths, constants.DaemonSocketPath)
+	}
+	return deleteOptions{
+		clearCache: clearCache,
+		cacheDir:   constants.MachineCacheDir,
+		scope:      deleteConfig.Scope,
+		paths:      paths,
+		dryRun:     deleteDryRun,
+	}, nil
+}
+
+func withoutPath(paths []string, excluded string) []string {
+	var filtered []string
+	for _, path := range paths {
+		if path != excluded {
+			filtered = append(filtered, path)
+		}
+	}
+	return filtered
+}
+
+// deleteMachine returns whether the instance was deleted, and the files and
+// directories of the scope which were deleted, or would be with dryRun
+func deleteMachine(client machine.Client, options deleteOptions, interactive, force bool) (bool, []string, error) {
+	if options.dryRun {
+		paths := options.paths
+		if options.clearCache {
+			paths = append(withoutPath(paths, options.cacheDir), options.cacheDir)
+		}
+		return false, paths, nil
+	}
+
+	if options.clearCache {
 		if !interactive && !force {
-			return false, errors.New("non-interactive deletion requires --force")
+			return false, nil, errors.New("non-interactive deletion requires --force")
 		}
 		yes := input.PromptUserForYesOrNo("Do you want to delete the instance cache", force)
 		if yes {
-			_ = os.RemoveAll(cacheDir)
+			_ = os.RemoveAll(options.cacheDir)
 		}
 	}
 
-	if err := checkIfMachineMissing(client); err != nil {
-		return false, err
+	machineExists := true
+	if options.scope == types.DeleteInstance {
+		if err := checkIfMachineMissing(client); err != nil {
+			return false, nil, err
+		}
+	} else {
+		var err error
+		if machineExists, err = client.Exists(); err != nil {
+			return false, nil, err
+		}
+		if !machineExists && len(options.paths) == 0 {
+			return false, nil, nil
+		}
 	}
 
 	if !interactive && !force {
-		return false, errors.New("non-interactive deletion requires --force")
+		return false, nil, errors.New("non-interactive deletion requires --force")
 	}
 
-	yes := input.PromptUserForYesOrNo("Do you want to delete the instance",
-		force)
-	if yes {
+	question := "Do you want to delete the instance"
+	if options.scope != types.DeleteInstance {
+		question = fmt.Sprintf("Do you want to delete %s", strings.Join(options.paths, ", "))
+		if machineExists {
+			question = fmt.Sprintf("Do you want to delete the instance and %s", strings.Join(options.paths, ", "))
+		}
+	}
+	if !input.PromptUserForYesOrNo(question, force) {
+		return false, nil, nil
+	}
+	if machineExists {
 		defer logging.BackupLogFile()
-		return true, client.Delete()
+		if err := client.Delete(); err != nil {
+			return false, nil, err
+		}
+	}
+	if options.scope == types.DeleteInstance {
+		return true, nil, nil
 	}
-	return false, nil
+	return machineExists, options.paths, machine.RemovePaths(options.paths)
 }
 
-func runDelete(writer io.Writer, client machine.Client, clearCache bool, cacheDir string, interactive, force bool, outputFormat string) error {
-	machineDeleted, err := deleteMachine(client, clearCache, cacheDir, interactive, force)
+func runDelete(writer io.Writer, client machine.Client, options deleteOptions, interactive, force bool, outputFormat string) error {
+	machineDeleted, paths, err := deleteMachine(client, options, interactive, force)
 	return render(&deleteResult{
 		Success:        err == nil,
-		Error:          crcErrors.ToSerializableError(err),
+		errorResult:    newErrorResult(err),
+		DryRun:         options.dryRun,
+		Paths:          paths,
 		machineDeleted: machineDeleted,
 	}, writer, outputFormat)
 }
 
 type deleteResult struct {
-	Success        bool                         `json:"success"`
-	Error          *crcErrors.SerializableError `json:"error,omitempty"`
+	Success bool `json:"success"`
+	errorResult
+	DryRun bool `json:"dryRun,omitempty"`
+	// Paths are the files and directories deleted besides the instance,
+	// or which would be deleted with DryRun
+	Paths          []string `json:"paths,omitempty"`
 	machineDeleted bool
 }
 
@@ -80,10 +189,23 @@ func (s *deleteResult) prettyPrintTo(writer io.Writer) error {
 	if s.Error != nil {
 		return s.Error
 	}
+	if s.DryRun {
+		if len(s.Paths) == 0 {
+			_, err := fmt.Fprintln(writer, "Nothing would be deleted")
+			return err
+		}
+		_, err := fmt.Fprintf(writer, "The following files and directories would be deleted:\n  %s\n", strings.Join(s.Paths, "\n  "))
+		return err
+	}
 	if s.machineDeleted {
 		if _, err := fmt.Fprintln(writer, "Deleted the instance"); err != nil {
 			return err
 		}
 	}
+	if len(s.Paths) > 0 {
+		if _, err := fmt.Fprintf(writer, "Deleted:\n  %s\n", strings.Join(s.Paths, "\n  ")); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -17,7 +19,7 @@ func TestPlainDelete(t *testing.T) {
 	defer os.RemoveAll(cacheDir)
 
 	out := new(bytes.Buffer)
-	assert.NoError(t, runDelete(out, fakemachine.NewClient(), true, cacheDir, true, true, ""))
+	assert.NoError(t, runDelete(out, fakemachine.NewClient(), deleteOptions{clearCache: true, cacheDir: cacheDir, scope: types.DeleteInstance}, true, true, ""))
 	assert.Equal(t, "Deleted the instance\n", out.String())
 
 	_, err = os.Stat(cacheDir)
@@ -30,7 +32,7 @@ func TestNonForceDelete(t *testing.T) {
 	defer os.RemoveAll(cacheDir)
 
 	out := new(bytes.Buffer)
-	assert.NoError(t, runDelete(out, fakemachine.NewClient(), true, cacheDir, true, false, ""))
+	assert.NoError(t, runDelete(out, fakemachine.NewClient(), deleteOptions{clearCache: true, cacheDir: cacheDir, scope: types.DeleteInstance}, true, false, ""))
 	assert.Equal(t, "", out.String())
 
 	_, err = os.Stat(cacheDir)
@@ -43,9 +45,30 @@ func TestJSONDelete(t *testing.T) {
 	defer os.RemoveAll(cacheDir)
 
 	out := new(bytes.Buffer)
-	assert.NoError(t, runDelete(out, fakemachine.NewClient(), true, cacheDir, false, true, jsonFormat))
+	assert.NoError(t, runDelete(out, fakemachine.NewClient(), deleteOptions{clearCache: true, cacheDir: cacheDir, scope: types.DeleteInstance}, false, true, jsonFormat))
 	assert.JSONEq(t, `{"success": true}`, out.String())
 
 	_, err = os.Stat(cacheDir)
 	assert.True(t, os.IsNotExist(err))
 }
+
+func TestDeleteScope(t *testing.T) {
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "port-forwards.json")
+	require.NoError(t, ioutil.WriteFile(statePath, []byte("[]"), 0600))
+	options := deleteOptions{scope: types.DeleteState, paths: []string{statePath}}
+
+	out := new(bytes.Buffer)
+	options.dryRun = true
+	assert.NoError(t, runDelete(out, fakemachine.NewClient(), options, false, true, jsonFormat))
+	assert.JSONEq(t, `{"success": true, "dryRun": true, "paths": ["`+statePath+`"]}`, out.String())
+	_, err := os.Stat(statePath)
+	assert.NoError(t, err)
+
+	out.Reset()
+	options.dryRun = false
+	assert.NoError(t, runDelete(out, fakemachine.NewClient(), options, true, true, ""))
+	assert.Equal(t, "Deleted the instance\nDeleted:\n  "+statePath+"\n", out.String())
+	_, err = os.Stat(statePath)
+	assert.True(t, os.IsNotExist(err))
+}
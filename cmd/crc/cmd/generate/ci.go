@@ -0,0 +1,184 @@
+package generate
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/version"
+	"github.com/spf13/cobra"
+)
+
+const (
+	githubProvider  = "github"
+	gitlabProvider  = "gitlab"
+	jenkinsProvider = "jenkins"
+
+	crcDownloadURLBase = "https://developers.redhat.com/content-gateway/rest/mirror/pub/openshift-v4/clients/crc"
+)
+
+var ciTemplates = map[string]string{
+	githubProvider:  githubActionsTemplate,
+	gitlabProvider:  gitlabCITemplate,
+	jenkinsProvider: jenkinsfileTemplate,
+}
+
+type ciParams struct {
+	DownloadURL string
+	Version     string
+	Preset      string
+	CPUs        int
+	Memory      int
+	DiskSize    int
+	CacheDir    string
+	ConfigCmds  []string
+}
+
+func getCICmd(config *config.Config) *cobra.Command {
+	var provider string
+	ciCmd := &cobra.Command{
+		Use:   "ci",
+		Short: "Generate a CI pipeline snippet which installs and starts CRC",
+		Long: fmt.Sprintf("Generate a CI pipeline snippet which installs crc, caches the bundle, starts the instance "+
+			"headlessly and tears it down, using the current configuration values. Supported providers: %s",
+			strings.Join(supportedProviders(), ", ")),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runCI(os.Stdout, config, provider)
+		},
+	}
+	ciCmd.Flags().StringVar(&provider, "provider", githubProvider, fmt.Sprintf("CI provider (%s)", strings.Join(supportedProviders(), ", ")))
+	return ciCmd
+}
+
+func supportedProviders() []string {
+	return []string{githubProvider, gitlabProvider, jenkinsProvider}
+}
+
+func runCI(writer io.Writer, cfg config.Storage, provider string) error {
+	text, ok := ciTemplates[provider]
+	if !ok {
+		return fmt.Errorf("Unsupported CI provider '%s', must be one of: %s", provider, strings.Join(supportedProviders(), ", "))
+	}
+	tmpl, err := template.New(provider).Parse(text)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(writer, newCIParams(cfg))
+}
+
+func newCIParams(cfg config.Storage) ciParams {
+	params := ciParams{
+		DownloadURL: fmt.Sprintf("%s/%s/crc-linux-amd64.tar.xz", crcDownloadURLBase, version.GetCRCVersion()),
+		Version:     version.GetCRCVersion(),
+		Preset:      string(config.GetPreset(cfg)),
+		CPUs:        config.GetCPUs(cfg),
+		Memory:      config.GetMemory(cfg),
+		DiskSize:    cfg.Get(config.DiskSize).AsInt(),
+		CacheDir:    "~/.crc/cache",
+	}
+	params.ConfigCmds = []string{
+		fmt.Sprintf("crc config set %s %s", config.ConsentTelemetry, "no"),
+		fmt.Sprintf("crc config set %s %s", config.Preset, params.Preset),
+		fmt.Sprintf("crc config set %s %d", config.CPUs, params.CPUs),
+		fmt.Sprintf("crc config set %s %d", config.Memory, params.Memory),
+		fmt.Sprintf("crc config set %s %d", config.DiskSize, params.DiskSize),
+	}
+	return params
+}
+
+const githubActionsTemplate = `name: crc
+on: [push, pull_request]
+jobs:
+  crc:
+    runs-on: ubuntu-latest
+    steps:
+      - name: Install dependencies
+        run: |
+          sudo apt-get update
+          sudo apt-get install -y qemu-kvm libvirt-daemon libvirt-daemon-system network-manager
+          sudo usermod -a -G libvirt $USER
+      - name: Install crc {{ .Version }}
+        run: |
+          curl -sL {{ .DownloadURL }} | sudo tar -xJ --strip-components=1 -C /usr/local/bin --wildcards '*/crc'
+      - name: Cache crc bundle
+        uses: actions/cache@v3
+        with:
+          path: {{ .CacheDir }}
+          key: crc-{{ .Version }}-{{ .Preset }}
+      - name: Configure crc
+        run: |
+{{- range .ConfigCmds }}
+          {{ . }}
+{{- end }}
+      - name: Start crc
+        env:
+          PULL_SECRET: ${{ "{{" }} secrets.CRC_PULL_SECRET {{ "}}" }}
+        run: |
+          echo "$PULL_SECRET" > pull-secret.json
+          sudo -su $USER crc setup
+          sudo -su $USER crc start --pull-secret-file pull-secret.json
+      - name: Tear down crc
+        if: always()
+        run: |
+          crc delete --force
+`
+
+const gitlabCITemplate = `crc:
+  image: fedora:latest
+  tags:
+    - kvm
+  cache:
+    key: crc-{{ .Version }}-{{ .Preset }}
+    paths:
+      - .crc-cache/
+  before_script:
+    - dnf install -y libvirt NetworkManager xz
+    - curl -sL {{ .DownloadURL }} | tar -xJ --strip-components=1 -C /usr/local/bin --wildcards '*/crc'
+    - mkdir -p {{ .CacheDir }} .crc-cache && cp -a .crc-cache/. {{ .CacheDir }}/
+{{- range .ConfigCmds }}
+    - {{ . }}
+{{- end }}
+  script:
+    - echo "$CRC_PULL_SECRET" > pull-secret.json
+    - crc setup
+    - crc start --pull-secret-file pull-secret.json
+  after_script:
+    - cp -a {{ .CacheDir }}/*.crcbundle .crc-cache/ || true
+    - crc delete --force
+`
+
+const jenkinsfileTemplate = `pipeline {
+    agent { label 'kvm' }
+    environment {
+        CRC_PULL_SECRET = credentials('crc-pull-secret')
+    }
+    stages {
+        stage('Install crc {{ .Version }}') {
+            steps {
+                sh "curl -sL {{ .DownloadURL }} | tar -xJ --strip-components=1 -C \${WORKSPACE} --wildcards '*/crc'"
+            }
+        }
+        stage('Configure crc') {
+            steps {
+{{- range .ConfigCmds }}
+                sh "\${WORKSPACE}/{{ . }}"
+{{- end }}
+            }
+        }
+        stage('Start crc') {
+            steps {
+                sh "\${WORKSPACE}/crc setup"
+                sh "\${WORKSPACE}/crc start --pull-secret-file \${CRC_PULL_SECRET}"
+            }
+        }
+    }
+    post {
+        always {
+            sh "\${WORKSPACE}/crc delete --force"
+        }
+    }
+}
+`
@@ -0,0 +1,42 @@
+package generate
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConfig(t *testing.T) *config.Config {
+	cfg := config.New(config.NewEmptyInMemoryStorage())
+	config.RegisterSettings(cfg)
+	_, err := cfg.Set(config.CPUs, 6)
+	require.NoError(t, err)
+	return cfg
+}
+
+func TestCIGitHub(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runCI(out, newTestConfig(t), githubProvider))
+	assert.Contains(t, out.String(), "          crc config set cpus 6\n")
+	assert.Contains(t, out.String(), "          crc config set memory 9216\n")
+	assert.Contains(t, out.String(), "${{ secrets.CRC_PULL_SECRET }}")
+	assert.Contains(t, out.String(), "crc delete --force")
+	// the hosted runners don't run as root
+	assert.Contains(t, out.String(), "| sudo tar -xJ --strip-components=1 -C /usr/local/bin")
+}
+
+func TestCIAllProviders(t *testing.T) {
+	for _, provider := range supportedProviders() {
+		out := new(bytes.Buffer)
+		assert.NoError(t, runCI(out, newTestConfig(t), provider))
+		assert.Contains(t, out.String(), "crc config set preset openshift")
+	}
+}
+
+func TestCIUnknownProvider(t *testing.T) {
+	assert.EqualError(t, runCI(new(bytes.Buffer), newTestConfig(t), "travis"),
+		"Unsupported CI provider 'travis', must be one of: github, gitlab, jenkins")
+}
@@ -0,0 +1,19 @@
+package generate
+
+import (
+	"github.com/code-ready/crc/pkg/crc/config"
+	"github.com/spf13/cobra"
+)
+
+func GetGenerateCmd(config *config.Config) *cobra.Command {
+	generateCmd := &cobra.Command{
+		Use:   "generate SUBCOMMAND [flags]",
+		Short: "Generate configuration snippets for use with CRC",
+		Long:  "Generate configuration snippets for use with CRC",
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+	generateCmd.AddCommand(getCICmd(config))
+	return generateCmd
+}
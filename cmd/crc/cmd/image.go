@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/image"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/preset"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/code-ready/crc/pkg/download"
+	"github.com/spf13/cobra"
+)
+
+var imageRoot bool
+
+func init() {
+	for _, cmd := range []*cobra.Command{imageLoadCmd, imageCopyCmd} {
+		addOutputFormatFlag(cmd)
+		cmd.Flags().BoolVar(&imageRoot, "root", false, "Load the image in the storage of root podman with the podman preset")
+		imageCmd.AddCommand(cmd)
+	}
+	rootCmd.AddCommand(imageCmd)
+}
+
+var imageCmd = &cobra.Command{
+	Use:   "image SUBCOMMAND [flags]",
+	Short: "Load container images in the instance",
+	Long: "Load container images in the container storage of the instance over SSH, " +
+		"without pulling them from the network of the instance",
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var imageLoadCmd = &cobra.Command{
+	Use:   "load FILE",
+	Short: "Load an image archive in the instance",
+	Long: "Load a docker-archive or oci-archive, as written by 'podman save' or 'docker save', in the instance. " +
+		"With the openshift preset the image is loaded in the storage used by the cluster.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runImageLoad(os.Stdout, newMachine(), args[0], imageRoot, outputFormat)
+	},
+}
+
+var imageCopyCmd = &cobra.Command{
+	Use:   fmt.Sprintf("copy %sREGISTRY/REPOSITORY[:TAG]", download.OCIReferencePrefix),
+	Short: "Copy an image from a registry to the instance",
+	Long: "Pull an image for the architecture of the instance on the host, with the credentials of podman and docker " +
+		"on the host, and load it in the instance. With the openshift preset the image is loaded in the storage used by the cluster.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runImageCopy(os.Stdout, newMachine(), args[0], imageRoot, outputFormat)
+	},
+}
+
+type imageResult struct {
+	Success bool `json:"success"`
+	errorResult
+	Images []string `json:"images,omitempty"`
+}
+
+func runImageLoad(writer io.Writer, client machine.Client, path string, root bool, outputFormat string) error {
+	images, err := loadImage(client, root, func(sshRunner *ssh.Runner, rootful bool) ([]string, error) {
+		archive, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer archive.Close()
+		return image.Load(sshRunner, archive, rootful)
+	})
+	return renderImageResult(writer, images, err, outputFormat)
+}
+
+func runImageCopy(writer io.Writer, client machine.Client, imageRef string, root bool, outputFormat string) error {
+	if _, err := download.ParseOCIReference(imageRef); err != nil {
+		return renderImageResult(writer, nil, err, outputFormat)
+	}
+	images, err := loadImage(client, root, func(sshRunner *ssh.Runner, rootful bool) ([]string, error) {
+		return image.Copy(sshRunner, imageRef, rootful)
+	})
+	return renderImageResult(writer, images, err, outputFormat)
+}
+
+// loadImage runs load with the storage of root for the openshift preset,
+// CRI-O only sees the images of this storage
+func loadImage(client machine.Client, root bool, load func(*ssh.Runner, bool) ([]string, error)) ([]string, error) {
+	sshRunner, err := runningSSHRunner(client)
+	if err != nil {
+		return nil, err
+	}
+	defer sshRunner.Close()
+	return load(sshRunner, root || client.GetPreset() == preset.OpenShift)
+}
+
+func renderImageResult(writer io.Writer, images []string, err error, outputFormat string) error {
+	return render(&imageResult{
+		Success:     err == nil,
+		errorResult: newErrorResult(err),
+		Images:      images,
+	}, writer, outputFormat)
+}
+
+func (s *imageResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	if len(s.Images) == 0 {
+		_, err := fmt.Fprintln(writer, "Image loaded")
+		return err
+	}
+	_, err := fmt.Fprintf(writer, "Loaded image(s): %s\n", strings.Join(s.Images, ", "))
+	return err
+}
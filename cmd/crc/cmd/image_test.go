@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImageLoadStopped(t *testing.T) {
+	client := fakemachine.NewClient()
+	client.Stopped = true
+
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runImageLoad(out, client, "image.tar", false, ""), "crc instance is not running")
+
+	out.Reset()
+	assert.EqualError(t, runImageLoad(out, client, "image.tar", false, jsonFormat), "crc instance is not running")
+	assert.JSONEq(t, `{"success": false, "error": "crc instance is not running", "exitCode": 1}`, out.String())
+}
+
+func TestImageCopyInvalidReference(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runImageCopy(out, fakemachine.NewClient(), "quay.io/foo/bar:1.0", false, ""),
+		"'quay.io/foo/bar:1.0' is not a docker:// reference")
+}
+
+func TestImagePlainOutput(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, renderImageResult(out, []string{"quay.io/foo/bar:1.0", "quay.io/foo/baz:2.0"}, nil, ""))
+	assert.Equal(t, "Loaded image(s): quay.io/foo/bar:1.0, quay.io/foo/baz:2.0\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, renderImageResult(out, []string{"quay.io/foo/bar:1.0"}, nil, jsonFormat))
+	assert.JSONEq(t, `{"success": true, "images": ["quay.io/foo/bar:1.0"]}`, out.String())
+}
@@ -2,11 +2,19 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
 
+	"github.com/code-ready/crc/pkg/crc/machine"
 	"github.com/spf13/cobra"
 )
 
+var ipAll bool
+
 func init() {
+	addOutputFormatFlag(ipCmd)
+	ipCmd.Flags().BoolVar(&ipAll, "all", false, "List all the addresses used to connect to the instance and to the host")
 	rootCmd.AddCommand(ipCmd)
 }
 
@@ -15,20 +23,76 @@ var ipCmd = &cobra.Command{
 	Short: "Get IP address of the running OpenShift cluster",
 	Long:  "Get IP address of the running OpenShift cluster",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runIP(args)
+		return runIP(os.Stdout, newMachine(), ipAll, outputFormat)
 	},
 }
 
-func runIP(arguments []string) error {
-	client := newMachine()
+type address struct {
+	Name        string `json:"name"`
+	IP          string `json:"ip"`
+	Description string `json:"description"`
+}
+
+type ipResult struct {
+	Success bool `json:"success"`
+	errorResult
+	IP        string    `json:"ip,omitempty"`
+	Addresses []address `json:"addresses,omitempty"`
+}
+
+func runIP(writer io.Writer, client machine.Client, all bool, outputFormat string) error {
+	result, err := getIP(client, all)
+	if err != nil {
+		result = &ipResult{}
+	}
+	result.Success = err == nil
+	result.errorResult = newErrorResult(err)
+	return render(result, writer, outputFormat)
+}
+
+func getIP(client machine.Client, all bool) (*ipResult, error) {
 	if err := checkIfMachineMissing(client); err != nil {
-		return err
+		return nil, err
+	}
+	if !all {
+		connectionDetails, err := client.ConnectionDetails()
+		if err != nil {
+			return nil, err
+		}
+		return &ipResult{IP: connectionDetails.IP}, nil
 	}
 
-	connectionDetails, err := client.ConnectionDetails()
+	addresses, err := client.Addresses()
 	if err != nil {
+		return nil, err
+	}
+	result := &ipResult{}
+	for _, addr := range addresses {
+		result.Addresses = append(result.Addresses, address{
+			Name:        addr.Name,
+			IP:          addr.IP,
+			Description: addr.Description,
+		})
+	}
+	return result, nil
+}
+
+func (s *ipResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	if len(s.Addresses) == 0 {
+		_, err := fmt.Fprintln(writer, s.IP)
+		return err
+	}
+	w := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "NAME\tIP\tDESCRIPTION"); err != nil {
 		return err
 	}
-	fmt.Println(connectionDetails.IP)
-	return nil
+	for _, addr := range s.Addresses {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\n", addr.Name, addr.IP, addr.Description); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
 }
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlainIPAll(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runIP(out, fakemachine.NewClient(), true, ""))
+	assert.Equal(t, `NAME      IP              DESCRIPTION
+host      127.0.0.1       Address of the instance used from the host and in the hosts file
+instance  192.168.127.2   Address of the instance on the user mode network
+node      192.168.126.11  Internal IP of the OpenShift node
+`, out.String())
+}
+
+func TestJSONIPAll(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runIP(out, fakemachine.NewClient(), true, jsonFormat))
+	assert.JSONEq(t, `{
+  "success": true,
+  "addresses": [
+    {"name": "host", "ip": "127.0.0.1", "description": "Address of the instance used from the host and in the hosts file"},
+    {"name": "instance", "ip": "192.168.127.2", "description": "Address of the instance on the user mode network"},
+    {"name": "node", "ip": "192.168.126.11", "description": "Internal IP of the OpenShift node"}
+  ]
+}`, out.String())
+}
+
+func TestPlainIPAllError(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runIP(out, fakemachine.NewFailingClient(), true, ""), "addresses failed")
+}
@@ -5,32 +5,107 @@ import (
 	"fmt"
 	"io"
 
+	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
 )
 
-const jsonFormat = "json"
+const (
+	jsonFormat = "json"
+	yamlFormat = "yaml"
+)
 
 var (
 	outputFormat string
+	quietOutput  bool
 )
 
 func addOutputFormatFlag(cmd *cobra.Command) {
-	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format. One of: json")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format. One of: json, yaml")
+	_ = cmd.RegisterFlagCompletionFunc("output", completeValues(jsonFormat, yamlFormat))
+	cmd.Flags().BoolVarP(&quietOutput, "quiet", "q", false, "Only print the errors and the result of the command, without the progress")
+}
+
+// isMachineReadable returns true for the output formats meant to be parsed,
+// the commands do not prompt and do not print anything else with them
+func isMachineReadable(outputFormat string) bool {
+	return outputFormat != ""
 }
 
 type prettyPrintable interface {
 	prettyPrintTo(writer io.Writer) error
 }
 
+// failedResult is implemented by the results embedding errorResult
+type failedResult interface {
+	failure() error
+}
+
+// render prints obj in the output format. When obj describes a failure, the
+// error is returned in all the formats so that the exit code is the same.
 func render(obj prettyPrintable, writer io.Writer, outputFormat string) error {
 	switch outputFormat {
 	case jsonFormat:
 		encoder := json.NewEncoder(writer)
 		encoder.SetIndent("", "  ")
-		return encoder.Encode(obj)
+		if err := encoder.Encode(obj); err != nil {
+			return err
+		}
+		return failureOf(obj)
+	case yamlFormat:
+		// the YAML document has the fields of the JSON document
+		out, err := yaml.Marshal(obj)
+		if err != nil {
+			return err
+		}
+		if _, err := writer.Write(out); err != nil {
+			return err
+		}
+		return failureOf(obj)
 	case "":
 		return obj.prettyPrintTo(writer)
 	default:
 		return fmt.Errorf("invalid format: %s", outputFormat)
 	}
 }
+
+// failureOf returns the error described by obj, if any
+func failureOf(obj prettyPrintable) error {
+	if result, ok := obj.(failedResult); ok {
+		return result.failure()
+	}
+	return nil
+}
+
+// errorResult is embedded in the results of the commands, it describes their
+// failure in the JSON output
+type errorResult struct {
+	Error       *crcErrors.SerializableError `json:"error,omitempty"`
+	ErrorCode   string                       `json:"errorCode,omitempty"`
+	Remediation string                       `json:"remediation,omitempty"`
+	DocsURL     string                       `json:"docsURL,omitempty"`
+	ExitCode    int                          `json:"exitCode,omitempty"`
+}
+
+func newErrorResult(err error) errorResult {
+	if err == nil {
+		return errorResult{}
+	}
+	result := errorResult{
+		Error:    crcErrors.ToSerializableError(err),
+		ExitCode: exitCodeOf(err),
+	}
+	if details := result.Error.Details(); details != nil {
+		result.ErrorCode = details.Code
+		result.Remediation = details.Remediation
+		result.DocsURL = details.DocsURL
+	}
+	return result
+}
+
+func (e errorResult) failure() error {
+	if e.Error == nil {
+		return nil
+	}
+	return e.Error
+}
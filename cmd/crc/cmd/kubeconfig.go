@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+type kubeconfigOptions struct {
+	merge   bool
+	unmerge bool
+	flatten bool
+	path    string
+	context string
+	as      string
+}
+
+var kubeconfigFlags kubeconfigOptions
+
+func init() {
+	addOutputFormatFlag(kubeconfigCmd)
+	kubeconfigCmd.Flags().BoolVar(&kubeconfigFlags.merge, "merge", false, "Add the contexts of the cluster to the default kubeconfig, or to the file of --path")
+	kubeconfigCmd.Flags().BoolVar(&kubeconfigFlags.unmerge, "unmerge", false, "Remove the contexts of the cluster from the default kubeconfig, or from the file of --path")
+	kubeconfigCmd.Flags().BoolVar(&kubeconfigFlags.flatten, "flatten", false, "Only keep the current context, its cluster and its user")
+	kubeconfigCmd.Flags().StringVar(&kubeconfigFlags.path, "path", "", "Write the kubeconfig to this file instead of the standard output, or merge it in this file")
+	kubeconfigCmd.Flags().StringVar(&kubeconfigFlags.context, "context", "", "Prefix of the names of the contexts, instead of 'crc'")
+	kubeconfigCmd.Flags().StringVar(&kubeconfigFlags.as, "as", "", fmt.Sprintf("Use the token of a service account with this role (%s) instead of the kubeadmin and developer users",
+		strings.Join(cluster.ServiceAccountRoles(), " or ")))
+	rootCmd.AddCommand(kubeconfigCmd)
+}
+
+var kubeconfigCmd = &cobra.Command{
+	Use:   "kubeconfig",
+	Short: "Print or merge a kubeconfig for the cluster",
+	Long: `Print or merge a kubeconfig for the cluster
+
+The kubeconfig has the crc-admin and crc-developer contexts, with the tokens
+of the kubeadmin and developer users. It is printed on the standard output,
+or written to the file of --path.
+
+With --merge, the contexts are added to the default kubeconfig, as done by
+'crc start' unless update-kubeconfig is false. The contexts, clusters and
+users of other clusters are never replaced, the merged ones get a number
+suffix when their names are taken. --unmerge removes them.
+
+With --as, the kubeconfig has the crc-sa-ROLE context instead, with the token
+of a service account bound to the view cluster role for the viewer role, or
+to the edit cluster role for the developer role. The service accounts are in
+the crc-service-accounts namespace.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runKubeconfig(os.Stdout, newMachine(), kubeconfigFlags, outputFormat)
+	},
+}
+
+type kubeconfigResult struct {
+	Success bool `json:"success"`
+	errorResult
+	Path       string   `json:"path,omitempty"`
+	Contexts   []string `json:"contexts,omitempty"`
+	Kubeconfig string   `json:"kubeconfig,omitempty"`
+	unmerged   bool
+}
+
+func runKubeconfig(writer io.Writer, client machine.Client, options kubeconfigOptions, outputFormat string) error {
+	result, err := kubeconfig(client, options)
+	if result == nil {
+		result = &kubeconfigResult{}
+	}
+	result.Success = err == nil
+	result.errorResult = newErrorResult(err)
+	return render(result, writer, outputFormat)
+}
+
+func kubeconfig(client machine.Client, options kubeconfigOptions) (*kubeconfigResult, error) {
+	if options.merge && options.unmerge {
+		return nil, errors.New("--merge and --unmerge cannot be used together")
+	}
+	if options.unmerge && (options.flatten || options.context != "" || options.as != "") {
+		return nil, errors.New("--flatten, --context and --as cannot be used with --unmerge")
+	}
+	if options.as != "" {
+		if err := cluster.ValidateServiceAccountRole(options.as); err != nil {
+			return nil, err
+		}
+	}
+	path := options.path
+	if path == "" && (options.merge || options.unmerge) {
+		path = machine.DefaultKubeconfigPath()
+	}
+
+	if options.unmerge {
+		if err := machine.UnmergeKubeconfig(path); err != nil {
+			return nil, err
+		}
+		return &kubeconfigResult{Path: path, unmerged: true}, nil
+	}
+
+	var cfg *api.Config
+	var err error
+	if options.as != "" {
+		cfg, err = serviceAccountKubeconfig(client, options.as)
+	} else {
+		if err := checkIfMachineMissing(client); err != nil {
+			return nil, err
+		}
+		cfg, err = client.GetKubeconfig()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if options.context != "" {
+		machine.RenameContexts(cfg, options.context)
+	}
+	if options.flatten {
+		flattenKubeconfig(cfg)
+	}
+
+	if options.merge {
+		contexts, err := machine.MergeKubeconfig(cfg, path)
+		if err != nil {
+			return nil, err
+		}
+		return &kubeconfigResult{Path: path, Contexts: contexts}, nil
+	}
+	if path != "" {
+		if err := clientcmd.WriteToFile(*cfg, path); err != nil {
+			return nil, err
+		}
+		return &kubeconfigResult{Path: path, Contexts: contextNames(cfg)}, nil
+	}
+	content, err := clientcmd.Write(*cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &kubeconfigResult{Contexts: contextNames(cfg), Kubeconfig: string(content)}, nil
+}
+
+// serviceAccountKubeconfig returns a kubeconfig with the token of the service
+// account of role, the service account is created when it does not exist
+func serviceAccountKubeconfig(client machine.Client, role string) (*api.Config, error) {
+	runner, err := runningOpenShiftSSHRunner(client)
+	if err != nil {
+		return nil, err
+	}
+	defer runner.Close()
+
+	token, err := cluster.CreateServiceAccountToken(context.Background(), oc.UseOCWithSSH(runner), runner, role)
+	if err != nil {
+		return nil, err
+	}
+	consoleResult, err := client.GetConsoleURL()
+	if err != nil {
+		return nil, err
+	}
+	return machine.ServiceAccountKubeconfig(&consoleResult.ClusterConfig, role, token)
+}
+
+// flattenKubeconfig removes the contexts other than the current one, and the
+// clusters and users they use
+func flattenKubeconfig(cfg *api.Config) {
+	current, ok := cfg.Contexts[cfg.CurrentContext]
+	if !ok {
+		return
+	}
+	for name, context := range cfg.Contexts {
+		if name != cfg.CurrentContext {
+			delete(cfg.Contexts, name)
+		}
+		if context.Cluster != current.Cluster {
+			delete(cfg.Clusters, context.Cluster)
+		}
+		if context.AuthInfo != current.AuthInfo {
+			delete(cfg.AuthInfos, context.AuthInfo)
+		}
+	}
+}
+
+func contextNames(cfg *api.Config) []string {
+	var names []string
+	for name := range cfg.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *kubeconfigResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	var err error
+	switch {
+	case s.unmerged:
+		_, err = fmt.Fprintf(writer, "Removed the contexts of the cluster from %s\n", s.Path)
+	case s.Kubeconfig != "":
+		_, err = fmt.Fprint(writer, s.Kubeconfig)
+	default:
+		_, err = fmt.Fprintf(writer, "Wrote the contexts %s to %s\n", strings.Join(s.Contexts, ", "), s.Path)
+	}
+	return err
+}
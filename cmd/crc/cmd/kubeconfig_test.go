@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+	clientcmdv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+	"sigs.k8s.io/yaml"
+)
+
+func parseKubeconfig(t *testing.T, content []byte) *clientcmdv1.Config {
+	var cfg clientcmdv1.Config
+	assert.NoError(t, yaml.Unmarshal(content, &cfg))
+	return &cfg
+}
+
+func TestKubeconfigPlain(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runKubeconfig(out, fakemachine.NewClient(), kubeconfigOptions{}, ""))
+	cfg := parseKubeconfig(t, out.Bytes())
+	assert.Len(t, cfg.Contexts, 2)
+	assert.Len(t, cfg.AuthInfos, 2)
+	assert.Equal(t, "crc-admin", cfg.CurrentContext)
+}
+
+func TestKubeconfigFlatten(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runKubeconfig(out, fakemachine.NewClient(), kubeconfigOptions{flatten: true, context: "local"}, ""))
+	cfg := parseKubeconfig(t, out.Bytes())
+	assert.Len(t, cfg.Contexts, 1)
+	assert.Len(t, cfg.AuthInfos, 1)
+	assert.Equal(t, "kubeadmin/api-crc-testing:6443", cfg.AuthInfos[0].Name)
+	assert.Equal(t, "local-admin", cfg.CurrentContext)
+}
+
+func TestKubeconfigMerge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	out := new(bytes.Buffer)
+	assert.NoError(t, runKubeconfig(out, fakemachine.NewClient(), kubeconfigOptions{merge: true, path: path}, jsonFormat))
+	assert.JSONEq(t, `{"success": true, "path": "`+path+`", "contexts": ["crc-admin", "crc-developer"]}`, out.String())
+
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	cfg := parseKubeconfig(t, content)
+	assert.Len(t, cfg.Contexts, 2)
+	assert.Equal(t, "crc-admin", cfg.CurrentContext)
+}
+
+func TestKubeconfigUnmergeMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	out := new(bytes.Buffer)
+	assert.NoError(t, runKubeconfig(out, fakemachine.NewClient(), kubeconfigOptions{unmerge: true, path: path}, ""))
+	assert.Equal(t, "Removed the contexts of the cluster from "+path+"\n", out.String())
+}
+
+func TestKubeconfigInvalidFlags(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runKubeconfig(out, fakemachine.NewClient(), kubeconfigOptions{merge: true, unmerge: true}, ""),
+		"--merge and --unmerge cannot be used together")
+}
+
+func TestKubeconfigFailing(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runKubeconfig(out, fakemachine.NewFailingClient(), kubeconfigOptions{}, ""), "cluster is not running")
+}
+
+func TestKubeconfigInvalidRole(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runKubeconfig(out, fakemachine.NewClient(), kubeconfigOptions{as: "admin"}, ""),
+		"Invalid role 'admin', supported roles are developer, viewer")
+	assert.EqualError(t, runKubeconfig(out, fakemachine.NewClient(), kubeconfigOptions{as: "viewer", unmerge: true}, ""),
+		"--flatten, --context and --as cannot be used with --unmerge")
+}
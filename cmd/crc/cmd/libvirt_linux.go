@@ -0,0 +1,12 @@
+package cmd
+
+import (
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/machine/libvirt"
+)
+
+// setLibvirtURI makes crc, its preflight checks and the libvirt machine
+// driver use the libvirt connection of the configuration
+func setLibvirtURI() error {
+	return libvirt.SetURI(crcConfig.GetLibvirtURI(config))
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+// +build !linux
+
+package cmd
+
+func setLibvirtURI() error {
+	return nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/api/client"
+	"github.com/code-ready/crc/pkg/crc/daemonclient"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logsFollow    bool
+	logsComponent string
+)
+
+func init() {
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Keep streaming the new log lines")
+	logsCmd.Flags().StringVar(&logsComponent, "component", string(types.KubeletLogs),
+		fmt.Sprintf("Logs to show: %s", logComponentNames()))
+	rootCmd.AddCommand(logsCmd)
+}
+
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Show the logs of the instance",
+	Long: "Show the logs of the instance: the kubelet journal, the journal of the units bootstrapping " +
+		"the node, or the serial console which is also available when the instance doesn't boot",
+	Example: "crc logs --component serial --follow",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+		return runLogs(ctx, os.Stdout, newLogsClient(), logsComponent, logsFollow)
+	},
+}
+
+// logsClient streams the logs through the daemon when it is running, or
+// directly from the instance otherwise
+type logsClient interface {
+	Logs(ctx context.Context, logsConfig types.LogsConfig, writer io.Writer) error
+}
+
+type daemonLogsClient struct {
+	client *client.Client
+}
+
+func (c *daemonLogsClient) Logs(ctx context.Context, logsConfig types.LogsConfig, writer io.Writer) error {
+	return c.client.Logs(ctx, string(logsConfig.Component), logsConfig.Follow, writer)
+}
+
+func newLogsClient() logsClient {
+	daemonClient := daemonclient.New()
+	if _, err := daemonClient.APIClient.Version(); err == nil {
+		return &daemonLogsClient{client: daemonClient.APIClient}
+	}
+	return newMachine()
+}
+
+func runLogs(ctx context.Context, writer io.Writer, client logsClient, component string, follow bool) error {
+	logsConfig := types.LogsConfig{
+		Component: types.LogComponent(component),
+		Follow:    follow,
+	}
+	if !logsConfig.Component.IsValid() {
+		return fmt.Errorf("Unknown log component '%s', use one of %s", component, logComponentNames())
+	}
+	return client.Logs(ctx, logsConfig, writer)
+}
+
+func logComponentNames() string {
+	var names []string
+	for _, component := range types.LogComponents {
+		names = append(names, string(component))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogs(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runLogs(context.Background(), out, fakemachine.NewClient(), "bootstrap", false))
+	assert.Equal(t, "dummy bootstrap logs\n", out.String())
+}
+
+func TestLogsUnknownComponent(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runLogs(context.Background(), out, fakemachine.NewClient(), "audit", false),
+		"Unknown log component 'audit', use one of kubelet, bootstrap, serial")
+	assert.Empty(t, out.String())
+}
+
+func TestLogsFailure(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runLogs(context.Background(), out, fakemachine.NewFailingClient(), "kubelet", false), "broken")
+}
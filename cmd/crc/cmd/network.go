@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addOutputFormatFlag(networkDiagnoseCmd)
+	networkCmd.AddCommand(networkDiagnoseCmd)
+	addOutputFormatFlag(networkDNSTestCmd)
+	networkCmd.AddCommand(networkDNSTestCmd)
+	rootCmd.AddCommand(networkCmd)
+}
+
+var networkCmd = &cobra.Command{
+	Use:   "network SUBCOMMAND [flags]",
+	Short: "Troubleshoot the network of the instance",
+	Long:  "Diagnose the network of the host used to reach the instance",
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var networkDiagnoseCmd = &cobra.Command{
+	Use:   "diagnose",
+	Short: "Find the VPN clients and the network settings which break the access to the instance",
+	Long: fmt.Sprintf("List the VPN clients of the host and, when the instance is running, check that the routes "+
+		"of the host reach it and that the host resolves its names. The %s network mode, used when %s is true, is not "+
+		"affected by the routes and the DNS servers of the VPN clients.", network.UserNetworkingMode, crcConfig.VPNCompat),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runNetworkDiagnose(os.Stdout, newMachine(), outputFormat)
+	},
+}
+
+var networkDNSTestCmd = &cobra.Command{
+	Use:   "dns-test NAME",
+	Short: "Resolve a name from the host, from the instance and with the DNS server of the daemon",
+	Long: fmt.Sprintf("Resolve a name, like console-openshift-console.apps-crc.testing, with the resolver of the host, with the resolver "+
+		"of the instance when it is running, and with the DNS server embedded in the daemon, to find which of them fails. "+
+		"'crc config set %s true' logs the queries answered by the daemon in its log file.", crcConfig.DNSQueryLogging),
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runNetworkDNSTest(os.Stdout, newMachine(), args[0], outputFormat)
+	},
+}
+
+type dnsTestResult struct {
+	Success bool `json:"success"`
+	errorResult
+	Name        string                  `json:"name"`
+	NetworkMode network.Mode            `json:"networkMode,omitempty"`
+	Results     []network.DNSTestResult `json:"results,omitempty"`
+}
+
+func runNetworkDNSTest(writer io.Writer, client machine.Client, name string, outputFormat string) error {
+	test, err := client.TestDNS(name)
+	result := &dnsTestResult{Name: name}
+	if test != nil {
+		result.NetworkMode = test.NetworkMode
+		result.Results = test.Results
+		var failed []string
+		for _, r := range test.Results {
+			if r.Failed() {
+				failed = append(failed, string(r.Path))
+			}
+		}
+		if len(failed) > 0 {
+			err = fmt.Errorf("Cannot resolve %s with the %s resolver(s)", name, strings.Join(failed, ", "))
+		}
+	}
+	result.Success = err == nil
+	result.errorResult = newErrorResult(err)
+	return render(result, writer, outputFormat)
+}
+
+// prettyPrintTo prints the outcome of each path before the error, when some
+// of them failed
+func (s *dnsTestResult) prettyPrintTo(writer io.Writer) error {
+	w := tabwriter.NewWriter(writer, 0, 0, 1, ' ', 0)
+	for _, result := range s.Results {
+		var outcome string
+		switch {
+		case result.Skipped:
+			outcome = "skipped, " + result.Error
+		case result.Error != "":
+			outcome = "failed, " + result.Error
+		default:
+			outcome = strings.Join(result.Addresses, ", ")
+		}
+		if result.Server != "" {
+			outcome = fmt.Sprintf("%s (%s)", outcome, result.Server)
+		}
+		if err := printLine(w, string(result.Path), outcome); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if s.Error != nil {
+		return s.Error
+	}
+	return nil
+}
+
+type networkDiagnosisResult struct {
+	Success bool `json:"success"`
+	errorResult
+	NetworkMode    network.Mode           `json:"networkMode,omitempty"`
+	VPNCompat      bool                   `json:"vpnCompat"`
+	VPNInterfaces  []network.VPNInterface `json:"vpnInterfaces,omitempty"`
+	Running        bool                   `json:"running"`
+	IP             string                 `json:"ip,omitempty"`
+	RouteInterface string                 `json:"routeInterface,omitempty"`
+	Conflicts      []network.Conflict     `json:"conflicts,omitempty"`
+}
+
+func runNetworkDiagnose(writer io.Writer, client machine.Client, outputFormat string) error {
+	diagnosis, err := client.DiagnoseNetwork()
+	result := &networkDiagnosisResult{
+		Success:     err == nil,
+		errorResult: newErrorResult(err),
+	}
+	if diagnosis != nil {
+		result.NetworkMode = diagnosis.NetworkMode
+		result.VPNCompat = diagnosis.VPNCompat
+		result.VPNInterfaces = diagnosis.VPNInterfaces
+		result.Running = diagnosis.Running
+		result.IP = diagnosis.IP
+		result.RouteInterface = diagnosis.RouteInterface
+		result.Conflicts = diagnosis.Conflicts
+	}
+	return render(result, writer, outputFormat)
+}
+
+func (s *networkDiagnosisResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	w := tabwriter.NewWriter(writer, 0, 0, 1, ' ', 0)
+	type line struct {
+		left, right string
+	}
+	lines := []line{{"Network Mode", string(s.NetworkMode)}}
+	for _, vpn := range s.VPNInterfaces {
+		lines = append(lines, line{"VPN Interface", fmt.Sprintf("%s (%s)", vpn.Name, strings.Join(vpn.Addresses, ", "))})
+	}
+	if s.IP != "" {
+		lines = append(lines, line{"Instance IP", s.IP})
+	}
+	if s.RouteInterface != "" {
+		lines = append(lines, line{"Route Interface", s.RouteInterface})
+	}
+	for _, conflict := range s.Conflicts {
+		lines = append(lines, line{"Conflict", conflict.Description})
+	}
+	for _, line := range lines {
+		if err := printLine(w, line.left, line.right); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	var advice []string
+	switch {
+	case !s.Running:
+		advice = append(advice, "The instance is not running, the routes and the DNS are only checked when it runs")
+	case len(s.Conflicts) == 0:
+		advice = append(advice, "No conflict found")
+	}
+	if len(s.VPNInterfaces) > 0 && s.NetworkMode == network.SystemNetworkingMode && !s.VPNCompat {
+		advice = append(advice, fmt.Sprintf("VPN clients are connected, 'crc config set %s true' switches to the %s network mode, "+
+			"which is not affected by their routes and DNS servers", crcConfig.VPNCompat, network.UserNetworkingMode))
+	}
+	for _, message := range advice {
+		if _, err := fmt.Fprintln(writer, message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlainNetworkDiagnose(t *testing.T) {
+	client := fakemachine.NewClient()
+	client.NetworkConflicts = []network.Conflict{
+		{Kind: network.RouteConflict, Interface: "tun0", Description: "The traffic to the instance at 192.168.130.11 is routed through the VPN interface tun0"},
+	}
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, runNetworkDiagnose(out, client, ""))
+	assert.Equal(t, `Network Mode:    system
+VPN Interface:   tun0 (10.8.0.2/24)
+Instance IP:     192.168.130.11
+Route Interface: crc
+Conflict:        The traffic to the instance at 192.168.130.11 is routed through the VPN interface tun0
+VPN clients are connected, 'crc config set vpn-compat true' switches to the user network mode, which is not affected by their routes and DNS servers
+`, out.String())
+}
+
+func TestJSONNetworkDiagnose(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runNetworkDiagnose(out, fakemachine.NewClient(), jsonFormat))
+	assert.JSONEq(t, `{"success": true, "networkMode": "system", "vpnCompat": false, "vpnInterfaces": [{"name": "tun0", "addresses": ["10.8.0.2/24"]}],
+		"running": true, "ip": "192.168.130.11", "routeInterface": "crc"}`, out.String())
+
+	out.Reset()
+	assert.EqualError(t, runNetworkDiagnose(out, fakemachine.NewFailingClient(), jsonFormat), "broken")
+	assert.JSONEq(t, `{"success": false, "error": "broken", "exitCode": 1, "vpnCompat": false, "running": false}`, out.String())
+}
+
+func TestPlainNetworkDNSTest(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runNetworkDNSTest(out, fakemachine.NewClient(), "console.apps-crc.testing", ""),
+		"Cannot resolve console.apps-crc.testing with the host resolver(s)")
+	assert.Equal(t, `host:      failed, lookup console.apps-crc.testing: no such host
+instance:  192.168.127.2
+forwarder: 127.0.0.1 (127.0.0.1:55353)
+`, out.String())
+}
+
+func TestJSONNetworkDNSTest(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.Error(t, runNetworkDNSTest(out, fakemachine.NewClient(), "console.apps-crc.testing", jsonFormat))
+	assert.JSONEq(t, `{"success": false, "error": "Cannot resolve console.apps-crc.testing with the host resolver(s)", "exitCode": 1,
+		"name": "console.apps-crc.testing", "networkMode": "user", "results": [
+		{"path": "host", "error": "lookup console.apps-crc.testing: no such host"},
+		{"path": "instance", "addresses": ["192.168.127.2"]},
+		{"path": "forwarder", "server": "127.0.0.1:55353", "addresses": ["127.0.0.1"]}]}`, out.String())
+
+	out.Reset()
+	assert.EqualError(t, runNetworkDNSTest(out, fakemachine.NewFailingClient(), "api.crc.testing", jsonFormat), "broken")
+	assert.JSONEq(t, `{"success": false, "error": "broken", "exitCode": 1, "name": "api.crc.testing"}`, out.String())
+}
@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	nodeCPUs   int
+	nodeMemory int
+)
+
+func init() {
+	addOutputFormatFlag(nodeListCmd)
+	addOutputFormatFlag(nodeAddCmd)
+	addOutputFormatFlag(nodeRemoveCmd)
+	addOutputFormatFlag(nodeStopCmd)
+	nodeAddCmd.Flags().IntVar(&nodeCPUs, "cpus", 0, fmt.Sprintf("Number of CPU cores of the worker node (default: value of %s)", crcConfig.WorkerCPUs))
+	nodeAddCmd.Flags().IntVar(&nodeMemory, "memory", 0, fmt.Sprintf("MiB of memory of the worker node (default: value of %s)", crcConfig.WorkerMemory))
+	nodeCmd.AddCommand(nodeListCmd)
+	nodeCmd.AddCommand(nodeAddCmd)
+	nodeCmd.AddCommand(nodeRemoveCmd)
+	nodeCmd.AddCommand(nodeStopCmd)
+	rootCmd.AddCommand(nodeCmd)
+}
+
+var nodeCmd = &cobra.Command{
+	Use:   "node SUBCOMMAND [flags]",
+	Short: "Manage the worker nodes of the OpenShift cluster",
+	Long: "Add, remove, list and stop the worker nodes running next to the control plane instance " +
+		"(experimental, only with the system network mode)",
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var nodeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the worker nodes",
+	Long:  "List the worker nodes with their state, address, size and readiness in the cluster",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runNodeList(os.Stdout, newMachine(), outputFormat)
+	},
+}
+
+var nodeAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a worker node to the running cluster",
+	Long: fmt.Sprintf("Create a worker node from the bundle and join it to the running cluster, "+
+		"the %s configuration property is updated so that the node is kept by 'crc start'", crcConfig.Workers),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workerConfig := workerConfig(config)
+		if cmd.Flags().Changed("cpus") {
+			workerConfig.CPUs = nodeCPUs
+		}
+		if cmd.Flags().Changed("memory") {
+			workerConfig.Memory = nodeMemory
+		}
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+		return runNodeAdd(ctx, os.Stdout, newMachine(), config, workerConfig, outputFormat)
+	},
+}
+
+var nodeRemoveCmd = &cobra.Command{
+	Use:   "remove [NAME]",
+	Short: "Remove a worker node",
+	Long: fmt.Sprintf("Remove a worker node from the cluster and delete its instance, the last worker node "+
+		"is removed when no name is given. The %s configuration property is updated.", crcConfig.Workers),
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var name string
+		if len(args) > 0 {
+			name = args[0]
+		}
+		return runNodeRemove(os.Stdout, newMachine(), config, name, outputFormat)
+	},
+}
+
+var nodeStopCmd = &cobra.Command{
+	Use:   "stop NAME",
+	Short: "Stop a worker node",
+	Long:  "Stop the instance of a worker node to test how the cluster handles a node failure, 'crc start' starts it again",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runNodeStop(os.Stdout, newMachine(), args[0], outputFormat)
+	},
+}
+
+// workerConfig returns the size of the worker nodes from the configuration
+func workerConfig(cfg crcConfig.Storage) types.WorkerConfig {
+	return types.WorkerConfig{
+		CPUs:   cfg.Get(crcConfig.WorkerCPUs).AsInt(),
+		Memory: cfg.Get(crcConfig.WorkerMemory).AsInt(),
+	}
+}
+
+type nodeListResult struct {
+	Success bool `json:"success"`
+	errorResult
+	Nodes []workerStatus `json:"nodes"`
+}
+
+type nodeResult struct {
+	Success bool `json:"success"`
+	errorResult
+	Action string        `json:"action"`
+	Name   string        `json:"name,omitempty"`
+	Node   *workerStatus `json:"node,omitempty"`
+}
+
+func runNodeList(writer io.Writer, client machine.Client, outputFormat string) error {
+	workers, err := client.Workers()
+	return render(&nodeListResult{
+		Success:     err == nil,
+		errorResult: newErrorResult(err),
+		Nodes:       toWorkerStatus(workers),
+	}, writer, outputFormat)
+}
+
+func runNodeAdd(ctx context.Context, writer io.Writer, client machine.Client, cfg *crcConfig.Config, workerConfig types.WorkerConfig, outputFormat string) error {
+	result := &nodeResult{Action: "added"}
+	worker, err := addNode(ctx, client, cfg, workerConfig)
+	if worker != nil {
+		result.Name = worker.Name
+		result.Node = &toWorkerStatus([]types.WorkerStatus{*worker})[0]
+	}
+	result.Success = err == nil
+	result.errorResult = newErrorResult(err)
+	return render(result, writer, outputFormat)
+}
+
+func addNode(ctx context.Context, client machine.Client, cfg *crcConfig.Config, workerConfig types.WorkerConfig) (*types.WorkerStatus, error) {
+	if ok, msg := crcConfig.ValidateWorkerCPUs(workerConfig.CPUs); !ok {
+		return nil, fmt.Errorf("Invalid number of CPU cores: %s", msg)
+	}
+	if ok, msg := crcConfig.ValidateWorkerMemory(workerConfig.Memory); !ok {
+		return nil, fmt.Errorf("Invalid memory size: %s", msg)
+	}
+	worker, err := client.AddWorker(ctx, workerConfig)
+	if err != nil {
+		return nil, err
+	}
+	saveWorkersCount(client, cfg)
+	return worker, nil
+}
+
+func runNodeRemove(writer io.Writer, client machine.Client, cfg *crcConfig.Config, name string, outputFormat string) error {
+	result := &nodeResult{Action: "removed"}
+	removed, err := client.RemoveWorker(name)
+	if err == nil {
+		result.Name = removed
+		saveWorkersCount(client, cfg)
+	}
+	result.Success = err == nil
+	result.errorResult = newErrorResult(err)
+	return render(result, writer, outputFormat)
+}
+
+func runNodeStop(writer io.Writer, client machine.Client, name string, outputFormat string) error {
+	err := client.StopWorker(name)
+	result := &nodeResult{
+		Success:     err == nil,
+		errorResult: newErrorResult(err),
+		Action:      "stopped",
+		Name:        name,
+	}
+	if err == nil {
+		result.Node = findWorker(client, name)
+	}
+	return render(result, writer, outputFormat)
+}
+
+func findWorker(client machine.Client, name string) *workerStatus {
+	workers, err := client.Workers()
+	if err != nil {
+		logging.Debugf("Cannot list the worker nodes: %v", err)
+		return nil
+	}
+	for _, worker := range toWorkerStatus(workers) {
+		if worker.Name == name {
+			return &worker
+		}
+	}
+	return nil
+}
+
+// saveWorkersCount stores the number of worker nodes in the configuration so
+// that the next start neither removes nor adds worker nodes
+func saveWorkersCount(client machine.Client, cfg *crcConfig.Config) {
+	workers, err := client.Workers()
+	if err != nil {
+		logging.Warnf("Cannot list the worker nodes: %v", err)
+		return
+	}
+	if _, err := cfg.Set(crcConfig.Workers, len(workers)); err != nil {
+		logging.Warnf("Cannot update the %s configuration property: %v", crcConfig.Workers, err)
+	}
+}
+
+func toWorkerStatus(workers []types.WorkerStatus) []workerStatus {
+	statuses := []workerStatus{}
+	for _, worker := range workers {
+		statuses = append(statuses, workerStatus{
+			Name:   worker.Name,
+			State:  string(worker.State),
+			IP:     worker.IP,
+			Ready:  worker.Ready,
+			CPUs:   worker.CPUs,
+			Memory: worker.Memory,
+		})
+	}
+	return statuses
+}
+
+func (s *nodeListResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	if len(s.Nodes) == 0 {
+		_, err := fmt.Fprintln(writer, "There is no worker node")
+		return err
+	}
+	w := tabwriter.NewWriter(writer, 0, 0, 1, ' ', 0)
+	for _, worker := range s.Nodes {
+		if err := printLine(w, worker.Name, workerStatusLine(worker)); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+func (s *nodeResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	if s.Node != nil {
+		_, err := fmt.Fprintf(writer, "Worker node %s %s: %s\n", s.Name, s.Action, workerStatusLine(*s.Node))
+		return err
+	}
+	_, err := fmt.Fprintf(writer, "Worker node %s %s\n", s.Name, s.Action)
+	return err
+}
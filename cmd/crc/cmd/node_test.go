@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestNodeConfig(t *testing.T) *crcConfig.Config {
+	cfg := crcConfig.New(crcConfig.NewEmptyInMemoryStorage())
+	crcConfig.RegisterSettings(cfg)
+	_, err := cfg.Set(crcConfig.NetworkMode, string(network.SystemNetworkingMode))
+	require.NoError(t, err)
+	return cfg
+}
+
+func TestNodeAddAndRemove(t *testing.T) {
+	client := fakemachine.NewClient()
+	cfg := newTestNodeConfig(t)
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, runNodeAdd(context.Background(), out, client, cfg, workerConfig(cfg), ""))
+	assert.Equal(t, "Worker node crc-worker-1 added: Running (192.168.130.12), node ready, 2 CPUs, 6GiB\n", out.String())
+	assert.Equal(t, 1, cfg.Get(crcConfig.Workers).AsInt())
+
+	out.Reset()
+	assert.NoError(t, runNodeAdd(context.Background(), out, client, cfg, types.WorkerConfig{CPUs: 4, Memory: 8192}, jsonFormat))
+	assert.JSONEq(t, `{"success": true, "action": "added", "name": "crc-worker-2", "node": {"name": "crc-worker-2", "state": "Running", "ip": "192.168.130.13", "ready": true, "cpus": 4, "memory": 8192}}`, out.String())
+	assert.Equal(t, 2, cfg.Get(crcConfig.Workers).AsInt())
+
+	out.Reset()
+	assert.NoError(t, runNodeList(out, client, ""))
+	assert.Equal(t, `crc-worker-1: Running (192.168.130.12), node ready, 2 CPUs, 6GiB
+crc-worker-2: Running (192.168.130.13), node ready, 4 CPUs, 8GiB
+`, out.String())
+
+	out.Reset()
+	assert.NoError(t, runNodeRemove(out, client, cfg, "", ""))
+	assert.Equal(t, "Worker node crc-worker-2 removed\n", out.String())
+	assert.Equal(t, 1, cfg.Get(crcConfig.Workers).AsInt())
+
+	assert.EqualError(t, runNodeRemove(new(bytes.Buffer), client, cfg, "crc-worker-3", ""), "Unknown worker node crc-worker-3")
+}
+
+func TestNodeAddInvalidSize(t *testing.T) {
+	cfg := newTestNodeConfig(t)
+	assert.EqualError(t, runNodeAdd(context.Background(), new(bytes.Buffer), fakemachine.NewClient(), cfg, types.WorkerConfig{CPUs: 1, Memory: 8192}, ""),
+		"Invalid number of CPU cores: requires integer value >= 2")
+	assert.EqualError(t, runNodeAdd(context.Background(), new(bytes.Buffer), fakemachine.NewClient(), cfg, types.WorkerConfig{CPUs: 2, Memory: 2048}, ""),
+		"Invalid memory size: requires integer value in MiB >= 6144")
+	assert.Equal(t, 0, cfg.Get(crcConfig.Workers).AsInt())
+}
+
+func TestNodeStop(t *testing.T) {
+	client := fakemachine.NewClient()
+	cfg := newTestNodeConfig(t)
+	_, err := client.AddWorker(context.Background(), workerConfig(cfg))
+	require.NoError(t, err)
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, runNodeStop(out, client, "crc-worker-1", jsonFormat))
+	assert.JSONEq(t, `{"success": true, "action": "stopped", "name": "crc-worker-1", "node": {"name": "crc-worker-1", "state": "Stopped", "ready": false, "cpus": 2, "memory": 6144}}`, out.String())
+
+	out.Reset()
+	assert.NoError(t, runNodeStop(out, client, "crc-worker-1", ""))
+	assert.Equal(t, "Worker node crc-worker-1 stopped: Stopped, 2 CPUs, 6GiB\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, runNodeList(out, client, ""))
+	assert.Equal(t, "crc-worker-1: Stopped, 2 CPUs, 6GiB\n", out.String())
+}
+
+func TestNodeListEmpty(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runNodeList(out, fakemachine.NewClient(), ""))
+	assert.Equal(t, "There is no worker node\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, runNodeList(out, fakemachine.NewClient(), jsonFormat))
+	assert.JSONEq(t, `{"success": true, "nodes": []}`, out.String())
+}
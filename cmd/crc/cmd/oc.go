@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	crcos "github.com/code-ready/crc/pkg/os"
+	"github.com/spf13/cobra"
+)
+
+var ocInstallList bool
+
+func init() {
+	addOutputFormatFlag(ocInstallCmd)
+	ocInstallCmd.Flags().BoolVar(&ocInstallList, "list", false, "List the OpenShift versions whose client is installed")
+	rootCmd.AddCommand(ocInstallCmd)
+
+	// the flags of oc are not parsed by crc
+	ocCmd.Flags().SetInterspersed(false)
+	rootCmd.AddCommand(ocCmd)
+}
+
+var ocInstallCmd = &cobra.Command{
+	Use:   "oc-install",
+	Short: "Install the oc client matching the OpenShift version of the instance",
+	Long: fmt.Sprintf("Copy the oc client of the bundle of the instance to %s/<version>. "+
+		"The clients of the OpenShift versions used before are kept, so that each cluster is used with its own client.", constants.OcVersionsDir),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runOcInstall(os.Stdout, newMachine(), constants.OcVersionsDir, ocInstallList, outputFormat)
+	},
+}
+
+var ocCmd = &cobra.Command{
+	Use:   "oc [--] [ARGS...]",
+	Short: "Run the oc client matching the OpenShift version of the instance",
+	Long:  "Run the oc client matching the OpenShift version of the instance, it is installed with 'crc oc-install' when it is missing.",
+	Example: `  crc oc get nodes
+  crc oc -- --context crc-admin get clusterversion`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runOc(newMachine(), args)
+	},
+}
+
+type ocInstallResult struct {
+	Success bool `json:"success"`
+	errorResult
+	Version   string   `json:"version,omitempty"`
+	Path      string   `json:"path,omitempty"`
+	Installed []string `json:"installed,omitempty"`
+}
+
+func runOcInstall(writer io.Writer, client machine.Client, versionsDir string, list bool, outputFormat string) error {
+	var (
+		result *ocInstallResult
+		err    error
+	)
+	if list {
+		result, err = listOpenShiftClients(versionsDir)
+	} else {
+		result, err = installOpenShiftClient(client)
+	}
+	if err != nil {
+		result = &ocInstallResult{}
+	}
+	result.Success = err == nil
+	result.errorResult = newErrorResult(err)
+	return render(result, writer, outputFormat)
+}
+
+func listOpenShiftClients(versionsDir string) (*ocInstallResult, error) {
+	installed, err := bundle.InstalledOpenShiftClients(versionsDir)
+	if err != nil {
+		return nil, err
+	}
+	return &ocInstallResult{Installed: installed}, nil
+}
+
+func installOpenShiftClient(client machine.Client) (*ocInstallResult, error) {
+	if err := checkIfMachineMissing(client); err != nil {
+		return nil, err
+	}
+	oc, err := client.InstallOpenShiftClient()
+	if err != nil {
+		return nil, err
+	}
+	return &ocInstallResult{Version: oc.Version, Path: oc.Path}, nil
+}
+
+func (s *ocInstallResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	if s.Path == "" {
+		for _, version := range s.Installed {
+			if _, err := fmt.Fprintln(writer, version); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	_, err := fmt.Fprintf(writer, "The oc client of OpenShift %s is installed in %s\n", s.Version, s.Path)
+	return err
+}
+
+func runOc(client machine.Client, args []string) error {
+	result, err := installOpenShiftClient(client)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(result.Path, args...) // #nosec G204
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return crcos.CodeExitError{Err: fmt.Errorf("oc exited with status %d", exitErr.ExitCode()), Code: exitErr.ExitCode()}
+	}
+	return err
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/os/shell"
@@ -10,8 +11,29 @@ import (
 
 var (
 	forceShell string
+	unsetEnv   bool
 )
 
+// addShellFlags adds the flags shared by the commands printing environment
+// variables
+func addShellFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&forceShell, "shell", "", fmt.Sprintf("Set the environment for the specified shell: [%s]. Default is auto-detect.", strings.Join(shell.SupportedShells(), ", ")))
+	cmd.Flags().BoolVarP(&unsetEnv, "unset", "u", false, "Unset the variables instead of setting them")
+	_ = cmd.RegisterFlagCompletionFunc("shell", completeValues(shell.SupportedShells()...))
+}
+
+// envCommandLine returns the command to evaluate in the shell, it keeps the
+// flags given to the command
+func envCommandLine(cmdLine string) string {
+	if forceShell != "" {
+		cmdLine += " --shell " + forceShell
+	}
+	if unsetEnv {
+		cmdLine += " --unset"
+	}
+	return cmdLine
+}
+
 var ocEnvCmd = &cobra.Command{
 	Use:   "oc-env",
 	Short: "Add the 'oc' executable to PATH",
@@ -27,6 +49,15 @@ func runOcEnv(args []string) error {
 		return fmt.Errorf("Error running the oc-env command: %s", err.Error())
 	}
 
+	if unsetEnv {
+		fmt.Println(shell.GetUnsetPathEnvString(userShell, constants.CrcOcBinDir))
+		fmt.Println(shell.GetUnsetEnvString(userShell, "HTTP_PROXY"))
+		fmt.Println(shell.GetUnsetEnvString(userShell, "HTTPS_PROXY"))
+		fmt.Println(shell.GetUnsetEnvString(userShell, "NO_PROXY"))
+		fmt.Println(shell.GenerateUsageHintWithComment(userShell, envCommandLine("crc oc-env")))
+		return nil
+	}
+
 	client := newMachine()
 	if err := checkIfMachineMissing(client); err != nil {
 		return err
@@ -43,11 +74,11 @@ func runOcEnv(args []string) error {
 		fmt.Println(shell.GetEnvString(userShell, "HTTPS_PROXY", proxyConfig.HTTPSProxy))
 		fmt.Println(shell.GetEnvString(userShell, "NO_PROXY", proxyConfig.GetNoProxyString()))
 	}
-	fmt.Println(shell.GenerateUsageHintWithComment(userShell, "crc oc-env"))
+	fmt.Println(shell.GenerateUsageHintWithComment(userShell, envCommandLine("crc oc-env")))
 	return nil
 }
 
 func init() {
 	rootCmd.AddCommand(ocEnvCmd)
-	ocEnvCmd.Flags().StringVar(&forceShell, "shell", "", "Set the environment for the specified shell: [fish, cmd, powershell, tcsh, bash, zsh]. Default is auto-detect.")
+	addShellFlags(ocEnvCmd)
 }
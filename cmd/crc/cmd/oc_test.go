@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlainOcInstall(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runOcInstall(out, fakemachine.NewClient(), t.TempDir(), false, ""))
+	assert.Equal(t, "The oc client of OpenShift 4.6.1 is installed in /home/user/.crc/bin/oc-versions/4.6.1/oc\n", out.String())
+}
+
+func TestJSONOcInstall(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runOcInstall(out, fakemachine.NewClient(), t.TempDir(), false, jsonFormat))
+	assert.JSONEq(t, `{
+  "success": true,
+  "version": "4.6.1",
+  "path": "/home/user/.crc/bin/oc-versions/4.6.1/oc"
+}`, out.String())
+}
+
+func TestPlainOcInstallError(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runOcInstall(out, fakemachine.NewFailingClient(), t.TempDir(), false, ""), "oc client install failed")
+}
+
+func TestPlainOcInstallList(t *testing.T) {
+	versionsDir := t.TempDir()
+	for _, version := range []string{"4.10.3", "4.9.0"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(versionsDir, version), 0750))
+		require.NoError(t, os.WriteFile(filepath.Join(versionsDir, version, constants.OcExecutableName), nil, 0600))
+	}
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, runOcInstall(out, fakemachine.NewFailingClient(), versionsDir, true, ""))
+	assert.Equal(t, "4.9.0\n4.10.3\n", out.String())
+}
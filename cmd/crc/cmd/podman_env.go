@@ -28,6 +28,15 @@ func runPodmanEnv() error {
 		return fmt.Errorf("Error running the podman-env command: %s", err.Error())
 	}
 
+	if unsetEnv {
+		fmt.Println(shell.GetUnsetPathEnvString(userShell, constants.CrcOcBinDir))
+		fmt.Println(shell.GetUnsetEnvString(userShell, "CONTAINER_SSHKEY"))
+		fmt.Println(shell.GetUnsetEnvString(userShell, "CONTAINER_HOST"))
+		fmt.Println(shell.GetUnsetEnvString(userShell, "DOCKER_HOST"))
+		fmt.Println(shell.GenerateUsageHintWithComment(userShell, envCommandLine("crc podman-env")))
+		return nil
+	}
+
 	client := newMachine()
 	if err := checkIfMachineMissing(client); err != nil {
 		return err
@@ -57,12 +66,12 @@ func runPodmanEnv() error {
 	} else {
 		fmt.Println(shell.GetEnvString(userShell, "DOCKER_HOST", "npipe:////./pipe/crc-podman"))
 	}
-	fmt.Println(shell.GenerateUsageHintWithComment(userShell, "crc podman-env"))
+	fmt.Println(shell.GenerateUsageHintWithComment(userShell, envCommandLine("crc podman-env")))
 	return nil
 }
 
 func init() {
 	podmanEnvCmd.Flags().BoolVar(&root, "root", false, "Use root podman in the virtual machine")
-	podmanEnvCmd.Flags().StringVar(&forceShell, "shell", "", "Set the environment for the specified shell: [fish, cmd, powershell, tcsh, bash, zsh]. Default is auto-detect.")
+	addShellFlags(podmanEnvCmd)
 	rootCmd.AddCommand(podmanEnvCmd)
 }
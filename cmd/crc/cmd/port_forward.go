@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/daemonclient"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addOutputFormatFlag(portForwardListCmd)
+	portForwardCmd.AddCommand(portForwardAddCmd)
+	portForwardCmd.AddCommand(portForwardRemoveCmd)
+	portForwardCmd.AddCommand(portForwardListCmd)
+	rootCmd.AddCommand(portForwardCmd)
+}
+
+// portForwardClient is the subset of the daemon API client used by the
+// port-forward commands
+type portForwardClient interface {
+	PortForwards() ([]network.PortForward, error)
+	AddPortForward(spec string) error
+	RemovePortForward(spec string) error
+}
+
+type daemonPortForwardClient struct {
+	client *daemonclient.Client
+}
+
+func (c *daemonPortForwardClient) PortForwards() ([]network.PortForward, error) {
+	result, err := c.client.APIClient.PortForwards()
+	return result.PortForwards, err
+}
+
+func (c *daemonPortForwardClient) AddPortForward(spec string) error {
+	return c.client.APIClient.AddPortForward(spec)
+}
+
+func (c *daemonPortForwardClient) RemovePortForward(spec string) error {
+	return c.client.APIClient.RemovePortForward(spec)
+}
+
+func newPortForwardClient() (portForwardClient, error) {
+	if crcConfig.GetNetworkMode(config) != network.UserNetworkingMode {
+		return nil, fmt.Errorf("Port forwarding is only needed with the %s network mode, the ports of the instance are reachable from the host", network.UserNetworkingMode)
+	}
+	if err := checkDaemonStarted(); err != nil {
+		return nil, err
+	}
+	return &daemonPortForwardClient{client: daemonclient.New()}, nil
+}
+
+var portForwardCmd = &cobra.Command{
+	Use:   "port-forward SUBCOMMAND [flags]",
+	Short: "Manage port forwardings from the host to the instance",
+	Long: "Manage persistent port forwardings from the host to the instance. They are only needed with the " +
+		"user network mode, and they are restored automatically when the instance is started again.",
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var portForwardAddCmd = &cobra.Command{
+	Use:     "add HOST_PORT:machine:GUEST_PORT[/tcp|/udp]",
+	Short:   "Forward a host port to a port of the instance",
+	Long:    "Forward a port on 127.0.0.1 on the host to a port of the instance",
+	Example: "crc port-forward add 8080:machine:80",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newPortForwardClient()
+		if err != nil {
+			return err
+		}
+		return runPortForwardAdd(os.Stdout, client, args[0])
+	},
+}
+
+var portForwardRemoveCmd = &cobra.Command{
+	Use:   "remove HOST_PORT:machine:GUEST_PORT[/tcp|/udp]",
+	Short: "Remove a port forwarding",
+	Long:  "Remove a port forwarding",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newPortForwardClient()
+		if err != nil {
+			return err
+		}
+		return runPortForwardRemove(os.Stdout, client, args[0])
+	},
+}
+
+var portForwardListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the port forwardings",
+	Long:  "List the port forwardings",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newPortForwardClient()
+		if err != nil {
+			return err
+		}
+		return runPortForwardList(os.Stdout, client, outputFormat)
+	},
+}
+
+func runPortForwardAdd(writer io.Writer, client portForwardClient, spec string) error {
+	pf, err := network.ParsePortForward(spec)
+	if err != nil {
+		return err
+	}
+	if err := client.AddPortForward(pf.String()); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(writer, "Forwarding 127.0.0.1:%d to port %d of the instance\n", pf.HostPort, pf.GuestPort)
+	return err
+}
+
+func runPortForwardRemove(writer io.Writer, client portForwardClient, spec string) error {
+	pf, err := network.ParsePortForward(spec)
+	if err != nil {
+		return err
+	}
+	if err := client.RemovePortForward(pf.String()); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(writer, "Removed port forwarding %s\n", pf)
+	return err
+}
+
+func runPortForwardList(writer io.Writer, client portForwardClient, outputFormat string) error {
+	forwards, err := client.PortForwards()
+	return render(&portForwardListResult{
+		Success:      err == nil,
+		errorResult:  newErrorResult(err),
+		PortForwards: forwards,
+	}, writer, outputFormat)
+}
+
+type portForwardListResult struct {
+	Success bool `json:"success"`
+	errorResult
+	PortForwards []network.PortForward `json:"portForwards"`
+}
+
+func (s *portForwardListResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	if len(s.PortForwards) == 0 {
+		_, err := fmt.Fprintln(writer, "No port forwarding")
+		return err
+	}
+	w := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "HOST PORT\tGUEST PORT\tPROTOCOL"); err != nil {
+		return err
+	}
+	for _, pf := range s.PortForwards {
+		if _, err := fmt.Fprintf(w, "%d\t%d\t%s\n", pf.HostPort, pf.GuestPort, pf.Protocol); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,256 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/code-ready/crc/pkg/crc/preset"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addOutputFormatFlag(registryExposeCmd)
+	addOutputFormatFlag(registryEnableCmd)
+	registryCmd.AddCommand(registryExposeCmd)
+	registryCmd.AddCommand(registryEnableCmd)
+	rootCmd.AddCommand(registryCmd)
+}
+
+var registryCmd = &cobra.Command{
+	Use:   "registry SUBCOMMAND [flags]",
+	Short: "Manage the internal image registry of the OpenShift cluster",
+	Long:  "Manage the internal image registry of the OpenShift cluster",
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var registryExposeCmd = &cobra.Command{
+	Use:   "expose",
+	Short: "Expose the internal image registry to the host",
+	Long: "Enable the default route of the internal image registry, trust its certificate " +
+		"for podman on the host and print the commands to log in to it",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRegistryExpose(os.Stdout, newMachine(), containersCertsDir(), outputFormat)
+	},
+}
+
+var registryEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Enable the internal image registry and configure the host to push to it",
+	Long: "Enable and expose the internal image registry, trust its certificate for podman on the host, " +
+		"and store credentials allowed to push images in the podman and docker authentication files",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRegistryEnable(os.Stdout, newMachine(), containersCertsDir(), registryAuthFiles(), outputFormat)
+	},
+}
+
+func containersCertsDir() string {
+	return filepath.Join(constants.GetHomeDir(), ".config", "containers", "certs.d")
+}
+
+// podman also reads ~/.config/containers/auth.json when the auth file in
+// $XDG_RUNTIME_DIR does not exist, and this one survives reboots
+func registryAuthFiles() []string {
+	return []string{
+		filepath.Join(constants.GetHomeDir(), ".config", "containers", "auth.json"),
+		filepath.Join(constants.GetHomeDir(), ".docker", "config.json"),
+	}
+}
+
+type registryResult struct {
+	Success bool `json:"success"`
+	errorResult
+	Host      string   `json:"host,omitempty"`
+	APIURL    string   `json:"apiURL,omitempty"`
+	CAFile    string   `json:"caFile,omitempty"`
+	AuthFiles []string `json:"authFiles,omitempty"`
+}
+
+func runRegistryExpose(writer io.Writer, client machine.Client, certsDir, outputFormat string) error {
+	host, caFile, err := exposeRegistry(client, certsDir)
+	result := &registryResult{
+		Success:     err == nil,
+		errorResult: newErrorResult(err),
+		Host:        host,
+		CAFile:      caFile,
+	}
+	if err == nil {
+		result.APIURL, _ = clusterEndpoints(client)
+	}
+	return render(result, writer, outputFormat)
+}
+
+func runRegistryEnable(writer io.Writer, client machine.Client, certsDir string, authFiles []string, outputFormat string) error {
+	host, caFile, err := enableRegistry(client, certsDir, authFiles)
+	result := &registryResult{
+		Success:     err == nil,
+		errorResult: newErrorResult(err),
+		Host:        host,
+		CAFile:      caFile,
+	}
+	if err == nil {
+		result.AuthFiles = authFiles
+	}
+	return render(result, writer, outputFormat)
+}
+
+func enableRegistry(client machine.Client, certsDir string, authFiles []string) (string, string, error) {
+	runner, err := runningOpenShiftSSHRunner(client)
+	if err != nil {
+		return "", "", err
+	}
+	defer runner.Close()
+
+	ocConfig := oc.UseOCWithSSH(runner)
+	username, token, err := cluster.EnableRegistry(context.Background(), ocConfig)
+	if err != nil {
+		return "", "", err
+	}
+	_, ingressPort := clusterEndpoints(client)
+	host, caFile, err := exposeRegistryWithOC(ocConfig, certsDir, ingressPort)
+	if err != nil {
+		return host, caFile, err
+	}
+	for _, authFile := range authFiles {
+		if err := addRegistryAuth(authFile, host, username, token); err != nil {
+			return host, caFile, err
+		}
+	}
+	return host, caFile, nil
+}
+
+// addRegistryAuth adds the credentials for host to a podman/docker
+// authentication file, keeping its other content untouched
+func addRegistryAuth(authFile, host, username, token string) error {
+	content := map[string]interface{}{}
+	data, err := ioutil.ReadFile(authFile)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(data, &content); err != nil {
+			return fmt.Errorf("Cannot parse %s: %w", authFile, err)
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+	auths, ok := content["auths"].(map[string]interface{})
+	if !ok {
+		auths = map[string]interface{}{}
+	}
+	auths[host] = map[string]string{
+		"auth": base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", username, token))),
+	}
+	content["auths"] = auths
+	data, err = json.MarshalIndent(content, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(authFile), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(authFile, data, 0600)
+}
+
+func exposeRegistry(client machine.Client, certsDir string) (string, string, error) {
+	runner, err := runningOpenShiftSSHRunner(client)
+	if err != nil {
+		return "", "", err
+	}
+	defer runner.Close()
+
+	_, ingressPort := clusterEndpoints(client)
+	return exposeRegistryWithOC(oc.UseOCWithSSH(runner), certsDir, ingressPort)
+}
+
+// clusterEndpoints returns the URL of the API server and the port of the
+// host forwarded to the https port of the router, empty for the default port
+func clusterEndpoints(client machine.Client) (string, string) {
+	result, err := client.GetConsoleURL()
+	if err != nil {
+		logging.Debugf("Cannot get the URLs of the cluster: %v", err)
+		return "", ""
+	}
+	var ingressPort string
+	if consoleURL, err := url.Parse(result.ClusterConfig.WebConsoleURL); err == nil {
+		ingressPort = consoleURL.Port()
+	}
+	return result.ClusterConfig.ClusterAPI, ingressPort
+}
+
+// exposeRegistryWithOC returns the host of the route of the registry, with
+// ingressPort when it is not empty
+func exposeRegistryWithOC(ocConfig oc.Config, certsDir string, ingressPort string) (string, string, error) {
+	host, err := cluster.ExposeRegistry(context.Background(), ocConfig)
+	if err != nil {
+		return "", "", err
+	}
+	if ingressPort != "" {
+		host = net.JoinHostPort(host, ingressPort)
+	}
+	ca, err := cluster.GetRouterCA(ocConfig)
+	if err != nil {
+		return host, "", err
+	}
+	caFile := filepath.Join(certsDir, host, "ca.crt")
+	if err := os.MkdirAll(filepath.Dir(caFile), 0750); err != nil {
+		return host, "", err
+	}
+	if err := ioutil.WriteFile(caFile, ca, 0600); err != nil {
+		return host, "", err
+	}
+	return host, caFile, nil
+}
+
+// runningOpenShiftSSHRunner is the same as runningSSHRunner, but it also fails
+// if the instance is not running OpenShift
+func runningOpenShiftSSHRunner(client machine.Client) (*ssh.Runner, error) {
+	if client.GetPreset() != preset.OpenShift {
+		return nil, fmt.Errorf("This command is only supported with the %s preset", preset.OpenShift)
+	}
+	return runningSSHRunner(client)
+}
+
+func (s *registryResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	if len(s.AuthFiles) > 0 {
+		_, err := fmt.Fprintf(writer, `The internal registry is enabled and exposed at %s
+Its certificate authority was saved in %s
+Credentials allowed to push images were added to %s
+
+To push an image, use:
+  podman push <image> %s/<project>/<image>
+`, s.Host, s.CAFile, strings.Join(s.AuthFiles, " and "), s.Host)
+		return err
+	}
+	apiURL := s.APIURL
+	if apiURL == "" {
+		apiURL = fmt.Sprintf("https://api%s:6443", constants.ClusterDomain)
+	}
+	_, err := fmt.Fprintf(writer, `The internal registry is exposed at %[1]s
+Its certificate authority was saved in %[2]s
+
+To log in, use:
+  oc login -u kubeadmin %[3]s
+  podman login -u kubeadmin -p $(oc whoami -t) %[1]s
+or, with docker (after adding %[2]s to the docker certs.d directory):
+  docker login -u kubeadmin -p $(oc whoami -t) %[1]s
+`, s.Host, s.CAFile, apiURL)
+	return err
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryExposeRendersResult(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runRegistryExpose(out, fakemachine.NewClient(), t.TempDir(), jsonFormat), "not implemented")
+	assert.JSONEq(t, `{"success": false, "error": "not implemented", "exitCode": 1}`, out.String())
+}
+
+func TestRegistryResultPrettyPrint(t *testing.T) {
+	out := new(bytes.Buffer)
+	result := &registryResult{Success: true, Host: "default-route-openshift-image-registry.apps-crc.testing", CAFile: "/tmp/ca.crt"}
+	assert.NoError(t, render(result, out, ""))
+	assert.Contains(t, out.String(), "podman login -u kubeadmin -p $(oc whoami -t) default-route-openshift-image-registry.apps-crc.testing\n")
+}
+
+func TestRegistryResultPrettyPrintWithHostPorts(t *testing.T) {
+	out := new(bytes.Buffer)
+	result := &registryResult{Success: true, Host: "default-route-openshift-image-registry.apps-crc.testing:8443", APIURL: "https://api.crc.testing:16443", CAFile: "/tmp/ca.crt"}
+	assert.NoError(t, render(result, out, ""))
+	assert.Contains(t, out.String(), "oc login -u kubeadmin https://api.crc.testing:16443\n")
+	assert.Contains(t, out.String(), "podman login -u kubeadmin -p $(oc whoami -t) default-route-openshift-image-registry.apps-crc.testing:8443\n")
+}
+
+func TestAddRegistryAuth(t *testing.T) {
+	authFile := filepath.Join(t.TempDir(), "containers", "auth.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(authFile), 0700))
+	require.NoError(t, ioutil.WriteFile(authFile, []byte(`{"auths": {"quay.io": {"auth": "Zm9vOmJhcg=="}}, "credHelpers": {}}`), 0600))
+
+	require.NoError(t, addRegistryAuth(authFile, "default-route.apps-crc.testing", "crc-registry", "token"))
+
+	data, err := ioutil.ReadFile(authFile)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+  "auths": {
+    "quay.io": {"auth": "Zm9vOmJhcg=="},
+    "default-route.apps-crc.testing": {"auth": "Y3JjLXJlZ2lzdHJ5OnRva2Vu"}
+  },
+  "credHelpers": {}
+}`, string(data))
+}
+
+func TestAddRegistryAuthNewFile(t *testing.T) {
+	authFile := filepath.Join(t.TempDir(), ".docker", "config.json")
+	require.NoError(t, addRegistryAuth(authFile, "default-route.apps-crc.testing", "crc-registry", "token"))
+
+	data, err := ioutil.ReadFile(authFile)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"auths": {"default-route.apps-crc.testing": {"auth": "Y3JjLXJlZ2lzdHJ5OnRva2Vu"}}}`, string(data))
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/statefile"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addOutputFormatFlag(repairStateCmd)
+	rootCmd.AddCommand(repairStateCmd)
+}
+
+var repairStateCmd = &cobra.Command{
+	Use:   "repair-state",
+	Short: "Recover the corrupted state files of the instance",
+	Long: "Restore the corrupted state files of the instance from their backup. The files without a valid backup are " +
+		"renamed with the .corrupted suffix, when it is the configuration of the instance it must be removed with 'crc cleanup --only vm'.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRepairState(os.Stdout, machine.RepairState, outputFormat)
+	},
+}
+
+type stateFile struct {
+	Path   string           `json:"path"`
+	Repair statefile.Repair `json:"repair"`
+}
+
+type repairStateResult struct {
+	Success bool `json:"success"`
+	errorResult
+	Files []stateFile `json:"files,omitempty"`
+}
+
+func runRepairState(writer io.Writer, repairState func(name string) ([]types.StateRepair, error), outputFormat string) error {
+	repairs, err := repairState(constants.DefaultName)
+	result := &repairStateResult{
+		Success:     err == nil,
+		errorResult: newErrorResult(err),
+	}
+	for _, repair := range repairs {
+		result.Files = append(result.Files, stateFile{Path: repair.Path, Repair: repair.Repair})
+	}
+	return render(result, writer, outputFormat)
+}
+
+func (s *repairStateResult) prettyPrintTo(writer io.Writer) error {
+	for _, file := range s.Files {
+		var err error
+		switch file.Repair {
+		case statefile.Restored:
+			_, err = fmt.Fprintf(writer, "%s was restored from its backup\n", file.Path)
+		case statefile.Discarded:
+			_, err = fmt.Fprintf(writer, "%s could not be recovered, it was renamed with the .corrupted suffix\n", file.Path)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if s.Error != nil {
+		return s.Error
+	}
+	for _, file := range s.Files {
+		if file.Repair != statefile.Valid {
+			return nil
+		}
+	}
+	_, err := fmt.Fprintln(writer, "The state files of the instance are valid")
+	return err
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/statefile"
+	"github.com/stretchr/testify/assert"
+)
+
+func repairedState(repairs ...types.StateRepair) func(string) ([]types.StateRepair, error) {
+	return func(string) ([]types.StateRepair, error) {
+		return repairs, nil
+	}
+}
+
+func TestPlainRepairState(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runRepairState(out, repairedState(
+		types.StateRepair{Path: "config.json", Repair: statefile.Valid},
+		types.StateRepair{Path: "provisioning.json", Repair: statefile.Valid},
+	), ""))
+	assert.Equal(t, "The state files of the instance are valid\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, runRepairState(out, repairedState(
+		types.StateRepair{Path: "config.json", Repair: statefile.Restored},
+		types.StateRepair{Path: "provisioning.json", Repair: statefile.Discarded},
+	), ""))
+	assert.Equal(t, `config.json was restored from its backup
+provisioning.json could not be recovered, it was renamed with the .corrupted suffix
+`, out.String())
+}
+
+func TestJSONRepairState(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runRepairState(out, repairedState(
+		types.StateRepair{Path: "config.json", Repair: statefile.Restored},
+	), jsonFormat))
+	assert.JSONEq(t, `{"success": true, "files": [{"path": "config.json", "repair": "restored"}]}`, out.String())
+
+	out.Reset()
+	assert.EqualError(t, runRepairState(out, func(string) ([]types.StateRepair, error) {
+		return nil, errors.New("permission denied")
+	}, jsonFormat), "permission denied")
+	assert.JSONEq(t, `{"success": false, "error": "permission denied", "exitCode": 1}`, out.String())
+}
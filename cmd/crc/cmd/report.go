@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reportSos       bool
+	reportOutputDir string
+)
+
+func init() {
+	addOutputFormatFlag(reportCmd)
+	reportCmd.Flags().BoolVar(&reportSos, "sos", false, "Run sosreport inside the instance and add it to the report, when sos is installed in the instance")
+	reportCmd.Flags().StringVar(&reportOutputDir, "output-dir", ".", "Directory where the report archive is created")
+	rootCmd.AddCommand(reportCmd)
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Collect logs and diagnostics for troubleshooting",
+	Long: "Collect the crc logs and configuration from the host, and the journal and node diagnostics " +
+		"from the instance when it is running, into a single archive which can be attached to a support case",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runReport(os.Stdout, newMachine(), reportSos, reportOutputDir, outputFormat)
+	},
+}
+
+// vmReportCommands are run in the instance, their output is stored in the
+// report under vm/<name>
+var vmReportCommands = map[string]string{
+	"journal.log":       "sudo journalctl --boot --no-pager",
+	"dmesg.log":         "sudo dmesg",
+	"containers.log":    "sudo crictl ps -a",
+	"services.log":      "sudo systemctl status --no-pager kubelet crio",
+	"disk-usage.log":    "df -h",
+	"memory-usage.log":  "free -m",
+	"network-setup.log": "ip addr",
+}
+
+const vmSosDir = "/var/tmp/crc-sos"
+
+func runReport(writer io.Writer, client machine.Client, sos bool, outputDir, outputFormat string) error {
+	path, err := generateReport(client, sos, outputDir)
+	return render(&reportResult{
+		Success:     err == nil,
+		errorResult: newErrorResult(err),
+		Path:        path,
+	}, writer, outputFormat)
+}
+
+type reportResult struct {
+	Success bool `json:"success"`
+	errorResult
+	Path string `json:"path,omitempty"`
+}
+
+func (s *reportResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	_, err := fmt.Fprintf(writer, "Report written to %s\n", s.Path)
+	return err
+}
+
+func generateReport(client machine.Client, sos bool, outputDir string) (string, error) {
+	reportPath := filepath.Join(outputDir, fmt.Sprintf("crc-report-%s.tar.gz", time.Now().Format("20060102-150405")))
+	file, err := os.OpenFile(reportPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	for _, hostFile := range []string{constants.LogFilePath, constants.DaemonLogFilePath, constants.ConfigPath} {
+		if err := addFileToReport(tarWriter, hostFile, filepath.Join("host", filepath.Base(hostFile))); err != nil {
+			logging.Debugf("Cannot add %s to the report: %v", hostFile, err)
+		}
+	}
+
+	if err := addVMDiagnosticsToReport(tarWriter, client, sos); err != nil {
+		logging.Warnf("Cannot collect diagnostics from the instance: %v", err)
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return "", err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return "", err
+	}
+	return reportPath, nil
+}
+
+func addVMDiagnosticsToReport(tarWriter *tar.Writer, client machine.Client, sos bool) error {
+	runner, err := runningSSHRunner(client)
+	if err != nil {
+		return err
+	}
+	defer runner.Close()
+
+	for name, command := range vmReportCommands {
+		stdout, stderr, err := runner.Run(command)
+		if err != nil {
+			stdout = fmt.Sprintf("%s\n%s\n%v", stdout, stderr, err)
+		}
+		if err := addDataToReport(tarWriter, []byte(stdout), filepath.Join("vm", name)); err != nil {
+			return err
+		}
+	}
+
+	if sos {
+		return addSosReport(tarWriter, runner)
+	}
+	return nil
+}
+
+// sosRunner runs sos in the instance and copies its archive, it is
+// implemented by *ssh.Runner
+type sosRunner interface {
+	Run(cmd string, args ...string) (string, string, error)
+	RunPrivileged(reason string, cmdAndArgs ...string) (string, string, error)
+	CopyFrom(srcFilename string, dest io.Writer) error
+}
+
+var _ sosRunner = &ssh.Runner{}
+
+// addSosReport runs sos in the instance when it is installed, it is not
+// part of the default RHCOS image, and streams its archive to the report
+func addSosReport(tarWriter *tar.Writer, runner sosRunner) error {
+	if _, _, err := runner.Run("command", "-v", "sos"); err != nil {
+		logging.Warn("sos is not installed in the instance, the report only has the journal and the diagnostics of the node")
+		return nil
+	}
+	logging.Info("Running sosreport in the instance, this can take a few minutes...")
+	if _, stderr, err := runner.RunPrivileged("collecting sosreport",
+		"mkdir", "-p", vmSosDir, "&&", "sudo", "sos", "report", "--batch", "--quiet", "--tmp-dir", vmSosDir); err != nil {
+		return fmt.Errorf("sosreport failed: %v: %s", err, stderr)
+	}
+	defer func() {
+		if _, _, err := runner.RunPrivileged("removing sosreport archive", "rm", "-rf", vmSosDir); err != nil {
+			logging.Debugf("Cannot remove %s: %v", vmSosDir, err)
+		}
+	}()
+	archive, _, err := runner.RunPrivileged("finding sosreport archive", "sh", "-c", fmt.Sprintf("'ls -1t %s/sosreport-*.tar.xz | head -1'", vmSosDir))
+	if err != nil {
+		return err
+	}
+	archive = strings.TrimSpace(archive)
+	if archive == "" {
+		return fmt.Errorf("no sosreport archive found in %s", vmSosDir)
+	}
+	// the archive is only readable by root, it is copied as the ssh user
+	size, _, err := runner.RunPrivileged("preparing the copy of the sosreport archive",
+		"chown", constants.DefaultSSHUser, archive, "&&", "stat", "-c", "%s", archive)
+	if err != nil {
+		return err
+	}
+	archiveSize, err := strconv.ParseInt(strings.TrimSpace(size), 10, 64)
+	if err != nil {
+		return fmt.Errorf("Cannot get the size of %s: %w", archive, err)
+	}
+	if err := tarWriter.WriteHeader(&tar.Header{
+		Name:    path.Join("vm", path.Base(archive)),
+		Mode:    0600,
+		Size:    archiveSize,
+		ModTime: time.Now(),
+	}); err != nil {
+		return err
+	}
+	return runner.CopyFrom(archive, tarWriter)
+}
+
+func addFileToReport(tarWriter *tar.Writer, srcPath, name string) error {
+	data, err := ioutil.ReadFile(srcPath)
+	if err != nil {
+		return err
+	}
+	return addDataToReport(tarWriter, data, name)
+}
+
+func addDataToReport(tarWriter *tar.Writer, data []byte, name string) error {
+	header := &tar.Header{
+		Name:    filepath.ToSlash(name),
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tarWriter.Write(data)
+	return err
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "report")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, runReport(out, fakemachine.NewClient(), false, dir, jsonFormat))
+
+	var result reportResult
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.True(t, result.Success)
+
+	file, err := os.Open(result.Path)
+	require.NoError(t, err)
+	defer file.Close()
+	gzipReader, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	_, err = tar.NewReader(gzipReader).Next()
+	assert.True(t, err == nil || err == io.EOF)
+}
+
+func TestReportMissingOutputDir(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.Error(t, runReport(out, fakemachine.NewClient(), false, "/nonexistent/dir", ""))
+}
+
+type fakeSosRunner struct {
+	installed bool
+	archive   string
+	commands  []string
+}
+
+func (r *fakeSosRunner) Run(cmd string, args ...string) (string, string, error) {
+	if !r.installed {
+		return "", "", errors.New("exit status 1")
+	}
+	return "/usr/sbin/sos", "", nil
+}
+
+func (r *fakeSosRunner) RunPrivileged(reason string, cmdAndArgs ...string) (string, string, error) {
+	r.commands = append(r.commands, strings.Join(cmdAndArgs, " "))
+	switch cmdAndArgs[0] {
+	case "sh":
+		return "/var/tmp/crc-sos/sosreport-crc-2022.tar.xz\n", "", nil
+	case "chown":
+		return fmt.Sprintf("%d\n", len(r.archive)), "", nil
+	}
+	return "", "", nil
+}
+
+func (r *fakeSosRunner) CopyFrom(srcFilename string, dest io.Writer) error {
+	_, err := io.WriteString(dest, r.archive)
+	return err
+}
+
+func TestAddSosReport(t *testing.T) {
+	var buf bytes.Buffer
+	tarWriter := tar.NewWriter(&buf)
+	runner := &fakeSosRunner{installed: true, archive: "sos archive"}
+	require.NoError(t, addSosReport(tarWriter, runner))
+	require.NoError(t, tarWriter.Close())
+	assert.Equal(t, "rm -rf /var/tmp/crc-sos", runner.commands[len(runner.commands)-1])
+
+	tarReader := tar.NewReader(&buf)
+	header, err := tarReader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "vm/sosreport-crc-2022.tar.xz", header.Name)
+	content, err := ioutil.ReadAll(tarReader)
+	assert.NoError(t, err)
+	assert.Equal(t, "sos archive", string(content))
+}
+
+func TestAddSosReportWithoutSos(t *testing.T) {
+	var buf bytes.Buffer
+	runner := &fakeSosRunner{}
+	assert.NoError(t, addSosReport(tar.NewWriter(&buf), runner))
+	assert.Empty(t, runner.commands)
+}
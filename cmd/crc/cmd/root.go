@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"time"
 
 	cmdBundle "github.com/code-ready/crc/cmd/crc/cmd/bundle"
 	cmdConfig "github.com/code-ready/crc/cmd/crc/cmd/config"
+	cmdGenerate "github.com/code-ready/crc/cmd/crc/cmd/generate"
+	"github.com/code-ready/crc/pkg/crc/audit"
 	crcConfig "github.com/code-ready/crc/pkg/crc/config"
 	"github.com/code-ready/crc/pkg/crc/constants"
 	crcErr "github.com/code-ready/crc/pkg/crc/errors"
@@ -18,9 +21,9 @@ import (
 	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/crc/preflight"
 	"github.com/code-ready/crc/pkg/crc/segment"
+	"github.com/code-ready/crc/pkg/crc/ssh"
 	"github.com/code-ready/crc/pkg/crc/telemetry"
 	"github.com/spf13/cobra"
-	"k8s.io/client-go/util/exec"
 )
 
 var rootCmd = &cobra.Command{
@@ -40,6 +43,7 @@ var rootCmd = &cobra.Command{
 
 var (
 	globalForce   bool
+	configFile    string
 	viper         *crcConfig.ViperStorage
 	config        *crcConfig.Config
 	segmentClient *segment.Client
@@ -49,8 +53,11 @@ func init() {
 	if err := constants.EnsureBaseDirectoriesExist(); err != nil {
 		logging.Fatal(err.Error())
 	}
+	// the configuration is needed to register the commands, so --config-file
+	// is looked up before cobra parses the flags
+	configFile = configFileFromArgs(os.Args[1:])
 	var err error
-	config, viper, err = newViperConfig()
+	config, viper, err = newViperConfig(configFile)
 	if err != nil {
 		logging.Fatal(err.Error())
 	}
@@ -58,6 +65,9 @@ func init() {
 	if err := setProxyDefaults(); err != nil {
 		logging.Fatal(err.Error())
 	}
+	if err := setLibvirtURI(); err != nil {
+		logging.Fatal(err.Error())
+	}
 
 	// Initiate segment client
 	if segmentClient, err = segment.NewClient(config, network.HTTPTransport()); err != nil {
@@ -67,8 +77,12 @@ func init() {
 	// subcommands
 	rootCmd.AddCommand(cmdConfig.GetConfigCmd(config))
 	rootCmd.AddCommand(cmdBundle.GetBundleCmd(config))
+	rootCmd.AddCommand(cmdGenerate.GetGenerateCmd(config))
 
 	logging.AddLogLevelFlag(rootCmd.PersistentFlags())
+	rootCmd.PersistentFlags().StringVar(&configFile, "config-file", configFile,
+		"YAML or JSON configuration file to use instead of the default one (a running daemon keeps the file it was started with)")
+	_ = rootCmd.RegisterFlagCompletionFunc("log-level", completeValues("debug", "info", "warn", "error"))
 }
 
 func runPrerun(cmd *cobra.Command) error {
@@ -77,11 +91,17 @@ func runPrerun(cmd *cobra.Command) error {
 	if cmd == daemonCmd {
 		logFile = constants.DaemonLogFilePath
 	}
+	if quietOutput {
+		logging.SetQuiet()
+	}
 	logging.InitLogrus(logFile)
 
 	for _, str := range defaultVersion().lines() {
 		logging.Debugf(str)
 	}
+	if !isCompletionCmd(cmd) {
+		checkUpgradeAdvisory(cmd)
+	}
 	return nil
 }
 
@@ -95,22 +115,96 @@ func runRoot() {
 }
 
 const (
-	defaultErrorExitCode    = 1
-	preflightFailedExitCode = 2
+	defaultErrorExitCode     = 1
+	preflightFailedExitCode  = 2
+	validationFailedExitCode = 3
+	pullSecretFailedExitCode = 4
+	daemonFailedExitCode     = 5
+	startFailedExitCode      = 6
+	bundleFailedExitCode     = 7
+	vmCreateFailedExitCode   = 8
+	clusterTimeoutExitCode   = 9
 )
 
+// failureClassExitCodes are the exit codes of the classes of failures, they
+// are the same for all the commands
+var failureClassExitCodes = map[crcErr.Class]int{
+	crcErr.ValidationFailure: validationFailedExitCode,
+	crcErr.PreflightFailure:  preflightFailedExitCode,
+	crcErr.PullSecretFailure: pullSecretFailedExitCode,
+	crcErr.DaemonUnreachable: daemonFailedExitCode,
+	crcErr.BundleFailure:     bundleFailedExitCode,
+	crcErr.VMCreateFailure:   vmCreateFailedExitCode,
+	crcErr.ClusterTimeout:    clusterTimeoutExitCode,
+}
+
+// printError prints the message of err, followed by how to fix it when it is
+// known
+func printError(writer io.Writer, err error) {
+	_, _ = fmt.Fprintln(writer, err.Error())
+	details := crcErr.DetailsOf(err)
+	if details == nil {
+		return
+	}
+	if details.Remediation != "" {
+		_, _ = fmt.Fprintln(writer, details.Remediation)
+	}
+	if details.DocsURL != "" {
+		_, _ = fmt.Fprintf(writer, "See %s\n", details.DocsURL)
+	}
+}
+
+// exitCodesHelp documents the exit codes in the help of the commands
+func exitCodesHelp() string {
+	codes := []struct {
+		code        int
+		description string
+	}{
+		{defaultErrorExitCode, "other failure"},
+		{preflightFailedExitCode, "a preflight check failed"},
+		{validationFailedExitCode, "invalid flags or configuration"},
+		{pullSecretFailedExitCode, "the pull secret is missing or invalid"},
+		{daemonFailedExitCode, "the daemon is not reachable"},
+		{startFailedExitCode, "the start of the instance failed"},
+		{bundleFailedExitCode, "the bundle is missing, invalid or does not match the instance"},
+		{vmCreateFailedExitCode, "the virtual machine cannot be created"},
+		{clusterTimeoutExitCode, "the cluster did not become ready in time"},
+	}
+	var help strings.Builder
+	help.WriteString("Exit codes, also reported as exitCode in the JSON output:\n")
+	for _, c := range codes {
+		fmt.Fprintf(&help, "  %d  %s\n", c.code, c.description)
+	}
+	return help.String()
+}
+
+// exitCodeOf returns the exit code of a command failing with err: the code
+// of an exec error, else the code of the class of the failure, else the code
+// of the phase of 'crc start' which failed
+func exitCodeOf(err error) int {
+	var exitErr interface{ ExitStatus() int }
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitStatus()
+	}
+	if code, ok := failureClassExitCodes[crcErr.ClassOf(err)]; ok {
+		return code
+	}
+	var phaseErr *startPhaseError
+	if errors.As(err, &phaseErr) {
+		if code, ok := startPhaseExitCodes[phaseErr.phase]; ok {
+			return code
+		}
+	}
+	return defaultErrorExitCode
+}
+
 func Execute() {
 	attachMiddleware([]string{}, rootCmd)
 
 	if err := rootCmd.ExecuteContext(telemetry.NewContext(context.Background())); err != nil {
 		runPostrun()
-		_, _ = fmt.Fprintln(os.Stderr, err.Error())
-		var e exec.CodeExitError
-		if errors.As(err, &e) {
-			os.Exit(e.ExitStatus())
-		} else {
-			os.Exit(defaultErrorExitCode)
-		}
+		printError(os.Stderr, err)
+		os.Exit(exitCodeOf(err))
 	}
 	runPostrun()
 }
@@ -126,13 +220,35 @@ func checkIfMachineMissing(client machine.Client) error {
 	return nil
 }
 
+// runningSSHRunner returns a ssh runner connected to the instance, it fails
+// if the instance does not exist or is not running
+func runningSSHRunner(client machine.Client) (*ssh.Runner, error) {
+	if err := checkIfMachineMissing(client); err != nil {
+		return nil, err
+	}
+	running, err := client.IsRunning()
+	if err != nil {
+		return nil, err
+	}
+	if !running {
+		return nil, fmt.Errorf("%s instance is not running", constants.DefaultName)
+	}
+	connectionDetails, err := client.ConnectionDetails()
+	if err != nil {
+		return nil, err
+	}
+	return ssh.CreateRunner(connectionDetails.IP, connectionDetails.SSHPort, connectionDetails.SSHKeys...)
+}
+
 func setProxyDefaults() error {
 	httpProxy := config.Get(crcConfig.HTTPProxy).AsString()
 	httpsProxy := config.Get(crcConfig.HTTPSProxy).AsString()
 	noProxy := config.Get(crcConfig.NoProxy).AsString()
 	proxyCAFile := config.Get(crcConfig.ProxyCAFile).AsString()
+	caFile := config.Get(crcConfig.CAFile).AsString()
+	authMethod := config.Get(crcConfig.ProxyAuthMethod).AsString()
 
-	proxyConfig, err := network.NewProxyDefaults(httpProxy, httpsProxy, noProxy, proxyCAFile)
+	proxyConfig, err := network.NewProxyDefaults(httpProxy, httpsProxy, noProxy, proxyCAFile, caFile, authMethod)
 	if err != nil {
 		return err
 	}
@@ -144,8 +260,24 @@ func setProxyDefaults() error {
 	return nil
 }
 
-func newViperConfig() (*crcConfig.Config, *crcConfig.ViperStorage, error) {
-	viper, err := crcConfig.NewViperStorage(constants.ConfigPath, constants.CrcEnvPrefix)
+// configFileFromArgs returns the value of the --config-file flag in args, or
+// the default configuration file
+func configFileFromArgs(args []string) string {
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return constants.ConfigPath
+		case strings.HasPrefix(arg, "--config-file="):
+			return strings.TrimPrefix(arg, "--config-file=")
+		case arg == "--config-file" && i+1 < len(args):
+			return args[i+1]
+		}
+	}
+	return constants.ConfigPath
+}
+
+func newViperConfig(configFile string) (*crcConfig.Config, *crcConfig.ViperStorage, error) {
+	viper, err := crcConfig.NewViperStorage(configFile, constants.CrcEnvPrefix)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -168,6 +300,7 @@ func executeWithLogging(fullCmd string, input func(cmd *cobra.Command, args []st
 		logging.Debugf("Running '%s'", fullCmd)
 		startTime := time.Now()
 		err := input(cmd, args)
+		recordAudit(audit.NewLog(constants.AuditLogPath), fullCmd, args, err)
 		if serr := segmentClient.UploadCmd(cmd.Context(), fullCmd, time.Since(startTime), err); serr != nil {
 			logging.Debugf("Cannot send data to telemetry: %v", serr)
 		}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
+	crcos "github.com/code-ready/crc/pkg/os"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigFileFromArgs(t *testing.T) {
+	assert.Equal(t, constants.ConfigPath, configFileFromArgs([]string{"start"}))
+	assert.Equal(t, "crc.yaml", configFileFromArgs([]string{"start", "--config-file", "crc.yaml"}))
+	assert.Equal(t, "crc.yaml", configFileFromArgs([]string{"--config-file=crc.yaml", "config", "view"}))
+	assert.Equal(t, constants.ConfigPath, configFileFromArgs([]string{"ssh", "--", "cat", "--config-file=crc.yaml"}))
+	assert.Equal(t, constants.ConfigPath, configFileFromArgs([]string{"start", "--config-file"}))
+}
+
+func TestExitCodeOf(t *testing.T) {
+	assert.Equal(t, defaultErrorExitCode, exitCodeOf(errors.New("failed")))
+	assert.Equal(t, preflightFailedExitCode, exitCodeOf(crcos.CodeExitError{Err: errors.New("failed"), Code: preflightFailedExitCode}))
+	assert.Equal(t, clusterTimeoutExitCode, exitCodeOf(fmt.Errorf("Error waiting for apiserver: %w", crcErrors.WithClass(crcErrors.ClusterTimeout, errors.New("timeout")))))
+	assert.Equal(t, startFailedExitCode, exitCodeOf(&startPhaseError{phase: instancePhase, err: errors.New("failed")}))
+	assert.Equal(t, vmCreateFailedExitCode, exitCodeOf(&startPhaseError{phase: instancePhase, err: crcErrors.WithClass(crcErrors.VMCreateFailure, errors.New("failed"))}))
+}
+
+func TestPrintError(t *testing.T) {
+	out := new(bytes.Buffer)
+	printError(out, errors.New("failed"))
+	assert.Equal(t, "failed\n", out.String())
+
+	out.Reset()
+	printError(out, crcErrors.WithClass(crcErrors.DaemonUnreachable, errors.New("daemon is not running")))
+	assert.Equal(t, `daemon is not running
+Start the daemon with 'crc daemon', or run 'crc setup' to install it
+See https://code-ready.github.io/crc/#setting-up-codeready-containers_gsg
+`, out.String())
+}
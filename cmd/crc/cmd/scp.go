@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/spf13/cobra"
+)
+
+// scpInstancePrefix marks the paths of the instance in the 'crc scp' arguments
+const scpInstancePrefix = constants.DefaultName + ":"
+
+func init() {
+	rootCmd.AddCommand(scpCmd)
+}
+
+var scpCmd = &cobra.Command{
+	Use:   "scp [flags] SOURCE DESTINATION",
+	Short: "Copy a file between the host and the instance",
+	Long: fmt.Sprintf("Copy a file between the host and the instance. The path in the instance is prefixed with '%s', "+
+		"the file is copied as the %s user. When DESTINATION is a directory, the file keeps its name.", scpInstancePrefix, constants.DefaultSSHUser),
+	Example: `  crc scp ./pod.yaml crc:/tmp/
+  crc scp crc:/var/log/messages messages.log`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runner, err := runningSSHRunner(newMachine())
+		if err != nil {
+			return err
+		}
+		defer runner.Close()
+		return runSCP(runner, args[0], args[1])
+	},
+}
+
+// fileCopier is the subset of ssh.Runner used by 'crc scp'
+type fileCopier interface {
+	CopyTo(src io.Reader, srcFilename string, destFilename string, mode os.FileMode) error
+	CopyFrom(srcFilename string, dest io.Writer) error
+}
+
+func parseSCPPath(arg string) (bool, string) {
+	if strings.HasPrefix(arg, scpInstancePrefix) {
+		return true, strings.TrimPrefix(arg, scpInstancePrefix)
+	}
+	return false, arg
+}
+
+func runSCP(copier fileCopier, source, destination string) error {
+	srcInInstance, src := parseSCPPath(source)
+	destInInstance, dest := parseSCPPath(destination)
+	if srcInInstance == destInInstance {
+		return fmt.Errorf("Exactly one of SOURCE and DESTINATION must be a path in the instance, prefixed with '%s'", scpInstancePrefix)
+	}
+	if src == "" || dest == "" {
+		return errors.New("SOURCE and DESTINATION cannot be empty")
+	}
+	if destInInstance {
+		return copyToInstance(copier, src, dest)
+	}
+	return copyFromInstance(copier, src, dest)
+}
+
+func copyToInstance(copier fileCopier, src, dest string) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory, only files can be copied", src)
+	}
+	return copier.CopyTo(file, filepath.Base(src), dest, info.Mode().Perm())
+}
+
+func copyFromInstance(copier fileCopier, src, dest string) error {
+	if info, err := os.Stat(dest); err == nil && info.IsDir() {
+		dest = filepath.Join(dest, path.Base(src))
+	}
+	file, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if err := copier.CopyFrom(src, file); err != nil {
+		file.Close()
+		os.Remove(dest)
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSCP(t *testing.T) {
+	dir := t.TempDir()
+	runner := &fakeSSHRunner{files: map[string]string{"/etc/hostname": "crc\n"}}
+
+	assert.NoError(t, runSCP(runner, "crc:/etc/hostname", dir))
+	data, err := ioutil.ReadFile(filepath.Join(dir, "hostname"))
+	assert.NoError(t, err)
+	assert.Equal(t, "crc\n", string(data))
+
+	assert.NoError(t, runSCP(runner, filepath.Join(dir, "hostname"), "crc:/tmp/hostname"))
+	assert.Equal(t, "crc\n", runner.files["/tmp/hostname"])
+
+	assert.EqualError(t, runSCP(runner, "crc:/missing", filepath.Join(dir, "missing")), "No such file or directory")
+	assert.NoFileExists(t, filepath.Join(dir, "missing"))
+
+	assert.EqualError(t, runSCP(runner, "a", "b"), "Exactly one of SOURCE and DESTINATION must be a path in the instance, prefixed with 'crc:'")
+	assert.EqualError(t, runSCP(runner, dir, "crc:/tmp"), dir+" is a directory, only files can be copied")
+}
@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/Masterminds/semver/v3"
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/daemonclient"
+	"github.com/code-ready/crc/pkg/crc/gpg"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/selfupdate"
+	crcversion "github.com/code-ready/crc/pkg/crc/version"
+	"github.com/spf13/cobra"
+)
+
+var selfUpdateCheck bool
+
+func init() {
+	addOutputFormatFlag(selfUpdateCmd)
+	selfUpdateCmd.Flags().BoolVar(&selfUpdateCheck, "check", false, "Only check if a newer release is available")
+	rootCmd.AddCommand(selfUpdateCmd)
+}
+
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Update crc to the latest release",
+	Long: fmt.Sprintf(`Update crc to the latest release of the update channel
+
+The archive of the release is downloaded from the mirror and checked against
+the sha256sum.txt file of the release, signed with the release key of Red
+Hat, then the crc executable is replaced with the executable of the archive,
+and the daemon is restarted with it. Use
+'crc config set %s %s' to get the release candidates.
+
+crc installed with the installer of macOS or Windows must be updated with the
+installer of the new release.`, crcConfig.UpdateChannel, crcversion.CandidateChannel),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		executable, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		if executable, err = filepath.EvalSymlinks(executable); err != nil {
+			return err
+		}
+		updater := &selfUpdater{
+			executable:     executable,
+			currentVersion: crcversion.GetCRCVersion(),
+			latestRelease: func(channel string) (*crcversion.CrcReleaseInfo, error) {
+				return crcversion.GetCRCLatestVersionFromMirror(network.HTTPTransport(), channel)
+			},
+			releaseKeyRing: gpg.ReleaseKeyRing,
+			restartDaemon:  restartUpdatedDaemon,
+		}
+		return runSelfUpdate(os.Stdout, updater, config.Get(crcConfig.UpdateChannel).AsString(), selfUpdateCheck, outputFormat)
+	},
+}
+
+// selfUpdater has the executable to update, and the steps of the update
+// which access the mirror and the daemon
+type selfUpdater struct {
+	executable     string
+	currentVersion string
+	latestRelease  func(channel string) (*crcversion.CrcReleaseInfo, error)
+	releaseKeyRing func() (*gpg.KeyRing, error)
+	restartDaemon  func(executable, version string) (bool, error)
+}
+
+type selfUpdateResult struct {
+	Success bool `json:"success"`
+	errorResult
+	Channel         string `json:"channel"`
+	CurrentVersion  string `json:"currentVersion"`
+	LatestVersion   string `json:"latestVersion,omitempty"`
+	Available       bool   `json:"available"`
+	Updated         bool   `json:"updated"`
+	DaemonRestarted bool   `json:"daemonRestarted"`
+}
+
+func runSelfUpdate(writer io.Writer, updater *selfUpdater, channel string, checkOnly bool, outputFormat string) error {
+	result := &selfUpdateResult{
+		Channel:        channel,
+		CurrentVersion: updater.currentVersion,
+	}
+	err := selfUpdate(updater, channel, checkOnly, result)
+	result.Success = err == nil
+	result.errorResult = newErrorResult(err)
+	return render(result, writer, outputFormat)
+}
+
+func selfUpdate(updater *selfUpdater, channel string, checkOnly bool, result *selfUpdateResult) error {
+	if err := selfupdate.Supported(); err != nil {
+		return err
+	}
+	release, err := updater.latestRelease(channel)
+	if err != nil {
+		return fmt.Errorf("Cannot get the latest release of the %s channel: %w", channel, err)
+	}
+	if release.Version.CrcVersion == nil {
+		return errors.New("empty version")
+	}
+	currentVersion, err := semver.NewVersion(updater.currentVersion)
+	if err != nil {
+		return err
+	}
+	result.LatestVersion = release.Version.CrcVersion.String()
+	result.Available = release.Version.CrcVersion.GreaterThan(currentVersion)
+	if !result.Available || checkOnly {
+		return nil
+	}
+
+	archiveURL, err := selfupdate.ArchiveURL(release)
+	if err != nil {
+		return err
+	}
+	keyRing, err := updater.releaseKeyRing()
+	if err != nil {
+		return err
+	}
+	tmpDir, err := ioutil.TempDir("", "crc-update")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	newExecutable, err := selfupdate.Download(archiveURL, tmpDir, keyRing)
+	if err != nil {
+		return err
+	}
+	// the executable of the archive must run on this host, and be the
+	// release of release-info.json
+	newVersion, err := selfupdate.ExecutableVersion(newExecutable)
+	if err != nil {
+		return err
+	}
+	if newVersion != result.LatestVersion {
+		return fmt.Errorf("the executable of %s is version %s instead of %s", archiveURL, newVersion, result.LatestVersion)
+	}
+	if err := selfupdate.Replace(updater.executable, newExecutable); err != nil {
+		return fmt.Errorf("Cannot replace %s: %w", updater.executable, err)
+	}
+	result.Updated = true
+
+	restarted, err := updater.restartDaemon(updater.executable, newVersion)
+	if err != nil {
+		logging.Warnf("The crc daemon was not restarted: %v", err)
+	}
+	result.DaemonRestarted = restarted
+	return nil
+}
+
+// restartUpdatedDaemon restarts the daemon when it is running, a stopped
+// daemon runs the new executable when it is started
+func restartUpdatedDaemon(executable, version string) (bool, error) {
+	daemonClient := daemonclient.New()
+	if _, err := daemonClient.APIClient.Version(); err != nil {
+		logging.Debugf("Cannot reach the daemon API: %v", err)
+		return false, nil
+	}
+	if _, err := restartDaemon(daemonClient, executable, version); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *selfUpdateResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	var err error
+	switch {
+	case s.Updated:
+		_, err = fmt.Fprintf(writer, "crc was updated from %s to %s\n", s.CurrentVersion, s.LatestVersion)
+		if err == nil && s.DaemonRestarted {
+			_, err = fmt.Fprintln(writer, "The crc daemon was restarted")
+		}
+	case s.Available:
+		_, err = fmt.Fprintf(writer, "crc %s is available in the %s channel, run 'crc self-update' to install it\n", s.LatestVersion, s.Channel)
+	default:
+		_, err = fmt.Fprintf(writer, "crc %s is up to date, the latest release of the %s channel is %s\n", s.CurrentVersion, s.Channel, s.LatestVersion)
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/code-ready/crc/pkg/crc/gpg/gpgtest"
+	crcversion "github.com/code-ready/crc/pkg/crc/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSelfUpdater(t *testing.T, latestVersion string, links map[string]string) (*selfUpdater, *gpgtest.Signer) {
+	executable := filepath.Join(t.TempDir(), "crc")
+	require.NoError(t, os.WriteFile(executable, []byte("old crc"), 0755))
+	signer, err := gpgtest.NewSigner()
+	require.NoError(t, err)
+	return &selfUpdater{
+		executable:     executable,
+		currentVersion: "2.0.1",
+		latestRelease: func(channel string) (*crcversion.CrcReleaseInfo, error) {
+			if channel != crcversion.StableChannel {
+				return nil, fmt.Errorf("unexpected channel %s", channel)
+			}
+			return &crcversion.CrcReleaseInfo{
+				Version: crcversion.Version{CrcVersion: semver.MustParse(latestVersion)},
+				Links:   links,
+			}, nil
+		},
+		releaseKeyRing: signer.KeyRing,
+		restartDaemon: func(executable, version string) (bool, error) {
+			return true, nil
+		},
+	}, signer
+}
+
+func TestSelfUpdateUpToDate(t *testing.T) {
+	out := new(bytes.Buffer)
+	updater, _ := newTestSelfUpdater(t, "2.0.1", nil)
+	assert.NoError(t, runSelfUpdate(out, updater, crcversion.StableChannel, false, ""))
+	assert.Equal(t, "crc 2.0.1 is up to date, the latest release of the stable channel is 2.0.1\n", out.String())
+}
+
+func TestSelfUpdateCheck(t *testing.T) {
+	out := new(bytes.Buffer)
+	updater, _ := newTestSelfUpdater(t, "2.1.0", nil)
+	assert.NoError(t, runSelfUpdate(out, updater, crcversion.StableChannel, true, jsonFormat))
+	assert.JSONEq(t, `{
+  "success": true,
+  "channel": "stable",
+  "currentVersion": "2.0.1",
+  "latestVersion": "2.1.0",
+  "available": true,
+  "updated": false,
+  "daemonRestarted": false
+}`, out.String())
+}
+
+func TestSelfUpdateWithoutArchive(t *testing.T) {
+	updater, _ := newTestSelfUpdater(t, "2.1.0", map[string]string{runtime.GOOS: "https://mirror.openshift.com/crc/2.1.0/crc-installer.pkg"})
+	assert.EqualError(t, runSelfUpdate(new(bytes.Buffer), updater, crcversion.StableChannel, false, ""),
+		"https://mirror.openshift.com/crc/2.1.0/crc-installer.pkg is not an archive of the crc executable, download and install it manually")
+	content, err := os.ReadFile(updater.executable)
+	assert.NoError(t, err)
+	assert.Equal(t, "old crc", string(content))
+}
+
+func TestSelfUpdate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the test executable is a shell script")
+	}
+	script := "#!/bin/sh\necho '{\"version\": \"2.1.0\"}'\n"
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "crc-2.1.0/crc", Mode: 0755, Size: int64(len(script))}))
+	_, err := tw.Write([]byte(script))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	sum := sha256.Sum256(archive.Bytes())
+	checksums := fmt.Sprintf("%s  crc-amd64.tar.gz\n", hex.EncodeToString(sum[:]))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/2.1.0/crc-amd64.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(archive.Bytes())
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	updater, signer := newTestSelfUpdater(t, "2.1.0", map[string]string{runtime.GOOS: server.URL + "/2.1.0/crc-amd64.tar.gz"})
+	signed, err := signer.ClearSign([]byte(checksums))
+	require.NoError(t, err)
+	mux.HandleFunc("/2.1.0/sha256sum.txt.sig", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(signed)
+	})
+	out := new(bytes.Buffer)
+	require.NoError(t, runSelfUpdate(out, updater, crcversion.StableChannel, false, ""))
+	assert.Equal(t, "crc was updated from 2.0.1 to 2.1.0\nThe crc daemon was restarted\n", out.String())
+	content, err := os.ReadFile(updater.executable)
+	assert.NoError(t, err)
+	assert.Equal(t, script, string(content))
+}
@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	crcConfig "github.com/code-ready/crc/pkg/crc/config"
 	"github.com/code-ready/crc/pkg/crc/constants"
 	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
 	"github.com/code-ready/crc/pkg/crc/input"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
 	"github.com/code-ready/crc/pkg/crc/preflight"
 	"github.com/code-ready/crc/pkg/crc/validation"
 
@@ -17,13 +19,16 @@ import (
 )
 
 var (
-	checkOnly bool
+	checkOnly            bool
+	verifyLeastPrivilege bool
 )
 
 func init() {
 	setupCmd.Flags().Bool(crcConfig.ExperimentalFeatures, false, "Allow the use of experimental features")
-	setupCmd.Flags().StringP(crcConfig.Bundle, "b", constants.GetDefaultBundlePath(crcConfig.GetPreset(config)), "Bundle to use for instance")
-	setupCmd.Flags().BoolVar(&checkOnly, "check-only", false, "Only run the preflight checks, don't try to fix any misconfiguration")
+	setupCmd.Flags().StringP(crcConfig.Bundle, "b", constants.GetDefaultBundlePath(crcConfig.GetPreset(config)), "Bundle to use for instance, a file, an HTTP(S) URL or a docker:// registry reference")
+	_ = setupCmd.RegisterFlagCompletionFunc(crcConfig.Bundle, completeBundles)
+	setupCmd.Flags().BoolVar(&checkOnly, "check-only", false, "Only run the preflight checks and report the result of each of them, don't try to fix any misconfiguration")
+	setupCmd.Flags().BoolVar(&verifyLeastPrivilege, "verify-least-privilege", false, "Verify that start, stop and delete will not need administrator rights, and list the operations which still need them")
 	addOutputFormatFlag(setupCmd)
 	rootCmd.AddCommand(setupCmd)
 }
@@ -36,6 +41,12 @@ var setupCmd = &cobra.Command{
 		if err := viper.BindFlagSet(cmd.Flags()); err != nil {
 			return err
 		}
+		if verifyLeastPrivilege {
+			return runVerifyLeastPrivilege(os.Stdout, preflight.VerifyLeastPrivilege(config), outputFormat)
+		}
+		if checkOnly {
+			return runSetupCheckOnly(os.Stdout, preflight.CheckHost(config), outputFormat)
+		}
 		return runSetup(args)
 	},
 }
@@ -58,28 +69,26 @@ func runSetup(arguments []string) error {
 	}
 
 	if !config.Get(crcConfig.Bundle).IsDefault {
-		if err := validation.ValidatePath(config.Get(crcConfig.Bundle).AsString()); err != nil {
+		bundlePath := config.Get(crcConfig.Bundle).AsString()
+		if bundle.IsRemote(bundlePath) {
+			if err := bundle.ValidateRemote(bundlePath); err != nil {
+				return err
+			}
+		} else if err := validation.ValidatePath(bundlePath); err != nil {
 			return err
 		}
 	}
 
-	err := preflight.SetupHost(config, checkOnly)
-	if err != nil && checkOnly {
-		err = exec.CodeExitError{
-			Err:  err,
-			Code: preflightFailedExitCode,
-		}
-	}
-
+	err := preflight.SetupHost(config, false)
 	return render(&setupResult{
-		Success: err == nil,
-		Error:   crcErrors.ToSerializableError(err),
+		Success:     err == nil,
+		errorResult: newErrorResult(err),
 	}, os.Stdout, outputFormat)
 }
 
 type setupResult struct {
-	Success bool                         `json:"success"`
-	Error   *crcErrors.SerializableError `json:"error,omitempty"`
+	Success bool `json:"success"`
+	errorResult
 }
 
 func (s *setupResult) prettyPrintTo(writer io.Writer) error {
@@ -90,3 +99,109 @@ func (s *setupResult) prettyPrintTo(writer io.Writer) error {
 		"Use 'crc start' to start the instance")
 	return err
 }
+
+func runSetupCheckOnly(writer io.Writer, checks []preflight.CheckResult, outputFormat string) error {
+	var failed int
+	for _, check := range checks {
+		if check.Status == preflight.CheckFailed {
+			failed++
+		}
+	}
+	var err error
+	if failed > 0 {
+		err = crcErrors.WithClass(crcErrors.PreflightFailure, fmt.Errorf("%d check(s) failed, run 'crc setup' to fix them", failed))
+	}
+	if err := render(&setupCheckResult{
+		Success:     err == nil,
+		errorResult: newErrorResult(err),
+		Checks:      checks,
+	}, writer, outputFormat); err != nil {
+		return exec.CodeExitError{
+			Err:  err,
+			Code: preflightFailedExitCode,
+		}
+	}
+	return nil
+}
+
+type setupCheckResult struct {
+	Success bool `json:"success"`
+	errorResult
+	Checks []preflight.CheckResult `json:"checks"`
+}
+
+func (s *setupCheckResult) prettyPrintTo(writer io.Writer) error {
+	for _, check := range s.Checks {
+		if _, err := fmt.Fprintf(writer, "[%s] %s\n", strings.ToUpper(string(check.Status)), check.Description); err != nil {
+			return err
+		}
+		if check.Error != "" {
+			if _, err := fmt.Fprintf(writer, "\terror: %s\n", check.Error); err != nil {
+				return err
+			}
+		}
+		if check.Remediation != "" {
+			if _, err := fmt.Fprintf(writer, "\tremediation: %s\n", check.Remediation); err != nil {
+				return err
+			}
+		}
+	}
+	if s.Error != nil {
+		return s.Error
+	}
+	_, err := fmt.Fprintln(writer, "Your system is correctly setup for using CodeReady Containers")
+	return err
+}
+
+func runVerifyLeastPrivilege(writer io.Writer, operations []preflight.PrivilegedOperation, outputFormat string) error {
+	var missing int
+	for _, operation := range operations {
+		if !operation.Granted {
+			missing++
+		}
+	}
+	var err error
+	if missing > 0 {
+		err = crcErrors.WithClass(crcErrors.PreflightFailure, fmt.Errorf("%d operation(s) would need administrator rights, run 'crc setup' to grant them", missing))
+	}
+	if err := render(&leastPrivilegeResult{
+		Success:     err == nil,
+		errorResult: newErrorResult(err),
+		Operations:  operations,
+	}, writer, outputFormat); err != nil {
+		return exec.CodeExitError{
+			Err:  err,
+			Code: preflightFailedExitCode,
+		}
+	}
+	return nil
+}
+
+type leastPrivilegeResult struct {
+	Success bool `json:"success"`
+	errorResult
+	Operations []preflight.PrivilegedOperation `json:"operations"`
+}
+
+func (s *leastPrivilegeResult) prettyPrintTo(writer io.Writer) error {
+	for _, operation := range s.Operations {
+		status := "OK"
+		if !operation.Granted {
+			status = "NEEDS ADMIN"
+		}
+		if _, err := fmt.Fprintf(writer, "[%s] %s (%s)\n\tgranted by: %s\n", status, operation.Description,
+			strings.Join(operation.Commands, ", "), operation.GrantedBy); err != nil {
+			return err
+		}
+		if operation.Error != "" {
+			if _, err := fmt.Fprintf(writer, "\terror: %s\n", operation.Error); err != nil {
+				return err
+			}
+		}
+	}
+	if s.Error != nil {
+		return s.Error
+	}
+	_, err := fmt.Fprintln(writer, "start, stop and delete will not need administrator rights")
+	return err
+}
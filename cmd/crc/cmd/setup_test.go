@@ -5,8 +5,9 @@ import (
 	"errors"
 	"testing"
 
-	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/preflight"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/util/exec"
 )
 
 func TestSetupRenderActionPlainSuccess(t *testing.T) {
@@ -21,8 +22,8 @@ func TestSetupRenderActionPlainFailure(t *testing.T) {
 	out := new(bytes.Buffer)
 	err := errors.New("broken")
 	assert.EqualError(t, render(&setupResult{
-		Success: false,
-		Error:   crcErrors.ToSerializableError(err),
+		Success:     false,
+		errorResult: newErrorResult(err),
 	}, out, ""), "broken")
 	assert.Equal(t, "", out.String())
 }
@@ -37,9 +38,141 @@ func TestSetupRenderActionJSONSuccess(t *testing.T) {
 
 func TestSetupRenderActionJSONFailure(t *testing.T) {
 	out := new(bytes.Buffer)
-	assert.NoError(t, render(&setupResult{
-		Success: false,
-		Error:   crcErrors.ToSerializableError(errors.New("broken")),
-	}, out, jsonFormat))
-	assert.JSONEq(t, `{"success": false, "error": "broken"}`, out.String())
+	assert.EqualError(t, render(&setupResult{
+		Success:     false,
+		errorResult: newErrorResult(errors.New("broken")),
+	}, out, jsonFormat), "broken")
+	assert.JSONEq(t, `{"success": false, "error": "broken", "exitCode": 1}`, out.String())
+}
+
+var testCheckResults = []preflight.CheckResult{
+	{
+		ID:          "check-ram",
+		Description: "Checking minimum RAM requirements",
+		Status:      preflight.CheckPassed,
+		Fixable:     false,
+	},
+	{
+		ID:          "check-libvirt-running",
+		Description: "Checking if libvirt daemon is running",
+		Status:      preflight.CheckFailed,
+		Fixable:     true,
+		Error:       "libvirtd is not running",
+		Remediation: "Run 'crc setup' to fix it (Starting libvirt service)",
+	},
+}
+
+func TestSetupCheckOnlyPlain(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runSetupCheckOnly(out, testCheckResults[:1], ""))
+	assert.Equal(t, `[PASS] Checking minimum RAM requirements
+Your system is correctly setup for using CodeReady Containers
+`, out.String())
+
+	out.Reset()
+	err := runSetupCheckOnly(out, testCheckResults, "")
+	assert.EqualError(t, err, "1 check(s) failed, run 'crc setup' to fix them")
+	assert.Equal(t, preflightFailedExitCode, err.(exec.CodeExitError).Code)
+	assert.Equal(t, `[PASS] Checking minimum RAM requirements
+[FAIL] Checking if libvirt daemon is running
+	error: libvirtd is not running
+	remediation: Run 'crc setup' to fix it (Starting libvirt service)
+`, out.String())
+}
+
+func TestSetupCheckOnlyJSON(t *testing.T) {
+	out := new(bytes.Buffer)
+	err := runSetupCheckOnly(out, testCheckResults, jsonFormat)
+	assert.Error(t, err)
+	assert.Equal(t, preflightFailedExitCode, err.(exec.CodeExitError).Code)
+	assert.JSONEq(t, `{
+  "success": false,
+  "error": "1 check(s) failed, run 'crc setup' to fix them",
+  "errorCode": "preflight",
+  "remediation": "Run 'crc setup' to prepare the host",
+  "docsURL": "https://code-ready.github.io/crc/#setting-up-codeready-containers_gsg",
+  "exitCode": 2,
+  "checks": [
+    {
+      "id": "check-ram",
+      "description": "Checking minimum RAM requirements",
+      "status": "pass",
+      "fixable": false
+    },
+    {
+      "id": "check-libvirt-running",
+      "description": "Checking if libvirt daemon is running",
+      "status": "fail",
+      "fixable": true,
+      "error": "libvirtd is not running",
+      "remediation": "Run 'crc setup' to fix it (Starting libvirt service)"
+    }
+  ]
+}`, out.String())
+}
+
+var testPrivilegedOperations = []preflight.PrivilegedOperation{
+	{
+		Description: "Add the cluster hostnames to /etc/hosts",
+		GrantedBy:   "SUID bit of the crc-admin-helper executable",
+		Commands:    []string{"start"},
+		Granted:     true,
+	},
+	{
+		Description: "Create the virtual machine",
+		GrantedBy:   "membership of the current user in the libvirt group",
+		Commands:    []string{"start", "delete"},
+		Granted:     false,
+		Error:       "user is not part of the libvirt group",
+	},
+}
+
+func TestVerifyLeastPrivilegePlain(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runVerifyLeastPrivilege(out, testPrivilegedOperations[:1], ""))
+	assert.Equal(t, `[OK] Add the cluster hostnames to /etc/hosts (start)
+	granted by: SUID bit of the crc-admin-helper executable
+start, stop and delete will not need administrator rights
+`, out.String())
+
+	out.Reset()
+	err := runVerifyLeastPrivilege(out, testPrivilegedOperations, "")
+	assert.EqualError(t, err, "1 operation(s) would need administrator rights, run 'crc setup' to grant them")
+	assert.Equal(t, preflightFailedExitCode, err.(exec.CodeExitError).Code)
+	assert.Equal(t, `[OK] Add the cluster hostnames to /etc/hosts (start)
+	granted by: SUID bit of the crc-admin-helper executable
+[NEEDS ADMIN] Create the virtual machine (start, delete)
+	granted by: membership of the current user in the libvirt group
+	error: user is not part of the libvirt group
+`, out.String())
+}
+
+func TestVerifyLeastPrivilegeJSON(t *testing.T) {
+	out := new(bytes.Buffer)
+	err := runVerifyLeastPrivilege(out, testPrivilegedOperations, jsonFormat)
+	assert.Error(t, err)
+	assert.Equal(t, preflightFailedExitCode, err.(exec.CodeExitError).Code)
+	assert.JSONEq(t, `{
+  "success": false,
+  "error": "1 operation(s) would need administrator rights, run 'crc setup' to grant them",
+  "errorCode": "preflight",
+  "remediation": "Run 'crc setup' to prepare the host",
+  "docsURL": "https://code-ready.github.io/crc/#setting-up-codeready-containers_gsg",
+  "exitCode": 2,
+  "operations": [
+    {
+      "description": "Add the cluster hostnames to /etc/hosts",
+      "grantedBy": "SUID bit of the crc-admin-helper executable",
+      "commands": ["start"],
+      "granted": true
+    },
+    {
+      "description": "Create the virtual machine",
+      "grantedBy": "membership of the current user in the libvirt group",
+      "commands": ["start", "delete"],
+      "granted": false,
+      "error": "user is not part of the libvirt group"
+    }
+  ]
+}`, out.String())
 }
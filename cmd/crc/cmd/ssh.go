@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	crcos "github.com/code-ready/crc/pkg/os"
+	"github.com/spf13/cobra"
+)
+
+var sshTTY bool
+
+func init() {
+	addOutputFormatFlag(sshCmd)
+	sshCmd.Flags().BoolVarP(&sshTTY, "tty", "t", false, "Allocate a pseudo-terminal for the command, one is always allocated for the login shell")
+	// the flags of the remote command are not parsed by crc
+	sshCmd.Flags().SetInterspersed(false)
+	addOutputFormatFlag(sshRotateKeyCmd)
+	sshCmd.AddCommand(sshRotateKeyCmd)
+	rootCmd.AddCommand(sshCmd)
+}
+
+var sshCmd = &cobra.Command{
+	Use:   "ssh [flags] [--] [COMMAND [ARGS...]]",
+	Short: "Open a shell or run a command in the instance",
+	Long: "Open a login shell in the instance, or run a command in it, using the SSH key created for the instance. " +
+		"With '--output json', the command output and exit status are printed as JSON.",
+	Example: `  crc ssh
+  crc ssh -- sudo crictl ps
+  crc ssh --output json -- cat /etc/os-release`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runner, err := runningSSHRunner(newMachine())
+		if err != nil {
+			return err
+		}
+		defer runner.Close()
+		return runSSH(os.Stdout, runner, args, sshTTY, outputFormat)
+	},
+}
+
+var sshRotateKeyCmd = &cobra.Command{
+	Use:   "rotate-key",
+	Short: "Replace the SSH key of the instance",
+	Long: "Replace the SSH key of the running instance with a new key, generated with the type of the ssh-key-type setting, " +
+		"or copied from the key of the ssh-key setting. The previous key stays in use when the instance cannot be reached with the new one.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSSHRotateKey(os.Stdout, newMachine(), outputFormat)
+	},
+}
+
+type sshRotateKeyResult struct {
+	Success bool `json:"success"`
+	errorResult
+	PrivateKey string `json:"privateKey,omitempty"`
+}
+
+func runSSHRotateKey(writer io.Writer, client machine.Client, outputFormat string) error {
+	err := checkIfMachineMissing(client)
+	if err == nil {
+		err = client.RotateSSHKey(context.Background())
+	}
+	result := &sshRotateKeyResult{
+		Success:     err == nil,
+		errorResult: newErrorResult(err),
+	}
+	if err == nil {
+		result.PrivateKey = constants.GetPrivateKeyPath()
+	}
+	return render(result, writer, outputFormat)
+}
+
+func (s *sshRotateKeyResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	_, err := fmt.Fprintf(writer, "Replaced the SSH key of the instance, its private key is %s\n", s.PrivateKey)
+	return err
+}
+
+// sshRunner is the subset of ssh.Runner used by 'crc ssh'
+type sshRunner interface {
+	Run(cmd string, args ...string) (string, string, error)
+	RunInteractive(command string, tty bool) (int, error)
+}
+
+type sshResult struct {
+	Success bool `json:"success"`
+	errorResult
+	ExitStatus int    `json:"exitStatus"`
+	Stdout     string `json:"stdout"`
+	Stderr     string `json:"stderr"`
+}
+
+func runSSH(writer io.Writer, runner sshRunner, args []string, tty bool, outputFormat string) error {
+	command := strings.Join(args, " ")
+	if !isMachineReadable(outputFormat) {
+		// a login shell is always interactive
+		status, err := runner.RunInteractive(command, tty || command == "")
+		if err != nil {
+			return err
+		}
+		if status != 0 {
+			return crcos.CodeExitError{Err: fmt.Errorf("Command exited with status %d", status), Code: status}
+		}
+		return nil
+	}
+
+	if command == "" {
+		return errors.New("A command is required with '--output json'")
+	}
+	stdout, stderr, err := runner.Run(command)
+	status, err := ssh.ExitStatus(err)
+	return render(&sshResult{
+		Success:     err == nil && status == 0,
+		errorResult: newErrorResult(err),
+		ExitStatus:  status,
+		Stdout:      stdout,
+		Stderr:      stderr,
+	}, writer, outputFormat)
+}
+
+func (s *sshResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	_, err := fmt.Fprint(writer, s.Stdout)
+	return err
+}
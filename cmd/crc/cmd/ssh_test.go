@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	crcos "github.com/code-ready/crc/pkg/os"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSSHRunner struct {
+	command string
+	tty     bool
+	status  int
+	files   map[string]string
+}
+
+func (r *fakeSSHRunner) Run(cmd string, args ...string) (string, string, error) {
+	r.command = cmd
+	return "Red Hat Enterprise Linux CoreOS\n", "", nil
+}
+
+func (r *fakeSSHRunner) RunInteractive(command string, tty bool) (int, error) {
+	r.command = command
+	r.tty = tty
+	return r.status, nil
+}
+
+func (r *fakeSSHRunner) CopyTo(src io.Reader, srcFilename string, destFilename string, mode os.FileMode) error {
+	data, err := ioutil.ReadAll(src)
+	if err != nil {
+		return err
+	}
+	r.files[destFilename] = string(data)
+	return nil
+}
+
+func (r *fakeSSHRunner) CopyFrom(srcFilename string, dest io.Writer) error {
+	data, ok := r.files[srcFilename]
+	if !ok {
+		return errors.New("No such file or directory")
+	}
+	_, err := io.WriteString(dest, data)
+	return err
+}
+
+func TestSSHShell(t *testing.T) {
+	runner := &fakeSSHRunner{}
+	assert.NoError(t, runSSH(new(bytes.Buffer), runner, nil, false, ""))
+	assert.Equal(t, "", runner.command)
+	assert.True(t, runner.tty)
+}
+
+func TestSSHCommandExitStatus(t *testing.T) {
+	runner := &fakeSSHRunner{status: 2}
+	err := runSSH(new(bytes.Buffer), runner, []string{"ls", "/missing"}, false, "")
+	var exitErr crcos.CodeExitError
+	require.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, 2, exitErr.ExitStatus())
+	assert.Equal(t, "ls /missing", runner.command)
+	assert.False(t, runner.tty)
+}
+
+func TestSSHJSON(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runSSH(out, &fakeSSHRunner{}, []string{"cat", "/etc/redhat-release"}, false, jsonFormat))
+	assert.JSONEq(t, `{"success": true, "exitStatus": 0, "stdout": "Red Hat Enterprise Linux CoreOS\n", "stderr": ""}`, out.String())
+
+	assert.EqualError(t, runSSH(out, &fakeSSHRunner{}, nil, false, jsonFormat), "A command is required with '--output json'")
+}
+
+func TestSSHRotateKey(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runSSHRotateKey(out, fakemachine.NewClient(), ""))
+	assert.Equal(t, fmt.Sprintf("Replaced the SSH key of the instance, its private key is %s\n", constants.GetPrivateKeyPath()), out.String())
+
+	out.Reset()
+	assert.NoError(t, runSSHRotateKey(out, fakemachine.NewClient(), jsonFormat))
+	assert.JSONEq(t, fmt.Sprintf(`{"success": true, "privateKey": %q}`, constants.GetPrivateKeyPath()), out.String())
+}
+
+func TestSSHRotateKeyFailing(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runSSHRotateKey(out, fakemachine.NewFailingClient(), ""), "The instance must be running to replace its SSH key")
+
+	out.Reset()
+	assert.Error(t, runSSHRotateKey(out, fakemachine.NewFailingClient(), jsonFormat))
+	assert.Contains(t, out.String(), `"error": "The instance must be running to replace its SSH key"`)
+}
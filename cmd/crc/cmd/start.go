@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
 	"text/template"
 
@@ -21,6 +22,7 @@ import (
 	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/crc/preflight"
 	"github.com/code-ready/crc/pkg/crc/preset"
+	"github.com/code-ready/crc/pkg/crc/selfupdate"
 	"github.com/code-ready/crc/pkg/crc/validation"
 	crcversion "github.com/code-ready/crc/pkg/crc/version"
 	crcos "github.com/code-ready/crc/pkg/os"
@@ -35,76 +37,249 @@ func init() {
 	addOutputFormatFlag(startCmd)
 
 	flagSet := pflag.NewFlagSet("start", pflag.ExitOnError)
-	flagSet.StringP(crcConfig.Bundle, "b", constants.GetDefaultBundlePath(crcConfig.GetPreset(config)), "The system bundle used to provision the instance")
+	flagSet.StringP(crcConfig.Bundle, "b", constants.GetDefaultBundlePath(crcConfig.GetPreset(config)), "The system bundle used to provision the instance, a file, an HTTP(S) URL or a docker:// registry reference")
 	flagSet.StringP(crcConfig.PullSecretFile, "p", "", fmt.Sprintf("File path of image pull secret (download from %s)", constants.CrcLandingPageURL))
-	flagSet.IntP(crcConfig.CPUs, "c", constants.GetDefaultCPUs(crcConfig.GetPreset(config)), "Number of CPU cores to allocate to the instance")
-	flagSet.IntP(crcConfig.Memory, "m", constants.GetDefaultMemory(crcConfig.GetPreset(config)), "MiB of memory to allocate to the instance")
-	flagSet.UintP(crcConfig.DiskSize, "d", constants.DefaultDiskSize, "Total size in GiB of the disk used by the instance")
-	flagSet.StringP(crcConfig.NameServer, "n", "", "IPv4 address of nameserver to use for the instance")
+	flagSet.Bool(crcConfig.PullSecretFromKeychain, false, "Move the pull secret to the OS credential store and only read it from there")
+	flagSet.StringP(crcConfig.CPUs, "c", strconv.Itoa(constants.GetDefaultCPUs(crcConfig.GetPreset(config))), fmt.Sprintf("Number of CPU cores to allocate to the instance, or '%s' to size it from the host capacity", crcConfig.AutoSize))
+	flagSet.StringP(crcConfig.Memory, "m", strconv.Itoa(constants.GetDefaultMemory(crcConfig.GetPreset(config))), fmt.Sprintf("Memory to allocate to the instance, in MiB without unit (like '12GiB'), or '%s' to size it from the host capacity", crcConfig.AutoSize))
+	flagSet.StringP(crcConfig.DiskSize, "d", strconv.Itoa(constants.DefaultDiskSize), "Total size of the disk used by the instance, in GiB without unit (like '80G')")
+	flagSet.Uint(crcConfig.Workers, 0, fmt.Sprintf("Number of worker nodes to create next to the control plane instance (experimental - at most %d, each one uses %d MiB of memory)", constants.MaxWorkers, constants.DefaultWorkerMemory))
+	flagSet.StringP(crcConfig.NameServer, "n", "", "Comma-separated list of nameservers to use for the instance (IP address, tls:// or https:// nameserver)")
 	flagSet.Bool(crcConfig.DisableUpdateCheck, false, "Don't check for update")
 
 	startCmd.Flags().AddFlagSet(flagSet)
+	_ = startCmd.RegisterFlagCompletionFunc(crcConfig.Bundle, completeBundles)
+	startCmd.Flags().BoolVar(&nonInteractive, "non-interactive", false, "Never prompt, and print a JSON document, or a YAML one with '--output yaml', describing the failure when the start fails")
+	startCmd.Flags().BoolVar(&offline, "offline", false, "Start without network access: skip the update check and telemetry, and fail if a required file is missing locally")
+	startCmd.Flags().BoolVar(&resume, "resume", false, "Finish a failed start of the running instance from the last completed provisioning phase")
+	startCmd.Flags().BoolVar(&freshStart, "fresh", false, "Remove the instance created by a failed start, with the entries of its cluster in the hosts file, and create it again")
+	startCmd.Flags().BoolVar(&startDryRun, "dry-run", false, "Validate the configuration and print what the start would do, without changing anything")
+	startCmd.Flags().StringVar(&applyDir, "apply", "", fmt.Sprintf("Directory of Kubernetes manifests, or of a kustomization, to apply when the cluster is ready (overrides %s)", crcConfig.StartupManifests))
+	_ = startCmd.MarkFlagDirname("apply")
 }
 
+var (
+	nonInteractive bool
+	offline        bool
+	resume         bool
+	freshStart     bool
+	applyDir       string
+	startDryRun    bool
+)
+
 var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start the instance",
-	Long:  "Start the instance",
+	Long:  "Start the instance, it is created when it does not exist.\n\n" + exitCodesHelp(),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := viper.BindFlagSet(cmd.Flags()); err != nil {
 			return err
 		}
-		return renderStartResult(runStart(cmd.Context()))
+		if startDryRun {
+			return runStartDryRun(os.Stdout, newMachine(), resume, outputFormat)
+		}
+		result, err := runStart(cmd.Context(), nonInteractive, offline, resume)
+		if nonInteractive && err != nil {
+			return renderStartFailure(os.Stdout, err, outputFormat)
+		}
+		return renderStartResult(result, err)
 	},
 }
 
-func runStart(ctx context.Context) (*types.StartResult, error) {
+func runStart(ctx context.Context, nonInteractive, offline, resume bool) (*types.StartResult, error) {
 	if err := validateStartFlags(); err != nil {
-		return nil, err
+		return nil, &startPhaseError{phase: validationPhase, err: crcErrors.WithClass(crcErrors.ValidationFailure, err)}
 	}
 
-	if err := checkIfNewVersionAvailable(config.Get(crcConfig.DisableUpdateCheck).AsBool()); err != nil {
-		logging.Debugf("Unable to find out if a new version is available: %v", err)
+	if offline {
+		segmentClient.Disable()
 	}
 
-	startConfig := types.StartConfig{
-		BundlePath:        config.Get(crcConfig.Bundle).AsString(),
-		Memory:            config.Get(crcConfig.Memory).AsInt(),
-		DiskSize:          config.Get(crcConfig.DiskSize).AsInt(),
-		CPUs:              config.Get(crcConfig.CPUs).AsInt(),
-		NameServer:        config.Get(crcConfig.NameServer).AsString(),
-		PullSecret:        cluster.NewInteractivePullSecretLoader(config),
-		KubeAdminPassword: config.Get(crcConfig.KubeAdminPassword).AsString(),
-		Preset:            crcConfig.GetPreset(config),
+	// the update check only prints a warning, it is not useful for
+	// unattended runs, and it would time out without network access
+	if !nonInteractive && !offline {
+		if err := checkIfNewVersionAvailable(config.Get(crcConfig.DisableUpdateCheck).AsBool()); err != nil {
+			logging.Debugf("Unable to find out if a new version is available: %v", err)
+		}
+	}
+
+	pullSecret := cluster.NewInteractivePullSecretLoader(config)
+	if nonInteractive {
+		pullSecret = cluster.NewNonInteractivePullSecretLoader(config, "")
+	}
+
+	startConfig := newStartConfig(pullSecret, resume)
+
+	if crcConfig.IsAutoSize(config.Get(crcConfig.CPUs).Value) {
+		logging.Infof("Using %d CPUs based on the host capacity", startConfig.CPUs)
+	}
+	if crcConfig.IsAutoSize(config.Get(crcConfig.Memory).Value) {
+		logging.Infof("Using %d MiB of memory based on the host capacity", startConfig.Memory)
 	}
 
 	client := newMachine()
 	isRunning, _ := client.IsRunning()
 
 	if !isRunning {
+		// fail early instead of failing after the instance is created
+		if nonInteractive && startConfig.Preset == preset.OpenShift {
+			if _, err := pullSecret.Value(); err != nil {
+				return nil, &startPhaseError{phase: pullSecretPhase, err: crcErrors.WithClass(crcErrors.PullSecretFailure, err)}
+			}
+		}
+
 		if err := checkDaemonStarted(); err != nil {
-			return nil, err
+			return nil, &startPhaseError{phase: daemonPhase, err: err}
+		}
+
+		if offline {
+			if err := preflight.CheckOfflineArtifacts(config); err != nil {
+				return nil, &startPhaseError{
+					phase: preflightPhase,
+					err: crcos.CodeExitError{
+						Err:  err,
+						Code: preflightFailedExitCode,
+					},
+				}
+			}
 		}
 
 		if err := preflight.StartPreflightChecks(config); err != nil {
-			return nil, crcos.CodeExitError{
-				Err:  err,
-				Code: preflightFailedExitCode,
+			return nil, &startPhaseError{
+				phase: preflightPhase,
+				err: crcos.CodeExitError{
+					Err:  err,
+					Code: preflightFailedExitCode,
+				},
 			}
 		}
 	}
 
-	return client.Start(ctx, startConfig)
+	result, err := client.Start(ctx, startConfig)
+	if err != nil {
+		return nil, &startPhaseError{phase: instancePhase, err: err}
+	}
+	return result, nil
+}
+
+// newStartConfig returns the configuration of the instance from the crc
+// settings and the start flags
+func newStartConfig(pullSecret cluster.PullSecretLoader, resume bool) types.StartConfig {
+	return types.StartConfig{
+		BundlePath:           config.Get(crcConfig.Bundle).AsString(),
+		Memory:               crcConfig.GetMemory(config),
+		DiskSize:             config.Get(crcConfig.DiskSize).AsInt(),
+		CPUs:                 crcConfig.GetCPUs(config),
+		NameServer:           config.Get(crcConfig.NameServer).AsString(),
+		PullSecret:           pullSecret,
+		KubeAdminPassword:    config.Get(crcConfig.KubeAdminPassword).AsString(),
+		Preset:               crcConfig.GetPreset(config),
+		Workers:              config.Get(crcConfig.Workers).AsInt(),
+		Worker:               workerConfig(config),
+		ClusterDomain:        config.Get(crcConfig.ClusterDomain).AsString(),
+		DualStack:            config.Get(crcConfig.EnableDualStack).AsBool(),
+		HostPorts:            crcConfig.GetHostPorts(config),
+		AlternativeHostPorts: config.Get(crcConfig.AlternativeHostPorts).AsBool(),
+		EnableGPU:            config.Get(crcConfig.EnableGPU).AsBool(),
+		EnableMonitoring:     config.Get(crcConfig.EnableClusterMonitoring).AsBool(),
+		PVPoolSize:           config.Get(crcConfig.PVPoolSize).AsInt(),
+		SharedDirs:           crcConfig.GetSharedDirs(config),
+		SharedDirPassword:    sharedDirPassword(),
+		ImageCache:           config.Get(crcConfig.EnableImageCache).AsBool(),
+		Operators:            crcConfig.GetEnableOperators(config),
+		Manifests:            startManifestsDir(),
+		ExtraIgnition:        config.Get(crcConfig.ExtraIgnition).AsString(),
+		HugePages:            config.Get(crcConfig.HugePages).AsInt(),
+		CgroupsVersion:       config.Get(crcConfig.CgroupsVersion).AsString(),
+		Sysctls:              crcConfig.GetSysctls(config),
+
+		NestedVirtualization:    config.Get(crcConfig.EnableNestedVirtualization).AsBool(),
+		EnableRosetta:           config.Get(crcConfig.EnableRosetta).AsBool(),
+		RotateKubeAdminPassword: config.Get(crcConfig.RotateKubeAdminPassword).AsBool(),
+		SkipKubeconfigUpdate:    !config.Get(crcConfig.UpdateKubeconfig).AsBool(),
+		Resume:                  resume,
+		Fresh:                   freshStart,
+	}
+}
+
+// sharedDirPassword returns the password of the current user from the OS
+// credential store, it is asked the first time the directories are shared
+// over SMB
+func sharedDirPassword() string {
+	if runtime.GOOS != "windows" || len(crcConfig.GetSharedDirs(config)) == 0 || crcConfig.GetVMDriver(config) == crcConfig.WSL2VMDriver {
+		return ""
+	}
+	password, err := cluster.LoadSharedDirPassword()
+	if err == nil {
+		return password
+	}
+	logging.Debugf("Cannot load the password of the shared directories from the credential store: %v", err)
+	password, err = cluster.PromptSharedDirPassword()
+	if err != nil {
+		logging.Debugf("Cannot ask the password of the shared directories: %v", err)
+		return ""
+	}
+	if err := cluster.StoreSharedDirPassword(password); err != nil {
+		logging.Warnf("Cannot add the password of the shared directories to the credential store: %v", err)
+	}
+	return password
+}
+
+// startManifestsDir returns the directory of the --apply flag, or of the
+// startup-manifests setting
+func startManifestsDir() string {
+	if applyDir != "" {
+		return applyDir
+	}
+	return config.Get(crcConfig.StartupManifests).AsString()
 }
 
 func renderStartResult(result *types.StartResult, err error) error {
 	return render(&startResult{
 		Success:       err == nil,
-		Error:         crcErrors.ToSerializableError(err),
+		errorResult:   newErrorResult(err),
 		ClusterConfig: toClusterConfig(result),
+		Operators:     toOperators(result),
+		Manifests:     toManifests(result),
 	}, os.Stdout, outputFormat)
 }
 
+func toManifests(result *types.StartResult) *manifestsResult {
+	if result == nil || result.Manifests == nil {
+		return nil
+	}
+	manifests := &manifestsResult{
+		Directory: result.Manifests.Directory,
+		Error:     result.Manifests.Error,
+	}
+	for _, resource := range result.Manifests.Resources {
+		manifests.Resources = append(manifests.Resources, appliedResource{
+			Kind:      resource.Kind,
+			Namespace: resource.Namespace,
+			Name:      resource.Name,
+			Ready:     resource.Ready,
+			Error:     resource.Error,
+		})
+	}
+	return manifests
+}
+
+func toOperators(result *types.StartResult) []operatorResult {
+	if result == nil {
+		return nil
+	}
+	var operators []operatorResult
+	for _, operator := range result.Operators {
+		operators = append(operators, operatorResult{
+			Name:  operator.Name,
+			CSV:   operator.CSV,
+			Ready: operator.Ready,
+			Error: operator.Error,
+		})
+	}
+	return operators
+}
+
 func toClusterConfig(result *types.StartResult) *clusterConfig {
 	if result == nil {
 		return nil
@@ -137,12 +312,36 @@ type clusterConfig struct {
 type credentials struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	Token    string `json:"token,omitempty"`
+}
+
+type operatorResult struct {
+	Name  string `json:"name"`
+	CSV   string `json:"csv,omitempty"`
+	Ready bool   `json:"ready"`
+	Error string `json:"error,omitempty"`
+}
+
+type appliedResource struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Ready     bool   `json:"ready"`
+	Error     string `json:"error,omitempty"`
+}
+
+type manifestsResult struct {
+	Directory string            `json:"directory"`
+	Resources []appliedResource `json:"resources,omitempty"`
+	Error     string            `json:"error,omitempty"`
 }
 
 type startResult struct {
-	Success       bool                         `json:"success"`
-	Error         *crcErrors.SerializableError `json:"error,omitempty"`
-	ClusterConfig *clusterConfig               `json:"clusterConfig,omitempty"`
+	Success bool `json:"success"`
+	errorResult
+	ClusterConfig *clusterConfig   `json:"clusterConfig,omitempty"`
+	Operators     []operatorResult `json:"operators,omitempty"`
+	Manifests     *manifestsResult `json:"manifests,omitempty"`
 }
 
 func (s *startResult) prettyPrintTo(writer io.Writer) error {
@@ -160,6 +359,12 @@ func (s *startResult) prettyPrintTo(writer io.Writer) error {
 	if err := writeTemplatedMessage(writer, s); err != nil {
 		return err
 	}
+	if err := writeOperators(writer, s.Operators); err != nil {
+		return err
+	}
+	if err := writeManifests(writer, s.Manifests); err != nil {
+		return err
+	}
 	if crcversion.IsOkdBuild() {
 		_, err := fmt.Fprintln(writer, strings.Join([]string{
 			"",
@@ -172,10 +377,23 @@ func (s *startResult) prettyPrintTo(writer io.Writer) error {
 }
 
 func validateStartFlags() error {
-	if err := validation.ValidateMemory(config.Get(crcConfig.Memory).AsInt(), crcConfig.GetPreset(config)); err != nil {
+	if resume && freshStart {
+		return errors.New("--resume and --fresh cannot be used together")
+	}
+	for _, key := range []string{crcConfig.Memory, crcConfig.DiskSize} {
+		if config.Get(key).Invalid {
+			return fmt.Errorf("Invalid value for %s, use a size like '12GiB' or '80G'", key)
+		}
+	}
+	if err := validation.ValidateMemory(crcConfig.GetMemory(config), crcConfig.GetPreset(config)); err != nil {
 		return err
 	}
-	if err := validation.ValidateCPUs(config.Get(crcConfig.CPUs).AsInt(), crcConfig.GetPreset(config)); err != nil {
+	if crcConfig.GetPreset(config) == preset.OpenShift && config.Get(crcConfig.EnableClusterMonitoring).AsBool() {
+		if err := validation.ValidateMonitoringMemory(crcConfig.GetMemory(config)); err != nil {
+			return fmt.Errorf("%s is enabled, it %s", crcConfig.EnableClusterMonitoring, err)
+		}
+	}
+	if err := validation.ValidateCPUs(crcConfig.GetCPUs(config), crcConfig.GetPreset(config)); err != nil {
 		return err
 	}
 	if err := validation.ValidateDiskSize(config.Get(crcConfig.DiskSize).AsInt()); err != nil {
@@ -184,9 +402,18 @@ func validateStartFlags() error {
 	if err := validation.ValidateBundle(config.Get(crcConfig.Bundle).AsString(), crcConfig.GetPreset(config)); err != nil {
 		return err
 	}
-	if config.Get(crcConfig.NameServer).AsString() != "" {
-		if err := validation.ValidateIPAddress(config.Get(crcConfig.NameServer).AsString()); err != nil {
-			return err
+	if _, err := network.ParseNameServers(config.Get(crcConfig.NameServer).AsString()); err != nil {
+		return err
+	}
+	if ok, msg := crcConfig.ValidateWorkers(config.Get(crcConfig.Workers).Value, crcConfig.GetPreset(config), crcConfig.GetNetworkMode(config)); !ok {
+		return fmt.Errorf("Invalid number of worker nodes: %s", msg)
+	}
+	if dir := startManifestsDir(); dir != "" {
+		if crcConfig.GetPreset(config) != preset.OpenShift {
+			return fmt.Errorf("Manifests can only be applied with the %s preset", preset.OpenShift)
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return fmt.Errorf("%s is not a directory of manifests", dir)
 		}
 	}
 	return nil
@@ -201,6 +428,10 @@ func checkIfNewVersionAvailable(noUpdateCheck bool) error {
 		return err
 	}
 	if isNewVersionAvailable {
+		if selfupdate.Supported() == nil {
+			logging.Warnf("A new version (%s) has been published on %s, run 'crc self-update' to install it", newVersion, link)
+			return nil
+		}
 		logging.Warnf("A new version (%s) has been published on %s", newVersion, link)
 		return nil
 	}
@@ -209,7 +440,7 @@ func checkIfNewVersionAvailable(noUpdateCheck bool) error {
 }
 
 func newVersionAvailable() (bool, string, string, error) {
-	release, err := crcversion.GetCRCLatestVersionFromMirror(network.HTTPTransport())
+	release, err := crcversion.GetCRCLatestVersionFromMirror(network.HTTPTransport(), config.Get(crcConfig.UpdateChannel).AsString())
 	if err != nil {
 		return false, "", "", err
 	}
@@ -305,6 +536,45 @@ func writePodmanTemplatedMessage(writer io.Writer, s *startResult) error {
 	})
 }
 
+func writeOperators(writer io.Writer, operators []operatorResult) error {
+	if len(operators) == 0 {
+		return nil
+	}
+	lines := []string{"", "Operators:"}
+	for _, operator := range operators {
+		if operator.Ready {
+			lines = append(lines, fmt.Sprintf("  %s: installed (%s)", operator.Name, operator.CSV))
+		} else {
+			lines = append(lines, fmt.Sprintf("  %s: not installed, %s", operator.Name, operator.Error))
+		}
+	}
+	_, err := fmt.Fprintln(writer, strings.Join(lines, "\n"))
+	return err
+}
+
+func writeManifests(writer io.Writer, manifests *manifestsResult) error {
+	if manifests == nil {
+		return nil
+	}
+	lines := []string{"", fmt.Sprintf("Manifests of %s:", manifests.Directory)}
+	if manifests.Error != "" {
+		lines = append(lines, "  "+manifests.Error)
+	}
+	for _, resource := range manifests.Resources {
+		name := fmt.Sprintf("%s/%s", strings.ToLower(resource.Kind), resource.Name)
+		if resource.Namespace != "" {
+			name = fmt.Sprintf("%s (%s)", name, resource.Namespace)
+		}
+		if resource.Ready {
+			lines = append(lines, fmt.Sprintf("  %s: applied", name))
+		} else {
+			lines = append(lines, fmt.Sprintf("  %s: not ready, %s", name, resource.Error))
+		}
+	}
+	_, err := fmt.Fprintln(writer, strings.Join(lines, "\n"))
+	return err
+}
+
 func commandLinePrefix(shell string) string {
 	if runtime.GOOS == "windows" {
 		if shell == "powershell" {
@@ -315,7 +585,7 @@ func commandLinePrefix(shell string) string {
 	return "$"
 }
 
-const genericDaemonNotRunningMessage = "Is 'crc daemon' running? Cannot reach daemon API"
+const genericDaemonNotRunningMessage = "Cannot reach daemon API, run 'crc daemon install' to start the daemon automatically, or run 'crc daemon' in another terminal"
 
 func checkDaemonStarted() error {
 	if crcConfig.GetNetworkMode(config) == network.SystemNetworkingMode {
@@ -324,10 +594,17 @@ func checkDaemonStarted() error {
 	daemonClient := daemonclient.New()
 	version, err := daemonClient.APIClient.Version()
 	if err != nil {
-		return pkgerrors.Wrap(err, daemonNotRunningMessage())
+		logging.Debugf("Cannot reach the daemon API: %v", err)
+		if version, err = startInstalledDaemon(daemonClient); err != nil {
+			return crcErrors.WithClass(crcErrors.DaemonUnreachable, pkgerrors.Wrap(err, daemonNotRunningMessage()))
+		}
 	}
 	if version.CrcVersion != crcversion.GetCRCVersion() {
-		return fmt.Errorf("The executable version (%s) doesn't match the daemon version (%s)", crcversion.GetCRCVersion(), version.CrcVersion)
+		logging.Infof("The daemon version (%s) doesn't match the executable version (%s)", version.CrcVersion, crcversion.GetCRCVersion())
+		if _, err := restartOutdatedDaemon(daemonClient); err != nil {
+			return crcErrors.WithClass(crcErrors.DaemonUnreachable, fmt.Errorf("The executable version (%s) doesn't match the daemon version (%s), and the daemon cannot be restarted: %w",
+				crcversion.GetCRCVersion(), version.CrcVersion, err))
+		}
 	}
 	return nil
 }
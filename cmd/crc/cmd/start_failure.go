@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
+	"k8s.io/client-go/util/exec"
+	"sigs.k8s.io/yaml"
+)
+
+type startPhase string
+
+const (
+	validationPhase startPhase = "validation"
+	pullSecretPhase startPhase = "pull-secret"
+	daemonPhase     startPhase = "daemon"
+	preflightPhase  startPhase = "preflight"
+	instancePhase   startPhase = "start"
+)
+
+// startPhaseExitCodes are the exit codes of 'crc start' for each phase, when
+// the class of the failure is not known
+var startPhaseExitCodes = map[startPhase]int{
+	validationPhase: validationFailedExitCode,
+	pullSecretPhase: pullSecretFailedExitCode,
+	daemonPhase:     daemonFailedExitCode,
+	preflightPhase:  preflightFailedExitCode,
+	instancePhase:   startFailedExitCode,
+}
+
+var startPhaseRemediations = map[startPhase]string{
+	validationPhase: "Check the values of the flags and of the settings listed by 'crc config view'",
+	pullSecretPhase: fmt.Sprintf("Use --pull-secret-file or the %s_PULL_SECRET_FILE environment variable to provide the pull secret", constants.CrcEnvPrefix),
+	daemonPhase:     "Start the daemon with 'crc daemon', or run 'crc setup' to install it",
+	instancePhase:   "Check the logs with 'crc start --log-level debug', and run 'crc delete' if the instance cannot be recovered",
+}
+
+// startPhaseError records the phase of 'crc start' which failed
+type startPhaseError struct {
+	phase startPhase
+	err   error
+}
+
+func (e *startPhaseError) Error() string {
+	return e.err.Error()
+}
+
+func (e *startPhaseError) Unwrap() error {
+	return e.err
+}
+
+type startFailure struct {
+	Success     bool                         `json:"success"`
+	Phase       startPhase                   `json:"phase"`
+	Class       crcErrors.Class              `json:"class,omitempty"`
+	Check       string                       `json:"check,omitempty"`
+	Error       *crcErrors.SerializableError `json:"error"`
+	ErrorCode   string                       `json:"errorCode,omitempty"`
+	Remediation string                       `json:"remediation,omitempty"`
+	DocsURL     string                       `json:"docsURL,omitempty"`
+	ExitCode    int                          `json:"exitCode"`
+}
+
+func newStartFailure(err error) *startFailure {
+	failure := &startFailure{
+		Success:  false,
+		Phase:    instancePhase,
+		Class:    crcErrors.ClassOf(err),
+		Error:    crcErrors.ToSerializableError(err),
+		ExitCode: exitCodeOf(err),
+	}
+	var phaseErr *startPhaseError
+	if errors.As(err, &phaseErr) {
+		failure.Phase = phaseErr.phase
+	}
+	failure.Remediation = startPhaseRemediations[failure.Phase]
+	var preflightErr *crcErrors.PreflightError
+	if errors.As(err, &preflightErr) {
+		failure.Check = preflightErr.CheckID
+	}
+	if details := failure.Error.Details(); details != nil {
+		failure.ErrorCode = details.Code
+		failure.Remediation = details.Remediation
+		failure.DocsURL = details.DocsURL
+	}
+	return failure
+}
+
+// renderStartFailure prints a single document describing the failure, in YAML
+// with the yaml output format and in JSON with the other formats, and returns
+// an error with the exit code of the failing phase
+func renderStartFailure(writer io.Writer, err error, outputFormat string) error {
+	failure := newStartFailure(err)
+	if outputFormat == yamlFormat {
+		out, encodeErr := yaml.Marshal(failure)
+		if encodeErr != nil {
+			return encodeErr
+		}
+		if _, encodeErr := writer.Write(out); encodeErr != nil {
+			return encodeErr
+		}
+	} else {
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		if encodeErr := encoder.Encode(failure); encodeErr != nil {
+			return encodeErr
+		}
+	}
+	return exec.CodeExitError{
+		Err:  err,
+		Code: failure.ExitCode,
+	}
+}
@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/code-ready/crc/pkg/crc/preflight"
+	"github.com/code-ready/crc/pkg/crc/preset"
+)
+
+// The actions of a start on the instance
+const (
+	planCreateAction = "create"
+	planStartAction  = "start"
+	planResumeAction = "resume"
+	planNoAction     = "none"
+)
+
+type bundlePlan struct {
+	Path string `json:"path"`
+	Name string `json:"name,omitempty"`
+	// Version is only known once the bundle is extracted
+	Version    string `json:"version,omitempty"`
+	Downloaded bool   `json:"downloaded"`
+	Extracted  bool   `json:"extracted"`
+}
+
+type sizingPlan struct {
+	CPUs         int  `json:"cpus"`
+	Memory       int  `json:"memory"`
+	DiskSize     int  `json:"diskSize"`
+	AutoCPUs     bool `json:"autoCPUs,omitempty"`
+	AutoMemory   bool `json:"autoMemory,omitempty"`
+	Workers      int  `json:"workers"`
+	WorkerCPUs   int  `json:"workerCPUs,omitempty"`
+	WorkerMemory int  `json:"workerMemory,omitempty"`
+}
+
+type networkPlan struct {
+	Mode          string `json:"mode"`
+	VMDriver      string `json:"vmDriver"`
+	NameServer    string `json:"nameServer,omitempty"`
+	ClusterDomain string `json:"clusterDomain,omitempty"`
+	DualStack     bool   `json:"dualStack,omitempty"`
+}
+
+// startPlanResult is what 'crc start --dry-run' prints, nothing is changed
+// to compute it
+type startPlanResult struct {
+	Success bool `json:"success"`
+	errorResult
+	Action  string        `json:"action,omitempty"`
+	Preset  preset.Preset `json:"preset,omitempty"`
+	Bundle  *bundlePlan   `json:"bundle,omitempty"`
+	Sizing  *sizingPlan   `json:"sizing,omitempty"`
+	Network *networkPlan  `json:"network,omitempty"`
+	// PreflightChecks are not run when the instance is already running
+	PreflightChecks []preflight.PlannedCheck `json:"preflightChecks,omitempty"`
+	Files           []string                 `json:"files,omitempty"`
+}
+
+func runStartDryRun(writer io.Writer, client machine.Client, resume bool, outputFormat string) error {
+	plan, err := planStart(client, resume)
+	if err != nil {
+		plan = &startPlanResult{}
+	}
+	plan.Success = err == nil
+	plan.errorResult = newErrorResult(err)
+	return render(plan, writer, outputFormat)
+}
+
+// planStart resolves the configuration and validates it like runStart
+func planStart(client machine.Client, resume bool) (*startPlanResult, error) {
+	if err := validateStartFlags(); err != nil {
+		return nil, err
+	}
+	// the pull secret is not read, a dry run never prompts
+	startConfig := newStartConfig(cluster.NewNonInteractivePullSecretLoader(config, ""), resume)
+
+	exists, err := client.Exists()
+	if err != nil {
+		return nil, err
+	}
+	running := false
+	if exists {
+		if running, err = client.IsRunning(); err != nil {
+			return nil, err
+		}
+	}
+	action := planStartAction
+	switch {
+	case !exists:
+		action = planCreateAction
+	case running && resume:
+		action = planResumeAction
+	case running:
+		action = planNoAction
+	}
+
+	bundleInfo := &bundlePlan{Path: startConfig.BundlePath}
+	var bundleDir string
+	if localPath, ok := bundle.LocalPath(startConfig.BundlePath); ok {
+		bundleInfo.Downloaded = true
+		bundleInfo.Name = bundle.GetBundleNameWithoutExtension(filepath.Base(localPath))
+		if metadata, err := bundle.Get(bundleInfo.Name); err == nil {
+			bundleInfo.Extracted = true
+			bundleInfo.Version = metadata.GetOpenshiftVersion()
+			if !metadata.IsOpenShift() {
+				bundleInfo.Version = metadata.GetPodmanVersion()
+			}
+		} else {
+			bundleDir = filepath.Join(constants.MachineCacheDir, bundleInfo.Name)
+		}
+	}
+
+	plan := &startPlanResult{
+		Action: action,
+		Preset: startConfig.Preset,
+		Bundle: bundleInfo,
+		Sizing: &sizingPlan{
+			CPUs:       startConfig.CPUs,
+			Memory:     startConfig.Memory,
+			DiskSize:   startConfig.DiskSize,
+			AutoCPUs:   crcConfig.IsAutoSize(config.Get(crcConfig.CPUs).Value),
+			AutoMemory: crcConfig.IsAutoSize(config.Get(crcConfig.Memory).Value),
+			Workers:    startConfig.Workers,
+		},
+		Network: &networkPlan{
+			Mode:          crcConfig.GetNetworkMode(config).String(),
+			VMDriver:      crcConfig.GetVMDriver(config),
+			NameServer:    startConfig.NameServer,
+			ClusterDomain: startConfig.ClusterDomain,
+			DualStack:     startConfig.DualStack,
+		},
+		Files: []string{},
+	}
+	if startConfig.Workers > 0 {
+		plan.Sizing.WorkerCPUs = startConfig.Worker.CPUs
+		plan.Sizing.WorkerMemory = startConfig.Worker.Memory
+	}
+	if action != planNoAction {
+		plan.PreflightChecks = preflight.PlanStartChecks(config)
+		plan.Files = machine.StartFiles(client.GetName(), startConfig, exists, bundleDir)
+	}
+	return plan, nil
+}
+
+func (s *startPlanResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	if _, err := fmt.Fprintln(writer, "Dry run, nothing was changed"); err != nil {
+		return err
+	}
+	if s.Action == planNoAction {
+		_, err := fmt.Fprintln(writer, "The instance is already running, 'crc start' would not change it")
+		return err
+	}
+
+	w := tabwriter.NewWriter(writer, 0, 0, 1, ' ', 0)
+	lines := [][]string{
+		{"Action", s.actionLine()},
+		{"Preset", string(s.Preset)},
+		{"Bundle", s.bundleLine()},
+		{"CPUs", sizeLine(fmt.Sprint(s.Sizing.CPUs), s.Sizing.AutoCPUs)},
+		{"Memory", sizeLine(fmt.Sprintf("%d MiB", s.Sizing.Memory), s.Sizing.AutoMemory)},
+		{"Disk size", fmt.Sprintf("%d GiB", s.Sizing.DiskSize)},
+	}
+	if s.Sizing.Workers > 0 {
+		lines = append(lines, []string{"Workers", fmt.Sprintf("%d (%d CPUs, %d MiB of memory each)", s.Sizing.Workers, s.Sizing.WorkerCPUs, s.Sizing.WorkerMemory)})
+	}
+	lines = append(lines, []string{"Network mode", s.Network.Mode}, []string{"VM driver", s.Network.VMDriver})
+	if s.Network.NameServer != "" {
+		lines = append(lines, []string{"Nameservers", s.Network.NameServer})
+	}
+	if s.Network.ClusterDomain != "" {
+		lines = append(lines, []string{"Cluster domain", s.Network.ClusterDomain})
+	}
+	if s.Network.DualStack {
+		lines = append(lines, []string{"Dual stack", "enabled"})
+	}
+	for _, line := range lines {
+		if err := printLine(w, line[0], line[1]); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(s.PreflightChecks) > 0 {
+		if _, err := fmt.Fprintln(writer, "\nPreflight checks:"); err != nil {
+			return err
+		}
+		for _, check := range s.PreflightChecks {
+			description := check.Description
+			if check.Skipped {
+				description += " (skipped)"
+			} else if check.Warn {
+				description += " (warning only)"
+			}
+			if _, err := fmt.Fprintf(writer, "  %s\n", description); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := fmt.Fprintln(writer, "\nFiles written:"); err != nil {
+		return err
+	}
+	for _, file := range s.Files {
+		if _, err := fmt.Fprintf(writer, "  %s\n", file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *startPlanResult) actionLine() string {
+	switch s.Action {
+	case planCreateAction:
+		return "create and start the instance"
+	case planResumeAction:
+		return "resume the failed start of the running instance"
+	default:
+		return "start the existing instance"
+	}
+}
+
+func (s *startPlanResult) bundleLine() string {
+	var state []string
+	if s.Bundle.Name != "" {
+		state = append(state, s.Bundle.Name)
+	}
+	if s.Bundle.Version != "" {
+		state = append(state, s.Bundle.Version)
+	}
+	switch {
+	case !s.Bundle.Downloaded:
+		state = append(state, "downloaded by the preflight checks")
+	case !s.Bundle.Extracted:
+		state = append(state, "extracted at start")
+	}
+	return fmt.Sprintf("%s (%s)", s.Bundle.Path, strings.Join(state, ", "))
+}
+
+func sizeLine(value string, auto bool) string {
+	if auto {
+		return value + " (from the host capacity)"
+	}
+	return value
+}
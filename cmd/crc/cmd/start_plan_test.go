@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/preflight"
+	"github.com/code-ready/crc/pkg/crc/preset"
+	"github.com/stretchr/testify/assert"
+)
+
+func samplePlan() *startPlanResult {
+	return &startPlanResult{
+		Success: true,
+		Action:  planCreateAction,
+		Preset:  preset.OpenShift,
+		Bundle: &bundlePlan{
+			Path:       "/home/user/.crc/cache/crc_libvirt_4.10.3_amd64.crcbundle",
+			Name:       "crc_libvirt_4.10.3_amd64",
+			Downloaded: true,
+		},
+		Sizing: &sizingPlan{
+			CPUs:       4,
+			Memory:     9216,
+			DiskSize:   31,
+			AutoMemory: true,
+		},
+		Network: &networkPlan{
+			Mode:     "system",
+			VMDriver: "libvirt",
+		},
+		PreflightChecks: []preflight.PlannedCheck{
+			{ID: "check-root-user", Description: "Checking if running as non-root"},
+			{ID: "check-ram", Description: "Checking minimum RAM requirements", Warn: true},
+		},
+		Files: []string{"/home/user/.crc/machines/crc", "/home/user/.kube/config"},
+	}
+}
+
+func TestStartPlanPlainOutput(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, render(samplePlan(), out, ""))
+	assert.Equal(t, `Dry run, nothing was changed
+Action:       create and start the instance
+Preset:       openshift
+Bundle:       /home/user/.crc/cache/crc_libvirt_4.10.3_amd64.crcbundle (crc_libvirt_4.10.3_amd64, extracted at start)
+CPUs:         4
+Memory:       9216 MiB (from the host capacity)
+Disk size:    31 GiB
+Network mode: system
+VM driver:    libvirt
+
+Preflight checks:
+  Checking if running as non-root
+  Checking minimum RAM requirements (warning only)
+
+Files written:
+  /home/user/.crc/machines/crc
+  /home/user/.kube/config
+`, out.String())
+}
+
+func TestStartPlanAlreadyRunning(t *testing.T) {
+	plan := samplePlan()
+	plan.Action = planNoAction
+	out := new(bytes.Buffer)
+	assert.NoError(t, render(plan, out, ""))
+	assert.Equal(t, "Dry run, nothing was changed\nThe instance is already running, 'crc start' would not change it\n", out.String())
+}
+
+func TestStartPlanJSONOutput(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, render(samplePlan(), out, jsonFormat))
+	assert.Contains(t, out.String(), `"action": "create"`)
+	assert.Contains(t, out.String(), `"vmDriver": "libvirt"`)
+	assert.Contains(t, out.String(), `"warn": true`)
+}
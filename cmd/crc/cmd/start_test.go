@@ -3,6 +3,7 @@ package cmd
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"runtime"
 	"testing"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/code-ready/crc/pkg/crc/preset"
 	"github.com/code-ready/crc/pkg/os/shell"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/util/exec"
 )
 
 const (
@@ -54,8 +56,8 @@ func TestRenderActionPlainFailure(t *testing.T) {
 	out := new(bytes.Buffer)
 	err := errors.New("broken")
 	assert.EqualError(t, render(&startResult{
-		Success: false,
-		Error:   crcErrors.ToSerializableError(err),
+		Success:     false,
+		errorResult: newErrorResult(err),
 	}, out, ""), "broken")
 	assert.Equal(t, "", out.String())
 }
@@ -98,13 +100,45 @@ func TestRenderActionJSONSuccess(t *testing.T) {
 `, out.String())
 }
 
-func TestRenderActionJSONFailure(t *testing.T) {
+func TestRenderActionYAML(t *testing.T) {
 	out := new(bytes.Buffer)
 	assert.NoError(t, render(&startResult{
-		Success: false,
-		Error:   crcErrors.ToSerializableError(errors.New("broken")),
-	}, out, jsonFormat))
-	assert.JSONEq(t, `{"success": false, "error": "broken"}`, out.String())
+		Success: true,
+		ClusterConfig: &clusterConfig{
+			ClusterType:   preset.Podman,
+			WebConsoleURL: defaultWebConsoleURL,
+			URL:           defaultAPIURL,
+		},
+	}, out, yamlFormat))
+	assert.Equal(t, `clusterConfig:
+  adminCredentials:
+    password: ""
+    username: ""
+  cacert: ""
+  clusterType: podman
+  developerCredentials:
+    password: ""
+    username: ""
+  url: https://api.crc.testing:6443
+  webConsoleUrl: https://console-openshift-console.apps-crc.testing
+success: true
+`, out.String())
+
+	out.Reset()
+	assert.EqualError(t, render(&startResult{
+		Success:     false,
+		errorResult: newErrorResult(errors.New("broken")),
+	}, out, yamlFormat), "broken")
+	assert.Equal(t, "error: broken\nexitCode: 1\nsuccess: false\n", out.String())
+}
+
+func TestRenderActionJSONFailure(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, render(&startResult{
+		Success:     false,
+		errorResult: newErrorResult(errors.New("broken")),
+	}, out, jsonFormat), "broken")
+	assert.JSONEq(t, `{"success": false, "error": "broken", "exitCode": 1}`, out.String())
 }
 
 const unixTemplate = `Started the OpenShift cluster.
@@ -170,3 +204,117 @@ func expectedTemplate(shell string) string {
 	}
 	return unixTemplate
 }
+
+func TestRenderStartFailurePreflight(t *testing.T) {
+	out := new(bytes.Buffer)
+	err := renderStartFailure(out, &startPhaseError{
+		phase: preflightPhase,
+		err: &crcErrors.PreflightError{
+			Err:         errors.New("libvirt is not installed"),
+			CheckID:     "check-libvirt-installed",
+			Remediation: "Run 'crc setup' to fix it (Installing libvirt service and dependencies)",
+		},
+	}, jsonFormat)
+	var exitErr exec.CodeExitError
+	assert.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, preflightFailedExitCode, exitErr.ExitStatus())
+	assert.JSONEq(t, `{
+  "success": false,
+  "phase": "preflight",
+  "check": "check-libvirt-installed",
+  "error": "libvirt is not installed",
+  "errorCode": "check-libvirt-installed",
+  "remediation": "Run 'crc setup' to fix it (Installing libvirt service and dependencies)",
+  "docsURL": "https://code-ready.github.io/crc/#setting-up-codeready-containers_gsg",
+  "exitCode": 2
+}`, out.String())
+}
+
+func TestRenderStartFailureDaemon(t *testing.T) {
+	out := new(bytes.Buffer)
+	err := renderStartFailure(out, &startPhaseError{
+		phase: daemonPhase,
+		err:   errors.New("daemon is not running"),
+	}, jsonFormat)
+	var exitErr exec.CodeExitError
+	assert.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, daemonFailedExitCode, exitErr.ExitStatus())
+	assert.JSONEq(t, `{
+  "success": false,
+  "phase": "daemon",
+  "error": "daemon is not running",
+  "remediation": "Start the daemon with 'crc daemon', or run 'crc setup' to install it",
+  "exitCode": 5
+}`, out.String())
+}
+
+func TestRenderStartFailureBundle(t *testing.T) {
+	out := new(bytes.Buffer)
+	err := renderStartFailure(out, &startPhaseError{
+		phase: instancePhase,
+		err:   fmt.Errorf("Error getting bundle metadata: %w", crcErrors.WithClass(crcErrors.BundleFailure, errors.New("corrupted"))),
+	}, jsonFormat)
+	var exitErr exec.CodeExitError
+	assert.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, bundleFailedExitCode, exitErr.ExitStatus())
+	assert.JSONEq(t, `{
+  "success": false,
+  "phase": "start",
+  "class": "bundle",
+  "error": "Error getting bundle metadata: corrupted",
+  "errorCode": "bundle",
+  "remediation": "Run 'crc setup' to download the bundle of this release, and 'crc delete' when the instance uses another bundle",
+  "docsURL": "https://code-ready.github.io/crc/#troubleshooting-bundle-version-mismatch_gsg",
+  "exitCode": 7
+}`, out.String())
+}
+
+func TestRenderStartFailureYAML(t *testing.T) {
+	out := new(bytes.Buffer)
+	err := renderStartFailure(out, &startPhaseError{
+		phase: daemonPhase,
+		err:   errors.New("daemon is not running"),
+	}, yamlFormat)
+	var exitErr exec.CodeExitError
+	assert.ErrorAs(t, err, &exitErr)
+	assert.Equal(t, daemonFailedExitCode, exitErr.ExitStatus())
+	assert.Equal(t, `error: daemon is not running
+exitCode: 5
+phase: daemon
+remediation: Start the daemon with 'crc daemon', or run 'crc setup' to install it
+success: false
+`, out.String())
+}
+
+func TestWriteOperators(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, writeOperators(out, []operatorResult{
+		{Name: "serverless-operator", CSV: "serverless-operator.v1.21.0", Ready: true},
+		{Name: "unknown", Error: "The operator unknown is not in the catalogs of the cluster"},
+	}))
+	assert.Equal(t, `
+Operators:
+  serverless-operator: installed (serverless-operator.v1.21.0)
+  unknown: not installed, The operator unknown is not in the catalogs of the cluster
+`, out.String())
+}
+
+func TestWriteManifests(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, writeManifests(out, &manifestsResult{
+		Directory: "/home/user/manifests",
+		Resources: []appliedResource{
+			{Kind: "Namespace", Name: "demo", Ready: true},
+			{Kind: "Deployment", Namespace: "demo", Name: "web", Error: "The rollout did not complete: timed out"},
+		},
+	}))
+	assert.Equal(t, `
+Manifests of /home/user/manifests:
+  namespace/demo: applied
+  deployment/web (demo): not ready, The rollout did not complete: timed out
+`, out.String())
+
+	out.Reset()
+	assert.NoError(t, writeManifests(out, nil))
+	assert.Empty(t, out.String())
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/stats"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addOutputFormatFlag(statsCmd)
+	rootCmd.AddCommand(statsCmd)
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show the duration of the phases of the last start",
+	Long: fmt.Sprintf("Compare the duration of the phases of the last successful start to the previous starts "+
+		"recorded in %s, and show the phases which got slower and the cluster operators which are often "+
+		"the last to be ready", constants.StatsPath),
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runStats(os.Stdout, stats.NewStore(constants.StatsPath), outputFormat)
+	},
+}
+
+type statsResult struct {
+	Success bool `json:"success"`
+	errorResult
+	*stats.Analysis
+}
+
+func runStats(writer io.Writer, store *stats.Store, outputFormat string) error {
+	records, err := store.List()
+	result := &statsResult{
+		Success:     err == nil,
+		errorResult: newErrorResult(err),
+	}
+	if err == nil {
+		result.Analysis = stats.Analyze(records)
+	}
+	return render(result, writer, outputFormat)
+}
+
+func (s *statsResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	if s.Last == nil {
+		_, err := fmt.Fprintln(writer, "No successful start was recorded yet")
+		return err
+	}
+	kind := "start of the instance"
+	if s.Last.Creation {
+		kind = "creation of the instance"
+	}
+	if _, err := fmt.Fprintf(writer, "Last %s: %s, took %s\n\n", kind, s.Last.Time.Local().Format("2006-01-02 15:04:05"), formatStatsDuration(s.Last.Duration)); err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "PHASE\tLAST\tMEDIAN"); err != nil {
+		return err
+	}
+	var regressions []stats.Trend
+	for _, trend := range s.Trends {
+		median := "-"
+		if trend.Starts > 0 {
+			median = fmt.Sprintf("%s (%d starts)", formatStatsDuration(trend.Median), trend.Starts)
+		}
+		note := ""
+		if trend.Regression {
+			note = ", slower"
+			regressions = append(regressions, trend)
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s%s\n", trend.Phase, formatStatsDuration(trend.Last), median, note); err != nil {
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(regressions) > 0 || len(s.SlowOperators) > 0 {
+		if _, err := fmt.Fprintln(writer); err != nil {
+			return err
+		}
+	}
+	for _, trend := range regressions {
+		if _, err := fmt.Fprintf(writer, "The %s phase took %s, the median of the %d previous starts is %s\n",
+			trend.Phase, formatStatsDuration(trend.Last), trend.Starts, formatStatsDuration(trend.Median)); err != nil {
+			return err
+		}
+	}
+	for _, operator := range s.SlowOperators {
+		if _, err := fmt.Fprintf(writer, "Operator %s was the last to be ready in %d of the last %d starts\n",
+			operator.Name, operator.Count, operator.Starts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatStatsDuration(duration time.Duration) string {
+	return duration.Round(time.Second).String()
+}
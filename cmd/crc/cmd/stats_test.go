@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func statsRecord(dns time.Duration, slowestOperator string) stats.Record {
+	return stats.Record{
+		Time:     time.Date(2022, 3, 1, 10, 0, 0, 0, time.Local),
+		Success:  true,
+		Duration: 5*time.Minute + dns,
+		Phases: []stats.Phase{
+			{Name: "vm-start", Duration: 5 * time.Minute},
+			{Name: "dns", Duration: dns},
+		},
+		SlowestOperators: []string{slowestOperator},
+	}
+}
+
+func TestStatsEmpty(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runStats(out, stats.NewStore(filepath.Join(t.TempDir(), "stats.json")), ""))
+	assert.Equal(t, "No successful start was recorded yet\n", out.String())
+}
+
+func TestStatsPlainOutput(t *testing.T) {
+	store := stats.NewStore(filepath.Join(t.TempDir(), "stats.json"))
+	require.NoError(t, store.Add(statsRecord(10*time.Second, "console")))
+	require.NoError(t, store.Add(statsRecord(12*time.Second, "kube-apiserver")))
+	require.NoError(t, store.Add(statsRecord(time.Minute, "kube-apiserver")))
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, runStats(out, store, ""))
+	assert.Equal(t, `Last start of the instance: 2022-03-01 10:00:00, took 6m0s
+
+PHASE     LAST  MEDIAN
+vm-start  5m0s  5m0s (2 starts)
+dns       1m0s  11s (2 starts), slower
+total     6m0s  5m11s (2 starts)
+
+The dns phase took 1m0s, the median of the 2 previous starts is 11s
+Operator kube-apiserver was the last to be ready in 2 of the last 3 starts
+`, out.String())
+}
+
+func TestStatsJSONOutput(t *testing.T) {
+	store := stats.NewStore(filepath.Join(t.TempDir(), "stats.json"))
+	require.NoError(t, store.Add(statsRecord(10*time.Second, "console")))
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, runStats(out, store, jsonFormat))
+	assert.Contains(t, out.String(), `"success": true`)
+	assert.Contains(t, out.String(), `"phase": "dns"`)
+	assert.Contains(t, out.String(), `"slowOperators": []`)
+}
@@ -1,23 +1,45 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/code-ready/crc/pkg/crc/constants"
-	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
 	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/state"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/crc/preset"
 	"github.com/docker/go-units"
 	"github.com/spf13/cobra"
 )
 
+var (
+	watchStatus         bool
+	watchStatusInterval time.Duration
+	waitReady           bool
+	waitReadyTimeout    time.Duration
+	readinessChecks     []string
+	readinessOperators  []string
+	showComponents      bool
+)
+
 func init() {
 	addOutputFormatFlag(statusCmd)
+	statusCmd.Flags().BoolVarP(&watchStatus, "watch", "w", false, "Watch the status and refresh it periodically")
+	statusCmd.Flags().BoolVar(&showComponents, "components", false, "Show the CPU, memory and disk usage of kube-apiserver, etcd and of the pods using the most memory")
+	statusCmd.Flags().DurationVar(&watchStatusInterval, "interval", 5*time.Second, "Refresh interval used with --watch and --wait-ready")
+	statusCmd.Flags().BoolVar(&waitReady, "wait-ready", false, "Wait until the cluster is ready, and exit with an error if it is not ready before the timeout")
+	statusCmd.Flags().DurationVar(&waitReadyTimeout, "timeout", 10*time.Minute, "Maximum time to wait with --wait-ready")
+	statusCmd.Flags().StringSliceVar(&readinessChecks, "ready-checks", defaultReadinessChecks, "Checks which must pass with --wait-ready (api, ingress, operators)")
+	statusCmd.Flags().StringSliceVar(&readinessOperators, "ready-operators", nil, "Cluster operators which must be available with --wait-ready (default: all)")
 	rootCmd.AddCommand(statusCmd)
 }
 
@@ -26,37 +48,122 @@ var statusCmd = &cobra.Command{
 	Short: "Display status of the OpenShift cluster",
 	Long:  "Show details about the OpenShift cluster",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runStatus(os.Stdout, newMachine(), constants.MachineCacheDir, outputFormat)
+		if waitReady {
+			if watchStatus {
+				return fmt.Errorf("--wait-ready cannot be used with --watch")
+			}
+			readinessConfig, err := newReadinessConfig(readinessChecks, readinessOperators)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+			return runWaitReady(ctx, os.Stdout, newMachine(), readinessConfig, outputFormat, watchStatusInterval, waitReadyTimeout)
+		}
+		if watchStatus {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+			return runWatchStatus(ctx, os.Stdout, newMachine(), constants.MachineCacheDir, showComponents, outputFormat, watchStatusInterval)
+		}
+		return runStatus(os.Stdout, newMachine(), constants.MachineCacheDir, showComponents, outputFormat)
 	},
 }
 
 type status struct {
-	Success          bool                         `json:"success"`
-	Error            *crcErrors.SerializableError `json:"error,omitempty"`
-	CrcStatus        string                       `json:"crcStatus,omitempty"`
-	OpenShiftStatus  types.OpenshiftStatus        `json:"openshiftStatus,omitempty"`
-	OpenShiftVersion string                       `json:"openshiftVersion,omitempty"`
-	PodmanVersion    string                       `json:"podmanVersion,omitempty"`
-	DiskUsage        int64                        `json:"diskUsage,omitempty"`
-	DiskSize         int64                        `json:"diskSize,omitempty"`
-	CacheUsage       int64                        `json:"cacheUsage,omitempty"`
-	CacheDir         string                       `json:"cacheDir,omitempty"`
-	Preset           preset.Preset                `json:"preset"`
+	Success bool `json:"success"`
+	errorResult
+	CrcStatus         string                `json:"crcStatus,omitempty"`
+	OpenShiftStatus   types.OpenshiftStatus `json:"openshiftStatus,omitempty"`
+	OpenShiftVersion  string                `json:"openshiftVersion,omitempty"`
+	PodmanVersion     string                `json:"podmanVersion,omitempty"`
+	IP                string                `json:"ip,omitempty"`
+	DiskUsage         int64                 `json:"diskUsage,omitempty"`
+	DiskSize          int64                 `json:"diskSize,omitempty"`
+	RAMUsage          int64                 `json:"ramUsage,omitempty"`
+	RAMSize           int64                 `json:"ramSize,omitempty"`
+	PVPoolUsage       int64                 `json:"pvPoolUsage,omitempty"`
+	PVPoolSize        int64                 `json:"pvPoolSize,omitempty"`
+	ClusterMonitoring bool                  `json:"clusterMonitoring,omitempty"`
+	DegradedOperators []string              `json:"degradedOperators,omitempty"`
+	Certificates      []certificateStatus   `json:"certificates,omitempty"`
+	CacheUsage        int64                 `json:"cacheUsage,omitempty"`
+	CacheDir          string                `json:"cacheDir,omitempty"`
+	Preset            preset.Preset         `json:"preset"`
+	Components        []componentUsage      `json:"components,omitempty"`
+	Workers           []workerStatus        `json:"workers,omitempty"`
+	NetworkConflicts  []network.Conflict    `json:"networkConflicts,omitempty"`
+	// ClockSkew is how many seconds the clock of the instance is ahead of
+	// the clock of the host
+	ClockSkew int64 `json:"clockSkew,omitempty"`
 }
 
-func runStatus(writer io.Writer, client machine.Client, cacheDir, outputFormat string) error {
-	status := getStatus(client, cacheDir)
+type workerStatus struct {
+	Name   string `json:"name"`
+	State  string `json:"state"`
+	IP     string `json:"ip,omitempty"`
+	Ready  bool   `json:"ready"`
+	CPUs   int    `json:"cpus,omitempty"`
+	Memory int    `json:"memory,omitempty"`
+}
+
+type componentUsage struct {
+	Name      string  `json:"name"`
+	Namespace string  `json:"namespace,omitempty"`
+	CPU       float64 `json:"cpu"`
+	Memory    int64   `json:"memory"`
+	Disk      int64   `json:"disk"`
+}
+
+type certificateStatus struct {
+	Name        string    `json:"name"`
+	Expires     time.Time `json:"expires"`
+	ExpiresSoon bool      `json:"expiresSoon"`
+}
+
+func runStatus(writer io.Writer, client machine.Client, cacheDir string, components bool, outputFormat string) error {
+	status := getStatus(client, cacheDir, components)
 	return render(status, writer, outputFormat)
 }
 
-func getStatus(client machine.Client, cacheDir string) *status {
+// runWatchStatus renders the status every interval until ctx is cancelled.
+// The screen is cleared between two renderings of the plain output.
+func runWatchStatus(ctx context.Context, writer io.Writer, client machine.Client, cacheDir string, components bool, outputFormat string, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("Invalid interval %s, it must be positive", interval)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if !isMachineReadable(outputFormat) {
+			if _, err := fmt.Fprintf(writer, "\033[H\033[2J%s\n\n", time.Now().Format(time.RFC1123)); err != nil {
+				return err
+			}
+		}
+		status := getStatus(client, cacheDir, components)
+		if err := render(status, writer, outputFormat); err != nil {
+			if status.Error == nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(writer, err); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func getStatus(client machine.Client, cacheDir string, components bool) *status {
 	if err := checkIfMachineMissing(client); err != nil {
-		return &status{Success: false, Error: crcErrors.ToSerializableError(err)}
+		return &status{Success: false, errorResult: newErrorResult(err)}
 	}
 
 	clusterStatus, err := client.Status()
 	if err != nil {
-		return &status{Success: false, Error: crcErrors.ToSerializableError(err)}
+		return &status{Success: false, errorResult: newErrorResult(err)}
 	}
 	var size int64
 	err = filepath.Walk(cacheDir, func(_ string, info os.FileInfo, err error) error {
@@ -66,20 +173,58 @@ func getStatus(client machine.Client, cacheDir string) *status {
 		return err
 	})
 	if err != nil {
-		return &status{Success: false, Error: crcErrors.ToSerializableError(err)}
+		return &status{Success: false, errorResult: newErrorResult(err)}
+	}
+
+	var certificates []certificateStatus
+	for _, cert := range clusterStatus.Certificates {
+		certificates = append(certificates, certificateStatus{
+			Name:        cert.Name,
+			Expires:     cert.NotAfter,
+			ExpiresSoon: cert.ExpiresSoon(time.Now()),
+		})
+	}
+
+	var usage []componentUsage
+	if components && clusterStatus.CrcStatus == state.Running && clusterStatus.Preset == preset.OpenShift {
+		componentsUsage, err := client.ComponentsUsage()
+		if err != nil {
+			return &status{Success: false, errorResult: newErrorResult(err)}
+		}
+		for _, component := range componentsUsage {
+			usage = append(usage, componentUsage{
+				Name:      component.Name,
+				Namespace: component.Namespace,
+				CPU:       component.CPU,
+				Memory:    component.Memory,
+				Disk:      component.Disk,
+			})
+		}
 	}
 
 	return &status{
-		Success:          true,
-		CrcStatus:        string(clusterStatus.CrcStatus),
-		OpenShiftStatus:  clusterStatus.OpenshiftStatus,
-		OpenShiftVersion: clusterStatus.OpenshiftVersion,
-		PodmanVersion:    clusterStatus.PodmanVersion,
-		DiskUsage:        clusterStatus.DiskUse,
-		DiskSize:         clusterStatus.DiskSize,
-		CacheUsage:       size,
-		CacheDir:         cacheDir,
-		Preset:           clusterStatus.Preset,
+		Success:           true,
+		CrcStatus:         string(clusterStatus.CrcStatus),
+		OpenShiftStatus:   clusterStatus.OpenshiftStatus,
+		OpenShiftVersion:  clusterStatus.OpenshiftVersion,
+		PodmanVersion:     clusterStatus.PodmanVersion,
+		IP:                clusterStatus.IP,
+		DiskUsage:         clusterStatus.DiskUse,
+		DiskSize:          clusterStatus.DiskSize,
+		RAMUsage:          clusterStatus.RAMUse,
+		RAMSize:           clusterStatus.RAMSize,
+		PVPoolUsage:       clusterStatus.PVPoolUse,
+		PVPoolSize:        clusterStatus.PVPoolSize,
+		ClusterMonitoring: clusterStatus.ClusterMonitoring,
+		DegradedOperators: clusterStatus.DegradedOperators,
+		Certificates:      certificates,
+		CacheUsage:        size,
+		CacheDir:          cacheDir,
+		Preset:            clusterStatus.Preset,
+		Components:        usage,
+		Workers:           toWorkerStatus(clusterStatus.Workers),
+		NetworkConflicts:  clusterStatus.NetworkConflicts,
+		ClockSkew:         int64(clusterStatus.ClockSkew / time.Second),
 	}
 }
 
@@ -89,27 +234,115 @@ func (s *status) prettyPrintTo(writer io.Writer) error {
 	}
 	w := tabwriter.NewWriter(writer, 0, 0, 1, ' ', 0)
 
-	lines := []struct {
+	type line struct {
 		left, right string
-	}{
-		{"CRC VM", s.CrcStatus},
-		{"OpenShift", openshiftStatus(s)},
-		{"Podman", s.PodmanVersion},
-		{"Disk Usage", fmt.Sprintf(
+	}
+	lines := []line{{"CRC VM", s.CrcStatus}}
+	if s.IP != "" {
+		lines = append(lines, line{"IP", s.IP})
+	}
+	for _, worker := range s.Workers {
+		lines = append(lines, line{fmt.Sprintf("Worker %s", worker.Name), workerStatusLine(worker)})
+	}
+	lines = append(lines, line{"OpenShift", openshiftStatus(s)})
+	if len(s.DegradedOperators) > 0 {
+		lines = append(lines, line{"Degraded Operators", strings.Join(s.DegradedOperators, ", ")})
+	}
+	for _, conflict := range s.NetworkConflicts {
+		lines = append(lines, line{"Network Conflict", conflict.Description})
+	}
+	if s.ClockSkew > maxClockSkew || s.ClockSkew < -maxClockSkew {
+		lines = append(lines, line{"Clock Skew", clockSkewLine(s.ClockSkew)})
+	}
+	if s.ClusterMonitoring {
+		lines = append(lines, line{"Cluster Monitoring", "Enabled"})
+	}
+	for _, cert := range s.Certificates {
+		if cert.ExpiresSoon {
+			lines = append(lines, line{"Expiring Certificate", fmt.Sprintf("%s (%s)", cert.Name, cert.Expires.Format(time.RFC822))})
+		}
+	}
+	lines = append(lines,
+		line{"Podman", s.PodmanVersion},
+		line{"Disk Usage", fmt.Sprintf(
 			"%s of %s (Inside the CRC VM)",
 			units.HumanSize(float64(s.DiskUsage)),
 			units.HumanSize(float64(s.DiskSize)))},
-		{"Cache Usage", units.HumanSize(float64(s.CacheUsage))},
-		{"Cache Directory", s.CacheDir},
+	)
+	if s.RAMSize > 0 {
+		lines = append(lines, line{"RAM Usage", fmt.Sprintf(
+			"%s of %s (Inside the CRC VM)",
+			units.HumanSize(float64(s.RAMUsage)),
+			units.HumanSize(float64(s.RAMSize)))})
+	}
+	if s.PVPoolSize > 0 {
+		lines = append(lines, line{"PV Pool Usage", fmt.Sprintf(
+			"%s of %s (%s available)",
+			units.HumanSize(float64(s.PVPoolUsage)),
+			units.HumanSize(float64(s.PVPoolSize)),
+			units.HumanSize(float64(s.PVPoolSize-s.PVPoolUsage)))})
 	}
+	lines = append(lines,
+		line{"Cache Usage", units.HumanSize(float64(s.CacheUsage))},
+		line{"Cache Directory", s.CacheDir},
+	)
 	for _, line := range lines {
 		if err := printLine(w, line.left, line.right); err != nil {
 			return err
 		}
 	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return printComponents(writer, s.Components)
+}
+
+func printComponents(writer io.Writer, components []componentUsage) error {
+	if len(components) == 0 {
+		return nil
+	}
+	w := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "COMPONENT\tCPU\tMEMORY\tDISK")
+	for _, component := range components {
+		name := component.Name
+		if component.Namespace != "" {
+			name = fmt.Sprintf("%s/%s", component.Namespace, component.Name)
+		}
+		fmt.Fprintf(w, "%s\t%dm\t%s\t%s\n", name, int64(component.CPU*1000),
+			units.HumanSize(float64(component.Memory)), units.HumanSize(float64(component.Disk)))
+	}
 	return w.Flush()
 }
 
+func workerStatusLine(worker workerStatus) string {
+	status := worker.State
+	if worker.IP != "" {
+		status = fmt.Sprintf("%s (%s)", status, worker.IP)
+	}
+	if worker.Ready {
+		status += ", node ready"
+	} else if worker.State == string(state.Running) {
+		status += ", node not ready"
+	}
+	if worker.CPUs > 0 {
+		status += fmt.Sprintf(", %d CPUs, %s", worker.CPUs, units.BytesSize(float64(worker.Memory)*1024*1024))
+	}
+	return status
+}
+
+// maxClockSkew is the number of seconds the clocks of the instance and of the
+// host can differ before status reports it, the clock of the instance is read
+// in seconds
+const maxClockSkew = 1
+
+func clockSkewLine(seconds int64) string {
+	if seconds < 0 {
+		return fmt.Sprintf("%s behind the host", time.Duration(-seconds)*time.Second)
+	}
+	return fmt.Sprintf("%s ahead of the host", time.Duration(seconds)*time.Second)
+}
+
 func openshiftStatus(status *status) string {
 	if status.OpenShiftVersion != "" {
 		return fmt.Sprintf("%s (v%s)", status.OpenShiftStatus, status.OpenShiftVersion)
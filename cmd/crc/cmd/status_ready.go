@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+)
+
+var defaultReadinessChecks = []string{
+	string(types.APIReadiness),
+	string(types.IngressReadiness),
+	string(types.OperatorsReadiness),
+}
+
+type readinessCheck struct {
+	Name    types.ReadinessCheck `json:"name"`
+	Ready   bool                 `json:"ready"`
+	Message string               `json:"message,omitempty"`
+}
+
+type readinessStatus struct {
+	Success bool `json:"success"`
+	errorResult
+	Ready  bool             `json:"ready"`
+	Checks []readinessCheck `json:"checks,omitempty"`
+}
+
+func newReadinessConfig(checks, operators []string) (types.ReadinessConfig, error) {
+	readinessConfig := types.ReadinessConfig{
+		Operators: operators,
+	}
+	for _, check := range checks {
+		switch readinessCheck := types.ReadinessCheck(check); readinessCheck {
+		case types.APIReadiness, types.IngressReadiness, types.OperatorsReadiness:
+			readinessConfig.Checks = append(readinessConfig.Checks, readinessCheck)
+		default:
+			return types.ReadinessConfig{}, fmt.Errorf("Unknown readiness check '%s', valid values are: %s, %s and %s",
+				check, types.APIReadiness, types.IngressReadiness, types.OperatorsReadiness)
+		}
+	}
+	if len(readinessConfig.Checks) == 0 {
+		return types.ReadinessConfig{}, fmt.Errorf("At least one readiness check is needed")
+	}
+	return readinessConfig, nil
+}
+
+// runWaitReady runs the readiness checks every interval until they all pass
+// or the timeout expires. It fails when the cluster is not ready, so that
+// scripts can rely on the exit code.
+func runWaitReady(ctx context.Context, writer io.Writer, client machine.Client, readinessConfig types.ReadinessConfig, outputFormat string, interval, timeout time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("Invalid interval %s, it must be positive", interval)
+	}
+	if err := checkIfMachineMissing(client); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var (
+		result *types.ReadinessResult
+		err    error
+	)
+	for {
+		result, err = client.CheckReadiness(ctx, readinessConfig)
+		if err == nil && result.Ready {
+			break
+		}
+		if err != nil {
+			logging.Debugf("Cannot check the cluster readiness: %v", err)
+		} else {
+			for _, check := range result.Checks {
+				if !check.Ready {
+					logging.Debugf("%s: %s", check.Check, check.Message)
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			if err == nil {
+				err = crcErrors.WithClass(crcErrors.ClusterTimeout, fmt.Errorf("The cluster is not ready after %s", timeout))
+			}
+			return renderReadiness(writer, result, err, outputFormat)
+		case <-time.After(interval):
+		}
+	}
+	return renderReadiness(writer, result, nil, outputFormat)
+}
+
+func renderReadiness(writer io.Writer, result *types.ReadinessResult, err error, outputFormat string) error {
+	status := &readinessStatus{
+		Success:     err == nil,
+		errorResult: newErrorResult(err),
+	}
+	if result != nil {
+		status.Ready = result.Ready
+		for _, check := range result.Checks {
+			status.Checks = append(status.Checks, readinessCheck{
+				Name:    check.Check,
+				Ready:   check.Ready,
+				Message: check.Message,
+			})
+		}
+	}
+	if renderErr := render(status, writer, outputFormat); renderErr != nil {
+		return renderErr
+	}
+	return err
+}
+
+func (s *readinessStatus) prettyPrintTo(writer io.Writer) error {
+	w := tabwriter.NewWriter(writer, 0, 0, 1, ' ', 0)
+	for _, check := range s.Checks {
+		state := "Ready"
+		if !check.Ready {
+			state = fmt.Sprintf("Not ready (%s)", check.Message)
+		}
+		if err := printLine(w, string(check.Name), state); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
@@ -2,14 +2,21 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/code-ready/crc/pkg/crc/cluster"
 	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/network"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -23,7 +30,7 @@ func TestPlainStatus(t *testing.T) {
 	require.NoError(t, ioutil.WriteFile(filepath.Join(cacheDir, "crc.qcow2"), make([]byte, 10000), 0600))
 
 	out := new(bytes.Buffer)
-	assert.NoError(t, runStatus(out, fakemachine.NewClient(), cacheDir, ""))
+	assert.NoError(t, runStatus(out, fakemachine.NewClient(), cacheDir, false, ""))
 
 	expected := `CRC VM:          Running
 OpenShift:       Running (v4.5.1)
@@ -43,7 +50,7 @@ func TestJsonStatus(t *testing.T) {
 	require.NoError(t, ioutil.WriteFile(filepath.Join(cacheDir, "crc.qcow2"), make([]byte, 10000), 0600))
 
 	out := new(bytes.Buffer)
-	assert.NoError(t, runStatus(out, fakemachine.NewClient(), cacheDir, jsonFormat))
+	assert.NoError(t, runStatus(out, fakemachine.NewClient(), cacheDir, false, jsonFormat))
 
 	expected := `{
   "success": true,
@@ -61,6 +68,130 @@ func TestJsonStatus(t *testing.T) {
 	assert.Equal(t, fmt.Sprintf(expected, strings.ReplaceAll(cacheDir, `\`, `\\`)), out.String())
 }
 
+func TestPlainStatusWithExpiringCertificate(t *testing.T) {
+	cacheDir := t.TempDir()
+	expires := time.Now().Add(48 * time.Hour)
+	client := fakemachine.NewClient()
+	client.Certificates = []cluster.CertExpiry{
+		{Name: "kubelet-client", NotAfter: expires},
+		{Name: "aggregator-client-ca", NotAfter: time.Now().Add(300 * 24 * time.Hour)},
+	}
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, runStatus(out, client, cacheDir, false, ""))
+	assert.Contains(t, out.String(), fmt.Sprintf("Expiring Certificate: kubelet-client (%s)\n", expires.Format(time.RFC822)))
+	assert.NotContains(t, out.String(), "aggregator-client-ca")
+
+	out.Reset()
+	assert.NoError(t, runStatus(out, client, cacheDir, false, jsonFormat))
+	var status status
+	require.NoError(t, json.Unmarshal(out.Bytes(), &status))
+	require.Len(t, status.Certificates, 2)
+	assert.True(t, status.Certificates[0].ExpiresSoon)
+	assert.False(t, status.Certificates[1].ExpiresSoon)
+}
+
+func TestPlainStatusWithPVPool(t *testing.T) {
+	client := fakemachine.NewClient()
+	client.PVPoolUse = 2_000_000_000
+	client.PVPoolSize = 5_000_000_000
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, runStatus(out, client, t.TempDir(), false, ""))
+	assert.Contains(t, out.String(), "PV Pool Usage:   2GB of 5GB (3GB available)\n")
+}
+
+func TestPlainStatusWithClusterMonitoring(t *testing.T) {
+	client := fakemachine.NewClient()
+	client.ClusterMonitoring = true
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, runStatus(out, client, t.TempDir(), false, ""))
+	assert.Contains(t, out.String(), "Cluster Monitoring: Enabled\n")
+
+	out.Reset()
+	assert.NoError(t, runStatus(out, client, t.TempDir(), false, jsonFormat))
+	assert.Contains(t, out.String(), `"clusterMonitoring": true`)
+}
+
+func TestStatusWithNetworkConflicts(t *testing.T) {
+	client := fakemachine.NewClient()
+	client.NetworkConflicts = []network.Conflict{
+		{Kind: network.RouteConflict, Interface: "tun0", Description: "The VPN interface tun0 uses the network 192.168.128.0/22 of the instance"},
+	}
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, runStatus(out, client, t.TempDir(), false, ""))
+	assert.Contains(t, out.String(), "Network Conflict: The VPN interface tun0 uses the network 192.168.128.0/22 of the instance\n")
+
+	out.Reset()
+	assert.NoError(t, runStatus(out, client, t.TempDir(), false, jsonFormat))
+	var result status
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.Equal(t, client.NetworkConflicts, result.NetworkConflicts)
+}
+
+func TestStatusWithClockSkew(t *testing.T) {
+	client := fakemachine.NewClient()
+	out := new(bytes.Buffer)
+	assert.NoError(t, runStatus(out, client, t.TempDir(), false, ""))
+	assert.NotContains(t, out.String(), "Clock Skew")
+
+	client.ClockSkew = -5 * time.Minute
+	out.Reset()
+	assert.NoError(t, runStatus(out, client, t.TempDir(), false, ""))
+	assert.Regexp(t, "Clock Skew: +5m0s behind the host\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, runStatus(out, client, t.TempDir(), false, jsonFormat))
+	assert.Contains(t, out.String(), `"clockSkew": -300`)
+}
+
+func TestStatusWithWorkers(t *testing.T) {
+	client := fakemachine.NewClient()
+	client.WorkerNodes = []types.WorkerStatus{
+		{Name: "crc-worker-1", State: state.Running, IP: "192.168.130.12", Ready: true},
+		{Name: "crc-worker-2", State: state.Running, IP: "192.168.130.13"},
+		{Name: "crc-worker-3", State: state.Stopped},
+	}
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, runStatus(out, client, t.TempDir(), false, ""))
+	assert.Contains(t, out.String(), `CRC VM:              Running
+Worker crc-worker-1: Running (192.168.130.12), node ready
+Worker crc-worker-2: Running (192.168.130.13), node not ready
+Worker crc-worker-3: Stopped
+OpenShift:           Running (v4.5.1)
+`)
+
+	out.Reset()
+	assert.NoError(t, runStatus(out, client, t.TempDir(), false, jsonFormat))
+	var result status
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.Equal(t, []workerStatus{
+		{Name: "crc-worker-1", State: "Running", IP: "192.168.130.12", Ready: true},
+		{Name: "crc-worker-2", State: "Running", IP: "192.168.130.13"},
+		{Name: "crc-worker-3", State: "Stopped"},
+	}, result.Workers)
+}
+
+func TestPlainStatusWithComponents(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runStatus(out, fakemachine.NewClient(), t.TempDir(), true, ""))
+	assert.Contains(t, out.String(), `
+COMPONENT                              CPU   MEMORY  DISK
+kube-apiserver                         350m  1.2GB   0B
+etcd                                   120m  300MB   500MB
+openshift-monitoring/prometheus-k8s-0  50m   800MB   0B
+`)
+
+	out.Reset()
+	assert.NoError(t, runStatus(out, fakemachine.NewClient(), t.TempDir(), true, jsonFormat))
+	var status status
+	require.NoError(t, json.Unmarshal(out.Bytes(), &status))
+	assert.Equal(t, componentUsage{Name: "etcd", CPU: 0.12, Memory: 300_000_000, Disk: 500_000_000}, status.Components[1])
+}
+
 func TestPlainStatusWithError(t *testing.T) {
 	cacheDir, err := ioutil.TempDir("", "cache")
 	require.NoError(t, err)
@@ -69,7 +200,7 @@ func TestPlainStatusWithError(t *testing.T) {
 	require.NoError(t, ioutil.WriteFile(filepath.Join(cacheDir, "crc.qcow2"), make([]byte, 10000), 0600))
 
 	out := new(bytes.Buffer)
-	assert.EqualError(t, runStatus(out, fakemachine.NewFailingClient(), cacheDir, ""), "broken")
+	assert.EqualError(t, runStatus(out, fakemachine.NewFailingClient(), cacheDir, false, ""), "broken")
 	assert.Equal(t, "", out.String())
 }
 
@@ -81,13 +212,90 @@ func TestJsonStatusWithError(t *testing.T) {
 	require.NoError(t, ioutil.WriteFile(filepath.Join(cacheDir, "crc.qcow2"), make([]byte, 10000), 0600))
 
 	out := new(bytes.Buffer)
-	assert.NoError(t, runStatus(out, fakemachine.NewFailingClient(), cacheDir, jsonFormat))
+	assert.EqualError(t, runStatus(out, fakemachine.NewFailingClient(), cacheDir, false, jsonFormat), "broken")
 
 	expected := `{
   "success": false,
   "error": "broken",
+  "exitCode": 1,
   "preset": ""
 }
 `
 	assert.Equal(t, expected, out.String())
 }
+
+func TestWatchStatus(t *testing.T) {
+	cacheDir, err := ioutil.TempDir("", "cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(cacheDir)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, runWatchStatus(ctx, out, fakemachine.NewClient(), cacheDir, false, "", time.Second))
+	assert.True(t, strings.HasPrefix(out.String(), "\033[H\033[2J"))
+	assert.Contains(t, out.String(), "OpenShift:       Running (v4.5.1)\n")
+}
+
+func TestWatchStatusInvalidInterval(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runWatchStatus(context.Background(), out, fakemachine.NewClient(), "", false, jsonFormat, 0), "Invalid interval 0s, it must be positive")
+}
+
+type readyAfterClient struct {
+	*fakemachine.Client
+	checks int
+	ready  int
+}
+
+func (c *readyAfterClient) CheckReadiness(ctx context.Context, readinessConfig types.ReadinessConfig) (*types.ReadinessResult, error) {
+	c.checks++
+	if c.checks < c.ready {
+		return fakemachine.NewFailingClient().CheckReadiness(ctx, readinessConfig)
+	}
+	return c.Client.CheckReadiness(ctx, readinessConfig)
+}
+
+func TestWaitReady(t *testing.T) {
+	readinessConfig, err := newReadinessConfig(defaultReadinessChecks, nil)
+	require.NoError(t, err)
+	client := &readyAfterClient{Client: fakemachine.NewClient(), ready: 3}
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, runWaitReady(context.Background(), out, client, readinessConfig, "", time.Millisecond, time.Minute))
+	assert.Equal(t, 3, client.checks)
+	assert.Equal(t, "api:       Ready\ningress:   Ready\noperators: Ready\n", out.String())
+}
+
+func TestWaitReadyTimeout(t *testing.T) {
+	readinessConfig, err := newReadinessConfig([]string{"api"}, nil)
+	require.NoError(t, err)
+
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runWaitReady(context.Background(), out, fakemachine.NewFailingClient(), readinessConfig, jsonFormat, time.Millisecond, 10*time.Millisecond),
+		"The cluster is not ready after 10ms")
+	expected := `{
+  "success": false,
+  "error": "The cluster is not ready after 10ms",
+  "errorCode": "cluster-timeout",
+  "remediation": "Check the state of the cluster with 'crc status', a slow host may need more time",
+  "docsURL": "https://code-ready.github.io/crc/#troubleshooting-unknown-issues_gsg",
+  "exitCode": 9,
+  "ready": false,
+  "checks": [
+    {
+      "name": "api",
+      "ready": false,
+      "message": "api is not ready"
+    }
+  ]
+}
+`
+	assert.Equal(t, expected, out.String())
+}
+
+func TestReadinessConfigInvalidCheck(t *testing.T) {
+	_, err := newReadinessConfig([]string{"api", "dns"}, nil)
+	assert.EqualError(t, err, "Unknown readiness check 'dns', valid values are: api, ingress and operators")
+}
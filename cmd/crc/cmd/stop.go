@@ -4,69 +4,84 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"time"
 
-	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
 	"github.com/code-ready/crc/pkg/crc/input"
+	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine"
 	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
 	"github.com/spf13/cobra"
 )
 
+var stopTimeout time.Duration
+
 func init() {
 	addOutputFormatFlag(stopCmd)
 	addForceFlag(stopCmd)
+	stopCmd.Flags().DurationVar(&stopTimeout, "timeout", machine.DefaultStopTimeout, "Time to wait for the graceful shutdown before powering off the instance (with --force)")
 	rootCmd.AddCommand(stopCmd)
 }
 
 var stopCmd = &cobra.Command{
 	Use:   "stop",
 	Short: "Stop the instance",
-	Long:  "Stop the instance",
+	Long: `Stop the instance
+
+The nodes are cordoned, the containers are stopped and the instance is shut
+down. With --force, the instance is powered off when it is still running
+after the timeout.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runStop(os.Stdout, newMachine(), outputFormat != jsonFormat, globalForce, outputFormat)
+		if stopTimeout <= 0 {
+			return fmt.Errorf("Invalid timeout %s, it must be positive", stopTimeout)
+		}
+		return runStop(os.Stdout, newMachine(), !isMachineReadable(outputFormat), globalForce, stopTimeout, outputFormat)
 	},
 }
 
-func stopMachine(client machine.Client, interactive, force bool) (bool, error) {
+// stopMachine returns the reason why the instance was powered off when the
+// graceful shutdown failed
+func stopMachine(client machine.Client, interactive, force bool, timeout time.Duration) (bool, string, error) {
 	if err := checkIfMachineMissing(client); err != nil {
-		return false, err
+		return false, "", err
 	}
 
-	vmState, err := client.Stop()
+	vmState, err := client.Stop(types.StopConfig{Timeout: timeout})
 	if err != nil {
 		if !interactive && !force {
-			return false, err
+			return false, "", err
 		}
 		// Here we are checking the VM state and if it is still running then
 		// Ask user to forcefully power off it.
 		if vmState == state.Running {
-			// Most of the time force kill don't work and libvirt throw
-			// Device or resource busy error. To make sure we give some
-			// graceful time to cluster before kill it.
+			logging.Warnf("Graceful shutdown failed: %v", err)
 			yes := input.PromptUserForYesOrNo("Do you want to force power off", force)
 			if yes {
-				err := client.PowerOff()
-				return true, err
+				return true, err.Error(), client.PowerOff()
 			}
 		}
-		return false, err
+		return false, "", err
 	}
-	return false, nil
+	return false, "", nil
 }
 
-func runStop(writer io.Writer, client machine.Client, interactive, force bool, outputFormat string) error {
-	forced, err := stopMachine(client, interactive, force)
+func runStop(writer io.Writer, client machine.Client, interactive, force bool, timeout time.Duration, outputFormat string) error {
+	forced, reason, err := stopMachine(client, interactive, force, timeout)
 	return render(&stopResult{
-		Success: err == nil,
-		Forced:  forced,
-		Error:   crcErrors.ToSerializableError(err),
+		Success:     err == nil,
+		Forced:      forced,
+		Reason:      reason,
+		errorResult: newErrorResult(err),
 	}, writer, outputFormat)
 }
 
 type stopResult struct {
-	Success bool                         `json:"success"`
-	Forced  bool                         `json:"forced"`
-	Error   *crcErrors.SerializableError `json:"error,omitempty"`
+	Success bool `json:"success"`
+	// Forced is true when the graceful shutdown failed and the instance was
+	// powered off, Reason is then the error of the graceful shutdown
+	Forced bool   `json:"forced"`
+	Reason string `json:"reason,omitempty"`
+	errorResult
 }
 
 func (s *stopResult) prettyPrintTo(writer io.Writer) error {
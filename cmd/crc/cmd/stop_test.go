@@ -3,41 +3,70 @@ package cmd
 import (
 	"bytes"
 	"testing"
+	"time"
 
+	"github.com/code-ready/crc/pkg/crc/machine"
 	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestStopPlainSuccess(t *testing.T) {
 	out := new(bytes.Buffer)
-	assert.NoError(t, runStop(out, fakemachine.NewClient(), true, false, ""))
+	assert.NoError(t, runStop(out, fakemachine.NewClient(), true, false, machine.DefaultStopTimeout, ""))
 	assert.Equal(t, "Stopped the instance\n", out.String())
 }
 
 func TestStopPlainError(t *testing.T) {
 	out := new(bytes.Buffer)
-	assert.EqualError(t, runStop(out, fakemachine.NewFailingClient(), true, false, ""), "stop failed")
+	assert.EqualError(t, runStop(out, fakemachine.NewFailingClient(), true, false, machine.DefaultStopTimeout, ""), "stop failed")
 }
 
 func TestStopWithForcePlainError(t *testing.T) {
 	out := new(bytes.Buffer)
-	assert.EqualError(t, runStop(out, fakemachine.NewFailingClient(), true, true, ""), "poweroff failed")
+	assert.EqualError(t, runStop(out, fakemachine.NewFailingClient(), true, true, machine.DefaultStopTimeout, ""), "poweroff failed")
 }
 
 func TestStopJSONSuccess(t *testing.T) {
 	out := new(bytes.Buffer)
-	assert.NoError(t, runStop(out, fakemachine.NewClient(), false, false, jsonFormat))
+	assert.NoError(t, runStop(out, fakemachine.NewClient(), false, false, machine.DefaultStopTimeout, jsonFormat))
 	assert.JSONEq(t, `{"success": true, "forced": false}`, out.String())
 }
 
 func TestStopJSONError(t *testing.T) {
 	out := new(bytes.Buffer)
-	assert.NoError(t, runStop(out, fakemachine.NewFailingClient(), false, false, jsonFormat))
-	assert.JSONEq(t, `{"success": false, "forced": false, "error": "stop failed"}`, out.String())
+	assert.EqualError(t, runStop(out, fakemachine.NewFailingClient(), false, false, machine.DefaultStopTimeout, jsonFormat), "stop failed")
+	assert.JSONEq(t, `{"success": false, "forced": false, "error": "stop failed", "exitCode": 1}`, out.String())
 }
 
 func TestStopWithForceJSONError(t *testing.T) {
 	out := new(bytes.Buffer)
-	assert.NoError(t, runStop(out, fakemachine.NewFailingClient(), false, true, jsonFormat))
-	assert.JSONEq(t, `{"success": false, "forced": true, "error": "poweroff failed"}`, out.String())
+	assert.EqualError(t, runStop(out, fakemachine.NewFailingClient(), false, true, machine.DefaultStopTimeout, jsonFormat), "poweroff failed")
+	assert.JSONEq(t, `{"success": false, "forced": true, "reason": "stop failed", "error": "poweroff failed", "exitCode": 1}`, out.String())
+}
+
+// timingOutClient never manages to shut down the instance gracefully
+type timingOutClient struct {
+	*fakemachine.Client
+}
+
+func (c *timingOutClient) Stop(stopConfig types.StopConfig) (state.State, error) {
+	return state.Running, &machine.StopTimeoutError{Timeout: stopConfig.Timeout}
+}
+
+func TestStopWithForceAfterTimeout(t *testing.T) {
+	client := &timingOutClient{fakemachine.NewClient()}
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, runStop(out, client, true, true, time.Minute, ""))
+	assert.Equal(t, "Forcibly stopped the instance\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, runStop(out, client, false, true, time.Minute, jsonFormat))
+	assert.JSONEq(t, `{"success": true, "forced": true, "reason": "The instance did not shut down within 1m0s"}`, out.String())
+
+	out.Reset()
+	assert.EqualError(t, runStop(out, client, false, false, time.Minute, jsonFormat), "The instance did not shut down within 1m0s")
+	assert.JSONEq(t, `{"success": false, "forced": false, "error": "The instance did not shut down within 1m0s", "exitCode": 1}`, out.String())
 }
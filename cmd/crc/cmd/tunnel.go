@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/tunnel"
+	"github.com/spf13/cobra"
+)
+
+var (
+	tunnelBasePort int
+	tunnelInterval time.Duration
+)
+
+func init() {
+	tunnelCmd.Flags().IntVar(&tunnelBasePort, "base-port", 9080, "First local port used to forward the routes")
+	tunnelCmd.Flags().DurationVar(&tunnelInterval, "interval", 5*time.Second, "Interval between two updates of the routes")
+	rootCmd.AddCommand(tunnelCmd)
+}
+
+var tunnelCmd = &cobra.Command{
+	Use:   "tunnel",
+	Short: "Forward local ports to the routes of the OpenShift cluster",
+	Long: `Watch the routes of the OpenShift cluster and forward a port on 127.0.0.1 to each of them, until interrupted.
+The plain HTTP routes are available on http://127.0.0.1:<port>. The TLS connections are passed through to the router,
+which selects the route with the server name sent by the client, such as with
+'curl --connect-to ::127.0.0.1:<port> https://<route host>'.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+		return runTunnel(ctx, os.Stdout, newMachine(), tunnelBasePort, tunnelInterval)
+	},
+}
+
+func runTunnel(ctx context.Context, writer io.Writer, client machine.Client, basePort int, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("Invalid interval %s, it must be positive", interval)
+	}
+	if err := checkIfMachineMissing(client); err != nil {
+		return err
+	}
+	connectionDetails, err := client.ConnectionDetails()
+	if err != nil {
+		return err
+	}
+	routesTunnel := tunnel.New(connectionDetails.IP, basePort)
+	defer routesTunnel.Close()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		routes, err := client.Routes()
+		if err != nil {
+			logging.Warnf("Cannot list the routes: %v", err)
+		} else if routesTunnel.Update(routes) {
+			if _, err := fmt.Fprintf(writer, "\033[H\033[2J%s\n\n", time.Now().Format(time.RFC1123)); err != nil {
+				return err
+			}
+			if err := renderTunnelMappings(writer, routesTunnel.Mappings()); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func renderTunnelMappings(writer io.Writer, mappings []tunnel.Mapping) error {
+	w := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "NAMESPACE\tROUTE\tHOST\tLOCAL"); err != nil {
+		return err
+	}
+	for _, mapping := range mappings {
+		local := fmt.Sprintf("http://%s", mapping.Local)
+		if mapping.IsTLS() {
+			local = fmt.Sprintf("%s (TLS passthrough)", mapping.Local)
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", mapping.Route.Namespace, mapping.Route.Name, mapping.Route.Host, local); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
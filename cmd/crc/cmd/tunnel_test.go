@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/tunnel"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderTunnelMappings(t *testing.T) {
+	out := new(bytes.Buffer)
+	require.NoError(t, renderTunnelMappings(out, []tunnel.Mapping{
+		{
+			Route: types.Route{Namespace: "demo", Name: "hello", Host: "hello-demo.apps-crc.testing"},
+			Local: "127.0.0.1:9080",
+		},
+		{
+			Route: types.Route{Namespace: "openshift-console", Name: "console", Host: "console-openshift-console.apps-crc.testing", Termination: "reencrypt"},
+			Local: "127.0.0.1:9081",
+		},
+	}))
+	assert.Equal(t, `NAMESPACE          ROUTE    HOST                                        LOCAL
+demo               hello    hello-demo.apps-crc.testing                 http://127.0.0.1:9080
+openshift-console  console  console-openshift-console.apps-crc.testing  127.0.0.1:9081 (TLS passthrough)
+`, out.String())
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	crcpreset "github.com/code-ready/crc/pkg/crc/preset"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addOutputFormatFlag(upgradeCmd)
+	rootCmd.AddCommand(upgradeCmd)
+}
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade [BUNDLE]",
+	Short: "Upgrade the instance to a newer bundle",
+	Long: `Upgrade the instance to a newer bundle
+
+The persistent volumes, the persistent volume claims of the user namespaces
+and the users added to the cluster are backed up to ~/.crc/upgrade-backup.
+The instance is then recreated from the new bundle, and the backup is
+restored in it. The pull secret and the kubeadmin password are kept, the
+other resources of the cluster, like the deployments, are not.
+
+BUNDLE is a file, an HTTP(S) URL or a docker:// registry reference, it is
+the bundle of this crc version by default. It is downloaded when needed, and
+becomes the bundle of the configuration.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		preset := crcConfig.GetPreset(config)
+		bundlePath := constants.GetDefaultBundlePath(preset)
+		if len(args) == 1 {
+			bundlePath = args[0]
+		}
+		if err := fetchBundle(bundlePath, preset, !config.Get(crcConfig.SkipBundleVerification).AsBool()); err != nil {
+			return err
+		}
+		if err := checkDaemonStarted(); err != nil {
+			return err
+		}
+		startConfig := newStartConfig(cluster.NewInteractivePullSecretLoader(config), false)
+		startConfig.BundlePath = bundlePath
+		return runUpgrade(cmd.Context(), os.Stdout, newMachine(), startConfig, outputFormat)
+	},
+}
+
+// fetchBundle downloads the bundle unless it is already on the host
+func fetchBundle(bundlePath string, preset crcpreset.Preset, verify bool) error {
+	if bundle.IsRemote(bundlePath) {
+		if _, ok := bundle.LocalPath(bundlePath); ok {
+			return nil
+		}
+		_, err := bundle.DownloadRemote(bundlePath, verify)
+		return err
+	}
+	if _, err := os.Stat(bundlePath); err == nil {
+		if bundlePath != constants.GetDefaultBundlePath(preset) {
+			logging.Warnf("Using %s without verifying it, only the downloaded bundles are verified", bundlePath)
+		}
+		return nil
+	}
+	if _, err := bundle.Get(filepath.Base(bundlePath)); err == nil {
+		return nil
+	}
+	if bundlePath != constants.GetDefaultBundlePath(preset) {
+		return fmt.Errorf("%s does not exist", bundlePath)
+	}
+	logging.Infof("Downloading %s", filepath.Base(bundlePath))
+	return bundle.Download(preset, verify)
+}
+
+func runUpgrade(ctx context.Context, writer io.Writer, client machine.Client, startConfig types.StartConfig, outputFormat string) error {
+	result, err := upgradeMachine(ctx, client, startConfig)
+	return render(toUpgradeResult(result, err), writer, outputFormat)
+}
+
+func upgradeMachine(ctx context.Context, client machine.Client, startConfig types.StartConfig) (*types.UpgradeResult, error) {
+	if err := checkIfMachineMissing(client); err != nil {
+		return nil, err
+	}
+	result, err := client.Upgrade(ctx, startConfig)
+	if err != nil {
+		return nil, err
+	}
+	// the next 'crc start' must use the bundle of the instance
+	if config.Get(crcConfig.Bundle).AsString() != startConfig.BundlePath {
+		if _, err := config.Set(crcConfig.Bundle, startConfig.BundlePath); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func toUpgradeResult(result *types.UpgradeResult, err error) *upgradeResult {
+	ret := &upgradeResult{
+		Success:     err == nil,
+		errorResult: newErrorResult(err),
+	}
+	if result == nil {
+		return ret
+	}
+	ret.PreviousVersion = result.PreviousVersion
+	ret.Version = result.Version
+	ret.Bundle = result.Bundle
+	ret.BackupDir = result.BackupDir
+	ret.RestoredVolumes = result.RestoredVolumes
+	ret.RestoredClaims = result.RestoredClaims
+	ret.RestoredUsers = result.RestoredUsers
+	if result.StartResult != nil {
+		ret.ClusterConfig = toClusterConfig(result.StartResult)
+	}
+	return ret
+}
+
+type upgradeResult struct {
+	Success bool `json:"success"`
+	errorResult
+	PreviousVersion string         `json:"previousVersion,omitempty"`
+	Version         string         `json:"version,omitempty"`
+	Bundle          string         `json:"bundle,omitempty"`
+	BackupDir       string         `json:"backupDir,omitempty"`
+	RestoredVolumes bool           `json:"restoredVolumes"`
+	RestoredClaims  int            `json:"restoredClaims"`
+	RestoredUsers   []string       `json:"restoredUsers,omitempty"`
+	ClusterConfig   *clusterConfig `json:"clusterConfig,omitempty"`
+}
+
+func (s *upgradeResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	lines := []string{
+		fmt.Sprintf("Upgraded the instance from OpenShift %s to OpenShift %s", s.PreviousVersion, s.Version),
+	}
+	if s.RestoredVolumes {
+		lines = append(lines, "Restored the persistent volumes")
+	}
+	if s.RestoredClaims > 0 {
+		lines = append(lines, fmt.Sprintf("Restored %d persistent volume claims", s.RestoredClaims))
+	}
+	if len(s.RestoredUsers) > 0 {
+		lines = append(lines, fmt.Sprintf("Restored the users: %s", strings.Join(s.RestoredUsers, ", ")))
+	}
+	lines = append(lines, fmt.Sprintf("The backup of the previous instance is kept in %s", s.BackupDir))
+	_, err := fmt.Fprintln(writer, strings.Join(lines, "\n"))
+	return err
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/code-ready/crc/pkg/crc/upgrade"
+	crcversion "github.com/code-ready/crc/pkg/crc/version"
+	"github.com/spf13/cobra"
+)
+
+// checkUpgradeAdvisory detects the first invocation after an upgrade, and
+// prints the changes which need an action before the next 'crc start'. The
+// daemon only records the advisory, it is available through its API.
+func checkUpgradeAdvisory(cmd *cobra.Command) {
+	store := upgrade.NewStore(constants.UpgradeStatePath)
+	if err := store.Detect(crcversion.GetCRCVersion(), upgradeEnvironment); err != nil {
+		logging.Debugf("Cannot detect crc upgrade: %v", err)
+		return
+	}
+	if cmd == daemonCmd {
+		return
+	}
+	advisory, err := store.Unshown()
+	if err != nil {
+		logging.Debugf("Cannot read the upgrade advisory: %v", err)
+		return
+	}
+	if advisory == nil {
+		return
+	}
+	if len(advisory.Changes) == 0 {
+		logging.Infof("%s, no action is needed", advisory)
+		return
+	}
+	logging.Warnf("%s, the following changes need your attention:", advisory)
+	for _, change := range advisory.Changes {
+		logging.Warnf("- %s", change.Description)
+	}
+}
+
+func upgradeEnvironment() upgrade.Environment {
+	bundlePath, ok := bundle.LocalPath(config.Get(crcConfig.Bundle).AsString())
+	_, err := os.Stat(bundlePath)
+	env := upgrade.Environment{
+		BundlePath:   bundlePath,
+		BundleExists: ok && err == nil,
+	}
+	if env.InstanceBundleName, err = machine.InstanceBundleName(constants.DefaultName); err != nil {
+		logging.Debugf("Cannot get the bundle of the existing instance: %v", err)
+	}
+	if env.UnknownSettings, err = unknownSettings(constants.ConfigPath, config); err != nil {
+		logging.Debugf("Cannot read the configuration file: %v", err)
+	}
+	return env
+}
+
+// unknownSettings returns the settings of the configuration file which are
+// not registered, they were usually renamed or removed by an upgrade
+func unknownSettings(configFile string, cfg *crcConfig.Config) ([]string, error) {
+	data, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+	var settings map[string]interface{}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, err
+	}
+	known := cfg.AllConfigs()
+	var unknown []string
+	for name := range settings {
+		if _, ok := known[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown, nil
+}
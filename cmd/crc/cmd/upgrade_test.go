@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func upgradeStartConfig() types.StartConfig {
+	return types.StartConfig{BundlePath: config.Get(crcConfig.Bundle).AsString()}
+}
+
+func TestUpgradePlainSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runUpgrade(context.Background(), out, fakemachine.NewClient(), upgradeStartConfig(), ""))
+	assert.Equal(t, `Upgraded the instance from OpenShift 4.10.3 to OpenShift 4.10.4
+Restored the persistent volumes
+Restored 2 persistent volume claims
+Restored the users: alice
+The backup of the previous instance is kept in /home/user/.crc/upgrade-backup
+`, out.String())
+}
+
+func TestUpgradePlainError(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runUpgrade(context.Background(), out, fakemachine.NewFailingClient(), upgradeStartConfig(), ""), "upgrade failed")
+}
+
+func TestUpgradeJSONError(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runUpgrade(context.Background(), out, fakemachine.NewFailingClient(), upgradeStartConfig(), jsonFormat), "upgrade failed")
+	assert.JSONEq(t, `{"success": false, "error": "upgrade failed", "exitCode": 1, "restoredVolumes": false, "restoredClaims": 0}`, out.String())
+}
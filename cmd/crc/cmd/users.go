@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/code-ready/crc/pkg/crc/preset"
+	"github.com/spf13/cobra"
+)
+
+var (
+	userPassword      string
+	userPasswordStdin bool
+	userClusterAdmin  bool
+)
+
+func init() {
+	for _, cmd := range []*cobra.Command{usersListCmd, usersAddCmd, usersRemoveCmd, usersSetPasswordCmd, usersGrantAdminCmd, usersRevokeAdminCmd} {
+		addOutputFormatFlag(cmd)
+		usersCmd.AddCommand(cmd)
+	}
+	for _, cmd := range []*cobra.Command{usersAddCmd, usersSetPasswordCmd} {
+		cmd.Flags().StringVar(&userPassword, "password", "", "Password of the user, a random password is generated when it is not set")
+		cmd.Flags().BoolVar(&userPasswordStdin, "password-stdin", false, "Read the password of the user from the standard input")
+	}
+	usersAddCmd.Flags().BoolVar(&userClusterAdmin, "cluster-admin", false, "Grant the cluster-admin role to the user")
+	rootCmd.AddCommand(usersCmd)
+}
+
+var usersCmd = &cobra.Command{
+	Use:   "users SUBCOMMAND [flags]",
+	Short: "Manage the users of the cluster",
+	Long: `Manage the users of the cluster
+
+The users log in with the htpasswd identity provider of the cluster, like
+the developer and kubeadmin users. They are stored in the machine directory
+and applied again to the cluster on every start.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var usersListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the users added to the cluster",
+	Long:  "List the users added to the cluster",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runUsersList(os.Stdout, userStore(), outputFormat)
+	},
+}
+
+var usersAddCmd = &cobra.Command{
+	Use:   "add NAME",
+	Short: "Add a user to the cluster",
+	Long:  "Add a user to the cluster",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runUsersChange(os.Stdout, newMachine(), outputFormat, func(store *cluster.UserStore) (*usersResult, error) {
+			password, generated, err := readUserPassword(cmd.InOrStdin())
+			if err != nil {
+				return nil, err
+			}
+			if err := store.Add(args[0], password, userClusterAdmin); err != nil {
+				return nil, err
+			}
+			result := &usersResult{Message: fmt.Sprintf("Added the user %s", args[0])}
+			if generated {
+				result.Credentials = &credentials{Username: args[0], Password: password}
+			}
+			return result, nil
+		})
+	},
+}
+
+var usersRemoveCmd = &cobra.Command{
+	Use:   "remove NAME",
+	Short: "Remove a user from the cluster",
+	Long:  "Remove a user from the cluster, with its identities and its cluster-admin role",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runUsersChange(os.Stdout, newMachine(), outputFormat, func(store *cluster.UserStore) (*usersResult, error) {
+			if err := store.Remove(args[0]); err != nil {
+				return nil, err
+			}
+			return &usersResult{Message: fmt.Sprintf("Removed the user %s", args[0]), removed: args[0]}, nil
+		})
+	},
+}
+
+var usersSetPasswordCmd = &cobra.Command{
+	Use:   "set-password NAME",
+	Short: "Change the password of a user",
+	Long:  "Change the password of a user",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runUsersChange(os.Stdout, newMachine(), outputFormat, func(store *cluster.UserStore) (*usersResult, error) {
+			password, generated, err := readUserPassword(cmd.InOrStdin())
+			if err != nil {
+				return nil, err
+			}
+			if err := store.SetPassword(args[0], password); err != nil {
+				return nil, err
+			}
+			result := &usersResult{Message: fmt.Sprintf("Changed the password of the user %s", args[0])}
+			if generated {
+				result.Credentials = &credentials{Username: args[0], Password: password}
+			}
+			return result, nil
+		})
+	},
+}
+
+var usersGrantAdminCmd = &cobra.Command{
+	Use:   "grant-admin NAME",
+	Short: "Grant the cluster-admin role to a user",
+	Long:  "Grant the cluster-admin role to a user",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runUsersChange(os.Stdout, newMachine(), outputFormat, func(store *cluster.UserStore) (*usersResult, error) {
+			if err := store.SetClusterAdmin(args[0], true); err != nil {
+				return nil, err
+			}
+			return &usersResult{Message: fmt.Sprintf("Granted the cluster-admin role to the user %s", args[0])}, nil
+		})
+	},
+}
+
+var usersRevokeAdminCmd = &cobra.Command{
+	Use:   "revoke-admin NAME",
+	Short: "Revoke the cluster-admin role of a user",
+	Long:  "Revoke the cluster-admin role of a user",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runUsersChange(os.Stdout, newMachine(), outputFormat, func(store *cluster.UserStore) (*usersResult, error) {
+			if err := store.SetClusterAdmin(args[0], false); err != nil {
+				return nil, err
+			}
+			return &usersResult{Message: fmt.Sprintf("Revoked the cluster-admin role of the user %s", args[0])}, nil
+		})
+	},
+}
+
+func userStore() *cluster.UserStore {
+	return cluster.NewUserStore(constants.GetUsersPath())
+}
+
+// readUserPassword returns the password of the flags, or a random one when
+// it is not set
+func readUserPassword(stdin io.Reader) (string, bool, error) {
+	if userPasswordStdin {
+		if userPassword != "" {
+			return "", false, errors.New("--password and --password-stdin cannot be used together")
+		}
+		password, err := bufio.NewReader(stdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", false, err
+		}
+		return strings.TrimRight(password, "\r\n"), false, nil
+	}
+	if userPassword != "" {
+		return userPassword, false, nil
+	}
+	password, err := cluster.GenerateRandomPasswordHash(23)
+	return password, true, err
+}
+
+type userInfo struct {
+	Name         string `json:"name"`
+	ClusterAdmin bool   `json:"clusterAdmin"`
+}
+
+type usersResult struct {
+	Success bool `json:"success"`
+	errorResult
+	Message     string       `json:"message,omitempty"`
+	Users       []userInfo   `json:"users,omitempty"`
+	Credentials *credentials `json:"credentials,omitempty"`
+	// Applied is false when the instance is stopped, the change is then
+	// applied by the next start
+	Applied bool `json:"applied"`
+
+	removed string
+	list    bool
+}
+
+func runUsersList(writer io.Writer, store *cluster.UserStore, outputFormat string) error {
+	users, err := store.List()
+	result := &usersResult{
+		Success:     err == nil,
+		errorResult: newErrorResult(err),
+		Users:       []userInfo{},
+		Applied:     true,
+		list:        true,
+	}
+	for _, user := range users {
+		result.Users = append(result.Users, userInfo{Name: user.Name, ClusterAdmin: user.ClusterAdmin})
+	}
+	return render(result, writer, outputFormat)
+}
+
+// runUsersChange records the change of the users in the machine directory,
+// and applies it to the cluster when the instance is running
+func runUsersChange(writer io.Writer, client machine.Client, outputFormat string, change func(store *cluster.UserStore) (*usersResult, error)) error {
+	result, err := changeUsers(client, userStore(), change)
+	if result == nil {
+		result = &usersResult{}
+	}
+	result.Success = err == nil
+	result.errorResult = newErrorResult(err)
+	return render(result, writer, outputFormat)
+}
+
+func changeUsers(client machine.Client, store *cluster.UserStore, change func(store *cluster.UserStore) (*usersResult, error)) (*usersResult, error) {
+	if err := checkIfMachineMissing(client); err != nil {
+		return nil, err
+	}
+	if client.GetPreset() != preset.OpenShift {
+		return nil, fmt.Errorf("This command is only supported with the %s preset", preset.OpenShift)
+	}
+	result, err := change(store)
+	if err != nil {
+		return nil, err
+	}
+	running, err := client.IsRunning()
+	if err != nil || !running {
+		return result, err
+	}
+	runner, err := runningSSHRunner(client)
+	if err != nil {
+		return result, err
+	}
+	defer runner.Close()
+	users, err := store.List()
+	if err != nil {
+		return result, err
+	}
+	var removed []string
+	if result.removed != "" {
+		removed = append(removed, result.removed)
+	}
+	if err := cluster.ApplyUsers(context.Background(), oc.UseOCWithSSH(runner), users, removed...); err != nil {
+		return result, err
+	}
+	result.Applied = true
+	return result, nil
+}
+
+func (s *usersResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	if s.list {
+		if len(s.Users) == 0 {
+			_, err := fmt.Fprintln(writer, "No users were added to the cluster")
+			return err
+		}
+		w := tabwriter.NewWriter(writer, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tCLUSTER-ADMIN")
+		for _, user := range s.Users {
+			fmt.Fprintf(w, "%s\t%t\n", user.Name, user.ClusterAdmin)
+		}
+		return w.Flush()
+	}
+	lines := []string{s.Message}
+	if s.Credentials != nil {
+		lines = append(lines, fmt.Sprintf("Its password is %s", s.Credentials.Password))
+	}
+	if !s.Applied {
+		lines = append(lines, "The instance is not running, the change will be applied by the next start")
+	}
+	_, err := fmt.Fprintln(writer, strings.Join(lines, "\n"))
+	return err
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsersListPlain(t *testing.T) {
+	store := cluster.NewUserStore(filepath.Join(t.TempDir(), "users.json"))
+	out := new(bytes.Buffer)
+	assert.NoError(t, runUsersList(out, store, ""))
+	assert.Equal(t, "No users were added to the cluster\n", out.String())
+
+	assert.NoError(t, store.Add("bob", "secret", false))
+	assert.NoError(t, store.Add("alice", "secret", true))
+	out.Reset()
+	assert.NoError(t, runUsersList(out, store, ""))
+	assert.Equal(t, `NAME   CLUSTER-ADMIN
+alice  true
+bob    false
+`, out.String())
+}
+
+func TestUsersListJSON(t *testing.T) {
+	store := cluster.NewUserStore(filepath.Join(t.TempDir(), "users.json"))
+	assert.NoError(t, store.Add("alice", "secret", true))
+	out := new(bytes.Buffer)
+	assert.NoError(t, runUsersList(out, store, jsonFormat))
+	assert.JSONEq(t, `{"success": true, "applied": true, "users": [{"name": "alice", "clusterAdmin": true}]}`, out.String())
+}
@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 
+	"github.com/code-ready/crc/pkg/crc/cache"
 	crcConfig "github.com/code-ready/crc/pkg/crc/config"
 	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
 	crcversion "github.com/code-ready/crc/pkg/crc/version"
 	"github.com/spf13/cobra"
 )
@@ -32,11 +35,32 @@ func runPrintVersion(writer io.Writer, version *version, outputFormat string) er
 	return render(version, writer, outputFormat)
 }
 
+// version has the versions of the bundles of this release in
+// openshiftVersion and podmanVersion, and the versions of the bundle in use
+// in bundle
 type version struct {
+	Version          string             `json:"version"`
+	Commit           string             `json:"commit"`
+	OpenshiftVersion string             `json:"openshiftVersion"`
+	PodmanVersion    string             `json:"podmanVersion"`
+	Bundle           *bundleVersion     `json:"bundle,omitempty"`
+	Components       []componentVersion `json:"components,omitempty"`
+}
+
+type bundleVersion struct {
+	Name              string `json:"name"`
+	Preset            string `json:"preset"`
+	OpenshiftVersion  string `json:"openshiftVersion,omitempty"`
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+	PodmanVersion     string `json:"podmanVersion,omitempty"`
+}
+
+// componentVersion is the version of an executable used by crc, the version
+// expected by this release and the version found in the crc directory
+type componentVersion struct {
+	Name             string `json:"name"`
 	Version          string `json:"version"`
-	Commit           string `json:"commit"`
-	OpenshiftVersion string `json:"openshiftVersion"`
-	PodmanVersion    string `json:"podmanVersion"`
+	InstalledVersion string `json:"installedVersion,omitempty"`
 }
 
 func defaultVersion() *version {
@@ -45,7 +69,49 @@ func defaultVersion() *version {
 		Commit:           crcversion.GetCommitSha(),
 		OpenshiftVersion: crcversion.GetBundleVersion(),
 		PodmanVersion:    crcversion.GetPodmanVersion(),
+		Bundle:           currentBundleVersion(config.Get(crcConfig.Bundle).AsString()),
+		Components:       componentVersions(cache.Components()),
+	}
+}
+
+// currentBundleVersion returns the versions of the bundle of the bundle
+// setting, nil when it is not extracted yet
+func currentBundleVersion(bundlePath string) *bundleVersion {
+	if bundlePath == "" {
+		return nil
+	}
+	bundleInfo, err := bundle.Get(filepath.Base(bundlePath))
+	if err != nil {
+		logging.Debugf("Cannot read the metadata of the bundle %s: %v", bundlePath, err)
+		return nil
+	}
+	result := &bundleVersion{
+		Name:   bundleInfo.GetBundleName(),
+		Preset: string(bundleInfo.GetBundleType()),
 	}
+	if bundleInfo.IsOpenShift() {
+		result.OpenshiftVersion = bundleInfo.GetOpenshiftVersion()
+		result.KubernetesVersion = bundleInfo.GetKubernetesVersion()
+	} else {
+		result.PodmanVersion = bundleInfo.GetPodmanVersion()
+	}
+	return result
+}
+
+func componentVersions(caches []*cache.Cache) []componentVersion {
+	var components []componentVersion
+	for _, c := range caches {
+		installed, err := c.InstalledVersion()
+		if err != nil {
+			logging.Debugf("Cannot get the version of %s: %v", c.GetExecutablePath(), err)
+		}
+		components = append(components, componentVersion{
+			Name:             c.GetExecutableName(),
+			Version:          c.ExpectedVersion(),
+			InstalledVersion: installed,
+		})
+	}
+	return components
 }
 
 func (v *version) prettyPrintTo(writer io.Writer) error {
@@ -58,9 +124,27 @@ func (v *version) prettyPrintTo(writer io.Writer) error {
 }
 
 func (v *version) lines() []string {
-	return []string{
+	lines := []string{
 		fmt.Sprintf("CodeReady Containers version: %s+%s\n", v.Version, v.Commit),
 		fmt.Sprintf("OpenShift version: %s\n", v.OpenshiftVersion),
 		fmt.Sprintf("Podman version: %s\n", v.PodmanVersion),
 	}
+	if v.Bundle != nil {
+		if v.Bundle.OpenshiftVersion != "" {
+			lines = append(lines, fmt.Sprintf("Bundle in use: %s (OpenShift %s, Kubernetes %s)\n", v.Bundle.Name, v.Bundle.OpenshiftVersion, v.Bundle.KubernetesVersion))
+		} else {
+			lines = append(lines, fmt.Sprintf("Bundle in use: %s (Podman %s)\n", v.Bundle.Name, v.Bundle.PodmanVersion))
+		}
+	}
+	for _, component := range v.Components {
+		switch component.InstalledVersion {
+		case "":
+			lines = append(lines, fmt.Sprintf("%s version: %s (not installed)\n", component.Name, component.Version))
+		case component.Version:
+			lines = append(lines, fmt.Sprintf("%s version: %s\n", component.Name, component.Version))
+		default:
+			lines = append(lines, fmt.Sprintf("%s version: %s (%s installed)\n", component.Name, component.Version, component.InstalledVersion))
+		}
+	}
+	return lines
 }
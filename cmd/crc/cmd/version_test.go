@@ -33,3 +33,54 @@ func TestJsonVersion(t *testing.T) {
 	expected := `{"version": "1.13", "commit": "aabbcc", "openshiftVersion": "4.5.4", "podmanVersion": "3.4.4"}`
 	assert.JSONEq(t, expected, out.String())
 }
+
+func TestVersionWithComponents(t *testing.T) {
+	v := &version{
+		Version:          "1.13",
+		Commit:           "aabbcc",
+		OpenshiftVersion: "4.10.3",
+		PodmanVersion:    "3.4.4",
+		Bundle: &bundleVersion{
+			Name:              "crc_libvirt_4.10.3_amd64.crcbundle",
+			Preset:            "openshift",
+			OpenshiftVersion:  "4.10.3",
+			KubernetesVersion: "1.23",
+		},
+		Components: []componentVersion{
+			{Name: "crc-driver-libvirt", Version: "0.13.2", InstalledVersion: "0.13.2"},
+			{Name: "crc-admin-helper-linux", Version: "0.0.10", InstalledVersion: "0.0.9"},
+			{Name: "vfkit", Version: "0.0.2"},
+		},
+	}
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, runPrintVersion(out, v, ""))
+	assert.Equal(t, `CodeReady Containers version: 1.13+aabbcc
+OpenShift version: 4.10.3
+Podman version: 3.4.4
+Bundle in use: crc_libvirt_4.10.3_amd64.crcbundle (OpenShift 4.10.3, Kubernetes 1.23)
+crc-driver-libvirt version: 0.13.2
+crc-admin-helper-linux version: 0.0.10 (0.0.9 installed)
+vfkit version: 0.0.2 (not installed)
+`, out.String())
+
+	out.Reset()
+	assert.NoError(t, runPrintVersion(out, v, "json"))
+	assert.JSONEq(t, `{
+  "version": "1.13",
+  "commit": "aabbcc",
+  "openshiftVersion": "4.10.3",
+  "podmanVersion": "3.4.4",
+  "bundle": {
+    "name": "crc_libvirt_4.10.3_amd64.crcbundle",
+    "preset": "openshift",
+    "openshiftVersion": "4.10.3",
+    "kubernetesVersion": "1.23"
+  },
+  "components": [
+    {"name": "crc-driver-libvirt", "version": "0.13.2", "installedVersion": "0.13.2"},
+    {"name": "crc-admin-helper-linux", "version": "0.0.10", "installedVersion": "0.0.9"},
+    {"name": "vfkit", "version": "0.0.2"}
+  ]
+}`, out.String())
+}
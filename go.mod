@@ -9,6 +9,7 @@ require (
 	github.com/RedHatQE/gowinx v0.0.3
 	github.com/StackExchange/wmi v1.2.1
 	github.com/YourFin/binappend v0.0.0-20181105185800-0add4bf0b9ad
+	github.com/alexbrainman/sspi v0.0.0-20180613141037-e580b900e9f5
 	github.com/asaskevich/govalidator v0.0.0-20210307081110-f21760c49a8d
 	github.com/cavaliercoder/grab v1.0.1-0.20210912125936-718223ba0a5a
 	github.com/cheggaaa/pb/v3 v3.0.8
@@ -24,7 +25,7 @@ require (
 	github.com/fatih/color v1.13.0 // indirect
 	github.com/felixge/httpsnoop v1.0.2 // indirect
 	github.com/go-ole/go-ole v1.2.6 // indirect
-	github.com/godbus/dbus/v5 v5.0.6 // indirect
+	github.com/godbus/dbus/v5 v5.0.6
 	github.com/gofrs/uuid v4.1.0+incompatible // indirect
 	github.com/google/uuid v1.3.0 // indirect
 	github.com/gorilla/handlers v1.5.1
@@ -38,6 +39,7 @@ require (
 	github.com/mattn/go-colorable v0.1.11
 	github.com/mdlayher/vsock v1.1.1
 	github.com/mgutz/ansi v0.0.0-20200706080929-d51e80ef957d // indirect
+	github.com/miekg/dns v1.1.46
 	github.com/onsi/ginkgo v1.16.5
 	github.com/onsi/gomega v1.18.1
 	github.com/openshift/api v0.0.0-20210730095913-85e1d547cdee
@@ -65,10 +67,12 @@ require (
 	golang.org/x/term v0.0.0-20210927222741-03fcf44c2211
 	golang.org/x/text v0.3.7
 	gopkg.in/ini.v1 v1.64.0 // indirect
+	gvisor.dev/gvisor v0.0.0-20220121190119-4f2d380c8b55
 	k8s.io/api v0.22.0-rc.0
 	k8s.io/apimachinery v0.22.0-rc.0
 	k8s.io/client-go v0.22.0-rc.0
 	libvirt.org/go/libvirtxml v1.7010.0
+	sigs.k8s.io/yaml v1.2.0
 )
 
 replace (
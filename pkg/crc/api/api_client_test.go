@@ -1,6 +1,8 @@
 package api
 
 import (
+	"bytes"
+	gocontext "context"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -32,7 +34,8 @@ func newTestClient() *testClient {
 	fakeMachine := fakemachine.NewClient()
 	config := setupNewInMemoryConfig()
 
-	ts := httptest.NewServer(NewMux(config, fakeMachine, &mockLogger{}, &mockTelemetry{}))
+	mux, _ := NewMux(config, fakeMachine, &mockLogger{}, &mockTelemetry{}, nil)
+	ts := httptest.NewServer(mux)
 
 	return &testClient{
 		apiClient.New(http.DefaultClient, ts.URL),
@@ -78,6 +81,26 @@ func TestStatus(t *testing.T) {
 	)
 }
 
+func TestStatusWithComponents(t *testing.T) {
+	client := newTestClient()
+	defer client.Close()
+	statusResult, err := client.StatusWithComponents()
+	assert.NoError(t, err)
+	assert.Len(t, statusResult.Components, 3)
+	assert.Equal(t, "kube-apiserver", statusResult.Components[0].Name)
+	assert.Equal(t, int64(1_200_000_000), statusResult.Components[0].Memory)
+}
+
+func TestLogs(t *testing.T) {
+	client := newTestClient()
+	defer client.Close()
+	var out bytes.Buffer
+	assert.NoError(t, client.Logs(gocontext.Background(), "serial", false, &out))
+	assert.Equal(t, "dummy serial logs\n", out.String())
+	assert.EqualError(t, client.Logs(gocontext.Background(), "audit", false, &out),
+		"Error occurred sending GET request to : /instance-logs : 400 : Unknown log component 'audit'")
+}
+
 func TestStart(t *testing.T) {
 	client := newTestClient()
 	defer client.Close()
@@ -244,7 +267,8 @@ func TestTelemetry(t *testing.T) {
 	config := setupNewInMemoryConfig()
 
 	telemetry := &mockTelemetry{}
-	ts := httptest.NewServer(NewMux(config, fakeMachine, &mockLogger{}, telemetry))
+	mux, _ := NewMux(config, fakeMachine, &mockLogger{}, telemetry, nil)
+	ts := httptest.NewServer(mux)
 	defer ts.Close()
 
 	client := apiClient.New(http.DefaultClient, ts.URL)
@@ -255,6 +279,27 @@ func TestTelemetry(t *testing.T) {
 	assert.Equal(t, []string{"click start", "click stop"}, telemetry.actions)
 }
 
+func TestRestart(t *testing.T) {
+	fakeMachine := fakemachine.NewClient()
+	var executables []string
+	restart := func(executable string) error {
+		executables = append(executables, executable)
+		return nil
+	}
+	mux, _ := NewMux(setupNewInMemoryConfig(), fakeMachine, &mockLogger{}, &mockTelemetry{}, restart)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	client := apiClient.New(http.DefaultClient, ts.URL)
+
+	assert.EqualError(t, client.Restart("/usr/local/bin/crc"), "Error occurred sending POST request to : /restart : 409")
+	assert.Empty(t, executables)
+
+	fakeMachine.Stopped = true
+	assert.NoError(t, client.Restart("/usr/local/bin/crc"))
+	assert.Equal(t, []string{"/usr/local/bin/crc"}, executables)
+}
+
 func TestPullSecret(t *testing.T) {
 	dir, err := ioutil.TempDir("", "test-pull-secret")
 	assert.NoError(t, err)
@@ -263,7 +308,8 @@ func TestPullSecret(t *testing.T) {
 	fakeMachine := fakemachine.NewClient()
 	config := setupNewInMemoryConfig()
 
-	ts := httptest.NewServer(NewMux(config, fakeMachine, &mockLogger{}, &mockTelemetry{}))
+	mux, _ := NewMux(config, fakeMachine, &mockLogger{}, &mockTelemetry{}, nil)
+	ts := httptest.NewServer(mux)
 	defer ts.Close()
 
 	client := apiClient.New(http.DefaultClient, ts.URL)
@@ -5,17 +5,45 @@ import (
 
 	"github.com/code-ready/crc/pkg/crc/cluster"
 	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/daemonclient"
 	"github.com/code-ready/crc/pkg/crc/machine"
 )
 
-func NewMux(config *crcConfig.Config, machine machine.Client, logger Logger, telemetry Telemetry) http.Handler {
+// NewMux returns the handler of the daemon API, and the handler of its
+// metrics endpoint alone, which the daemon can also serve on a TCP port.
+// restart is called to restart the daemon with a new executable, it can be
+// nil when it is not supported.
+func NewMux(config *crcConfig.Config, machine machine.Client, logger Logger, telemetry Telemetry, restart func(executable string) error) (http.Handler, http.Handler) {
 	handler := NewHandler(config, machine, logger, telemetry)
+	handler.Restart = restart
 
 	server := newServerWithRoutes(handler)
 
-	return server.Handler()
+	mux := http.NewServeMux()
+	mux.Handle("/", handler.throttler.Wrap(server.Handler()))
+	for _, path := range longRunningPaths {
+		mux.Handle(path, handler.throttler.WrapLongRunning(server.Handler()))
+	}
+	// the scrapes of a monitoring system are not throttled, the status of
+	// the instance they report is cached
+	mux.Handle(metricsPath, server.Handler())
+
+	metricsMux := http.NewServeMux()
+	metricsMux.Handle(metricsPath, server.Handler())
+	return handler.metrics.Wrap(mux), handler.metrics.Wrap(metricsMux)
 }
 
+// longRunningPaths are the streams and the operations which can take
+// minutes, they don't count in the concurrent requests
+var longRunningPaths = []string{"/start", "/stop", "/poweroff", "/delete", "/data", instanceLogsPath}
+
+// metricsPath serves the metrics in the Prometheus text format
+const metricsPath = "/metrics"
+
+// instanceLogsPath streams the logs of the instance, unlike /logs which
+// returns the messages of the daemon
+const instanceLogsPath = "/instance-logs"
+
 func newServerWithRoutes(handler *Handler) *server {
 	server := newServer()
 
@@ -31,16 +59,27 @@ func newServerWithRoutes(handler *Handler) *server {
 
 	server.DELETE("/delete", handler.Delete)
 	server.GET("/delete", handler.Delete)
+	server.GET("/delete-plan", handler.DeletePlan)
+	server.DELETE("/data", handler.DeleteData)
 
 	server.GET("/version", handler.GetVersion)
 
+	server.POST("/restart", handler.RestartDaemon)
+
+	server.GET("/upgrade-advisory", handler.GetUpgradeAdvisory)
+
 	server.GET("/webconsoleurl", handler.GetWebconsoleInfo)
 
+	server.GET("/ip", handler.GetAddresses)
+
 	server.GET("/config", handler.GetConfig)
 	server.POST("/config", handler.SetConfig)
 	server.DELETE("/config", handler.UnsetConfig)
 
 	server.GET("/logs", handler.Logs)
+	server.GET(instanceLogsPath, handler.InstanceLogs)
+
+	server.GET(metricsPath, handler.Metrics)
 
 	server.GET("/telemetry", handler.UploadTelemetry)
 	server.POST("/telemetry", handler.UploadTelemetry)
@@ -48,9 +87,17 @@ func newServerWithRoutes(handler *Handler) *server {
 	server.GET("/pull-secret", getPullSecret(handler.Config))
 	server.POST("/pull-secret", setPullSecret())
 
+	server.GET("/port-forwards", listPortForwards(handler.PortForwards))
+	server.POST("/port-forwards", addPortForward(handler.PortForwards, handler.Client, daemonNetworkClient))
+	server.DELETE("/port-forwards", removePortForward(handler.PortForwards, handler.Client, daemonNetworkClient))
+
 	return server
 }
 
+func daemonNetworkClient() portForwarder {
+	return daemonclient.New().NetworkClient
+}
+
 func setPullSecret() func(c *context) error {
 	return func(c *context) error {
 		if err := cluster.StoreInKeyring(string(c.requestBody)); err != nil {
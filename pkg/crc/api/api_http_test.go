@@ -8,12 +8,16 @@ import (
 	"net/http/httptest"
 	"net/http/httputil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/code-ready/crc/pkg/crc/audit"
 	crcConfig "github.com/code-ready/crc/pkg/crc/config"
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/upgrade"
 	"github.com/code-ready/crc/pkg/crc/version"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -41,13 +45,20 @@ func removePullSecret(t *testing.T, server *mockServer) {
 	assert.NoError(t, err)
 }
 
-func newMockServer(pullSecretPath string) *mockServer {
+func newMockServer(t *testing.T, pullSecretPath string) *mockServer {
 	fakeMachine := fakemachine.NewClient()
 
 	config := setupNewInMemoryConfig()
 	_, _ = config.Set(crcConfig.PullSecretFile, pullSecretPath)
 
 	handler := NewHandler(config, fakeMachine, &mockLogger{}, &mockTelemetry{})
+	dir := t.TempDir()
+	handler.PortForwards = network.NewPortForwardStore(filepath.Join(dir, "port-forwards.json"))
+	handler.Upgrade = upgrade.NewStore(filepath.Join(dir, "last-version.json"))
+	handler.Audit = audit.NewLog(filepath.Join(dir, "audit.jsonl"))
+	handler.Restart = func(executable string) error {
+		return nil
+	}
 
 	return &mockServer{
 		server: newServerWithRoutes(handler),
@@ -94,6 +105,8 @@ type response struct {
 	protoMinor int
 	// headers
 	body string
+	// bodyPrefix is set when the end of the body varies between runs
+	bodyPrefix bool
 }
 
 type testCase struct {
@@ -163,6 +176,12 @@ func (resp response) withBody(body string) response {
 	return resp
 }
 
+func (resp response) withBodyPrefix(prefix string) response {
+	resp.body = prefix
+	resp.bodyPrefix = true
+	return resp
+}
+
 var testCases = []testCase{
 	// start
 	{
@@ -227,7 +246,7 @@ var testCases = []testCase{
 	// status
 	{
 		request:  get("status"),
-		response: jSon(`{"CrcStatus":"Running","OpenshiftStatus":"Running","OpenshiftVersion":"4.5.1","PodmanVersion":"3.3.1","DiskUse":10000000000,"DiskSize":20000000000,"Preset":"openshift"}`),
+		response: jSon(`{"CrcStatus":"Running","OpenshiftStatus":"Running","OpenshiftVersion":"4.5.1","PodmanVersion":"3.3.1","DiskUse":10000000000,"DiskSize":20000000000,"PVPoolUse":0,"PVPoolSize":0,"Preset":"openshift"}`),
 	},
 
 	// status with failure
@@ -284,6 +303,19 @@ var testCases = []testCase{
 		response: httpError(500).withBody("console failed\n"),
 	},
 
+	// ip
+	{
+		request:  get("ip"),
+		response: jSon(`{"Addresses":[{"Name":"host","IP":"127.0.0.1","Description":"Address of the instance used from the host and in the hosts file"},{"Name":"instance","IP":"192.168.127.2","Description":"Address of the instance on the user mode network"},{"Name":"node","IP":"192.168.126.11","Description":"Internal IP of the OpenShift node"}]}`),
+	},
+
+	// ip with failure
+	{
+		request:     get("ip"),
+		failRequest: true,
+		response:    httpError(500).withBody("addresses failed\n"),
+	},
+
 	// config
 	{
 		request:  get("config?cpus"),
@@ -310,6 +342,39 @@ var testCases = []testCase{
 
 	// logs never fails
 
+	// delete-plan
+	{
+		request:  get("delete-plan?scope=vm"),
+		response: httpError(400).withBody("Unknown deletion scope 'vm'"),
+	},
+
+	// data
+	{
+		request:  delete("data?scope=all"),
+		response: httpError(409).withBody("The instance must be deleted first"),
+	},
+
+	// restart
+	{
+		request:  post("restart").withBody(`{"executable":"/usr/local/bin/crc"}`),
+		response: httpError(409).withBody("The daemon cannot be restarted while the instance is running, stop it with 'crc stop' first"),
+	},
+
+	// instance-logs
+	{
+		request:  get("instance-logs?component=kubelet"),
+		response: empty().withBody("dummy kubelet logs\n"),
+	},
+	{
+		request:  get("instance-logs?component=audit"),
+		response: httpError(400).withBody("Unknown log component 'audit'"),
+	},
+	{
+		request:     get("instance-logs"),
+		response:    httpError(500).withBody("broken\n"),
+		failRequest: true,
+	},
+
 	// telemetry
 	{
 		request:  get("telemetry"),
@@ -357,6 +422,42 @@ var testCases = []testCase{
 		response:    httpError(500).withBody("empty pull secret\n"),
 	},
 
+	// metrics, the durations of the starts and the host memory vary
+	{
+		request: get("metrics"),
+		response: httpError(200).withBodyPrefix(`# HELP crc_api_requests_total Number of API requests received
+# TYPE crc_api_requests_total counter
+# HELP crc_api_throttled_requests_total Number of API requests rejected because of rate limiting
+# TYPE crc_api_throttled_requests_total counter
+# HELP crc_api_queued_requests_total Number of API requests which waited in the queue
+# TYPE crc_api_queued_requests_total counter
+# HELP crc_api_http_requests_total Number of API requests by method, path and status code
+# TYPE crc_api_http_requests_total counter
+# HELP crc_start_duration_seconds Duration of the starts of the instance requested through the API
+# TYPE crc_start_duration_seconds histogram
+`),
+	},
+
+	// upgrade-advisory
+	{
+		request:  get("upgrade-advisory"),
+		response: jSon(`{"Advisory":null}`),
+	},
+
+	// port-forwards
+	{
+		request:  get("port-forwards"),
+		response: jSon(`{"PortForwards":[]}`),
+	},
+	{
+		request:  post("port-forwards").withBody(`{"spec": "8080:80"}`),
+		response: httpError(400).withBody("Invalid port forwarding '8080:80', expected <host port>:machine:<guest port>"),
+	},
+	{
+		request:  delete("port-forwards").withBody(`{"spec": "8080:machine:80"}`),
+		response: httpError(400).withBody("No port forwarding for host port 8080/tcp"),
+	},
+
 	// not found
 	{
 		request:  get("notfound"),
@@ -419,6 +520,12 @@ var invalidHTTPMethods = []testCase{
 		response: httpError(404).withBody("Not Found\n"),
 	},
 
+	// upgrade-advisory
+	{
+		request:  post("upgrade-advisory"),
+		response: httpError(404).withBody("Not Found\n"),
+	},
+
 	// webconsoleurl
 	{
 		request:  post("webconsoleurl"),
@@ -429,6 +536,12 @@ var invalidHTTPMethods = []testCase{
 		response: httpError(404).withBody("Not Found\n"),
 	},
 
+	// ip
+	{
+		request:  post("ip"),
+		response: httpError(404).withBody("Not Found\n"),
+	},
+
 	// logs
 	{
 		request:  post("logs"),
@@ -439,6 +552,30 @@ var invalidHTTPMethods = []testCase{
 		response: httpError(404).withBody("Not Found\n"),
 	},
 
+	// delete-plan
+	{
+		request:  post("delete-plan"),
+		response: httpError(404).withBody("Not Found\n"),
+	},
+
+	// data
+	{
+		request:  get("data"),
+		response: httpError(404).withBody("Not Found\n"),
+	},
+
+	// restart
+	{
+		request:  get("restart"),
+		response: httpError(404).withBody("Not Found\n"),
+	},
+
+	// instance-logs
+	{
+		request:  post("instance-logs"),
+		response: httpError(404).withBody("Not Found\n"),
+	},
+
 	// telemetry
 	{
 		request:  delete("telemetry"),
@@ -465,14 +602,18 @@ func testOne(t *testing.T, testCase *testCase, server *mockServer) {
 	require.Equal(t, testCase.response.protoMinor, resp.ProtoMinor, testCase.request)
 	body, err := ioutil.ReadAll(resp.Body)
 	require.NoError(t, err, testCase.request)
-	require.Equal(t, testCase.response.body, string(body), testCase.request)
+	if testCase.response.bodyPrefix {
+		require.True(t, strings.HasPrefix(string(body), testCase.response.body), testCase.request)
+	} else {
+		require.Equal(t, testCase.response.body, string(body), testCase.request)
+	}
 	fmt.Println("-----")
 }
 
 func TestRequests(t *testing.T) {
 	pullSecretPath := createDummyPullSecret(t)
 	defer os.Remove(pullSecretPath)
-	server := newMockServer(pullSecretPath)
+	server := newMockServer(t, pullSecretPath)
 
 	for i := range testCases {
 		testOne(t, &testCases[i], server)
@@ -496,7 +637,7 @@ func TestRoutes(t *testing.T) {
 		routes[pattern] = append(routes[pattern], testCase.request.httpMethod)
 	}
 
-	server := newMockServer("")
+	server := newMockServer(t, "")
 	for pattern, methodMap := range server.routes {
 		assert.Contains(t, routes, pattern)
 		for method := range methodMap {
@@ -2,6 +2,7 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,6 +10,9 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/version"
 )
 
 type Client struct {
@@ -49,6 +53,21 @@ func (c *Client) Status() (ClusterStatusResult, error) {
 	return sr, nil
 }
 
+// StatusWithComponents returns the status with the resource usage of the
+// control plane components and of the pods using the most memory
+func (c *Client) StatusWithComponents() (ClusterStatusResult, error) {
+	var sr = ClusterStatusResult{}
+	body, err := c.sendGetRequest("/status?components=true")
+	if err != nil {
+		return sr, err
+	}
+	err = json.Unmarshal(body, &sr)
+	if err != nil {
+		return sr, err
+	}
+	return sr, nil
+}
+
 func (c *Client) Start(config StartConfig) (StartResult, error) {
 	var sr = StartResult{}
 	var data = new(bytes.Buffer)
@@ -79,6 +98,39 @@ func (c *Client) Delete() error {
 	return err
 }
 
+// DeletePlan returns the files and directories removed by a deletion with
+// deleteConfig
+func (c *Client) DeletePlan(deleteConfig types.DeleteConfig) (DeletePathsResult, error) {
+	var result DeletePathsResult
+	body, err := c.sendGetRequest("/delete-plan?" + deleteQuery(deleteConfig))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(body, &result)
+	return result, err
+}
+
+// DeleteData removes the files and directories of the deletion scope, the
+// instance must be deleted first
+func (c *Client) DeleteData(deleteConfig types.DeleteConfig) (DeletePathsResult, error) {
+	var result DeletePathsResult
+	body, err := c.sendDeleteRequest("/data?"+deleteQuery(deleteConfig), nil)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(body, &result)
+	return result, err
+}
+
+func deleteQuery(deleteConfig types.DeleteConfig) string {
+	query := url.Values{}
+	query.Set("scope", string(deleteConfig.Scope))
+	if deleteConfig.KeepBundleCache {
+		query.Set("keep-bundle-cache", "true")
+	}
+	return query.Encode()
+}
+
 func (c *Client) WebconsoleURL() (ConsoleResult, error) {
 	var cr = ConsoleResult{}
 	body, err := c.sendGetRequest("/webconsoleurl")
@@ -92,6 +144,19 @@ func (c *Client) WebconsoleURL() (ConsoleResult, error) {
 	return cr, nil
 }
 
+func (c *Client) Addresses() (AddressesResult, error) {
+	var ar = AddressesResult{}
+	body, err := c.sendGetRequest("/ip")
+	if err != nil {
+		return ar, err
+	}
+	err = json.Unmarshal(body, &ar)
+	if err != nil {
+		return ar, err
+	}
+	return ar, nil
+}
+
 func (c *Client) GetConfig(configs []string) (GetConfigResult, error) {
 	var gcr = GetConfigResult{}
 	var escapeConfigs []string
@@ -169,7 +234,7 @@ func (c *Client) Telemetry(action string) error {
 }
 
 func (c *Client) IsPullSecretDefined() (bool, error) {
-	res, err := c.client.Get(fmt.Sprintf("%s%s", c.base, "/pull-secret"))
+	res, err := c.get(fmt.Sprintf("%s%s", c.base, "/pull-secret"))
 	if err != nil {
 		return false, err
 	}
@@ -185,8 +250,114 @@ func (c *Client) SetPullSecret(data string) error {
 	return nil
 }
 
+func (c *Client) UpgradeAdvisory() (UpgradeAdvisoryResult, error) {
+	var uar = UpgradeAdvisoryResult{}
+	body, err := c.sendGetRequest("/upgrade-advisory")
+	if err != nil {
+		return uar, err
+	}
+	err = json.Unmarshal(body, &uar)
+	if err != nil {
+		return uar, err
+	}
+	return uar, nil
+}
+
+func (c *Client) PortForwards() (PortForwardsResult, error) {
+	var pfr = PortForwardsResult{}
+	body, err := c.sendGetRequest("/port-forwards")
+	if err != nil {
+		return pfr, err
+	}
+	err = json.Unmarshal(body, &pfr)
+	if err != nil {
+		return pfr, err
+	}
+	return pfr, nil
+}
+
+func (c *Client) AddPortForward(spec string) error {
+	data, err := json.Marshal(PortForwardRequest{
+		Spec: spec,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to encode data to JSON: %w", err)
+	}
+	_, err = c.sendPostRequest("/port-forwards", bytes.NewReader(data))
+	return err
+}
+
+func (c *Client) RemovePortForward(spec string) error {
+	data, err := json.Marshal(PortForwardRequest{
+		Spec: spec,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to encode data to JSON: %w", err)
+	}
+	_, err = c.sendDeleteRequest("/port-forwards", bytes.NewReader(data))
+	return err
+}
+
+// Restart asks the daemon to restart itself with executable, it returns
+// before the restart is done
+func (c *Client) Restart(executable string) error {
+	data, err := json.Marshal(RestartRequest{
+		Executable: executable,
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to encode data to JSON: %w", err)
+	}
+	_, err = c.sendPostRequest("/restart", bytes.NewReader(data))
+	return err
+}
+
+// Logs copies the logs of the component of the instance to writer as they are
+// streamed by the daemon. With follow, it returns when ctx is cancelled.
+func (c *Client) Logs(ctx context.Context, component string, follow bool, writer io.Writer) error {
+	query := url.Values{}
+	query.Set("component", component)
+	if follow {
+		query.Set("follow", "true")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/instance-logs?%s", c.base, query.Encode()), nil)
+	if err != nil {
+		return err
+	}
+	res, err := c.do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(res.Body)
+		return fmt.Errorf("Error occurred sending GET request to : /instance-logs : %d : %s", res.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if _, err := io.Copy(writer, res.Body); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+// do sends the request with the crc user agent, the daemon names the
+// clients with it in its metrics
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	req.Header.Set("User-Agent", fmt.Sprintf("crc/%s", version.GetCRCVersion()))
+	return c.client.Do(req)
+}
+
+func (c *Client) get(url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(req)
+}
+
 func (c *Client) sendGetRequest(url string) ([]byte, error) {
-	res, err := c.client.Get(fmt.Sprintf("%s%s", c.base, url))
+	res, err := c.get(fmt.Sprintf("%s%s", c.base, url))
 	if err != nil {
 		return nil, err
 	}
@@ -216,7 +387,7 @@ func (c *Client) sendRequest(url string, method string, data io.Reader) ([]byte,
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	res, err := c.client.Do(req)
+	res, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -1,8 +1,14 @@
 package client
 
 import (
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/crc/preset"
+	"github.com/code-ready/crc/pkg/crc/upgrade"
+	"github.com/code-ready/crc/pkg/crc/watchdog/events"
 )
 
 type VersionResult struct {
@@ -16,6 +22,8 @@ type StartResult struct {
 	Status         string
 	ClusterConfig  types.ClusterConfig
 	KubeletStarted bool
+	Operators      []cluster.OperatorInstallation `json:",omitempty"`
+	Manifests      *cluster.ManifestsApplication  `json:",omitempty"`
 }
 
 type ClusterStatusResult struct {
@@ -25,13 +33,27 @@ type ClusterStatusResult struct {
 	PodmanVersion    string
 	DiskUse          int64
 	DiskSize         int64
+	PVPoolUse        int64
+	PVPoolSize       int64
 	Preset           preset.Preset
+	Components       []cluster.ComponentUsage `json:",omitempty"`
+	Workers          []types.WorkerStatus     `json:",omitempty"`
+	NetworkConflicts []network.Conflict       `json:",omitempty"`
+	// WatchdogEvents are the last events of the watchdog of the daemon
+	WatchdogEvents []events.Event `json:",omitempty"`
+	// ClockSkew is how far the clock of the instance is ahead of the clock
+	// of the host
+	ClockSkew time.Duration `json:",omitempty"`
 }
 
 type ConsoleResult struct {
 	ClusterConfig types.ClusterConfig
 }
 
+type AddressesResult struct {
+	Addresses []types.Address
+}
+
 // setOrUnsetConfigResult struct is used to return the result of
 // setconfig/unsetconfig command
 type SetOrUnsetConfigResult struct {
@@ -60,3 +82,25 @@ type TelemetryRequest struct {
 	Source string `json:"source"`
 	Status string `json:"status"`
 }
+
+// DeletePathsResult lists the files and directories of a deletion scope
+type DeletePathsResult struct {
+	Paths []string
+}
+
+type RestartRequest struct {
+	// Executable is the crc executable the daemon restarts with
+	Executable string `json:"executable"`
+}
+
+type PortForwardRequest struct {
+	Spec string `json:"spec"`
+}
+
+type UpgradeAdvisoryResult struct {
+	Advisory *upgrade.Advisory
+}
+
+type PortForwardsResult struct {
+	PortForwards []network.PortForward
+}
@@ -2,23 +2,47 @@ package api
 
 import (
 	gocontext "context"
+	"fmt"
+	"io"
 	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/code-ready/crc/pkg/crc/api/client"
+	"github.com/code-ready/crc/pkg/crc/audit"
 	"github.com/code-ready/crc/pkg/crc/cluster"
 	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/crc/preflight"
+	"github.com/code-ready/crc/pkg/crc/upgrade"
 	"github.com/code-ready/crc/pkg/crc/version"
+	"github.com/code-ready/crc/pkg/crc/watchdog/events"
 )
 
 type Handler struct {
-	Logger    Logger
-	Client    machine.Client
-	Config    *crcConfig.Config
-	Telemetry Telemetry
+	Logger       Logger
+	Client       machine.Client
+	Config       *crcConfig.Config
+	Telemetry    Telemetry
+	PortForwards *network.PortForwardStore
+	Upgrade      *upgrade.Store
+	Watchdog     *events.Store
+	Audit        *audit.Log
+
+	// Restart restarts the daemon with the given executable, once the
+	// response is sent
+	Restart func(executable string) error
+
+	throttler *throttler
+	metrics   *metricsRecorder
+	status    *statusCache
 }
 
 type Logger interface {
@@ -39,20 +63,82 @@ func (h *Handler) Logs(c *context) error {
 	})
 }
 
+// RestartDaemon is used when the version of the daemon doesn't match the one
+// of the crc executable. The virtual network of the instance is served by the
+// daemon, it is not restarted while the instance is running.
+func (h *Handler) RestartDaemon(c *context) error {
+	if h.Restart == nil {
+		return c.String(http.StatusNotImplemented, "This daemon cannot restart itself")
+	}
+	var req client.RestartRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if running, _ := h.Client.IsRunning(); running {
+		return c.String(http.StatusConflict, "The daemon cannot be restarted while the instance is running, stop it with 'crc stop' first")
+	}
+	if err := h.Restart(req.Executable); err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	return c.Code(http.StatusOK)
+}
+
+func (h *Handler) InstanceLogs(c *context) error {
+	logsConfig := types.LogsConfig{
+		Component: types.KubeletLogs,
+		Follow:    c.url.Query().Get("follow") == "true",
+	}
+	if component := c.url.Query().Get("component"); component != "" {
+		logsConfig.Component = types.LogComponent(component)
+	}
+	if !logsConfig.Component.IsValid() {
+		return c.String(http.StatusBadRequest, fmt.Sprintf("Unknown log component '%s'", logsConfig.Component))
+	}
+	return c.Stream(http.StatusOK, "text/plain; charset=UTF-8", func(writer io.Writer) error {
+		return h.Client.Logs(c.ctx, logsConfig, writer)
+	})
+}
+
 func NewHandler(config *crcConfig.Config, machine machine.Client, logger Logger, telemetry Telemetry) *Handler {
 	return &Handler{
-		Client:    machine,
-		Config:    config,
-		Logger:    logger,
-		Telemetry: telemetry,
+		Client:       machine,
+		Config:       config,
+		Logger:       logger,
+		Telemetry:    telemetry,
+		PortForwards: network.NewPortForwardStore(constants.PortForwardsPath),
+		Upgrade:      upgrade.NewStore(constants.UpgradeStatePath),
+		Watchdog:     events.NewStore(constants.WatchdogEventsPath),
+		Audit:        audit.NewLog(constants.AuditLogPath),
+		throttler:    newThrottler(clientRequestRate, clientRequestBurst, maxConcurrentRequests),
+		metrics:      newMetricsRecorder(),
+		status:       newStatusCache(machine, statusCacheTTL),
 	}
 }
 
+func (h *Handler) Metrics(c *context) error {
+	var b strings.Builder
+	b.WriteString(h.throttler.metrics())
+	h.metrics.writeTo(&b)
+	writeInstanceMetrics(&b, h.status)
+	writeHostMetrics(&b)
+	return c.String(http.StatusOK, b.String())
+}
+
 func (h *Handler) Status(c *context) error {
 	res, err := h.Client.Status()
 	if err != nil {
 		return err
 	}
+	var components []cluster.ComponentUsage
+	if c.url.Query().Get("components") == "true" {
+		if components, err = h.Client.ComponentsUsage(); err != nil {
+			return err
+		}
+	}
+	watchdogEvents, err := h.Watchdog.List()
+	if err != nil {
+		return err
+	}
 	return c.JSON(http.StatusOK, client.ClusterStatusResult{
 		CrcStatus:        string(res.CrcStatus),
 		OpenshiftStatus:  string(res.OpenshiftStatus),
@@ -60,12 +146,22 @@ func (h *Handler) Status(c *context) error {
 		PodmanVersion:    res.PodmanVersion,
 		DiskUse:          res.DiskUse,
 		DiskSize:         res.DiskSize,
+		PVPoolUse:        res.PVPoolUse,
+		PVPoolSize:       res.PVPoolSize,
 		Preset:           res.Preset,
+		Components:       components,
+		Workers:          res.Workers,
+		NetworkConflicts: res.NetworkConflicts,
+		WatchdogEvents:   watchdogEvents,
+		ClockSkew:        res.ClockSkew,
 	})
 }
 
 func (h *Handler) Stop(c *context) error {
-	_, err := h.Client.Stop()
+	_, err := h.Client.Stop(types.StopConfig{
+		Timeout: machine.DefaultStopTimeout,
+	})
+	h.Audit.Record(audit.Daemon, audit.Stop, "", err)
 	if err != nil {
 		return err
 	}
@@ -76,7 +172,9 @@ func (h *Handler) PowerOff(c *context) error {
 	if c.method != http.MethodPost {
 		return c.String(http.StatusMethodNotAllowed, "Only POST is allowed")
 	}
-	if err := h.Client.PowerOff(); err != nil {
+	err := h.Client.PowerOff()
+	h.Audit.Record(audit.Daemon, audit.PowerOff, "", err)
+	if err != nil {
 		return err
 	}
 	return c.Code(http.StatusOK)
@@ -91,11 +189,15 @@ func (h *Handler) Start(c *context) error {
 		}
 	}
 	if err := preflight.StartPreflightChecks(h.Config); err != nil {
+		h.Audit.Record(audit.Daemon, audit.Start, "", err)
 		return err
 	}
 
 	startConfig := getStartConfig(h.Config, parsedArgs)
+	startTime := time.Now()
 	res, err := h.Client.Start(gocontext.Background(), startConfig)
+	h.metrics.observeStart(time.Since(startTime), err)
+	h.Audit.Record(audit.Daemon, audit.Start, fmt.Sprintf("preset %s", startConfig.Preset), err)
 	if err != nil {
 		return err
 	}
@@ -103,22 +205,63 @@ func (h *Handler) Start(c *context) error {
 		Status:         string(res.Status),
 		ClusterConfig:  res.ClusterConfig,
 		KubeletStarted: res.KubeletStarted,
+		Operators:      res.Operators,
+		Manifests:      res.Manifests,
 	})
 }
 
 func getStartConfig(cfg crcConfig.Storage, args client.StartConfig) types.StartConfig {
 	return types.StartConfig{
 		BundlePath:        cfg.Get(crcConfig.Bundle).AsString(),
-		Memory:            cfg.Get(crcConfig.Memory).AsInt(),
+		Memory:            crcConfig.GetMemory(cfg),
 		DiskSize:          cfg.Get(crcConfig.DiskSize).AsInt(),
-		CPUs:              cfg.Get(crcConfig.CPUs).AsInt(),
+		CPUs:              crcConfig.GetCPUs(cfg),
 		NameServer:        cfg.Get(crcConfig.NameServer).AsString(),
 		PullSecret:        cluster.NewNonInteractivePullSecretLoader(cfg, args.PullSecretFile),
 		KubeAdminPassword: cfg.Get(crcConfig.KubeAdminPassword).AsString(),
 		Preset:            crcConfig.GetPreset(cfg),
+		Workers:           cfg.Get(crcConfig.Workers).AsInt(),
+		Worker: types.WorkerConfig{
+			CPUs:   cfg.Get(crcConfig.WorkerCPUs).AsInt(),
+			Memory: cfg.Get(crcConfig.WorkerMemory).AsInt(),
+		},
+		ClusterDomain:        cfg.Get(crcConfig.ClusterDomain).AsString(),
+		DualStack:            cfg.Get(crcConfig.EnableDualStack).AsBool(),
+		HostPorts:            crcConfig.GetHostPorts(cfg),
+		AlternativeHostPorts: cfg.Get(crcConfig.AlternativeHostPorts).AsBool(),
+		EnableGPU:            cfg.Get(crcConfig.EnableGPU).AsBool(),
+		EnableMonitoring:     cfg.Get(crcConfig.EnableClusterMonitoring).AsBool(),
+		PVPoolSize:           cfg.Get(crcConfig.PVPoolSize).AsInt(),
+		SharedDirs:           crcConfig.GetSharedDirs(cfg),
+		SharedDirPassword:    sharedDirPassword(cfg),
+		ImageCache:           cfg.Get(crcConfig.EnableImageCache).AsBool(),
+		Operators:            crcConfig.GetEnableOperators(cfg),
+		Manifests:            cfg.Get(crcConfig.StartupManifests).AsString(),
+		ExtraIgnition:        cfg.Get(crcConfig.ExtraIgnition).AsString(),
+		HugePages:            cfg.Get(crcConfig.HugePages).AsInt(),
+		CgroupsVersion:       cfg.Get(crcConfig.CgroupsVersion).AsString(),
+		Sysctls:              crcConfig.GetSysctls(cfg),
+
+		NestedVirtualization:    cfg.Get(crcConfig.EnableNestedVirtualization).AsBool(),
+		EnableRosetta:           cfg.Get(crcConfig.EnableRosetta).AsBool(),
+		RotateKubeAdminPassword: cfg.Get(crcConfig.RotateKubeAdminPassword).AsBool(),
+		SkipKubeconfigUpdate:    !cfg.Get(crcConfig.UpdateKubeconfig).AsBool(),
 	}
 }
 
+// sharedDirPassword returns the password of the current user from the OS
+// credential store, the daemon cannot ask it, 'crc start' adds it there
+func sharedDirPassword(cfg crcConfig.Storage) string {
+	if runtime.GOOS != "windows" || len(crcConfig.GetSharedDirs(cfg)) == 0 {
+		return ""
+	}
+	password, err := cluster.LoadSharedDirPassword()
+	if err != nil {
+		logging.Debugf("Cannot load the password of the shared directories from the credential store: %v", err)
+	}
+	return password
+}
+
 func (h *Handler) GetVersion(c *context) error {
 	return c.JSON(http.StatusOK, &client.VersionResult{
 		CrcVersion:       version.GetCRCVersion(),
@@ -128,14 +271,87 @@ func (h *Handler) GetVersion(c *context) error {
 	})
 }
 
+func (h *Handler) GetUpgradeAdvisory(c *context) error {
+	advisory, err := h.Upgrade.Advisory()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, &client.UpgradeAdvisoryResult{
+		Advisory: advisory,
+	})
+}
+
 func (h *Handler) Delete(c *context) error {
 	err := h.Client.Delete()
+	h.Audit.Record(audit.Daemon, audit.Delete, "", err)
 	if err != nil {
 		return err
 	}
 	return c.Code(http.StatusOK)
 }
 
+// deleteConfig reads the deletion scope from the scope and
+// keep-bundle-cache query parameters
+func deleteConfig(c *context) (types.DeleteConfig, error) {
+	deleteConfig := types.DeleteConfig{
+		Scope:           types.DeleteInstance,
+		KeepBundleCache: c.url.Query().Get("keep-bundle-cache") == "true",
+	}
+	if scope := c.url.Query().Get("scope"); scope != "" {
+		deleteConfig.Scope = types.DeleteScope(scope)
+	}
+	if !deleteConfig.Scope.IsValid() {
+		return deleteConfig, fmt.Errorf("Unknown deletion scope '%s'", deleteConfig.Scope)
+	}
+	return deleteConfig, nil
+}
+
+// DeletePlan lists the files and directories removed by a deletion, without
+// removing them
+func (h *Handler) DeletePlan(c *context) error {
+	deleteConfig, err := deleteConfig(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	paths, err := machine.DeletePaths(h.Client.GetName(), deleteConfig)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, &client.DeletePathsResult{
+		Paths: paths,
+	})
+}
+
+// DeleteData removes the files and directories of the deletion scope once
+// the instance is deleted. The socket of the daemon is kept.
+func (h *Handler) DeleteData(c *context) error {
+	deleteConfig, err := deleteConfig(c)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	if exists, _ := h.Client.Exists(); exists {
+		return c.String(http.StatusConflict, "The instance must be deleted first")
+	}
+	paths, err := machine.DeletePaths(h.Client.GetName(), deleteConfig)
+	if err != nil {
+		return err
+	}
+	var removed []string
+	for _, path := range paths {
+		if path != constants.DaemonSocketPath {
+			removed = append(removed, path)
+		}
+	}
+	err = machine.RemovePaths(removed)
+	h.Audit.Record(audit.Daemon, audit.DeleteData, fmt.Sprintf("scope %s", deleteConfig.Scope), err)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, &client.DeletePathsResult{
+		Paths: removed,
+	})
+}
+
 func (h *Handler) GetWebconsoleInfo(c *context) error {
 	res, err := h.Client.GetConsoleURL()
 	if err != nil {
@@ -146,6 +362,16 @@ func (h *Handler) GetWebconsoleInfo(c *context) error {
 	})
 }
 
+func (h *Handler) GetAddresses(c *context) error {
+	addresses, err := h.Client.Addresses()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, client.AddressesResult{
+		Addresses: addresses,
+	})
+}
+
 func (h *Handler) SetConfig(c *context) error {
 	var req client.SetConfigRequest
 	if err := c.Bind(&req); err != nil {
@@ -167,6 +393,13 @@ func (h *Handler) SetConfig(c *context) error {
 		}
 		successProps = append(successProps, k)
 	}
+	// only the names of the properties are recorded, their values can be
+	// secrets
+	var keys []string
+	for k := range req.Properties {
+		keys = append(keys, k)
+	}
+	h.recordConfigChange(audit.ConfigSet, keys, multiError)
 	if len(multiError.Errors) != 0 {
 		return multiError
 	}
@@ -195,6 +428,7 @@ func (h *Handler) UnsetConfig(c *context) error {
 		}
 		successProps = append(successProps, key)
 	}
+	h.recordConfigChange(audit.ConfigUnset, req.Properties, multiError)
 	if len(multiError.Errors) != 0 {
 		return multiError
 	}
@@ -203,6 +437,16 @@ func (h *Handler) UnsetConfig(c *context) error {
 	})
 }
 
+func (h *Handler) recordConfigChange(operation audit.Operation, keys []string, multiError errors.MultiError) {
+	sorted := append([]string{}, keys...)
+	sort.Strings(sorted)
+	var err error
+	if len(multiError.Errors) != 0 {
+		err = multiError
+	}
+	h.Audit.Record(audit.Daemon, operation, strings.Join(sorted, ", "), err)
+}
+
 func (h *Handler) GetConfig(c *context) error {
 	crcConfig.UpdateDefaults(h.Config)
 	queries := c.url.Query()
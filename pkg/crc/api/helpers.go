@@ -1,7 +1,9 @@
 package api
 
 import (
+	gocontext "context"
 	"encoding/json"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -11,6 +13,7 @@ import (
 )
 
 type context struct {
+	ctx         gocontext.Context
 	method      string
 	requestBody []byte
 	url         *url.URL
@@ -18,6 +21,7 @@ type context struct {
 	code         int
 	headers      map[string]string
 	responseBody []byte
+	stream       func(io.Writer) error
 }
 
 func (c *context) Bind(r interface{}) error {
@@ -44,6 +48,50 @@ func (c *context) Code(code int) error {
 	return nil
 }
 
+// Stream sends the data written by stream as it is produced instead of
+// buffering the response. The response is only sent once stream writes
+// something, so that its errors can still be returned as a 500 error.
+func (c *context) Stream(code int, contentType string, stream func(io.Writer) error) error {
+	c.code = code
+	c.headers["Content-Type"] = contentType
+	c.stream = stream
+	return nil
+}
+
+// streamWriter sends the headers on the first write and flushes each write
+type streamWriter struct {
+	w       http.ResponseWriter
+	c       *context
+	started bool
+}
+
+func (s *streamWriter) Write(p []byte) (int, error) {
+	if !s.started {
+		s.started = true
+		for k, v := range s.c.headers {
+			s.w.Header().Set(k, v)
+		}
+		s.w.WriteHeader(s.c.code)
+	}
+	n, err := s.w.Write(p)
+	if flusher, ok := s.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return n, err
+}
+
+func (s *streamWriter) run() {
+	err := s.c.stream(s)
+	switch {
+	case err != nil && !s.started:
+		http.Error(s.w, err.Error(), http.StatusInternalServerError)
+	case err != nil:
+		logging.Error("Failed to stream response: ", err)
+	case !s.started:
+		_, _ = s.Write(nil)
+	}
+}
+
 type server struct {
 	routes     map[string]map[string]func(*context) error
 	routesLock sync.RWMutex
@@ -105,6 +153,7 @@ func (s *server) Handler() http.Handler {
 			return
 		}
 		c := &context{
+			ctx:         r.Context(),
 			method:      r.Method,
 			requestBody: requestBody,
 			headers:     make(map[string]string),
@@ -114,6 +163,10 @@ func (s *server) Handler() http.Handler {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+		if c.stream != nil {
+			(&streamWriter{w: w, c: c}).run()
+			return
+		}
 
 		w.WriteHeader(c.code)
 		for k, v := range c.headers {
@@ -0,0 +1,243 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/preset"
+	"github.com/pbnjay/memory"
+)
+
+// startDurationBuckets are the upper bounds in seconds of the buckets of the
+// histogram of the start durations, an OpenShift start takes minutes
+var startDurationBuckets = []float64{30, 60, 120, 180, 300, 420, 600, 900, 1200, 1800}
+
+var (
+	instanceStates    = []state.State{state.Running, state.Stopped, state.Starting, state.Stopping, state.Error}
+	openshiftStatuses = []types.OpenshiftStatus{types.OpenshiftRunning, types.OpenshiftStarting, types.OpenshiftDegraded,
+		types.OpenshiftUnreachable, types.OpenshiftStopping, types.OpenshiftStopped}
+)
+
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{
+		buckets: buckets,
+		counts:  make([]uint64, len(buckets)),
+	}
+}
+
+func (h *histogram) observe(value float64) {
+	for i, bound := range h.buckets {
+		if value <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += value
+	h.count++
+}
+
+func (h *histogram) writeTo(b *strings.Builder, name, labels string) {
+	for i, bound := range h.buckets {
+		fmt.Fprintf(b, "%s_bucket{%sle=%q} %d\n", name, labels, strconv.FormatFloat(bound, 'f', -1, 64), h.counts[i])
+	}
+	fmt.Fprintf(b, "%s_bucket{%sle=\"+Inf\"} %d\n", name, labels, h.count)
+	fmt.Fprintf(b, "%s_sum{%s} %s\n", name, strings.TrimSuffix(labels, ","), strconv.FormatFloat(h.sum, 'f', -1, 64))
+	fmt.Fprintf(b, "%s_count{%s} %d\n", name, strings.TrimSuffix(labels, ","), h.count)
+}
+
+type requestKey struct {
+	method string
+	path   string
+	code   int
+}
+
+// metricsRecorder records the API requests by path and the durations of the
+// starts requested through the API
+type metricsRecorder struct {
+	lock           sync.Mutex
+	requests       map[requestKey]uint64
+	startDurations map[string]*histogram
+}
+
+func newMetricsRecorder() *metricsRecorder {
+	return &metricsRecorder{
+		requests:       make(map[requestKey]uint64),
+		startDurations: make(map[string]*histogram),
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.code = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Wrap counts the requests by method, path and status code. The paths of the
+// requests which were not found are not kept, a client could send any path.
+func (m *metricsRecorder) Wrap(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+		handler.ServeHTTP(recorder, r)
+		path := r.URL.Path
+		if recorder.code == http.StatusNotFound {
+			path = "unknown"
+		}
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		m.requests[requestKey{method: r.Method, path: path, code: recorder.code}]++
+	})
+}
+
+func (m *metricsRecorder) observeStart(duration time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	h, ok := m.startDurations[result]
+	if !ok {
+		h = newHistogram(startDurationBuckets)
+		m.startDurations[result] = h
+	}
+	h.observe(duration.Seconds())
+}
+
+func (m *metricsRecorder) writeTo(b *strings.Builder) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	var keys []requestKey
+	for key := range m.requests {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].path != keys[j].path {
+			return keys[i].path < keys[j].path
+		}
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].code < keys[j].code
+	})
+	writeHeader(b, "crc_api_http_requests_total", "Number of API requests by method, path and status code", "counter")
+	for _, key := range keys {
+		fmt.Fprintf(b, "crc_api_http_requests_total{method=%q,path=%q,code=\"%d\"} %d\n", key.method, key.path, key.code, m.requests[key])
+	}
+
+	writeHeader(b, "crc_start_duration_seconds", "Duration of the starts of the instance requested through the API", "histogram")
+	for _, result := range []string{"success", "failure"} {
+		if h, ok := m.startDurations[result]; ok {
+			h.writeTo(b, "crc_start_duration_seconds", fmt.Sprintf("result=%q,", result))
+		}
+	}
+}
+
+func writeHeader(b *strings.Builder, name, help, metricType string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func writeGauge(b *strings.Builder, name, help string, value interface{}) {
+	writeHeader(b, name, help, "gauge")
+	fmt.Fprintf(b, "%s %v\n", name, value)
+}
+
+func boolValue(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+// statusCacheTTL is how long the status of the instance is reused for the
+// metrics, getting it runs commands in the instance and queries the cluster
+const statusCacheTTL = 30 * time.Second
+
+// statusCache keeps the status of the instance for the scrapes of the
+// metrics, a monitoring system can scrape them every few seconds
+type statusCache struct {
+	lock    sync.Mutex
+	client  machine.Client
+	ttl     time.Duration
+	status  *types.ClusterStatusResult
+	err     error
+	updated time.Time
+}
+
+func newStatusCache(client machine.Client, ttl time.Duration) *statusCache {
+	return &statusCache{
+		client: client,
+		ttl:    ttl,
+	}
+}
+
+// get returns the cached status, it is only refreshed when it is older than
+// the TTL, the concurrent scrapes wait for the same refresh
+func (c *statusCache) get() (*types.ClusterStatusResult, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.updated.IsZero() || time.Since(c.updated) >= c.ttl {
+		c.status, c.err = c.client.Status()
+		c.updated = time.Now()
+	}
+	return c.status, c.err
+}
+
+// writeInstanceMetrics writes the state and the resource usage of the
+// instance, the usage is only known when it is running
+func writeInstanceMetrics(b *strings.Builder, cache *statusCache) {
+	status, err := cache.get()
+	if err != nil {
+		logging.Debugf("Cannot get the status of the instance for the metrics: %v", err)
+		return
+	}
+	writeHeader(b, "crc_instance_state", "State of the instance, 1 for the current state", "gauge")
+	for _, s := range instanceStates {
+		fmt.Fprintf(b, "crc_instance_state{state=%q} %d\n", s, boolValue(status.CrcStatus == s))
+	}
+	if status.Preset == preset.OpenShift {
+		writeHeader(b, "crc_openshift_status", "Status of the OpenShift cluster, 1 for the current status", "gauge")
+		for _, s := range openshiftStatuses {
+			fmt.Fprintf(b, "crc_openshift_status{status=%q} %d\n", s, boolValue(status.OpenshiftStatus == s))
+		}
+	}
+	if status.CrcStatus != state.Running {
+		return
+	}
+	writeGauge(b, "crc_instance_disk_used_bytes", "Disk space used in the instance", status.DiskUse)
+	writeGauge(b, "crc_instance_disk_size_bytes", "Size of the disk of the instance", status.DiskSize)
+	writeGauge(b, "crc_instance_memory_used_bytes", "Memory used in the instance", status.RAMUse)
+	writeGauge(b, "crc_instance_memory_size_bytes", "Memory of the instance", status.RAMSize)
+}
+
+func writeHostMetrics(b *strings.Builder) {
+	writeGauge(b, "crc_host_cpus", "Number of CPUs of the host", runtime.NumCPU())
+	writeGauge(b, "crc_host_memory_total_bytes", "Total memory of the host", memory.TotalMemory())
+	writeGauge(b, "crc_host_memory_free_bytes", "Free memory of the host", memory.FreeMemory())
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogram(t *testing.T) {
+	h := newHistogram([]float64{60, 300})
+	h.observe(45)
+	h.observe(200)
+	h.observe(1000)
+
+	var b strings.Builder
+	h.writeTo(&b, "crc_start_duration_seconds", `result="success",`)
+	assert.Equal(t, `crc_start_duration_seconds_bucket{result="success",le="60"} 1
+crc_start_duration_seconds_bucket{result="success",le="300"} 2
+crc_start_duration_seconds_bucket{result="success",le="+Inf"} 3
+crc_start_duration_seconds_sum{result="success"} 1245
+crc_start_duration_seconds_count{result="success"} 3
+`, b.String())
+}
+
+func TestMetricsRecorder(t *testing.T) {
+	recorder := newMetricsRecorder()
+	handler := recorder.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/status" {
+			http.Error(w, "Not Found", http.StatusNotFound)
+		}
+	}))
+	for _, path := range []string{"/status", "/status", "/random"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	recorder.observeStart(4*time.Minute, nil)
+	recorder.observeStart(10*time.Second, errors.New("failed"))
+
+	var b strings.Builder
+	recorder.writeTo(&b)
+	assert.Contains(t, b.String(), `crc_api_http_requests_total{method="GET",path="/status",code="200"} 2
+crc_api_http_requests_total{method="GET",path="unknown",code="404"} 1
+`)
+	assert.Contains(t, b.String(), `crc_start_duration_seconds_count{result="success"} 1
+`)
+	assert.Contains(t, b.String(), `crc_start_duration_seconds_bucket{result="failure",le="30"} 1
+`)
+}
+
+func TestMetricsEndpoint(t *testing.T) {
+	mux, _ := NewMux(setupNewInMemoryConfig(), fakemachine.NewClient(), &mockLogger{}, &mockTelemetry{}, nil)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	_, err := http.Get(ts.URL + "/status")
+	require.NoError(t, err)
+	res, err := http.Get(ts.URL + "/metrics")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Contains(t, string(body), `crc_api_http_requests_total{method="GET",path="/status",code="200"} 1
+`)
+	assert.Contains(t, string(body), `crc_instance_state{state="Running"} 1
+crc_instance_state{state="Stopped"} 0
+`)
+	assert.Contains(t, string(body), `crc_openshift_status{status="Running"} 1
+`)
+	assert.Contains(t, string(body), "crc_instance_disk_used_bytes 10000000000\n")
+	assert.Contains(t, string(body), "# TYPE crc_host_memory_total_bytes gauge\n")
+}
+
+// countingClient counts the status requests sent to the machine
+type countingClient struct {
+	machine.Client
+	statusCalls int
+}
+
+func (c *countingClient) Status() (*types.ClusterStatusResult, error) {
+	c.statusCalls++
+	return c.Client.Status()
+}
+
+func TestStatusCache(t *testing.T) {
+	client := &countingClient{Client: fakemachine.NewClient()}
+	cache := newStatusCache(client, time.Hour)
+	for i := 0; i < 3; i++ {
+		status, err := cache.get()
+		require.NoError(t, err)
+		assert.Equal(t, state.Running, status.CrcStatus)
+	}
+	assert.Equal(t, 1, client.statusCalls)
+
+	cache = newStatusCache(client, 0)
+	_, _ = cache.get()
+	_, _ = cache.get()
+	assert.Equal(t, 3, client.statusCalls)
+}
+
+func TestMetricsHandler(t *testing.T) {
+	_, metricsMux := NewMux(setupNewInMemoryConfig(), fakemachine.NewClient(), &mockLogger{}, &mockTelemetry{}, nil)
+	ts := httptest.NewServer(metricsMux)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/metrics")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	// only the metrics are served on the TCP port
+	res, err = http.Get(ts.URL + "/status")
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestMetricsNotThrottled(t *testing.T) {
+	mux, _ := NewMux(setupNewInMemoryConfig(), fakemachine.NewClient(), &mockLogger{}, &mockTelemetry{}, nil)
+	for i := 0; i < 2*clientRequestBurst; i++ {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		require.Equal(t, http.StatusOK, recorder.Code)
+	}
+}
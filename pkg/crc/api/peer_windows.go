@@ -0,0 +1,30 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	kernel32 = windows.NewLazySystemDLL("kernel32.dll")
+
+	procGetNamedPipeClientProcessID = kernel32.NewProc("GetNamedPipeClientProcessId")
+)
+
+// peerOf returns the pid of the process connected to the named pipe of the
+// daemon
+func peerOf(conn net.Conn) (string, error) {
+	pipe, ok := conn.(interface{ Fd() uintptr })
+	if !ok {
+		return "", fmt.Errorf("unexpected connection type %T", conn)
+	}
+	var pid uint32
+	ret, _, err := procGetNamedPipeClientProcessID.Call(pipe.Fd(), uintptr(unsafe.Pointer(&pid)))
+	if ret == 0 {
+		return "", fmt.Errorf("Cannot get the process of the named pipe client: %v", err)
+	}
+	return fmt.Sprintf("pid %d", pid), nil
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/code-ready/crc/pkg/crc/api/client"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
+)
+
+// portForwarder is implemented by the gvisor-tap-vsock network client
+type portForwarder interface {
+	Expose(req *types.ExposeRequest) error
+	Unexpose(req *types.UnexposeRequest) error
+}
+
+func listPortForwards(store *network.PortForwardStore) func(c *context) error {
+	return func(c *context) error {
+		forwards, err := store.List()
+		if err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, client.PortForwardsResult{
+			PortForwards: forwards,
+		})
+	}
+}
+
+// addPortForward applies the port forwarding immediately when the instance
+// is running, and persists it once it is applied, it is applied on the next
+// start otherwise.
+func addPortForward(store *network.PortForwardStore, machine machine.Client, forwarder func() portForwarder) func(c *context) error {
+	return func(c *context) error {
+		var req client.PortForwardRequest
+		if err := c.Bind(&req); err != nil {
+			return err
+		}
+		pf, err := network.ParsePortForward(req.Spec)
+		if err != nil {
+			return c.String(http.StatusBadRequest, err.Error())
+		}
+		if err := store.Check(pf); err != nil {
+			return c.String(http.StatusBadRequest, err.Error())
+		}
+		running, _ := machine.IsRunning()
+		if running {
+			if err := exposePortForward(forwarder(), pf); err != nil {
+				return err
+			}
+		}
+		if err := store.Add(pf); err != nil {
+			if running {
+				unexposePortForward(forwarder(), pf)
+			}
+			return c.String(http.StatusBadRequest, err.Error())
+		}
+		return c.Code(http.StatusCreated)
+	}
+}
+
+// exposePortForward exposes the port forwarding on all the loopback
+// addresses, or on none of them when one of them fails
+func exposePortForward(forwarder portForwarder, pf network.PortForward) error {
+	for i, exposeRequest := range pf.ExposeRequests(constants.VSockVirtualMachineIP, network.LoopbackAddresses()) {
+		exposeRequest := exposeRequest
+		if err := forwarder.Expose(&exposeRequest); err != nil {
+			for _, unexposeRequest := range pf.UnexposeRequests(network.LoopbackAddresses())[:i] {
+				unexposeRequest := unexposeRequest
+				if err := forwarder.Unexpose(&unexposeRequest); err != nil {
+					logging.Debugf("Cannot remove the port forwarding of %s: %v", unexposeRequest.Local, err)
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func unexposePortForward(forwarder portForwarder, pf network.PortForward) {
+	for _, unexposeRequest := range pf.UnexposeRequests(network.LoopbackAddresses()) {
+		unexposeRequest := unexposeRequest
+		if err := forwarder.Unexpose(&unexposeRequest); err != nil {
+			logging.Debugf("Cannot remove the port forwarding of %s: %v", unexposeRequest.Local, err)
+		}
+	}
+}
+
+func removePortForward(store *network.PortForwardStore, machine machine.Client, forwarder func() portForwarder) func(c *context) error {
+	return func(c *context) error {
+		var req client.PortForwardRequest
+		if err := c.Bind(&req); err != nil {
+			return err
+		}
+		pf, err := network.ParsePortForward(req.Spec)
+		if err != nil {
+			return c.String(http.StatusBadRequest, err.Error())
+		}
+		if _, err := store.Remove(pf.HostPort, pf.Protocol); err != nil {
+			return c.String(http.StatusBadRequest, err.Error())
+		}
+		if running, _ := machine.IsRunning(); running {
+			for _, unexposeRequest := range pf.UnexposeRequests(network.LoopbackAddresses()) {
+				unexposeRequest := unexposeRequest
+				if err := forwarder().Unexpose(&unexposeRequest); err != nil {
+					return err
+				}
+			}
+		}
+		return c.Code(http.StatusOK)
+	}
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPortForwarder struct {
+	exposed []types.ExposeRequest
+	// failOn is the local address which cannot be exposed
+	failOn string
+}
+
+func (f *mockPortForwarder) Expose(req *types.ExposeRequest) error {
+	if req.Local == f.failOn {
+		return errors.New("address already in use")
+	}
+	f.exposed = append(f.exposed, *req)
+	return nil
+}
+
+func (f *mockPortForwarder) Unexpose(req *types.UnexposeRequest) error {
+	for i, exposed := range f.exposed {
+		if exposed.Local == req.Local {
+			f.exposed = append(f.exposed[:i], f.exposed[i+1:]...)
+		}
+	}
+	return nil
+}
+
+func TestAddRemovePortForward(t *testing.T) {
+	store := network.NewPortForwardStore(filepath.Join(t.TempDir(), "port-forwards.json"))
+	forwarder := &mockPortForwarder{}
+	getForwarder := func() portForwarder { return forwarder }
+
+	c := &context{requestBody: []byte(`{"spec": "8080:machine:80"}`), headers: map[string]string{}}
+	require.NoError(t, addPortForward(store, fakemachine.NewClient(), getForwarder)(c))
+	assert.Equal(t, http.StatusCreated, c.code)
+	assert.Contains(t, forwarder.exposed, types.ExposeRequest{Protocol: types.TCP, Local: "127.0.0.1:8080", Remote: "192.168.127.2:80"})
+	assert.Len(t, forwarder.exposed, len(network.LoopbackAddresses()))
+
+	forwards, err := store.List()
+	require.NoError(t, err)
+	assert.Equal(t, []network.PortForward{{Protocol: "tcp", HostPort: 8080, GuestPort: 80}}, forwards)
+
+	c = &context{requestBody: []byte(`{"spec": "8080:machine:80"}`), headers: map[string]string{}}
+	require.NoError(t, removePortForward(store, fakemachine.NewClient(), getForwarder)(c))
+	assert.Equal(t, http.StatusOK, c.code)
+	assert.Empty(t, forwarder.exposed)
+}
+
+func TestAddPortForwardNotPersistedOnFailure(t *testing.T) {
+	store := network.NewPortForwardStore(filepath.Join(t.TempDir(), "port-forwards.json"))
+	loopbackAddresses := network.LoopbackAddresses()
+	forwarder := &mockPortForwarder{failOn: net.JoinHostPort(loopbackAddresses[len(loopbackAddresses)-1], "8080")}
+	getForwarder := func() portForwarder { return forwarder }
+
+	c := &context{requestBody: []byte(`{"spec": "8080:machine:80"}`), headers: map[string]string{}}
+	assert.EqualError(t, addPortForward(store, fakemachine.NewClient(), getForwarder)(c), "address already in use")
+	assert.Empty(t, forwarder.exposed)
+	forwards, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, forwards)
+}
@@ -0,0 +1,313 @@
+package api
+
+import (
+	gocontext "context"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+)
+
+const (
+	// a client is allowed to send clientRequestRate requests per second on
+	// average, with bursts of up to clientRequestBurst requests
+	clientRequestRate  = 10
+	clientRequestBurst = 20
+	// maximum number of requests handled at the same time, the other ones
+	// wait in a queue which is served round-robin between the clients
+	maxConcurrentRequests = 4
+)
+
+type peerKey struct{}
+
+// ConnContext identifies the process connected to the daemon API server, it
+// is the ConnContext of its http.Server
+func ConnContext(ctx gocontext.Context, conn net.Conn) gocontext.Context {
+	peer, err := peerOf(conn)
+	if err != nil {
+		logging.Debugf("Cannot identify the API client: %v", err)
+		return ctx
+	}
+	return withPeer(ctx, peer)
+}
+
+func withPeer(ctx gocontext.Context, peer string) gocontext.Context {
+	return gocontext.WithValue(ctx, peerKey{}, peer)
+}
+
+// knownClients are the user agents named in the metrics, the other clients
+// are counted as otherClient so that the number of series stays bounded
+var knownClients = map[string]bool{
+	"crc": true,
+}
+
+const otherClient = "other"
+
+// requestClient is the client sending a request. The requests are throttled
+// by the process sending them, the key, so that a client cannot get a new
+// rate with a new connection. The name is the user agent without its
+// version, it is only used in the metrics.
+type requestClient struct {
+	key  string
+	name string
+}
+
+func clientOf(r *http.Request) requestClient {
+	userAgent := strings.SplitN(r.Header.Get("User-Agent"), "/", 2)[0]
+	name := otherClient
+	if knownClients[userAgent] {
+		name = userAgent
+	}
+	if peer, ok := r.Context().Value(peerKey{}).(string); ok {
+		return requestClient{key: peer, name: name}
+	}
+	// the clients which cannot be identified share the rate of their user
+	// agent
+	return requestClient{key: "user agent " + userAgent, name: name}
+}
+
+// tokenBucket is a basic token bucket rate limiter, it is not thread safe
+type tokenBucket struct {
+	tokens   float64
+	lastFill time.Time
+}
+
+func (b *tokenBucket) allow(now time.Time, rate float64, burst int) bool {
+	b.tokens += now.Sub(b.lastFill).Seconds() * rate
+	if b.tokens > float64(burst) {
+		b.tokens = float64(burst)
+	}
+	b.lastFill = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+type clientCounters struct {
+	Requests  uint64
+	Throttled uint64
+	Queued    uint64
+}
+
+type throttler struct {
+	rate          float64
+	burst         int
+	maxConcurrent int
+	now           func() time.Time
+
+	lock    sync.Mutex
+	buckets map[string]*tokenBucket
+	// the counters are by client name, the other fields by client key
+	counters map[string]*clientCounters
+	inFlight int
+	// clients with waiting requests, in the order they will be served
+	order   []string
+	waiting map[string][]chan struct{}
+}
+
+func newThrottler(rate float64, burst, maxConcurrent int) *throttler {
+	return &throttler{
+		rate:          rate,
+		burst:         burst,
+		maxConcurrent: maxConcurrent,
+		now:           time.Now,
+		buckets:       make(map[string]*tokenBucket),
+		counters:      make(map[string]*clientCounters),
+		waiting:       make(map[string][]chan struct{}),
+	}
+}
+
+// Wrap rejects the requests of the clients exceeding their rate with
+// 429 Too Many Requests, and limits the number of requests handled
+// concurrently
+func (t *throttler) Wrap(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := clientOf(r)
+		if !t.allow(client) {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+			return
+		}
+		if !t.acquire(r, client) {
+			return
+		}
+		defer t.release()
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// WrapLongRunning only applies the rate limit, the streams and the
+// operations like start, which take minutes, would otherwise hold the slots
+// of the concurrent requests
+func (t *throttler) WrapLongRunning(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !t.allow(clientOf(r)) {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func (t *throttler) allow(client requestClient) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	counters, ok := t.counters[client.name]
+	if !ok {
+		counters = &clientCounters{}
+		t.counters[client.name] = counters
+	}
+	counters.Requests++
+	bucket, ok := t.buckets[client.key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(t.burst), lastFill: t.now()}
+		t.buckets[client.key] = bucket
+	}
+	allowed := bucket.allow(t.now(), t.rate, t.burst)
+	t.prune()
+	if !allowed {
+		counters.Throttled++
+		return false
+	}
+	return true
+}
+
+// prune forgets the clients without waiting requests and with a full
+// bucket, like the processes which exited, a new bucket is the same
+func (t *throttler) prune() {
+	refill := time.Duration(float64(t.burst) / t.rate * float64(time.Second))
+	buckets := make(map[string]*tokenBucket)
+	for key, bucket := range t.buckets {
+		if t.now().Sub(bucket.lastFill) < refill {
+			buckets[key] = bucket
+		}
+	}
+	t.buckets = buckets
+	waiting := make(map[string][]chan struct{})
+	for key, waiters := range t.waiting {
+		if len(waiters) > 0 {
+			waiting[key] = waiters
+		}
+	}
+	t.waiting = waiting
+}
+
+// acquire waits for its turn to handle a request of client. It returns false
+// if the request was cancelled while waiting.
+func (t *throttler) acquire(r *http.Request, client requestClient) bool {
+	t.lock.Lock()
+	if t.inFlight < t.maxConcurrent && len(t.order) == 0 {
+		t.inFlight++
+		t.lock.Unlock()
+		return true
+	}
+	ch := make(chan struct{})
+	if len(t.waiting[client.key]) == 0 {
+		t.order = append(t.order, client.key)
+	}
+	t.waiting[client.key] = append(t.waiting[client.key], ch)
+	t.counters[client.name].Queued++
+	t.lock.Unlock()
+
+	select {
+	case <-ch:
+		return true
+	case <-r.Context().Done():
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		select {
+		case <-ch:
+			// the slot was granted concurrently, give it back
+			t.releaseUnlocked()
+		default:
+			t.removeWaiter(client.key, ch)
+		}
+		return false
+	}
+}
+
+func (t *throttler) release() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.releaseUnlocked()
+}
+
+// releaseUnlocked hands the slot to the first waiting request of the next
+// client, and moves this client to the end of the queue
+func (t *throttler) releaseUnlocked() {
+	if len(t.order) == 0 {
+		t.inFlight--
+		return
+	}
+	client := t.order[0]
+	t.order = t.order[1:]
+	ch := t.waiting[client][0]
+	t.waiting[client] = t.waiting[client][1:]
+	if len(t.waiting[client]) > 0 {
+		t.order = append(t.order, client)
+	}
+	close(ch)
+}
+
+func (t *throttler) removeWaiter(client string, ch chan struct{}) {
+	waiters := t.waiting[client]
+	for i, waiter := range waiters {
+		if waiter == ch {
+			t.waiting[client] = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(t.waiting[client]) > 0 {
+		return
+	}
+	for i, c := range t.order {
+		if c == client {
+			t.order = append(t.order[:i], t.order[i+1:]...)
+			break
+		}
+	}
+}
+
+func (t *throttler) Counters() map[string]clientCounters {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	counters := make(map[string]clientCounters)
+	for client, c := range t.counters {
+		counters[client] = *c
+	}
+	return counters
+}
+
+// metrics returns the counters in the prometheus text format
+func (t *throttler) metrics() string {
+	counters := t.Counters()
+	var clients []string
+	for client := range counters {
+		clients = append(clients, client)
+	}
+	sort.Strings(clients)
+
+	var b strings.Builder
+	for _, metric := range []struct {
+		name, help string
+		value      func(c clientCounters) uint64
+	}{
+		{"crc_api_requests_total", "Number of API requests received", func(c clientCounters) uint64 { return c.Requests }},
+		{"crc_api_throttled_requests_total", "Number of API requests rejected because of rate limiting", func(c clientCounters) uint64 { return c.Throttled }},
+		{"crc_api_queued_requests_total", "Number of API requests which waited in the queue", func(c clientCounters) uint64 { return c.Queued }},
+	} {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n", metric.name, metric.help, metric.name)
+		for _, client := range clients {
+			fmt.Fprintf(&b, "%s{client=%q} %d\n", metric.name, client, metric.value(counters[client]))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	gocontext "context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrottlerRateLimit(t *testing.T) {
+	now := time.Now()
+	throttler := newThrottler(1, 2, 1)
+	throttler.now = func() time.Time { return now }
+
+	handler := throttler.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	send := func(peer, userAgent string) int {
+		req := httptest.NewRequest(http.MethodGet, "/status", nil).WithContext(withPeer(gocontext.Background(), peer))
+		req.Header.Set("User-Agent", userAgent)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send("pid 10", "vscode/1.0"))
+	assert.Equal(t, http.StatusOK, send("pid 10", "vscode/1.0"))
+	assert.Equal(t, http.StatusTooManyRequests, send("pid 10", "vscode/1.0"))
+	// other clients are not affected
+	assert.Equal(t, http.StatusOK, send("pid 20", "crc/2.1.0"))
+
+	now = now.Add(time.Second)
+	assert.Equal(t, http.StatusOK, send("pid 10", "vscode/1.0"))
+
+	// the unknown user agents are counted together
+	assert.Equal(t, map[string]clientCounters{
+		"other": {Requests: 4, Throttled: 1},
+		"crc":   {Requests: 1},
+	}, throttler.Counters())
+	assert.Contains(t, throttler.metrics(), "crc_api_throttled_requests_total{client=\"other\"} 1\n")
+	assert.Contains(t, throttler.metrics(), "crc_api_requests_total{client=\"crc\"} 1\n")
+}
+
+func TestThrottlerFairQueue(t *testing.T) {
+	throttler := newThrottler(100, 100, 1)
+	req := httptest.NewRequest(http.MethodGet, "/status", nil)
+
+	require.True(t, throttler.allow(requestClient{key: "ide", name: "ide"}))
+	require.True(t, throttler.acquire(req, requestClient{key: "ide", name: "ide"}))
+
+	granted := make(chan string, 3)
+	wait := func(client string) {
+		require.True(t, throttler.allow(requestClient{key: client, name: client}))
+		go func() {
+			if throttler.acquire(req, requestClient{key: client, name: client}) {
+				granted <- client
+			}
+		}()
+		// make sure the requests are queued in order
+		assert.Eventually(t, func() bool {
+			throttler.lock.Lock()
+			defer throttler.lock.Unlock()
+			return throttler.counters[client].Queued > 0 && len(throttler.waiting[client]) > 0
+		}, time.Second, time.Millisecond)
+	}
+	wait("ide")
+	wait("ide")
+	wait("cli")
+
+	// the cli request is served before the second ide request
+	throttler.release()
+	assert.Equal(t, "ide", <-granted)
+	throttler.release()
+	assert.Equal(t, "cli", <-granted)
+	throttler.release()
+	assert.Equal(t, "ide", <-granted)
+	throttler.release()
+	assert.Equal(t, 0, throttler.inFlight)
+}
+
+func TestThrottlerCancelledRequest(t *testing.T) {
+	throttler := newThrottler(100, 100, 1)
+	req := httptest.NewRequest(http.MethodGet, "/status", nil)
+	require.True(t, throttler.allow(requestClient{key: "cli", name: "cli"}))
+	require.True(t, throttler.acquire(req, requestClient{key: "cli", name: "cli"}))
+
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+	cancel()
+	require.True(t, throttler.allow(requestClient{key: "ide", name: "ide"}))
+	assert.False(t, throttler.acquire(req.WithContext(ctx), requestClient{key: "ide", name: "ide"}))
+	assert.Empty(t, throttler.order)
+
+	throttler.release()
+	assert.Equal(t, 0, throttler.inFlight)
+}
+
+func TestThrottlerByPeer(t *testing.T) {
+	now := time.Now()
+	throttler := newThrottler(1, 1, 1)
+	throttler.now = func() time.Time { return now }
+
+	handler := throttler.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	send := func(ctx gocontext.Context) int {
+		req := httptest.NewRequest(http.MethodGet, "/status", nil).WithContext(ctx)
+		req.Header.Set("User-Agent", "Go-http-client/1.1")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, send(withPeer(gocontext.Background(), "pid 10")))
+	// a new connection of the same process does not get a new rate
+	assert.Equal(t, http.StatusTooManyRequests, send(withPeer(gocontext.Background(), "pid 10")))
+	assert.Equal(t, http.StatusOK, send(withPeer(gocontext.Background(), "pid 20")))
+	// the clients which cannot be identified share the rate of their user agent
+	assert.Equal(t, http.StatusOK, send(gocontext.Background()))
+	assert.Equal(t, http.StatusTooManyRequests, send(gocontext.Background()))
+
+	// the buckets of the idle clients are forgotten
+	now = now.Add(time.Second)
+	assert.Equal(t, http.StatusOK, send(withPeer(gocontext.Background(), "pid 10")))
+	assert.Len(t, throttler.buckets, 1)
+}
+
+func TestConnContext(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the daemon listens on a named pipe")
+	}
+	socket := filepath.Join(t.TempDir(), "api.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	client, err := net.Dial("unix", socket)
+	require.NoError(t, err)
+	defer client.Close()
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	peer, ok := ConnContext(gocontext.Background(), conn).Value(peerKey{}).(string)
+	assert.True(t, ok)
+	assert.Equal(t, fmt.Sprintf("uid %d pid %d", os.Getuid(), os.Getpid()), peer)
+}
+
+func TestThrottlerLongRunning(t *testing.T) {
+	throttler := newThrottler(100, 100, 1)
+	started := make(chan struct{})
+	done := make(chan struct{})
+	long := throttler.WrapLongRunning(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-done
+	}))
+	go long.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/start", nil))
+	<-started
+	defer close(done)
+
+	// a start in progress doesn't hold the only slot
+	w := httptest.NewRecorder()
+	throttler.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/status", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
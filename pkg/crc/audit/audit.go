@@ -0,0 +1,143 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"os/user"
+	"sync"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+)
+
+type Operation string
+
+const (
+	Start               Operation = "start"
+	Stop                Operation = "stop"
+	PowerOff            Operation = "poweroff"
+	Delete              Operation = "delete"
+	DeleteData          Operation = "delete-data"
+	Upgrade             Operation = "upgrade"
+	ConfigSet           Operation = "config-set"
+	ConfigUnset         Operation = "config-unset"
+	ConfigImport        Operation = "config-import"
+	ConfigUsePreset     Operation = "config-use-preset"
+	UserAdd             Operation = "user-add"
+	UserRemove          Operation = "user-remove"
+	UserSetPassword     Operation = "user-set-password"
+	UserGrantAdmin      Operation = "user-grant-admin"
+	UserRevokeAdmin     Operation = "user-revoke-admin"
+	AdminPasswordRotate Operation = "admin-password-rotate"
+	SSHKeyRotate        Operation = "ssh-rotate-key"
+	SelfUpdate          Operation = "self-update"
+)
+
+// Source tells which process performed the operation
+type Source string
+
+const (
+	CLI    Source = "cli"
+	Daemon Source = "daemon"
+)
+
+// Entry is a line of the audit log. Details never contain the values of the
+// configuration properties or the passwords, only their names.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	User      string    `json:"user"`
+	Source    Source    `json:"source"`
+	Operation Operation `json:"operation"`
+	Details   string    `json:"details,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Log appends the entries to a JSON lines file, the existing lines are never
+// rewritten. The crc commands and the daemon append to the same file.
+type Log struct {
+	path string
+	lock sync.Mutex
+	now  func() time.Time
+}
+
+func NewLog(path string) *Log {
+	return &Log{
+		path: path,
+		now:  time.Now,
+	}
+}
+
+// Record appends an entry for operation, err is the failure of the
+// operation. Failing to write the entry must not fail the operation, it is
+// only logged.
+func (log *Log) Record(source Source, operation Operation, details string, err error) {
+	entry := Entry{
+		Time:      log.now().UTC(),
+		User:      currentUser(),
+		Source:    source,
+		Operation: operation,
+		Details:   details,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if err := log.append(entry); err != nil {
+		logging.Debugf("Cannot write to the audit log: %v", err)
+	}
+}
+
+func (log *Log) append(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	log.lock.Lock()
+	defer log.lock.Unlock()
+	file, err := os.OpenFile(log.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	// a single write keeps the lines of concurrent processes apart
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// List returns the entries, the oldest first. The lines which cannot be
+// parsed, like a line truncated by a crash, are skipped.
+func (log *Log) List() ([]Entry, error) {
+	log.lock.Lock()
+	defer log.lock.Unlock()
+	file, err := os.Open(log.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			logging.Debugf("Skipping an invalid line of the audit log: %v", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+func currentUser() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return u.Username
+}
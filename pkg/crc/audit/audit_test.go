@@ -0,0 +1,54 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAppendsEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log := NewLog(path)
+	log.now = func() time.Time { return time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC) }
+
+	log.Record(CLI, Start, "", nil)
+	log.Record(Daemon, ConfigSet, "memory", errors.New("invalid value"))
+
+	entries, err := log.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, CLI, entries[0].Source)
+	assert.Equal(t, Start, entries[0].Operation)
+	assert.Empty(t, entries[0].Error)
+	assert.Equal(t, ConfigSet, entries[1].Operation)
+	assert.Equal(t, "memory", entries[1].Details)
+	assert.Equal(t, "invalid value", entries[1].Error)
+	assert.Equal(t, 2022, entries[1].Time.Year())
+
+	// a second log of the same file, like the one of the daemon, appends
+	NewLog(path).Record(Daemon, Stop, "", nil)
+	entries, err = log.List()
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+}
+
+func TestListSkipsInvalidLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"operation":"start"}`+"\n"+`{"operation":"st`+"\n"), 0600))
+
+	entries, err := NewLog(path).List()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, Start, entries[0].Operation)
+}
+
+func TestListWithoutLog(t *testing.T) {
+	entries, err := NewLog(filepath.Join(t.TempDir(), "audit.jsonl")).List()
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
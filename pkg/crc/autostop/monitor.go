@@ -0,0 +1,101 @@
+package autostop
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+)
+
+const checkInterval = time.Minute
+
+// Monitor stops the instance when it was not used for the duration of the
+// auto-stop-after setting. The instance is used when there are connections
+// from the host to the API server, the router or ssh, or when the daemon API
+// receives requests.
+type Monitor struct {
+	client machine.Client
+	config crcConfig.Storage
+	now    func() time.Time
+
+	lock         sync.Mutex
+	lastActivity time.Time
+}
+
+func NewMonitor(client machine.Client, config crcConfig.Storage) *Monitor {
+	return &Monitor{
+		client:       client,
+		config:       config,
+		now:          time.Now,
+		lastActivity: time.Now(),
+	}
+}
+
+// Touch records an activity, the idle duration starts again from now
+func (m *Monitor) Touch() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.lastActivity = m.now()
+}
+
+func (m *Monitor) idleDuration() time.Duration {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.now().Sub(m.lastActivity)
+}
+
+// Wrap records the requests sent to handler as activity. GET requests are
+// ignored as the tray polls the status of the instance with them.
+func (m *Monitor) Wrap(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			m.Touch()
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// Run checks the activity of the instance every minute, it never returns
+func (m *Monitor) Run() {
+	for {
+		time.Sleep(checkInterval)
+		m.check()
+	}
+}
+
+func (m *Monitor) check() {
+	timeout := crcConfig.GetAutoStopAfter(m.config)
+	if timeout == 0 {
+		m.Touch()
+		return
+	}
+	running, err := m.client.IsRunning()
+	if err != nil || !running {
+		m.Touch()
+		return
+	}
+	connections, err := m.client.ActiveConnections()
+	if err != nil {
+		logging.Debugf("Cannot get the connections to the instance: %v", err)
+		m.Touch()
+		return
+	}
+	if connections > 0 {
+		m.Touch()
+		return
+	}
+	idle := m.idleDuration()
+	if idle < timeout {
+		return
+	}
+
+	logging.Infof("The instance was not used for %s, stopping it (%s is set to %s)", idle.Round(time.Minute), crcConfig.AutoStopAfter, timeout)
+	if _, err := m.client.Stop(types.StopConfig{Timeout: machine.DefaultStopTimeout}); err != nil {
+		logging.Errorf("Cannot stop the idle instance: %v", err)
+	}
+	m.Touch()
+}
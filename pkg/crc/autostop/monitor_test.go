@@ -0,0 +1,88 @@
+package autostop
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stopRecordingClient struct {
+	*fakemachine.Client
+	stopped int
+}
+
+func (c *stopRecordingClient) Stop(stopConfig types.StopConfig) (state.State, error) {
+	c.stopped++
+	return c.Client.Stop(stopConfig)
+}
+
+func newTestMonitor(t *testing.T, autoStopAfter string) (*Monitor, *stopRecordingClient, *time.Time) {
+	cfg := crcConfig.New(crcConfig.NewEmptyInMemoryStorage())
+	crcConfig.RegisterSettings(cfg)
+	if autoStopAfter != "" {
+		_, err := cfg.Set(crcConfig.AutoStopAfter, autoStopAfter)
+		require.NoError(t, err)
+	}
+	client := &stopRecordingClient{Client: fakemachine.NewClient()}
+	now := time.Date(2022, 3, 1, 20, 0, 0, 0, time.UTC)
+	monitor := NewMonitor(client, cfg)
+	monitor.now = func() time.Time { return now }
+	monitor.Touch()
+	return monitor, client, &now
+}
+
+func TestMonitorStopsIdleInstance(t *testing.T) {
+	monitor, client, now := newTestMonitor(t, "60m")
+
+	*now = now.Add(30 * time.Minute)
+	monitor.check()
+	assert.Equal(t, 0, client.stopped)
+
+	*now = now.Add(30 * time.Minute)
+	monitor.check()
+	assert.Equal(t, 1, client.stopped)
+}
+
+func TestMonitorKeepsUsedInstance(t *testing.T) {
+	monitor, client, now := newTestMonitor(t, "60m")
+	client.Connections = 1
+
+	*now = now.Add(2 * time.Hour)
+	monitor.check()
+	assert.Equal(t, 0, client.stopped)
+
+	client.Connections = 0
+	*now = now.Add(59 * time.Minute)
+	monitor.check()
+	assert.Equal(t, 0, client.stopped)
+}
+
+func TestMonitorDisabled(t *testing.T) {
+	monitor, client, now := newTestMonitor(t, "")
+
+	*now = now.Add(24 * time.Hour)
+	monitor.check()
+	assert.Equal(t, 0, client.stopped)
+}
+
+func TestMonitorWrap(t *testing.T) {
+	monitor, client, now := newTestMonitor(t, "60m")
+	handler := monitor.Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	*now = now.Add(2 * time.Hour)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/status", nil))
+	assert.Equal(t, 2*time.Hour, monitor.idleDuration())
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/start", nil))
+	assert.Equal(t, time.Duration(0), monitor.idleDuration())
+	monitor.check()
+	assert.Equal(t, 0, client.stopped)
+}
@@ -83,6 +83,27 @@ func NewAdminHelperCache() *Cache {
 	)
 }
 
+// Components returns the caches of the executables used by crc on this host,
+// the machine drivers and the admin helper
+func Components() []*Cache {
+	return append(hostComponents(), NewAdminHelperCache())
+}
+
+// ExpectedVersion returns the version of the executable used by this crc
+// release
+func (c *Cache) ExpectedVersion() string {
+	return c.version
+}
+
+// InstalledVersion returns the version of the cached executable, it is empty
+// when the executable is not cached
+func (c *Cache) InstalledVersion() (string, error) {
+	if !c.IsCached() {
+		return "", nil
+	}
+	return c.getVersion(c.GetExecutablePath())
+}
+
 func (c *Cache) IsCached() bool {
 	if _, err := os.Stat(c.GetExecutablePath()); os.IsNotExist(err) {
 		return false
@@ -187,7 +208,7 @@ func (c *Cache) CheckVersion() error {
 }
 
 func IsTarball(filename string) bool {
-	tarballExtensions := []string{".tar", ".tar.gz", ".tar.xz", ".zip", ".tar.bz2", ".crcbundle"}
+	tarballExtensions := []string{".tar", ".tar.gz", ".tar.xz", ".tar.zst", ".zip", ".tar.bz2", ".crcbundle"}
 	for _, extension := range tarballExtensions {
 		if strings.HasSuffix(strings.ToLower(filename), extension) {
 			return true
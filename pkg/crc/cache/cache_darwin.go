@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/code-ready/crc/pkg/crc/machine/hyperkit"
+	"github.com/code-ready/crc/pkg/crc/machine/vfkit"
 	crcos "github.com/code-ready/crc/pkg/os"
 )
 
@@ -22,6 +23,16 @@ func NewHyperKitCache() *Cache {
 	return New(hyperkit.HyperKitCommand, hyperkit.HyperKitDownloadURL, hyperkit.HyperKitVersion, getHyperKitVersion)
 }
 
+func NewVfkitCache() *Cache {
+	return New(vfkit.VfkitCommand, vfkit.VfkitDownloadURL, vfkit.VfkitVersion, getVfkitVersion)
+}
+
+// hostComponents returns the caches of the executables used by crc on this
+// host
+func hostComponents() []*Cache {
+	return []*Cache{NewMachineDriverHyperKitCache(), NewHyperKitCache(), NewQcowToolCache(), NewVfkitCache()}
+}
+
 func getHyperKitMachineDriverVersion(executablePath string) (string, error) {
 	return getVersionGeneric(executablePath, "version")
 }
@@ -50,6 +61,13 @@ func getHyperKitVersion(executablePath string) (string, error) {
 	return strings.TrimSpace(parsedOutput[1]), err
 }
 
+// getVfkitVersion parses the output of 'vfkit --version', which is like
+// 'vfkit version: v0.0.2'
+func getVfkitVersion(executablePath string) (string, error) {
+	version, err := getVersionGeneric(executablePath, "--version")
+	return strings.TrimPrefix(version, "v"), err
+}
+
 func getFirstLine(s string) (line string, err error) {
 	reader := bufio.NewReader(strings.NewReader(s))
 	line, err = reader.ReadString('\n')
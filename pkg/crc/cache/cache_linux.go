@@ -11,3 +11,9 @@ func NewMachineDriverLibvirtCache() *Cache {
 func getCurrentLibvirtDriverVersion(executablePath string) (string, error) {
 	return getVersionGeneric(executablePath, "version")
 }
+
+// hostComponents returns the caches of the executables used by crc on this
+// host
+func hostComponents() []*Cache {
+	return []*Cache{NewMachineDriverLibvirtCache()}
+}
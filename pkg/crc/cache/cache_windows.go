@@ -0,0 +1,7 @@
+package cache
+
+// hostComponents returns the caches of the executables used by crc on this
+// host, Hyper-V is part of Windows
+func hostComponents() []*Cache {
+	return nil
+}
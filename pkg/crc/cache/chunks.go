@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+)
+
+// ChunkCache keeps the chunks downloaded by 'crc bundle update', named
+// after their sha256 sum, so that an interrupted update does not download
+// them again. It is emptied once the bundle is updated.
+type ChunkCache struct {
+	dir string
+}
+
+func NewChunkCache() *ChunkCache {
+	return NewChunkCacheInDir(constants.BundleChunksDir)
+}
+
+func NewChunkCacheInDir(dir string) *ChunkCache {
+	return &ChunkCache{dir: dir}
+}
+
+func (c *ChunkCache) path(digest string) string {
+	if len(digest) < 2 {
+		return filepath.Join(c.dir, digest)
+	}
+	return filepath.Join(c.dir, digest[:2], digest)
+}
+
+// Get returns the content of the chunk, it is checked against its digest
+// as the chunk could have been partially written
+func (c *ChunkCache) Get(digest string) ([]byte, bool) {
+	data, err := ioutil.ReadFile(c.path(digest))
+	if err != nil {
+		return nil, false
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != digest {
+		_ = os.Remove(c.path(digest))
+		return nil, false
+	}
+	return data, true
+}
+
+// Add stores the chunk, it must match its digest
+func (c *ChunkCache) Add(digest string, data []byte) error {
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != digest {
+		return fmt.Errorf("chunk does not match its digest %s", digest)
+	}
+	path := c.path(digest)
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	tmpPath := path + ".tmp"
+	if err := ioutil.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// Size returns the number of chunks and the space they use
+func (c *ChunkCache) Size() (int, int64, error) {
+	var count int
+	var size int64
+	err := filepath.Walk(c.dir, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() {
+			count++
+			size += info.Size()
+		}
+		return nil
+	})
+	return count, size, err
+}
+
+// Clear removes all the chunks
+func (c *ChunkCache) Clear() error {
+	return os.RemoveAll(c.dir)
+}
@@ -0,0 +1,49 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkCache(t *testing.T) {
+	chunks := &ChunkCache{dir: filepath.Join(t.TempDir(), "chunks")}
+	data := []byte("chunk")
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+
+	_, ok := chunks.Get(digest)
+	assert.False(t, ok)
+	assert.Error(t, chunks.Add(digest, []byte("another chunk")))
+
+	assert.NoError(t, chunks.Add(digest, data))
+	cached, ok := chunks.Get(digest)
+	assert.True(t, ok)
+	assert.Equal(t, data, cached)
+
+	count, size, err := chunks.Size()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, int64(len(data)), size)
+
+	assert.NoError(t, chunks.Clear())
+	count, _, err = chunks.Size()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestChunkCacheDropsCorruptedChunks(t *testing.T) {
+	chunks := &ChunkCache{dir: t.TempDir()}
+	sum := sha256.Sum256([]byte("chunk"))
+	digest := hex.EncodeToString(sum[:])
+	assert.NoError(t, chunks.Add(digest, []byte("chunk")))
+	assert.NoError(t, ioutil.WriteFile(chunks.path(digest), []byte("chu"), 0600))
+
+	_, ok := chunks.Get(digest)
+	assert.False(t, ok)
+	assert.NoFileExists(t, chunks.path(digest))
+}
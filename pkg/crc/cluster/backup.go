@@ -0,0 +1,265 @@
+package cluster
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// bundlePVDir holds the persistent volumes created with the bundle
+const bundlePVDir = "/var/mnt/pv-data"
+
+// volumeDirs are the directories of the persistent volumes of the control
+// plane node, the volumes of the worker nodes are not backed up
+var volumeDirs = []string{bundlePVDir, PVPoolDir}
+
+// ClaimBackup holds the claims of the user namespaces and the volumes of
+// the PV pool they are bound to. The volumes of the bundle are recreated
+// with the cluster, the claims only need to be bound to them again.
+type ClaimBackup struct {
+	Node    string                     `json:"node"`
+	Volumes []v1.PersistentVolume      `json:"volumes"`
+	Claims  []v1.PersistentVolumeClaim `json:"claims"`
+}
+
+// BackupVolumes writes a gzip compressed tarball of the persistent volumes
+// of the instance to w. It returns false when there are no volumes.
+func BackupVolumes(sshRunner *ssh.Runner, w io.Writer) (bool, error) {
+	var dirs []string
+	for _, dir := range volumeDirs {
+		if _, _, err := sshRunner.RunPrivileged("checking the volume directories", "test", "-d", dir); err == nil {
+			dirs = append(dirs, strings.TrimPrefix(dir, "/"))
+		}
+	}
+	if len(dirs) == 0 {
+		return false, nil
+	}
+	logging.Infof("Backing up the persistent volumes...")
+	args := append([]string{"tar", "-C", "/", "--selinux", "--xattrs", "-czf", "-"}, dirs...)
+	if stderr, err := sshRunner.RunPrivilegedWithIO("backing up the persistent volumes", nil, w, args...); err != nil {
+		return false, fmt.Errorf("Failed to back up the persistent volumes %v: %s", err, stderr)
+	}
+	return true, nil
+}
+
+// RestoreVolumes extracts the tarball of BackupVolumes in the instance
+func RestoreVolumes(sshRunner *ssh.Runner, r io.Reader) error {
+	logging.Infof("Restoring the persistent volumes...")
+	if stderr, err := sshRunner.RunPrivilegedWithIO("restoring the persistent volumes", r, io.Discard,
+		"tar", "-C", "/", "--selinux", "--xattrs", "-xzf", "-"); err != nil {
+		return fmt.Errorf("Failed to restore the persistent volumes %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// GetHtpasswd returns the base64 encoded htpasswd file of the identity
+// provider of the cluster, with the users added to the cluster
+func GetHtpasswd(ocConfig oc.Config) (string, error) {
+	htpasswd, stderr, err := ocConfig.RunOcCommandPrivate("get", "secret", "htpass-secret", "-n", "openshift-config", "-o", `jsonpath="{.data.htpasswd}"`)
+	if err != nil {
+		return "", fmt.Errorf("%s:%v", stderr, err)
+	}
+	return strings.TrimSpace(htpasswd), nil
+}
+
+// RestoreHtpasswd replaces the htpasswd file of the identity provider, the
+// passwords of the developer and kubeadmin users are then set again
+func RestoreHtpasswd(ctx context.Context, ocConfig oc.Config, htpasswd string) error {
+	if err := WaitForOpenshiftResource(ctx, ocConfig, "secret"); err != nil {
+		return err
+	}
+	logging.Infof("Restoring the users of the cluster...")
+	cmdArgs := []string{"patch", "secret", "htpass-secret", "-p",
+		fmt.Sprintf(`'{"data":{"htpasswd":"%s"}}'`, htpasswd),
+		"-n", "openshift-config", "--type", "merge"}
+	if _, stderr, err := ocConfig.RunOcCommandPrivate(cmdArgs...); err != nil {
+		return fmt.Errorf("Failed to restore the users %v: %s", err, stderr)
+	}
+	return UpdateKubeAdminUserPassword(ctx, ocConfig, "")
+}
+
+// AddedUsers returns the users of the base64 encoded htpasswd file other
+// than developer and kubeadmin
+func AddedUsers(htpasswd string) ([]string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(htpasswd)
+	if err != nil {
+		return nil, err
+	}
+	var users []string
+	for _, line := range strings.Split(string(decoded), "\n") {
+		i := strings.Index(line, ":")
+		if i == -1 {
+			continue
+		}
+		username := line[:i]
+		if username == "developer" || username == "kubeadmin" {
+			continue
+		}
+		users = append(users, username)
+	}
+	return users, nil
+}
+
+// BackupClaims returns the claims of the user namespaces, the claims of the
+// openshift-* and kube-* namespaces are recreated with the cluster
+func BackupClaims(ocConfig oc.Config, sshRunner *ssh.Runner) (*ClaimBackup, error) {
+	node, _, err := sshRunner.Run("hostname")
+	if err != nil {
+		return nil, err
+	}
+	stdout, stderr, err := ocConfig.RunOcCommand("get", "pvc", "--all-namespaces", "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list the persistent volume claims %v: %s", err, stderr)
+	}
+	var claims v1.PersistentVolumeClaimList
+	if err := json.Unmarshal([]byte(stdout), &claims); err != nil {
+		return nil, err
+	}
+	stdout, stderr, err = ocConfig.RunOcCommand("get", "pv", "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list the persistent volumes %v: %s", err, stderr)
+	}
+	var volumes v1.PersistentVolumeList
+	if err := json.Unmarshal([]byte(stdout), &volumes); err != nil {
+		return nil, err
+	}
+	return newClaimBackup(strings.TrimSpace(node), claims.Items, volumes.Items), nil
+}
+
+func newClaimBackup(node string, claims []v1.PersistentVolumeClaim, volumes []v1.PersistentVolume) *ClaimBackup {
+	backup := &ClaimBackup{Node: node}
+	poolVolumes := make(map[string]v1.PersistentVolume)
+	for _, volume := range volumes {
+		if volume.Spec.StorageClassName == pvStorageClass {
+			poolVolumes[volume.Name] = volume
+		}
+	}
+	for _, claim := range claims {
+		if isClusterNamespace(claim.Namespace) || claim.Spec.VolumeName == "" {
+			continue
+		}
+		backup.Claims = append(backup.Claims, claim)
+		if volume, ok := poolVolumes[claim.Spec.VolumeName]; ok {
+			backup.Volumes = append(backup.Volumes, volume)
+		}
+	}
+	return backup
+}
+
+func isClusterNamespace(namespace string) bool {
+	return strings.HasPrefix(namespace, "openshift") || strings.HasPrefix(namespace, "kube-") ||
+		namespace == "default" || namespace == "crc-storage"
+}
+
+// RestoreClaims creates the namespaces, volumes and claims of the backup,
+// the claims are bound to the volumes holding the restored data. It
+// returns the number of restored claims.
+func RestoreClaims(ctx context.Context, ocConfig oc.Config, sshRunner *ssh.Runner, backup *ClaimBackup) (int, error) {
+	if len(backup.Claims) == 0 {
+		return 0, nil
+	}
+	node, _, err := sshRunner.Run("hostname")
+	if err != nil {
+		return 0, err
+	}
+	manifest, err := claimsManifest(backup, strings.TrimSpace(node))
+	if err != nil {
+		return 0, err
+	}
+	if err := WaitForOpenshiftResource(ctx, ocConfig, "pvc"); err != nil {
+		return 0, err
+	}
+	logging.Infof("Restoring %d persistent volume claims...", len(backup.Claims))
+	manifestFileName := "/tmp/crc-claims.json"
+	if err := sshRunner.CopyData(manifest, manifestFileName, 0600); err != nil {
+		return 0, err
+	}
+	defer func() {
+		_, _, _ = sshRunner.Run("rm", "-f", manifestFileName)
+	}()
+	if _, stderr, err := ocConfig.RunOcCommand("apply", "-f", manifestFileName); err != nil {
+		return 0, fmt.Errorf("Failed to restore the persistent volume claims %v: %s", err, stderr)
+	}
+	return len(backup.Claims), nil
+}
+
+// claimsManifest returns a list of the objects to create, without their
+// state in the previous cluster. The volumes of the pool are bound to the
+// node by their affinity, it is changed from the node of the backup to
+// node.
+func claimsManifest(backup *ClaimBackup, node string) ([]byte, error) {
+	var items []interface{}
+	namespaces := make(map[string]bool)
+	for _, claim := range backup.Claims {
+		if namespaces[claim.Namespace] {
+			continue
+		}
+		namespaces[claim.Namespace] = true
+		items = append(items, v1.Namespace{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Namespace"},
+			ObjectMeta: metav1.ObjectMeta{Name: claim.Namespace},
+		})
+	}
+	for _, volume := range backup.Volumes {
+		restored := v1.PersistentVolume{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolume"},
+			ObjectMeta: cleanObjectMeta(volume.ObjectMeta),
+			Spec:       *volume.Spec.DeepCopy(),
+		}
+		// the claim binds the volume again
+		restored.Spec.ClaimRef = nil
+		if affinity := restored.Spec.NodeAffinity; affinity != nil && affinity.Required != nil {
+			for i := range affinity.Required.NodeSelectorTerms {
+				term := &affinity.Required.NodeSelectorTerms[i]
+				for j := range term.MatchExpressions {
+					values := term.MatchExpressions[j].Values
+					for k := range values {
+						if values[k] == backup.Node {
+							values[k] = node
+						}
+					}
+				}
+			}
+		}
+		items = append(items, restored)
+	}
+	for _, claim := range backup.Claims {
+		items = append(items, v1.PersistentVolumeClaim{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "PersistentVolumeClaim"},
+			ObjectMeta: cleanObjectMeta(claim.ObjectMeta),
+			Spec:       claim.Spec,
+		})
+	}
+	return json.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      items,
+	})
+}
+
+// cleanObjectMeta only keeps the fields of the metadata which are not set
+// by the cluster
+func cleanObjectMeta(meta metav1.ObjectMeta) metav1.ObjectMeta {
+	annotations := make(map[string]string)
+	for key, value := range meta.Annotations {
+		if strings.HasPrefix(key, "pv.kubernetes.io/") || key == "kubectl.kubernetes.io/last-applied-configuration" {
+			continue
+		}
+		annotations[key] = value
+	}
+	return metav1.ObjectMeta{
+		Name:        meta.Name,
+		Namespace:   meta.Namespace,
+		Labels:      meta.Labels,
+		Annotations: annotations,
+	}
+}
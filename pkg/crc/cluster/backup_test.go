@@ -0,0 +1,95 @@
+package cluster
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAddedUsers(t *testing.T) {
+	htpasswd := base64.StdEncoding.EncodeToString([]byte("developer:$2y$x\nkubeadmin:$2y$y\nalice:$2y$z\n"))
+	users, err := AddedUsers(htpasswd)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, users)
+}
+
+func TestClaimsManifest(t *testing.T) {
+	claims := []v1.PersistentVolumeClaim{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "data",
+				Namespace:       "myproject",
+				UID:             "1234",
+				ResourceVersion: "42",
+				Annotations: map[string]string{
+					"pv.kubernetes.io/bind-completed": "yes",
+					"app":                             "db",
+				},
+			},
+			Spec: v1.PersistentVolumeClaimSpec{VolumeName: "pvc-1234"},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "registry", Namespace: "openshift-image-registry"},
+			Spec:       v1.PersistentVolumeClaimSpec{VolumeName: "pv0001"},
+		},
+	}
+	volumes := []v1.PersistentVolume{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pvc-1234", UID: "5678"},
+			Spec: v1.PersistentVolumeSpec{
+				StorageClassName: pvStorageClass,
+				ClaimRef:         &v1.ObjectReference{Name: "data", Namespace: "myproject", UID: "1234"},
+				NodeAffinity: &v1.VolumeNodeAffinity{
+					Required: &v1.NodeSelector{
+						NodeSelectorTerms: []v1.NodeSelectorTerm{{
+							MatchExpressions: []v1.NodeSelectorRequirement{{
+								Key:      "kubernetes.io/hostname",
+								Operator: v1.NodeSelectorOpIn,
+								Values:   []string{"crc-old-master-0"},
+							}},
+						}},
+					},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv0001"},
+		},
+	}
+	backup := newClaimBackup("crc-old-master-0", claims, volumes)
+	require.Len(t, backup.Claims, 1)
+	require.Len(t, backup.Volumes, 1)
+
+	data, err := claimsManifest(backup, "crc-new-master-0")
+	require.NoError(t, err)
+	var manifest struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(data, &manifest))
+	require.Len(t, manifest.Items, 3)
+
+	var namespace v1.Namespace
+	require.NoError(t, json.Unmarshal(manifest.Items[0], &namespace))
+	assert.Equal(t, "Namespace", namespace.Kind)
+	assert.Equal(t, "myproject", namespace.Name)
+
+	var volume v1.PersistentVolume
+	require.NoError(t, json.Unmarshal(manifest.Items[1], &volume))
+	assert.Equal(t, "PersistentVolume", volume.Kind)
+	assert.Empty(t, volume.UID)
+	assert.Nil(t, volume.Spec.ClaimRef)
+	assert.Equal(t, []string{"crc-new-master-0"}, volume.Spec.NodeAffinity.Required.NodeSelectorTerms[0].MatchExpressions[0].Values)
+
+	var claim v1.PersistentVolumeClaim
+	require.NoError(t, json.Unmarshal(manifest.Items[2], &claim))
+	assert.Equal(t, "PersistentVolumeClaim", claim.Kind)
+	assert.Empty(t, claim.UID)
+	assert.Empty(t, claim.ResourceVersion)
+	assert.Equal(t, map[string]string{"app": "db"}, claim.Annotations)
+	assert.Equal(t, "pvc-1234", claim.Spec.VolumeName)
+}
@@ -19,22 +19,9 @@ func isPending(csr *k8scerts.CertificateSigningRequest) bool {
 
 func approvePendingCSRs(ctx context.Context, ocConfig oc.Config, expectedSignerName string) error {
 	return crcerrors.Retry(ctx, 8*time.Minute, func() error {
-		csrs, err := getCSRList(ctx, ocConfig, expectedSignerName)
+		csrsApproved, err := approvePendingCSRsOnce(ctx, ocConfig, expectedSignerName)
 		if err != nil {
-			return &crcerrors.RetriableError{Err: err}
-		}
-		csrsApproved := false
-		for i := range csrs.Items {
-			csr := &csrs.Items[i]
-			if !isPending(csr) {
-				continue
-			}
-			logging.Debugf("Approving csr %s (signerName: %s)", csr.ObjectMeta.Name, expectedSignerName)
-			_, stderr, err := ocConfig.RunOcCommand("adm", "certificate", "approve", csr.ObjectMeta.Name)
-			if err != nil {
-				return fmt.Errorf("Not able to approve csr (%v : %s)", err, stderr)
-			}
-			csrsApproved = true
+			return err
 		}
 		if !csrsApproved {
 			return &crcerrors.RetriableError{Err: fmt.Errorf("No Pending CSR with signerName %s", expectedSignerName)}
@@ -43,11 +30,29 @@ func approvePendingCSRs(ctx context.Context, ocConfig oc.Config, expectedSignerN
 	}, time.Second*5)
 }
 
+func approvePendingCSRsOnce(ctx context.Context, ocConfig oc.Config, expectedSignerName string) (bool, error) {
+	csrs, err := getCSRList(ctx, ocConfig, expectedSignerName)
+	if err != nil {
+		return false, &crcerrors.RetriableError{Err: err}
+	}
+	csrsApproved := false
+	for i := range csrs.Items {
+		csr := &csrs.Items[i]
+		if !isPending(csr) {
+			continue
+		}
+		logging.Debugf("Approving csr %s (signerName: %s)", csr.ObjectMeta.Name, expectedSignerName)
+		_, stderr, err := ocConfig.RunOcCommand("adm", "certificate", "approve", csr.ObjectMeta.Name)
+		if err != nil {
+			return false, fmt.Errorf("Not able to approve csr (%v : %s)", err, stderr)
+		}
+		csrsApproved = true
+	}
+	return csrsApproved, nil
+}
+
 func ApproveCSRAndWaitForCertsRenewal(ctx context.Context, sshRunner *ssh.Runner, ocConfig oc.Config, client, server bool) error {
-	const (
-		kubeletClientSignerName = "kubernetes.io/kube-apiserver-client-kubelet"
-		authClientSignerName    = "kubernetes.io/kube-apiserver-client"
-	)
+	const authClientSignerName = "kubernetes.io/kube-apiserver-client"
 
 	// First, kubelet starts and tries to connect to API server. If its certificate is expired, it asks for a new one
 	// Admin needs to approve it. The Kubernetes controller manager will then issue the cert, kubelet will fetch it and use it.
@@ -95,3 +100,79 @@ func waitForCertRenewal(sshRunner *ssh.Runner, cert string) func() error {
 		return &crcerrors.RetriableError{Err: fmt.Errorf("certificate %s still expired", cert)}
 	}
 }
+
+// CertRenewalThreshold is the remaining validity under which the kubelet
+// certificates are renewed while the cluster is running
+const CertRenewalThreshold = 7 * 24 * time.Hour
+
+// CertExpiry is the expiry date of a certificate of the cluster
+type CertExpiry struct {
+	Name     string
+	NotAfter time.Time
+}
+
+// ExpiresSoon returns true when the certificate expires in less than
+// CertRenewalThreshold
+func (cert CertExpiry) ExpiresSoon(now time.Time) bool {
+	return cert.NotAfter.Sub(now) < CertRenewalThreshold
+}
+
+type monitoredCert struct {
+	name string
+	path string
+	// signer of the CSRs renewing the certificate, empty when the
+	// certificate is renewed by the cluster operators
+	signerName string
+}
+
+var monitoredCerts = []monitoredCert{
+	{name: "kubelet-client", path: KubeletClientCert, signerName: kubeletClientSignerName},
+	{name: "kubelet-server", path: KubeletServerCert, signerName: kubeletServingSignerName},
+	{name: "aggregator-client-ca", path: AggregatorClientCert},
+}
+
+// GetCertsExpiry returns the expiry dates of the kubelet and API server
+// certificates which make the start fail once expired
+func GetCertsExpiry(sshRunner *ssh.Runner) ([]CertExpiry, error) {
+	var certs []CertExpiry
+	for _, cert := range monitoredCerts {
+		notAfter, err := getCertExpiry(sshRunner, cert.path)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot get the expiry date of %s: %w", cert.path, err)
+		}
+		certs = append(certs, CertExpiry{
+			Name:     cert.name,
+			NotAfter: notAfter,
+		})
+	}
+	return certs, nil
+}
+
+// RenewExpiringCerts approves the pending CSRs of the kubelet certificates
+// which expire in less than CertRenewalThreshold. The kubelet requests new
+// certificates ahead of the expiry, but nothing approves the client
+// certificate request on a single node cluster.
+func RenewExpiringCerts(ctx context.Context, sshRunner *ssh.Runner, ocConfig oc.Config) error {
+	now := time.Now()
+	for _, cert := range monitoredCerts {
+		if cert.signerName == "" {
+			continue
+		}
+		notAfter, err := getCertExpiry(sshRunner, cert.path)
+		if err != nil {
+			return fmt.Errorf("Cannot get the expiry date of %s: %w", cert.path, err)
+		}
+		if !(CertExpiry{Name: cert.name, NotAfter: notAfter}).ExpiresSoon(now) {
+			continue
+		}
+		logging.Infof("The %s certificate expires on %s, renewing it", cert.name, notAfter.Format(time.RFC822))
+		approved, err := approvePendingCSRsOnce(ctx, ocConfig, cert.signerName)
+		if err != nil {
+			return err
+		}
+		if !approved {
+			logging.Debugf("The kubelet did not request a new %s certificate yet", cert.name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCertExpiresSoon(t *testing.T) {
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	assert.True(t, CertExpiry{NotAfter: now.Add(-time.Hour)}.ExpiresSoon(now))
+	assert.True(t, CertExpiry{NotAfter: now.Add(6 * 24 * time.Hour)}.ExpiresSoon(now))
+	assert.False(t, CertExpiry{NotAfter: now.Add(8 * 24 * time.Hour)}.ExpiresSoon(now))
+}
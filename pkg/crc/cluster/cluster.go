@@ -46,11 +46,7 @@ func CheckCertsValidity(sshRunner *ssh.Runner) (map[string]bool, error) {
 }
 
 func checkCertValidity(sshRunner *ssh.Runner, cert string) (bool, error) {
-	output, _, err := sshRunner.Run(fmt.Sprintf(`date --date="$(sudo openssl x509 -in %s -noout -enddate | cut -d= -f 2)" --iso-8601=seconds`, cert))
-	if err != nil {
-		return false, err
-	}
-	expiryDate, err := time.Parse(time.RFC3339, strings.TrimSpace(output))
+	expiryDate, err := getCertExpiry(sshRunner, cert)
 	if err != nil {
 		return false, err
 	}
@@ -61,9 +57,23 @@ func checkCertValidity(sshRunner *ssh.Runner, cert string) (bool, error) {
 	return false, nil
 }
 
+func getCertExpiry(sshRunner *ssh.Runner, cert string) (time.Time, error) {
+	output, _, err := sshRunner.Run(fmt.Sprintf(`date --date="$(sudo openssl x509 -in %s -noout -enddate | cut -d= -f 2)" --iso-8601=seconds`, cert))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, strings.TrimSpace(output))
+}
+
 // Return size of disk, used space in bytes and the mountpoint
 func GetRootPartitionUsage(sshRunner *ssh.Runner) (int64, int64, error) {
-	cmd := "df -B1 --output=size,used,target /sysroot | tail -1"
+	return getPartitionUsage(sshRunner, "/sysroot")
+}
+
+// getPartitionUsage returns the size and the used space in bytes of the
+// filesystem mounted at path
+func getPartitionUsage(sshRunner *ssh.Runner, path string) (int64, int64, error) {
+	cmd := fmt.Sprintf("df -B1 --output=size,used,target %s | tail -1", path)
 
 	out, _, err := sshRunner.Run(cmd)
 
@@ -82,6 +92,29 @@ func GetRootPartitionUsage(sshRunner *ssh.Runner) (int64, int64, error) {
 	return diskSize, diskUsage, nil
 }
 
+// GetRAMUsage returns the total and the used memory of the VM in bytes
+func GetRAMUsage(sshRunner *ssh.Runner) (int64, int64, error) {
+	cmd := "awk '/^MemTotal:|^MemAvailable:/ {print $2}' /proc/meminfo"
+
+	out, _, err := sshRunner.Run(cmd)
+	if err != nil {
+		return 0, 0, err
+	}
+	memDetails := strings.Fields(out)
+	if len(memDetails) != 2 {
+		return 0, 0, fmt.Errorf("unexpected /proc/meminfo content: %s", out)
+	}
+	memTotal, err := strconv.ParseInt(memDetails[0], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	memAvailable, err := strconv.ParseInt(memDetails[1], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return memTotal * 1024, (memTotal - memAvailable) * 1024, nil
+}
+
 func EnsureSSHKeyPresentInTheCluster(ctx context.Context, ocConfig oc.Config, sshPublicKeyPath string) error {
 	sshPublicKeyByte, err := ioutil.ReadFile(sshPublicKeyPath)
 	if err != nil {
@@ -303,10 +336,10 @@ func AddProxyConfigToCluster(ctx context.Context, sshRunner *ssh.Runner, ocConfi
 		return err
 	}
 
-	if proxy.ProxyCACert != "" {
+	if caBundle := proxy.TrustedCABundle(); caBundle != "" {
 		trustedCAName := "user-ca-bundle"
 		logging.Debug("Adding proxy CA cert to cluster")
-		if err := addProxyCACertToCluster(sshRunner, ocConfig, proxy, trustedCAName); err != nil {
+		if err := addProxyCACertToCluster(sshRunner, ocConfig, caBundle, trustedCAName); err != nil {
 			return err
 		}
 		patch.Spec.TrustedCA = trustedCA{Name: trustedCAName}
@@ -325,7 +358,7 @@ func AddProxyConfigToCluster(ctx context.Context, sshRunner *ssh.Runner, ocConfi
 	return nil
 }
 
-func addProxyCACertToCluster(sshRunner *ssh.Runner, ocConfig oc.Config, proxy *network.ProxyConfig, trustedCAName string) error {
+func addProxyCACertToCluster(sshRunner *ssh.Runner, ocConfig oc.Config, caBundle string, trustedCAName string) error {
 	proxyConfigMapFileName := fmt.Sprintf("/tmp/%s.json", trustedCAName)
 	proxyCABundleTemplate := `{
   "apiVersion": "v1",
@@ -341,7 +374,7 @@ func addProxyCACertToCluster(sshRunner *ssh.Runner, ocConfig oc.Config, proxy *n
 `
 	// Replace the carriage return ("\n" or "\r\n") with literal `\n` string
 	re := regexp.MustCompile(`\r?\n`)
-	p := fmt.Sprintf(proxyCABundleTemplate, re.ReplaceAllString(proxy.ProxyCACert, `\n`), trustedCAName)
+	p := fmt.Sprintf(proxyCABundleTemplate, re.ReplaceAllString(caBundle, `\n`), trustedCAName)
 	err := sshRunner.CopyData([]byte(p), proxyConfigMapFileName, 0644)
 	if err != nil {
 		return err
@@ -75,6 +75,30 @@ func min(a, b int) int {
 	return a
 }
 
+// DegradedOperators returns the sorted names of the degraded cluster operators
+func (status *Status) DegradedOperators() []string {
+	degraded := append([]string{}, status.degraded...)
+	sort.Strings(degraded)
+	return degraded
+}
+
+// NotReadyOperators returns the sorted names of the cluster operators which
+// are progressing, degraded or not available
+func (status *Status) NotReadyOperators() []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, list := range [][]string{status.progressing, status.degraded, status.unavailable} {
+		for _, name := range list {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (status *Status) IsReady() bool {
 	return status.Available && !status.Progressing && !status.Degraded && !status.Disabled
 }
@@ -98,11 +122,13 @@ func getStatus(ctx context.Context, lister operatorLister, selector []string) (*
 	}
 
 	found := false
+	selected := map[string]bool{}
 	for _, c := range co.Items {
 		if len(selector) > 0 && !contains(c.ObjectMeta.Name, selector) {
 			continue
 		}
 		found = true
+		selected[c.ObjectMeta.Name] = true
 		for _, con := range c.Status.Conditions {
 			switch con.Type {
 			case openshiftapi.OperatorAvailable:
@@ -140,6 +166,13 @@ func getStatus(ctx context.Context, lister operatorLister, selector []string) (*
 	if !found {
 		return nil, errors.New("no cluster operator found")
 	}
+	// a selected operator which does not exist is reported as unavailable
+	for _, name := range selector {
+		if !selected[name] {
+			cs.unavailable = append(cs.unavailable, name)
+			cs.Available = false
+		}
+	}
 	return cs, nil
 }
 
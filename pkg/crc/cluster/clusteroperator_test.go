@@ -35,6 +35,26 @@ func TestGetClusterOperatorsStatusProgressing(t *testing.T) {
 	assert.Equal(t, progressing, status)
 }
 
+func TestNotReadyOperators(t *testing.T) {
+	status := &Status{
+		progressing: []string{"kube-apiserver", "authentication"},
+		degraded:    []string{"authentication"},
+		unavailable: []string{"console"},
+	}
+	assert.Equal(t, []string{"authentication", "console", "kube-apiserver"}, status.NotReadyOperators())
+	assert.Empty(t, available.NotReadyOperators())
+}
+
+func TestGetClusterOperatorsStatusSelector(t *testing.T) {
+	status, err := getStatus(context.Background(), lister("co-progressing.json"), []string{"cloud-credential"})
+	assert.NoError(t, err)
+	assert.Equal(t, available, status)
+
+	status, err = getStatus(context.Background(), lister("co.json"), []string{"cloud-credential", "unknown"})
+	assert.NoError(t, err)
+	assert.Equal(t, &Status{unavailable: []string{"unknown"}}, status)
+}
+
 type mockLister struct {
 	file string
 }
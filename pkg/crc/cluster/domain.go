@@ -0,0 +1,167 @@
+package cluster
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+)
+
+// clusterDomainSecret is the TLS secret with the certificate of the custom
+// cluster domain
+const clusterDomainSecret = "crc-cluster-domain"
+
+// ClusterDomainConfig describes a domain replacing the one of the bundle, the
+// API server is api.<Domain> and the routes are in <AppsDomain>
+type ClusterDomainConfig struct {
+	Domain     string
+	APIHost    string
+	AppsDomain string
+	Cert       []byte
+	Key        []byte
+}
+
+// ConfigureClusterDomain serves the API server and the routes of the cluster
+// with the custom domain: the API server gets a named certificate for its new
+// hostname, the console and the oauth server are moved to the new apps domain,
+// and the router serves the certificate of the custom domain by default.
+func ConfigureClusterDomain(ctx context.Context, ocConfig oc.Config, sshRunner *ssh.Runner, domainConfig ClusterDomainConfig) error {
+	logging.Infof("Configuring the cluster domain %s...", domainConfig.Domain)
+	if err := WaitForOpenshiftResource(ctx, ocConfig, "ingresses.config.openshift.io"); err != nil {
+		return err
+	}
+	// the secret is needed in openshift-config for the API server and the
+	// component routes, and in openshift-ingress for the router
+	for _, namespace := range []string{"openshift-config", "openshift-ingress"} {
+		if err := applyTLSSecret(ocConfig, sshRunner, namespace, domainConfig.Cert, domainConfig.Key); err != nil {
+			return err
+		}
+	}
+
+	patches := []struct {
+		resource  []string
+		patch     interface{}
+		errorText string
+	}{
+		{
+			resource:  []string{"apiserver", "cluster"},
+			patch:     apiServerCertificatePatch(domainConfig),
+			errorText: "Failed to configure the certificate of the API server",
+		},
+		{
+			resource:  []string{"ingresses.config.openshift.io", "cluster"},
+			patch:     ingressDomainPatch(domainConfig),
+			errorText: "Failed to configure the apps domain",
+		},
+		{
+			resource:  []string{"ingresscontroller", "default", "-n", "openshift-ingress-operator"},
+			patch:     ingressCertificatePatch(),
+			errorText: "Failed to configure the certificate of the router",
+		},
+	}
+	for _, p := range patches {
+		patch, err := json.Marshal(p.patch)
+		if err != nil {
+			return err
+		}
+		cmdArgs := append([]string{"patch"}, p.resource...)
+		cmdArgs = append(cmdArgs, "-p", fmt.Sprintf("'%s'", string(patch)), "--type", "merge")
+		if _, stderr, err := ocConfig.RunOcCommand(cmdArgs...); err != nil {
+			return fmt.Errorf("%s %v: %s", p.errorText, err, stderr)
+		}
+	}
+	return nil
+}
+
+func applyTLSSecret(ocConfig oc.Config, sshRunner *ssh.Runner, namespace string, cert, key []byte) error {
+	secret := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"type":       "kubernetes.io/tls",
+		"metadata": map[string]string{
+			"name":      clusterDomainSecret,
+			"namespace": namespace,
+		},
+		"data": map[string]string{
+			"tls.crt": base64.StdEncoding.EncodeToString(cert),
+			"tls.key": base64.StdEncoding.EncodeToString(key),
+		},
+	}
+	data, err := json.Marshal(secret)
+	if err != nil {
+		return err
+	}
+	secretFileName := fmt.Sprintf("/tmp/%s-%s.json", clusterDomainSecret, namespace)
+	if err := sshRunner.CopyData(data, secretFileName, 0600); err != nil {
+		return err
+	}
+	defer func() {
+		_, _, _ = sshRunner.Run("rm", "-f", secretFileName)
+	}()
+	if _, stderr, err := ocConfig.RunOcCommandPrivate("apply", "-f", secretFileName); err != nil {
+		return fmt.Errorf("Failed to add the certificate of the cluster domain %v: %s", err, stderr)
+	}
+	return nil
+}
+
+type secretReference struct {
+	Name string `json:"name"`
+}
+
+func apiServerCertificatePatch(domainConfig ClusterDomainConfig) interface{} {
+	type namedCertificate struct {
+		Names              []string        `json:"names"`
+		ServingCertificate secretReference `json:"servingCertificate"`
+	}
+	return map[string]interface{}{
+		"spec": map[string]interface{}{
+			"servingCerts": map[string]interface{}{
+				"namedCertificates": []namedCertificate{
+					{
+						Names:              []string{domainConfig.APIHost},
+						ServingCertificate: secretReference{Name: clusterDomainSecret},
+					},
+				},
+			},
+		},
+	}
+}
+
+func ingressDomainPatch(domainConfig ClusterDomainConfig) interface{} {
+	type componentRoute struct {
+		Name                     string          `json:"name"`
+		Namespace                string          `json:"namespace"`
+		Hostname                 string          `json:"hostname"`
+		ServingCertKeyPairSecret secretReference `json:"servingCertKeyPairSecret"`
+	}
+	route := func(name, namespace, hostname string) componentRoute {
+		return componentRoute{
+			Name:                     name,
+			Namespace:                namespace,
+			Hostname:                 fmt.Sprintf("%s.%s", hostname, domainConfig.AppsDomain),
+			ServingCertKeyPairSecret: secretReference{Name: clusterDomainSecret},
+		}
+	}
+	return map[string]interface{}{
+		"spec": map[string]interface{}{
+			"appsDomain": domainConfig.AppsDomain,
+			"componentRoutes": []componentRoute{
+				route("console", "openshift-console", "console-openshift-console"),
+				route("downloads", "openshift-console", "downloads-openshift-console"),
+				route("oauth-openshift", "openshift-authentication", "oauth-openshift"),
+			},
+		},
+	}
+}
+
+func ingressCertificatePatch() interface{} {
+	return map[string]interface{}{
+		"spec": map[string]interface{}{
+			"defaultCertificate": secretReference{Name: clusterDomainSecret},
+		},
+	}
+}
@@ -0,0 +1,30 @@
+package cluster
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testDomainConfig = ClusterDomainConfig{
+	Domain:     "crc.example.com",
+	APIHost:    "api.crc.example.com",
+	AppsDomain: "apps.crc.example.com",
+}
+
+func TestAPIServerCertificatePatch(t *testing.T) {
+	patch, err := json.Marshal(apiServerCertificatePatch(testDomainConfig))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"spec":{"servingCerts":{"namedCertificates":[{"names":["api.crc.example.com"],"servingCertificate":{"name":"crc-cluster-domain"}}]}}}`, string(patch))
+}
+
+func TestIngressDomainPatch(t *testing.T) {
+	patch, err := json.Marshal(ingressDomainPatch(testDomainConfig))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"spec":{"appsDomain":"apps.crc.example.com","componentRoutes":[
+		{"name":"console","namespace":"openshift-console","hostname":"console-openshift-console.apps.crc.example.com","servingCertKeyPairSecret":{"name":"crc-cluster-domain"}},
+		{"name":"downloads","namespace":"openshift-console","hostname":"downloads-openshift-console.apps.crc.example.com","servingCertKeyPairSecret":{"name":"crc-cluster-domain"}},
+		{"name":"oauth-openshift","namespace":"openshift-authentication","hostname":"oauth-openshift.apps.crc.example.com","servingCertKeyPairSecret":{"name":"crc-cluster-domain"}}]}}`, string(patch))
+}
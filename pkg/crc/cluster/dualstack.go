@@ -0,0 +1,125 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/pkg/errors"
+)
+
+// The IPv6 networks added to the cluster network configuration, they are
+// unique local addresses which are not routed outside of the cluster
+const (
+	ipv6ClusterNetwork    = "fd01::/48"
+	ipv6ClusterHostPrefix = 64
+	ipv6ServiceNetwork    = "fd02::/112"
+)
+
+type clusterNetworkEntry struct {
+	CIDR       string `json:"cidr"`
+	HostPrefix int    `json:"hostPrefix"`
+}
+
+type networkConfigSpec struct {
+	ClusterNetwork []clusterNetworkEntry `json:"clusterNetwork"`
+	ServiceNetwork []string              `json:"serviceNetwork"`
+	NetworkType    string                `json:"networkType"`
+}
+
+// EnableDualStack adds IPv6 networks to the pod and service networks of the
+// cluster. The instance needs a global IPv6 address, otherwise the cluster
+// stays IPv4-only. Only the OVNKubernetes network plugin supports the
+// conversion to dual-stack.
+func EnableDualStack(ctx context.Context, ocConfig oc.Config, sshRunner *ssh.Runner) error {
+	hasIPv6, err := instanceHasGlobalIPv6(sshRunner)
+	if err != nil {
+		return err
+	}
+	if !hasIPv6 {
+		logging.Warn("The instance has no IPv6 address, the cluster network stays IPv4-only")
+		return nil
+	}
+	if err := WaitForOpenshiftResource(ctx, ocConfig, "network.config.openshift.io"); err != nil {
+		return err
+	}
+	stdout, stderr, err := ocConfig.RunOcCommand("get", "network.config.openshift.io", "cluster", "-o", `jsonpath="{.spec}"`)
+	if err != nil {
+		return fmt.Errorf("Failed to get the cluster network configuration %v: %s", err, stderr)
+	}
+	var spec networkConfigSpec
+	if err := json.Unmarshal([]byte(strings.Trim(strings.TrimSpace(stdout), `"`)), &spec); err != nil {
+		return errors.Wrap(err, "Cannot parse the cluster network configuration")
+	}
+	if spec.NetworkType != "OVNKubernetes" {
+		logging.Warnf("The %s network plugin does not support dual-stack, the cluster network stays IPv4-only", spec.NetworkType)
+		return nil
+	}
+	patch, changed := dualStackPatch(spec)
+	if !changed {
+		return nil
+	}
+	logging.Info("Adding IPv6 networks to the cluster network configuration...")
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	cmdArgs := []string{"patch", "network.config.openshift.io", "cluster", "-p", fmt.Sprintf("'%s'", string(data)), "--type", "merge"}
+	if _, stderr, err := ocConfig.RunOcCommand(cmdArgs...); err != nil {
+		return fmt.Errorf("Failed to enable dual-stack networking %v: %s", err, stderr)
+	}
+	return nil
+}
+
+func instanceHasGlobalIPv6(sshRunner *ssh.Runner) (bool, error) {
+	stdout, _, err := sshRunner.Run("ip", "-6", "-o", "addr", "show", "scope", "global")
+	if err != nil {
+		return false, errors.Wrap(err, "Cannot list the IPv6 addresses of the instance")
+	}
+	return strings.TrimSpace(stdout) != "", nil
+}
+
+// dualStackPatch returns the merge patch adding the IPv6 networks to spec,
+// and false when spec already has IPv6 pod and service networks
+func dualStackPatch(spec networkConfigSpec) (interface{}, bool) {
+	changed := false
+	clusterNetwork := spec.ClusterNetwork
+	if !hasIPv6ClusterNetwork(clusterNetwork) {
+		clusterNetwork = append(clusterNetwork, clusterNetworkEntry{CIDR: ipv6ClusterNetwork, HostPrefix: ipv6ClusterHostPrefix})
+		changed = true
+	}
+	serviceNetwork := spec.ServiceNetwork
+	if !hasIPv6CIDR(serviceNetwork) {
+		serviceNetwork = append(serviceNetwork, ipv6ServiceNetwork)
+		changed = true
+	}
+	return map[string]interface{}{
+		"spec": map[string]interface{}{
+			"clusterNetwork": clusterNetwork,
+			"serviceNetwork": serviceNetwork,
+		},
+	}, changed
+}
+
+func hasIPv6ClusterNetwork(networks []clusterNetworkEntry) bool {
+	var cidrs []string
+	for _, network := range networks {
+		cidrs = append(cidrs, network.CIDR)
+	}
+	return hasIPv6CIDR(cidrs)
+}
+
+func hasIPv6CIDR(cidrs []string) bool {
+	for _, cidr := range cidrs {
+		ip, _, err := net.ParseCIDR(cidr)
+		if err == nil && ip.To4() == nil {
+			return true
+		}
+	}
+	return false
+}
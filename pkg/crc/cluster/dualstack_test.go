@@ -0,0 +1,34 @@
+package cluster
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDualStackPatch(t *testing.T) {
+	spec := networkConfigSpec{
+		ClusterNetwork: []clusterNetworkEntry{{CIDR: "10.217.0.0/22", HostPrefix: 23}},
+		ServiceNetwork: []string{"10.217.4.0/23"},
+		NetworkType:    "OVNKubernetes",
+	}
+	patch, changed := dualStackPatch(spec)
+	assert.True(t, changed)
+	data, err := json.Marshal(patch)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"spec": {
+		"clusterNetwork": [{"cidr": "10.217.0.0/22", "hostPrefix": 23}, {"cidr": "fd01::/48", "hostPrefix": 64}],
+		"serviceNetwork": ["10.217.4.0/23", "fd02::/112"]
+	}}`, string(data))
+}
+
+func TestDualStackPatchAlreadyDualStack(t *testing.T) {
+	spec := networkConfigSpec{
+		ClusterNetwork: []clusterNetworkEntry{{CIDR: "10.217.0.0/22", HostPrefix: 23}, {CIDR: "fd01::/48", HostPrefix: 64}},
+		ServiceNetwork: []string{"10.217.4.0/23", "fd02::/112"},
+	}
+	_, changed := dualStackPatch(spec)
+	assert.False(t, changed)
+}
@@ -0,0 +1,42 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/oc"
+)
+
+// nvidiaVendorID is the PCI vendor ID of NVIDIA
+const nvidiaVendorID = "10de"
+
+// LabelGPUNodes labels the nodes with the vendors of the GPUs assigned to the
+// instance, like node-feature-discovery does, so that the GPU operators
+// deploy their drivers and device plugins on them
+func LabelGPUNodes(ctx context.Context, ocConfig oc.Config, vendors []string) error {
+	labels := gpuNodeLabels(vendors)
+	if len(labels) == 0 {
+		return nil
+	}
+	logging.Debugf("Labelling the nodes with %v", labels)
+	if err := WaitForOpenshiftResource(ctx, ocConfig, "nodes"); err != nil {
+		return err
+	}
+	args := append([]string{"label", "nodes", "--all", "--overwrite"}, labels...)
+	if _, stderr, err := ocConfig.RunOcCommand(args...); err != nil {
+		return fmt.Errorf("Failed to label the nodes with their GPUs %v: %s", err, stderr)
+	}
+	return nil
+}
+
+func gpuNodeLabels(vendors []string) []string {
+	var labels []string
+	for _, vendor := range vendors {
+		labels = append(labels, fmt.Sprintf("feature.node.kubernetes.io/pci-%s.present=true", vendor))
+		if vendor == nvidiaVendorID {
+			labels = append(labels, "nvidia.com/gpu.present=true")
+		}
+	}
+	return labels
+}
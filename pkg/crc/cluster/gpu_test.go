@@ -0,0 +1,16 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGPUNodeLabels(t *testing.T) {
+	assert.Empty(t, gpuNodeLabels(nil))
+	assert.Equal(t, []string{
+		"feature.node.kubernetes.io/pci-10de.present=true",
+		"nvidia.com/gpu.present=true",
+		"feature.node.kubernetes.io/pci-1002.present=true",
+	}, gpuNodeLabels([]string{"10de", "1002"}))
+}
@@ -0,0 +1,137 @@
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/code-ready/crc/pkg/crc/systemd"
+	"github.com/code-ready/crc/pkg/crc/systemd/states"
+)
+
+const (
+	// ImageCacheDir is where the host directory of the image cache is
+	// mounted in the instance
+	ImageCacheDir = "/var/mnt/crc-image-cache"
+
+	// the images of the bundle are not copied to the cache, they are listed
+	// when the cache is configured for the first time
+	imageCacheBaselinePath = "/var/lib/crc-image-cache-baseline"
+	imageCacheRunRoot      = "/run/crc-image-cache"
+	saveImagesScriptPath   = "/usr/local/bin/crc-save-images.sh"
+	imageCacheMarker       = "# crc-image-cache"
+
+	storageConfPath    = "/etc/containers/storage.conf"
+	storageOptionsLine = "[storage.options]"
+)
+
+// saveImagesScript copies the images pulled since the creation of the
+// instance to the cache, the images already in the cache are skipped
+const saveImagesScript = `cached=$(podman --root %[1]s --runroot %[2]s images -q --no-trunc)
+podman images --no-trunc --format '{{.ID}} {{.Repository}}:{{.Tag}}' | while read id name; do
+	case "$name" in *'<none>'*) continue;; esac
+	grep -q "$id" %[3]s && continue
+	echo "$cached" | grep -q "$id" && continue
+	podman push -q "$id" "containers-storage:[overlay@%[1]s+%[2]s]$name" || echo "cannot cache $name" >&2
+done
+`
+
+// ConfigureImageCache adds the image cache mounted at ImageCacheDir to the
+// additional image stores of CRI-O and podman, or removes it when enabled is
+// false. CRI-O is restarted when its configuration changed.
+func ConfigureImageCache(sshRunner *ssh.Runner, enabled bool) error {
+	conf, _, err := sshRunner.RunPrivileged("reading the storage configuration", "cat", storageConfPath)
+	if err != nil {
+		return err
+	}
+	updated, err := withImageCache(conf, enabled)
+	if err != nil {
+		return err
+	}
+	if enabled {
+		if _, _, err := sshRunner.Run("test", "-f", imageCacheBaselinePath); err != nil {
+			if _, stderr, err := sshRunner.RunPrivileged("listing the images of the bundle", "sh", "-c",
+				fmt.Sprintf("'podman images -q --no-trunc > %s'", imageCacheBaselinePath)); err != nil {
+				return fmt.Errorf("Failed to list the images of the bundle %v: %s", err, stderr)
+			}
+		}
+	}
+	if updated == conf {
+		return nil
+	}
+	logging.Debugf("Updating %s for the image cache", storageConfPath)
+	if err := sshRunner.CopyData([]byte(updated), storageConfPath, 0644); err != nil {
+		return err
+	}
+	sd := systemd.NewInstanceSystemdCommander(sshRunner)
+	if status, err := sd.Status("crio"); err == nil && status == states.Running {
+		return sd.Restart("crio")
+	}
+	return nil
+}
+
+// withImageCache adds the image cache to the additional image stores of the
+// storage configuration. It is added on a marked line of its own, so that it
+// can be removed, and an existing list is split after its opening bracket.
+func withImageCache(conf string, enabled bool) (string, error) {
+	var lines []string
+	for _, line := range strings.Split(conf, "\n") {
+		if !strings.HasSuffix(line, imageCacheMarker) {
+			lines = append(lines, line)
+		}
+	}
+	if !enabled {
+		return strings.Join(lines, "\n"), nil
+	}
+
+	store := fmt.Sprintf(`"%s", %s`, ImageCacheDir, imageCacheMarker)
+	options := -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == storageOptionsLine:
+			options = i
+		case strings.HasPrefix(trimmed, "[") && options != -1:
+			// end of the options section, the key is not set
+			return insertLines(lines, options+1, "additionalimagestores = [", store, "]"), nil
+		case options != -1 && strings.HasPrefix(trimmed, "additionalimagestores"):
+			bracket := strings.Index(line, "[")
+			if bracket == -1 {
+				return "", fmt.Errorf("Unexpected additionalimagestores line in %s: %s", storageConfPath, line)
+			}
+			added := []string{line[:bracket+1], store}
+			if tail := strings.TrimSpace(line[bracket+1:]); tail != "" {
+				added = append(added, tail)
+			}
+			return insertLines(append(lines[:i:i], lines[i+1:]...), i, added...), nil
+		}
+	}
+	if options == -1 {
+		return "", fmt.Errorf("%s has no %s section", storageConfPath, storageOptionsLine)
+	}
+	return insertLines(lines, options+1, "additionalimagestores = [", store, "]"), nil
+}
+
+func insertLines(lines []string, index int, added ...string) string {
+	result := append(append(append([]string{}, lines[:index]...), added...), lines[index:]...)
+	return strings.Join(result, "\n")
+}
+
+// SaveImagesToCache copies the images pulled in the instance to the image
+// cache, so that they are not pulled again after the instance is recreated
+func SaveImagesToCache(sshRunner *ssh.Runner) error {
+	logging.Info("Saving the pulled images to the image cache...")
+	script := fmt.Sprintf(saveImagesScript, ImageCacheDir, imageCacheRunRoot, imageCacheBaselinePath)
+	if err := sshRunner.CopyData([]byte(script), saveImagesScriptPath, 0755); err != nil {
+		return err
+	}
+	_, stderr, err := sshRunner.RunPrivileged("saving the images to the image cache", "sh", saveImagesScriptPath)
+	if err != nil {
+		return fmt.Errorf("Failed to save the images to the image cache %v: %s", err, stderr)
+	}
+	if stderr != "" {
+		logging.Debugf("Image cache: %s", stderr)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const storageConf = `[storage]
+driver = "overlay"
+graphroot = "/var/lib/containers/storage"
+
+[storage.options]
+additionalimagestores = [
+]
+
+[storage.options.overlay]
+mountopt = "nodev,metacopy=on"
+`
+
+func TestWithImageCache(t *testing.T) {
+	enabled, err := withImageCache(storageConf, true)
+	require.NoError(t, err)
+	assert.Contains(t, enabled, "[storage.options]\nadditionalimagestores = [\n\"/var/mnt/crc-image-cache\", # crc-image-cache\n]\n")
+
+	again, err := withImageCache(enabled, true)
+	require.NoError(t, err)
+	assert.Equal(t, enabled, again)
+
+	disabled, err := withImageCache(enabled, false)
+	require.NoError(t, err)
+	assert.Equal(t, storageConf, disabled)
+
+	_, err = withImageCache("[storage]\n", true)
+	assert.EqualError(t, err, "/etc/containers/storage.conf has no [storage.options] section")
+}
+
+func TestWithImageCacheSingleLineList(t *testing.T) {
+	enabled, err := withImageCache("[storage.options]\nadditionalimagestores = [\"/usr/lib/containers/storage\"]\n", true)
+	require.NoError(t, err)
+	assert.Equal(t, "[storage.options]\nadditionalimagestores = [\n\"/var/mnt/crc-image-cache\", # crc-image-cache\n\"/usr/lib/containers/storage\"]\n", enabled)
+}
+
+func TestWithImageCacheWithoutList(t *testing.T) {
+	enabled, err := withImageCache("[storage.options]\npull_options = {}\n\n[storage.options.overlay]\n", true)
+	require.NoError(t, err)
+	assert.Equal(t, "[storage.options]\nadditionalimagestores = [\n\"/var/mnt/crc-image-cache\", # crc-image-cache\n]\npull_options = {}\n\n[storage.options.overlay]\n", enabled)
+
+	enabled, err = withImageCache("[storage.options]\n", true)
+	require.NoError(t, err)
+	assert.Equal(t, "[storage.options]\nadditionalimagestores = [\n\"/var/mnt/crc-image-cache\", # crc-image-cache\n]\n", enabled)
+}
@@ -41,6 +41,33 @@ func UpdateKubeAdminUserPassword(ctx context.Context, ocConfig oc.Config, newPas
 	if err != nil {
 		return fmt.Errorf("Cannot generate the kubeadmin user password: %w", err)
 	}
+	return updateHtpasswdSecret(ctx, ocConfig, kubeAdminPassword)
+}
+
+// RotateKubeAdminUserPassword generates a new password for the kubeadmin user
+// and updates the htpasswd secret with it. The password file is only replaced
+// once the cluster accepts the new password, it keeps the password of the
+// cluster when the update fails.
+func RotateKubeAdminUserPassword(ctx context.Context, ocConfig oc.Config) (string, error) {
+	return rotateKubeAdminUserPassword(ctx, ocConfig, constants.GetKubeAdminPasswordPath())
+}
+
+func rotateKubeAdminUserPassword(ctx context.Context, ocConfig oc.Config, kubeAdminPasswordFile string) (string, error) {
+	logging.Infof("Generating new password for the kubeadmin user")
+	kubeAdminPassword, err := GenerateRandomPasswordHash(23)
+	if err != nil {
+		return "", fmt.Errorf("Cannot generate the kubeadmin user password: %w", err)
+	}
+	if err := updateHtpasswdSecret(ctx, ocConfig, kubeAdminPassword); err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(kubeAdminPasswordFile, []byte(kubeAdminPassword), 0600); err != nil {
+		return "", fmt.Errorf("The kubeadmin password of the cluster was changed but it cannot be saved, rotate it again: %w", err)
+	}
+	return kubeAdminPassword, nil
+}
+
+func updateHtpasswdSecret(ctx context.Context, ocConfig oc.Config, kubeAdminPassword string) error {
 	credentials := map[string]string{
 		"developer": "developer",
 		"kubeadmin": kubeAdminPassword,
@@ -1,9 +1,16 @@
 package cluster
 
 import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/code-ready/crc/pkg/crc/oc"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCompareHtpasswdWithOneUsername(t *testing.T) {
@@ -56,3 +63,73 @@ func TestCompareFalseWithCustomEntries(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, ok)
 }
+
+// htpasswdRunner runs the oc commands reading and patching the htpasswd
+// secret of a fake cluster
+type htpasswdRunner struct {
+	htpasswd string
+	patchErr error
+}
+
+func (r *htpasswdRunner) Run(command string, args ...string) (string, string, error) {
+	return r.RunPrivate(command, args...)
+}
+
+func (r *htpasswdRunner) RunPrivate(command string, args ...string) (string, string, error) {
+	for i, arg := range args {
+		if arg != "-p" {
+			continue
+		}
+		if r.patchErr != nil {
+			return "", "forbidden", r.patchErr
+		}
+		r.htpasswd = strings.TrimSuffix(strings.TrimPrefix(args[i+1], `'{"data":{"htpasswd":"`), `"}}'`)
+		return "", "", nil
+	}
+	return r.htpasswd, "", nil
+}
+
+func (r *htpasswdRunner) RunPrivileged(reason string, cmdAndArgs ...string) (string, string, error) {
+	return "", "", errors.New("not supported")
+}
+
+func newHtpasswdRunner(t *testing.T, kubeAdminPassword string) *htpasswdRunner {
+	htpasswd, err := getHtpasswd(map[string]string{"developer": "developer", "kubeadmin": kubeAdminPassword}, []string{})
+	require.NoError(t, err)
+	return &htpasswdRunner{htpasswd: htpasswd}
+}
+
+func TestRotateKubeAdminUserPassword(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "kubeadmin-password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("old-password"), 0600))
+	runner := newHtpasswdRunner(t, "old-password")
+
+	password, err := rotateKubeAdminUserPassword(context.Background(), oc.Config{Runner: runner}, passwordFile)
+	require.NoError(t, err)
+	assert.NotEqual(t, "old-password", password)
+
+	content, err := os.ReadFile(passwordFile)
+	require.NoError(t, err)
+	assert.Equal(t, password, string(content))
+	ok, _, err := compareHtpasswd(runner.htpasswd, map[string]string{"developer": "developer", "kubeadmin": password})
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestRotateKubeAdminUserPasswordFailure(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "kubeadmin-password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("old-password"), 0600))
+	runner := newHtpasswdRunner(t, "old-password")
+	runner.patchErr = errors.New("exit status 1")
+
+	_, err := rotateKubeAdminUserPassword(context.Background(), oc.Config{Runner: runner}, passwordFile)
+	assert.EqualError(t, err, "Failed to update kubeadmin password exit status 1: forbidden")
+
+	// the file keeps the password of the cluster
+	content, err := os.ReadFile(passwordFile)
+	require.NoError(t, err)
+	assert.Equal(t, "old-password", string(content))
+	ok, _, err := compareHtpasswd(runner.htpasswd, map[string]string{"developer": "developer", "kubeadmin": "old-password"})
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
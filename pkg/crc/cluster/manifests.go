@@ -0,0 +1,169 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+)
+
+// manifestsRolloutTimeout is how long to wait for the rollout of each
+// workload of the startup manifests
+const manifestsRolloutTimeout = 10 * time.Minute
+
+const manifestsInstanceDir = "/tmp/crc-manifests"
+
+// ManifestsApplication is the result of the application of the directory of
+// the startup-manifests setting
+type ManifestsApplication struct {
+	Directory string
+	Resources []AppliedResource
+	// Error is set when the manifests could not be applied at all
+	Error string
+}
+
+// AppliedResource is a resource created or updated from the startup
+// manifests. Ready is false when the rollout of a workload didn't complete.
+type AppliedResource struct {
+	Kind      string
+	Namespace string `json:",omitempty"`
+	Name      string
+	Ready     bool
+	Error     string `json:",omitempty"`
+}
+
+// rolloutKinds are the kinds supported by 'oc rollout status'
+var rolloutKinds = map[string]bool{
+	"Deployment":  true,
+	"StatefulSet": true,
+	"DaemonSet":   true,
+}
+
+// ApplyManifests applies the Kubernetes manifests of the host directory dir,
+// with kustomize when it has a kustomization file, and waits for the rollout
+// of the workloads. The failures are reported in the result, the cluster
+// stays usable without the manifests.
+func ApplyManifests(ctx context.Context, ocConfig oc.Config, sshRunner *ssh.Runner, dir string) *ManifestsApplication {
+	result := &ManifestsApplication{Directory: dir}
+	logging.Infof("Applying the manifests of %s...", dir)
+	if err := copyManifests(sshRunner, dir); err != nil {
+		result.Error = fmt.Sprintf("Cannot copy the manifests to the instance: %v", err)
+		return result
+	}
+	defer func() {
+		_, _, _ = sshRunner.Run("rm", "-rf", manifestsInstanceDir)
+	}()
+
+	stdout, stderr, err := ocConfig.RunOcCommand(applyManifestsArgs(dir)...)
+	if err != nil {
+		result.Error = fmt.Sprintf("Failed to apply the manifests: %s", strings.TrimSpace(stderr))
+		return result
+	}
+	resources, err := parseAppliedResources([]byte(stdout))
+	if err != nil {
+		result.Error = fmt.Sprintf("Cannot parse the applied resources: %v", err)
+		return result
+	}
+
+	for i := range resources {
+		resource := &resources[i]
+		if !rolloutKinds[resource.Kind] {
+			resource.Ready = true
+			continue
+		}
+		if ctx.Err() != nil {
+			resource.Error = ctx.Err().Error()
+			continue
+		}
+		args := []string{"rollout", "status", fmt.Sprintf("%s/%s", strings.ToLower(resource.Kind), resource.Name),
+			fmt.Sprintf("--timeout=%s", manifestsRolloutTimeout)}
+		if resource.Namespace != "" {
+			args = append(args, "-n", resource.Namespace)
+		}
+		if _, stderr, err := ocConfig.RunOcCommand(args...); err != nil {
+			resource.Error = fmt.Sprintf("The rollout did not complete: %s", strings.TrimSpace(stderr))
+			continue
+		}
+		resource.Ready = true
+	}
+	result.Resources = resources
+	return result
+}
+
+func applyManifestsArgs(dir string) []string {
+	for _, name := range []string{"kustomization.yaml", "kustomization.yml", "Kustomization"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return []string{"apply", "-k", manifestsInstanceDir, "-o", "json"}
+		}
+	}
+	return []string{"apply", "-R", "-f", manifestsInstanceDir, "-o", "json"}
+}
+
+// copyManifests copies the files of dir and of its subdirectories, the
+// kustomizations can refer to them
+func copyManifests(sshRunner *ssh.Runner, dir string) error {
+	if _, _, err := sshRunner.Run("rm", "-rf", manifestsInstanceDir); err != nil {
+		return err
+	}
+	return filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		dest := path.Join(manifestsInstanceDir, filepath.ToSlash(rel))
+		switch {
+		case info.IsDir():
+			_, _, err = sshRunner.Run("mkdir", "-p", dest)
+			return err
+		case info.Mode().IsRegular():
+			return sshRunner.CopyFile(file, dest, 0600)
+		default:
+			return nil
+		}
+	})
+}
+
+type appliedObject struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+	Items []appliedObject `json:"items"`
+}
+
+// parseAppliedResources parses the output of 'oc apply -o json', which is
+// either a single object or a list
+func parseAppliedResources(data []byte) ([]AppliedResource, error) {
+	if strings.TrimSpace(string(data)) == "" {
+		return nil, nil
+	}
+	var object appliedObject
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	objects := []appliedObject{object}
+	if object.Kind == "List" {
+		objects = object.Items
+	}
+	var resources []AppliedResource
+	for _, object := range objects {
+		resources = append(resources, AppliedResource{
+			Kind:      object.Kind,
+			Namespace: object.Metadata.Namespace,
+			Name:      object.Metadata.Name,
+		})
+	}
+	return resources, nil
+}
@@ -0,0 +1,42 @@
+package cluster
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAppliedResources(t *testing.T) {
+	resources, err := parseAppliedResources([]byte(`{
+  "apiVersion": "v1",
+  "kind": "List",
+  "items": [
+    {"kind": "Namespace", "metadata": {"name": "demo"}},
+    {"kind": "Deployment", "metadata": {"name": "web", "namespace": "demo"}}
+  ]
+}`))
+	require.NoError(t, err)
+	assert.Equal(t, []AppliedResource{
+		{Kind: "Namespace", Name: "demo"},
+		{Kind: "Deployment", Namespace: "demo", Name: "web"},
+	}, resources)
+
+	resources, err = parseAppliedResources([]byte(`{"kind": "ConfigMap", "metadata": {"name": "settings", "namespace": "demo"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, []AppliedResource{{Kind: "ConfigMap", Namespace: "demo", Name: "settings"}}, resources)
+
+	resources, err = parseAppliedResources(nil)
+	require.NoError(t, err)
+	assert.Empty(t, resources)
+}
+
+func TestApplyManifestsArgs(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, []string{"apply", "-R", "-f", "/tmp/crc-manifests", "-o", "json"}, applyManifestsArgs(dir))
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte("resources: []\n"), 0600))
+	assert.Equal(t, []string{"apply", "-k", "/tmp/crc-manifests", "-o", "json"}, applyManifestsArgs(dir))
+}
@@ -1,15 +1,22 @@
 package cluster
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/code-ready/crc/pkg/crc/oc"
 	v1 "github.com/openshift/api/config/v1"
 	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func StartMonitoring(ocConfig oc.Config) error {
+// StartMonitoring removes the overrides of the cluster version which keep
+// the cluster monitoring operator unmanaged and scaled down in the bundle
+func StartMonitoring(ctx context.Context, ocConfig oc.Config) error {
+	if err := WaitForOpenshiftResource(ctx, ocConfig, "clusterversion"); err != nil {
+		return err
+	}
 	data, _, err := ocConfig.RunOcCommand("get", "clusterversion/version", "-o", "json")
 	if err != nil {
 		return err
@@ -32,6 +39,25 @@ func StartMonitoring(ocConfig oc.Config) error {
 	return err
 }
 
+// IsMonitoringEnabled returns true when the cluster monitoring operator is
+// managed by the cluster version operator
+func IsMonitoringEnabled(ctx context.Context, ip string, kubeconfigFilePath string) (bool, error) {
+	client, err := kubernetesClient(ip, kubeconfigFilePath)
+	if err != nil {
+		return false, err
+	}
+	cv, err := client.ConfigV1().ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	return monitoringEnabled(*cv), nil
+}
+
+func monitoringEnabled(cv v1.ClusterVersion) bool {
+	index := getIndexInOverridesForObjectName(cv, "cluster-monitoring-operator")
+	return index == -1 || !cv.Spec.Overrides[index].Unmanaged
+}
+
 func getIndexInOverridesForObjectName(cv v1.ClusterVersion, objectName string) int {
 	pos := -1
 	for i, override := range cv.Spec.Overrides {
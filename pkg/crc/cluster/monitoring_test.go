@@ -0,0 +1,22 @@
+package cluster
+
+import (
+	"testing"
+
+	v1 "github.com/openshift/api/config/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonitoringEnabled(t *testing.T) {
+	var cv v1.ClusterVersion
+	assert.True(t, monitoringEnabled(cv))
+
+	cv.Spec.Overrides = []v1.ComponentOverride{
+		{Kind: "Deployment", Group: "apps", Namespace: "openshift-monitoring", Name: "cluster-monitoring-operator", Unmanaged: true},
+		{Kind: "ClusterOperator", Group: "config.openshift.io", Name: "monitoring", Unmanaged: true},
+	}
+	assert.False(t, monitoringEnabled(cv))
+
+	cv.Spec.Overrides[0].Unmanaged = false
+	assert.True(t, monitoringEnabled(cv))
+}
@@ -0,0 +1,69 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/oc"
+)
+
+// allNodesSelector matches the control plane and the worker nodes
+const allNodesSelector = "kubernetes.io/os=linux"
+
+// CordonNodes marks the nodes as unschedulable so that no pod is started
+// while the cluster shuts down
+func CordonNodes(ocConfig oc.Config) error {
+	_, stderr, err := ocConfig.RunOcCommand("adm", "cordon", "--selector", allNodesSelector)
+	if err != nil {
+		return fmt.Errorf("Failed to cordon the nodes: %v %s", err, stderr)
+	}
+	return nil
+}
+
+// UncordonNodes makes the nodes schedulable again after CordonNodes
+func UncordonNodes(ocConfig oc.Config) error {
+	_, stderr, err := ocConfig.RunOcCommand("adm", "uncordon", "--selector", allNodesSelector)
+	if err != nil {
+		return fmt.Errorf("Failed to uncordon the nodes: %v %s", err, stderr)
+	}
+	return nil
+}
+
+// GetNodesReady returns the readiness of the nodes of the cluster, indexed by
+// node name
+func GetNodesReady(ocConfig oc.Config) (map[string]bool, error) {
+	stdout, stderr, err := ocConfig.RunOcCommand("get", "nodes", "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get the nodes: %v %s", err, stderr)
+	}
+	return parseNodesReady([]byte(stdout))
+}
+
+func parseNodesReady(data []byte) (map[string]bool, error) {
+	var nodes struct {
+		Items []struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Status struct {
+				Conditions []struct {
+					Type   string `json:"type"`
+					Status string `json:"status"`
+				} `json:"conditions"`
+			} `json:"status"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(data, &nodes); err != nil {
+		return nil, err
+	}
+	ready := make(map[string]bool)
+	for _, node := range nodes.Items {
+		ready[node.Metadata.Name] = false
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == "Ready" {
+				ready[node.Metadata.Name] = condition.Status == "True"
+			}
+		}
+	}
+	return ready, nil
+}
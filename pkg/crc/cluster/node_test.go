@@ -0,0 +1,43 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const nodesJSON = `{
+  "apiVersion": "v1",
+  "kind": "List",
+  "items": [
+    {
+      "metadata": {"name": "crc-xxxxx-master-0"},
+      "status": {"conditions": [
+        {"type": "MemoryPressure", "status": "False"},
+        {"type": "Ready", "status": "True"}
+      ]}
+    },
+    {
+      "metadata": {"name": "crc-worker-1"},
+      "status": {"conditions": [{"type": "Ready", "status": "Unknown"}]}
+    },
+    {
+      "metadata": {"name": "crc-worker-2"},
+      "status": {}
+    }
+  ]
+}`
+
+func TestParseNodesReady(t *testing.T) {
+	ready, err := parseNodesReady([]byte(nodesJSON))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{
+		"crc-xxxxx-master-0": true,
+		"crc-worker-1":       false,
+		"crc-worker-2":       false,
+	}, ready)
+
+	_, err = parseNodesReady([]byte("not json"))
+	assert.Error(t, err)
+}
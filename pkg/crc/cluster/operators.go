@@ -0,0 +1,172 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	crcerrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+)
+
+// operatorsNamespace has the global operator group of the cluster, the
+// operators installed in it watch all the namespaces
+const operatorsNamespace = "openshift-operators"
+
+// operatorsInstallTimeout is how long to wait for the operators to be
+// installed, their images are pulled by the instance
+const operatorsInstallTimeout = 15 * time.Minute
+
+// OperatorInstallation is the result of the installation of an operator of
+// the enable-operators setting
+type OperatorInstallation struct {
+	Name string
+	// CSV is the cluster service version installed by the subscription
+	CSV   string
+	Ready bool
+	Error string
+}
+
+type operatorPackage struct {
+	name             string
+	catalog          string
+	catalogNamespace string
+	channel          string
+}
+
+// InstallOperators subscribes to the packages of the catalogs of the cluster
+// and waits for their cluster service versions to succeed. The failures are
+// reported in the results, the cluster stays usable without the operators.
+func InstallOperators(ctx context.Context, ocConfig oc.Config, sshRunner *ssh.Runner, names []string) []OperatorInstallation {
+	results := make([]OperatorInstallation, len(names))
+	for i, name := range names {
+		results[i].Name = name
+	}
+	if err := WaitForOpenshiftResource(ctx, ocConfig, "packagemanifests"); err != nil {
+		for i := range results {
+			results[i].Error = fmt.Sprintf("The catalogs of the cluster are not available: %v", err)
+		}
+		return results
+	}
+
+	var packages []operatorPackage
+	for i, name := range names {
+		pkg, err := getOperatorPackage(ocConfig, name)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		packages = append(packages, pkg)
+	}
+	if len(packages) == 0 {
+		return results
+	}
+	if err := subscribeOperators(ocConfig, sshRunner, packages); err != nil {
+		for i := range results {
+			if results[i].Error == "" {
+				results[i].Error = err.Error()
+			}
+		}
+		return results
+	}
+
+	logging.Infof("Installing the operators %s...", strings.Join(names, ", "))
+	waitForOperators := func() error {
+		var pending []string
+		for i := range results {
+			if results[i].Ready || results[i].Error != "" {
+				continue
+			}
+			ready, csv, err := operatorReady(ocConfig, results[i].Name)
+			results[i].CSV = csv
+			if err != nil || !ready {
+				pending = append(pending, results[i].Name)
+				continue
+			}
+			results[i].Ready = true
+			logging.Infof("Installed the %s operator (%s)", results[i].Name, csv)
+		}
+		if len(pending) > 0 {
+			return &crcerrors.RetriableError{Err: fmt.Errorf("the operators %s are not installed yet", strings.Join(pending, ", "))}
+		}
+		return nil
+	}
+	if err := crcerrors.Retry(ctx, operatorsInstallTimeout, waitForOperators, 10*time.Second); err != nil {
+		for i := range results {
+			if !results[i].Ready && results[i].Error == "" {
+				results[i].Error = fmt.Sprintf("The operator was not installed after %s", operatorsInstallTimeout)
+			}
+		}
+	}
+	return results
+}
+
+func getOperatorPackage(ocConfig oc.Config, name string) (operatorPackage, error) {
+	stdout, stderr, err := ocConfig.RunOcCommand("get", "packagemanifest", name, "-n", "openshift-marketplace",
+		"-o", `jsonpath="{.status.catalogSource} {.status.catalogSourceNamespace} {.status.defaultChannel}"`)
+	if err != nil {
+		return operatorPackage{}, fmt.Errorf("The operator %s is not in the catalogs of the cluster: %s", name, strings.TrimSpace(stderr))
+	}
+	fields := strings.Fields(stdout)
+	if len(fields) != 3 {
+		return operatorPackage{}, fmt.Errorf("Unexpected catalog information for the operator %s: %s", name, stdout)
+	}
+	return operatorPackage{
+		name:             name,
+		catalog:          fields[0],
+		catalogNamespace: fields[1],
+		channel:          fields[2],
+	}, nil
+}
+
+func subscribeOperators(ocConfig oc.Config, sshRunner *ssh.Runner, packages []operatorPackage) error {
+	manifestFileName := "/tmp/crc-operators.yaml"
+	if err := sshRunner.CopyData([]byte(subscriptionsManifest(packages)), manifestFileName, 0600); err != nil {
+		return err
+	}
+	defer func() {
+		_, _, _ = sshRunner.Run("rm", "-f", manifestFileName)
+	}()
+	if _, stderr, err := ocConfig.RunOcCommand("apply", "-f", manifestFileName); err != nil {
+		return fmt.Errorf("Failed to subscribe to the operators %v: %s", err, stderr)
+	}
+	return nil
+}
+
+func subscriptionsManifest(packages []operatorPackage) string {
+	var manifests []string
+	for _, pkg := range packages {
+		manifests = append(manifests, fmt.Sprintf(`apiVersion: operators.coreos.com/v1alpha1
+kind: Subscription
+metadata:
+  name: %s
+  namespace: %s
+spec:
+  name: %s
+  channel: %s
+  source: %s
+  sourceNamespace: %s
+  installPlanApproval: Automatic
+`, pkg.name, operatorsNamespace, pkg.name, pkg.channel, pkg.catalog, pkg.catalogNamespace))
+	}
+	return strings.Join(manifests, "---\n")
+}
+
+func operatorReady(ocConfig oc.Config, name string) (bool, string, error) {
+	csv, _, err := ocConfig.RunOcCommand("get", "subscription", name, "-n", operatorsNamespace, "-o", `jsonpath="{.status.installedCSV}"`)
+	if err != nil {
+		return false, "", err
+	}
+	csv = strings.TrimSpace(csv)
+	if csv == "" {
+		return false, "", nil
+	}
+	phase, _, err := ocConfig.RunOcCommand("get", "csv", csv, "-n", operatorsNamespace, "-o", `jsonpath="{.status.phase}"`)
+	if err != nil {
+		return false, csv, err
+	}
+	return strings.TrimSpace(phase) == "Succeeded", csv, nil
+}
@@ -0,0 +1,24 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscriptionsManifest(t *testing.T) {
+	assert.Equal(t, `apiVersion: operators.coreos.com/v1alpha1
+kind: Subscription
+metadata:
+  name: serverless-operator
+  namespace: openshift-operators
+spec:
+  name: serverless-operator
+  channel: stable
+  source: redhat-operators
+  sourceNamespace: openshift-marketplace
+  installPlanApproval: Automatic
+`, subscriptionsManifest([]operatorPackage{
+		{name: "serverless-operator", catalog: "redhat-operators", catalogNamespace: "openshift-marketplace", channel: "stable"},
+	}))
+}
@@ -36,6 +36,14 @@ type interactivePullSecretLoader struct {
 }
 
 func NewInteractivePullSecretLoader(config crcConfig.Storage) PullSecretLoader {
+	if config.Get(crcConfig.PullSecretFromKeychain).AsBool() {
+		return &PullSecretMemoizer{
+			Getter: &keyringPullSecretLoader{
+				config:      config,
+				interactive: true,
+			},
+		}
+	}
 	return &PullSecretMemoizer{
 		Getter: &interactivePullSecretLoader{
 			nonInteractivePullSecretLoader: &nonInteractivePullSecretLoader{
@@ -68,6 +76,14 @@ type nonInteractivePullSecretLoader struct {
 }
 
 func NewNonInteractivePullSecretLoader(config crcConfig.Storage, path string) PullSecretLoader {
+	if config.Get(crcConfig.PullSecretFromKeychain).AsBool() {
+		return &PullSecretMemoizer{
+			Getter: &keyringPullSecretLoader{
+				config: config,
+				path:   path,
+			},
+		}
+	}
 	return &PullSecretMemoizer{
 		Getter: &nonInteractivePullSecretLoader{
 			config: config,
@@ -107,6 +123,61 @@ func (loader *nonInteractivePullSecretLoader) Value() (string, error) {
 	return "", fmt.Errorf("unable to load pull secret from path %q or from configuration", loader.path)
 }
 
+// keyringPullSecretLoader only reads the pull secret from the OS credential
+// store (macOS Keychain, Windows Credential Manager or secret-service on
+// Linux). When it is not there yet, it is read from the file or asked to the
+// user, and then moved to the credential store.
+type keyringPullSecretLoader struct {
+	config      crcConfig.Storage
+	path        string
+	interactive bool
+}
+
+func (loader *keyringPullSecretLoader) Value() (string, error) {
+	if crcversion.IsOkdBuild() || crcConfig.GetPreset(loader.config) == preset.Podman {
+		return constants.OkdPullSecret, nil
+	}
+
+	fromKeyring, err := loadFromKeyring()
+	if err == nil {
+		logging.Debugf("Using secret from keyring")
+		return fromKeyring, nil
+	}
+	logging.Debugf("Cannot load secret from keyring: %v", err)
+
+	pullSecret, source, err := loader.loadFromFileOrUser()
+	if err != nil {
+		return "", err
+	}
+	if err := StoreInKeyring(pullSecret); err != nil {
+		return "", fmt.Errorf("Cannot add pull secret to the credential store: %w", err)
+	}
+	if source != "" {
+		logging.Infof("The pull secret from %s was added to the credential store, this file is no longer needed", source)
+	}
+	return pullSecret, nil
+}
+
+// loadFromFileOrUser returns the pull secret and the file it was read from,
+// which is empty if it was entered by the user
+func (loader *keyringPullSecretLoader) loadFromFileOrUser() (string, string, error) {
+	for _, path := range []string{loader.path, loader.config.Get(crcConfig.PullSecretFile).AsString()} {
+		if path == "" {
+			continue
+		}
+		fromPath, err := loadFile(path)
+		if err == nil {
+			return fromPath, path, nil
+		}
+		logging.Debugf("Cannot load secret from path %q: %v", path, err)
+	}
+	if !loader.interactive {
+		return "", "", errors.New("unable to load pull secret from the credential store, from path or from configuration")
+	}
+	pullSecret, err := promptUserForSecret()
+	return pullSecret, "", err
+}
+
 func loadFromKeyring() (string, error) {
 	pullsecret, err := keyring.Get(keyringService, keyringUser)
 	if err != nil {
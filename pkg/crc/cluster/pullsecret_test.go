@@ -58,3 +58,34 @@ func TestLoadPullSecret(t *testing.T) {
 
 	assert.Error(t, StoreInKeyring(secret4))
 }
+
+func TestLoadPullSecretFromKeychain(t *testing.T) {
+	keyring.MockInit()
+
+	dir, err := ioutil.TempDir("", "pull-secret")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := config.New(config.NewEmptyInMemoryStorage())
+	config.RegisterSettings(cfg)
+	_, err = cfg.Set(config.PullSecretFromKeychain, true)
+	assert.NoError(t, err)
+
+	_, err = NewNonInteractivePullSecretLoader(cfg, filepath.Join(dir, "file1")).Value()
+	assert.Error(t, err)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "file1"), []byte(secret1), 0600))
+	val, err := NewNonInteractivePullSecretLoader(cfg, filepath.Join(dir, "file1")).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, secret1, val)
+
+	val, err = loadFromKeyring()
+	assert.NoError(t, err)
+	assert.Equal(t, secret1, val)
+
+	// the credential store has precedence over the file once it is populated
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "file1"), []byte(secret2), 0600))
+	val, err = NewNonInteractivePullSecretLoader(cfg, filepath.Join(dir, "file1")).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, secret1, val)
+}
@@ -0,0 +1,232 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/docker/go-units"
+)
+
+const (
+	// PVPoolDir is where the persistent volumes are provisioned in the
+	// instance, it is the mount point of the pool filesystem
+	PVPoolDir = "/var/mnt/pv-pool"
+
+	// the pool is a sparse image, so it only uses the space of the volumes
+	// on the disk of the instance, and its size is the capacity of the pool
+	pvPoolImage = "/var/lib/crc-pv-pool.img"
+
+	pvProvisionerImage       = "docker.io/rancher/local-path-provisioner:v0.0.22"
+	pvProvisionerHelperImage = "docker.io/library/busybox:1.35"
+	pvStorageClass           = "crc-pv-pool"
+)
+
+// SetupPVPool creates the filesystem of the persistent volumes with a
+// capacity of sizeGiB and mounts it. It is grown when sizeGiB increased, it
+// cannot shrink. When sizeGiB is 0, an existing pool is still mounted so
+// that its volumes remain available.
+func SetupPVPool(sshRunner *ssh.Runner, sizeGiB int) error {
+	wantedSize := int64(sizeGiB) * units.GiB
+	grow := false
+	out, _, err := sshRunner.RunPrivileged("reading the size of the PV pool", "stat", "-c", "%s", pvPoolImage)
+	if err != nil {
+		if sizeGiB == 0 {
+			return nil
+		}
+		logging.Infof("Creating a %d GiB pool for the persistent volumes...", sizeGiB)
+		if _, stderr, err := sshRunner.RunPrivileged("creating the PV pool", "truncate", "-s", fmt.Sprintf("%dG", sizeGiB), pvPoolImage); err != nil {
+			return fmt.Errorf("Failed to create the PV pool %v: %s", err, stderr)
+		}
+		if _, stderr, err := sshRunner.RunPrivileged("formatting the PV pool", "mkfs.xfs", "-q", pvPoolImage); err != nil {
+			return fmt.Errorf("Failed to format the PV pool %v: %s", err, stderr)
+		}
+	} else {
+		currentSize, err := strconv.ParseInt(strings.TrimSpace(out), 10, 64)
+		if err != nil {
+			return fmt.Errorf("Unexpected size of the PV pool: %s", out)
+		}
+		switch {
+		case wantedSize > currentSize:
+			logging.Infof("Growing the pool of the persistent volumes to %d GiB...", sizeGiB)
+			if _, stderr, err := sshRunner.RunPrivileged("growing the PV pool", "truncate", "-s", fmt.Sprintf("%dG", sizeGiB), pvPoolImage); err != nil {
+				return fmt.Errorf("Failed to grow the PV pool %v: %s", err, stderr)
+			}
+			grow = true
+		case wantedSize != 0 && wantedSize < currentSize:
+			logging.Warnf("The pool of the persistent volumes cannot shrink, it keeps its size of %s", units.BytesSize(float64(currentSize)))
+		}
+	}
+
+	if _, _, err := sshRunner.RunPrivileged("creating the mount point of the PV pool", "mkdir", "-p", PVPoolDir); err != nil {
+		return err
+	}
+	if _, _, err := sshRunner.Run("mountpoint", "-q", PVPoolDir); err != nil {
+		// the label lets the pods write to their volumes
+		options := "loop,context=system_u:object_r:container_file_t:s0"
+		if _, stderr, err := sshRunner.RunPrivileged("mounting the PV pool", "mount", "-o", options, pvPoolImage, PVPoolDir); err != nil {
+			return fmt.Errorf("Failed to mount the PV pool %v: %s", err, stderr)
+		}
+	}
+	if grow {
+		if _, stderr, err := sshRunner.RunPrivileged("growing the filesystem of the PV pool", "xfs_growfs", PVPoolDir); err != nil {
+			return fmt.Errorf("Failed to grow the filesystem of the PV pool %v: %s", err, stderr)
+		}
+	}
+	return nil
+}
+
+// GetPVPoolUsage returns the capacity and the used space in bytes of the PV
+// pool, they are 0 when there is no pool
+func GetPVPoolUsage(sshRunner *ssh.Runner) (int64, int64, error) {
+	if _, _, err := sshRunner.Run("mountpoint", "-q", PVPoolDir); err != nil {
+		return 0, 0, nil
+	}
+	return getPartitionUsage(sshRunner, PVPoolDir)
+}
+
+// DeployPVProvisioner deploys a provisioner creating the persistent volumes
+// of the claims in the PV pool. Its storage class is the default one, so the
+// claims without storage class use it.
+func DeployPVProvisioner(ctx context.Context, ocConfig oc.Config, sshRunner *ssh.Runner) error {
+	logging.Info("Deploying the provisioner of the persistent volumes...")
+	if err := WaitForOpenshiftResource(ctx, ocConfig, "storageclasses"); err != nil {
+		return err
+	}
+	manifestFileName := "/tmp/crc-pv-provisioner.yaml"
+	if err := sshRunner.CopyData([]byte(pvProvisionerManifest()), manifestFileName, 0600); err != nil {
+		return err
+	}
+	defer func() {
+		_, _, _ = sshRunner.Run("rm", "-f", manifestFileName)
+	}()
+	if _, stderr, err := ocConfig.RunOcCommand("apply", "-f", manifestFileName); err != nil {
+		return fmt.Errorf("Failed to deploy the provisioner of the persistent volumes %v: %s", err, stderr)
+	}
+	return nil
+}
+
+func pvProvisionerManifest() string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: crc-storage
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: local-path-provisioner-service-account
+  namespace: crc-storage
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: crc-pv-provisioner
+rules:
+- apiGroups: [""]
+  resources: ["nodes", "persistentvolumeclaims", "configmaps"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: [""]
+  resources: ["endpoints", "persistentvolumes", "pods"]
+  verbs: ["*"]
+- apiGroups: [""]
+  resources: ["events"]
+  verbs: ["create", "patch"]
+- apiGroups: ["storage.k8s.io"]
+  resources: ["storageclasses"]
+  verbs: ["get", "list", "watch"]
+# the helper pods creating and removing the volumes mount the pool
+- apiGroups: ["security.openshift.io"]
+  resources: ["securitycontextconstraints"]
+  resourceNames: ["privileged"]
+  verbs: ["use"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: crc-pv-provisioner
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: crc-pv-provisioner
+subjects:
+- kind: ServiceAccount
+  name: local-path-provisioner-service-account
+  namespace: crc-storage
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: local-path-config
+  namespace: crc-storage
+data:
+  config.json: |-
+    {"nodePathMap": [{"node": "DEFAULT_PATH_FOR_NON_LISTED_NODES", "paths": ["%[1]s"]}]}
+  setup: |-
+    #!/bin/sh
+    set -eu
+    mkdir -m 0777 -p "$VOL_DIR"
+  teardown: |-
+    #!/bin/sh
+    set -eu
+    rm -rf "$VOL_DIR"
+  helperPod.yaml: |-
+    apiVersion: v1
+    kind: Pod
+    metadata:
+      name: helper-pod
+    spec:
+      containers:
+      - name: helper-pod
+        image: %[3]s
+        imagePullPolicy: IfNotPresent
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: local-path-provisioner
+  namespace: crc-storage
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: local-path-provisioner
+  template:
+    metadata:
+      labels:
+        app: local-path-provisioner
+    spec:
+      serviceAccountName: local-path-provisioner-service-account
+      containers:
+      - name: local-path-provisioner
+        image: %[2]s
+        imagePullPolicy: IfNotPresent
+        command: ["local-path-provisioner", "start", "--config", "/etc/config/config.json"]
+        env:
+        - name: POD_NAMESPACE
+          valueFrom:
+            fieldRef:
+              fieldPath: metadata.namespace
+        volumeMounts:
+        - name: config-volume
+          mountPath: /etc/config/
+      volumes:
+      - name: config-volume
+        configMap:
+          name: local-path-config
+---
+apiVersion: storage.k8s.io/v1
+kind: StorageClass
+metadata:
+  name: %[4]s
+  annotations:
+    storageclass.kubernetes.io/is-default-class: "true"
+provisioner: rancher.io/local-path
+volumeBindingMode: WaitForFirstConsumer
+reclaimPolicy: Delete
+`, PVPoolDir, pvProvisionerImage, pvProvisionerHelperImage, pvStorageClass)
+}
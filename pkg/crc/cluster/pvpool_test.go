@@ -0,0 +1,34 @@
+package cluster
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+func TestPVProvisionerManifest(t *testing.T) {
+	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(pvProvisionerManifest()), 4096)
+	var kinds []string
+	var storageClass map[string]interface{}
+	for {
+		var object map[string]interface{}
+		err := decoder.Decode(&object)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		kinds = append(kinds, object["kind"].(string))
+		if object["kind"] == "StorageClass" {
+			storageClass = object
+		}
+	}
+	assert.Equal(t, []string{"Namespace", "ServiceAccount", "ClusterRole", "ClusterRoleBinding", "ConfigMap", "Deployment", "StorageClass"}, kinds)
+	require.NotNil(t, storageClass)
+	assert.Equal(t, "rancher.io/local-path", storageClass["provisioner"])
+	assert.Equal(t, "true", storageClass["metadata"].(map[string]interface{})["annotations"].(map[string]interface{})["storageclass.kubernetes.io/is-default-class"])
+	assert.Contains(t, pvProvisionerManifest(), `"paths": ["/var/mnt/pv-pool"]`)
+}
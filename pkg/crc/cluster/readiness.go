@@ -0,0 +1,48 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/ssh"
+)
+
+// routerHealthURL is the readiness endpoint of the default router, which
+// runs on the host network of the instance
+const routerHealthURL = "http://localhost:1936/healthz/ready"
+
+// CheckAPIServerReady queries the readiness endpoint of the API server
+func CheckAPIServerReady(ctx context.Context, ip string, kubeconfigFilePath string) error {
+	client, err := kubernetesClient(ip, kubeconfigFilePath)
+	if err != nil {
+		return err
+	}
+	body, err := client.Discovery().RESTClient().Get().AbsPath("/readyz").DoRaw(ctx)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(body)) != "ok" {
+		return fmt.Errorf("API server is not ready: %s", body)
+	}
+	return nil
+}
+
+// CheckIngressReady checks that the default router is ready to serve the
+// routes of the cluster
+func CheckIngressReady(sshRunner *ssh.Runner) error {
+	if _, stderr, err := sshRunner.Run("curl", "--fail", "--silent", "--show-error", routerHealthURL); err != nil {
+		return fmt.Errorf("Router is not ready: %s", strings.TrimSpace(stderr))
+	}
+	return nil
+}
+
+// GetClusterOperatorsStatusByName returns the status of the given cluster
+// operators, or of all of them if names is empty
+func GetClusterOperatorsStatusByName(ctx context.Context, ip string, kubeconfigFilePath string, names []string) (*Status, error) {
+	lister, err := kubernetesClient(ip, kubeconfigFilePath)
+	if err != nil {
+		return nil, err
+	}
+	return getStatus(ctx, lister.ConfigV1().ClusterOperators(), names)
+}
@@ -0,0 +1,103 @@
+package cluster
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	crcerrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/oc"
+)
+
+const (
+	registryNamespace   = "openshift-image-registry"
+	registryDefaultHost = "default-route"
+)
+
+// ExposeRegistry enables the default route of the internal image registry
+// and returns its hostname once the route is available
+func ExposeRegistry(ctx context.Context, ocConfig oc.Config) (string, error) {
+	if err := WaitForOpenshiftResource(ctx, ocConfig, "configs.imageregistry.operator.openshift.io"); err != nil {
+		return "", err
+	}
+	_, stderr, err := ocConfig.RunOcCommand("patch", "configs.imageregistry.operator.openshift.io/cluster",
+		"--type", "merge", "--patch", `'{"spec":{"defaultRoute":true}}'`)
+	if err != nil {
+		return "", fmt.Errorf("Failed to enable the registry default route: %v: %s", err, stderr)
+	}
+
+	var host string
+	lookupRoute := func() error {
+		stdout, stderr, err := ocConfig.WithFailFast().RunOcCommand("get", "route", registryDefaultHost,
+			"-n", registryNamespace, "-o", `jsonpath="{.spec.host}"`)
+		if err != nil {
+			logging.Debug(stderr)
+			return &crcerrors.RetriableError{Err: err}
+		}
+		host = strings.Trim(strings.TrimSpace(stdout), `"`)
+		if host == "" {
+			return &crcerrors.RetriableError{Err: fmt.Errorf("registry route has no host yet")}
+		}
+		return nil
+	}
+	if err := crcerrors.Retry(ctx, 2*time.Minute, lookupRoute, 2*time.Second); err != nil {
+		return "", err
+	}
+	return host, nil
+}
+
+// GetRouterCA returns the PEM encoded CA used to sign the certificates of the
+// default ingress controller, and thus of the routes
+func GetRouterCA(ocConfig oc.Config) ([]byte, error) {
+	stdout, stderr, err := ocConfig.RunOcCommand("get", "secret", "router-ca", "-n", "openshift-ingress-operator",
+		"-o", `jsonpath="{.data.tls\.crt}"`)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get the router CA: %v: %s", err, stderr)
+	}
+	return base64.StdEncoding.DecodeString(strings.Trim(strings.TrimSpace(stdout), `"`))
+}
+
+const (
+	registryServiceAccount          = "crc-registry"
+	registryServiceAccountNamespace = "default"
+)
+
+// EnableRegistry makes sure the internal image registry is managed by its
+// operator, and creates a service account allowed to push images to it.
+// It returns the service account name and its token.
+func EnableRegistry(ctx context.Context, ocConfig oc.Config) (string, string, error) {
+	if err := WaitForOpenshiftResource(ctx, ocConfig, "configs.imageregistry.operator.openshift.io"); err != nil {
+		return "", "", err
+	}
+	if _, stderr, err := ocConfig.RunOcCommand("patch", "configs.imageregistry.operator.openshift.io/cluster",
+		"--type", "merge", "--patch", `'{"spec":{"managementState":"Managed"}}'`); err != nil {
+		return "", "", fmt.Errorf("Failed to enable the image registry: %v: %s", err, stderr)
+	}
+	if _, _, err := ocConfig.RunOcCommand("get", "serviceaccount", registryServiceAccount, "-n", registryServiceAccountNamespace); err != nil {
+		if _, stderr, err := ocConfig.RunOcCommand("create", "serviceaccount", registryServiceAccount, "-n", registryServiceAccountNamespace); err != nil {
+			return "", "", fmt.Errorf("Failed to create the %s service account: %v: %s", registryServiceAccount, err, stderr)
+		}
+	}
+	if _, stderr, err := ocConfig.RunOcCommand("adm", "policy", "add-cluster-role-to-user", "registry-editor",
+		"-z", registryServiceAccount, "-n", registryServiceAccountNamespace); err != nil {
+		return "", "", fmt.Errorf("Failed to allow %s to push images: %v: %s", registryServiceAccount, err, stderr)
+	}
+
+	var token string
+	getToken := func() error {
+		stdout, stderr, err := ocConfig.RunOcCommandPrivate("serviceaccounts", "get-token", registryServiceAccount, "-n", registryServiceAccountNamespace)
+		if err != nil {
+			logging.Debug(stderr)
+			return &crcerrors.RetriableError{Err: err}
+		}
+		token = strings.TrimSpace(stdout)
+		return nil
+	}
+	if err := crcerrors.Retry(ctx, time.Minute, getToken, 2*time.Second); err != nil {
+		return "", "", err
+	}
+	return registryServiceAccount, token, nil
+}
@@ -0,0 +1,137 @@
+package cluster
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	crcerrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+)
+
+// ServiceAccountNamespace is the namespace of the service accounts created by
+// 'crc kubeconfig --as'
+const ServiceAccountNamespace = "crc-service-accounts"
+
+// serviceAccountRoles maps the roles of 'crc kubeconfig --as' to the cluster
+// roles bound to their service accounts
+var serviceAccountRoles = map[string]string{
+	"developer": "edit",
+	"viewer":    "view",
+}
+
+// ServiceAccountRoles returns the roles which can be given to a service
+// account
+func ServiceAccountRoles() []string {
+	var roles []string
+	for role := range serviceAccountRoles {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+func ValidateServiceAccountRole(role string) error {
+	if _, ok := serviceAccountRoles[role]; !ok {
+		return fmt.Errorf("Invalid role '%s', supported roles are %s", role, strings.Join(ServiceAccountRoles(), ", "))
+	}
+	return nil
+}
+
+// ServiceAccountName returns the name of the service account of role
+func ServiceAccountName(role string) string {
+	return fmt.Sprintf("crc-%s", role)
+}
+
+// CreateServiceAccountToken creates the service account of role, bound to
+// its cluster role in all the namespaces, and returns a token of the service
+// account. The token is kept in a secret, it is valid until the secret or the
+// service account is deleted.
+func CreateServiceAccountToken(ctx context.Context, ocConfig oc.Config, sshRunner *ssh.Runner, role string) (string, error) {
+	if err := ValidateServiceAccountRole(role); err != nil {
+		return "", err
+	}
+	if err := WaitForOpenshiftResource(ctx, ocConfig, "serviceaccounts"); err != nil {
+		return "", err
+	}
+	manifestFileName := "/tmp/crc-service-account.yaml"
+	if err := sshRunner.CopyData([]byte(serviceAccountManifest(role)), manifestFileName, 0600); err != nil {
+		return "", err
+	}
+	defer func() {
+		_, _, _ = sshRunner.Run("rm", "-f", manifestFileName)
+	}()
+	if _, stderr, err := ocConfig.RunOcCommand("apply", "-f", manifestFileName); err != nil {
+		return "", fmt.Errorf("Failed to create the service account %s %v: %s", ServiceAccountName(role), err, stderr)
+	}
+
+	// the token controller fills the secret asynchronously
+	var token string
+	getToken := func() error {
+		stdout, stderr, err := ocConfig.WithFailFast().RunOcCommandPrivate("get", "secret", serviceAccountTokenSecret(role),
+			"-n", ServiceAccountNamespace, "-o", `jsonpath="{.data.token}"`)
+		if err != nil {
+			logging.Debug(stderr)
+			return &crcerrors.RetriableError{Err: err}
+		}
+		encoded := strings.Trim(strings.TrimSpace(stdout), `"`)
+		if encoded == "" {
+			return &crcerrors.RetriableError{Err: fmt.Errorf("the secret of the service account has no token yet")}
+		}
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return err
+		}
+		token = string(decoded)
+		return nil
+	}
+	if err := crcerrors.Retry(ctx, time.Minute, getToken, 2*time.Second); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func serviceAccountTokenSecret(role string) string {
+	return fmt.Sprintf("%s-token", ServiceAccountName(role))
+}
+
+func serviceAccountManifest(role string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Namespace
+metadata:
+  name: %[1]s
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: %[2]s
+  namespace: %[1]s
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: %[2]s-%[3]s
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: %[3]s
+subjects:
+- kind: ServiceAccount
+  name: %[2]s
+  namespace: %[1]s
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: %[4]s
+  namespace: %[1]s
+  annotations:
+    kubernetes.io/service-account.name: %[2]s
+type: kubernetes.io/service-account-token
+`, ServiceAccountNamespace, ServiceAccountName(role), serviceAccountRoles[role], serviceAccountTokenSecret(role))
+}
@@ -0,0 +1,38 @@
+package cluster
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+func TestServiceAccountManifest(t *testing.T) {
+	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(serviceAccountManifest("viewer")), 4096)
+	var kinds []string
+	var binding map[string]interface{}
+	for {
+		var object map[string]interface{}
+		err := decoder.Decode(&object)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		kinds = append(kinds, object["kind"].(string))
+		if object["kind"] == "ClusterRoleBinding" {
+			binding = object
+		}
+	}
+	assert.Equal(t, []string{"Namespace", "ServiceAccount", "ClusterRoleBinding", "Secret"}, kinds)
+	require.NotNil(t, binding)
+	assert.Equal(t, "view", binding["roleRef"].(map[string]interface{})["name"])
+}
+
+func TestValidateServiceAccountRole(t *testing.T) {
+	assert.NoError(t, ValidateServiceAccountRole("developer"))
+	assert.NoError(t, ValidateServiceAccountRole("viewer"))
+	assert.EqualError(t, ValidateServiceAccountRole("admin"), "Invalid role 'admin', supported roles are developer, viewer")
+}
@@ -0,0 +1,44 @@
+package cluster
+
+import (
+	"errors"
+
+	crcos "github.com/code-ready/crc/pkg/os"
+
+	"github.com/AlecAivazis/survey/v2"
+	"github.com/zalando/go-keyring"
+)
+
+// sharedDirPasswordKeyringUser is the entry of the OS credential store with
+// the password of the current user, which the instance uses to mount the
+// directories shared over SMB on Windows
+const sharedDirPasswordKeyringUser = "shared-dir-password"
+
+func LoadSharedDirPassword() (string, error) {
+	return keyring.Get(keyringService, sharedDirPasswordKeyringUser)
+}
+
+func StoreSharedDirPassword(password string) error {
+	return keyring.Set(keyringService, sharedDirPasswordKeyringUser, password)
+}
+
+func ForgetSharedDirPassword() error {
+	_ = keyring.Delete(keyringService, sharedDirPasswordKeyringUser)
+	return nil
+}
+
+// PromptSharedDirPassword asks the password of the current user, to mount the
+// shared directories over SMB
+func PromptSharedDirPassword() (string, error) {
+	if !crcos.RunningInTerminal() {
+		return "", errors.New("cannot ask for the password, crc not launched by a terminal")
+	}
+	var password string
+	prompt := &survey.Password{
+		Message: "Please enter the password of the current user, the instance uses it to mount the shared directories",
+	}
+	if err := survey.AskOne(prompt, &password, survey.WithValidator(survey.Required)); err != nil {
+		return "", err
+	}
+	return password, nil
+}
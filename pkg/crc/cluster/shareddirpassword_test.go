@@ -0,0 +1,24 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zalando/go-keyring"
+)
+
+func TestSharedDirPassword(t *testing.T) {
+	keyring.MockInit()
+
+	_, err := LoadSharedDirPassword()
+	assert.Error(t, err)
+
+	assert.NoError(t, StoreSharedDirPassword("secret"))
+	password, err := LoadSharedDirPassword()
+	assert.NoError(t, err)
+	assert.Equal(t, "secret", password)
+
+	assert.NoError(t, ForgetSharedDirPassword())
+	_, err = LoadSharedDirPassword()
+	assert.Error(t, err)
+}
@@ -9,28 +9,38 @@ import (
 	"github.com/code-ready/crc/pkg/crc/network"
 )
 
+// DefaultOperatorsTimeout is how long WaitForClusterStable waits by default,
+// it waits 5 more minutes when a proxy is used
+const DefaultOperatorsTimeout = 10 * time.Minute
+
 // WaitForClusterStable checks that the cluster is running a number of consecutive times
-func WaitForClusterStable(ctx context.Context, ip string, kubeconfigFilePath string, proxy *network.ProxyConfig) error {
+// WaitForClusterStable waits until the cluster operators are ready, it
+// returns the operators which were the last not to be ready. It waits at most
+// timeout, or DefaultOperatorsTimeout when timeout is 0.
+func WaitForClusterStable(ctx context.Context, ip string, kubeconfigFilePath string, proxy *network.ProxyConfig, timeout time.Duration) ([]string, error) {
 	if ctx.Err() != nil {
-		return ctx.Err()
+		return nil, ctx.Err()
 	}
 
 	startTime := time.Now()
 
 	retryDuration := 30 * time.Second
-	retryCount := 20 // 10 minutes
 
-	if proxy.IsEnabled() {
-		// In case proxy is enabled increase the retry count
-		// to 10 and this will add addition 5 mins.
-		retryCount += 10
+	if timeout == 0 {
+		timeout = DefaultOperatorsTimeout
+		if proxy.IsEnabled() {
+			timeout += 5 * time.Minute
+		}
 	}
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
 	numConsecutive := 3
 	var count int // holds num of consecutive matches
+	var lastNotReady []string
 
-	for i := 0; i < retryCount; i++ {
-		status, err := GetClusterOperatorsStatus(ctx, ip, kubeconfigFilePath)
+	for {
+		status, err := GetClusterOperatorsStatus(waitCtx, ip, kubeconfigFilePath)
 		if err == nil {
 			// update counter for consecutive matches
 			if status.IsReady() {
@@ -42,22 +52,24 @@ func WaitForClusterStable(ctx context.Context, ip string, kubeconfigFilePath str
 				}
 			} else {
 				logging.Info(status.String())
+				lastNotReady = status.NotReadyOperators()
 				count = 0
 			}
 			// break if done
 			if count == numConsecutive {
 				logging.Debugf("Cluster took %s to stabilize", time.Since(startTime))
-				return nil
+				return lastNotReady, nil
 			}
 		} else {
 			count = 0
 		}
 		select {
-		case <-ctx.Done():
-			return ctx.Err()
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("cluster operators are still not stable after %s", time.Since(startTime).Round(time.Second))
 		case <-time.After(retryDuration):
 		}
 	}
-
-	return fmt.Errorf("cluster operators are still not stable after %s", time.Since(startTime))
 }
@@ -0,0 +1,129 @@
+package cluster
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// challengingClientID is the OAuth client used by 'oc login' to get a token
+// with basic authentication
+const challengingClientID = "openshift-challenging-client"
+
+type oauthMetadata struct {
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+}
+
+// RequestBearerToken logs in the OpenShift cluster with the given credentials
+// and returns the resulting bearer token, in the same way as 'oc login -u -p'.
+// The OAuth server is reached with the host port forwarded to the https port
+// of the router, ingressHTTPSPort, its URL uses the default port.
+func RequestBearerToken(ctx context.Context, apiURL string, ingressHTTPSPort int, caCert []byte, username, password string) (string, error) {
+	client, err := oauthClient(caCert)
+	if err != nil {
+		return "", err
+	}
+	authorizationEndpoint, err := discoverAuthorizationEndpoint(ctx, client, apiURL)
+	if err != nil {
+		return "", err
+	}
+
+	authorizeURL, err := url.Parse(authorizationEndpoint)
+	if err != nil {
+		return "", err
+	}
+	if authorizeURL.Port() == "" && ingressHTTPSPort != 443 {
+		authorizeURL.Host = net.JoinHostPort(authorizeURL.Hostname(), strconv.Itoa(ingressHTTPSPort))
+	}
+	query := authorizeURL.Query()
+	query.Set("response_type", "token")
+	query.Set("client_id", challengingClientID)
+	authorizeURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, authorizeURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.SetBasicAuth(username, password)
+	req.Header.Set("X-CSRF-Token", "1")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusFound:
+	case http.StatusUnauthorized:
+		return "", fmt.Errorf("Login failed for user %s, invalid credentials", username)
+	default:
+		return "", fmt.Errorf("Unexpected response from the OAuth server: %s", resp.Status)
+	}
+	location, err := resp.Location()
+	if err != nil {
+		return "", err
+	}
+	fragment, err := url.ParseQuery(location.Fragment)
+	if err != nil {
+		return "", err
+	}
+	if errorCode := fragment.Get("error"); errorCode != "" {
+		return "", fmt.Errorf("Login failed for user %s: %s", username, errorCode)
+	}
+	token := fragment.Get("access_token")
+	if token == "" {
+		return "", errors.New("No access token in the OAuth server response")
+	}
+	return token, nil
+}
+
+func oauthClient(caCert []byte) (*http.Client, error) {
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(caCert) {
+		return nil, errors.New("Cannot load the cluster CA certificate")
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			// the cluster is local, the proxy settings must not be used
+			Proxy: nil,
+			TLSClientConfig: &tls.Config{
+				RootCAs:    certPool,
+				MinVersion: tls.VersionTLS12,
+			},
+		},
+		// the token is in the URL fragment of the redirection
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}, nil
+}
+
+func discoverAuthorizationEndpoint(ctx context.Context, client *http.Client, apiURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL+"/.well-known/oauth-authorization-server", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Cannot get the OAuth server metadata: %s", resp.Status)
+	}
+	var metadata oauthMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return "", err
+	}
+	if metadata.AuthorizationEndpoint == "" {
+		return "", errors.New("No authorization endpoint in the OAuth server metadata")
+	}
+	return metadata.AuthorizationEndpoint, nil
+}
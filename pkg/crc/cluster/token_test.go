@@ -0,0 +1,54 @@
+package cluster
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newOAuthServer(t *testing.T) (*httptest.Server, []byte) {
+	mux := http.NewServeMux()
+	server := httptest.NewTLSServer(mux)
+	t.Cleanup(server.Close)
+
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"issuer":"%[1]s","authorization_endpoint":"%[1]s/oauth/authorize"}`, server.URL)
+	})
+	mux.HandleFunc("/oauth/authorize", func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "kubeadmin" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("client_id") != challengingClientID || r.Header.Get("X-CSRF-Token") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Location", server.URL+"/oauth/token/implicit#access_token=sha256~token&expires_in=86400&token_type=Bearer")
+		w.WriteHeader(http.StatusFound)
+	})
+
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	return server, caCert
+}
+
+func TestRequestBearerToken(t *testing.T) {
+	server, caCert := newOAuthServer(t)
+
+	token, err := RequestBearerToken(context.Background(), server.URL, 443, caCert, "kubeadmin", "secret")
+	require.NoError(t, err)
+	assert.Equal(t, "sha256~token", token)
+}
+
+func TestRequestBearerTokenInvalidCredentials(t *testing.T) {
+	server, caCert := newOAuthServer(t)
+
+	_, err := RequestBearerToken(context.Background(), server.URL, 443, caCert, "kubeadmin", "wrong")
+	assert.EqualError(t, err, "Login failed for user kubeadmin, invalid credentials")
+}
@@ -0,0 +1,158 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/ssh"
+)
+
+const etcdDataDir = "/var/lib/etcd"
+
+// ComponentUsage is the resource usage of a control plane component or of
+// a pod, summed over its containers
+type ComponentUsage struct {
+	Name      string
+	Namespace string
+	// CPU is in cores
+	CPU float64
+	// Memory is the working set in bytes
+	Memory int64
+	// Disk is the size in bytes of the writable layers of the containers,
+	// and of the data directory for etcd
+	Disk int64
+}
+
+// uint64Value is a value of the stats of crictl, the 64 bits integers are
+// quoted in its JSON output
+type uint64Value struct {
+	Value uint64 `json:"value"`
+}
+
+func (v *uint64Value) UnmarshalJSON(data []byte) error {
+	var value struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	parsed, err := strconv.ParseUint(strings.Trim(string(value.Value), `"`), 10, 64)
+	if err != nil {
+		return err
+	}
+	v.Value = parsed
+	return nil
+}
+
+type containerStats struct {
+	Attributes struct {
+		Labels map[string]string `json:"labels"`
+	} `json:"attributes"`
+	CPU struct {
+		UsageNanoCores *uint64Value `json:"usageNanoCores"`
+	} `json:"cpu"`
+	Memory struct {
+		WorkingSetBytes *uint64Value `json:"workingSetBytes"`
+	} `json:"memory"`
+	WritableLayer struct {
+		UsedBytes *uint64Value `json:"usedBytes"`
+	} `json:"writableLayer"`
+}
+
+type crictlStats struct {
+	Stats []containerStats `json:"stats"`
+}
+
+// GetComponentsUsage returns the usage of kube-apiserver and etcd, followed
+// by the topPods pods using the most memory
+func GetComponentsUsage(sshRunner *ssh.Runner, topPods int) ([]ComponentUsage, error) {
+	stdout, stderr, err := sshRunner.RunPrivileged("getting the resource usage of the containers", "crictl", "stats", "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get the resource usage of the containers %v: %s", err, stderr)
+	}
+	var stats crictlStats
+	if err := json.Unmarshal([]byte(stdout), &stats); err != nil {
+		return nil, err
+	}
+	components := componentsUsage(stats, topPods)
+	for i := range components {
+		if components[i].Name != "etcd" || components[i].Namespace != "" {
+			continue
+		}
+		stdout, _, err := sshRunner.RunPrivileged("getting the size of the etcd data", "du", "-sb", etcdDataDir)
+		if err != nil {
+			break
+		}
+		if fields := strings.Fields(stdout); len(fields) > 0 {
+			if size, err := strconv.ParseInt(fields[0], 10, 64); err == nil {
+				components[i].Disk += size
+			}
+		}
+	}
+	return components, nil
+}
+
+// controlPlaneComponent returns the name of the component run by the pod,
+// or an empty string for the other pods
+func controlPlaneComponent(namespace, pod string) string {
+	switch {
+	case namespace == "openshift-kube-apiserver" && strings.HasPrefix(pod, "kube-apiserver-") && !strings.HasPrefix(pod, "kube-apiserver-guard-"):
+		return "kube-apiserver"
+	case namespace == "openshift-etcd" && strings.HasPrefix(pod, "etcd-") && !strings.HasPrefix(pod, "etcd-guard-"):
+		return "etcd"
+	}
+	return ""
+}
+
+func componentsUsage(stats crictlStats, topPods int) []ComponentUsage {
+	pods := make(map[string]*ComponentUsage)
+	var order []string
+	for _, container := range stats.Stats {
+		namespace := container.Attributes.Labels["io.kubernetes.pod.namespace"]
+		name := container.Attributes.Labels["io.kubernetes.pod.name"]
+		if name == "" {
+			continue
+		}
+		key := namespace + "/" + name
+		pod, ok := pods[key]
+		if !ok {
+			pod = &ComponentUsage{Name: name, Namespace: namespace}
+			pods[key] = pod
+			order = append(order, key)
+		}
+		if container.CPU.UsageNanoCores != nil {
+			pod.CPU += float64(container.CPU.UsageNanoCores.Value) / 1e9
+		}
+		if container.Memory.WorkingSetBytes != nil {
+			pod.Memory += int64(container.Memory.WorkingSetBytes.Value)
+		}
+		if container.WritableLayer.UsedBytes != nil {
+			pod.Disk += int64(container.WritableLayer.UsedBytes.Value)
+		}
+	}
+
+	var components, others []ComponentUsage
+	for _, component := range []string{"kube-apiserver", "etcd"} {
+		for _, key := range order {
+			pod := pods[key]
+			if controlPlaneComponent(pod.Namespace, pod.Name) == component {
+				components = append(components, ComponentUsage{Name: component, CPU: pod.CPU, Memory: pod.Memory, Disk: pod.Disk})
+			}
+		}
+	}
+	for _, key := range order {
+		if pod := pods[key]; controlPlaneComponent(pod.Namespace, pod.Name) == "" {
+			others = append(others, *pod)
+		}
+	}
+	sort.SliceStable(others, func(i, j int) bool {
+		return others[i].Memory > others[j].Memory
+	})
+	if len(others) > topPods {
+		others = others[:topPods]
+	}
+	return append(components, others...)
+}
@@ -0,0 +1,56 @@
+package cluster
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const crictlStatsOutput = `{
+  "stats": [
+    {
+      "attributes": {"labels": {"io.kubernetes.pod.name": "etcd-crc-dzk9v-master-0", "io.kubernetes.pod.namespace": "openshift-etcd"}},
+      "cpu": {"usageNanoCores": {"value": "150000000"}},
+      "memory": {"workingSetBytes": {"value": "300000000"}},
+      "writableLayer": {"usedBytes": {"value": "4096"}}
+    },
+    {
+      "attributes": {"labels": {"io.kubernetes.pod.name": "etcd-crc-dzk9v-master-0", "io.kubernetes.pod.namespace": "openshift-etcd"}},
+      "cpu": {"usageNanoCores": {"value": "50000000"}},
+      "memory": {"workingSetBytes": {"value": "20000000"}},
+      "writableLayer": {"usedBytes": {"value": "4096"}}
+    },
+    {
+      "attributes": {"labels": {"io.kubernetes.pod.name": "kube-apiserver-crc-dzk9v-master-0", "io.kubernetes.pod.namespace": "openshift-kube-apiserver"}},
+      "cpu": {"usageNanoCores": {"value": "400000000"}},
+      "memory": {"workingSetBytes": {"value": "1200000000"}}
+    },
+    {
+      "attributes": {"labels": {"io.kubernetes.pod.name": "kube-apiserver-guard-crc-dzk9v-master-0", "io.kubernetes.pod.namespace": "openshift-kube-apiserver"}},
+      "memory": {"workingSetBytes": {"value": "1000000"}}
+    },
+    {
+      "attributes": {"labels": {"io.kubernetes.pod.name": "prometheus-k8s-0", "io.kubernetes.pod.namespace": "openshift-monitoring"}},
+      "cpu": {"usageNanoCores": {"value": "100000000"}},
+      "memory": {"workingSetBytes": {"value": "800000000"}}
+    },
+    {
+      "attributes": {"labels": {"io.kubernetes.pod.name": "router-default-1", "io.kubernetes.pod.namespace": "openshift-ingress"}},
+      "memory": {"workingSetBytes": {"value": 90000000}}
+    }
+  ]
+}`
+
+func TestComponentsUsage(t *testing.T) {
+	var stats crictlStats
+	require.NoError(t, json.Unmarshal([]byte(crictlStatsOutput), &stats))
+
+	assert.Equal(t, []ComponentUsage{
+		{Name: "kube-apiserver", CPU: 0.4, Memory: 1200000000},
+		{Name: "etcd", CPU: 0.2, Memory: 320000000, Disk: 8192},
+		{Name: "prometheus-k8s-0", Namespace: "openshift-monitoring", CPU: 0.1, Memory: 800000000},
+		{Name: "router-default-1", Namespace: "openshift-ingress", Memory: 90000000},
+	}, componentsUsage(stats, 2))
+}
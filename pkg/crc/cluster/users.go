@@ -0,0 +1,272 @@
+package cluster
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/oc"
+)
+
+// User is a user of the htpasswd identity provider added with 'crc users'
+type User struct {
+	Name         string `json:"name"`
+	PasswordHash string `json:"passwordHash"`
+	ClusterAdmin bool   `json:"clusterAdmin"`
+}
+
+var validUserName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._@-]*$`)
+
+// UserStore keeps the users in the machine directory, so that they are
+// applied again to the cluster on every start
+type UserStore struct {
+	path string
+}
+
+func NewUserStore(path string) *UserStore {
+	return &UserStore{path: path}
+}
+
+func (s *UserStore) List() ([]User, error) {
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var users []User
+	if err := json.Unmarshal(data, &users); err != nil {
+		return nil, fmt.Errorf("Invalid %s: %w", s.path, err)
+	}
+	return users, nil
+}
+
+func (s *UserStore) save(users []User) error {
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Name < users[j].Name
+	})
+	data, err := json.MarshalIndent(users, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.path, data, 0600)
+}
+
+// update calls fn with the user, it fails when the user does not exist
+func (s *UserStore) update(name string, fn func(user *User) error) error {
+	users, err := s.List()
+	if err != nil {
+		return err
+	}
+	for i := range users {
+		if users[i].Name == name {
+			if err := fn(&users[i]); err != nil {
+				return err
+			}
+			return s.save(users)
+		}
+	}
+	return fmt.Errorf("User %s does not exist", name)
+}
+
+func (s *UserStore) Add(name, password string, clusterAdmin bool) error {
+	if err := ValidateUserName(name); err != nil {
+		return err
+	}
+	hash, err := hashUserPassword(password)
+	if err != nil {
+		return err
+	}
+	users, err := s.List()
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if user.Name == name {
+			return fmt.Errorf("User %s already exists", name)
+		}
+	}
+	users = append(users, User{Name: name, PasswordHash: hash, ClusterAdmin: clusterAdmin})
+	return s.save(users)
+}
+
+func (s *UserStore) Remove(name string) error {
+	users, err := s.List()
+	if err != nil {
+		return err
+	}
+	for i, user := range users {
+		if user.Name == name {
+			return s.save(append(users[:i], users[i+1:]...))
+		}
+	}
+	return fmt.Errorf("User %s does not exist", name)
+}
+
+func (s *UserStore) SetPassword(name, password string) error {
+	hash, err := hashUserPassword(password)
+	if err != nil {
+		return err
+	}
+	return s.update(name, func(user *User) error {
+		user.PasswordHash = hash
+		return nil
+	})
+}
+
+func (s *UserStore) SetClusterAdmin(name string, clusterAdmin bool) error {
+	return s.update(name, func(user *User) error {
+		user.ClusterAdmin = clusterAdmin
+		return nil
+	})
+}
+
+// ValidateUserName rejects the names which cannot be in an htpasswd file,
+// and the users managed by crc
+func ValidateUserName(name string) error {
+	if name == "developer" || name == "kubeadmin" {
+		return fmt.Errorf("The %s user is managed by crc", name)
+	}
+	if !validUserName.MatchString(name) {
+		return fmt.Errorf("Invalid user name '%s', it must start with a letter or a digit, followed by letters, digits, '.', '_', '@' or '-'", name)
+	}
+	return nil
+}
+
+func hashUserPassword(password string) (string, error) {
+	if password == "" || strings.ContainsAny(password, "\r\n") {
+		return "", fmt.Errorf("The password must not be empty and must fit on one line")
+	}
+	return hashBcrypt(password)
+}
+
+// ApplyUsers adds the users to the htpasswd identity provider of the
+// cluster, and removes the users of removed. The developer and kubeadmin
+// users, and the users which were not added with 'crc users', are kept.
+// The cluster-admin role is bound to the users of the store which have it,
+// and unbound from the others.
+func ApplyUsers(ctx context.Context, ocConfig oc.Config, users []User, removed ...string) error {
+	if err := WaitForOpenshiftResource(ctx, ocConfig, "secret"); err != nil {
+		return err
+	}
+	given, err := GetHtpasswd(ocConfig)
+	if err != nil {
+		return err
+	}
+	expected, changed, err := mergeHtpasswd(given, users, removed)
+	if err != nil {
+		return err
+	}
+	if changed {
+		logging.Info("Updating the users of the cluster...")
+		cmdArgs := []string{"patch", "secret", "htpass-secret", "-p",
+			fmt.Sprintf(`'{"data":{"htpasswd":"%s"}}'`, expected),
+			"-n", "openshift-config", "--type", "merge"}
+		if _, stderr, err := ocConfig.RunOcCommandPrivate(cmdArgs...); err != nil {
+			return fmt.Errorf("Failed to update the users %v: %s", err, stderr)
+		}
+	}
+
+	for _, user := range users {
+		if err := setClusterAdmin(ocConfig, user.Name, user.ClusterAdmin); err != nil {
+			return err
+		}
+	}
+	for _, name := range removed {
+		if err := setClusterAdmin(ocConfig, name, false); err != nil {
+			return err
+		}
+		if err := deleteUser(ocConfig, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeHtpasswd returns the base64 encoded htpasswd file with the users
+// replaced or added, and without the removed users
+func mergeHtpasswd(given string, users []User, removed []string) (string, bool, error) {
+	decoded, err := base64.StdEncoding.DecodeString(given)
+	if err != nil {
+		return "", false, err
+	}
+	hashes := make(map[string]string)
+	for _, user := range users {
+		hashes[user.Name] = user.PasswordHash
+	}
+	for _, name := range removed {
+		hashes[name] = ""
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(decoded), "\n") {
+		i := strings.Index(line, ":")
+		if i == -1 {
+			continue
+		}
+		username := line[:i]
+		hash, managed := hashes[username]
+		if !managed {
+			lines = append(lines, line)
+			continue
+		}
+		delete(hashes, username)
+		if hash != "" {
+			lines = append(lines, fmt.Sprintf("%s:%s", username, hash))
+		}
+	}
+	for _, user := range users {
+		if _, missing := hashes[user.Name]; missing {
+			lines = append(lines, fmt.Sprintf("%s:%s", user.Name, user.PasswordHash))
+		}
+	}
+	expected := strings.Join(lines, "\n")
+	return base64.StdEncoding.EncodeToString([]byte(expected)), expected != strings.TrimRight(string(decoded), "\n"), nil
+}
+
+func clusterAdminBinding(name string) string {
+	return fmt.Sprintf("crc-cluster-admin-%s", strings.ToLower(strings.NewReplacer("@", "-at-", "_", "-").Replace(name)))
+}
+
+func setClusterAdmin(ocConfig oc.Config, name string, clusterAdmin bool) error {
+	binding := clusterAdminBinding(name)
+	if !clusterAdmin {
+		if _, stderr, err := ocConfig.RunOcCommand("delete", "clusterrolebinding", binding, "--ignore-not-found"); err != nil {
+			return fmt.Errorf("Failed to remove the cluster-admin role of %s %v: %s", name, err, stderr)
+		}
+		return nil
+	}
+	if _, _, err := ocConfig.RunOcCommand("get", "clusterrolebinding", binding); err == nil {
+		return nil
+	}
+	if _, stderr, err := ocConfig.RunOcCommand("create", "clusterrolebinding", binding, "--clusterrole=cluster-admin", "--user="+name); err != nil {
+		return fmt.Errorf("Failed to grant the cluster-admin role to %s %v: %s", name, err, stderr)
+	}
+	return nil
+}
+
+// deleteUser removes the user and its identities, so that a new user with
+// the same name does not inherit them
+func deleteUser(ocConfig oc.Config, name string) error {
+	identities, _, err := ocConfig.RunOcCommand("get", "user", name, "-o", `jsonpath="{.identities[*]}"`, "--ignore-not-found")
+	if err != nil {
+		return err
+	}
+	for _, identity := range strings.Fields(identities) {
+		if _, stderr, err := ocConfig.RunOcCommand("delete", "identity", identity, "--ignore-not-found"); err != nil {
+			return fmt.Errorf("Failed to remove the identity %s %v: %s", identity, err, stderr)
+		}
+	}
+	if _, stderr, err := ocConfig.RunOcCommand("delete", "user", name, "--ignore-not-found"); err != nil {
+		return fmt.Errorf("Failed to remove the user %s %v: %s", name, err, stderr)
+	}
+	return nil
+}
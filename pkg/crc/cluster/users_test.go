@@ -0,0 +1,73 @@
+package cluster
+
+import (
+	"encoding/base64"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestUserStore(t *testing.T) {
+	store := NewUserStore(filepath.Join(t.TempDir(), "users.json"))
+
+	users, err := store.List()
+	assert.NoError(t, err)
+	assert.Empty(t, users)
+
+	assert.NoError(t, store.Add("bob", "secret", false))
+	assert.NoError(t, store.Add("alice", "secret", true))
+	assert.EqualError(t, store.Add("alice", "other", false), "User alice already exists")
+	assert.Error(t, store.Add("kubeadmin", "secret", false))
+	assert.Error(t, store.Add("-alice", "secret", false))
+	assert.Error(t, store.Add("carol", "", false))
+
+	users, err = store.List()
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, "alice", users[0].Name)
+	assert.True(t, users[0].ClusterAdmin)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(users[0].PasswordHash), []byte("secret")))
+
+	assert.NoError(t, store.SetPassword("alice", "changed"))
+	assert.NoError(t, store.SetClusterAdmin("alice", false))
+	assert.EqualError(t, store.SetClusterAdmin("carol", true), "User carol does not exist")
+	users, err = store.List()
+	require.NoError(t, err)
+	assert.False(t, users[0].ClusterAdmin)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(users[0].PasswordHash), []byte("changed")))
+
+	assert.NoError(t, store.Remove("alice"))
+	assert.Error(t, store.Remove("alice"))
+	users, err = store.List()
+	require.NoError(t, err)
+	assert.Equal(t, []User{{Name: "bob", PasswordHash: users[0].PasswordHash}}, users)
+}
+
+func TestMergeHtpasswd(t *testing.T) {
+	given := base64.StdEncoding.EncodeToString([]byte("developer:dev\nkubeadmin:admin\nexternal:ext\nalice:old\nbob:bob\n"))
+
+	merged, changed, err := mergeHtpasswd(given, []User{
+		{Name: "alice", PasswordHash: "new"},
+		{Name: "carol", PasswordHash: "carol"},
+	}, []string{"bob"})
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	decoded, err := base64.StdEncoding.DecodeString(merged)
+	assert.NoError(t, err)
+	assert.Equal(t, "developer:dev\nkubeadmin:admin\nexternal:ext\nalice:new\ncarol:carol", string(decoded))
+
+	_, changed, err = mergeHtpasswd(merged, []User{
+		{Name: "alice", PasswordHash: "new"},
+		{Name: "carol", PasswordHash: "carol"},
+	}, nil)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestClusterAdminBinding(t *testing.T) {
+	assert.Equal(t, "crc-cluster-admin-alice", clusterAdminBinding("alice"))
+	assert.Equal(t, "crc-cluster-admin-alice-at-example.com", clusterAdminBinding("Alice@example.com"))
+}
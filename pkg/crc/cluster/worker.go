@@ -0,0 +1,157 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	crcerrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/code-ready/crc/pkg/crc/systemd"
+)
+
+const (
+	workerMarkerPath        = "/var/lib/crc-worker"
+	workerNetworkScriptPath = "/usr/local/bin/crc-worker-network.sh"
+	workerNetworkService    = "crc-worker-network.service"
+	workerKubeletDropInPath = "/etc/systemd/system/kubelet.service.d/30-crc-worker.conf"
+
+	kubeletServingSignerName = "kubernetes.io/kubelet-serving"
+	kubeletClientSignerName  = "kubernetes.io/kube-apiserver-client-kubelet"
+)
+
+// The bundle disk image contains the control plane node, its internal IP is
+// the address of the API server in the cluster. On a worker, this address is
+// routed to the control plane node instead of being local.
+const workerNetworkScript = `#!/bin/sh
+ip -o -4 address show to %[1]s | awk '{print $2, $4}' | while read dev addr; do
+	ip address del "$addr" dev "$dev"
+done
+ip route replace %[1]s/32 via %[2]s
+`
+
+const workerNetworkUnit = `[Unit]
+Description=Route the CodeReady Containers control plane internal IP
+After=network-online.target
+Before=kubelet.service
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=/bin/sh ` + workerNetworkScriptPath + `
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// WorkerNode describes a worker node created from the bundle disk image
+type WorkerNode struct {
+	Hostname string
+	IP       string
+	// ControlPlaneIP is the IP of the control plane instance
+	ControlPlaneIP string
+	// ControlPlaneInternalIP is the address of the API server in the cluster
+	ControlPlaneInternalIP string
+}
+
+// ConvertToWorker turns an instance booted from the bundle disk image, which
+// contains a control plane node, into a worker node. The kubelet identity is
+// removed so that it joins the cluster with the bootstrap credentials. This
+// is only done once, on the first start of the instance.
+func ConvertToWorker(sshRunner *ssh.Runner, worker WorkerNode) error {
+	if _, _, err := sshRunner.Run("test", "-f", workerMarkerPath); err == nil {
+		logging.Debugf("%s is already configured as a worker node", worker.Hostname)
+		return nil
+	}
+
+	logging.Infof("Configuring %s as a worker node...", worker.Hostname)
+	sd := systemd.NewInstanceSystemdCommander(sshRunner)
+	if err := sd.Stop("kubelet"); err != nil {
+		return err
+	}
+	if _, _, err := sshRunner.RunPrivileged("Removing the control plane pods", "crictl", "rmp", "--force", "--all"); err != nil {
+		return err
+	}
+	if _, _, err := sshRunner.RunPrivileged("Removing the control plane static pods", "sh", "-c", "'rm -f /etc/kubernetes/manifests/*'"); err != nil {
+		return err
+	}
+	if _, _, err := sshRunner.RunPrivileged("Removing the kubelet identity", "rm", "-rf", "/var/lib/kubelet/pki", "/var/lib/kubelet/kubeconfig", "/var/lib/etcd/member"); err != nil {
+		return err
+	}
+	if _, _, err := sshRunner.RunPrivileged("Setting the hostname", "hostnamectl", "set-hostname", worker.Hostname); err != nil {
+		return err
+	}
+	if _, _, err := sshRunner.RunPrivileged("Changing the node role", "sed", "-i",
+		"-e", "'s|node-role.kubernetes.io/master|node-role.kubernetes.io/worker|'",
+		"-e", "'/--register-with-taints/d'",
+		"/etc/systemd/system/kubelet.service"); err != nil {
+		return err
+	}
+	kubeletDropIn := fmt.Sprintf("[Service]\nEnvironment=\"KUBELET_NODE_IP=%s\"\n", worker.IP)
+	if err := sshRunner.CopyData([]byte(kubeletDropIn), workerKubeletDropInPath, 0644); err != nil {
+		return err
+	}
+	networkScript := fmt.Sprintf(workerNetworkScript, worker.ControlPlaneInternalIP, worker.ControlPlaneIP)
+	if err := sshRunner.CopyData([]byte(networkScript), workerNetworkScriptPath, 0755); err != nil {
+		return err
+	}
+	if err := sshRunner.CopyData([]byte(workerNetworkUnit), fmt.Sprintf("/etc/systemd/system/%s", workerNetworkService), 0644); err != nil {
+		return err
+	}
+	if err := sd.DaemonReload(); err != nil {
+		return err
+	}
+	if err := sd.Enable(workerNetworkService); err != nil {
+		return err
+	}
+	if err := sd.Start(workerNetworkService); err != nil {
+		return err
+	}
+	_, _, err := sshRunner.RunPrivileged("Marking the instance as a worker node", "touch", workerMarkerPath)
+	return err
+}
+
+// JoinWorker approves the certificate requests of the kubelet of a new
+// worker node, and waits until the node is ready. The requests are only
+// approved when the node is not registered yet.
+func JoinWorker(ctx context.Context, ocConfig oc.Config, nodeName string) error {
+	if _, _, err := ocConfig.RunOcCommand("get", "node", nodeName); err != nil {
+		if err := approvePendingCSRs(ctx, ocConfig, kubeletClientSignerName); err != nil {
+			return err
+		}
+		if err := approvePendingCSRs(ctx, ocConfig, kubeletServingSignerName); err != nil {
+			return err
+		}
+	}
+	return WaitForNodeReady(ctx, ocConfig, nodeName)
+}
+
+// WaitForNodeReady waits until the node is registered and ready
+func WaitForNodeReady(ctx context.Context, ocConfig oc.Config, nodeName string) error {
+	nodeReady := func() error {
+		stdout, stderr, err := ocConfig.RunOcCommand("get", "node", nodeName,
+			"-o", `jsonpath='{.status.conditions[?(@.type=="Ready")].status}'`)
+		if err != nil {
+			logging.Debug(stderr)
+			return &crcerrors.RetriableError{Err: err}
+		}
+		if strings.TrimSpace(stdout) != "True" {
+			return &crcerrors.RetriableError{Err: fmt.Errorf("node %s is not ready", nodeName)}
+		}
+		return nil
+	}
+	return crcerrors.Retry(ctx, 10*time.Minute, nodeReady, 5*time.Second)
+}
+
+// DeleteNode removes the node from the cluster, it is not an error if it
+// does not exist
+func DeleteNode(ocConfig oc.Config, nodeName string) error {
+	_, stderr, err := ocConfig.RunOcCommand("delete", "node", nodeName, "--ignore-not-found")
+	if err != nil {
+		return fmt.Errorf("Failed to delete node %s: %v %s", nodeName, err, stderr)
+	}
+	return nil
+}
@@ -24,6 +24,17 @@ func RequiresDeleteAndSetupMsg(key string, _ interface{}) string {
 		"delete the CRC instance with 'crc delete', setup it with `crc setup` and start it with 'crc start'.", key, key)
 }
 
+func RequiresSSHKeyRotationMsg(key string, _ interface{}) string {
+	return fmt.Sprintf("Changes to configuration property '%s' are only applied when the CRC instance is created.\n"+
+		"If you already have a running CRC instance, then for this configuration change to take effect, "+
+		"replace its SSH key with 'crc ssh rotate-key'.", key)
+}
+
+func RequiresDaemonRestartMsg(key string, _ interface{}) string {
+	return fmt.Sprintf("Changes to configuration property '%s' are only applied when the crc daemon is started.\n"+
+		"If the daemon is running, then for this configuration change to take effect, restart it.", key)
+}
+
 func SuccessfullyApplied(key string, value interface{}) string {
 	return fmt.Sprintf("Successfully configured %s to %s", key, cast.ToString(value))
 }
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/preset"
+)
+
+// SuggestedValues returns the values of a setting proposed by the shell
+// completion, it is nil for the settings which accept free-form values
+func (c *Config) SuggestedValues(key string) []string {
+	setting, ok := c.settingsByName[key]
+	if !ok {
+		return nil
+	}
+	switch key {
+	case Preset:
+		return []string{preset.OpenShift.String(), preset.Podman.String()}
+	case NetworkMode:
+		return []string{string(network.UserNetworkingMode), string(network.SystemNetworkingMode)}
+	case VMDriver:
+		return SupportedVMDrivers()
+	case ProxyAuthMethod:
+		return network.ProxyAuthMethods()
+	case ConsentTelemetry:
+		return []string{"yes", "no"}
+	}
+	if _, ok := setting.defaultValue.(bool); ok {
+		return []string{"true", "false"}
+	}
+	return nil
+}
@@ -59,37 +59,60 @@ func (c *Config) AddSetting(name string, defValue interface{}, validationFn Vali
 	}
 }
 
-// Set sets the value for a given config key
-func (c *Config) Set(key string, value interface{}) (string, error) {
+// Validate checks that value is valid for the config key, without setting it
+func (c *Config) Validate(key string, value interface{}) error {
+	_, err := c.validate(key, value)
+	return err
+}
+
+// validate returns value converted to the type of the config key
+func (c *Config) validate(key string, value interface{}) (interface{}, error) {
 	setting, ok := c.settingsByName[key]
 	if !ok {
-		return "", fmt.Errorf(configPropDoesntExistMsg, key)
+		return nil, fmt.Errorf(configPropDoesntExistMsg, key)
+	}
+
+	canonical, err := canonicalValue(value, setting.unit)
+	if err != nil {
+		return nil, fmt.Errorf(invalidProp, value, key, err)
 	}
+	value = canonical
 
-	ok, expectedValue := c.settingsByName[key].validationFn(value)
+	ok, expectedValue := setting.validationFn(value)
 	if !ok {
-		return "", fmt.Errorf(invalidProp, value, key, expectedValue)
+		return nil, fmt.Errorf(invalidProp, value, key, expectedValue)
 	}
 
-	var castValue interface{}
-	var err error
 	switch setting.defaultValue.(type) {
 	case int:
-		castValue, err = cast.ToIntE(value)
+		if IsAutoSize(value) {
+			return AutoSize, nil
+		}
+		castValue, err := cast.ToIntE(value)
 		if err != nil {
-			return "", fmt.Errorf(invalidProp, value, key, err)
+			return nil, fmt.Errorf(invalidProp, value, key, err)
 		}
+		return castValue, nil
 	case string:
-		castValue = cast.ToString(value)
+		return cast.ToString(value), nil
 	case bool:
-		castValue, err = cast.ToBoolE(value)
+		castValue, err := cast.ToBoolE(value)
 		if err != nil {
-			return "", fmt.Errorf(invalidProp, value, key, err)
+			return nil, fmt.Errorf(invalidProp, value, key, err)
 		}
+		return castValue, nil
 	case preset.Preset:
-		castValue = cast.ToString(value)
+		return cast.ToString(value), nil
 	default:
-		return "", fmt.Errorf(invalidType, value, key)
+		return nil, fmt.Errorf(invalidType, value, key)
+	}
+}
+
+// Set sets the value for a given config key
+func (c *Config) Set(key string, value interface{}) (string, error) {
+	castValue, err := c.validate(key, value)
+	if err != nil {
+		return "", err
 	}
 
 	if err := c.storage.Set(key, castValue); err != nil {
@@ -113,6 +136,22 @@ func (c *Config) Unset(key string) (string, error) {
 	return fmt.Sprintf("Successfully unset configuration property '%s'", key), nil
 }
 
+// Origin returns where the value of the config key comes from, and the flag,
+// environment variable or file setting it
+func (c *Config) Origin(key string) (Origin, string, error) {
+	if _, ok := c.settingsByName[key]; !ok {
+		return "", "", fmt.Errorf(configPropDoesntExistMsg, key)
+	}
+	if storage, ok := c.storage.(OriginStorage); ok {
+		origin, source := storage.Origin(key)
+		return origin, source, nil
+	}
+	if c.storage.Get(key) != nil {
+		return OriginConfigFile, "", nil
+	}
+	return OriginDefault, "", nil
+}
+
 func (c *Config) Get(key string) SettingValue {
 	setting, ok := c.settingsByName[key]
 	if !ok {
@@ -124,9 +163,18 @@ func (c *Config) Get(key string) SettingValue {
 	if value == nil {
 		value = setting.defaultValue
 	}
-	var err error
+	value, err := canonicalValue(value, setting.unit)
+	if err != nil {
+		return SettingValue{
+			Invalid: true,
+		}
+	}
 	switch setting.defaultValue.(type) {
 	case int:
+		if IsAutoSize(value) {
+			value = AutoSize
+			break
+		}
 		value, err = cast.ToIntE(value)
 		if err != nil {
 			return SettingValue{
@@ -157,5 +205,6 @@ func (c *Config) Get(key string) SettingValue {
 	return SettingValue{
 		Value:     value,
 		IsDefault: reflect.DeepEqual(setting.defaultValue, value),
+		Unit:      setting.unit,
 	}
 }
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"reflect"
+
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/preset"
+)
+
+// SettingDoc is the reference documentation of a setting, generated from
+// its registration
+type SettingDoc struct {
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Default     interface{} `json:"default"`
+	Description string      `json:"description"`
+	Since       string      `json:"since,omitempty"`
+	AppliedWhen string      `json:"appliedWhen"`
+}
+
+// settingsSinceVersion records the crc version which introduced a setting.
+// Settings which were available before this was tracked are not listed.
+var settingsSinceVersion = map[string]string{
+	AddClientsToPath:           "2.1.0",
+	AlternativeHostPorts:       "2.1.0",
+	APIPort:                    "2.1.0",
+	AutoStopAfter:              "2.1.0",
+	CAFile:                     "2.1.0",
+	CgroupsVersion:             "2.1.0",
+	ClusterDomain:              "2.1.0",
+	DNSQueryLogging:            "2.1.0",
+	EnableClusterMonitoring:    "2.1.0",
+	EnableDualStack:            "2.1.0",
+	EnableGPU:                  "2.1.0",
+	EnableImageCache:           "2.1.0",
+	EnableNestedVirtualization: "2.1.0",
+	EnableOperators:            "2.1.0",
+	EnableRosetta:              "2.1.0",
+	ExtraIgnition:              "2.1.0",
+	HostSleepAction:            "2.1.0",
+	HugePages:                  "2.1.0",
+	IngressPorts:               "2.1.0",
+	InstallShellCompletion:     "2.1.0",
+	LibvirtURI:                 "2.1.0",
+	MetricsPort:                "2.1.0",
+	OperatorsTimeout:           "2.1.0",
+	PostStartHook:              "2.1.0",
+	PreStopHook:                "2.1.0",
+	ProxyAuthMethod:            "2.1.0",
+	PullSecretFromKeychain:     "2.1.0",
+	PVPoolSize:                 "2.1.0",
+	RollbackFailedStart:        "2.1.0",
+	RotateKubeAdminPassword:    "2.1.0",
+	SharedDirs:                 "2.1.0",
+	SkipBundleVerification:     "2.1.0",
+	SSHKey:                     "2.1.0",
+	SSHKeyType:                 "2.1.0",
+	SSHPort:                    "2.1.0",
+	SSHTimeout:                 "2.1.0",
+	StartTimeout:               "2.1.0",
+	StartupManifests:           "2.1.0",
+	Sysctls:                    "2.1.0",
+	UpdateChannel:              "2.1.0",
+	UpdateKubeconfig:           "2.1.0",
+	VMBootTimeout:              "2.1.0",
+	VMDriver:                   "2.1.0",
+	VMWatchdog:                 "2.1.0",
+	VPNCompat:                  "2.1.0",
+	WorkerCPUs:                 "2.1.0",
+	WorkerMemory:               "2.1.0",
+	Workers:                    "2.1.0",
+}
+
+func (c *Config) Docs() []SettingDoc {
+	var docs []SettingDoc
+	for _, setting := range c.settingsByName {
+		docs = append(docs, SettingDoc{
+			Name:        setting.Name,
+			Type:        settingType(setting.defaultValue, setting.unit),
+			Default:     setting.defaultValue,
+			Description: setting.Help,
+			Since:       settingsSinceVersion[setting.Name],
+			AppliedWhen: appliedWhen(setting.callbackFn),
+		})
+	}
+	return docs
+}
+
+func settingType(defaultValue interface{}, unit Unit) string {
+	switch {
+	case unit == Duration:
+		return "duration"
+	case unit != NoUnit:
+		return "size in " + string(unit)
+	}
+	switch defaultValue.(type) {
+	case int:
+		return "integer"
+	case bool:
+		return "boolean"
+	case string, preset.Preset:
+		return "string"
+	default:
+		return "unknown"
+	}
+}
+
+// appliedWhen describes when a change to a setting takes effect. It is
+// derived from the callback used when registering the setting, as these
+// callbacks only print this information to the user.
+func appliedWhen(callbackFn SetFn) string {
+	if callbackFn == nil {
+		return "immediately"
+	}
+	switch reflect.ValueOf(callbackFn).Pointer() {
+	case reflect.ValueOf(RequiresRestartMsg).Pointer():
+		return "on the next start"
+	case reflect.ValueOf(RequiresDeleteMsg).Pointer():
+		return "when the instance is created"
+	case reflect.ValueOf(RequiresDeleteAndSetupMsg).Pointer():
+		return "when the instance is created, after running setup"
+	case reflect.ValueOf(RequiresDaemonRestartMsg).Pointer():
+		return "when the daemon is started"
+	case reflect.ValueOf(RequiresCRCSetup).Pointer():
+		return "after running setup"
+	case reflect.ValueOf(network.SuccessfullyAppliedMode).Pointer():
+		return "after running cleanup and setup"
+	default:
+		return "immediately"
+	}
+}
@@ -2,33 +2,123 @@ package config
 
 import (
 	"fmt"
+	"runtime"
+	"strings"
+	"time"
 
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/crc/preset"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/code-ready/crc/pkg/crc/validation"
 	"github.com/code-ready/crc/pkg/crc/version"
 )
 
+// AutoSize is the value of the cpus and memory settings which sizes the
+// instance according to the host capacity
+const AutoSize = "auto"
+
+// values of the vm-driver setting
+const (
+	LibvirtVMDriver  = "libvirt"
+	QemuVMDriver     = "qemu"
+	HyperkitVMDriver = "hyperkit"
+	VfkitVMDriver    = "vfkit"
+	HypervVMDriver   = "hyperv"
+	WSL2VMDriver     = "wsl2"
+)
+
+// values of the vm-watchdog setting
+const (
+	WatchdogOff        = "off"
+	WatchdogNotifyOnly = "notify-only"
+	WatchdogRestartVM  = "restart-vm"
+)
+
+// values of the host-sleep-action setting
 const (
-	Bundle                  = "bundle"
-	CPUs                    = "cpus"
-	Memory                  = "memory"
-	DiskSize                = "disk-size"
-	NameServer              = "nameserver"
-	PullSecretFile          = "pull-secret-file"
-	DisableUpdateCheck      = "disable-update-check"
-	ExperimentalFeatures    = "enable-experimental-features"
-	NetworkMode             = "network-mode"
-	HostNetworkAccess       = "host-network-access"
-	HTTPProxy               = "http-proxy"
-	HTTPSProxy              = "https-proxy"
-	NoProxy                 = "no-proxy"
-	ProxyCAFile             = "proxy-ca-file"
-	ConsentTelemetry        = "consent-telemetry"
-	EnableClusterMonitoring = "enable-cluster-monitoring"
-	AutostartTray           = "autostart-tray"
-	KubeAdminPassword       = "kubeadmin-password"
-	Preset                  = "preset"
+	HostSleepNone = "none"
+	HostSleepSync = "sync"
+	HostSleepStop = "stop"
+)
+
+// values of the cgroups-version setting
+const (
+	CgroupsDefault = "default"
+	CgroupsV1      = "v1"
+	CgroupsV2      = "v2"
+)
+
+// HugePageSize is the size in MiB of the huge pages of the hugepages setting
+const HugePageSize = 2
+
+const (
+	Bundle                     = "bundle"
+	CPUs                       = "cpus"
+	Memory                     = "memory"
+	DiskSize                   = "disk-size"
+	NameServer                 = "nameserver"
+	PullSecretFile             = "pull-secret-file"
+	PullSecretFromKeychain     = "pull-secret-from-keychain"
+	DisableUpdateCheck         = "disable-update-check"
+	UpdateChannel              = "update-channel"
+	ExperimentalFeatures       = "enable-experimental-features"
+	NetworkMode                = "network-mode"
+	HostNetworkAccess          = "host-network-access"
+	HTTPProxy                  = "http-proxy"
+	HTTPSProxy                 = "https-proxy"
+	NoProxy                    = "no-proxy"
+	ProxyCAFile                = "proxy-ca-file"
+	ProxyAuthMethod            = "proxy-auth-method"
+	CAFile                     = "ca-file"
+	ConsentTelemetry           = "consent-telemetry"
+	EnableClusterMonitoring    = "enable-cluster-monitoring"
+	AutostartTray              = "autostart-tray"
+	KubeAdminPassword          = "kubeadmin-password"
+	RotateKubeAdminPassword    = "rotate-kubeadmin-password"
+	UpdateKubeconfig           = "update-kubeconfig"
+	SSHKey                     = "ssh-key"
+	SSHKeyType                 = "ssh-key-type"
+	SSHPort                    = "ssh-port"
+	Preset                     = "preset"
+	Workers                    = "workers"
+	WorkerCPUs                 = "worker-cpus"
+	WorkerMemory               = "worker-memory"
+	SkipBundleVerification     = "skip-bundle-verification"
+	AutoStopAfter              = "auto-stop-after"
+	VMWatchdog                 = "vm-watchdog"
+	HostSleepAction            = "host-sleep-action"
+	ClusterDomain              = "cluster-domain"
+	EnableDualStack            = "enable-dual-stack"
+	SharedDirs                 = "shared-dirs"
+	PVPoolSize                 = "pv-pool-size"
+	EnableGPU                  = "enable-gpu"
+	EnableNestedVirtualization = "enable-nested-virtualization"
+	VMDriver                   = "vm-driver"
+	EnableRosetta              = "enable-rosetta"
+	EnableImageCache           = "enable-image-cache"
+	EnableOperators            = "enable-operators"
+	PostStartHook              = "post-start-hook"
+	PreStopHook                = "pre-stop-hook"
+	StartupManifests           = "startup-manifests"
+	ExtraIgnition              = "extra-ignition"
+	HugePages                  = "hugepages"
+	CgroupsVersion             = "cgroups-version"
+	Sysctls                    = "sysctls"
+	InstallShellCompletion     = "install-shell-completion"
+	AddClientsToPath           = "add-clients-to-path"
+	LibvirtURI                 = "libvirt-uri"
+	StartTimeout               = "start-timeout"
+	VMBootTimeout              = "vm-boot-timeout"
+	SSHTimeout                 = "ssh-timeout"
+	OperatorsTimeout           = "operators-timeout"
+	RollbackFailedStart        = "rollback-failed-start"
+	VPNCompat                  = "vpn-compat"
+	DNSQueryLogging            = "dns-query-logging"
+	AlternativeHostPorts       = "alternative-host-ports"
+	APIPort                    = "api-port"
+	IngressPorts               = "ingress-ports"
+	MetricsPort                = "metrics-port"
 )
 
 func RegisterSettings(cfg *Config) {
@@ -46,13 +136,45 @@ func RegisterSettings(cfg *Config) {
 	}
 
 	validateMemory := func(value interface{}) (bool, string) {
-		return ValidateMemory(value, GetPreset(cfg))
+		return ValidateMemory(value, GetPreset(cfg), monitoringEnabled(cfg))
+	}
+
+	validateEnableClusterMonitoring := func(value interface{}) (bool, string) {
+		return ValidateEnableClusterMonitoring(value, GetPreset(cfg), cfg.Get(Memory).Value)
 	}
 
 	validateBundlePath := func(value interface{}) (bool, string) {
 		return ValidateBundlePath(value, GetPreset(cfg))
 	}
 
+	validatePVPoolSize := func(value interface{}) (bool, string) {
+		return ValidatePVPoolSize(value, cfg.Get(DiskSize).AsInt())
+	}
+
+	validateHugePages := func(value interface{}) (bool, string) {
+		return ValidateHugePages(value, GetMemory(cfg))
+	}
+
+	validateVMDriver := func(value interface{}) (bool, string) {
+		return ValidateVMDriver(value, GetNetworkMode(cfg))
+	}
+
+	validateEnableRosetta := func(value interface{}) (bool, string) {
+		return ValidateEnableRosetta(value, GetVMDriver(cfg))
+	}
+
+	validateLibvirtURI := func(value interface{}) (bool, string) {
+		return ValidateLibvirtURI(value, GetVMDriver(cfg), GetNetworkMode(cfg))
+	}
+
+	validateEnableImageCache := func(value interface{}) (bool, string) {
+		return ValidateEnableImageCache(value, GetVMDriver(cfg))
+	}
+
+	validateWorkers := func(value interface{}) (bool, string) {
+		return ValidateWorkers(value, GetPreset(cfg), GetNetworkMode(cfg))
+	}
+
 	// Preset setting should be on top because CPUs/Memory config depend on it.
 	cfg.AddSetting(Preset, string(preset.OpenShift), validatePreset, RequiresDeleteAndSetupMsg,
 		fmt.Sprintf("Virtual machine preset (alpha feature - valid values are: %s or %s)", preset.Podman, preset.OpenShift))
@@ -60,46 +182,202 @@ func RegisterSettings(cfg *Config) {
 	cfg.AddSetting(Bundle, defaultBundlePath(cfg), validateBundlePath, SuccessfullyApplied,
 		fmt.Sprintf("Bundle path (string, default '%s')", defaultBundlePath(cfg)))
 	cfg.AddSetting(CPUs, defaultCPUs(cfg), validateCPUs, RequiresRestartMsg,
-		fmt.Sprintf("Number of CPU cores (must be greater than or equal to '%d')", defaultCPUs(cfg)))
+		fmt.Sprintf("Number of CPU cores (must be greater than or equal to '%d', or '%s' to use half of the host CPUs)", defaultCPUs(cfg), AutoSize))
 	cfg.AddSetting(Memory, defaultMemory(cfg), validateMemory, RequiresRestartMsg,
-		fmt.Sprintf("Memory size in MiB (must be greater than or equal to '%d')", defaultMemory(cfg)))
+		fmt.Sprintf("Memory size, in MiB without unit (like '12GiB', must be greater than or equal to '%d', or '%s' to use half of the host memory)", defaultMemory(cfg), AutoSize))
 	cfg.AddSetting(DiskSize, constants.DefaultDiskSize, ValidateDiskSize, RequiresRestartMsg,
-		fmt.Sprintf("Total size in GiB of the disk (must be greater than or equal to '%d')", constants.DefaultDiskSize))
-	cfg.AddSetting(NameServer, "", ValidateIPAddress, SuccessfullyApplied,
-		"IPv4 address of nameserver (string, like '1.1.1.1 or 8.8.8.8')")
+		fmt.Sprintf("Total size of the disk, in GiB without unit (like '80G', must be greater than or equal to '%d')", constants.DefaultDiskSize))
+	cfg.AddSetting(PVPoolSize, 0, validatePVPoolSize, RequiresRestartMsg,
+		fmt.Sprintf("Size, in GiB without unit, of the pool in which the persistent volumes of the claims are provisioned, taken from the disk "+
+			"beyond the '%d' GiB needed by the cluster (0 to use the persistent volumes of the bundle, default: 0)", constants.DefaultDiskSize))
+	cfg.AddSetting(Workers, 0, validateWorkers, RequiresRestartMsg,
+		fmt.Sprintf("Number of worker nodes, only with the %s network mode (experimental - between 0 and %d, default: 0)",
+			network.SystemNetworkingMode, constants.MaxWorkers))
+	cfg.AddSetting(WorkerCPUs, constants.DefaultWorkerCPUs, ValidateWorkerCPUs, RequiresRestartMsg,
+		fmt.Sprintf("Number of CPU cores of the worker nodes created by 'crc start' or 'crc node add' (must be greater than or equal to '%d')", constants.DefaultWorkerCPUs))
+	cfg.AddSetting(WorkerMemory, constants.DefaultWorkerMemory, ValidateWorkerMemory, RequiresRestartMsg,
+		fmt.Sprintf("Memory size, in MiB without unit, of the worker nodes created by 'crc start' or 'crc node add' (like '8GiB', must be greater than or equal to '%d')", constants.DefaultWorkerMemory))
+	cfg.setUnit(Memory, MiB)
+	cfg.setUnit(DiskSize, GiB)
+	cfg.setUnit(PVPoolSize, GiB)
+	cfg.setUnit(WorkerMemory, MiB)
+	cfg.AddSetting(NameServer, "", ValidateNameServers, SuccessfullyApplied,
+		"Comma-separated list of nameservers: IPv4 addresses (like '1.1.1.1,8.8.8.8'), and with the user network mode, "+
+			"IPv6 addresses, DNS-over-TLS (tls://1.1.1.1[:853][#cloudflare-dns.com]) or DNS-over-HTTPS (https://cloudflare-dns.com/dns-query) nameservers")
+	cfg.AddSetting(DNSQueryLogging, false, ValidateBool, SuccessfullyApplied,
+		"Log the DNS queries answered by the daemon, and their replies, in its log file (true/false, default: false)")
+	cfg.AddSetting(SkipBundleVerification, false, ValidateBool, SuccessfullyApplied,
+		"Use the downloaded bundles without verifying them against the sha256sum.txt files of the mirror signed by Red Hat (true/false, default: false)")
 	cfg.AddSetting(PullSecretFile, "", ValidatePath, SuccessfullyApplied,
 		fmt.Sprintf("Path of image pull secret (download from %s)", constants.CrcLandingPageURL))
+	cfg.AddSetting(PullSecretFromKeychain, false, ValidateBool, SuccessfullyApplied,
+		"Only read the pull secret from the OS credential store, after moving it there (true/false, default: false)")
 	cfg.AddSetting(DisableUpdateCheck, false, ValidateBool, SuccessfullyApplied,
 		"Disable update check (true/false, default: false)")
+	cfg.AddSetting(UpdateChannel, version.StableChannel, ValidateUpdateChannel, SuccessfullyApplied,
+		fmt.Sprintf("Release channel of the update check and of 'crc self-update' (%s or %s, default: %s)",
+			version.StableChannel, version.CandidateChannel, version.StableChannel))
 	cfg.AddSetting(ExperimentalFeatures, false, ValidateBool, SuccessfullyApplied,
 		"Enable experimental features (true/false, default: false)")
 
 	if !version.IsInstaller() {
 		cfg.AddSetting(NetworkMode, string(defaultNetworkMode()), network.ValidateMode, network.SuccessfullyAppliedMode,
 			fmt.Sprintf("Network mode (%s or %s)", network.UserNetworkingMode, network.SystemNetworkingMode))
+		cfg.AddSetting(VPNCompat, false, ValidateBool, network.SuccessfullyAppliedMode,
+			fmt.Sprintf("Use the %s network mode, which is not affected by the routes and the DNS servers of the VPN clients, "+
+				"when network-mode is not set (true/false, default: false)", network.UserNetworkingMode))
+		cfg.AddSetting(AlternativeHostPorts, false, ValidateBool, SuccessfullyApplied,
+			fmt.Sprintf("In the %s network mode, forward the API server and the router of the cluster from free ports of the host "+
+				"when other processes use the ports %d, %d or %d (true/false, default: false)", network.UserNetworkingMode, network.APIPort, network.HTTPPort, network.HTTPSPort))
+		cfg.AddSetting(APIPort, network.APIPort, ValidatePort, RequiresRestartMsg,
+			fmt.Sprintf("Port of the host forwarded to the API server of the cluster in the %s network mode (default: %d)", network.UserNetworkingMode, network.APIPort))
+		cfg.AddSetting(SSHPort, network.SSHPort, ValidatePort, RequiresRestartMsg,
+			fmt.Sprintf("Port of the host forwarded to the SSH server of the instance in the %s network mode (default: %d)", network.UserNetworkingMode, network.SSHPort))
+		cfg.AddSetting(IngressPorts, fmt.Sprintf("%d,%d", network.HTTPPort, network.HTTPSPort), ValidateIngressPorts, RequiresRestartMsg,
+			fmt.Sprintf("Ports of the host forwarded to the http and https ports of the router of the cluster in the %s network mode, "+
+				"the ports below 1024 need privileges on some hosts (like '8080,8443', default: '%d,%d')", network.UserNetworkingMode, network.HTTPPort, network.HTTPSPort))
 	}
+	cfg.AddSetting(VMDriver, defaultVMDriver(), validateVMDriver, RequiresDeleteMsg,
+		fmt.Sprintf("Virtual machine driver (%s, default: %s), the %s driver runs QEMU without libvirt on Linux, "+
+			"the %s driver uses Virtualization.framework on Apple silicon, both need the %s network mode, "+
+			"the %s driver runs the instance as a WSL2 distribution on Windows and needs the %s network mode",
+			strings.Join(SupportedVMDrivers(), " or "), defaultVMDriver(), QemuVMDriver, VfkitVMDriver, network.UserNetworkingMode,
+			WSL2VMDriver, network.SystemNetworkingMode))
+
+	cfg.AddSetting(LibvirtURI, constants.LibvirtSystemURI, validateLibvirtURI, RequiresDeleteAndSetupMsg,
+		fmt.Sprintf("Connection of the %s driver (%s or %s, default: %s), with %s the instance runs without privileges "+
+			"and the user does not need to be in the libvirt group, it needs the %s network mode",
+			LibvirtVMDriver, constants.LibvirtSystemURI, constants.LibvirtSessionURI, constants.LibvirtSystemURI, constants.LibvirtSessionURI, network.UserNetworkingMode))
 
 	cfg.AddSetting(HostNetworkAccess, false, validateHostNetworkAccess, SuccessfullyApplied,
 		"Allow TCP/IP connections from the CodeReady Containers VM to services running on the host (true/false, default: false)")
 	// Proxy Configuration
 	cfg.AddSetting(HTTPProxy, "", ValidateHTTPProxy, SuccessfullyApplied,
-		"HTTP proxy URL (string, like 'http://my-proxy.com:8443')")
+		"HTTP proxy URL (string, like 'http://my-proxy.com:8443' or 'socks5://my-proxy.com:1080')")
 	cfg.AddSetting(HTTPSProxy, "", ValidateHTTPSProxy, SuccessfullyApplied,
-		"HTTPS proxy URL (string, like 'https://my-proxy.com:8443')")
+		"HTTPS proxy URL (string, like 'https://my-proxy.com:8443' or 'socks5://my-proxy.com:1080')")
 	cfg.AddSetting(NoProxy, "", ValidateNoProxy, SuccessfullyApplied,
 		"Hosts, ipv4 addresses or CIDR which do not use a proxy (string, comma-separated list such as '127.0.0.1,192.168.100.1/24')")
 	cfg.AddSetting(ProxyCAFile, "", ValidatePath, SuccessfullyApplied,
 		"Path to an HTTPS proxy certificate authority (CA)")
+	cfg.AddSetting(ProxyAuthMethod, network.BasicProxyAuth, ValidateProxyAuthMethod, RequiresRestartMsg,
+		fmt.Sprintf("Authentication method of the proxy (%s, default: %s), with %s and %s crc authenticates with the credentials "+
+			"of the user logged in on the host and the cluster reaches the proxy through the daemon",
+			strings.Join(network.ProxyAuthMethods(), ", "), network.BasicProxyAuth, network.NegotiateProxyAuth, network.NTLMProxyAuth))
+	cfg.AddSetting(CAFile, "", ValidatePath, RequiresRestartMsg,
+		"Path to additional certificate authorities (CA) to trust, such as the one of a TLS-intercepting proxy")
 
-	cfg.AddSetting(EnableClusterMonitoring, false, ValidateBool, SuccessfullyApplied,
-		"Enable cluster monitoring Operator (true/false, default: false)")
+	cfg.AddSetting(EnableClusterMonitoring, false, validateEnableClusterMonitoring, RequiresRestartMsg,
+		fmt.Sprintf("Enable cluster monitoring Operator, it needs at least %d MiB of memory, "+
+			"disabling it requires to delete the instance (true/false, default: false)", constants.MonitoringMemory))
 
 	// Telemeter Configuration
 	cfg.AddSetting(ConsentTelemetry, "", ValidateYesNo, SuccessfullyApplied,
 		"Consent to collection of anonymous usage data (yes/no)")
 
+	cfg.AddSetting(AutoStopAfter, "", ValidateAutoStopAfter, SuccessfullyApplied,
+		"Stop the instance when the cluster, the console and ssh were not used for this duration, "+
+			"the daemon must be running (duration like '60m' or '2h', default: disabled)")
+	cfg.setUnit(AutoStopAfter, Duration)
+	cfg.AddSetting(MetricsPort, "", ValidateMetricsPort, RequiresDaemonRestartMsg,
+		"Port of 127.0.0.1 on which the daemon also serves its metrics over HTTP, for the monitoring systems "+
+			"which cannot use the daemon socket (port number, default: disabled)")
+	cfg.AddSetting(VMWatchdog, WatchdogNotifyOnly, ValidateWatchdogPolicy, SuccessfullyApplied,
+		fmt.Sprintf("What the daemon does when the instance, started by crc, stopped or no longer answers ssh: "+
+			"%s does nothing, %s records an event in the status, %s also restarts the instance (default: %s)",
+			WatchdogOff, WatchdogNotifyOnly, WatchdogRestartVM, WatchdogNotifyOnly))
+	cfg.AddSetting(HostSleepAction, HostSleepSync, ValidateHostSleepAction, SuccessfullyApplied,
+		fmt.Sprintf("What the daemon does with the running instance before the host sleeps: "+
+			"%s does nothing, %s flushes its disks, %s stops it and starts it again when the host resumes (default: %s)",
+			HostSleepNone, HostSleepSync, HostSleepStop, HostSleepSync))
+
+	cfg.AddSetting(StartTimeout, "", ValidateTimeout, SuccessfullyApplied,
+		"Fail 'crc start' when it did not complete in this duration (duration like '30m', default: no limit)")
+	cfg.setUnit(StartTimeout, Duration)
+	cfg.AddSetting(VMBootTimeout, "", ValidateTimeout, SuccessfullyApplied,
+		"Time to wait for the instance to be running after starting it (duration like '5m', default: 3m)")
+	cfg.setUnit(VMBootTimeout, Duration)
+	cfg.AddSetting(SSHTimeout, "", ValidateTimeout, SuccessfullyApplied,
+		"Time to wait for ssh to be available in the running instance (duration like '10m', default: 5m)")
+	cfg.setUnit(SSHTimeout, Duration)
+	cfg.AddSetting(OperatorsTimeout, "", ValidateTimeout, SuccessfullyApplied,
+		"Time to wait for the cluster operators to be stable (duration like '20m', default: 10m, 15m with a proxy)")
+	cfg.setUnit(OperatorsTimeout, Duration)
+	cfg.AddSetting(RollbackFailedStart, false, ValidateBool, SuccessfullyApplied,
+		"Remove the instance created by a start which failed, instead of keeping it for 'crc start --resume' (true/false, default: false)")
+
+	cfg.AddSetting(ClusterDomain, "", ValidateClusterDomain, RequiresDeleteAndSetupMsg,
+		"Domain of the cluster, the API server is then api.<domain> and the applications *.apps.<domain> "+
+			"(string, like 'crc.example.com', default: crc.testing)")
+
+	cfg.AddSetting(EnableDualStack, false, ValidateBool, RequiresRestartMsg,
+		"Add IPv6 pod and service networks to the cluster when the instance has an IPv6 address, "+
+			"disabling it requires to delete the instance (true/false, default: false)")
+
+	cfg.AddSetting(EnableGPU, false, ValidateEnableGPU, RequiresRestartMsg,
+		"Assign the GPUs bound to the vfio-pci driver to the instance and label the node for the GPU operators, "+
+			"only with the libvirt driver (true/false, default: false)")
+
+	cfg.AddSetting(EnableOperators, "", ValidateEnableOperators, RequiresRestartMsg,
+		"Comma-separated list of the operator packages installed from the catalogs of the cluster when it is ready, "+
+			"in all the namespaces (string, like 'openshift-pipelines-operator-rh,serverless-operator')")
+
+	cfg.AddSetting(EnableNestedVirtualization, false, ValidateEnableNestedVirtualization, RequiresRestartMsg,
+		"Expose the virtualization extensions of the processor to the instance, to run virtual machines in the cluster "+
+			"with KubeVirt, not supported by the hyperkit driver (true/false, default: false)")
+
+	cfg.AddSetting(EnableRosetta, false, validateEnableRosetta, RequiresRestartMsg,
+		"Run the x86_64 executables of the containers with Rosetta on Apple silicon, "+
+			"only with the vfkit driver and macOS 13 or newer (true/false, default: false)")
+
+	cfg.AddSetting(SharedDirs, "", ValidateSharedDirs, RequiresRestartMsg,
+		"Comma-separated list of host directories mounted at the same path in the instance, "+
+			"or at /mnt/<drive>/<path> on Windows, they can be used in hostPath volumes (string, like '/home/user/src')")
+	cfg.AddSetting(EnableImageCache, false, validateEnableImageCache, RequiresRestartMsg,
+		fmt.Sprintf("Keep the images pulled by the cluster and by podman in %s, which is mounted with virtiofs in the instance "+
+			"and survives 'crc delete', only with the libvirt driver (true/false, default: false)", constants.ImageCacheDir))
+
 	cfg.AddSetting(KubeAdminPassword, "", ValidateString, SuccessfullyApplied,
 		"User defined kubeadmin password")
+	cfg.AddSetting(RotateKubeAdminPassword, false, ValidateBool, SuccessfullyApplied,
+		fmt.Sprintf("Generate a new kubeadmin password on every start, unless %s is set (true/false, default: false)", KubeAdminPassword))
+	cfg.AddSetting(UpdateKubeconfig, true, ValidateBool, SuccessfullyApplied,
+		"Add the crc-admin and crc-developer contexts to the default kubeconfig on every start, "+
+			"when disabled use 'crc kubeconfig' to get a kubeconfig for the cluster (true/false, default: true)")
+	cfg.AddSetting(SSHKey, "", ValidateSSHKey, RequiresSSHKeyRotationMsg,
+		"Private key used to connect to the instance instead of a generated one, its public key must be in the same file with .pub added, "+
+			"the key is copied to the instance directory")
+	cfg.AddSetting(SSHKeyType, ssh.ECDSAKey, ValidateSSHKeyType, RequiresSSHKeyRotationMsg,
+		fmt.Sprintf("Type of the generated SSH key of the instance, when %s is not set (%s, default: %s)",
+			SSHKey, strings.Join(ssh.KeyTypes(), ", "), ssh.ECDSAKey))
+
+	cfg.AddSetting(StartupManifests, "", ValidatePath, RequiresRestartMsg,
+		"Directory of Kubernetes manifests, or of a kustomization, applied when the cluster is ready, "+
+			"the start waits for the rollout of their workloads (string, like '/home/user/crc-manifests')")
+	cfg.AddSetting(ExtraIgnition, "", ValidateExtraIgnition, RequiresRestartMsg,
+		"Ignition v3 configuration whose files, directories, systemd units and kernel arguments are applied to the instance "+
+			"on every start, the instance is restarted when its kernel arguments change (string, like '/home/user/crc.ign')")
+	cfg.AddSetting(HugePages, 0, validateHugePages, RequiresRestartMsg,
+		fmt.Sprintf("Number of %d MiB huge pages reserved when the instance boots, at most half of the memory, "+
+			"the instance is restarted by the next start to change it (default: 0)", HugePageSize))
+	cfg.AddSetting(CgroupsVersion, CgroupsDefault, ValidateCgroupsVersion, RequiresRestartMsg,
+		fmt.Sprintf("Version of the control groups of the instance, %s keeps the one of the bundle, "+
+			"the instance is restarted by the next start to change it (%s, %s or %s, default: %s)",
+			CgroupsDefault, CgroupsDefault, CgroupsV1, CgroupsV2, CgroupsDefault))
+	cfg.AddSetting(Sysctls, "", ValidateSysctls, RequiresRestartMsg,
+		"Kernel parameters set in the instance on every start (comma-separated list like 'vm.max_map_count=262144,fs.inotify.max_user_watches=524288')")
+
+	cfg.AddSetting(InstallShellCompletion, false, ValidateBool, RequiresCRCSetup,
+		"Load the completion of the crc commands in the profile of the user shell (true/false, default: false)")
+	cfg.AddSetting(AddClientsToPath, false, ValidateBool, RequiresCRCSetup,
+		fmt.Sprintf("Add %s, which holds the oc and podman clients of the bundle, to the PATH of the user (true/false, default: false)", constants.CrcOcBinDir))
+
+	cfg.AddSetting(PostStartHook, "", ValidatePath, SuccessfullyApplied,
+		fmt.Sprintf("Executable run after every start with KUBECONFIG set to the admin kubeconfig, "+
+			"the post-start-* executables of %s are also run", constants.HooksDir))
+	cfg.AddSetting(PreStopHook, "", ValidatePath, SuccessfullyApplied,
+		fmt.Sprintf("Executable run before every stop with KUBECONFIG set to the admin kubeconfig, "+
+			"the pre-stop-* executables of %s are also run", constants.HooksDir))
 }
 
 func defaultCPUs(cfg Storage) int {
@@ -110,6 +388,117 @@ func defaultMemory(cfg Storage) int {
 	return constants.GetDefaultMemory(GetPreset(cfg))
 }
 
+// GetCPUs returns the number of CPUs of the instance, computed from the host
+// capacity when the setting is 'auto'
+func GetCPUs(cfg Storage) int {
+	value := cfg.Get(CPUs)
+	if IsAutoSize(value.Value) {
+		return validation.AutoCPUs(GetPreset(cfg))
+	}
+	return value.AsInt()
+}
+
+// GetMemory returns the memory size in MiB of the instance, computed from the
+// host capacity when the setting is 'auto'
+func GetMemory(cfg Storage) int {
+	value := cfg.Get(Memory)
+	if IsAutoSize(value.Value) {
+		memory := validation.AutoMemory(GetPreset(cfg))
+		if monitoringEnabled(cfg) && memory < constants.MonitoringMemory {
+			return constants.MonitoringMemory
+		}
+		return memory
+	}
+	return value.AsInt()
+}
+
+// monitoringEnabled returns true when the cluster monitoring is enabled, it
+// is only available with the OpenShift preset
+func monitoringEnabled(cfg Storage) bool {
+	return GetPreset(cfg) == preset.OpenShift && cfg.Get(EnableClusterMonitoring).AsBool()
+}
+
+// IsAutoSize returns true for the 'auto' value of the cpus and memory settings
+func IsAutoSize(value interface{}) bool {
+	s, ok := value.(string)
+	return ok && s == AutoSize
+}
+
+// GetAutoStopAfter returns the idle duration after which the instance is
+// stopped, 0 when auto-stop is disabled
+func GetAutoStopAfter(cfg Storage) time.Duration {
+	return GetDuration(cfg, AutoStopAfter)
+}
+
+// GetWatchdogPolicy returns the value of the vm-watchdog setting
+func GetWatchdogPolicy(cfg Storage) string {
+	return cfg.Get(VMWatchdog).AsString()
+}
+
+// GetHostSleepAction returns the value of the host-sleep-action setting
+func GetHostSleepAction(cfg Storage) string {
+	return cfg.Get(HostSleepAction).AsString()
+}
+
+// GetDuration returns the value of a duration setting, 0 when it is empty
+func GetDuration(cfg Storage, key string) time.Duration {
+	value := cfg.Get(key).AsString()
+	if value == "" {
+		return 0
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0
+	}
+	return duration
+}
+
+// GetSharedDirs returns the host directories mounted in the instance
+// GetHostPorts returns the ports of the host forwarded to the cluster in the
+// user network mode
+func GetHostPorts(cfg Storage) network.HostPorts {
+	ports := network.DefaultHostPorts()
+	if port, err := network.ParsePort(cfg.Get(APIPort).AsString()); err == nil {
+		ports.API = port
+	}
+	if port, err := network.ParsePort(cfg.Get(SSHPort).AsString()); err == nil {
+		ports.SSH = port
+	}
+	if http, https, err := network.ParseIngressPorts(cfg.Get(IngressPorts).AsString()); err == nil {
+		ports.HTTP, ports.HTTPS = http, https
+	}
+	return ports
+}
+
+func GetSharedDirs(cfg Storage) []string {
+	return splitList(cfg.Get(SharedDirs).AsString())
+}
+
+// GetEnableOperators returns the operator packages to install in the cluster
+func GetEnableOperators(cfg Storage) []string {
+	return splitList(cfg.Get(EnableOperators).AsString())
+}
+
+// GetSysctls returns the kernel parameters of the sysctls setting
+func GetSysctls(cfg Storage) map[string]string {
+	sysctls, err := ParseSysctls(cfg.Get(Sysctls).AsString())
+	if err != nil {
+		return nil
+	}
+	return sysctls
+}
+
+// splitList returns the non-empty items of a comma-separated list
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func defaultBundlePath(cfg Storage) string {
 	return constants.GetDefaultBundlePath(GetPreset(cfg))
 }
@@ -119,7 +508,7 @@ func GetPreset(config Storage) preset.Preset {
 }
 
 func defaultNetworkMode() network.Mode {
-	if version.IsInstaller() {
+	if version.IsInstaller() || defaultVMDriver() == VfkitVMDriver {
 		return network.UserNetworkingMode
 	}
 	return network.SystemNetworkingMode
@@ -129,9 +518,65 @@ func GetNetworkMode(config Storage) network.Mode {
 	if version.IsInstaller() {
 		return network.UserNetworkingMode
 	}
+	// the WSL2 driver only supports the system network mode
+	if config.Get(VPNCompat).AsBool() && !isSet(config, NetworkMode) && GetVMDriver(config) != WSL2VMDriver {
+		return network.UserNetworkingMode
+	}
 	return network.ParseMode(config.Get(NetworkMode).AsString())
 }
 
+// isSet returns true when the value of key does not come from its default
+func isSet(config Storage, key string) bool {
+	if cfg, ok := config.(interface {
+		Origin(key string) (Origin, string, error)
+	}); ok {
+		origin, _, err := cfg.Origin(key)
+		return err == nil && origin != OriginDefault
+	}
+	return !config.Get(key).IsDefault
+}
+
+// SupportedVMDrivers returns the virtual machine drivers of this platform,
+// the first one is the default
+func SupportedVMDrivers() []string {
+	switch runtime.GOOS {
+	case "darwin":
+		// hyperkit is not available on Apple silicon, and the bundles of
+		// Virtualization.framework are only built for it
+		if runtime.GOARCH == "arm64" {
+			return []string{VfkitVMDriver}
+		}
+		return []string{HyperkitVMDriver}
+	case "windows":
+		return []string{HypervVMDriver, WSL2VMDriver}
+	default:
+		return []string{LibvirtVMDriver, QemuVMDriver}
+	}
+}
+
+func defaultVMDriver() string {
+	return SupportedVMDrivers()[0]
+}
+
+// GetVMDriver returns the virtual machine driver of the instance
+func GetVMDriver(config Storage) string {
+	return config.Get(VMDriver).AsString()
+}
+
+// GetMetricsPort returns the TCP port of the metrics of the daemon, or 0 when
+// they are only served on the daemon socket
+func GetMetricsPort(config Storage) int {
+	port, err := network.ParsePort(config.Get(MetricsPort).AsString())
+	if err != nil {
+		return 0
+	}
+	return port
+}
+
+func GetLibvirtURI(config Storage) string {
+	return config.Get(LibvirtURI).AsString()
+}
+
 func UpdateDefaults(cfg *Config) {
 	RegisterSettings(cfg)
 }
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PresetSetting is a property assigned by a settings preset, a nil Value
+// unsets the property so that its default is used
+type PresetSetting struct {
+	Key   string
+	Value interface{}
+}
+
+// SettingsPreset is a named set of properties applied at once with
+// 'crc config use-preset'. Not to be confused with the preset property,
+// which selects the bundle of the instance.
+type SettingsPreset struct {
+	Name        string
+	Description string
+	// Settings are applied in order, the properties which are validated
+	// against other properties come after them
+	Settings []PresetSetting
+}
+
+var settingsPresets = []SettingsPreset{
+	{
+		Name:        "low-memory",
+		Description: "Smallest instance which can run the cluster, for hosts with 16GiB of memory",
+		Settings: []PresetSetting{
+			{Key: EnableClusterMonitoring, Value: false},
+			{Key: CPUs, Value: nil},
+			{Key: Memory, Value: nil},
+			{Key: Workers, Value: 0},
+			{Key: PVPoolSize, Value: 0},
+			{Key: EnableImageCache, Value: nil},
+		},
+	},
+	{
+		Name:        "ci",
+		Description: "Unattended use on a dedicated host, no prompts and no update checks",
+		Settings: []PresetSetting{
+			{Key: ConsentTelemetry, Value: "no"},
+			{Key: DisableUpdateCheck, Value: true},
+			{Key: EnableClusterMonitoring, Value: false},
+			{Key: CPUs, Value: AutoSize},
+			{Key: Memory, Value: AutoSize},
+			{Key: AutoStopAfter, Value: nil},
+		},
+	},
+	{
+		Name:        "demo",
+		Description: "Roomy instance with cluster monitoring, for hosts with 32GiB of memory",
+		Settings: []PresetSetting{
+			{Key: CPUs, Value: 6},
+			{Key: Memory, Value: 16384},
+			{Key: DiskSize, Value: 50},
+			{Key: EnableClusterMonitoring, Value: true},
+		},
+	},
+}
+
+// SettingsPresets returns all the settings presets
+func SettingsPresets() []SettingsPreset {
+	return settingsPresets
+}
+
+// SettingsPresetNames returns the names of the settings presets
+func SettingsPresetNames() []string {
+	var names []string
+	for _, p := range settingsPresets {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// GetSettingsPreset returns the settings preset called name
+func GetSettingsPreset(name string) (SettingsPreset, error) {
+	for _, p := range settingsPresets {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return SettingsPreset{}, fmt.Errorf("Unknown settings preset '%s', valid presets are: %s", name, strings.Join(SettingsPresetNames(), ", "))
+}
+
+// SettingDifference is a property whose value differs from the one of a
+// settings preset
+type SettingDifference struct {
+	Key     string      `json:"key"`
+	Current interface{} `json:"current"`
+	Preset  interface{} `json:"preset"`
+	// UsesDefault tells if the preset unsets the property
+	UsesDefault bool `json:"usesDefault,omitempty"`
+}
+
+// DiffSettingsPreset returns the properties of cfg which differ from the
+// settings preset p
+func DiffSettingsPreset(cfg Storage, p SettingsPreset) []SettingDifference {
+	var diff []SettingDifference
+	for _, setting := range p.Settings {
+		current := cfg.Get(setting.Key)
+		if current.Invalid {
+			continue
+		}
+		if setting.Value == nil {
+			if !current.IsDefault {
+				diff = append(diff, SettingDifference{Key: setting.Key, Current: current.Value, UsesDefault: true})
+			}
+			continue
+		}
+		if current.AsString() != fmt.Sprint(setting.Value) {
+			diff = append(diff, SettingDifference{Key: setting.Key, Current: current.Value, Preset: setting.Value})
+		}
+	}
+	return diff
+}
+
+// ApplySettingsPreset assigns the properties of the settings preset p. It
+// returns the keys which were changed and the messages of the properties.
+// When a property is invalid, the properties which were already changed are
+// reverted and none of the preset is applied.
+func ApplySettingsPreset(cfg Storage, p SettingsPreset) ([]string, []string, error) {
+	type previousValue struct {
+		key   string
+		value SettingValue
+	}
+	var (
+		previous []previousValue
+		changed  []string
+		messages []string
+	)
+	revert := func() {
+		for i := len(previous) - 1; i >= 0; i-- {
+			if previous[i].value.IsDefault {
+				_, _ = cfg.Unset(previous[i].key)
+			} else {
+				_, _ = cfg.Set(previous[i].key, previous[i].value.Value)
+			}
+		}
+	}
+
+	for _, setting := range p.Settings {
+		current := cfg.Get(setting.Key)
+		if current.Invalid {
+			revert()
+			return nil, nil, fmt.Errorf(configPropDoesntExistMsg, setting.Key)
+		}
+		var (
+			message string
+			err     error
+		)
+		if setting.Value == nil {
+			if current.IsDefault {
+				continue
+			}
+			message, err = cfg.Unset(setting.Key)
+		} else {
+			message, err = cfg.Set(setting.Key, setting.Value)
+		}
+		if err != nil {
+			revert()
+			return nil, nil, fmt.Errorf("Settings preset '%s' not applied: %w", p.Name, err)
+		}
+		previous = append(previous, previousValue{key: setting.Key, value: current})
+		changed = append(changed, setting.Key)
+		if message != "" && !contains(messages, message) {
+			messages = append(messages, message)
+		}
+	}
+	return changed, messages, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
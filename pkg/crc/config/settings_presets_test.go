@@ -0,0 +1,83 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSettingsPresetTestConfig() *Config {
+	cfg := New(NewEmptyInMemoryStorage())
+	cfg.AddSetting(DiskSize, 31, ValidateDiskSize, RequiresRestartMsg, "")
+	cfg.AddSetting(DisableUpdateCheck, false, ValidateBool, SuccessfullyApplied, "")
+	cfg.AddSetting(HTTPProxy, "", ValidateString, SuccessfullyApplied, "")
+	return cfg
+}
+
+func TestSettingsPresetsAreValid(t *testing.T) {
+	cfg := New(NewEmptyInMemoryStorage())
+	RegisterSettings(cfg)
+	for _, p := range SettingsPresets() {
+		for _, setting := range p.Settings {
+			assert.False(t, cfg.Get(setting.Key).Invalid, "%s: %s", p.Name, setting.Key)
+		}
+	}
+}
+
+func TestGetSettingsPreset(t *testing.T) {
+	p, err := GetSettingsPreset("ci")
+	require.NoError(t, err)
+	assert.Equal(t, "ci", p.Name)
+
+	_, err = GetSettingsPreset("fast")
+	assert.EqualError(t, err, "Unknown settings preset 'fast', valid presets are: low-memory, ci, demo")
+}
+
+func TestApplySettingsPreset(t *testing.T) {
+	cfg := newSettingsPresetTestConfig()
+	_, err := cfg.Set(HTTPProxy, "http://proxy:3128")
+	require.NoError(t, err)
+
+	p := SettingsPreset{
+		Name: "test",
+		Settings: []PresetSetting{
+			{Key: DiskSize, Value: 50},
+			{Key: DisableUpdateCheck, Value: true},
+			{Key: HTTPProxy, Value: nil},
+		},
+	}
+	assert.Equal(t, []SettingDifference{
+		{Key: DiskSize, Current: 31, Preset: 50},
+		{Key: DisableUpdateCheck, Current: false, Preset: true},
+		{Key: HTTPProxy, Current: "http://proxy:3128", UsesDefault: true},
+	}, DiffSettingsPreset(cfg, p))
+
+	changed, messages, err := ApplySettingsPreset(cfg, p)
+	require.NoError(t, err)
+	assert.Equal(t, []string{DiskSize, DisableUpdateCheck, HTTPProxy}, changed)
+	assert.Len(t, messages, 3)
+	assert.Equal(t, 50, cfg.Get(DiskSize).AsInt())
+	assert.True(t, cfg.Get(DisableUpdateCheck).AsBool())
+	assert.True(t, cfg.Get(HTTPProxy).IsDefault)
+	assert.Empty(t, DiffSettingsPreset(cfg, p))
+}
+
+func TestApplySettingsPresetReverts(t *testing.T) {
+	cfg := newSettingsPresetTestConfig()
+	_, err := cfg.Set(DiskSize, 40)
+	require.NoError(t, err)
+
+	p := SettingsPreset{
+		Name: "test",
+		Settings: []PresetSetting{
+			{Key: DiskSize, Value: 50},
+			{Key: HTTPProxy, Value: "http://proxy:3128"},
+			{Key: DisableUpdateCheck, Value: "maybe"},
+		},
+	}
+	_, _, err = ApplySettingsPreset(cfg, p)
+	assert.EqualError(t, err, "Settings preset 'test' not applied: Value 'maybe' for configuration property 'disable-update-check' is invalid, reason: must be true or false")
+	assert.Equal(t, 40, cfg.Get(DiskSize).AsInt())
+	assert.True(t, cfg.Get(HTTPProxy).IsDefault)
+}
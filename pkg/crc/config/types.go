@@ -19,12 +19,14 @@ type Setting struct {
 	validationFn ValidationFnType
 	callbackFn   SetFn
 	Help         string
+	unit         Unit
 }
 
 type SettingValue struct {
 	Value     interface{}
 	Invalid   bool
 	IsDefault bool
+	Unit      Unit
 }
 
 func (v SettingValue) AsBool() bool {
@@ -39,6 +41,25 @@ func (v SettingValue) AsInt() int {
 	return cast.ToInt(v.Value)
 }
 
+// Origin is where the effective value of a setting comes from. The
+// precedence is: command line flag, environment variable, configuration
+// file, default value.
+type Origin string
+
+const (
+	OriginFlag       Origin = "command line flag"
+	OriginEnv        Origin = "environment variable"
+	OriginConfigFile Origin = "configuration file"
+	OriginDefault    Origin = "default"
+)
+
+// OriginStorage is implemented by the storages which know where the value
+// of a setting comes from. The second return value is the flag, the
+// environment variable or the file which sets it.
+type OriginStorage interface {
+	Origin(key string) (Origin, string)
+}
+
 // validationFnType takes the key, value as args and checks if valid
 type ValidationFnType func(interface{}) (bool, string)
 type SetFn func(string, interface{}) string
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	units "github.com/docker/go-units"
+	"github.com/spf13/cast"
+)
+
+// Unit is the unit in which the value of a setting is stored. The size
+// settings also accept values with a unit, like '12GiB' or '80g', and the
+// duration settings accept any duration, like '90m', they are stored in
+// their canonical form.
+type Unit string
+
+const (
+	NoUnit   Unit = ""
+	MiB      Unit = "MiB"
+	GiB      Unit = "GiB"
+	Duration Unit = "duration"
+)
+
+var unitBytes = map[Unit]int64{
+	MiB: units.MiB,
+	GiB: units.GiB,
+}
+
+// displayUnits are the units used to show the sizes, from the largest
+var displayUnits = []struct {
+	name  string
+	bytes int64
+}{
+	{"TiB", units.TiB},
+	{"GiB", units.GiB},
+	{"MiB", units.MiB},
+}
+
+// setUnit records the unit of the values of the setting name
+func (c *Config) setUnit(name string, unit Unit) {
+	setting, ok := c.settingsByName[name]
+	if !ok {
+		return
+	}
+	setting.unit = unit
+	c.settingsByName[name] = setting
+}
+
+// canonicalValue converts value to the unit of the setting, the other values
+// are returned unchanged
+func canonicalValue(value interface{}, unit Unit) (interface{}, error) {
+	switch {
+	case unit == Duration:
+		return parseDuration(value)
+	case unitBytes[unit] != 0 && !IsAutoSize(value):
+		return parseSize(value, unit)
+	default:
+		return value, nil
+	}
+}
+
+// parseSize returns the size in unit, an integer without unit is already a
+// size in unit. The units are binary, '1G' and '1GB' are 1GiB.
+func parseSize(value interface{}, unit Unit) (int, error) {
+	if size, err := cast.ToIntE(value); err == nil {
+		return size, nil
+	}
+	s := strings.TrimSpace(cast.ToString(value))
+	bytes, err := units.RAMInBytes(s)
+	if err != nil || s == "" || !unicode.IsLetter(rune(s[len(s)-1])) {
+		return 0, fmt.Errorf("'%s' is not a size like '12%s' or '12G'", s, unit)
+	}
+	if bytes%unitBytes[unit] != 0 {
+		return 0, fmt.Errorf("%s is not a whole number of %s", s, unit)
+	}
+	return int(bytes / unitBytes[unit]), nil
+}
+
+// formatSize returns the size in unit with the largest unit which keeps it
+// whole, for instance 12GiB for 12288 MiB
+func formatSize(size int, unit Unit) string {
+	bytes := int64(size) * unitBytes[unit]
+	for _, displayUnit := range displayUnits {
+		if displayUnit.bytes < unitBytes[unit] {
+			break
+		}
+		if bytes != 0 && bytes%displayUnit.bytes == 0 {
+			return fmt.Sprintf("%d%s", bytes/displayUnit.bytes, displayUnit.name)
+		}
+	}
+	return fmt.Sprintf("%d%s", size, unit)
+}
+
+// parseDuration returns the canonical form of the duration, the empty string
+// is kept as it disables the settings using it
+func parseDuration(value interface{}) (string, error) {
+	s := strings.TrimSpace(cast.ToString(value))
+	if s == "" {
+		return "", nil
+	}
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return "", fmt.Errorf("'%s' is not a duration like '90s', '15m' or '2h'", s)
+	}
+	return formatDuration(duration), nil
+}
+
+// formatDuration is time.Duration.String() without the zero minutes and
+// seconds, 2h instead of 2h0m0s
+func formatDuration(duration time.Duration) string {
+	if duration == 0 || duration%time.Second != 0 {
+		return duration.String()
+	}
+	var s string
+	if duration < 0 {
+		s = "-"
+		duration = -duration
+	}
+	if hours := duration / time.Hour; hours > 0 {
+		s += fmt.Sprintf("%dh", hours)
+	}
+	if minutes := duration % time.Hour / time.Minute; minutes > 0 {
+		s += fmt.Sprintf("%dm", minutes)
+	}
+	if seconds := duration % time.Minute / time.Second; seconds > 0 {
+		s += fmt.Sprintf("%ds", seconds)
+	}
+	return s
+}
+
+// String returns the value with its unit, as shown by 'crc config get' and
+// 'crc config view'
+func (v SettingValue) String() string {
+	if size, ok := v.Value.(int); ok && unitBytes[v.Unit] != 0 {
+		return formatSize(size, v.Unit)
+	}
+	return v.AsString()
+}
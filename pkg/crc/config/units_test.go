@@ -0,0 +1,84 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSize(t *testing.T) {
+	for value, expected := range map[interface{}]int{
+		12288:    12288,
+		"12288":  12288,
+		"12GiB":  12288,
+		"12G":    12288,
+		"12gb":   12288,
+		"1.5GiB": 1536,
+		"2TiB":   2097152,
+	} {
+		size, err := parseSize(value, MiB)
+		assert.NoError(t, err, value)
+		assert.Equal(t, expected, size, value)
+	}
+
+	size, err := parseSize("80g", GiB)
+	assert.NoError(t, err)
+	assert.Equal(t, 80, size)
+
+	_, err = parseSize("1500MiB", GiB)
+	assert.EqualError(t, err, "1500MiB is not a whole number of GiB")
+	_, err = parseSize("12 apples", MiB)
+	assert.EqualError(t, err, "'12 apples' is not a size like '12MiB' or '12G'")
+	_, err = parseSize("1.5", MiB)
+	assert.Error(t, err)
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "12GiB", formatSize(12288, MiB))
+	assert.Equal(t, "9000MiB", formatSize(9000, MiB))
+	assert.Equal(t, "2TiB", formatSize(2048, GiB))
+	assert.Equal(t, "31GiB", formatSize(31, GiB))
+	assert.Equal(t, "0GiB", formatSize(0, GiB))
+}
+
+func TestParseDuration(t *testing.T) {
+	for value, expected := range map[string]string{
+		"":       "",
+		"15m":    "15m",
+		"90m":    "1h30m",
+		"3600s":  "1h",
+		"1h0m5s": "1h5s",
+		"1.5s":   "1.5s",
+	} {
+		duration, err := parseDuration(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, expected, duration, value)
+	}
+	_, err := parseDuration("15 minutes")
+	assert.EqualError(t, err, "'15 minutes' is not a duration like '90s', '15m' or '2h'")
+}
+
+func TestSetAndGetWithUnits(t *testing.T) {
+	cfg := New(NewEmptyInMemoryStorage())
+	cfg.AddSetting(Memory, 9216, func(interface{}) (bool, string) { return true, "" }, SuccessfullyApplied, "")
+	cfg.setUnit(Memory, MiB)
+	cfg.AddSetting(AutoStopAfter, "", ValidateAutoStopAfter, SuccessfullyApplied, "")
+	cfg.setUnit(AutoStopAfter, Duration)
+
+	assert.Equal(t, "9GiB", cfg.Get(Memory).String())
+	_, err := cfg.Set(Memory, "12GiB")
+	require.NoError(t, err)
+	assert.Equal(t, 12288, cfg.Get(Memory).AsInt())
+	assert.Equal(t, "12GiB", cfg.Get(Memory).String())
+	_, err = cfg.Set(Memory, "twelve")
+	assert.EqualError(t, err, "Value 'twelve' for configuration property 'memory' is invalid, reason: 'twelve' is not a size like '12MiB' or '12G'")
+
+	_, err = cfg.Set(AutoStopAfter, "120m")
+	require.NoError(t, err)
+	assert.Equal(t, "2h", cfg.Get(AutoStopAfter).String())
+	assert.Equal(t, 2*time.Hour, GetDuration(cfg, AutoStopAfter))
+	_, err = cfg.Set(AutoStopAfter, "30s")
+	assert.EqualError(t, err, "Value '30s' for configuration property 'auto-stop-after' is invalid, reason: must be at least one minute")
+}
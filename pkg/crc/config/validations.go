@@ -2,12 +2,18 @@ package config
 
 import (
 	"fmt"
+	"regexp"
+	"runtime"
 	"strings"
+	"time"
 
 	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/ignition"
 	"github.com/code-ready/crc/pkg/crc/network"
 	crcpreset "github.com/code-ready/crc/pkg/crc/preset"
+	"github.com/code-ready/crc/pkg/crc/ssh"
 	"github.com/code-ready/crc/pkg/crc/validation"
+	"github.com/code-ready/crc/pkg/crc/version"
 	"github.com/spf13/cast"
 )
 
@@ -43,6 +49,9 @@ func ValidateDiskSize(value interface{}) (bool, string) {
 
 // ValidateCPUs checks if provided cpus count is valid in the config
 func ValidateCPUs(value interface{}, preset crcpreset.Preset) (bool, string) {
+	if IsAutoSize(value) {
+		return true, ""
+	}
 	v, err := cast.ToIntE(value)
 	if err != nil {
 		return false, fmt.Sprintf("requires integer value >= %d", constants.GetDefaultCPUs(preset))
@@ -53,8 +62,47 @@ func ValidateCPUs(value interface{}, preset crcpreset.Preset) (bool, string) {
 	return true, ""
 }
 
+// ValidateWorkers checks if the number of worker nodes is supported
+func ValidateWorkers(value interface{}, preset crcpreset.Preset, mode network.Mode) (bool, string) {
+	workers, err := cast.ToIntE(value)
+	if err != nil || workers < 0 || workers > constants.MaxWorkers {
+		return false, fmt.Sprintf("requires integer value between 0 and %d", constants.MaxWorkers)
+	}
+	if workers == 0 {
+		return true, ""
+	}
+	if preset != crcpreset.OpenShift {
+		return false, fmt.Sprintf("worker nodes are only supported with the %s preset", crcpreset.OpenShift)
+	}
+	if mode != network.SystemNetworkingMode {
+		return false, fmt.Sprintf("worker nodes are only supported with the %s network mode", network.SystemNetworkingMode)
+	}
+	return true, ""
+}
+
+// ValidateWorkerCPUs checks if the cpus count of the worker nodes is valid
+func ValidateWorkerCPUs(value interface{}) (bool, string) {
+	v, err := cast.ToIntE(value)
+	if err != nil || v < constants.DefaultWorkerCPUs {
+		return false, fmt.Sprintf("requires integer value >= %d", constants.DefaultWorkerCPUs)
+	}
+	return true, ""
+}
+
+// ValidateWorkerMemory checks if the memory of the worker nodes is valid
+func ValidateWorkerMemory(value interface{}) (bool, string) {
+	v, err := cast.ToIntE(value)
+	if err != nil || v < constants.DefaultWorkerMemory {
+		return false, fmt.Sprintf("requires integer value in MiB >= %d", constants.DefaultWorkerMemory)
+	}
+	return true, ""
+}
+
 // ValidateMemory checks if provided memory is valid in the config
-func ValidateMemory(value interface{}, preset crcpreset.Preset) (bool, string) {
+func ValidateMemory(value interface{}, preset crcpreset.Preset, monitoring bool) (bool, string) {
+	if IsAutoSize(value) {
+		return true, ""
+	}
 	v, err := cast.ToIntE(value)
 	if err != nil {
 		return false, fmt.Sprintf("requires integer value in MiB >= %d", constants.GetDefaultMemory(preset))
@@ -62,6 +110,26 @@ func ValidateMemory(value interface{}, preset crcpreset.Preset) (bool, string) {
 	if err := validation.ValidateMemory(v, preset); err != nil {
 		return false, err.Error()
 	}
+	if monitoring {
+		if err := validation.ValidateMonitoringMemory(v); err != nil {
+			return false, fmt.Sprintf("%s, or disable %s", err.Error(), EnableClusterMonitoring)
+		}
+	}
+	return true, ""
+}
+
+// ValidateEnableClusterMonitoring checks if the memory setting is enough to
+// run the cluster monitoring of the OpenShift preset
+func ValidateEnableClusterMonitoring(value interface{}, preset crcpreset.Preset, memory interface{}) (bool, string) {
+	if ok, msg := ValidateBool(value); !ok {
+		return ok, msg
+	}
+	if !cast.ToBool(value) || preset != crcpreset.OpenShift || IsAutoSize(memory) {
+		return true, ""
+	}
+	if err := validation.ValidateMonitoringMemory(cast.ToInt(memory)); err != nil {
+		return false, fmt.Sprintf("%s, set %s first", err.Error(), Memory)
+	}
 	return true, ""
 }
 
@@ -81,6 +149,14 @@ func ValidateIPAddress(value interface{}) (bool, string) {
 	return true, ""
 }
 
+// ValidateNameServers checks if the provided nameservers are valid
+func ValidateNameServers(value interface{}) (bool, string) {
+	if _, err := network.ParseNameServers(cast.ToString(value)); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
 // ValidatePath checks if provided path is exist
 func ValidatePath(value interface{}) (bool, string) {
 	if err := validation.ValidatePath(cast.ToString(value)); err != nil {
@@ -89,6 +165,110 @@ func ValidatePath(value interface{}) (bool, string) {
 	return true, ""
 }
 
+// ValidateHugePages checks if the huge pages take at most half of the memory
+// of the instance
+func ValidateHugePages(value interface{}, memory int) (bool, string) {
+	pages, err := cast.ToIntE(value)
+	if err != nil {
+		return false, fmt.Sprintf("could not convert '%s' to integer", value)
+	}
+	if pages < 0 {
+		return false, "must be a positive number of pages, or 0 to reserve none"
+	}
+	if pages*HugePageSize > memory/2 {
+		return false, fmt.Sprintf("the %d MiB of huge pages must be at most half of the %d MiB of memory", pages*HugePageSize, memory)
+	}
+	return true, ""
+}
+
+func ValidateCgroupsVersion(value interface{}) (bool, string) {
+	switch cast.ToString(value) {
+	case CgroupsDefault, CgroupsV1, CgroupsV2:
+		return true, ""
+	}
+	return false, fmt.Sprintf("must be %s, %s or %s", CgroupsDefault, CgroupsV1, CgroupsV2)
+}
+
+func ValidateUpdateChannel(value interface{}) (bool, string) {
+	switch cast.ToString(value) {
+	case version.StableChannel, version.CandidateChannel:
+		return true, ""
+	}
+	return false, fmt.Sprintf("must be %s or %s", version.StableChannel, version.CandidateChannel)
+}
+
+func ValidateSysctls(value interface{}) (bool, string) {
+	if _, err := ParseSysctls(cast.ToString(value)); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+var sysctlKeyRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+([./][A-Za-z0-9_-]+)+$`)
+
+// ParseSysctls parses a comma-separated list of key=value kernel parameters
+func ParseSysctls(value string) (map[string]string, error) {
+	sysctls := map[string]string{}
+	for _, item := range splitList(value) {
+		fields := strings.SplitN(item, "=", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid kernel parameter '%s', it must be like 'vm.max_map_count=262144'", item)
+		}
+		key, val := strings.TrimSpace(fields[0]), strings.TrimSpace(fields[1])
+		if val == "" || !sysctlKeyRegex.MatchString(key) {
+			return nil, fmt.Errorf("invalid kernel parameter '%s', it must be like 'vm.max_map_count=262144'", item)
+		}
+		if strings.ContainsAny(val, "\n\r") {
+			return nil, fmt.Errorf("invalid value of the kernel parameter %s", key)
+		}
+		sysctls[key] = val
+	}
+	return sysctls, nil
+}
+
+// ValidateExtraIgnition checks if the value is empty, or an Ignition
+// configuration which crc can apply
+func ValidateExtraIgnition(value interface{}) (bool, string) {
+	path := cast.ToString(value)
+	if path == "" {
+		return true, ""
+	}
+	if err := validation.ValidatePath(path); err != nil {
+		return false, err.Error()
+	}
+	if _, err := ignition.Load(path); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// ValidateSSHKey checks if the value is empty, to use a generated key, or a
+// private key usable by crc
+func ValidateSSHKey(value interface{}) (bool, string) {
+	path := cast.ToString(value)
+	if path == "" {
+		return true, ""
+	}
+	if err := validation.ValidatePath(path); err != nil {
+		return false, err.Error()
+	}
+	if err := ssh.ValidateKeyPair(path); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// ValidateSSHKeyType checks if the value is a type of key which crc can
+// generate
+func ValidateSSHKeyType(value interface{}) (bool, string) {
+	for _, keyType := range ssh.KeyTypes() {
+		if cast.ToString(value) == keyType {
+			return true, ""
+		}
+	}
+	return false, fmt.Sprintf("must be %s", strings.Join(ssh.KeyTypes(), ", "))
+}
+
 // ValidateHTTPProxy checks if given URI is valid for a HTTP proxy
 func ValidateHTTPProxy(value interface{}) (bool, string) {
 	if err := network.ValidateProxyURL(cast.ToString(value), false); err != nil {
@@ -105,6 +285,15 @@ func ValidateHTTPSProxy(value interface{}) (bool, string) {
 	return true, ""
 }
 
+// ValidateProxyAuthMethod checks if the proxy authentication method is
+// supported on this host
+func ValidateProxyAuthMethod(value interface{}) (bool, string) {
+	if err := network.ValidateProxyAuthMethod(cast.ToString(value)); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
 // ValidateNoProxy checks if the NoProxy string has the correct format
 func ValidateNoProxy(value interface{}) (bool, string) {
 	if strings.Contains(cast.ToString(value), " ") {
@@ -120,6 +309,239 @@ func ValidateYesNo(value interface{}) (bool, string) {
 	return false, "must be yes or no"
 }
 
+// ValidateWatchdogPolicy checks if the value is a policy of the vm-watchdog
+// setting
+func ValidateWatchdogPolicy(value interface{}) (bool, string) {
+	switch cast.ToString(value) {
+	case WatchdogOff, WatchdogNotifyOnly, WatchdogRestartVM:
+		return true, ""
+	}
+	return false, fmt.Sprintf("must be %s, %s or %s", WatchdogOff, WatchdogNotifyOnly, WatchdogRestartVM)
+}
+
+// ValidateHostSleepAction checks if the value is an action of the
+// host-sleep-action setting
+func ValidateHostSleepAction(value interface{}) (bool, string) {
+	switch cast.ToString(value) {
+	case HostSleepNone, HostSleepSync, HostSleepStop:
+		return true, ""
+	}
+	return false, fmt.Sprintf("must be %s, %s or %s", HostSleepNone, HostSleepSync, HostSleepStop)
+}
+
+// ValidateAutoStopAfter checks if the idle duration is empty, to disable
+// auto-stop, or at least one minute
+func ValidateAutoStopAfter(value interface{}) (bool, string) {
+	s := cast.ToString(value)
+	if s == "" {
+		return true, ""
+	}
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return false, "must be a duration like '60m' or '2h'"
+	}
+	if duration < time.Minute {
+		return false, "must be at least one minute"
+	}
+	return true, ""
+}
+
+// ValidateTimeout checks if the timeout is empty, to use the default timeout,
+// or at least one second
+func ValidateTimeout(value interface{}) (bool, string) {
+	s := cast.ToString(value)
+	if s == "" {
+		return true, ""
+	}
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return false, "must be a duration like '90s' or '15m'"
+	}
+	if duration < time.Second {
+		return false, "must be at least one second"
+	}
+	return true, ""
+}
+
+// ValidateClusterDomain checks if the domain is empty, to use the domain of
+// the bundle, or a valid domain name
+func ValidateClusterDomain(value interface{}) (bool, string) {
+	domain := cast.ToString(value)
+	if domain == "" {
+		return true, ""
+	}
+	if err := validation.ValidateClusterDomain(domain); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// ValidatePVPoolSize checks if the PV pool fits in the disk of the instance,
+// next to the space needed by the cluster
+func ValidatePVPoolSize(value interface{}, diskSize int) (bool, string) {
+	size, err := cast.ToIntE(value)
+	if err != nil {
+		return false, fmt.Sprintf("could not convert '%s' to integer", value)
+	}
+	if size < 0 {
+		return false, "must be a positive size in GiB, or 0 to disable the pool"
+	}
+	if available := diskSize - constants.DefaultDiskSize; size > available {
+		return false, fmt.Sprintf("must be less than or equal to %d GiB, increase %s to make room for a larger pool", available, DiskSize)
+	}
+	return true, ""
+}
+
+// ValidateEnableGPU checks if GPU passthrough can be enabled, it is only
+// implemented for the libvirt driver
+func ValidateEnableGPU(value interface{}) (bool, string) {
+	enable, err := cast.ToBoolE(value)
+	if err != nil {
+		return false, "must be true or false"
+	}
+	if enable && runtime.GOOS != "linux" {
+		return false, "GPU passthrough is only supported on Linux"
+	}
+	return true, ""
+}
+
+// ValidateEnableImageCache checks if the image cache can be mounted in the
+// instance, it needs the virtiofs devices of the libvirt driver
+func ValidateEnableImageCache(value interface{}, driver string) (bool, string) {
+	enable, err := cast.ToBoolE(value)
+	if err != nil {
+		return false, "must be true or false"
+	}
+	if !enable {
+		return true, ""
+	}
+	if runtime.GOOS != "linux" || driver != LibvirtVMDriver {
+		return false, fmt.Sprintf("the image cache is only supported with the %s driver on Linux", LibvirtVMDriver)
+	}
+	return true, ""
+}
+
+// ValidateVMDriver checks if the virtual machine driver is available on this
+// platform, the qemu and vfkit drivers have no network of their own and only
+// work with the user network mode, the wsl2 driver uses the network of WSL2
+// which has no vsock path to the daemon
+func ValidateVMDriver(value interface{}, mode network.Mode) (bool, string) {
+	driver := cast.ToString(value)
+	supported := SupportedVMDrivers()
+	found := false
+	for _, supportedDriver := range supported {
+		if driver == supportedDriver {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false, fmt.Sprintf("must be %s", strings.Join(supported, " or "))
+	}
+	if (driver == QemuVMDriver || driver == VfkitVMDriver) && mode != network.UserNetworkingMode {
+		return false, fmt.Sprintf("the %s driver needs %s set to '%s'", driver, NetworkMode, network.UserNetworkingMode)
+	}
+	if driver == WSL2VMDriver && mode != network.SystemNetworkingMode {
+		return false, fmt.Sprintf("the %s driver needs %s set to '%s'", driver, NetworkMode, network.SystemNetworkingMode)
+	}
+	return true, ""
+}
+
+// ValidateLibvirtURI checks if the libvirt driver can use the connection, the
+// session of the user cannot create the network of the system network mode
+func ValidateLibvirtURI(value interface{}, driver string, mode network.Mode) (bool, string) {
+	uri := cast.ToString(value)
+	if uri != constants.LibvirtSystemURI && uri != constants.LibvirtSessionURI {
+		return false, fmt.Sprintf("must be %s or %s", constants.LibvirtSystemURI, constants.LibvirtSessionURI)
+	}
+	if uri == constants.LibvirtSystemURI {
+		return true, ""
+	}
+	if runtime.GOOS != "linux" || driver != LibvirtVMDriver {
+		return false, fmt.Sprintf("%s is only supported with the %s driver on Linux", constants.LibvirtSessionURI, LibvirtVMDriver)
+	}
+	if mode != network.UserNetworkingMode {
+		return false, fmt.Sprintf("%s needs %s set to '%s'", constants.LibvirtSessionURI, NetworkMode, network.UserNetworkingMode)
+	}
+	return true, ""
+}
+
+// ValidateEnableRosetta checks if Rosetta can run the x86_64 executables of
+// the instance, it is only exposed by Virtualization.framework on Apple
+// silicon
+func ValidateEnableRosetta(value interface{}, driver string) (bool, string) {
+	enable, err := cast.ToBoolE(value)
+	if err != nil {
+		return false, "must be true or false"
+	}
+	if !enable {
+		return true, ""
+	}
+	if runtime.GOOS != "darwin" || runtime.GOARCH != "arm64" {
+		return false, "Rosetta is only available on Apple silicon"
+	}
+	if driver != VfkitVMDriver {
+		return false, fmt.Sprintf("Rosetta needs %s set to '%s'", VMDriver, VfkitVMDriver)
+	}
+	return true, ""
+}
+
+// ValidateEnableNestedVirtualization checks if nested virtualization can be
+// enabled, the macOS drivers do not support it
+func ValidateEnableNestedVirtualization(value interface{}) (bool, string) {
+	enable, err := cast.ToBoolE(value)
+	if err != nil {
+		return false, "must be true or false"
+	}
+	if enable && runtime.GOOS == "darwin" {
+		return false, "nested virtualization is not supported on macOS"
+	}
+	return true, ""
+}
+
+// ValidateSharedDirs checks if the comma-separated directories can be mounted
+// in the instance
+func ValidateSharedDirs(value interface{}) (bool, string) {
+	if err := validation.ValidateSharedDirs(splitList(cast.ToString(value))); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// ValidateEnableOperators checks if the comma-separated operator package
+// names are valid
+func ValidateEnableOperators(value interface{}) (bool, string) {
+	if err := validation.ValidateOperators(splitList(cast.ToString(value))); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// ValidatePort checks if the value is a port number
+func ValidatePort(value interface{}) (bool, string) {
+	if _, err := network.ParsePort(cast.ToString(value)); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// ValidateMetricsPort checks if the value is a port, or empty to disable the
+// TCP listener of the metrics
+func ValidateMetricsPort(value interface{}) (bool, string) {
+	if cast.ToString(value) == "" {
+		return true, ""
+	}
+	return ValidatePort(value)
+}
+
+// ValidateIngressPorts checks if the value is a pair of different ports
+func ValidateIngressPorts(value interface{}) (bool, string) {
+	if _, _, err := network.ParseIngressPorts(cast.ToString(value)); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
 func validatePreset(value interface{}) (bool, string) {
 	_, err := crcpreset.ParsePresetE(cast.ToString(value))
 	if err != nil {
@@ -0,0 +1,207 @@
+package config
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/preset"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePVPoolSize(t *testing.T) {
+	valid, _ := ValidatePVPoolSize(0, 31)
+	assert.True(t, valid)
+	valid, _ = ValidatePVPoolSize("20", 51)
+	assert.True(t, valid)
+	valid, msg := ValidatePVPoolSize(21, 51)
+	assert.False(t, valid)
+	assert.Equal(t, "must be less than or equal to 20 GiB, increase disk-size to make room for a larger pool", msg)
+	valid, _ = ValidatePVPoolSize(-1, 51)
+	assert.False(t, valid)
+	valid, _ = ValidatePVPoolSize("ten", 51)
+	assert.False(t, valid)
+}
+
+func TestValidateEnableClusterMonitoring(t *testing.T) {
+	valid, _ := ValidateEnableClusterMonitoring(true, preset.OpenShift, 14336)
+	assert.True(t, valid)
+	valid, _ = ValidateEnableClusterMonitoring(false, preset.OpenShift, 9216)
+	assert.True(t, valid)
+	valid, _ = ValidateEnableClusterMonitoring(true, preset.OpenShift, AutoSize)
+	assert.True(t, valid)
+	valid, msg := ValidateEnableClusterMonitoring(true, preset.OpenShift, 9216)
+	assert.False(t, valid)
+	assert.Equal(t, "requires memory in MiB >= 14336 to run the cluster monitoring, set memory first", msg)
+	valid, _ = ValidateEnableClusterMonitoring("maybe", preset.OpenShift, 14336)
+	assert.False(t, valid)
+}
+
+func TestValidateVMDriver(t *testing.T) {
+	valid, msg := ValidateVMDriver("virtualbox", network.UserNetworkingMode)
+	assert.False(t, valid)
+	assert.Equal(t, fmt.Sprintf("must be %s", strings.Join(SupportedVMDrivers(), " or ")), msg)
+	switch {
+	case runtime.GOOS == "linux":
+		valid, _ = ValidateVMDriver("libvirt", network.SystemNetworkingMode)
+		assert.True(t, valid)
+		valid, _ = ValidateVMDriver("qemu", network.UserNetworkingMode)
+		assert.True(t, valid)
+		valid, msg = ValidateVMDriver("qemu", network.SystemNetworkingMode)
+		assert.False(t, valid)
+		assert.Equal(t, "the qemu driver needs network-mode set to 'user'", msg)
+	case runtime.GOOS == "darwin" && runtime.GOARCH == "arm64":
+		valid, _ = ValidateVMDriver("vfkit", network.UserNetworkingMode)
+		assert.True(t, valid)
+		valid, msg = ValidateVMDriver("vfkit", network.SystemNetworkingMode)
+		assert.False(t, valid)
+		assert.Equal(t, "the vfkit driver needs network-mode set to 'user'", msg)
+		valid, _ = ValidateVMDriver("hyperkit", network.SystemNetworkingMode)
+		assert.False(t, valid)
+	case runtime.GOOS == "windows":
+		valid, _ = ValidateVMDriver("hyperv", network.UserNetworkingMode)
+		assert.True(t, valid)
+		valid, _ = ValidateVMDriver("wsl2", network.SystemNetworkingMode)
+		assert.True(t, valid)
+		valid, msg = ValidateVMDriver("wsl2", network.UserNetworkingMode)
+		assert.False(t, valid)
+		assert.Equal(t, "the wsl2 driver needs network-mode set to 'system'", msg)
+	}
+}
+
+func TestValidateEnableRosetta(t *testing.T) {
+	valid, _ := ValidateEnableRosetta(false, LibvirtVMDriver)
+	assert.True(t, valid)
+	valid, _ = ValidateEnableRosetta("maybe", VfkitVMDriver)
+	assert.False(t, valid)
+	valid, msg := ValidateEnableRosetta(true, HyperkitVMDriver)
+	assert.False(t, valid)
+	if runtime.GOOS != "darwin" || runtime.GOARCH != "arm64" {
+		assert.Equal(t, "Rosetta is only available on Apple silicon", msg)
+		return
+	}
+	assert.Equal(t, "Rosetta needs vm-driver set to 'vfkit'", msg)
+	valid, _ = ValidateEnableRosetta(true, VfkitVMDriver)
+	assert.True(t, valid)
+}
+
+func TestValidateLibvirtURI(t *testing.T) {
+	valid, _ := ValidateLibvirtURI("qemu:///system", LibvirtVMDriver, network.SystemNetworkingMode)
+	assert.True(t, valid)
+	valid, msg := ValidateLibvirtURI("qemu+ssh://host/system", LibvirtVMDriver, network.SystemNetworkingMode)
+	assert.False(t, valid)
+	assert.Equal(t, "must be qemu:///system or qemu:///session", msg)
+	valid, msg = ValidateLibvirtURI("qemu:///session", LibvirtVMDriver, network.SystemNetworkingMode)
+	assert.False(t, valid)
+	if runtime.GOOS != "linux" {
+		assert.Equal(t, "qemu:///session is only supported with the libvirt driver on Linux", msg)
+		return
+	}
+	assert.Equal(t, "qemu:///session needs network-mode set to 'user'", msg)
+	valid, _ = ValidateLibvirtURI("qemu:///session", LibvirtVMDriver, network.UserNetworkingMode)
+	assert.True(t, valid)
+}
+
+func TestValidateTimeout(t *testing.T) {
+	valid, _ := ValidateTimeout("")
+	assert.True(t, valid)
+	valid, _ = ValidateTimeout("15m")
+	assert.True(t, valid)
+	valid, msg := ValidateTimeout("15")
+	assert.False(t, valid)
+	assert.Equal(t, "must be a duration like '90s' or '15m'", msg)
+	valid, msg = ValidateTimeout("500ms")
+	assert.False(t, valid)
+	assert.Equal(t, "must be at least one second", msg)
+}
+
+func TestValidateMetricsPort(t *testing.T) {
+	valid, _ := ValidateMetricsPort("")
+	assert.True(t, valid)
+	valid, _ = ValidateMetricsPort(9090)
+	assert.True(t, valid)
+	valid, _ = ValidateMetricsPort("70000")
+	assert.False(t, valid)
+}
+
+func TestValidateHugePages(t *testing.T) {
+	valid, _ := ValidateHugePages(0, 9216)
+	assert.True(t, valid)
+	valid, _ = ValidateHugePages("2304", 9216)
+	assert.True(t, valid)
+	valid, msg := ValidateHugePages(2305, 9216)
+	assert.False(t, valid)
+	assert.Equal(t, "the 4610 MiB of huge pages must be at most half of the 9216 MiB of memory", msg)
+	valid, _ = ValidateHugePages(-1, 9216)
+	assert.False(t, valid)
+}
+
+func TestValidateUpdateChannel(t *testing.T) {
+	valid, _ := ValidateUpdateChannel("candidate")
+	assert.True(t, valid)
+	valid, msg := ValidateUpdateChannel("nightly")
+	assert.False(t, valid)
+	assert.Equal(t, "must be stable or candidate", msg)
+}
+
+func TestParseSysctls(t *testing.T) {
+	sysctls, err := ParseSysctls("vm.max_map_count=262144, net.ipv4.ip_local_port_range = 32768 60999")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"vm.max_map_count":             "262144",
+		"net.ipv4.ip_local_port_range": "32768 60999",
+	}, sysctls)
+	sysctls, err = ParseSysctls("")
+	assert.NoError(t, err)
+	assert.Empty(t, sysctls)
+	_, err = ParseSysctls("vm.max_map_count")
+	assert.Error(t, err)
+	_, err = ParseSysctls("max_map_count=1")
+	assert.Error(t, err)
+	_, err = ParseSysctls("vm.max_map_count=")
+	assert.Error(t, err)
+}
+
+func TestVPNCompatNetworkMode(t *testing.T) {
+	cfg := New(NewEmptyInMemoryStorage())
+	RegisterSettings(cfg)
+	defaultMode := GetNetworkMode(cfg)
+
+	_, err := cfg.Set(VPNCompat, true)
+	assert.NoError(t, err)
+	assert.Equal(t, network.UserNetworkingMode, GetNetworkMode(cfg))
+
+	// the network mode set by the user is kept
+	_, err = cfg.Set(NetworkMode, string(network.SystemNetworkingMode))
+	assert.NoError(t, err)
+	assert.Equal(t, network.SystemNetworkingMode, GetNetworkMode(cfg))
+
+	_, err = cfg.Unset(NetworkMode)
+	assert.NoError(t, err)
+	_, err = cfg.Set(VPNCompat, false)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultMode, GetNetworkMode(cfg))
+}
+
+func TestHostPorts(t *testing.T) {
+	cfg := New(NewEmptyInMemoryStorage())
+	RegisterSettings(cfg)
+	assert.Equal(t, network.DefaultHostPorts(), GetHostPorts(cfg))
+
+	_, err := cfg.Set(APIPort, 16443)
+	assert.NoError(t, err)
+	_, err = cfg.Set(IngressPorts, "8080,8443")
+	assert.NoError(t, err)
+	_, err = cfg.Set(SSHPort, 2022)
+	assert.NoError(t, err)
+	assert.Equal(t, network.HostPorts{API: 16443, HTTP: 8080, HTTPS: 8443, SSH: 2022}, GetHostPorts(cfg))
+
+	_, err = cfg.Set(APIPort, 70000)
+	assert.Error(t, err)
+	_, err = cfg.Set(IngressPorts, "8443")
+	assert.Error(t, err)
+	_, err = cfg.Set(IngressPorts, "8443,8443")
+	assert.Error(t, err)
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"sigs.k8s.io/yaml"
 )
 
 type ViperStorage struct {
@@ -37,7 +38,7 @@ func (c *ViperStorage) viperInstance() (*viper.Viper, error) {
 	}
 	v := viper.New()
 	v.SetConfigFile(c.configFile)
-	v.SetConfigType("json")
+	v.SetConfigType(configType(c.configFile))
 	v.SetEnvPrefix(c.envPrefix)
 	// Replaces '-' in flags with '_' in env variables
 	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
@@ -68,20 +69,12 @@ func (c *ViperStorage) Set(key string, value interface{}) error {
 	if err := ensureConfigFileExists(c.configFile); err != nil {
 		return err
 	}
-	in, err := ioutil.ReadFile(c.configFile)
+	cfg, err := readConfigFile(c.configFile)
 	if err != nil {
 		return err
 	}
-	var cfg map[string]interface{}
-	if err := json.Unmarshal(in, &cfg); err != nil {
-		return err
-	}
 	cfg[key] = value
-	bin, err := json.MarshalIndent(cfg, "", "  ")
-	if err != nil {
-		return err
-	}
-	return atomicWrite(bin, c.configFile)
+	return writeConfigFile(cfg, c.configFile)
 }
 
 func (c *ViperStorage) Unset(key string) error {
@@ -90,20 +83,40 @@ func (c *ViperStorage) Unset(key string) error {
 	if err := ensureConfigFileExists(c.configFile); err != nil {
 		return err
 	}
-	in, err := ioutil.ReadFile(c.configFile)
+	cfg, err := readConfigFile(c.configFile)
 	if err != nil {
 		return err
 	}
-	var cfg map[string]interface{}
-	if err := json.Unmarshal(in, &cfg); err != nil {
-		return err
-	}
 	delete(cfg, key)
-	bin, err := json.MarshalIndent(cfg, "", "  ")
-	if err != nil {
-		return err
+	return writeConfigFile(cfg, c.configFile)
+}
+
+// EnvVar returns the name of the environment variable overriding the key,
+// for example CRC_HTTP_PROXY for http-proxy
+func (c *ViperStorage) EnvVar(key string) string {
+	return strings.ToUpper(c.envPrefix + "_" + strings.ReplaceAll(key, "-", "_"))
+}
+
+// Origin returns where viper finds the value of key, following its
+// precedence: flag, environment variable, configuration file, default
+func (c *ViperStorage) Origin(key string) (Origin, string) {
+	c.storeLock.Lock()
+	defer c.storeLock.Unlock()
+	if c.flagSet != nil {
+		if flag := c.flagSet.Lookup(key); flag != nil && flag.Changed {
+			return OriginFlag, "--" + key
+		}
+	}
+	// viper ignores empty environment variables
+	if value, ok := os.LookupEnv(c.EnvVar(key)); ok && value != "" {
+		return OriginEnv, c.EnvVar(key)
 	}
-	return atomicWrite(bin, c.configFile)
+	if cfg, err := readConfigFile(c.configFile); err == nil {
+		if _, ok := cfg[key]; ok {
+			return OriginConfigFile, c.configFile
+		}
+	}
+	return OriginDefault, ""
 }
 
 // BindFlagset binds a flagset to their respective config properties
@@ -114,6 +127,50 @@ func (c *ViperStorage) BindFlagSet(flagSet *pflag.FlagSet) error {
 	return nil
 }
 
+// configType returns the format of the configuration file from its extension,
+// the default configuration file is JSON
+func configType(file string) string {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		return "yaml"
+	default:
+		return "json"
+	}
+}
+
+func readConfigFile(file string) (map[string]interface{}, error) {
+	in, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if configType(file) == "yaml" {
+		if in, err = yaml.YAMLToJSON(in); err != nil {
+			return nil, err
+		}
+	}
+	var cfg map[string]interface{}
+	if err := json.Unmarshal(in, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg == nil {
+		cfg = make(map[string]interface{})
+	}
+	return cfg, nil
+}
+
+func writeConfigFile(cfg map[string]interface{}, file string) error {
+	bin, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if configType(file) == "yaml" {
+		if bin, err = yaml.JSONToYAML(bin); err != nil {
+			return err
+		}
+	}
+	return atomicWrite(bin, file)
+}
+
 // ensureConfigFileExists creates the viper config file if it does not exists
 func ensureConfigFileExists(file string) error {
 	_, err := os.Stat(file)
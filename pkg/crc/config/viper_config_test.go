@@ -67,6 +67,28 @@ func TestViperConfigSetAndGet(t *testing.T) {
 	assert.JSONEq(t, `{"cpus":5}`, string(bin))
 }
 
+func TestViperConfigSetAndGetAutoSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	configFile := filepath.Join(dir, "crc.json")
+
+	config, err := newTestConfig(configFile, "CRC")
+	require.NoError(t, err)
+
+	_, err = config.Set(cpus, AutoSize)
+	assert.NoError(t, err)
+
+	assert.Equal(t, SettingValue{
+		Value:     AutoSize,
+		IsDefault: false,
+	}, config.Get(cpus))
+
+	bin, err := ioutil.ReadFile(configFile)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"cpus":"auto"}`, string(bin))
+}
+
 func TestViperConfigUnsetAndGet(t *testing.T) {
 	dir, err := ioutil.TempDir("", "cfg")
 	require.NoError(t, err)
@@ -326,3 +348,57 @@ func TestTwoInstancesSetAndUnsetSameConfiguration(t *testing.T) {
 	assert.Equal(t, 4, config2.Get(cpus).Value)
 	assert.Equal(t, 4, config1.Get(cpus).Value)
 }
+
+func TestSuggestedValues(t *testing.T) {
+	config := New(NewEmptyInMemoryStorage())
+	config.AddSetting(Preset, string(preset.OpenShift), ValidateString, SuccessfullyApplied, "")
+	config.AddSetting(ConsentTelemetry, "", ValidateYesNo, SuccessfullyApplied, "")
+	config.AddSetting(DisableUpdateCheck, false, ValidateBool, SuccessfullyApplied, "")
+	config.AddSetting(nameServer, "", ValidateIPAddress, SuccessfullyApplied, "")
+
+	assert.Equal(t, []string{"openshift", "podman"}, config.SuggestedValues(Preset))
+	assert.Equal(t, []string{"yes", "no"}, config.SuggestedValues(ConsentTelemetry))
+	assert.Equal(t, []string{"true", "false"}, config.SuggestedValues(DisableUpdateCheck))
+	assert.Nil(t, config.SuggestedValues(nameServer))
+	assert.Nil(t, config.SuggestedValues("foo"))
+}
+
+func TestViperConfigOrigin(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "crc.json")
+	storage, err := NewViperStorage(configFile, "CRC")
+	require.NoError(t, err)
+	config := New(storage)
+	config.AddSetting(cpus, 4, func(value interface{}) (bool, string) {
+		return ValidateCPUs(value, preset.OpenShift)
+	}, RequiresRestartMsg, "")
+	flagSet := pflag.NewFlagSet("start", pflag.ExitOnError)
+	flagSet.IntP(cpus, "c", 4, "")
+	require.NoError(t, storage.BindFlagSet(flagSet))
+
+	origin, source, err := config.Origin(cpus)
+	require.NoError(t, err)
+	assert.Equal(t, OriginDefault, origin)
+	assert.Empty(t, source)
+
+	_, err = config.Set(cpus, 5)
+	require.NoError(t, err)
+	origin, source, _ = config.Origin(cpus)
+	assert.Equal(t, OriginConfigFile, origin)
+	assert.Equal(t, configFile, source)
+
+	require.NoError(t, os.Setenv("CRC_CPUS", "6"))
+	defer os.Unsetenv("CRC_CPUS")
+	origin, source, _ = config.Origin(cpus)
+	assert.Equal(t, OriginEnv, origin)
+	assert.Equal(t, "CRC_CPUS", source)
+	assert.Equal(t, 6, config.Get(cpus).Value)
+
+	require.NoError(t, flagSet.Set(cpus, "7"))
+	origin, source, _ = config.Origin(cpus)
+	assert.Equal(t, OriginFlag, origin)
+	assert.Equal(t, "--cpus", source)
+	assert.Equal(t, 7, config.Get(cpus).Value)
+
+	_, _, err = config.Origin("foo")
+	assert.EqualError(t, err, "Configuration property 'foo' does not exist")
+}
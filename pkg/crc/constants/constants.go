@@ -14,6 +14,16 @@ const (
 	DefaultName     = "crc"
 	DefaultDiskSize = 31
 
+	// Worker nodes are experimental, each of them needs DefaultWorkerMemory on
+	// top of the memory of the control plane instance
+	MaxWorkers          = 3
+	DefaultWorkerCPUs   = 2
+	DefaultWorkerMemory = 6144
+
+	// MonitoringMemory is the minimum memory in MiB of an OpenShift
+	// instance running the cluster monitoring
+	MonitoringMemory = 14336
+
 	DefaultSSHUser = "core"
 	DefaultSSHPort = 22
 
@@ -31,8 +41,13 @@ const (
 	DaemonHTTPEndpoint        = "http://unix/api"
 	DefaultPodmanNamedPipe    = `\\.\pipe\crc-podman`
 
-	VSockGateway = "192.168.127.1"
-	VsockSSHPort = 2222
+	VSockGateway          = "192.168.127.1"
+	VSockVirtualMachineIP = "192.168.127.2"
+	VSockHostVirtualIP    = "192.168.127.254"
+	VsockSSHPort          = 2222
+	// the daemon relays the HTTP requests of the instance to the proxies
+	// OpenShift cannot use
+	ProxyRelayPort = 3128
 
 	OkdPullSecret = `{"auths":{"fake":{"auth": "Zm9vOmJhcgo="}}}` // #nosec G101
 
@@ -65,18 +80,34 @@ func GetAdminHelperURL() string {
 func defaultBundleForOs(preset crcpreset.Preset) map[string]string {
 	if preset == crcpreset.Podman {
 		return map[string]string{
-			"darwin":  fmt.Sprintf("crc_podman_hyperkit_%s_%s.crcbundle", version.GetPodmanVersion(), runtime.GOARCH),
+			"darwin":  fmt.Sprintf("crc_podman_%s_%s_%s.crcbundle", darwinBundleDriver(), version.GetPodmanVersion(), runtime.GOARCH),
 			"linux":   fmt.Sprintf("crc_podman_libvirt_%s_%s.crcbundle", version.GetPodmanVersion(), runtime.GOARCH),
 			"windows": fmt.Sprintf("crc_podman_hyperv_%s_%s.crcbundle", version.GetPodmanVersion(), runtime.GOARCH),
 		}
 	}
 	return map[string]string{
-		"darwin":  fmt.Sprintf("crc_hyperkit_%s_%s.crcbundle", version.GetBundleVersion(), runtime.GOARCH),
+		"darwin":  fmt.Sprintf("crc_%s_%s_%s.crcbundle", darwinBundleDriver(), version.GetBundleVersion(), runtime.GOARCH),
 		"linux":   fmt.Sprintf("crc_libvirt_%s_%s.crcbundle", version.GetBundleVersion(), runtime.GOARCH),
 		"windows": fmt.Sprintf("crc_hyperv_%s_%s.crcbundle", version.GetBundleVersion(), runtime.GOARCH),
 	}
 }
 
+// darwinBundleDriver returns the driver of the macOS bundles, hyperkit is not
+// available on Apple silicon
+func darwinBundleDriver() string {
+	if runtime.GOARCH == "arm64" {
+		return "vfkit"
+	}
+	return "hyperkit"
+}
+
+// connections of the libvirt driver, the session of the user runs the
+// instance without privileges
+const (
+	LibvirtSystemURI  = "qemu:///system"
+	LibvirtSessionURI = "qemu:///session"
+)
+
 func GetDefaultBundle(preset crcpreset.Preset) string {
 	bundles := defaultBundleForOs(preset)
 	return bundles[runtime.GOOS]
@@ -86,15 +117,27 @@ var (
 	CrcBaseDir         = filepath.Join(GetHomeDir(), ".crc")
 	crcBinDir          = filepath.Join(CrcBaseDir, "bin")
 	CrcOcBinDir        = filepath.Join(crcBinDir, "oc")
+	OcVersionsDir      = filepath.Join(crcBinDir, "oc-versions")
 	CrcSymlinkPath     = filepath.Join(crcBinDir, "crc")
 	ConfigPath         = filepath.Join(CrcBaseDir, ConfigFile)
 	LogFilePath        = filepath.Join(CrcBaseDir, LogFile)
 	DaemonLogFilePath  = filepath.Join(CrcBaseDir, DaemonLogFile)
 	MachineBaseDir     = CrcBaseDir
 	MachineCacheDir    = filepath.Join(MachineBaseDir, "cache")
+	BundleChunksDir    = filepath.Join(CrcBaseDir, "chunks")
 	MachineInstanceDir = filepath.Join(MachineBaseDir, "machines")
 	DaemonSocketPath   = filepath.Join(CrcBaseDir, "crc.sock")
 	KubeconfigFilePath = filepath.Join(MachineInstanceDir, DefaultName, "kubeconfig")
+	PortForwardsPath   = filepath.Join(CrcBaseDir, "port-forwards.json")
+	UpgradeStatePath   = filepath.Join(CrcBaseDir, "last-version.json")
+	StatsPath          = filepath.Join(CrcBaseDir, "stats.json")
+	WatchdogEventsPath = filepath.Join(CrcBaseDir, "watchdog-events.json")
+	AuditLogPath       = filepath.Join(CrcBaseDir, "audit.jsonl")
+	SetupManifestPath  = filepath.Join(CrcBaseDir, "setup-manifest.json")
+	PreflightPluginDir = filepath.Join(CrcBaseDir, "preflight.d")
+	HooksDir           = filepath.Join(CrcBaseDir, "hooks.d")
+	UpgradeBackupDir   = filepath.Join(CrcBaseDir, "upgrade-backup")
+	ImageCacheDir      = filepath.Join(CrcBaseDir, "image-cache")
 )
 
 func GetDefaultBundlePath(preset crcpreset.Preset) string {
@@ -147,6 +190,17 @@ func GetKubeAdminPasswordPath() string {
 	return filepath.Join(MachineInstanceDir, DefaultName, "kubeadmin-password")
 }
 
+// GetConsoleLogPath returns the file where the drivers log the serial console
+// of the instance
+func GetConsoleLogPath() string {
+	return filepath.Join(MachineInstanceDir, DefaultName, "console.log")
+}
+
+// GetUsersPath returns the file of the users added with 'crc users'
+func GetUsersPath() string {
+	return filepath.Join(MachineInstanceDir, DefaultName, "users.json")
+}
+
 // TODO: follow the same pattern as oc and podman above
 func GetCRCMacTrayDownloadURL() string {
 	return fmt.Sprintf(CRCMacTrayDownloadURL, version.GetTrayVersion())
@@ -168,6 +222,28 @@ func GetDefaultCPUs(preset crcpreset.Preset) int {
 	}
 }
 
+// GetMaxAutoCPUs is the upper limit of the number of CPUs picked for the
+// 'auto' value of the cpus setting
+func GetMaxAutoCPUs(preset crcpreset.Preset) int {
+	switch preset {
+	case crcpreset.Podman:
+		return 4
+	default:
+		return 8
+	}
+}
+
+// GetMaxAutoMemory is the upper limit in MiB of the memory picked for the
+// 'auto' value of the memory setting
+func GetMaxAutoMemory(preset crcpreset.Preset) int {
+	switch preset {
+	case crcpreset.Podman:
+		return 4096
+	default:
+		return 16384
+	}
+}
+
 func GetDefaultMemory(preset crcpreset.Preset) int {
 	switch preset {
 	case crcpreset.OpenShift:
@@ -10,6 +10,8 @@ const (
 	OcExecutableName           = "oc"
 	PodmanRemoteExecutableName = "podman"
 	TrayExecutableName         = "CodeReady Containers.app"
+	// the DNS resolver of the daemon for the host, port 53 would require root
+	HostDNSResolverPort = 55353
 )
 
 var (
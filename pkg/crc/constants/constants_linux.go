@@ -8,6 +8,8 @@ const (
 	OcExecutableName           = "oc"
 	PodmanRemoteExecutableName = "podman-remote"
 	TapSocketPath              = ""
+	// the DNS resolver of the daemon for the host, port 53 would require root
+	HostDNSResolverPort = 55353
 )
 
 var DaemonHTTPSocketPath = filepath.Join(CrcBaseDir, "crc-http.sock")
@@ -5,4 +5,7 @@ const (
 	PodmanRemoteExecutableName = "podman.exe"
 	TapSocketPath              = ""
 	DaemonHTTPNamedPipe        = `\\.\pipe\crc-http`
+	// the DNS resolver of the daemon for the host, the NRPT rules cannot use
+	// another port
+	HostDNSResolverPort = 53
 )
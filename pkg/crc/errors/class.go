@@ -0,0 +1,51 @@
+package errors
+
+import "errors"
+
+// Class is the kind of failure of a command. Each class has its own exit
+// code, so that the scripts running crc can react to the failure without
+// parsing the messages.
+type Class string
+
+const (
+	ValidationFailure Class = "validation"
+	PreflightFailure  Class = "preflight"
+	PullSecretFailure Class = "pull-secret"
+	DaemonUnreachable Class = "daemon-unreachable"
+	BundleFailure     Class = "bundle"
+	VMCreateFailure   Class = "vm-create"
+	ClusterTimeout    Class = "cluster-timeout"
+)
+
+// ClassError is an error of a known class
+type ClassError struct {
+	Class Class
+	Err   error
+}
+
+func (e *ClassError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ClassError) Unwrap() error {
+	return e.Err
+}
+
+// WithClass records the class of err, it returns nil when err is nil
+func WithClass(class Class, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ClassError{Class: class, Err: err}
+}
+
+// ClassOf returns the class of err, or an empty string when the class of
+// the failure is not known. The outermost class wins when err wraps several
+// errors with a class.
+func ClassOf(err error) Class {
+	var classErr *ClassError
+	if errors.As(err, &classErr) {
+		return classErr.Class
+	}
+	return ""
+}
@@ -0,0 +1,20 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassOf(t *testing.T) {
+	assert.Equal(t, Class(""), ClassOf(errors.New("failed")))
+	assert.Nil(t, WithClass(BundleFailure, nil))
+
+	err := fmt.Errorf("Error getting bundle metadata: %w", WithClass(BundleFailure, errors.New("corrupted")))
+	assert.Equal(t, BundleFailure, ClassOf(err))
+	assert.EqualError(t, err, "Error getting bundle metadata: corrupted")
+
+	assert.Equal(t, ClusterTimeout, ClassOf(WithClass(ClusterTimeout, err)))
+}
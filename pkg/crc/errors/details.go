@@ -0,0 +1,106 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+)
+
+const docsURL = "https://code-ready.github.io/crc/"
+
+// docsTopic returns the URL of a topic of the getting started guide, topic is
+// the id of the section without its context
+func docsTopic(topic string) string {
+	return fmt.Sprintf("%s#%s_gsg", docsURL, topic)
+}
+
+// Details tell the user and the integrations, like the tray, how to act on
+// an error
+type Details struct {
+	// Code identifies the error, it does not change between releases
+	Code        string `json:"code"`
+	Remediation string `json:"remediation,omitempty"`
+	DocsURL     string `json:"docsURL,omitempty"`
+}
+
+// DetailedError is an error with its details
+type DetailedError struct {
+	Err     error
+	Details Details
+}
+
+func (e *DetailedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DetailedError) Unwrap() error {
+	return e.Err
+}
+
+// WithDetails attaches details to err, it returns nil when err is nil
+func WithDetails(err error, details Details) error {
+	if err == nil {
+		return nil
+	}
+	return &DetailedError{Err: err, Details: details}
+}
+
+// classDetails are the details of the errors which only have a class
+var classDetails = map[Class]Details{
+	ValidationFailure: {
+		Code:        string(ValidationFailure),
+		Remediation: "Check the values of the flags and of the settings listed by 'crc config view'",
+		DocsURL:     docsTopic("configuring-the-instance"),
+	},
+	PreflightFailure: {
+		Code:        string(PreflightFailure),
+		Remediation: "Run 'crc setup' to prepare the host",
+		DocsURL:     docsTopic("setting-up-codeready-containers"),
+	},
+	PullSecretFailure: {
+		Code:        string(PullSecretFailure),
+		Remediation: "Use --pull-secret-file or the CRC_PULL_SECRET_FILE environment variable to provide the pull secret",
+		DocsURL:     docsTopic("starting-the-instance"),
+	},
+	DaemonUnreachable: {
+		Code:        string(DaemonUnreachable),
+		Remediation: "Start the daemon with 'crc daemon', or run 'crc setup' to install it",
+		DocsURL:     docsTopic("setting-up-codeready-containers"),
+	},
+	BundleFailure: {
+		Code:        string(BundleFailure),
+		Remediation: "Run 'crc setup' to download the bundle of this release, and 'crc delete' when the instance uses another bundle",
+		DocsURL:     docsTopic("troubleshooting-bundle-version-mismatch"),
+	},
+	VMCreateFailure: {
+		Code:        string(VMCreateFailure),
+		Remediation: "Check that the host meets the requirements, then run 'crc setup' and 'crc start' again",
+		DocsURL:     docsTopic("minimum-system-requirements"),
+	},
+	ClusterTimeout: {
+		Code:        string(ClusterTimeout),
+		Remediation: "Check the state of the cluster with 'crc status', a slow host may need more time",
+		DocsURL:     docsTopic("troubleshooting-unknown-issues"),
+	},
+}
+
+// DetailsOf returns the details of err, it returns nil when err has none.
+// The details attached with WithDetails win over the ones of a failing
+// preflight check, which win over the ones of the class of the error.
+func DetailsOf(err error) *Details {
+	var detailedErr *DetailedError
+	if errors.As(err, &detailedErr) {
+		return &detailedErr.Details
+	}
+	var preflightErr *PreflightError
+	if errors.As(err, &preflightErr) && preflightErr.CheckID != "" {
+		return &Details{
+			Code:        preflightErr.CheckID,
+			Remediation: preflightErr.Remediation,
+			DocsURL:     docsTopic("setting-up-codeready-containers"),
+		}
+	}
+	if details, ok := classDetails[ClassOf(err)]; ok {
+		return &details
+	}
+	return nil
+}
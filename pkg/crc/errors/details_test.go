@@ -0,0 +1,39 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetailsOf(t *testing.T) {
+	assert.Nil(t, DetailsOf(errors.New("failed")))
+	assert.Nil(t, DetailsOf(nil))
+
+	err := fmt.Errorf("Error waiting for apiserver: %w", WithClass(ClusterTimeout, errors.New("timeout")))
+	assert.Equal(t, &Details{
+		Code:        "cluster-timeout",
+		Remediation: "Check the state of the cluster with 'crc status', a slow host may need more time",
+		DocsURL:     "https://code-ready.github.io/crc/#troubleshooting-unknown-issues_gsg",
+	}, DetailsOf(err))
+
+	details := Details{Code: "proxy-ca", Remediation: "Set proxy-ca-file"}
+	assert.Equal(t, &details, DetailsOf(WithClass(ValidationFailure, WithDetails(errors.New("invalid CA"), details))))
+}
+
+func TestDetailsOfPreflightError(t *testing.T) {
+	err := WithClass(PreflightFailure, &PreflightError{
+		Err:         errors.New("libvirt is not installed"),
+		CheckID:     "check-libvirt-installed",
+		Remediation: "Run 'crc setup' to fix it (Installing libvirt service and dependencies)",
+	})
+	assert.Equal(t, &Details{
+		Code:        "check-libvirt-installed",
+		Remediation: "Run 'crc setup' to fix it (Installing libvirt service and dependencies)",
+		DocsURL:     "https://code-ready.github.io/crc/#setting-up-codeready-containers_gsg",
+	}, ToSerializableError(err).Details())
+
+	assert.Equal(t, "preflight", DetailsOf(WithClass(PreflightFailure, errors.New("2 checks failed"))).Code)
+}
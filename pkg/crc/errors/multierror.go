@@ -19,6 +19,11 @@ const VMNotExist vmNotExist = "Machine does not exist. Use 'crc start' to create
 
 type PreflightError struct {
 	Err error
+	// CheckID identifies the failing check, 'skip-<CheckID>' is the setting
+	// to skip it
+	CheckID string
+	// Remediation tells the user how to fix the failing check
+	Remediation string
 }
 
 func (p *PreflightError) Error() string {
@@ -20,3 +20,9 @@ func (e *SerializableError) MarshalJSON() ([]byte, error) {
 func (e *SerializableError) Unwrap() error {
 	return e.error
 }
+
+// Details returns the code, the remediation and the documentation of the
+// error, or nil when it has none
+func (e *SerializableError) Details() *Details {
+	return DetailsOf(e.error)
+}
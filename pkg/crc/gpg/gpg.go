@@ -0,0 +1,127 @@
+// Package gpg checks the files of the mirror signed with the release key of
+// Red Hat
+package gpg
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/download"
+	"golang.org/x/crypto/openpgp"           //nolint:staticcheck // deprecated, no replacement in golang.org/x/crypto
+	"golang.org/x/crypto/openpgp/clearsign" //nolint:staticcheck // deprecated, no replacement in golang.org/x/crypto
+)
+
+const (
+	// releaseKeyFingerprint is the fingerprint of the release key listed on
+	// https://access.redhat.com/security/team/key, the embedded key is only
+	// trusted when it has this fingerprint
+	releaseKeyFingerprint = "567E347AD0044ADE55BA8A5F199E2F91FD431D51"
+
+	// signatureExtension is the extension of the signature of the
+	// sha256sum.txt file of a directory of the mirror, it is either the
+	// clearsigned sha256sum.txt file or its detached signature
+	signatureExtension = ".sig"
+)
+
+// KeyRing has the public keys of the signatures
+type KeyRing struct {
+	entities openpgp.EntityList
+}
+
+// ReadKeyRing reads an armored public key, which is only trusted when it has
+// the fingerprint
+func ReadKeyRing(armoredKey []byte, fingerprint string) (*KeyRing, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(bytes.NewReader(armoredKey))
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read the public key: %w", err)
+	}
+	for _, entity := range entities {
+		if fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint) == fingerprint {
+			return &KeyRing{entities: openpgp.EntityList{entity}}, nil
+		}
+	}
+	return nil, fmt.Errorf("The public key does not have the fingerprint %s", fingerprint)
+}
+
+// releaseKey is the armored release key of Red Hat, 'make update-release-key'
+// fetches it from https://www.redhat.com/security/data/fd431d51.txt
+//
+//go:embed release-key.asc
+var releaseKey []byte
+
+// ReleaseKeyRing reads the embedded release key of Red Hat and checks its
+// fingerprint
+func ReleaseKeyRing() (*KeyRing, error) {
+	keyRing, err := ReadKeyRing(releaseKey, releaseKeyFingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read the release key of Red Hat: %w", err)
+	}
+	return keyRing, nil
+}
+
+// IsClearsigned returns true if data is a clearsigned message
+func IsClearsigned(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN PGP SIGNED MESSAGE-----"))
+}
+
+// VerifyClearsigned checks the signature of a clearsigned message and
+// returns the message
+func (keyRing *KeyRing) VerifyClearsigned(data []byte) ([]byte, error) {
+	block, _ := clearsign.Decode(data)
+	if block == nil {
+		return nil, errors.New("not a clearsigned message")
+	}
+	if _, err := openpgp.CheckDetachedSignature(keyRing.entities, bytes.NewReader(block.Bytes), block.ArmoredSignature.Body); err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+	return block.Plaintext, nil
+}
+
+// VerifyDetached checks the detached signature of message, it is armored or
+// binary
+func (keyRing *KeyRing) VerifyDetached(message, signature []byte) error {
+	var err error
+	if bytes.HasPrefix(bytes.TrimSpace(signature), []byte("-----BEGIN PGP SIGNATURE-----")) {
+		_, err = openpgp.CheckArmoredDetachedSignature(keyRing.entities, bytes.NewReader(message), bytes.NewReader(signature))
+	} else {
+		_, err = openpgp.CheckDetachedSignature(keyRing.entities, bytes.NewReader(message), bytes.NewReader(signature))
+	}
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	return nil
+}
+
+// RemoteChecksum returns the sha256 sum of fileURL from the sha256sum.txt
+// file of its directory, once its signature, sha256sum.txt.sig, is checked
+// with the keys of keyRing
+func (keyRing *KeyRing) RemoteChecksum(fileURL string) ([]byte, error) {
+	checksumsURL, err := download.ChecksumsURL(fileURL)
+	if err != nil {
+		return nil, err
+	}
+	signatureURL := checksumsURL + signatureExtension
+	signature, err := download.InMemory(signatureURL)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot download the signature of %s: %w", checksumsURL, err)
+	}
+	var checksums []byte
+	if IsClearsigned(signature) {
+		checksums, err = keyRing.VerifyClearsigned(signature)
+	} else {
+		checksums, err = download.InMemory(checksumsURL)
+		if err == nil {
+			err = keyRing.VerifyDetached(checksums, signature)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Cannot verify %s with %s: %w", checksumsURL, signatureURL, err)
+	}
+	sha256sum, err := download.FindChecksum(checksums, fileURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w at %s", err, checksumsURL)
+	}
+	return sha256sum, nil
+}
@@ -0,0 +1,107 @@
+package gpg_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/gpg"
+	"github.com/code-ready/crc/pkg/crc/gpg/gpgtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const checksums = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef  crc_libvirt_4.10.3_amd64.crcbundle\n"
+
+func newSigner(t *testing.T) *gpgtest.Signer {
+	signer, err := gpgtest.NewSigner()
+	require.NoError(t, err)
+	return signer
+}
+
+func newKeyRing(t *testing.T, signer *gpgtest.Signer) *gpg.KeyRing {
+	keyRing, err := signer.KeyRing()
+	require.NoError(t, err)
+	return keyRing
+}
+
+func TestReadKeyRing(t *testing.T) {
+	signer := newSigner(t)
+	armoredKey, err := signer.ArmoredPublicKey()
+	require.NoError(t, err)
+
+	_, err = gpg.ReadKeyRing(armoredKey, signer.Fingerprint())
+	assert.NoError(t, err)
+	_, err = gpg.ReadKeyRing(armoredKey, "567E347AD0044ADE55BA8A5F199E2F91FD431D51")
+	assert.EqualError(t, err, "The public key does not have the fingerprint 567E347AD0044ADE55BA8A5F199E2F91FD431D51")
+	_, err = gpg.ReadKeyRing([]byte("not a key"), signer.Fingerprint())
+	assert.Error(t, err)
+}
+
+func TestVerifyClearsigned(t *testing.T) {
+	signer := newSigner(t)
+	signed, err := signer.ClearSign([]byte(checksums))
+	require.NoError(t, err)
+	assert.True(t, gpg.IsClearsigned(signed))
+
+	message, err := newKeyRing(t, signer).VerifyClearsigned(signed)
+	require.NoError(t, err)
+	assert.Equal(t, checksums, string(message))
+
+	_, err = newKeyRing(t, newSigner(t)).VerifyClearsigned(signed)
+	assert.Error(t, err)
+	_, err = newKeyRing(t, signer).VerifyClearsigned([]byte(checksums))
+	assert.EqualError(t, err, "not a clearsigned message")
+}
+
+func TestVerifyDetached(t *testing.T) {
+	signer := newSigner(t)
+	signature, err := signer.DetachSign([]byte(checksums))
+	require.NoError(t, err)
+	assert.False(t, gpg.IsClearsigned(signature))
+
+	assert.NoError(t, newKeyRing(t, signer).VerifyDetached([]byte(checksums), signature))
+	assert.Error(t, newKeyRing(t, signer).VerifyDetached([]byte("tampered "+checksums), signature))
+	assert.Error(t, newKeyRing(t, newSigner(t)).VerifyDetached([]byte(checksums), signature))
+}
+
+func TestRemoteChecksum(t *testing.T) {
+	signer := newSigner(t)
+	clearsigned, err := signer.ClearSign([]byte(checksums))
+	require.NoError(t, err)
+	signature, err := signer.DetachSign([]byte(checksums))
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/clearsigned/sha256sum.txt.sig", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(clearsigned)
+	})
+	mux.HandleFunc("/detached/sha256sum.txt", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(checksums))
+	})
+	mux.HandleFunc("/detached/sha256sum.txt.sig", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(signature)
+	})
+	mux.HandleFunc("/tampered/sha256sum.txt", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "%064d  crc_libvirt_4.10.3_amd64.crcbundle\n", 0)
+	})
+	mux.HandleFunc("/tampered/sha256sum.txt.sig", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(signature)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	keyRing := newKeyRing(t, signer)
+	for _, dir := range []string{"clearsigned", "detached"} {
+		sha256sum, err := keyRing.RemoteChecksum(server.URL + "/" + dir + "/crc_libvirt_4.10.3_amd64.crcbundle")
+		assert.NoError(t, err, dir)
+		assert.Equal(t, "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", fmt.Sprintf("%x", sha256sum), dir)
+	}
+	_, err = keyRing.RemoteChecksum(server.URL + "/clearsigned/crc_hyperv_4.10.3_amd64.crcbundle")
+	assert.EqualError(t, err, "crc_hyperv_4.10.3_amd64.crcbundle is not listed in sha256sum.txt at "+server.URL+"/clearsigned/sha256sum.txt")
+	_, err = keyRing.RemoteChecksum(server.URL + "/tampered/crc_libvirt_4.10.3_amd64.crcbundle")
+	assert.Error(t, err)
+	_, err = keyRing.RemoteChecksum(server.URL + "/unsigned/crc_libvirt_4.10.3_amd64.crcbundle")
+	assert.Error(t, err)
+}
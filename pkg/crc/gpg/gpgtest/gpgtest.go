@@ -0,0 +1,82 @@
+// Package gpgtest signs files with a key generated for the tests
+package gpgtest
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/gpg"
+	"golang.org/x/crypto/openpgp"           //nolint:staticcheck // deprecated, no replacement in golang.org/x/crypto
+	"golang.org/x/crypto/openpgp/armor"     //nolint:staticcheck // deprecated, no replacement in golang.org/x/crypto
+	"golang.org/x/crypto/openpgp/clearsign" //nolint:staticcheck // deprecated, no replacement in golang.org/x/crypto
+)
+
+// Signer has a generated key pair
+type Signer struct {
+	entity *openpgp.Entity
+}
+
+// NewSigner generates a key pair
+func NewSigner() (*Signer, error) {
+	entity, err := openpgp.NewEntity("crc test", "", "crc-test@example.com", nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Signer{entity: entity}, nil
+}
+
+// Fingerprint is the fingerprint of the public key
+func (signer *Signer) Fingerprint() string {
+	return fmt.Sprintf("%X", signer.entity.PrimaryKey.Fingerprint)
+}
+
+// ArmoredPublicKey returns the public key as gpg --armor --export prints it
+func (signer *Signer) ArmoredPublicKey() ([]byte, error) {
+	var buf bytes.Buffer
+	writer, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := signer.entity.Serialize(writer); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// KeyRing returns the key ring of the public key
+func (signer *Signer) KeyRing() (*gpg.KeyRing, error) {
+	armoredKey, err := signer.ArmoredPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	return gpg.ReadKeyRing(armoredKey, signer.Fingerprint())
+}
+
+// ClearSign returns the clearsigned message, like gpg --clearsign
+func (signer *Signer) ClearSign(message []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer, err := clearsign.Encode(&buf, signer.entity.PrivateKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(message); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DetachSign returns the armored detached signature of message, like gpg
+// --armor --detach-sign
+func (signer *Signer) DetachSign(message []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&buf, signer.entity, bytes.NewReader(message), nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,139 @@
+// Package hooks runs the user commands configured to run after the instance
+// is started and before it is stopped.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+)
+
+type Phase string
+
+const (
+	PostStart Phase = "post-start"
+	PreStop   Phase = "pre-stop"
+)
+
+// Commands returns the hooks of phase: the configured command first, then
+// the executables of hooksDir whose name starts with the phase, such as
+// post-start-login.sh, in lexical order
+func Commands(phase Phase, configured, hooksDir string) []string {
+	var commands []string
+	if configured != "" {
+		commands = append(commands, configured)
+	}
+	entries, err := ioutil.ReadDir(hooksDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logging.Warnf("Cannot read hooks from %s: %v", hooksDir, err)
+		}
+		return commands
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), string(phase)) {
+			continue
+		}
+		if !isExecutable(entry) {
+			logging.Debugf("Ignoring hook %s, it is not executable", filepath.Join(hooksDir, entry.Name()))
+			continue
+		}
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		commands = append(commands, filepath.Join(hooksDir, name))
+	}
+	return commands
+}
+
+// Run runs the commands one after the other with env added to the
+// environment. The output of the commands is written to the log. A failing
+// hook doesn't prevent the next ones from running, the failures are
+// returned together.
+func Run(ctx context.Context, phase Phase, commands []string, env map[string]string) error {
+	var failures []string
+	for _, command := range commands {
+		logging.Infof("Running %s hook %s...", phase, command)
+		if err := run(ctx, phase, command, env); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", command, err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%s hooks failed:\n%s", phase, strings.Join(failures, "\n"))
+	}
+	return nil
+}
+
+func run(ctx context.Context, phase Phase, command string, env map[string]string) error {
+	cmd := exec.CommandContext(ctx, command) // #nosec G204
+	cmd.Env = append(os.Environ(), "CRC_HOOK="+string(phase))
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		cmd.Env = append(cmd.Env, key+"="+env[key])
+	}
+	output := &logWriter{prefix: filepath.Base(command)}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	err := cmd.Run()
+	output.flush()
+	return err
+}
+
+// logWriter writes the output of a hook to the log, one line at a time
+type logWriter struct {
+	lock   sync.Mutex
+	prefix string
+	buf    bytes.Buffer
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// incomplete line, wait for the rest of it
+			w.buf.Reset()
+			w.buf.WriteString(line)
+			return len(p), nil
+		}
+		logging.Debugf("[%s] %s", w.prefix, strings.TrimRight(line, "\r\n"))
+	}
+}
+
+func (w *logWriter) flush() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.buf.Len() > 0 {
+		logging.Debugf("[%s] %s", w.prefix, w.buf.String())
+		w.buf.Reset()
+	}
+}
+
+func isExecutable(info os.FileInfo) bool {
+	if runtime.GOOS == "windows" {
+		switch strings.ToLower(filepath.Ext(info.Name())) {
+		case ".exe", ".bat", ".cmd":
+			return true
+		}
+		return false
+	}
+	return info.Mode().IsRegular() && info.Mode().Perm()&0111 != 0
+}
@@ -0,0 +1,51 @@
+package hooks
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on Windows")
+	}
+	dir := t.TempDir()
+	for _, name := range []string{"post-start-20-registry.sh", "post-start-10-login.sh", "pre-stop-backup.sh"} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0700))
+	}
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "post-start-README"), []byte("not a hook"), 0600))
+
+	assert.Equal(t, []string{
+		"/usr/local/bin/configure-cluster",
+		filepath.Join(dir, "post-start-10-login.sh"),
+		filepath.Join(dir, "post-start-20-registry.sh"),
+	}, Commands(PostStart, "/usr/local/bin/configure-cluster", dir))
+	assert.Equal(t, []string{filepath.Join(dir, "pre-stop-backup.sh")}, Commands(PreStop, "", dir))
+	assert.Empty(t, Commands(PreStop, "", filepath.Join(dir, "missing")))
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on Windows")
+	}
+	dir := t.TempDir()
+	output := filepath.Join(dir, "output")
+	hook := filepath.Join(dir, "post-start-env.sh")
+	require.NoError(t, ioutil.WriteFile(hook, []byte("#!/bin/sh\necho \"$CRC_HOOK $KUBECONFIG\" > "+output+"\n"), 0700))
+	failing := filepath.Join(dir, "post-start-failing.sh")
+	require.NoError(t, ioutil.WriteFile(failing, []byte("#!/bin/sh\necho failing\nexit 3\n"), 0700))
+
+	err := Run(context.Background(), PostStart, []string{failing, hook}, map[string]string{"KUBECONFIG": "/tmp/kubeconfig"})
+	assert.EqualError(t, err, "post-start hooks failed:\n"+failing+": exit status 3")
+
+	// the failing hook doesn't prevent the next one from running
+	content, err := ioutil.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, "post-start /tmp/kubeconfig\n", string(content))
+}
@@ -0,0 +1,163 @@
+package ignition
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	unitsDir  = "/etc/systemd/system"
+	sysctlDir = "/etc/sysctl.d/"
+)
+
+// Runner runs commands in the instance, it is implemented by ssh.Runner
+type Runner interface {
+	RunPrivate(command string, args ...string) (string, string, error)
+	RunPrivileged(reason string, cmdAndArgs ...string) (string, string, error)
+	CopyData(data []byte, destFilename string, mode os.FileMode) error
+}
+
+// Apply applies the files, directories and units of config to the running
+// instance. The files and units are only written when their contents
+// changed, and the changed units are restarted. The kernel arguments are
+// left to the caller, they are only used once the instance is restarted.
+func Apply(runner Runner, config *Config) error {
+	for _, directory := range config.Storage.Directories {
+		if _, _, err := runner.RunPrivileged(fmt.Sprintf("Creating %s", directory.Path),
+			"install", "-d", "-m", fmt.Sprintf("0%o", directory.DirectoryMode()), directory.Path); err != nil {
+			return err
+		}
+		if err := chown(runner, directory.Path, directory.User, directory.Group); err != nil {
+			return err
+		}
+	}
+
+	sysctlChanged := false
+	for _, file := range config.Storage.Files {
+		data, err := file.Data()
+		if err != nil {
+			return err
+		}
+		changed, err := writeFile(runner, file.Path, data, file.FileMode())
+		if err != nil {
+			return err
+		}
+		if err := chown(runner, file.Path, file.User, file.Group); err != nil {
+			return err
+		}
+		if changed && strings.HasPrefix(file.Path, sysctlDir) {
+			sysctlChanged = true
+		}
+	}
+	if sysctlChanged {
+		if _, _, err := runner.RunPrivileged("Applying the sysctl settings", "sysctl", "--system"); err != nil {
+			return err
+		}
+	}
+
+	return applyUnits(runner, config.Systemd.Units)
+}
+
+func applyUnits(runner Runner, units []Unit) error {
+	changedUnits := map[string]bool{}
+	for _, unit := range units {
+		if unit.Contents != nil {
+			changed, err := writeFile(runner, path.Join(unitsDir, unit.Name), []byte(*unit.Contents), defaultFileMode)
+			if err != nil {
+				return err
+			}
+			if changed {
+				changedUnits[unit.Name] = true
+			}
+		}
+		for _, dropin := range unit.Dropins {
+			dropinDir := path.Join(unitsDir, unit.Name+".d")
+			if _, _, err := runner.RunPrivileged(fmt.Sprintf("Creating %s", dropinDir), "install", "-d", "-m", "0755", dropinDir); err != nil {
+				return err
+			}
+			contents := ""
+			if dropin.Contents != nil {
+				contents = *dropin.Contents
+			}
+			changed, err := writeFile(runner, path.Join(dropinDir, dropin.Name), []byte(contents), defaultFileMode)
+			if err != nil {
+				return err
+			}
+			if changed {
+				changedUnits[unit.Name] = true
+			}
+		}
+	}
+	if len(changedUnits) > 0 {
+		if _, _, err := runner.RunPrivileged("Reloading the systemd units", "systemctl", "daemon-reload"); err != nil {
+			return err
+		}
+	}
+
+	for _, unit := range units {
+		var err error
+		switch {
+		case unit.Mask != nil && *unit.Mask:
+			_, _, err = runner.RunPrivileged(fmt.Sprintf("Masking %s", unit.Name), "systemctl", "mask", "--now", unit.Name)
+		case unit.Enabled != nil && !*unit.Enabled:
+			_, _, err = runner.RunPrivileged(fmt.Sprintf("Disabling %s", unit.Name), "systemctl", "disable", "--now", unit.Name)
+		case unit.Enabled != nil && *unit.Enabled:
+			if _, _, err = runner.RunPrivileged(fmt.Sprintf("Enabling %s", unit.Name), "systemctl", "enable", unit.Name); err != nil {
+				break
+			}
+			action := "start"
+			if changedUnits[unit.Name] {
+				action = "restart"
+			}
+			_, _, err = runner.RunPrivileged(fmt.Sprintf("Starting %s", unit.Name), "systemctl", action, unit.Name)
+		case changedUnits[unit.Name]:
+			// the units which are neither enabled nor disabled are only
+			// restarted when they are already running
+			_, _, err = runner.RunPrivileged(fmt.Sprintf("Restarting %s", unit.Name), "systemctl", "try-restart", unit.Name)
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to apply the unit %s: %w", unit.Name, err)
+		}
+	}
+	return nil
+}
+
+// writeFile writes data to filePath when its contents differ, it returns
+// true when the file was written. The contents are not logged, the files
+// may contain secrets.
+func writeFile(runner Runner, filePath string, data []byte, mode int) (bool, error) {
+	current, _, err := runner.RunPrivate("sudo", "cat", filePath)
+	if err == nil && current == string(data) {
+		_, _, err := runner.RunPrivileged(fmt.Sprintf("Setting the permissions of %s", filePath), "chmod", fmt.Sprintf("0%o", mode), filePath)
+		return false, err
+	}
+	if _, _, err := runner.RunPrivileged(fmt.Sprintf("Creating the directory of %s", filePath), "mkdir", "-p", path.Dir(filePath)); err != nil {
+		return false, err
+	}
+	return true, runner.CopyData(data, filePath, os.FileMode(mode))
+}
+
+func chown(runner Runner, path string, user, group Owner) error {
+	owner := ownerName(user)
+	if g := ownerName(group); g != "" {
+		owner = fmt.Sprintf("%s:%s", owner, g)
+	}
+	if owner == "" {
+		return nil
+	}
+	_, _, err := runner.RunPrivileged(fmt.Sprintf("Changing the owner of %s", path), "chown", owner, path)
+	return err
+}
+
+func ownerName(owner Owner) string {
+	if owner.Name != "" {
+		return owner.Name
+	}
+	if owner.ID != nil {
+		return strconv.Itoa(*owner.ID)
+	}
+	return ""
+}
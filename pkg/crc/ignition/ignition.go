@@ -0,0 +1,267 @@
+package ignition
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Config is the subset of an Ignition v3 configuration which crc applies to
+// the running instance. The bundles are provisioned when they are built, so
+// the configuration is applied over ssh on every start instead of by
+// Ignition on the first boot.
+type Config struct {
+	Ignition        Metadata        `json:"ignition"`
+	Storage         Storage         `json:"storage,omitempty"`
+	Systemd         Systemd         `json:"systemd,omitempty"`
+	KernelArguments KernelArguments `json:"kernelArguments,omitempty"`
+}
+
+type Metadata struct {
+	Version string `json:"version"`
+}
+
+type Storage struct {
+	Directories []Directory `json:"directories,omitempty"`
+	Files       []File      `json:"files,omitempty"`
+}
+
+type Owner struct {
+	ID   *int   `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type Directory struct {
+	Path  string `json:"path"`
+	Mode  *int   `json:"mode,omitempty"`
+	User  Owner  `json:"user,omitempty"`
+	Group Owner  `json:"group,omitempty"`
+	// Overwrite is accepted for compatibility, the directories are always
+	// created again
+	Overwrite *bool `json:"overwrite,omitempty"`
+}
+
+type File struct {
+	Path     string   `json:"path"`
+	Mode     *int     `json:"mode,omitempty"`
+	User     Owner    `json:"user,omitempty"`
+	Group    Owner    `json:"group,omitempty"`
+	Contents Resource `json:"contents,omitempty"`
+	// Overwrite is accepted for compatibility, the files are always written
+	// again
+	Overwrite *bool `json:"overwrite,omitempty"`
+}
+
+type Resource struct {
+	Source      *string `json:"source,omitempty"`
+	Compression *string `json:"compression,omitempty"`
+}
+
+type Systemd struct {
+	Units []Unit `json:"units,omitempty"`
+}
+
+type Unit struct {
+	Name     string   `json:"name"`
+	Enabled  *bool    `json:"enabled,omitempty"`
+	Mask     *bool    `json:"mask,omitempty"`
+	Contents *string  `json:"contents,omitempty"`
+	Dropins  []Dropin `json:"dropins,omitempty"`
+}
+
+type Dropin struct {
+	Name     string  `json:"name"`
+	Contents *string `json:"contents,omitempty"`
+}
+
+type KernelArguments struct {
+	ShouldExist    []string `json:"shouldExist,omitempty"`
+	ShouldNotExist []string `json:"shouldNotExist,omitempty"`
+}
+
+const (
+	defaultFileMode      = 0644
+	defaultDirectoryMode = 0755
+)
+
+// Load reads and validates the Ignition configuration of path
+func Load(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid Ignition configuration %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// Parse validates an Ignition configuration. The fields which crc cannot
+// apply, like passwd or the remote sources, are rejected instead of being
+// ignored.
+func Parse(data []byte) (*Config, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var config Config
+	if err := decoder.Decode(&config); err != nil {
+		return nil, fmt.Errorf("%v, only ignition.version, storage.directories, storage.files, systemd.units and kernelArguments are supported", err)
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (config *Config) validate() error {
+	minor, err := specMinorVersion(config.Ignition.Version)
+	if err != nil {
+		return err
+	}
+	for _, directory := range config.Storage.Directories {
+		if err := validatePath(directory.Path); err != nil {
+			return err
+		}
+	}
+	for _, file := range config.Storage.Files {
+		if err := validatePath(file.Path); err != nil {
+			return err
+		}
+		if _, err := file.Data(); err != nil {
+			return fmt.Errorf("Invalid contents of %s: %w", file.Path, err)
+		}
+	}
+	for _, unit := range config.Systemd.Units {
+		if err := validateUnitName(unit.Name); err != nil {
+			return err
+		}
+		for _, dropin := range unit.Dropins {
+			if !strings.HasSuffix(dropin.Name, ".conf") || !safeArgument.MatchString(dropin.Name) || strings.Contains(dropin.Name, "/") {
+				return fmt.Errorf("Invalid drop-in name '%s' of unit %s, it must be a file name ending with .conf", dropin.Name, unit.Name)
+			}
+		}
+	}
+	kargs := append(append([]string{}, config.KernelArguments.ShouldExist...), config.KernelArguments.ShouldNotExist...)
+	if minor < 3 && len(kargs) > 0 {
+		return fmt.Errorf("kernelArguments requires the version 3.3.0 of the specification or a later one")
+	}
+	for _, karg := range kargs {
+		if !safeArgument.MatchString(karg) {
+			return fmt.Errorf("Invalid kernel argument '%s', it must not contain spaces or quotes", karg)
+		}
+	}
+	return nil
+}
+
+// specMinorVersion returns the minor version of the specification, the
+// versions 3.0.0 to 3.4.0 are supported
+func specMinorVersion(version string) (int, error) {
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 || parts[0] != "3" || parts[2] != "0" {
+		return 0, fmt.Errorf("Unsupported Ignition specification version '%s', the versions 3.0.0 to 3.4.0 are supported", version)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil || minor < 0 || minor > 4 {
+		return 0, fmt.Errorf("Unsupported Ignition specification version '%s', the versions 3.0.0 to 3.4.0 are supported", version)
+	}
+	return minor, nil
+}
+
+// safeArgument matches the paths and the kernel arguments which can be passed
+// to the commands run in the instance without quoting
+var safeArgument = regexp.MustCompile(`^[A-Za-z0-9._@:,+=/-]+$`)
+
+func validatePath(p string) error {
+	if !path.IsAbs(p) || path.Clean(p) != p || !safeArgument.MatchString(p) {
+		return fmt.Errorf("Invalid path '%s', it must be absolute and clean, without spaces or quotes", p)
+	}
+	// /usr is the read-only tree of the ostree deployment
+	if p == "/usr" || strings.HasPrefix(p, "/usr/") {
+		return fmt.Errorf("Invalid path '%s', /usr is read-only in the instance, use /etc or /var instead", p)
+	}
+	return nil
+}
+
+func validateUnitName(name string) error {
+	if !safeArgument.MatchString(name) || strings.Contains(name, "/") {
+		return fmt.Errorf("Invalid unit name '%s'", name)
+	}
+	for _, suffix := range []string{".service", ".socket", ".timer", ".path", ".mount", ".target"} {
+		if strings.HasSuffix(name, suffix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("Invalid unit name '%s', the supported types are service, socket, timer, path, mount and target", name)
+}
+
+// Data returns the decoded contents of the file, only the data URLs are
+// supported since the instance may not reach the network yet
+func (file File) Data() ([]byte, error) {
+	if file.Contents.Source == nil {
+		return []byte{}, nil
+	}
+	data, err := decodeDataURL(*file.Contents.Source)
+	if err != nil {
+		return nil, err
+	}
+	if file.Contents.Compression == nil || *file.Contents.Compression == "" {
+		return data, nil
+	}
+	if *file.Contents.Compression != "gzip" {
+		return nil, fmt.Errorf("unsupported compression '%s'", *file.Contents.Compression)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+// FileMode returns the permissions of the file
+func (file File) FileMode() int {
+	if file.Mode == nil {
+		return defaultFileMode
+	}
+	return *file.Mode
+}
+
+func (directory Directory) DirectoryMode() int {
+	if directory.Mode == nil {
+		return defaultDirectoryMode
+	}
+	return *directory.Mode
+}
+
+// decodeDataURL decodes an RFC 2397 URL, like data:,hello or
+// data:text/plain;base64,aGVsbG8=
+func decodeDataURL(source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "data:") {
+		return nil, fmt.Errorf("unsupported source, only data URLs are supported")
+	}
+	fields := strings.SplitN(strings.TrimPrefix(source, "data:"), ",", 2)
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("invalid data URL, it has no comma")
+	}
+	header, payload := fields[0], fields[1]
+	if strings.HasSuffix(header, ";base64") {
+		unescaped, err := url.PathUnescape(payload)
+		if err != nil {
+			return nil, err
+		}
+		return base64.StdEncoding.DecodeString(unescaped)
+	}
+	unescaped, err := url.PathUnescape(payload)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(unescaped), nil
+}
@@ -0,0 +1,109 @@
+package ignition
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `{
+  "ignition": {"version": "3.3.0"},
+  "storage": {
+    "files": [
+      {"path": "/etc/sysctl.d/90-crc.conf", "mode": 420, "contents": {"source": "data:,vm.max_map_count%3D262144%0A"}},
+      {"path": "/var/home/core/motd", "user": {"name": "core"}, "contents": {"source": "data:text/plain;base64,aGVsbG8K"}}
+    ]
+  },
+  "systemd": {
+    "units": [
+      {"name": "hello.service", "enabled": true, "contents": "[Service]\nExecStart=/bin/true\n"},
+      {"name": "crio.service", "dropins": [{"name": "10-debug.conf", "contents": "[Service]\nEnvironment=DEBUG=1\n"}]}
+    ]
+  },
+  "kernelArguments": {"shouldExist": ["hugepages=16"], "shouldNotExist": ["mitigations=off"]}
+}`
+
+func TestParse(t *testing.T) {
+	config, err := Parse([]byte(testConfig))
+	require.NoError(t, err)
+	require.Len(t, config.Storage.Files, 2)
+	data, err := config.Storage.Files[0].Data()
+	require.NoError(t, err)
+	assert.Equal(t, "vm.max_map_count=262144\n", string(data))
+	data, err = config.Storage.Files[1].Data()
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(data))
+	assert.Equal(t, 0644, config.Storage.Files[1].FileMode())
+	assert.Equal(t, []string{"hugepages=16"}, config.KernelArguments.ShouldExist)
+}
+
+func TestParseRejectsInvalidConfigs(t *testing.T) {
+	tests := map[string]string{
+		`{"ignition": {"version": "2.2.0"}}`:                                                                                                    "Unsupported Ignition specification version",
+		`{"ignition": {"version": "3.2.0"}, "passwd": {}}`:                                                                                      `unknown field "passwd"`,
+		`{"ignition": {"version": "3.2.0"}, "storage": {"files": [{"path": "etc/motd"}]}}`:                                                      "Invalid path",
+		`{"ignition": {"version": "3.2.0"}, "storage": {"files": [{"path": "/usr/bin/tool"}]}}`:                                                 "/usr is read-only",
+		`{"ignition": {"version": "3.2.0"}, "storage": {"files": [{"path": "/etc/a b"}]}}`:                                                      "Invalid path",
+		`{"ignition": {"version": "3.2.0"}, "storage": {"files": [{"path": "/etc/motd", "contents": {"source": "https://example.com/motd"}}]}}`: "only data URLs",
+		`{"ignition": {"version": "3.2.0"}, "systemd": {"units": [{"name": "hello"}]}}`:                                                         "Invalid unit name",
+		`{"ignition": {"version": "3.2.0"}, "kernelArguments": {"shouldExist": ["hugepages=16"]}}`:                                              "requires the version 3.3.0",
+		`{"ignition": {"version": "3.4.0"}, "kernelArguments": {"shouldExist": ["a b"]}}`:                                                       "Invalid kernel argument",
+	}
+	for config, expected := range tests {
+		_, err := Parse([]byte(config))
+		require.Error(t, err, config)
+		assert.Contains(t, err.Error(), expected)
+	}
+}
+
+type recordingRunner struct {
+	files    map[string]string
+	commands []string
+}
+
+func (r *recordingRunner) RunPrivate(command string, args ...string) (string, string, error) {
+	if contents, ok := r.files[args[len(args)-1]]; ok {
+		return contents, "", nil
+	}
+	return "", "", errors.New("No such file or directory")
+}
+
+func (r *recordingRunner) RunPrivileged(reason string, cmdAndArgs ...string) (string, string, error) {
+	r.commands = append(r.commands, strings.Join(cmdAndArgs, " "))
+	return "", "", nil
+}
+
+func (r *recordingRunner) CopyData(data []byte, destFilename string, mode os.FileMode) error {
+	r.files[destFilename] = string(data)
+	r.commands = append(r.commands, fmt.Sprintf("copy %s 0%o", destFilename, mode))
+	return nil
+}
+
+func TestApply(t *testing.T) {
+	config, err := Parse([]byte(testConfig))
+	require.NoError(t, err)
+	runner := &recordingRunner{files: map[string]string{}}
+
+	require.NoError(t, Apply(runner, config))
+	assert.Equal(t, "vm.max_map_count=262144\n", runner.files["/etc/sysctl.d/90-crc.conf"])
+	assert.Equal(t, "[Service]\nEnvironment=DEBUG=1\n", runner.files["/etc/systemd/system/crio.service.d/10-debug.conf"])
+	assert.Contains(t, runner.commands, "chown core /var/home/core/motd")
+	assert.Contains(t, runner.commands, "sysctl --system")
+	assert.Contains(t, runner.commands, "systemctl daemon-reload")
+	assert.Contains(t, runner.commands, "systemctl restart hello.service")
+	assert.Contains(t, runner.commands, "systemctl try-restart crio.service")
+
+	// nothing changed, the files are not written and the units are not
+	// restarted again
+	runner.commands = nil
+	require.NoError(t, Apply(runner, config))
+	assert.NotContains(t, runner.commands, "sysctl --system")
+	assert.NotContains(t, runner.commands, "systemctl daemon-reload")
+	assert.NotContains(t, runner.commands, "systemctl try-restart crio.service")
+	assert.Contains(t, runner.commands, "systemctl start hello.service")
+}
@@ -0,0 +1,190 @@
+// Package image loads container images in the container storage of the
+// instance, without pulling them from the network of the instance
+package image
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/code-ready/crc/pkg/download"
+	"github.com/pkg/errors"
+)
+
+// ociRefNameAnnotation names the image of an OCI layout, podman load uses it
+// as the name of the loaded image
+const ociRefNameAnnotation = "org.opencontainers.image.ref.name"
+
+// Load streams the docker-archive or oci-archive to podman load in the
+// instance, in the storage of root, which is also the storage of CRI-O, or
+// of the core user. It returns the names of the loaded images.
+func Load(sshRunner *ssh.Runner, archive io.Reader, rootful bool) ([]string, error) {
+	var (
+		stdout strings.Builder
+		stderr string
+		err    error
+	)
+	if rootful {
+		stderr, err = sshRunner.RunPrivilegedWithIO("loading an image", archive, &stdout, "podman", "load")
+	} else {
+		stderr, err = sshRunner.RunWithIO(archive, &stdout, "podman", "load")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load the image: %v: %s", err, stderr)
+	}
+	return parseLoadedImages(stdout.String()), nil
+}
+
+// parseLoadedImages returns the images of the 'Loaded image: name' or of the
+// 'Loaded image(s): name1,name2' lines printed by podman load
+func parseLoadedImages(output string) []string {
+	var images []string
+	for _, line := range strings.Split(output, "\n") {
+		i := strings.Index(line, "Loaded image")
+		if i == -1 {
+			continue
+		}
+		names := line[i:]
+		j := strings.Index(names, ":")
+		if j == -1 {
+			continue
+		}
+		for _, name := range strings.Split(names[j+1:], ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				images = append(images, name)
+			}
+		}
+	}
+	return images
+}
+
+// Copy pulls the image of a docker:// reference on the host and loads it
+// in the instance
+func Copy(sshRunner *ssh.Runner, imageRef string, rootful bool) ([]string, error) {
+	ref, err := download.ParseOCIReference(imageRef)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := ioutil.TempDir("", "crc-image")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	logging.Infof("Pulling %s on the host...", ref)
+	registry := download.NewOCIRegistry(ref)
+	// the instance runs on the architecture of the host
+	image, err := registry.Image("linux", runtime.GOARCH)
+	if err != nil {
+		return nil, err
+	}
+	blobsDir := filepath.Join(dir, "blobs", "sha256")
+	if err := os.MkdirAll(blobsDir, 0700); err != nil {
+		return nil, err
+	}
+	for _, blob := range append([]download.OCILayer{image.Config}, image.Layers...) {
+		if _, err := registry.DownloadLayer(blob, blobPath(dir, blob.Digest), 0600); err != nil {
+			return nil, errors.Wrapf(err, "Cannot download %s", blob.Digest)
+		}
+	}
+	if err := writeOCILayout(dir, image, imageName(ref)); err != nil {
+		return nil, err
+	}
+
+	logging.Infof("Loading %s in the instance...", ref)
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(writeTar(writer, dir))
+	}()
+	images, err := Load(sshRunner, reader, rootful)
+	reader.Close()
+	return images, err
+}
+
+// imageName returns the name given to the image in the instance, an image
+// referenced by digest keeps the name of its repository
+func imageName(ref *download.OCIReference) string {
+	name := fmt.Sprintf("%s/%s", ref.Registry, ref.Repository)
+	if strings.HasPrefix(ref.Reference, "sha256:") {
+		return name
+	}
+	return fmt.Sprintf("%s:%s", name, ref.Reference)
+}
+
+func blobPath(dir, digest string) string {
+	return filepath.Join(dir, "blobs", "sha256", strings.TrimPrefix(digest, "sha256:"))
+}
+
+// writeOCILayout adds the manifest of the image to the blobs downloaded in
+// dir, and the files which make it an OCI image layout
+func writeOCILayout(dir string, image *download.OCIImage, name string) error {
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(image.Manifest))
+	if err := ioutil.WriteFile(blobPath(dir, digest), image.Manifest, 0600); err != nil {
+		return err
+	}
+	index := map[string]interface{}{
+		"schemaVersion": 2,
+		"manifests": []map[string]interface{}{
+			{
+				"mediaType":   image.MediaType,
+				"digest":      digest,
+				"size":        len(image.Manifest),
+				"annotations": map[string]string{ociRefNameAnnotation: name},
+			},
+		},
+	}
+	data, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "index.json"), data, 0600); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "oci-layout"), []byte(`{"imageLayoutVersion": "1.0.0"}`), 0600)
+}
+
+// writeTar writes the content of dir to w as a tar archive, the names of
+// the entries are relative to dir
+func writeTar(w io.Writer, dir string) error {
+	tarWriter := tar.NewWriter(w)
+	err := filepath.Walk(dir, func(file string, fi os.FileInfo, err error) error {
+		if err != nil || file == dir {
+			return err
+		}
+		header, err := tar.FileInfoHeader(fi, "")
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		data, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer data.Close()
+		_, err = io.Copy(tarWriter, data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tarWriter.Close()
+}
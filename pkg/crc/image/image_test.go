@@ -0,0 +1,79 @@
+package image
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/download"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLoadedImages(t *testing.T) {
+	assert.Equal(t, []string{"quay.io/foo/bar:latest"}, parseLoadedImages("Getting image source signatures\nLoaded image: quay.io/foo/bar:latest\n"))
+	assert.Equal(t, []string{"quay.io/foo/bar:1", "quay.io/foo/baz:2"}, parseLoadedImages("Loaded image(s): quay.io/foo/bar:1,quay.io/foo/baz:2\n"))
+	assert.Empty(t, parseLoadedImages("Copying blob 2a4b\n"))
+}
+
+func TestImageName(t *testing.T) {
+	ref, err := download.ParseOCIReference("docker://quay.io/foo/bar:1.0")
+	require.NoError(t, err)
+	assert.Equal(t, "quay.io/foo/bar:1.0", imageName(ref))
+
+	ref, err = download.ParseOCIReference("docker://busybox@sha256:4b8a")
+	require.NoError(t, err)
+	assert.Equal(t, "docker.io/library/busybox", imageName(ref))
+}
+
+func TestOCILayoutArchive(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "blobs", "sha256"), 0700))
+	require.NoError(t, ioutil.WriteFile(blobPath(dir, "sha256:1234"), []byte("config"), 0600))
+	image := &download.OCIImage{
+		Manifest:  []byte(`{"schemaVersion":2}`),
+		MediaType: "application/vnd.oci.image.manifest.v1+json",
+		Config:    download.OCILayer{Digest: "sha256:1234"},
+	}
+	require.NoError(t, writeOCILayout(dir, image, "quay.io/foo/bar:1.0"))
+
+	var archive bytes.Buffer
+	require.NoError(t, writeTar(&archive, dir))
+	files := map[string]string{}
+	reader := tar.NewReader(&archive)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(reader)
+		require.NoError(t, err)
+		files[header.Name] = string(data)
+	}
+
+	assert.Equal(t, `{"imageLayoutVersion": "1.0.0"}`, files["oci-layout"])
+	assert.Equal(t, "config", files["blobs/sha256/1234"])
+	assert.Contains(t, files, "blobs/sha256")
+
+	var index struct {
+		Manifests []struct {
+			MediaType   string            `json:"mediaType"`
+			Digest      string            `json:"digest"`
+			Size        int               `json:"size"`
+			Annotations map[string]string `json:"annotations"`
+		} `json:"manifests"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(files["index.json"]), &index))
+	require.Len(t, index.Manifests, 1)
+	assert.Equal(t, "sha256:bafebd36189ad3688b7b3915ea55d461e0bfcfbdde11e54b0a123999fb6be50f", index.Manifests[0].Digest)
+	assert.Equal(t, image.MediaType, index.Manifests[0].MediaType)
+	assert.Equal(t, len(image.Manifest), index.Manifests[0].Size)
+	assert.Equal(t, "quay.io/foo/bar:1.0", index.Manifests[0].Annotations[ociRefNameAnnotation])
+	assert.Equal(t, string(image.Manifest), files["blobs/sha256/bafebd36189ad3688b7b3915ea55d461e0bfcfbdde11e54b0a123999fb6be50f"])
+}
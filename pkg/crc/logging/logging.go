@@ -13,6 +13,7 @@ import (
 var (
 	logfile       *os.File
 	logLevel      = defaultLogLevel()
+	quiet         bool
 	originalHooks = logrus.LevelHooks{}
 	Memory        = newInMemoryHook(100)
 )
@@ -86,6 +87,18 @@ func IsDebug() bool {
 	return logLevel == "debug"
 }
 
+// SetQuiet only sends the errors to stderr and hides the progress bars, it
+// must be called before InitLogrus
+func SetQuiet() {
+	quiet = true
+	logLevel = "error"
+}
+
+// IsQuiet returns true when the progress must not be shown
+func IsQuiet() bool {
+	return quiet
+}
+
 func Info(args ...interface{}) {
 	logrus.Info(args...)
 }
@@ -0,0 +1,101 @@
+package machine
+
+import (
+	"net"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/pkg/errors"
+)
+
+// clientPorts are the ports of the instance used from the host: ssh, the
+// router and the API server
+var clientPorts = []string{"22", "80", "443", "6443"}
+
+// ActiveConnections returns the number of connections opened from the host
+// to the instance (API server, web console, applications and ssh sessions).
+// The ssh session used to run the query is not counted.
+func (client *client) ActiveConnections() (int, error) {
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
+	if err != nil {
+		return 0, errors.Wrap(err, "Cannot load machine")
+	}
+	defer vm.Close()
+
+	vmState, err := vm.State()
+	if err != nil {
+		return 0, errors.Wrap(err, "Cannot get machine state")
+	}
+	if vmState != state.Running {
+		return 0, nil
+	}
+
+	sshRunner, err := vm.SSHRunner()
+	if err != nil {
+		return 0, errors.Wrap(err, "Error creating the ssh client")
+	}
+	defer sshRunner.Close()
+
+	route, _, err := sshRunner.Run("ip", "-4", "route", "show", "default")
+	if err != nil {
+		return 0, errors.Wrap(err, "Cannot get the default gateway of the instance")
+	}
+	sshConnection, _, err := sshRunner.Run("echo", "$SSH_CONNECTION")
+	if err != nil {
+		return 0, err
+	}
+	var filters []string
+	for _, port := range clientPorts {
+		filters = append(filters, "sport = :"+port)
+	}
+	connections, _, err := sshRunner.Run("ss", "-Htn", "state", "established", "'( "+strings.Join(filters, " or ")+" )'")
+	if err != nil {
+		return 0, errors.Wrap(err, "Cannot list the established connections")
+	}
+	return countClientConnections(connections, defaultGateway(route), sshConnection), nil
+}
+
+// defaultGateway parses the output of 'ip route show default'. The host is
+// the default gateway of the instance with all the network modes.
+func defaultGateway(route string) net.IP {
+	fields := strings.Fields(route)
+	for i := 0; i+1 < len(fields); i++ {
+		if fields[i] == "via" {
+			return net.ParseIP(fields[i+1])
+		}
+	}
+	return nil
+}
+
+// countClientConnections counts the connections listed by 'ss -Htn' whose
+// peer is the host. sshConnection is the value of $SSH_CONNECTION in the
+// session used to run ss, it is excluded from the count.
+func countClientConnections(connections string, gateway net.IP, sshConnection string) int {
+	if gateway == nil {
+		return 0
+	}
+	ownPeer := ""
+	if fields := strings.Fields(sshConnection); len(fields) == 4 {
+		ownPeer = net.JoinHostPort(fields[0], fields[1])
+	}
+	count := 0
+	for _, line := range strings.Split(connections, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 {
+			continue
+		}
+		host, port, err := net.SplitHostPort(fields[3])
+		if err != nil {
+			continue
+		}
+		ip := net.ParseIP(host)
+		if ip == nil || !ip.Equal(gateway) {
+			continue
+		}
+		if net.JoinHostPort(ip.String(), port) == ownPeer {
+			continue
+		}
+		count++
+	}
+	return count
+}
@@ -0,0 +1,27 @@
+package machine
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultGateway(t *testing.T) {
+	assert.Equal(t, net.ParseIP("192.168.127.1"), defaultGateway("default via 192.168.127.1 dev tap0 proto dhcp metric 100\n"))
+	assert.Nil(t, defaultGateway(""))
+}
+
+func TestCountClientConnections(t *testing.T) {
+	connections := `0      0      192.168.127.2:22        192.168.127.1:40522
+0      0      192.168.127.2:22        192.168.127.1:40530
+0      0      [::ffff:192.168.127.2]:6443 [::ffff:192.168.127.1]:51234
+0      0      [::ffff:192.168.126.11]:6443 [::ffff:192.168.126.11]:37654
+0      0      [::ffff:192.168.126.11]:6443 [::ffff:10.217.0.12]:44212
+`
+	gateway := net.ParseIP("192.168.127.1")
+	assert.Equal(t, 2, countClientConnections(connections, gateway, "192.168.127.1 40522 192.168.127.2 22\n"))
+	assert.Equal(t, 3, countClientConnections(connections, gateway, ""))
+	assert.Equal(t, 0, countClientConnections(connections, nil, ""))
+	assert.Equal(t, 0, countClientConnections("", gateway, ""))
+}
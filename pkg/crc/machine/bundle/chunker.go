@@ -0,0 +1,64 @@
+package bundle
+
+import (
+	"bufio"
+	"io"
+)
+
+// The boundaries of the chunks depend on their content, like with casync,
+// an insertion or a removal in a file only changes the chunks around it.
+// These values and gearTable are part of the format of the chunk indexes,
+// changing them makes all the published chunks useless.
+const (
+	minChunkSize = 256 * 1024
+	maxChunkSize = 4 * 1024 * 1024
+	// a boundary is found every 1 MiB on average
+	chunkMaskBits = 20
+	chunkMask     = uint64(1<<chunkMaskBits-1) << (64 - chunkMaskBits)
+)
+
+var gearTable = newGearTable()
+
+// newGearTable returns the random values of the gear rolling hash, they
+// are generated with splitmix64 from a fixed seed
+func newGearTable() [256]uint64 {
+	var table [256]uint64
+	state := uint64(0x6372635f6368756e) // "crc_chun"
+	for i := range table {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		table[i] = z ^ (z >> 31)
+	}
+	return table
+}
+
+// splitChunks calls fn with each chunk of r, the chunk is only valid
+// until fn returns
+func splitChunks(r io.Reader, fn func(chunk []byte) error) error {
+	reader := bufio.NewReaderSize(r, 1024*1024)
+	chunk := make([]byte, 0, maxChunkSize)
+	var hash uint64
+	for {
+		b, err := reader.ReadByte()
+		if err == io.EOF {
+			if len(chunk) > 0 {
+				return fn(chunk)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		chunk = append(chunk, b)
+		hash = (hash << 1) + gearTable[b]
+		if (len(chunk) >= minChunkSize && hash&chunkMask == 0) || len(chunk) == maxChunkSize {
+			if err := fn(chunk); err != nil {
+				return err
+			}
+			chunk = chunk[:0]
+			hash = 0
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package bundle
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomData(t *testing.T, seed int64, size int) []byte {
+	data := make([]byte, size)
+	_, err := rand.New(rand.NewSource(seed)).Read(data) // #nosec G404
+	require.NoError(t, err)
+	return data
+}
+
+func chunkDigests(t *testing.T, data []byte) []string {
+	var digests []string
+	var reassembled []byte
+	require.NoError(t, splitChunks(bytes.NewReader(data), func(chunk []byte) error {
+		assert.LessOrEqual(t, len(chunk), maxChunkSize)
+		digests = append(digests, chunkDigest(chunk))
+		reassembled = append(reassembled, chunk...)
+		return nil
+	}))
+	assert.Equal(t, data, reassembled)
+	return digests
+}
+
+func TestSplitChunks(t *testing.T) {
+	data := randomData(t, 1, 16*1024*1024)
+	digests := chunkDigests(t, data)
+	assert.Greater(t, len(digests), 4)
+	assert.Equal(t, digests, chunkDigests(t, data))
+
+	// an insertion only changes the chunks around it
+	modified := append(append(append([]byte{}, data[:8*1024*1024]...), []byte("inserted")...), data[8*1024*1024:]...)
+	modifiedDigests := chunkDigests(t, modified)
+	common := 0
+	for _, digest := range modifiedDigests {
+		for _, other := range digests {
+			if digest == other {
+				common++
+				break
+			}
+		}
+	}
+	assert.GreaterOrEqual(t, common, len(digests)-2)
+}
+
+func TestSplitChunksEmpty(t *testing.T) {
+	assert.Empty(t, chunkDigests(t, nil))
+}
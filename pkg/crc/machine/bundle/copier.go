@@ -70,10 +70,16 @@ func (copier *Copier) CopyPrivateSSHKey(srcPath string) error {
 	return crcos.CopyFileContents(srcPath, destPath, 0400)
 }
 
-func (copier *Copier) CopyKubeConfig() error {
+// CopyKubeConfig copies the kubeconfig file of the instance, which contains
+// the client certificate currently trusted by the cluster. The kubeconfig file
+// of the source bundle is used if it does not exist.
+func (copier *Copier) CopyKubeConfig(instanceKubeConfigPath string) error {
 	if copier.srcBundle.IsOpenShift() {
 		kubeConfigFileName := filepath.Base(copier.srcBundle.GetKubeConfigPath())
-		srcPath := copier.srcBundle.GetKubeConfigPath()
+		srcPath := instanceKubeConfigPath
+		if _, err := os.Stat(srcPath); err != nil {
+			srcPath = copier.srcBundle.GetKubeConfigPath()
+		}
 		destPath := copier.resolvePath(kubeConfigFileName)
 		return crcos.CopyFileContents(srcPath, destPath, 0640)
 	}
@@ -111,8 +117,9 @@ func (copier *Copier) GenerateBundle(bundleName string) error {
 		return err
 	}
 
-	// update bundle info
-	copier.copiedBundle.BuildInfo.BuildTime = time.Now().String()
+	// update bundle info, its age is used to estimate the expiry of the
+	// certificates of the cluster
+	copier.copiedBundle.BuildInfo.BuildTime = time.Now().Format(time.RFC3339)
 
 	// Create the metadata json for custom bundle
 	bundleContent, err := json.MarshalIndent(copier.copiedBundle, "", " ")
@@ -31,7 +31,12 @@ func TestGenerateBundle(t *testing.T) {
 	copier, err := NewCopier(&b, srcDir, customBundleName)
 	assert.NoError(t, err)
 
-	assert.NoError(t, copier.CopyKubeConfig())
+	instanceKubeConfig := filepath.Join(srcDir, "kubeconfig")
+	assert.NoError(t, ioutil.WriteFile(instanceKubeConfig, []byte("instance kubeconfig"), 0600))
+	assert.NoError(t, copier.CopyKubeConfig(instanceKubeConfig))
+	kubeConfig, err := ioutil.ReadFile(copier.copiedBundle.GetKubeConfigPath())
+	assert.NoError(t, err)
+	assert.Equal(t, "instance kubeconfig", string(kubeConfig))
 
 	assert.NoError(t, copier.CopyPrivateSSHKey(copier.srcBundle.GetSSHKeyPath()))
 	assert.NoError(t, copier.CopyFilesFromFileList())
@@ -42,6 +47,8 @@ func TestGenerateBundle(t *testing.T) {
 
 	assert.NoError(t, copier.GenerateBundle(customBundleName))
 	defer os.Remove(fmt.Sprintf("%s%s", customBundleName, bundleExtension))
+	_, err = copier.copiedBundle.GetBundleBuildTime()
+	assert.NoError(t, err)
 }
 
 func TestGetType(t *testing.T) {
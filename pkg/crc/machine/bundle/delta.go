@@ -0,0 +1,424 @@
+package bundle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/cache"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	crcpreset "github.com/code-ready/crc/pkg/crc/preset"
+	"github.com/code-ready/crc/pkg/download"
+	"github.com/docker/go-units"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+const (
+	// chunkIndexExtension is appended to the URL of a bundle to get the
+	// index of its chunks
+	chunkIndexExtension = ".chunks.json"
+	// chunkStoreDir is the directory next to the bundle holding its
+	// zstd compressed chunks
+	chunkStoreDir  = "chunks"
+	chunkExtension = ".zst"
+)
+
+// ChunkIndex lists the files of an extracted bundle and the chunks they
+// are made of. The bundles are updated by only downloading the chunks
+// which are not in the bundles already extracted in the cache.
+type ChunkIndex struct {
+	// Name is the name of the bundle directory, the bundle file name
+	// without its extension
+	Name  string        `json:"name"`
+	Files []ChunkedFile `json:"files"`
+}
+
+type ChunkedFile struct {
+	// Path is relative to the bundle directory, with slashes
+	Path   string      `json:"path"`
+	Mode   os.FileMode `json:"mode"`
+	Size   int64       `json:"size"`
+	Sha256 string      `json:"sha256"`
+	Chunks []Chunk     `json:"chunks"`
+}
+
+type Chunk struct {
+	Sha256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// chunkLocation is a chunk found in an extracted bundle
+type chunkLocation struct {
+	path   string
+	offset int64
+	size   int64
+}
+
+func chunkPath(digest string) string {
+	return path.Join(chunkStoreDir, digest[:2], digest+chunkExtension)
+}
+
+func chunkDigest(chunk []byte) string {
+	sum := sha256.Sum256(chunk)
+	return hex.EncodeToString(sum[:])
+}
+
+// chunkFile splits the file in chunks and calls fn with each of them
+func chunkFile(filePath string, fn func(offset int64, digest string, chunk []byte) error) (*ChunkedFile, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	file := &ChunkedFile{
+		Mode: info.Mode().Perm(),
+		Size: info.Size(),
+	}
+	h := sha256.New()
+	var offset int64
+	err = splitChunks(io.TeeReader(f, h), func(chunk []byte) error {
+		digest := chunkDigest(chunk)
+		if fn != nil {
+			if err := fn(offset, digest, chunk); err != nil {
+				return err
+			}
+		}
+		file.Chunks = append(file.Chunks, Chunk{Sha256: digest, Size: int64(len(chunk))})
+		offset += int64(len(chunk))
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot split %s in chunks", filePath)
+	}
+	file.Sha256 = hex.EncodeToString(h.Sum(nil))
+	return file, nil
+}
+
+// WriteChunkStore writes the chunks of the extracted bundle bundleDir to
+// storeDir, compressed with zstd, and the index of the bundle next to
+// them. storeDir is meant to be published in the directory of the
+// .crcbundle file, and can be shared by several bundles.
+func WriteChunkStore(bundleDir, storeDir string) (string, error) {
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+	if err != nil {
+		return "", err
+	}
+	defer encoder.Close()
+
+	index := ChunkIndex{
+		Name: filepath.Base(filepath.Clean(bundleDir)),
+	}
+	err = filepath.Walk(bundleDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		relPath, err := filepath.Rel(bundleDir, filePath)
+		if err != nil {
+			return err
+		}
+		logging.Infof("Splitting %s in chunks", relPath)
+		file, err := chunkFile(filePath, func(_ int64, digest string, chunk []byte) error {
+			chunkFile := filepath.Join(storeDir, filepath.FromSlash(chunkPath(digest)))
+			if _, err := os.Stat(chunkFile); err == nil {
+				return nil
+			}
+			if err := os.MkdirAll(filepath.Dir(chunkFile), 0755); err != nil {
+				return err
+			}
+			return ioutil.WriteFile(chunkFile, encoder.EncodeAll(chunk, nil), 0644) // #nosec G306
+		})
+		if err != nil {
+			return err
+		}
+		file.Path = filepath.ToSlash(relPath)
+		index.Files = append(index.Files, *file)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	indexPath := filepath.Join(storeDir, index.Name+bundleExtension+chunkIndexExtension)
+	if err := ioutil.WriteFile(indexPath, data, 0644); err != nil { // #nosec G306
+		return "", err
+	}
+	return indexPath, nil
+}
+
+// downloadChunkIndex downloads the index of the chunks of the bundle, and
+// checks it against the sha256sum.txt file next to it, signed by Red Hat,
+// when verify is true
+func downloadChunkIndex(bundleURL string, verify bool) (*ChunkIndex, error) {
+	bundleName, err := remoteBundleName(bundleURL)
+	if err != nil {
+		return nil, err
+	}
+	indexURL := bundleURL + chunkIndexExtension
+	data, err := download.InMemory(indexURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot download the chunk index of %s", bundleName)
+	}
+	if verify {
+		sha256sum, err := verifiedChecksum(indexURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Cannot verify the chunk index of %s", bundleName)
+		}
+		if digest := sha256.Sum256(data); !bytes.Equal(digest[:], sha256sum) {
+			return nil, fmt.Errorf("The chunk index %s does not match its signed sha256 sum", indexURL)
+		}
+	}
+
+	var index ChunkIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, errors.Wrapf(err, "Invalid chunk index %s", indexURL)
+	}
+	if index.Name != GetBundleNameWithoutExtension(bundleName) {
+		return nil, fmt.Errorf("The chunk index %s is for %s, not %s", indexURL, index.Name, bundleName)
+	}
+	for _, file := range index.Files {
+		cleanPath := path.Clean(file.Path)
+		if path.IsAbs(cleanPath) || cleanPath == ".." || strings.HasPrefix(cleanPath, "../") {
+			return nil, fmt.Errorf("The chunk index %s has an invalid path: %s", indexURL, file.Path)
+		}
+		for _, chunk := range file.Chunks {
+			if len(chunk.Sha256) != sha256.Size*2 {
+				return nil, fmt.Errorf("The chunk index %s has an invalid digest: %s", indexURL, chunk.Sha256)
+			}
+		}
+	}
+	return &index, nil
+}
+
+// localChunks looks for the needed chunks in the bundles extracted in the
+// cache, they are the same for the files which did not change between two
+// versions of a bundle
+func (repo *Repository) localChunks(index *ChunkIndex) map[string]chunkLocation {
+	needed := make(map[string]bool)
+	for _, file := range index.Files {
+		for _, chunk := range file.Chunks {
+			needed[chunk.Sha256] = true
+		}
+	}
+	found := make(map[string]chunkLocation)
+
+	dirs, err := ioutil.ReadDir(repo.CacheDir)
+	if err != nil {
+		return found
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() || dir.Name() == index.Name || strings.HasPrefix(dir.Name(), "tmp-") {
+			continue
+		}
+		bundleDir := filepath.Join(repo.CacheDir, dir.Name())
+		if _, err := os.Stat(filepath.Join(bundleDir, metadataFilename)); err != nil {
+			continue
+		}
+		logging.Infof("Looking for unchanged chunks in %s", dir.Name())
+		_ = filepath.Walk(bundleDir, func(filePath string, info os.FileInfo, err error) error {
+			if err != nil || !info.Mode().IsRegular() || len(found) == len(needed) {
+				return nil
+			}
+			_, err = chunkFile(filePath, func(offset int64, digest string, chunk []byte) error {
+				if _, ok := found[digest]; needed[digest] && !ok {
+					found[digest] = chunkLocation{path: filePath, offset: offset, size: int64(len(chunk))}
+				}
+				return nil
+			})
+			if err != nil {
+				logging.Debugf("Skipping %s: %v", filePath, err)
+			}
+			return nil
+		})
+		if len(found) == len(needed) {
+			break
+		}
+	}
+	return found
+}
+
+func readChunk(location chunkLocation, digest string) ([]byte, error) {
+	f, err := os.Open(location.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	chunk := make([]byte, location.size)
+	if _, err := f.ReadAt(chunk, location.offset); err != nil {
+		return nil, err
+	}
+	if chunkDigest(chunk) != digest {
+		return nil, fmt.Errorf("%s changed while updating the bundle", location.path)
+	}
+	return chunk, nil
+}
+
+func downloadChunk(baseURL *url.URL, digest string, decoder *zstd.Decoder) ([]byte, error) {
+	uri := *baseURL
+	uri.Path = path.Join(uri.Path, chunkPath(digest))
+	compressed, err := download.InMemory(uri.String())
+	if err != nil {
+		return nil, err
+	}
+	chunk, err := decoder.DecodeAll(compressed, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot decompress chunk %s", digest)
+	}
+	if chunkDigest(chunk) != digest {
+		return nil, fmt.Errorf("chunk %s does not match its digest", digest)
+	}
+	return chunk, nil
+}
+
+// UpdateFromChunks extracts the bundle of bundleURL in the cache. Only the
+// chunks which are neither in the bundles already extracted nor in the
+// chunk cache are downloaded, they are kept in the chunk cache until the
+// bundle is complete so that an interrupted update can be resumed.
+func (repo *Repository) UpdateFromChunks(bundleURL string, verify bool, chunks *cache.ChunkCache) (*CrcBundleInfo, error) {
+	index, err := downloadChunkIndex(bundleURL, verify)
+	if err != nil {
+		return nil, err
+	}
+	if bundleInfo, err := repo.Get(index.Name); err == nil {
+		logging.Infof("%s is up to date", index.Name)
+		return bundleInfo, nil
+	}
+	if err := os.MkdirAll(repo.CacheDir, 0775); err != nil {
+		return nil, err
+	}
+
+	baseURL, err := url.Parse(bundleURL)
+	if err != nil {
+		return nil, err
+	}
+	baseURL.Path = path.Dir(baseURL.Path)
+	baseURL.RawQuery = ""
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer decoder.Close()
+
+	local := repo.localChunks(index)
+
+	tmpDir := filepath.Join(repo.CacheDir, "tmp-update")
+	_ = os.RemoveAll(tmpDir) // clean up before using it
+	defer func() {
+		_ = os.RemoveAll(tmpDir) // clean up after using it
+	}()
+
+	var reused, downloaded int64
+	getChunk := func(chunk Chunk) ([]byte, error) {
+		if location, ok := local[chunk.Sha256]; ok {
+			data, err := readChunk(location, chunk.Sha256)
+			if err == nil {
+				reused += chunk.Size
+				return data, nil
+			}
+			logging.Debugf("Cannot reuse chunk %s: %v", chunk.Sha256, err)
+		}
+		if data, ok := chunks.Get(chunk.Sha256); ok {
+			downloaded += chunk.Size
+			return data, nil
+		}
+		data, err := downloadChunk(baseURL, chunk.Sha256, decoder)
+		if err != nil {
+			return nil, err
+		}
+		if err := chunks.Add(chunk.Sha256, data); err != nil {
+			logging.Debugf("Cannot cache chunk %s: %v", chunk.Sha256, err)
+		}
+		downloaded += chunk.Size
+		return data, nil
+	}
+
+	logging.Infof("Updating to %s", index.Name)
+	for _, file := range index.Files {
+		if err := assembleFile(filepath.Join(tmpDir, filepath.FromSlash(path.Clean(file.Path))), file, getChunk); err != nil {
+			return nil, err
+		}
+	}
+	if err := repo.moveToCache(tmpDir, index.Name); err != nil {
+		return nil, err
+	}
+	bundleInfo, err := repo.Get(index.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := chunks.Clear(); err != nil {
+		logging.Debugf("Cannot clear the chunk cache: %v", err)
+	}
+	logging.Infof("Reused %s from the cached bundles, downloaded %s",
+		units.HumanSize(float64(reused)), units.HumanSize(float64(downloaded)))
+	return bundleInfo, nil
+}
+
+func assembleFile(filePath string, file ChunkedFile, getChunk func(Chunk) ([]byte, error)) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, file.Mode.Perm()|0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	w := io.MultiWriter(f, h)
+	for _, chunk := range file.Chunks {
+		data, err := getChunk(chunk)
+		if err != nil {
+			return errors.Wrapf(err, "Cannot get the chunks of %s", file.Path)
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != file.Sha256 {
+		return fmt.Errorf("%s does not match its sha256 sum, expected %s, got %s", file.Path, file.Sha256, sum)
+	}
+	return f.Close()
+}
+
+// UpdateFromChunks updates to the bundle of bundleURL, or to the default
+// bundle of the preset when it is empty. A URL is recorded as a remote
+// bundle so that it can be used as the bundle config value.
+func UpdateFromChunks(preset crcpreset.Preset, bundleURL string, verify bool) (*CrcBundleInfo, error) {
+	if bundleURL == "" {
+		downloadInfo, err := getBundleDownloadInfo(preset)
+		if err != nil {
+			return nil, err
+		}
+		return defaultRepo.UpdateFromChunks(downloadInfo.GetURI(), verify, cache.NewChunkCache())
+	}
+	bundleInfo, err := defaultRepo.UpdateFromChunks(bundleURL, verify, cache.NewChunkCache())
+	if err != nil {
+		return nil, err
+	}
+	bundleName, _ := remoteBundleName(bundleURL)
+	if err := defaultRepo.addRemoteBundle(bundleURL, bundleName); err != nil {
+		return nil, err
+	}
+	return bundleInfo, nil
+}
@@ -0,0 +1,73 @@
+package bundle
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/cache"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateFromChunks(t *testing.T) {
+	oldName := "crc_libvirt_4.10.3"
+	newName := "crc_libvirt_4.10.4"
+	ocBinary := randomData(t, 2, 12*1024*1024)
+
+	mirrorDir := t.TempDir()
+	createDummyBundleContent(t, mirrorDir, newName, "1.0")
+	newOcBinary := append(append([]byte{}, ocBinary[:6*1024*1024]...), []byte("updated")...)
+	newOcBinary = append(newOcBinary, ocBinary[6*1024*1024:]...)
+	require.NoError(t, os.WriteFile(filepath.Join(mirrorDir, newName, constants.OcExecutableName), newOcBinary, 0600))
+	indexPath, err := WriteChunkStore(filepath.Join(mirrorDir, newName), mirrorDir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(mirrorDir, newName+".crcbundle.chunks.json"), indexPath)
+	require.NoError(t, os.RemoveAll(filepath.Join(mirrorDir, newName)))
+
+	var chunkRequests int32
+	fileServer := http.FileServer(http.Dir(mirrorDir))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/chunks/") {
+			atomic.AddInt32(&chunkRequests, 1)
+		}
+		fileServer.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	repo := &Repository{CacheDir: t.TempDir()}
+	createDummyBundleContent(t, repo.CacheDir, oldName, "1.0")
+	require.NoError(t, os.WriteFile(filepath.Join(repo.CacheDir, oldName, constants.OcExecutableName), ocBinary, 0600))
+
+	chunks := cache.NewChunkCacheInDir(t.TempDir())
+	bundleURL := server.URL + "/" + newName + ".crcbundle"
+	bundleInfo, err := repo.UpdateFromChunks(bundleURL, false, chunks)
+	require.NoError(t, err)
+	assert.Equal(t, newName, bundleInfo.GetBundleName())
+
+	content, err := os.ReadFile(filepath.Join(repo.CacheDir, newName, constants.OcExecutableName))
+	require.NoError(t, err)
+	assert.Equal(t, newOcBinary, content)
+
+	index, err := downloadChunkIndex(bundleURL, false)
+	require.NoError(t, err)
+	var totalChunks int
+	for _, file := range index.Files {
+		totalChunks += len(file.Chunks)
+	}
+	assert.Less(t, int(chunkRequests), totalChunks/2)
+
+	// the bundle is already extracted
+	chunkRequests = 0
+	_, err = repo.UpdateFromChunks(bundleURL, false, chunks)
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), chunkRequests)
+
+	_, err = repo.UpdateFromChunks(bundleURL, true, chunks)
+	assert.Error(t, err)
+}
@@ -1,8 +1,10 @@
 package bundle
 
 import (
+	"encoding/hex"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"runtime"
@@ -12,8 +14,10 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/preset"
 	"github.com/code-ready/crc/pkg/download"
+	"github.com/pkg/errors"
 )
 
 // Metadata structure to unmarshal the crc-bundle-info.json file
@@ -28,7 +32,8 @@ type CrcBundleInfo struct {
 	Storage     Storage     `json:"storage"`
 	DriverInfo  DriverInfo  `json:"driverInfo"`
 
-	cachedPath string
+	cachedPath    string
+	clusterDomain string
 }
 
 type BuildInfo struct {
@@ -98,11 +103,55 @@ func (bundle *CrcBundleInfo) GetBundleName() string {
 	return bundle.Name
 }
 
+// SetClusterDomain replaces the domain of the bundle, crc.testing, with the
+// domain configured by the user. The API server is then api.<domain> and
+// the routes *.apps.<domain>.
+func (bundle *CrcBundleInfo) SetClusterDomain(domain string) {
+	if domain == bundle.getDefaultClusterDomain() {
+		domain = ""
+	}
+	bundle.clusterDomain = domain
+}
+
+func (bundle *CrcBundleInfo) HasCustomClusterDomain() bool {
+	return bundle.clusterDomain != ""
+}
+
+func (bundle *CrcBundleInfo) getDefaultClusterDomain() string {
+	return fmt.Sprintf("%s.%s", bundle.ClusterInfo.ClusterName, bundle.ClusterInfo.BaseDomain)
+}
+
+func (bundle *CrcBundleInfo) GetClusterDomain() string {
+	if bundle.HasCustomClusterDomain() {
+		return bundle.clusterDomain
+	}
+	return bundle.getDefaultClusterDomain()
+}
+
+func (bundle *CrcBundleInfo) GetAppsDomain() string {
+	if bundle.HasCustomClusterDomain() {
+		return fmt.Sprintf("apps.%s", bundle.clusterDomain)
+	}
+	return bundle.ClusterInfo.AppsDomain
+}
+
 func (bundle *CrcBundleInfo) GetAPIHostname() string {
-	return fmt.Sprintf("api.%s.%s", bundle.ClusterInfo.ClusterName, bundle.ClusterInfo.BaseDomain)
+	return fmt.Sprintf("api.%s", bundle.GetClusterDomain())
 }
 
 func (bundle *CrcBundleInfo) GetAppHostname(appName string) string {
+	return fmt.Sprintf("%s.%s", appName, bundle.GetAppsDomain())
+}
+
+// GetDefaultAPIHostname returns the hostname of the API server in the
+// domain of the bundle, regardless of the configured cluster domain
+func (bundle *CrcBundleInfo) GetDefaultAPIHostname() string {
+	return fmt.Sprintf("api.%s", bundle.getDefaultClusterDomain())
+}
+
+// GetDefaultAppHostname returns the hostname of a route in the domain of the
+// bundle, regardless of the configured cluster domain
+func (bundle *CrcBundleInfo) GetDefaultAppHostname(appName string) string {
 	return fmt.Sprintf("%s.%s", appName, bundle.ClusterInfo.AppsDomain)
 }
 
@@ -166,6 +215,16 @@ func (bundle *CrcBundleInfo) GetOpenshiftVersion() string {
 	return bundle.ClusterInfo.OpenShiftVersion.String()
 }
 
+// GetKubernetesVersion returns the minor version of Kubernetes of the
+// OpenShift release, OpenShift 4.N is based on Kubernetes 1.N+13
+func (bundle *CrcBundleInfo) GetKubernetesVersion() string {
+	openshiftVersion := bundle.ClusterInfo.OpenShiftVersion
+	if !bundle.IsOpenShift() || openshiftVersion == nil || openshiftVersion.Major() != 4 {
+		return ""
+	}
+	return fmt.Sprintf("1.%d", openshiftVersion.Minor()+13)
+}
+
 func (bundle *CrcBundleInfo) GetPodmanVersion() string {
 	return bundle.Nodes[0].PodmanVersion
 }
@@ -263,14 +322,24 @@ func getBundleDownloadInfo(preset preset.Preset) (*download.RemoteFile, error) {
 	return downloadInfo, nil
 }
 
-func Download(preset preset.Preset) error {
+// Download fetches the default bundle of the preset. When verifySignature
+// is true, the sha256 sum of the bundle known by crc must also be the one of
+// the sha256sum.txt file of the mirror signed by Red Hat.
+func Download(preset preset.Preset, verifySignature bool) error {
 	downloadInfo, err := getBundleDownloadInfo(preset)
 	if err != nil {
 		return err
 	}
-	if _, err := downloadInfo.Download(constants.GetDefaultBundlePath(preset), 0664); err != nil {
-		return err
+	if verifySignature {
+		logging.Infof("Verifying the signature of the sha256 sum of %s", path.Base(downloadInfo.GetURI()))
+		sha256sum, err := verifiedChecksum(downloadInfo.GetURI())
+		if err != nil {
+			return errors.Wrapf(err, "Cannot verify %s", downloadInfo.GetURI())
+		}
+		if hex.EncodeToString(sha256sum) != downloadInfo.GetSha256Sum() {
+			return fmt.Errorf("The sha256 sum of %s is not the signed sha256 sum of the mirror", downloadInfo.GetURI())
+		}
 	}
-
-	return nil
+	_, err = downloadInfo.Download(constants.GetDefaultBundlePath(preset), 0664)
+	return err
 }
@@ -164,3 +164,31 @@ func TestCustomBundleName(t *testing.T) {
 	customBundleName = GetCustomBundleName(customBundleName)
 	checkBundleName(t, customBundleName)
 }
+
+func TestKubernetesVersion(t *testing.T) {
+	var bundle CrcBundleInfo
+	assert.NoError(t, json.Unmarshal([]byte(jsonForBundle("crc_libvirt_4.6.1")), &bundle))
+	assert.Equal(t, "1.19", bundle.GetKubernetesVersion())
+	bundle.Type = "podman"
+	assert.Equal(t, "", bundle.GetKubernetesVersion())
+}
+
+func TestClusterDomain(t *testing.T) {
+	var bundle CrcBundleInfo
+	assert.NoError(t, json.Unmarshal([]byte(jsonForBundle("crc_libvirt_4.6.1")), &bundle))
+	assert.False(t, bundle.HasCustomClusterDomain())
+	assert.Equal(t, "api.crc.testing", bundle.GetAPIHostname())
+	assert.Equal(t, "console.apps-crc.testing", bundle.GetAppHostname("console"))
+
+	bundle.SetClusterDomain("crc.testing")
+	assert.False(t, bundle.HasCustomClusterDomain())
+
+	bundle.SetClusterDomain("crc.example.com")
+	assert.True(t, bundle.HasCustomClusterDomain())
+	assert.Equal(t, "crc.example.com", bundle.GetClusterDomain())
+	assert.Equal(t, "apps.crc.example.com", bundle.GetAppsDomain())
+	assert.Equal(t, "api.crc.example.com", bundle.GetAPIHostname())
+	assert.Equal(t, "console.apps.crc.example.com", bundle.GetAppHostname("console"))
+	assert.Equal(t, "api.crc.testing", bundle.GetDefaultAPIHostname())
+	assert.Equal(t, "console.apps-crc.testing", bundle.GetDefaultAppHostname("console"))
+}
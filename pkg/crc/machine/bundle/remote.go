@@ -0,0 +1,201 @@
+package bundle
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/download"
+	"github.com/pkg/errors"
+)
+
+const (
+	// remoteBundlesFilename maps the remote bundles to the files they were
+	// downloaded to in the cache directory
+	remoteBundlesFilename = "remote-bundles.json"
+)
+
+// IsRemote checks if the bundle is an HTTP(S) URL or a docker:// reference
+// to a registry instead of a local file
+func IsRemote(bundlePath string) bool {
+	return strings.HasPrefix(bundlePath, "https://") ||
+		strings.HasPrefix(bundlePath, "http://") ||
+		strings.HasPrefix(bundlePath, download.OCIReferencePrefix)
+}
+
+// ValidateRemote checks the syntax of a remote bundle, it does not access
+// the network
+func ValidateRemote(bundleRef string) error {
+	if strings.HasPrefix(bundleRef, download.OCIReferencePrefix) {
+		_, err := download.ParseOCIReference(bundleRef)
+		return err
+	}
+	_, err := remoteBundleName(bundleRef)
+	return err
+}
+
+// RemoteBundleName returns the file name of the bundle of a URL, it is
+// empty for a registry reference as it is only known once its manifest is
+// downloaded
+func RemoteBundleName(bundleRef string) string {
+	name, err := remoteBundleName(bundleRef)
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+func remoteBundleName(bundleURL string) (string, error) {
+	uri, err := url.Parse(bundleURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "'%s' is not a valid URL", bundleURL)
+	}
+	if uri.Scheme != "https" && uri.Scheme != "http" {
+		return "", fmt.Errorf("'%s' is not an HTTP(S) URL", bundleURL)
+	}
+	name := path.Base(uri.Path)
+	if !strings.HasSuffix(name, bundleExtension) {
+		return "", fmt.Errorf("'%s' is not the URL of a %s file", bundleURL, bundleExtension)
+	}
+	return name, nil
+}
+
+func (repo *Repository) remoteBundlesPath() string {
+	return filepath.Join(repo.CacheDir, remoteBundlesFilename)
+}
+
+func (repo *Repository) remoteBundles() (map[string]string, error) {
+	remoteBundles := make(map[string]string)
+	data, err := ioutil.ReadFile(repo.remoteBundlesPath())
+	if os.IsNotExist(err) {
+		return remoteBundles, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &remoteBundles); err != nil {
+		return nil, errors.Wrapf(err, "Invalid %s", repo.remoteBundlesPath())
+	}
+	return remoteBundles, nil
+}
+
+func (repo *Repository) addRemoteBundle(bundleRef, bundleName string) error {
+	remoteBundles, err := repo.remoteBundles()
+	if err != nil {
+		return err
+	}
+	remoteBundles[bundleRef] = bundleName
+	data, err := json.MarshalIndent(remoteBundles, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(repo.remoteBundlesPath(), data, 0600)
+}
+
+// LocalPath returns the file of the bundle on the host. It is false when the
+// bundle is remote and was not downloaded yet.
+func (repo *Repository) LocalPath(bundlePath string) (string, bool) {
+	if !IsRemote(bundlePath) {
+		return bundlePath, true
+	}
+	remoteBundles, err := repo.remoteBundles()
+	if err != nil {
+		logging.Debugf("Cannot read the remote bundles: %v", err)
+		return "", false
+	}
+	bundleName, ok := remoteBundles[bundlePath]
+	if !ok {
+		return "", false
+	}
+	localPath := filepath.Join(repo.CacheDir, bundleName)
+	if _, err := os.Stat(localPath); err != nil {
+		// the extracted bundle is enough to start the instance
+		if _, err := repo.Get(bundleName); err != nil {
+			return "", false
+		}
+	}
+	return localPath, true
+}
+
+// DownloadRemote downloads the bundle to the cache directory and returns its
+// path. The bundles of a registry are checked against the digest of their
+// layer, and the bundles of a URL against the sha256sum.txt file next to
+// them, which must be signed by Red Hat when verify is true.
+func (repo *Repository) DownloadRemote(bundleRef string, verify bool) (string, error) {
+	if err := os.MkdirAll(repo.CacheDir, 0775); err != nil {
+		return "", err
+	}
+	var localPath string
+	var err error
+	if strings.HasPrefix(bundleRef, download.OCIReferencePrefix) {
+		localPath, err = repo.pullBundle(bundleRef)
+	} else {
+		localPath, err = repo.downloadBundle(bundleRef, verify)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := repo.addRemoteBundle(bundleRef, filepath.Base(localPath)); err != nil {
+		return "", err
+	}
+	return localPath, nil
+}
+
+func (repo *Repository) downloadBundle(bundleURL string, verify bool) (string, error) {
+	bundleName, err := remoteBundleName(bundleURL)
+	if err != nil {
+		return "", err
+	}
+	var sha256sum []byte
+	if verify {
+		if sha256sum, err = verifiedChecksum(bundleURL); err != nil {
+			return "", errors.Wrapf(err, "Cannot verify %s", bundleURL)
+		}
+	} else if sha256sum, err = download.RemoteChecksum(bundleURL); err != nil {
+		logging.Warnf("Skipping the verification of %s: %v", bundleName, err)
+	}
+	logging.Infof("Downloading %s", bundleURL)
+	return download.Download(bundleURL, filepath.Join(repo.CacheDir, bundleName), 0664, sha256sum)
+}
+
+// pullBundle downloads the bundle from an OCI artifact of a registry, it is
+// the layer with a .crcbundle title, as pushed by
+// 'oras push <registry>/<repository>:<tag> <bundle>'
+func (repo *Repository) pullBundle(bundleRef string) (string, error) {
+	ref, err := download.ParseOCIReference(bundleRef)
+	if err != nil {
+		return "", err
+	}
+	registry := download.NewOCIRegistry(ref)
+	layers, err := registry.Layers()
+	if err != nil {
+		return "", err
+	}
+	for _, layer := range layers {
+		bundleName := path.Base(layer.Annotations[download.OCITitleAnnotation])
+		if !strings.HasSuffix(bundleName, bundleExtension) {
+			continue
+		}
+		logging.Infof("Pulling %s from %s", bundleName, ref)
+		return registry.DownloadLayer(layer, filepath.Join(repo.CacheDir, bundleName), 0664)
+	}
+	return "", fmt.Errorf("%s has no layer with a %s title", ref, bundleExtension)
+}
+
+// LocalPath returns the file of the bundle on the host, it is false when
+// the bundle is remote and was not downloaded yet
+func LocalPath(bundlePath string) (string, bool) {
+	return defaultRepo.LocalPath(bundlePath)
+}
+
+// DownloadRemote downloads an HTTP(S) or registry bundle to the cache
+// directory
+func DownloadRemote(bundleRef string, verify bool) (string, error) {
+	return defaultRepo.DownloadRemote(bundleRef, verify)
+}
@@ -0,0 +1,84 @@
+package bundle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsRemote(t *testing.T) {
+	assert.True(t, IsRemote("https://mirror.openshift.com/crc_libvirt_4.10.3_amd64.crcbundle"))
+	assert.True(t, IsRemote("docker://quay.io/crcont/bundle:4.10.3"))
+	assert.False(t, IsRemote("/home/user/crc_libvirt_4.10.3_amd64.crcbundle"))
+	assert.False(t, IsRemote(`C:\Users\user\crc_hyperv_4.10.3_amd64.crcbundle`))
+}
+
+func TestValidateRemote(t *testing.T) {
+	assert.NoError(t, ValidateRemote("https://mirror.openshift.com/crc_libvirt_4.10.3_amd64.crcbundle"))
+	assert.NoError(t, ValidateRemote("docker://quay.io/crcont/bundle:4.10.3"))
+	assert.EqualError(t, ValidateRemote("https://mirror.openshift.com/crc_libvirt_4.10.3_amd64.tar"),
+		"'https://mirror.openshift.com/crc_libvirt_4.10.3_amd64.tar' is not the URL of a .crcbundle file")
+	assert.Equal(t, "crc_libvirt_4.10.3_amd64.crcbundle", RemoteBundleName("https://mirror.openshift.com/4.10.3/crc_libvirt_4.10.3_amd64.crcbundle?raw=true"))
+	assert.Equal(t, "", RemoteBundleName("docker://quay.io/crcont/bundle:4.10.3"))
+}
+
+func serveBundle(t *testing.T, content, checksum string) *httptest.Server {
+	signer := useTestKeyRing(t)
+	checksums := fmt.Sprintf("%s  crc_libvirt_4.10.3_amd64.crcbundle\n", checksum)
+	signed, err := signer.ClearSign([]byte(checksums))
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/4.10.3/crc_libvirt_4.10.3_amd64.crcbundle", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(content))
+	})
+	mux.HandleFunc("/4.10.3/sha256sum.txt", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(checksums))
+	})
+	mux.HandleFunc("/4.10.3/sha256sum.txt.sig", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(signed)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDownloadRemote(t *testing.T) {
+	sum := sha256.Sum256([]byte("bundle"))
+	server := serveBundle(t, "bundle", hex.EncodeToString(sum[:]))
+	repo := &Repository{CacheDir: t.TempDir()}
+	bundleURL := server.URL + "/4.10.3/crc_libvirt_4.10.3_amd64.crcbundle"
+
+	_, ok := repo.LocalPath(bundleURL)
+	assert.False(t, ok)
+
+	localPath, err := repo.DownloadRemote(bundleURL, true)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(repo.CacheDir, "crc_libvirt_4.10.3_amd64.crcbundle"), localPath)
+	content, err := os.ReadFile(localPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "bundle", string(content))
+
+	cachedPath, ok := repo.LocalPath(bundleURL)
+	assert.True(t, ok)
+	assert.Equal(t, localPath, cachedPath)
+}
+
+func TestDownloadRemoteChecksumMismatch(t *testing.T) {
+	sum := sha256.Sum256([]byte("another bundle"))
+	server := serveBundle(t, "bundle", hex.EncodeToString(sum[:]))
+	repo := &Repository{CacheDir: t.TempDir()}
+	bundleURL := server.URL + "/4.10.3/crc_libvirt_4.10.3_amd64.crcbundle"
+
+	_, err := repo.DownloadRemote(bundleURL, true)
+	assert.Error(t, err)
+	_, ok := repo.LocalPath(bundleURL)
+	assert.False(t, ok)
+}
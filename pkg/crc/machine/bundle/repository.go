@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
@@ -58,7 +59,7 @@ func (repo *Repository) Get(bundleName string) (*CrcBundleInfo, error) {
 		if fmt.Sprintf(".%s", bundleInfo.ClusterInfo.AppsDomain) != constants.AppsDomain {
 			return nil, fmt.Errorf("unexpected bundle, it must have %s apps domain", constants.AppsDomain)
 		}
-		if bundleInfo.GetAPIHostname() != fmt.Sprintf("api%s", constants.ClusterDomain) {
+		if bundleInfo.GetDefaultAPIHostname() != fmt.Sprintf("api%s", constants.ClusterDomain) {
 			return nil, fmt.Errorf("unexpected bundle, it must have %s base domain", constants.ClusterDomain)
 		}
 	}
@@ -123,6 +124,63 @@ func (bundle *CrcBundleInfo) createSymlinkOrCopyPodmanRemote(binDir string) erro
 	return bundle.copyExecutableFromBundle(binDir, PodmanExecutable, constants.PodmanRemoteExecutableName)
 }
 
+// InstallOpenShiftClient copies the oc executable of the bundle to the
+// directory of its OpenShift version in versionsDir, so that it is kept when
+// the bundle is removed, and returns its path
+func (bundle *CrcBundleInfo) InstallOpenShiftClient(versionsDir string) (string, error) {
+	srcPath := bundle.GetOcPath()
+	if !bundle.IsOpenShift() || srcPath == "" {
+		return "", fmt.Errorf("The %s bundle has no OpenShift client", bundle.GetBundleName())
+	}
+	destDir := filepath.Join(versionsDir, bundle.GetOpenshiftVersion())
+	destPath := filepath.Join(destDir, constants.OcExecutableName)
+	if crcos.FileExists(destPath) {
+		return destPath, nil
+	}
+	if err := os.MkdirAll(destDir, 0750); err != nil {
+		return "", err
+	}
+	tmpPath := destPath + ".tmp"
+	if err := crcos.CopyFileContents(srcPath, tmpPath, 0750); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return "", err
+	}
+	return destPath, nil
+}
+
+// InstalledOpenShiftClients returns the OpenShift versions whose client is
+// installed in versionsDir, oldest first
+func InstalledOpenShiftClients(versionsDir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(versionsDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var versions []*semver.Version
+	for _, entry := range entries {
+		if !entry.IsDir() || !crcos.FileExists(filepath.Join(versionsDir, entry.Name(), constants.OcExecutableName)) {
+			continue
+		}
+		version, err := semver.NewVersion(entry.Name())
+		if err != nil {
+			logging.Debugf("Ignoring %s: %v", filepath.Join(versionsDir, entry.Name()), err)
+			continue
+		}
+		versions = append(versions, version)
+	}
+	sort.Sort(semver.Collection(versions))
+	var installed []string
+	for _, version := range versions {
+		installed = append(installed, version.Original())
+	}
+	return installed, nil
+}
+
 func (repo *Repository) Extract(path string) error {
 	bundleName := filepath.Base(path)
 
@@ -137,10 +195,16 @@ func (repo *Repository) Extract(path string) error {
 	}
 
 	bundleBaseDir := GetBundleNameWithoutExtension(bundleName)
+	return repo.moveToCache(filepath.Join(tmpDir, bundleBaseDir), bundleBaseDir)
+}
+
+// moveToCache replaces the bundle directory bundleBaseDir of the cache with
+// srcDir
+func (repo *Repository) moveToCache(srcDir, bundleBaseDir string) error {
 	bundleDir := filepath.Join(repo.CacheDir, bundleBaseDir)
 	_ = os.RemoveAll(bundleDir)
 	err := crcerrors.Retry(context.Background(), time.Minute, func() error {
-		if err := os.Rename(filepath.Join(tmpDir, bundleBaseDir), bundleDir); err != nil {
+		if err := os.Rename(srcDir, bundleDir); err != nil {
 			return &crcerrors.RetriableError{Err: err}
 		}
 		return nil
@@ -175,6 +239,16 @@ func (repo *Repository) List() ([]CrcBundleInfo, error) {
 	return ret, nil
 }
 
+// IsBundle returns true when the file of the cache directory is a bundle or
+// an extracted bundle
+func (repo *Repository) IsBundle(file os.FileInfo) bool {
+	if !file.IsDir() {
+		return strings.HasSuffix(file.Name(), bundleExtension)
+	}
+	_, err := os.Stat(filepath.Join(repo.CacheDir, file.Name(), metadataFilename))
+	return err == nil
+}
+
 func (repo *Repository) CalculateBundleSha256Sum(bundlePath string) (string, error) {
 	return sha256sum(bundlePath)
 }
@@ -36,6 +36,34 @@ func TestUse(t *testing.T) {
 	assert.Equal(t, "openshift-client", string(bin))
 }
 
+func TestInstallOpenShiftClient(t *testing.T) {
+	dir := t.TempDir()
+	versionsDir := t.TempDir()
+	createDummyBundleContent(t, dir, "crc_libvirt_4.6.1", "1.0")
+	repo := &Repository{
+		CacheDir: dir,
+		OcBinDir: t.TempDir(),
+	}
+	bundle, err := repo.Get("crc_libvirt_4.6.1.crcbundle")
+	assert.NoError(t, err)
+
+	path, err := bundle.InstallOpenShiftClient(versionsDir)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(versionsDir, "4.6.1", constants.OcExecutableName), path)
+	bin, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "openshift-client", string(bin))
+
+	// the client is kept when the bundle is removed
+	assert.NoError(t, os.RemoveAll(dir))
+	assert.NoError(t, os.MkdirAll(filepath.Join(versionsDir, "4.10.3"), 0750))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(versionsDir, "4.10.3", constants.OcExecutableName), []byte("openshift-client"), 0600))
+	assert.NoError(t, os.MkdirAll(filepath.Join(versionsDir, "4.9.0"), 0750))
+	versions, err := InstalledOpenShiftClients(versionsDir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"4.6.1", "4.10.3"}, versions)
+}
+
 func TestExtract(t *testing.T) {
 	dir, err := ioutil.TempDir("", "repo")
 	assert.NoError(t, err)
@@ -0,0 +1,19 @@
+package bundle
+
+import (
+	"github.com/code-ready/crc/pkg/crc/gpg"
+)
+
+// releaseKeyRing returns the key of the signatures of the sha256sum.txt
+// files of the mirror
+var releaseKeyRing = gpg.ReleaseKeyRing
+
+// verifiedChecksum returns the sha256 sum of fileURL from the sha256sum.txt
+// file of its directory, once its signature is checked with the release key
+func verifiedChecksum(fileURL string) ([]byte, error) {
+	keyRing, err := releaseKeyRing()
+	if err != nil {
+		return nil, err
+	}
+	return keyRing.RemoteChecksum(fileURL)
+}
@@ -0,0 +1,24 @@
+package bundle
+
+import (
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/gpg"
+	"github.com/code-ready/crc/pkg/crc/gpg/gpgtest"
+	"github.com/stretchr/testify/require"
+)
+
+// useTestKeyRing replaces the release key with a key generated for the test
+func useTestKeyRing(t *testing.T) *gpgtest.Signer {
+	signer, err := gpgtest.NewSigner()
+	require.NoError(t, err)
+	keyRing, err := signer.KeyRing()
+	require.NoError(t, err)
+	releaseKeyRing = func() (*gpg.KeyRing, error) {
+		return keyRing, nil
+	}
+	t.Cleanup(func() {
+		releaseKeyRing = gpg.ReleaseKeyRing
+	})
+	return signer
+}
@@ -0,0 +1,44 @@
+package machine
+
+import (
+	"context"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/pkg/errors"
+)
+
+// RenewExpiringCertificates renews the kubelet certificates of the running
+// cluster which are about to expire, so that the next start does not have
+// to wait for their renewal
+func (client *client) RenewExpiringCertificates(ctx context.Context) error {
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
+	if err != nil {
+		return errors.Wrap(err, "Cannot load machine")
+	}
+	defer vm.Close()
+
+	if !vm.bundle.IsOpenShift() {
+		return nil
+	}
+	vmState, err := vm.State()
+	if err != nil {
+		return errors.Wrap(err, "Cannot get machine state")
+	}
+	if vmState != state.Running {
+		return nil
+	}
+
+	sshRunner, err := vm.SSHRunner()
+	if err != nil {
+		return errors.Wrap(err, "Error creating the ssh client")
+	}
+	defer sshRunner.Close()
+
+	if err := cluster.RenewExpiringCerts(ctx, sshRunner, oc.UseOCWithSSH(sshRunner)); err != nil {
+		return err
+	}
+	client.certsExpiry.Storage.Flush()
+	return nil
+}
@@ -2,30 +2,55 @@ package machine
 
 import (
 	"context"
+	"io"
 	"time"
 
+	"github.com/code-ready/crc/pkg/crc/cluster"
 	crcConfig "github.com/code-ready/crc/pkg/crc/config"
 	"github.com/code-ready/crc/pkg/crc/machine/state"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
 	"github.com/code-ready/crc/pkg/crc/network"
 	crcPreset "github.com/code-ready/crc/pkg/crc/preset"
 	"github.com/kofalt/go-memoize"
+	"k8s.io/client-go/tools/clientcmd/api"
 )
 
 type Client interface {
 	GetName() string
 	GetConsoleURL() (*types.ConsoleResult, error)
+	GetKubeAdminToken() (string, error)
+	GetKubeconfig() (*api.Config, error)
 	ConnectionDetails() (*types.ConnectionDetails, error)
+	Addresses() ([]types.Address, error)
+	Routes() ([]types.Route, error)
 
 	Delete() error
 	Exists() (bool, error)
 	PowerOff() error
 	Start(ctx context.Context, startConfig types.StartConfig) (*types.StartResult, error)
+	Upgrade(ctx context.Context, startConfig types.StartConfig) (*types.UpgradeResult, error)
 	Status() (*types.ClusterStatusResult, error)
-	Stop() (state.State, error)
+	ComponentsUsage() ([]cluster.ComponentUsage, error)
+	Logs(ctx context.Context, logsConfig types.LogsConfig, writer io.Writer) error
+	CheckReadiness(ctx context.Context, readinessConfig types.ReadinessConfig) (*types.ReadinessResult, error)
+	Stop(stopConfig types.StopConfig) (state.State, error)
 	IsRunning() (bool, error)
 	GenerateBundle(forceStop bool) error
+	CompactDisk() (*types.CompactDiskResult, error)
+	ActiveConnections() (int, error)
+	RenewExpiringCertificates(ctx context.Context) error
 	GetPreset() crcPreset.Preset
+	InstallOpenShiftClient() (*types.OpenShiftClient, error)
+	DiagnoseNetwork() (*types.NetworkDiagnosis, error)
+	TestDNS(name string) (*types.DNSTest, error)
+	SyncTime() (time.Duration, error)
+	FlushDisks() error
+	RotateSSHKey(ctx context.Context) error
+
+	Workers() ([]types.WorkerStatus, error)
+	AddWorker(ctx context.Context, workerConfig types.WorkerConfig) (*types.WorkerStatus, error)
+	RemoveWorker(name string) (string, error)
+	StopWorker(name string) error
 }
 
 type client struct {
@@ -34,6 +59,7 @@ type client struct {
 	config crcConfig.Storage
 
 	diskDetails *memoize.Memoizer
+	certsExpiry *memoize.Memoizer
 }
 
 func NewClient(name string, debug bool, config crcConfig.Storage) Client {
@@ -42,6 +68,7 @@ func NewClient(name string, debug bool, config crcConfig.Storage) Client {
 		debug:       debug,
 		config:      config,
 		diskDetails: memoize.NewMemoizer(time.Minute, 5*time.Minute),
+		certsExpiry: memoize.NewMemoizer(10*time.Minute, 30*time.Minute),
 	}
 }
 
@@ -61,6 +88,11 @@ func (client *client) networkMode() network.Mode {
 	return crcConfig.GetNetworkMode(client.config)
 }
 
-func (client *client) monitoringEnabled() bool {
-	return client.config.Get(crcConfig.EnableClusterMonitoring).AsBool()
+// timeout returns the duration of the timeout setting key, or defaultTimeout
+// when it is not set
+func (client *client) timeout(key string, defaultTimeout time.Duration) time.Duration {
+	if timeout := crcConfig.GetDuration(client.config, key); timeout > 0 {
+		return timeout
+	}
+	return defaultTimeout
 }
@@ -0,0 +1,143 @@
+package machine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/pkg/errors"
+)
+
+// maxClockSkew is the difference between the clocks of the host and of the
+// instance above which the clock of the instance is set
+const maxClockSkew = time.Second
+
+// SyncTime sets the clock of the instance to the time of the host when they
+// differ, which happens after the host resumed from sleep. It returns how far
+// the clock of the instance was ahead of the host, 0 when it was not set.
+func (client *client) SyncTime() (time.Duration, error) {
+	var skew time.Duration
+	err := client.runInRunningInstance(func(sshRunner *ssh.Runner) error {
+		found, now, err := measureClockSkew(sshRunner)
+		if err != nil {
+			return err
+		}
+		if found <= maxClockSkew && found >= -maxClockSkew {
+			return nil
+		}
+		skew = found
+		logging.Debugf("The clock of the instance is off by %s, setting it", skew)
+		if _, _, err := sshRunner.RunPrivileged("Setting the clock", "date", "-u", "-s", fmt.Sprintf("@%d", now.Unix())); err != nil {
+			return errors.Wrap(err, "Cannot set the time of the instance")
+		}
+		return nil
+	})
+	return skew, err
+}
+
+// FlushDisks writes the data cached by the instance to its disks
+func (client *client) FlushDisks() error {
+	return client.runInRunningInstance(func(sshRunner *ssh.Runner) error {
+		if _, _, err := sshRunner.RunPrivileged("Flushing the disks", "sync"); err != nil {
+			return errors.Wrap(err, "Cannot flush the disks of the instance")
+		}
+		return nil
+	})
+}
+
+// runInRunningInstance calls run with a ssh runner of the instance, it does
+// nothing when the instance does not exist or is not running
+func (client *client) runInRunningInstance(run func(*ssh.Runner) error) error {
+	exists, err := client.Exists()
+	if err != nil || !exists {
+		return err
+	}
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
+	if err != nil {
+		return errors.Wrap(err, "Cannot load machine")
+	}
+	defer vm.Close()
+	vmState, err := vm.State()
+	if err != nil {
+		return errors.Wrap(err, "Cannot get machine state")
+	}
+	if vmState != state.Running {
+		return nil
+	}
+	sshRunner, err := vm.SSHRunner()
+	if err != nil {
+		return errors.Wrap(err, "Error creating the ssh client")
+	}
+	defer sshRunner.Close()
+	return run(sshRunner)
+}
+
+// measureClockSkew returns how far the clock of the instance is ahead of the
+// clock of the host, and the time of the host when it was measured
+func measureClockSkew(sshRunner *ssh.Runner) (time.Duration, time.Time, error) {
+	stdout, _, err := sshRunner.Run("date", "+%s")
+	if err != nil {
+		return 0, time.Time{}, errors.Wrap(err, "Cannot get the time of the instance")
+	}
+	now := time.Now()
+	skew, err := clockSkew(now, stdout)
+	return skew, now, err
+}
+
+// configureTimeSync makes chronyd follow the clock of the host, through the
+// PTP clock of the hypervisor when there is one, and step the clock of the
+// instance at any time instead of only at boot, as its clock stands still
+// while the host sleeps
+func configureTimeSync(sshRunner *ssh.Runner) error {
+	// ptp_kvm exposes the clock of the KVM host, loading it fails with the
+	// other hypervisors
+	if _, _, err := sshRunner.RunPrivileged("Loading the ptp_kvm module", "modprobe", "ptp_kvm"); err != nil {
+		logging.Debugf("Cannot load the ptp_kvm module: %v", err)
+	}
+	device, _, err := sshRunner.Run("ls /dev/ptp_hyperv /dev/ptp0 2>/dev/null | head -n 1")
+	if err != nil {
+		return err
+	}
+	config := chronyConfig(strings.TrimSpace(device))
+	current, _, err := sshRunner.Run("cat", chronyConfigPath)
+	if err == nil && current == config {
+		return nil
+	}
+	if err := sshRunner.CopyData([]byte(config), chronyConfigPath, 0644); err != nil {
+		return err
+	}
+	_, _, err = sshRunner.RunPrivileged("Restarting chronyd", "systemctl", "restart", "chronyd")
+	return err
+}
+
+const chronyConfigPath = "/etc/chrony.conf"
+
+func chronyConfig(ptpDevice string) string {
+	var config strings.Builder
+	config.WriteString("# Written by crc\n")
+	if ptpDevice != "" {
+		fmt.Fprintf(&config, "refclock PHC %s poll 2 dpoll -2 stratum 2\n", ptpDevice)
+	}
+	config.WriteString(`pool 2.rhel.pool.ntp.org iburst
+# the clock of the instance is behind after the host slept
+makestep 1.0 -1
+driftfile /var/lib/chrony/drift
+rtcsync
+logdir /var/log/chrony
+`)
+	return config.String()
+}
+
+// clockSkew returns how far the time of the instance, in seconds since the
+// epoch, is ahead of now
+func clockSkew(now time.Time, guestTime string) (time.Duration, error) {
+	seconds, err := strconv.ParseInt(strings.TrimSpace(guestTime), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid time of the instance '%s'", strings.TrimSpace(guestTime))
+	}
+	return time.Unix(seconds, 0).Sub(now.Truncate(time.Second)), nil
+}
@@ -0,0 +1,37 @@
+package machine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClockSkew(t *testing.T) {
+	now := time.Unix(1700000000, 500000000)
+
+	skew, err := clockSkew(now, "1700000000\n")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), skew)
+
+	skew, err = clockSkew(now, "1699996400")
+	assert.NoError(t, err)
+	assert.Equal(t, -time.Hour, skew)
+
+	skew, err = clockSkew(now, "1700000042")
+	assert.NoError(t, err)
+	assert.Equal(t, 42*time.Second, skew)
+
+	_, err = clockSkew(now, "Tue Nov 14 22:13:20 UTC 2023")
+	assert.Error(t, err)
+}
+
+func TestChronyConfig(t *testing.T) {
+	config := chronyConfig("/dev/ptp0")
+	assert.Contains(t, config, "refclock PHC /dev/ptp0 poll 2 dpoll -2 stratum 2\n")
+	assert.Contains(t, config, "makestep 1.0 -1\n")
+
+	config = chronyConfig("")
+	assert.NotContains(t, config, "refclock")
+	assert.Contains(t, config, "pool 2.rhel.pool.ntp.org iburst\n")
+}
@@ -0,0 +1,73 @@
+package machine
+
+import (
+	"fmt"
+	"os"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/libmachine/host"
+	"github.com/pkg/errors"
+)
+
+// CompactDisk shrinks the disk image of the stopped instance on the host,
+// releasing the blocks which were discarded by the periodic fstrim in the
+// instance
+func (client *client) CompactDisk() (*types.CompactDiskResult, error) {
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot load machine")
+	}
+	defer vm.Close()
+
+	vmState, err := vm.State()
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot get machine state")
+	}
+	if vmState != state.Stopped {
+		return nil, errors.New("The instance must be stopped to compact its disk, run 'crc stop' first")
+	}
+
+	// WSL gives the space freed in the distribution back to Windows itself
+	if vm.DriverName == crcConfig.WSL2VMDriver {
+		return nil, errors.New("The disk of the wsl2 driver cannot be compacted, it is sparse")
+	}
+	diskPath, err := diskImagePath(vm.Host)
+	if err != nil {
+		return nil, err
+	}
+	sizeBefore, err := fileSize(diskPath)
+	if err != nil {
+		return nil, err
+	}
+	logging.Infof("Compacting %s...", diskPath)
+	if err := compactDiskImage(diskPath); err != nil {
+		return nil, errors.Wrap(err, "Failed to compact the disk image")
+	}
+	sizeAfter, err := fileSize(diskPath)
+	if err != nil {
+		return nil, err
+	}
+	return &types.CompactDiskResult{
+		SizeBefore: sizeBefore,
+		SizeAfter:  sizeAfter,
+	}, nil
+}
+
+func diskImagePath(host *host.Host) (string, error) {
+	driver, err := loadDriverConfig(host)
+	if err != nil {
+		return "", errors.Wrap(err, "Cannot load driver configuration")
+	}
+	return driver.ResolveStorePath(fmt.Sprintf("%s.%s", driver.MachineName, driver.ImageFormat)), nil
+}
+
+func fileSize(path string) (int64, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
@@ -0,0 +1,25 @@
+package machine
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine/hyperkit"
+	crcos "github.com/code-ready/crc/pkg/os"
+)
+
+// enableDiskDiscard is a no-op, the hyperkit machine driver already
+// configures the qcow2 backend to handle discard requests, and the raw disks
+// of vfkit are not configurable
+func enableDiskDiscard(vm *virtualMachine) error {
+	return nil
+}
+
+func compactDiskImage(diskPath string) error {
+	if filepath.Ext(diskPath) != ".qcow2" {
+		return errors.New("Only the qcow2 disk images of the hyperkit driver can be compacted")
+	}
+	_, _, err := crcos.RunWithDefaultLocale(filepath.Join(constants.BinDir(), hyperkit.QcowToolCommand), "compact", diskPath)
+	return err
+}
@@ -0,0 +1,92 @@
+package machine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	crcos "github.com/code-ready/crc/pkg/os"
+)
+
+var (
+	qcow2DriverRegexp = regexp.MustCompile(`<driver name='qemu' type='qcow2'[^>]*>`)
+	discardRegexp     = regexp.MustCompile(`\sdiscard=`)
+)
+
+// enableDiskDiscard makes qemu pass the discard requests of the instance to
+// the qcow2 image so that trimmed blocks are released on the host. The domain
+// is created by the libvirt machine driver, which does not set it, while the
+// qemu driver always enables it.
+func enableDiskDiscard(vm *virtualMachine) error {
+	if usesQemuDriver(vm) {
+		return nil
+	}
+	return updateLibvirtDomain(vm.name, "Enabling discard on the instance disk", func(domainXML string) (string, error) {
+		updatedXML, _ := addDiskDiscard(domainXML)
+		return updatedXML, nil
+	})
+}
+
+func addDiskDiscard(domainXML string) (string, bool) {
+	changed := false
+	updatedXML := qcow2DriverRegexp.ReplaceAllStringFunc(domainXML, func(driver string) string {
+		if discardRegexp.MatchString(driver) {
+			return driver
+		}
+		changed = true
+		end := strings.TrimSuffix(driver, ">")
+		if strings.HasSuffix(end, "/") {
+			return strings.TrimSuffix(end, "/") + " discard='unmap'/>"
+		}
+		return end + " discard='unmap'>"
+	})
+	return updatedXML, changed
+}
+
+// diskImageInfo is the part of the output of 'qemu-img info' about the
+// backing file of an image
+type diskImageInfo struct {
+	BackingFilename       string `json:"backing-filename"`
+	BackingFilenameFormat string `json:"backing-filename-format"`
+}
+
+// compactDiskImage rewrites the image without its unallocated and zeroed
+// clusters. An overlay keeps its backing file, only its own clusters are
+// rewritten.
+func compactDiskImage(diskPath string) error {
+	stdout, stderr, err := crcos.RunWithDefaultLocale("qemu-img", "info", "--output=json", diskPath)
+	if err != nil {
+		return fmt.Errorf("Cannot get the information of %s: %v: %s", diskPath, err, stderr)
+	}
+	var info diskImageInfo
+	if err := json.Unmarshal([]byte(stdout), &info); err != nil {
+		return fmt.Errorf("Cannot parse the information of %s: %w", diskPath, err)
+	}
+	tmpPath := filepath.Join(filepath.Dir(diskPath), "compact-"+filepath.Base(diskPath))
+	if _, stderr, err := crcos.RunWithDefaultLocale("qemu-img", compactArgs(diskPath, tmpPath, info)...); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("%v: %s", err, stderr)
+	}
+	if err := os.Rename(tmpPath, diskPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// compactArgs returns the arguments of 'qemu-img convert' to copy the image,
+// the backing file is given as it is recorded in the image, relative paths
+// are relative to the directory of the image
+func compactArgs(diskPath, tmpPath string, info diskImageInfo) []string {
+	args := []string{"convert", "-f", "qcow2", "-O", "qcow2"}
+	if info.BackingFilename != "" {
+		args = append(args, "-B", info.BackingFilename)
+		if info.BackingFilenameFormat != "" {
+			args = append(args, "-o", "backing_fmt="+info.BackingFilenameFormat)
+		}
+	}
+	return append(args, diskPath, tmpPath)
+}
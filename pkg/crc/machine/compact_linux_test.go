@@ -0,0 +1,29 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddDiskDiscard(t *testing.T) {
+	domainXML := `<disk type='file' device='disk'>
+      <driver name='qemu' type='qcow2' cache='default' io='threads'/>
+      <source file='/home/user/.crc/machines/crc/crc.qcow2'/>
+    </disk>`
+
+	updatedXML, changed := addDiskDiscard(domainXML)
+	assert.True(t, changed)
+	assert.Contains(t, updatedXML, "<driver name='qemu' type='qcow2' cache='default' io='threads' discard='unmap'/>")
+
+	_, changed = addDiskDiscard(updatedXML)
+	assert.False(t, changed)
+}
+
+func TestCompactArgs(t *testing.T) {
+	assert.Equal(t, []string{"convert", "-f", "qcow2", "-O", "qcow2", "/crc/crc.qcow2", "/crc/compact-crc.qcow2"},
+		compactArgs("/crc/crc.qcow2", "/crc/compact-crc.qcow2", diskImageInfo{}))
+
+	assert.Equal(t, []string{"convert", "-f", "qcow2", "-O", "qcow2", "-B", "../cache/crc.qcow2", "-o", "backing_fmt=qcow2", "/crc/crc.qcow2", "/crc/compact-crc.qcow2"},
+		compactArgs("/crc/crc.qcow2", "/crc/compact-crc.qcow2", diskImageInfo{BackingFilename: "../cache/crc.qcow2", BackingFilenameFormat: "qcow2"}))
+}
@@ -0,0 +1,18 @@
+package machine
+
+import (
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/os/windows/powershell"
+)
+
+// enableDiskDiscard is a no-op, dynamically expanding VHDX disks support
+// discard requests out of the box
+func enableDiskDiscard(vm *virtualMachine) error {
+	return nil
+}
+
+func compactDiskImage(diskPath string) error {
+	_, _, err := powershell.Execute("Hyper-V\\Optimize-VHD", "-Path", fmt.Sprintf("'%s'", diskPath), "-Mode", "Full")
+	return err
+}
@@ -21,6 +21,10 @@ type MachineConfig struct {
 	Initramfs     string
 	Kernel        string
 
+	// Driver of the instance, libvirt or qemu on Linux, hyperkit or vfkit
+	// on macOS
+	VMDriver string
+
 	// Experimental features
 	NetworkMode network.Mode
 }
@@ -1,8 +1,13 @@
 package machine
 
 import (
+	"context"
+	"encoding/base64"
 	"fmt"
+	"time"
 
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	"github.com/code-ready/crc/pkg/crc/machine/state"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
 	"github.com/pkg/errors"
 )
@@ -27,7 +32,7 @@ func (client *client) GetConsoleURL() (*types.ConsoleResult, error) {
 		return nil, errors.Wrap(err, "Error getting the state for virtual machine")
 	}
 
-	clusterConfig, err := getClusterConfig(vm.bundle)
+	clusterConfig, err := getClusterConfig(vm.name, vm.bundle)
 	if err != nil {
 		return nil, errors.Wrap(err, "Error loading cluster configuration")
 	}
@@ -37,3 +42,30 @@ func (client *client) GetConsoleURL() (*types.ConsoleResult, error) {
 		State:         vmState,
 	}, nil
 }
+
+// GetKubeAdminToken logs in the cluster as kubeadmin and returns the bearer
+// token which can be used with 'oc login --token'
+func (client *client) GetKubeAdminToken() (string, error) {
+	consoleResult, err := client.GetConsoleURL()
+	if err != nil {
+		return "", err
+	}
+	if consoleResult.State != state.Running {
+		return "", errors.New("The OpenShift cluster is not running, cannot request a token")
+	}
+	caCert, err := base64.StdEncoding.DecodeString(consoleResult.ClusterConfig.ClusterCACert)
+	if err != nil {
+		return "", errors.Wrap(err, "Cannot decode the cluster CA certificate")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	hostPorts, err := loadHostPorts(client.name)
+	if err != nil {
+		return "", errors.Wrap(err, "Cannot read the host ports of the instance")
+	}
+	token, err := cluster.RequestBearerToken(ctx, consoleResult.ClusterConfig.ClusterAPI, hostPorts.HTTPS, caCert, "kubeadmin", consoleResult.ClusterConfig.KubeAdminPass)
+	if err != nil {
+		return "", errors.Wrap(err, "Cannot request a token for kubeadmin")
+	}
+	return token, nil
+}
@@ -0,0 +1,100 @@
+package machine
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/pkg/errors"
+)
+
+// dataLayout lists the files and directories written by crc on the host
+type dataLayout struct {
+	instanceDir string
+	machinesDir string
+	statePaths  []string
+	cacheDir    string
+	cachePaths  []string
+}
+
+func defaultDataLayout(name string) dataLayout {
+	return dataLayout{
+		instanceDir: filepath.Join(constants.MachineInstanceDir, name),
+		machinesDir: constants.MachineInstanceDir,
+		statePaths:  []string{constants.PortForwardsPath, constants.UpgradeStatePath, constants.UpgradeBackupDir, constants.StatsPath},
+		cacheDir:    constants.MachineCacheDir,
+		cachePaths:  []string{constants.BundleChunksDir, constants.ImageCacheDir, constants.DaemonSocketPath},
+	}
+}
+
+// DeletePaths returns the existing files and directories removed by a
+// deletion of the instance with deleteConfig
+func DeletePaths(name string, deleteConfig types.DeleteConfig) ([]string, error) {
+	return defaultDataLayout(name).paths(deleteConfig)
+}
+
+// RemovePaths removes the files and directories returned by DeletePaths, the
+// instance must be deleted first
+func RemovePaths(paths []string) error {
+	for _, path := range paths {
+		if err := os.RemoveAll(path); err != nil {
+			return errors.Wrapf(err, "Cannot remove %s", path)
+		}
+	}
+	return nil
+}
+
+func (layout dataLayout) paths(deleteConfig types.DeleteConfig) ([]string, error) {
+	var paths []string
+	switch deleteConfig.Scope {
+	case types.DeleteInstance:
+		paths = []string{layout.instanceDir}
+	case types.DeleteState, types.DeleteAll:
+		paths = append([]string{layout.machinesDir}, layout.statePaths...)
+	default:
+		return nil, errors.Errorf("Unknown deletion scope '%s'", deleteConfig.Scope)
+	}
+	if deleteConfig.Scope == types.DeleteAll {
+		if deleteConfig.KeepBundleCache {
+			cachePaths, err := cacheWithoutBundles(layout.cacheDir)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, cachePaths...)
+		} else {
+			paths = append(paths, layout.cacheDir)
+		}
+		paths = append(paths, layout.cachePaths...)
+	}
+
+	var existing []string
+	for _, path := range paths {
+		if _, err := os.Lstat(path); err == nil {
+			existing = append(existing, path)
+		}
+	}
+	return existing, nil
+}
+
+// cacheWithoutBundles returns the entries of the cache directory which are
+// neither a bundle nor an extracted bundle
+func cacheWithoutBundles(cacheDir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(cacheDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	repo := &bundle.Repository{CacheDir: cacheDir}
+	var paths []string
+	for _, entry := range entries {
+		if !repo.IsBundle(entry) {
+			paths = append(paths, filepath.Join(cacheDir, entry.Name()))
+		}
+	}
+	return paths, nil
+}
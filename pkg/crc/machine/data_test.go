@@ -0,0 +1,67 @@
+package machine
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDataLayout(t *testing.T) dataLayout {
+	dir := t.TempDir()
+	layout := dataLayout{
+		instanceDir: filepath.Join(dir, "machines", "crc"),
+		machinesDir: filepath.Join(dir, "machines"),
+		statePaths:  []string{filepath.Join(dir, "port-forwards.json"), filepath.Join(dir, "upgrade-backup")},
+		cacheDir:    filepath.Join(dir, "cache"),
+		cachePaths:  []string{filepath.Join(dir, "chunks"), filepath.Join(dir, "crc.sock")},
+	}
+	require.NoError(t, os.MkdirAll(layout.instanceDir, 0700))
+	require.NoError(t, ioutil.WriteFile(layout.statePaths[0], []byte("[]"), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(layout.cacheDir, "crc_libvirt_4.10.3_amd64"), 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(layout.cacheDir, "crc_libvirt_4.10.3_amd64", "crc-bundle-info.json"), []byte("{}"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(layout.cacheDir, "crc_libvirt_4.10.3_amd64.crcbundle"), nil, 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(layout.cacheDir, "oc.tar.gz"), nil, 0600))
+	require.NoError(t, os.MkdirAll(layout.cachePaths[0], 0700))
+	return layout
+}
+
+func TestDataLayoutPaths(t *testing.T) {
+	layout := testDataLayout(t)
+
+	paths, err := layout.paths(types.DeleteConfig{Scope: types.DeleteInstance})
+	require.NoError(t, err)
+	assert.Equal(t, []string{layout.instanceDir}, paths)
+
+	paths, err = layout.paths(types.DeleteConfig{Scope: types.DeleteState})
+	require.NoError(t, err)
+	assert.Equal(t, []string{layout.machinesDir, layout.statePaths[0]}, paths)
+
+	paths, err = layout.paths(types.DeleteConfig{Scope: types.DeleteAll})
+	require.NoError(t, err)
+	assert.Equal(t, []string{layout.machinesDir, layout.statePaths[0], layout.cacheDir, layout.cachePaths[0]}, paths)
+
+	paths, err = layout.paths(types.DeleteConfig{Scope: types.DeleteAll, KeepBundleCache: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{layout.machinesDir, layout.statePaths[0], filepath.Join(layout.cacheDir, "oc.tar.gz"), layout.cachePaths[0]}, paths)
+
+	_, err = layout.paths(types.DeleteConfig{Scope: "vm"})
+	assert.EqualError(t, err, "Unknown deletion scope 'vm'")
+}
+
+func TestRemovePaths(t *testing.T) {
+	layout := testDataLayout(t)
+	paths, err := layout.paths(types.DeleteConfig{Scope: types.DeleteAll, KeepBundleCache: true})
+	require.NoError(t, err)
+	require.NoError(t, RemovePaths(paths))
+
+	paths, err = layout.paths(types.DeleteConfig{Scope: types.DeleteAll})
+	require.NoError(t, err)
+	assert.Equal(t, []string{layout.cacheDir}, paths)
+	_, err = os.Stat(filepath.Join(layout.cacheDir, "crc_libvirt_4.10.3_amd64.crcbundle"))
+	assert.NoError(t, err)
+}
@@ -8,6 +8,10 @@ import (
 )
 
 func (client *client) Delete() error {
+	if err := client.deleteWorkers(); err != nil {
+		return errors.Wrap(err, "Cannot remove the worker nodes")
+	}
+
 	vm, err := loadVirtualMachine(client.name, client.useVSock())
 	if err != nil && !errors.Is(err, errInvalidBundleMetadata) {
 		return errors.Wrap(err, "Cannot load machine")
@@ -18,6 +22,10 @@ func (client *client) Delete() error {
 		return errors.Wrap(err, "Cannot remove machine")
 	}
 
+	if err := removeSharedDirs(); err != nil {
+		logging.Warnf("Failed to remove the shared directories: %v", err)
+	}
+
 	// In case usermode networking make sure all the port bind on host should be released
 	if client.useVSock() {
 		if err := unexposePorts(); err != nil {
@@ -0,0 +1,196 @@
+package machine
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
+	crctls "github.com/code-ready/crc/pkg/crc/tls"
+	"github.com/pkg/errors"
+)
+
+// The cluster domain is recorded when the instance is created, changing the
+// cluster-domain setting afterwards requires to delete the instance.
+func clusterDomainPath(name string) string {
+	return filepath.Join(constants.MachineInstanceDir, name, "cluster-domain")
+}
+
+func saveClusterDomain(name, domain string) error {
+	return ioutil.WriteFile(clusterDomainPath(name), []byte(domain), 0600)
+}
+
+// loadClusterDomain returns an empty string when the instance uses the
+// domain of the bundle
+func loadClusterDomain(name string) (string, error) {
+	data, err := ioutil.ReadFile(clusterDomainPath(name))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// the serving certificate is renewed when it expires within this duration
+const clusterDomainCertRenewal = 30 * 24 * time.Hour
+
+func clusterDomainCAPath(name string) string {
+	return filepath.Join(constants.MachineInstanceDir, name, "cluster-domain-ca.crt")
+}
+
+func clusterDomainCAKeyPath(name string) string {
+	return filepath.Join(constants.MachineInstanceDir, name, "cluster-domain-ca.key")
+}
+
+func clusterDomainCertPath(name string) string {
+	return filepath.Join(constants.MachineInstanceDir, name, "cluster-domain.crt")
+}
+
+func clusterDomainKeyPath(name string) string {
+	return filepath.Join(constants.MachineInstanceDir, name, "cluster-domain.key")
+}
+
+// clusterDomainCA returns the CA which signed the certificate of the API
+// server and of the router for the custom cluster domain
+func clusterDomainCA(name string) ([]byte, error) {
+	return ioutil.ReadFile(clusterDomainCAPath(name))
+}
+
+// clusterDomainCertificate returns the serving certificate and key for the
+// custom cluster domain. They are generated with their CA the first time,
+// and the certificate is generated again when it is about to expire.
+func clusterDomainCertificate(name string, bundleInfo *bundle.CrcBundleInfo) ([]byte, []byte, error) {
+	cert, certErr := ioutil.ReadFile(clusterDomainCertPath(name))
+	key, keyErr := ioutil.ReadFile(clusterDomainKeyPath(name))
+	if certErr == nil && keyErr == nil && !certificateExpiresSoon(cert, time.Now()) {
+		return cert, key, nil
+	}
+
+	caKey, caCert, err := clusterDomainSigner(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	certKey, servingCert, err := crctls.GenerateSignedCertificate(caKey, caCert, &crctls.CertCfg{
+		Subject:      pkix.Name{CommonName: bundleInfo.GetAPIHostname()},
+		DNSNames:     clusterDomainDNSNames(bundleInfo),
+		KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		Validity:     crctls.ValidityOneYear,
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Cannot generate the certificate of the cluster domain")
+	}
+	cert = crctls.CertToPem(servingCert)
+	key = crctls.PrivateKeyToPem(certKey)
+	if err := ioutil.WriteFile(clusterDomainKeyPath(name), key, 0600); err != nil {
+		return nil, nil, err
+	}
+	if err := ioutil.WriteFile(clusterDomainCertPath(name), cert, 0600); err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+// clusterDomainDNSNames lists the names served with the certificate of the
+// custom domain. The router only has one default certificate, so it must
+// also be valid for the routes in the domain of the bundle.
+func clusterDomainDNSNames(bundleInfo *bundle.CrcBundleInfo) []string {
+	return []string{
+		bundleInfo.GetAPIHostname(),
+		fmt.Sprintf("*.%s", bundleInfo.GetAppsDomain()),
+		fmt.Sprintf("*.%s", bundleInfo.ClusterInfo.AppsDomain),
+	}
+}
+
+// clusterDomainSigner loads the CA of the custom cluster domain, it is
+// generated when it does not exist yet
+func clusterDomainSigner(name string) (*rsa.PrivateKey, *x509.Certificate, error) {
+	caCertPEM, certErr := ioutil.ReadFile(clusterDomainCAPath(name))
+	caKeyPEM, keyErr := ioutil.ReadFile(clusterDomainCAKeyPath(name))
+	if certErr == nil && keyErr == nil {
+		certBlock, _ := pem.Decode(caCertPEM)
+		keyBlock, _ := pem.Decode(caKeyPEM)
+		if certBlock == nil || keyBlock == nil {
+			return nil, nil, fmt.Errorf("Invalid CA of the cluster domain in %s", clusterDomainCAPath(name))
+		}
+		caCert, err := x509.ParseCertificate(certBlock.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		caKey, err := x509.ParsePKCS1PrivateKey(keyBlock.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return caKey, caCert, nil
+	}
+
+	caKey, caCert, err := crctls.GenerateSelfSignedCertificate(&crctls.CertCfg{
+		Subject:   pkix.Name{CommonName: "crc-cluster-domain-signer", OrganizationalUnit: []string{"crc"}},
+		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		Validity:  crctls.ValidityTenYears,
+		IsCA:      true,
+	})
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Cannot generate the CA of the cluster domain")
+	}
+	if err := ioutil.WriteFile(clusterDomainCAKeyPath(name), crctls.PrivateKeyToPem(caKey), 0600); err != nil {
+		return nil, nil, err
+	}
+	if err := ioutil.WriteFile(clusterDomainCAPath(name), crctls.CertToPem(caCert), 0600); err != nil {
+		return nil, nil, err
+	}
+	return caKey, caCert, nil
+}
+
+func certificateExpiresSoon(certPEM []byte, now time.Time) bool {
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return true
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return true
+	}
+	return now.Add(clusterDomainCertRenewal).After(cert.NotAfter)
+}
+
+func configureClusterDomain(ctx context.Context, ocConfig oc.Config, sshRunner *crcssh.Runner, vm *virtualMachine) error {
+	cert, key, err := clusterDomainCertificate(vm.name, vm.bundle)
+	if err != nil {
+		return err
+	}
+	return cluster.ConfigureClusterDomain(ctx, ocConfig, sshRunner, cluster.ClusterDomainConfig{
+		Domain:     vm.bundle.GetClusterDomain(),
+		APIHost:    vm.bundle.GetAPIHostname(),
+		AppsDomain: vm.bundle.GetAppsDomain(),
+		Cert:       cert,
+		Key:        key,
+	})
+}
+
+// warnClusterDomainMismatch warns when the cluster-domain setting was changed
+// after the instance was created
+func warnClusterDomainMismatch(bundleInfo *bundle.CrcBundleInfo, requestedDomain string) {
+	requested := *bundleInfo
+	requested.SetClusterDomain(requestedDomain)
+	if requested.GetClusterDomain() == bundleInfo.GetClusterDomain() {
+		return
+	}
+	logging.Warnf("The instance uses the cluster domain %s, delete it to use %s",
+		bundleInfo.GetClusterDomain(), requested.GetClusterDomain())
+}
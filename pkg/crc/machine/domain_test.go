@@ -0,0 +1,39 @@
+package machine
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	crctls "github.com/code-ready/crc/pkg/crc/tls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterDomainDNSNames(t *testing.T) {
+	bundleInfo := &bundle.CrcBundleInfo{
+		ClusterInfo: bundle.ClusterInfo{
+			ClusterName: "crc",
+			BaseDomain:  "testing",
+			AppsDomain:  "apps-crc.testing",
+		},
+	}
+	bundleInfo.SetClusterDomain("crc.example.com")
+	assert.Equal(t, []string{"api.crc.example.com", "*.apps.crc.example.com", "*.apps-crc.testing"}, clusterDomainDNSNames(bundleInfo))
+}
+
+func TestCertificateExpiresSoon(t *testing.T) {
+	_, cert, err := crctls.GenerateSelfSignedCertificate(&crctls.CertCfg{
+		Subject:   pkix.Name{CommonName: "api.crc.example.com", OrganizationalUnit: []string{"crc"}},
+		KeyUsages: x509.KeyUsageDigitalSignature,
+		Validity:  crctls.ValidityOneYear,
+	})
+	require.NoError(t, err)
+	certPEM := crctls.CertToPem(cert)
+
+	assert.False(t, certificateExpiresSoon(certPEM, time.Now()))
+	assert.True(t, certificateExpiresSoon(certPEM, time.Now().Add(350*24*time.Hour)))
+	assert.True(t, certificateExpiresSoon([]byte("invalid"), time.Now()))
+}
@@ -57,3 +57,13 @@ func setDiskSize(host *host.Host, diskSizeGiB int) error {
 
 	return updateDriverValue(host, diskSizeSetter)
 }
+
+// getVMSize returns the number of CPUs and the memory size in MiB of the
+// instance
+func getVMSize(host *host.Host) (int, int, error) {
+	driver, err := loadDriverConfig(host)
+	if err != nil {
+		return 0, 0, err
+	}
+	return driver.CPU, driver.Memory, nil
+}
@@ -4,15 +4,26 @@ import (
 	"encoding/json"
 	"errors"
 
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/machine/config"
 	"github.com/code-ready/crc/pkg/crc/machine/hyperkit"
+	"github.com/code-ready/crc/pkg/crc/machine/vfkit"
+	machineVfkit "github.com/code-ready/crc/pkg/drivers/vfkit"
 	"github.com/code-ready/crc/pkg/libmachine"
 	"github.com/code-ready/crc/pkg/libmachine/host"
 	machineHyperkit "github.com/code-ready/machine/drivers/hyperkit"
+	"github.com/code-ready/machine/libmachine/drivers"
 )
 
 func newHost(api libmachine.API, machineConfig config.MachineConfig) (*host.Host, error) {
+	if machineConfig.VMDriver == crcConfig.VfkitVMDriver {
+		json, err := json.Marshal(vfkit.CreateHost(machineConfig))
+		if err != nil {
+			return nil, errors.New("Failed to marshal driver options")
+		}
+		return api.NewHost(machineVfkit.DriverName, "", json)
+	}
 	json, err := json.Marshal(hyperkit.CreateHost(machineConfig))
 	if err != nil {
 		return nil, errors.New("Failed to marshal driver options")
@@ -20,18 +31,50 @@ func newHost(api libmachine.API, machineConfig config.MachineConfig) (*host.Host
 	return api.NewHost("hyperkit", constants.BinDir(), json)
 }
 
-func loadDriverConfig(host *host.Host) (*machineHyperkit.Driver, error) {
+// darwinDriver is the configuration of the hyperkit or of the vfkit driver,
+// VMDriver is shared with the configuration of the driver in use
+type darwinDriver struct {
+	*drivers.VMDriver
+	hyperkit *machineHyperkit.Driver
+	vfkit    *machineVfkit.Driver
+}
+
+func loadDriverConfig(host *host.Host) (*darwinDriver, error) {
+	if host.DriverName == machineVfkit.DriverName {
+		var vfkitDriver machineVfkit.Driver
+		err := json.Unmarshal(host.RawDriver, &vfkitDriver)
+		return &darwinDriver{VMDriver: vfkitDriver.VMDriver, vfkit: &vfkitDriver}, err
+	}
 	var hyperkitDriver machineHyperkit.Driver
 	err := json.Unmarshal(host.RawDriver, &hyperkitDriver)
 
-	return &hyperkitDriver, err
+	return &darwinDriver{VMDriver: hyperkitDriver.VMDriver, hyperkit: &hyperkitDriver}, err
 }
 
-func updateDriverConfig(host *host.Host, driver *machineHyperkit.Driver) error {
-	driverData, err := json.Marshal(driver)
+func updateDriverConfig(host *host.Host, driver *darwinDriver) error {
+	var driverData []byte
+	var err error
+	if driver.vfkit != nil {
+		driverData, err = json.Marshal(driver.vfkit)
+	} else {
+		driverData, err = json.Marshal(driver.hyperkit)
+	}
 	if err != nil {
 		return err
 	}
 
 	return host.UpdateConfig(driverData)
 }
+
+// usesVfkitDriver returns true when the instance runs with
+// Virtualization.framework instead of hyperkit
+func usesVfkitDriver(vm *virtualMachine) bool {
+	return vm.DriverName == machineVfkit.DriverName
+}
+
+func setNestedVirtualization(vm *virtualMachine, enable bool) error {
+	if enable {
+		return drivers.ErrNotImplemented
+	}
+	return nil
+}
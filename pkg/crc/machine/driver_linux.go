@@ -4,15 +4,26 @@ import (
 	"encoding/json"
 	"errors"
 
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/machine/config"
 	"github.com/code-ready/crc/pkg/crc/machine/libvirt"
+	"github.com/code-ready/crc/pkg/crc/machine/qemu"
+	machineQemu "github.com/code-ready/crc/pkg/drivers/qemu"
 	"github.com/code-ready/crc/pkg/libmachine"
 	"github.com/code-ready/crc/pkg/libmachine/host"
 	machineLibvirt "github.com/code-ready/machine/drivers/libvirt"
+	libmachineDrivers "github.com/code-ready/machine/libmachine/drivers"
 )
 
 func newHost(api libmachine.API, machineConfig config.MachineConfig) (*host.Host, error) {
+	if machineConfig.VMDriver == crcConfig.QemuVMDriver {
+		json, err := json.Marshal(qemu.CreateHost(machineConfig))
+		if err != nil {
+			return nil, errors.New("Failed to marshal driver options")
+		}
+		return api.NewHost(machineQemu.DriverName, "", json)
+	}
 	json, err := json.Marshal(libvirt.CreateHost(machineConfig))
 	if err != nil {
 		return nil, errors.New("Failed to marshal driver options")
@@ -20,24 +31,49 @@ func newHost(api libmachine.API, machineConfig config.MachineConfig) (*host.Host
 	return api.NewHost("libvirt", constants.BinDir(), json)
 }
 
+// linuxDriver is the configuration of the libvirt or of the qemu driver,
+// VMDriver is shared with the configuration of the driver in use
+type linuxDriver struct {
+	*libmachineDrivers.VMDriver
+	libvirt *machineLibvirt.Driver
+	qemu    *machineQemu.Driver
+}
+
 /* FIXME: host.Host is only known here, and libvirt.Driver is only accessible
  * in libvirt/driver_linux.go
  */
-func loadDriverConfig(host *host.Host) (*machineLibvirt.Driver, error) {
+func loadDriverConfig(host *host.Host) (*linuxDriver, error) {
+	if host.DriverName == machineQemu.DriverName {
+		var qemuDriver machineQemu.Driver
+		err := json.Unmarshal(host.RawDriver, &qemuDriver)
+		return &linuxDriver{VMDriver: qemuDriver.VMDriver, qemu: &qemuDriver}, err
+	}
 	var libvirtDriver machineLibvirt.Driver
 	err := json.Unmarshal(host.RawDriver, &libvirtDriver)
 
-	return &libvirtDriver, err
+	return &linuxDriver{VMDriver: libvirtDriver.VMDriver, libvirt: &libvirtDriver}, err
 }
 
-func updateDriverConfig(host *host.Host, driver *machineLibvirt.Driver) error {
-	driverData, err := json.Marshal(driver)
+func updateDriverConfig(host *host.Host, driver *linuxDriver) error {
+	var driverData []byte
+	var err error
+	if driver.qemu != nil {
+		driverData, err = json.Marshal(driver.qemu)
+	} else {
+		driverData, err = json.Marshal(driver.libvirt)
+	}
 	if err != nil {
 		return err
 	}
 	return host.UpdateConfig(driverData)
 }
 
+// usesQemuDriver returns true when the instance runs with the qemu driver
+// instead of libvirt
+func usesQemuDriver(vm *virtualMachine) bool {
+	return vm.DriverName == machineQemu.DriverName
+}
+
 /*
 func (r *RPCServerDriver) SetConfigRaw(data []byte, _ *struct{}) error {
 	return json.Unmarshal(data, &r.ActualDriver)
@@ -4,14 +4,25 @@ import (
 	"encoding/json"
 	"errors"
 
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
 	"github.com/code-ready/crc/pkg/crc/machine/config"
 	"github.com/code-ready/crc/pkg/crc/machine/hyperv"
+	"github.com/code-ready/crc/pkg/crc/machine/wsl"
 	machineHyperv "github.com/code-ready/crc/pkg/drivers/hyperv"
+	machineWsl "github.com/code-ready/crc/pkg/drivers/wsl"
 	"github.com/code-ready/crc/pkg/libmachine"
 	"github.com/code-ready/crc/pkg/libmachine/host"
+	"github.com/code-ready/machine/libmachine/drivers"
 )
 
 func newHost(api libmachine.API, machineConfig config.MachineConfig) (*host.Host, error) {
+	if machineConfig.VMDriver == crcConfig.WSL2VMDriver {
+		json, err := json.Marshal(wsl.CreateHost(machineConfig))
+		if err != nil {
+			return nil, errors.New("Failed to marshal driver options")
+		}
+		return api.NewHost(machineWsl.DriverName, "", json)
+	}
 	json, err := json.Marshal(hyperv.CreateHost(machineConfig))
 	if err != nil {
 		return nil, errors.New("Failed to marshal driver options")
@@ -19,17 +30,62 @@ func newHost(api libmachine.API, machineConfig config.MachineConfig) (*host.Host
 	return api.NewHost("hyperv", "", json)
 }
 
-func loadDriverConfig(host *host.Host) (*machineHyperv.Driver, error) {
+// windowsDriver is the configuration of the hyperv or of the wsl2 driver,
+// VMDriver is shared with the configuration of the driver in use
+type windowsDriver struct {
+	*drivers.VMDriver
+	hyperv *machineHyperv.Driver
+	wsl    *machineWsl.Driver
+}
+
+func loadDriverConfig(host *host.Host) (*windowsDriver, error) {
+	if host.DriverName == machineWsl.DriverName {
+		var wslDriver machineWsl.Driver
+		err := json.Unmarshal(host.RawDriver, &wslDriver)
+		return &windowsDriver{VMDriver: wslDriver.VMDriver, wsl: &wslDriver}, err
+	}
 	var hypervDriver machineHyperv.Driver
 	err := json.Unmarshal(host.RawDriver, &hypervDriver)
 
-	return &hypervDriver, err
+	return &windowsDriver{VMDriver: hypervDriver.VMDriver, hyperv: &hypervDriver}, err
 }
 
-func updateDriverConfig(host *host.Host, driver *machineHyperv.Driver) error {
-	driverData, err := json.Marshal(driver)
+func updateDriverConfig(host *host.Host, driver *windowsDriver) error {
+	var driverData []byte
+	var err error
+	if driver.wsl != nil {
+		driverData, err = json.Marshal(driver.wsl)
+	} else {
+		driverData, err = json.Marshal(driver.hyperv)
+	}
 	if err != nil {
 		return err
 	}
 	return host.UpdateConfig(driverData)
 }
+
+// usesWSL2Driver returns true when the instance runs as a WSL2 distribution
+// instead of a Hyper-V virtual machine
+func usesWSL2Driver(vm *virtualMachine) bool {
+	return vm.DriverName == machineWsl.DriverName
+}
+
+func setNestedVirtualization(vm *virtualMachine, enable bool) error {
+	driver, err := loadDriverConfig(vm.Host)
+	if err != nil {
+		return err
+	}
+	// nested virtualization is enabled for all the distributions in
+	// .wslconfig
+	if driver.wsl != nil {
+		if enable {
+			return drivers.ErrNotImplemented
+		}
+		return nil
+	}
+	if driver.hyperv.NestedVirtualization == enable {
+		return nil
+	}
+	driver.hyperv.NestedVirtualization = enable
+	return updateDriverConfig(vm.Host, driver)
+}
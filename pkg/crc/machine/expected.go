@@ -0,0 +1,41 @@
+package machine
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+)
+
+// The marker is created when a start succeeds and removed when the instance
+// is stopped or powered off by crc, so that the watchdog of the daemon can
+// tell a crash of the instance from a stop
+const expectedRunningFile = "expected-running"
+
+func expectedRunningPath(name string) string {
+	return filepath.Join(constants.MachineInstanceDir, name, expectedRunningFile)
+}
+
+// ExpectedRunning returns true when the instance was started by crc and was
+// not stopped since
+func ExpectedRunning(name string) bool {
+	_, err := os.Stat(expectedRunningPath(name))
+	return err == nil
+}
+
+func setExpectedRunning(name string, running bool) {
+	var err error
+	if running {
+		err = ioutil.WriteFile(expectedRunningPath(name), nil, 0600)
+	} else {
+		err = os.Remove(expectedRunningPath(name))
+		if os.IsNotExist(err) {
+			err = nil
+		}
+	}
+	if err != nil {
+		logging.Debugf("Cannot update the expected state of the instance: %v", err)
+	}
+}
@@ -3,11 +3,16 @@ package fakemachine
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"time"
 
+	"github.com/code-ready/crc/pkg/crc/cluster"
 	"github.com/code-ready/crc/pkg/crc/machine/state"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
 	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/crc/preset"
+	"k8s.io/client-go/tools/clientcmd/api"
 )
 
 func NewClient() *Client {
@@ -21,7 +26,20 @@ func NewFailingClient() *Client {
 }
 
 type Client struct {
-	Failing bool
+	Failing      bool
+	Connections  int
+	Certificates []cluster.CertExpiry
+	PVPoolUse    int64
+	PVPoolSize   int64
+
+	ClusterMonitoring bool
+	WorkerNodes       []types.WorkerStatus
+	// Stopped makes IsRunning return false
+	Stopped bool
+	// NetworkConflicts are returned by Status and DiagnoseNetwork
+	NetworkConflicts []network.Conflict
+	// ClockSkew is returned by Status and SyncTime
+	ClockSkew time.Duration
 }
 
 var DummyClusterConfig = types.ClusterConfig{
@@ -34,6 +52,8 @@ var DummyClusterConfig = types.ClusterConfig{
 	ProxyConfig:   nil,
 }
 
+const DummyKubeAdminToken = "sha256~7lW9Y0ROtyP4u6n3nW8LhPXpx5Hk9ggsC2FAyX0tGyA"
+
 func (c *Client) GetName() string {
 	return "crc"
 }
@@ -55,6 +75,30 @@ func (c *Client) GetConsoleURL() (*types.ConsoleResult, error) {
 	}, nil
 }
 
+func (c *Client) GetKubeAdminToken() (string, error) {
+	if c.Failing {
+		return "", errors.New("token request failed")
+	}
+	return DummyKubeAdminToken, nil
+}
+
+func (c *Client) CheckReadiness(_ context.Context, readinessConfig types.ReadinessConfig) (*types.ReadinessResult, error) {
+	result := &types.ReadinessResult{
+		Ready: !c.Failing,
+	}
+	for _, check := range readinessConfig.Checks {
+		checkResult := types.ReadinessCheckResult{
+			Check: check,
+			Ready: !c.Failing,
+		}
+		if c.Failing {
+			checkResult.Message = fmt.Sprintf("%s is not ready", check)
+		}
+		result.Checks = append(result.Checks, checkResult)
+	}
+	return result, nil
+}
+
 func (c *Client) GetProxyConfig(machineName string) (*network.ProxyConfig, error) {
 	return nil, errors.New("not implemented")
 }
@@ -63,6 +107,26 @@ func (c *Client) ConnectionDetails() (*types.ConnectionDetails, error) {
 	return nil, errors.New("not implemented")
 }
 
+func (c *Client) Addresses() ([]types.Address, error) {
+	if c.Failing {
+		return nil, errors.New("addresses failed")
+	}
+	return []types.Address{
+		{Name: "host", IP: "127.0.0.1", Description: "Address of the instance used from the host and in the hosts file"},
+		{Name: "instance", IP: "192.168.127.2", Description: "Address of the instance on the user mode network"},
+		{Name: "node", IP: "192.168.126.11", Description: "Internal IP of the OpenShift node"},
+	}, nil
+}
+
+func (c *Client) Routes() ([]types.Route, error) {
+	if c.Failing {
+		return nil, errors.New("routes failed")
+	}
+	return []types.Route{
+		{Namespace: "openshift-console", Name: "console", Host: "console-openshift-console.apps-crc.testing", Termination: "reencrypt"},
+	}, nil
+}
+
 func (c *Client) PowerOff() error {
 	if c.Failing {
 		return errors.New("poweroff failed")
@@ -77,6 +141,30 @@ func (c *Client) GenerateBundle(forceStop bool) error {
 	return nil
 }
 
+func (c *Client) CompactDisk() (*types.CompactDiskResult, error) {
+	if c.Failing {
+		return nil, errors.New("disk compaction failed")
+	}
+	return &types.CompactDiskResult{
+		SizeBefore: 35_000_000_000,
+		SizeAfter:  20_000_000_000,
+	}, nil
+}
+
+func (c *Client) ActiveConnections() (int, error) {
+	if c.Failing {
+		return 0, errors.New("cannot list connections")
+	}
+	return c.Connections, nil
+}
+
+func (c *Client) RenewExpiringCertificates(ctx context.Context) error {
+	if c.Failing {
+		return errors.New("certificate renewal failed")
+	}
+	return nil
+}
+
 func (c *Client) Start(ctx context.Context, startConfig types.StartConfig) (*types.StartResult, error) {
 	if c.Failing {
 		return nil, errors.New("Failed to start")
@@ -87,7 +175,27 @@ func (c *Client) Start(ctx context.Context, startConfig types.StartConfig) (*typ
 	}, nil
 }
 
-func (c *Client) Stop() (state.State, error) {
+func (c *Client) Upgrade(ctx context.Context, startConfig types.StartConfig) (*types.UpgradeResult, error) {
+	if c.Failing {
+		return nil, errors.New("upgrade failed")
+	}
+	return &types.UpgradeResult{
+		PreviousBundle:  "crc_libvirt_4.10.3_amd64.crcbundle",
+		PreviousVersion: "4.10.3",
+		Bundle:          "crc_libvirt_4.10.4_amd64.crcbundle",
+		Version:         "4.10.4",
+		BackupDir:       "/home/user/.crc/upgrade-backup",
+		RestoredVolumes: true,
+		RestoredClaims:  2,
+		RestoredUsers:   []string{"alice"},
+		StartResult: &types.StartResult{
+			ClusterConfig:  DummyClusterConfig,
+			KubeletStarted: true,
+		},
+	}, nil
+}
+
+func (c *Client) Stop(stopConfig types.StopConfig) (state.State, error) {
 	if c.Failing {
 		return state.Running, errors.New("stop failed")
 	}
@@ -99,13 +207,110 @@ func (c *Client) Status() (*types.ClusterStatusResult, error) {
 		return nil, errors.New("broken")
 	}
 	return &types.ClusterStatusResult{
-		CrcStatus:        state.Running,
-		OpenshiftStatus:  types.OpenshiftRunning,
-		OpenshiftVersion: "4.5.1",
-		PodmanVersion:    "3.3.1",
-		DiskUse:          10_000_000_000,
-		DiskSize:         20_000_000_000,
-		Preset:           preset.OpenShift,
+		CrcStatus:         state.Running,
+		OpenshiftStatus:   types.OpenshiftRunning,
+		OpenshiftVersion:  "4.5.1",
+		PodmanVersion:     "3.3.1",
+		DiskUse:           10_000_000_000,
+		DiskSize:          20_000_000_000,
+		PVPoolUse:         c.PVPoolUse,
+		PVPoolSize:        c.PVPoolSize,
+		ClusterMonitoring: c.ClusterMonitoring,
+		Certificates:      c.Certificates,
+		Preset:            preset.OpenShift,
+		Workers:           c.WorkerNodes,
+		NetworkConflicts:  c.NetworkConflicts,
+		ClockSkew:         c.ClockSkew,
+	}, nil
+}
+
+func (c *Client) DiagnoseNetwork() (*types.NetworkDiagnosis, error) {
+	if c.Failing {
+		return nil, errors.New("broken")
+	}
+	return &types.NetworkDiagnosis{
+		NetworkMode:    network.SystemNetworkingMode,
+		VPNInterfaces:  []network.VPNInterface{{Name: "tun0", Addresses: []string{"10.8.0.2/24"}}},
+		Running:        true,
+		IP:             "192.168.130.11",
+		RouteInterface: "crc",
+		Conflicts:      c.NetworkConflicts,
+	}, nil
+}
+
+func (c *Client) GetKubeconfig() (*api.Config, error) {
+	if c.Failing {
+		return nil, errors.New("cluster is not running")
+	}
+	cfg := api.NewConfig()
+	cfg.Clusters["api-crc-testing:6443"] = &api.Cluster{
+		Server: "https://api.crc.testing:6443",
+	}
+	for context, user := range map[string]string{"crc-admin": "kubeadmin", "crc-developer": "developer"} {
+		authInfo := user + "/api-crc-testing:6443"
+		cfg.AuthInfos[authInfo] = &api.AuthInfo{Token: "sha256~" + user}
+		cfg.Contexts[context] = &api.Context{
+			Cluster:   "api-crc-testing:6443",
+			AuthInfo:  authInfo,
+			Namespace: "default",
+		}
+	}
+	cfg.CurrentContext = "crc-admin"
+	return cfg, nil
+}
+
+func (c *Client) TestDNS(name string) (*types.DNSTest, error) {
+	if c.Failing {
+		return nil, errors.New("broken")
+	}
+	return &types.DNSTest{
+		Name:        name,
+		NetworkMode: network.UserNetworkingMode,
+		Results: []network.DNSTestResult{
+			{Path: network.HostDNSPath, Error: "lookup " + name + ": no such host"},
+			{Path: network.InstanceDNSPath, Addresses: []string{"192.168.127.2"}},
+			{Path: network.ForwarderDNSPath, Server: "127.0.0.1:55353", Addresses: []string{"127.0.0.1"}},
+		},
+	}, nil
+}
+
+func (c *Client) SyncTime() (time.Duration, error) {
+	if c.Failing {
+		return 0, errors.New("cannot set the time")
+	}
+	return c.ClockSkew, nil
+}
+
+func (c *Client) FlushDisks() error {
+	if c.Failing {
+		return errors.New("cannot flush the disks")
+	}
+	return nil
+}
+
+func (c *Client) RotateSSHKey(ctx context.Context) error {
+	if c.Failing {
+		return errors.New("The instance must be running to replace its SSH key")
+	}
+	return nil
+}
+
+func (c *Client) Logs(ctx context.Context, logsConfig types.LogsConfig, writer io.Writer) error {
+	if c.Failing {
+		return errors.New("broken")
+	}
+	_, err := fmt.Fprintf(writer, "dummy %s logs\n", logsConfig.Component)
+	return err
+}
+
+func (c *Client) ComponentsUsage() ([]cluster.ComponentUsage, error) {
+	if c.Failing {
+		return nil, errors.New("broken")
+	}
+	return []cluster.ComponentUsage{
+		{Name: "kube-apiserver", CPU: 0.35, Memory: 1_200_000_000},
+		{Name: "etcd", CPU: 0.12, Memory: 300_000_000, Disk: 500_000_000},
+		{Name: "prometheus-k8s-0", Namespace: "openshift-monitoring", CPU: 0.05, Memory: 800_000_000},
 	}, nil
 }
 
@@ -114,9 +319,76 @@ func (c *Client) Exists() (bool, error) {
 }
 
 func (c *Client) IsRunning() (bool, error) {
-	return true, nil
+	return !c.Stopped, nil
+}
+
+func (c *Client) InstallOpenShiftClient() (*types.OpenShiftClient, error) {
+	if c.Failing {
+		return nil, errors.New("oc client install failed")
+	}
+	return &types.OpenShiftClient{
+		Version: "4.6.1",
+		Path:    "/home/user/.crc/bin/oc-versions/4.6.1/oc",
+	}, nil
 }
 
 func (c *Client) GetPreset() preset.Preset {
 	return preset.OpenShift
 }
+
+func (c *Client) Workers() ([]types.WorkerStatus, error) {
+	if c.Failing {
+		return nil, errors.New("broken")
+	}
+	return c.WorkerNodes, nil
+}
+
+func (c *Client) AddWorker(ctx context.Context, workerConfig types.WorkerConfig) (*types.WorkerStatus, error) {
+	if c.Failing {
+		return nil, errors.New("adding worker node failed")
+	}
+	worker := types.WorkerStatus{
+		Name:   fmt.Sprintf("crc-worker-%d", len(c.WorkerNodes)+1),
+		State:  state.Running,
+		IP:     fmt.Sprintf("192.168.130.%d", len(c.WorkerNodes)+12),
+		Ready:  true,
+		CPUs:   workerConfig.CPUs,
+		Memory: workerConfig.Memory,
+	}
+	c.WorkerNodes = append(c.WorkerNodes, worker)
+	return &worker, nil
+}
+
+func (c *Client) RemoveWorker(name string) (string, error) {
+	if c.Failing {
+		return "", errors.New("removing worker node failed")
+	}
+	if len(c.WorkerNodes) == 0 {
+		return "", errors.New("There is no worker node")
+	}
+	if name == "" {
+		name = c.WorkerNodes[len(c.WorkerNodes)-1].Name
+	}
+	for i, worker := range c.WorkerNodes {
+		if worker.Name == name {
+			c.WorkerNodes = append(c.WorkerNodes[:i], c.WorkerNodes[i+1:]...)
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("Unknown worker node %s", name)
+}
+
+func (c *Client) StopWorker(name string) error {
+	if c.Failing {
+		return errors.New("stopping worker node failed")
+	}
+	for i, worker := range c.WorkerNodes {
+		if worker.Name == name {
+			c.WorkerNodes[i].State = state.Stopped
+			c.WorkerNodes[i].IP = ""
+			c.WorkerNodes[i].Ready = false
+			return nil
+		}
+	}
+	return fmt.Errorf("Unknown worker node %s", name)
+}
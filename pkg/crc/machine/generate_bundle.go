@@ -10,7 +10,9 @@ import (
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
 	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/code-ready/crc/pkg/crc/services/dns"
 	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
 	"github.com/code-ready/machine/libmachine/state"
 	"github.com/pkg/errors"
@@ -32,10 +34,18 @@ func (client *client) GenerateBundle(forceStop bool) error {
 		if err := cluster.RemoveOldRenderedMachineConfig(ocConfig); err != nil {
 			return errors.Wrap(err, "Error removing old rendered machine configs")
 		}
+
+		if err := client.removeWorkers(0, ocConfig); err != nil {
+			return errors.Wrap(err, "Error removing the worker nodes")
+		}
+	}
+
+	if err := stripInstanceState(sshRunner); err != nil {
+		return errors.Wrap(err, "Error removing the state specific to this instance")
 	}
 
 	// Stop the cluster
-	if _, err := client.Stop(); err != nil {
+	if _, err := client.Stop(types.StopConfig{Timeout: DefaultStopTimeout}); err != nil {
 		if forceStop {
 			if err := client.PowerOff(); err != nil {
 				return err
@@ -69,7 +79,7 @@ func (client *client) GenerateBundle(forceStop bool) error {
 	defer copier.Cleanup() //nolint
 	customBundleDir := copier.CachedPath()
 
-	if err := copier.CopyKubeConfig(); err != nil {
+	if err := copier.CopyKubeConfig(constants.KubeconfigFilePath); err != nil {
 		return err
 	}
 
@@ -105,6 +115,24 @@ func (client *client) GenerateBundle(forceStop bool) error {
 	return nil
 }
 
+// stripInstanceState removes the configuration which is specific to the host
+// running the instance. It is recreated by 'crc start' from the settings of
+// the user of the generated bundle.
+func stripInstanceState(sshRunner *crcssh.Runner) error {
+	logging.Info("Removing the state specific to this instance...")
+	if err := ensureCAIsTrustedInInstance(sshRunner, ""); err != nil {
+		return err
+	}
+	if _, _, err := sshRunner.RunPrivileged("Removing the DNS configuration", "rm", "-f", dns.DnsmasqConfPath); err != nil {
+		return err
+	}
+	if _, _, err := sshRunner.RunPrivileged("Removing the journal", "journalctl", "--rotate", "--vacuum-time=1s"); err != nil {
+		return err
+	}
+	_, _, err := sshRunner.Run("rm", "-f", "/home/core/.bash_history")
+	return err
+}
+
 func loadVM(client *client) (*bundle.CrcBundleInfo, *crcssh.Runner, error) {
 	vm, err := loadVirtualMachine(client.name, client.useVSock())
 	if err != nil {
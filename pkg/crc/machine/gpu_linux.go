@@ -0,0 +1,104 @@
+package machine
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/os/linux"
+	"github.com/pkg/errors"
+)
+
+var hostdevRegexp = regexp.MustCompile(`(?s)\n?[ \t]*<hostdev\b.*?</hostdev>`)
+
+// configureGPU assigns the GPUs bound to vfio-pci to the libvirt domain, or
+// removes them when enable is false. The libvirt machine driver never adds
+// host devices, so all the PCI host devices of the domain are managed here.
+// The qemu driver gets the devices in its configuration.
+func configureGPU(vm *virtualMachine, enable bool) error {
+	var devices []linux.PCIDevice
+	if enable {
+		var err error
+		devices, err = linux.PassthroughGPUs()
+		if err != nil {
+			return errors.Wrap(err, "Cannot list the GPUs of the host")
+		}
+		if len(devices) == 0 {
+			return errors.New("No GPU is bound to the vfio-pci driver")
+		}
+	}
+	if usesQemuDriver(vm) {
+		return setQemuPCIDevices(vm, devices)
+	}
+	return updateLibvirtDomain(vm.name, "Updating the GPUs assigned to the instance", func(domainXML string) (string, error) {
+		return addPCIHostDevices(domainXML, devices)
+	})
+}
+
+func setQemuPCIDevices(vm *virtualMachine, devices []linux.PCIDevice) error {
+	driver, err := loadDriverConfig(vm.Host)
+	if err != nil {
+		return err
+	}
+	var addresses []string
+	for _, device := range devices {
+		addresses = append(addresses, device.Address)
+	}
+	if reflect.DeepEqual(driver.qemu.PCIDevices, addresses) {
+		return nil
+	}
+	driver.qemu.PCIDevices = addresses
+	if err := updateDriverConfig(vm.Host, driver); err != nil {
+		return err
+	}
+	return vm.api.Save(vm.Host)
+}
+
+func addPCIHostDevices(domainXML string, devices []linux.PCIDevice) (string, error) {
+	updatedXML := hostdevRegexp.ReplaceAllStringFunc(domainXML, func(hostdev string) string {
+		if strings.Contains(hostdev, "type='pci'") {
+			return ""
+		}
+		return hostdev
+	})
+	if len(devices) == 0 {
+		return updatedXML, nil
+	}
+
+	var hostdevs bytes.Buffer
+	for _, device := range devices {
+		var domain, bus, slot, function int
+		if _, err := fmt.Sscanf(device.Address, "%x:%x:%x.%x", &domain, &bus, &slot, &function); err != nil {
+			return "", fmt.Errorf("Invalid PCI address %s: %v", device.Address, err)
+		}
+		fmt.Fprintf(&hostdevs, `
+    <hostdev mode='subsystem' type='pci' managed='yes'>
+      <source>
+        <address domain='0x%04x' bus='0x%02x' slot='0x%02x' function='0x%x'/>
+      </source>
+    </hostdev>`, domain, bus, slot, function)
+	}
+	end := strings.LastIndex(updatedXML, "\n  </devices>")
+	if end == -1 {
+		return "", errors.New("Invalid libvirt domain definition, it has no devices")
+	}
+	return updatedXML[:end] + hostdevs.String() + updatedXML[end:], nil
+}
+
+// gpuVendors returns the PCI vendor IDs of the GPUs assigned to the instance
+func gpuVendors() ([]string, error) {
+	devices, err := linux.PassthroughGPUs()
+	if err != nil {
+		return nil, err
+	}
+	var vendors []string
+	for _, device := range devices {
+		vendor := strings.TrimPrefix(device.Vendor, "0x")
+		if device.IsGPU() && !contains(vendors, vendor) {
+			vendors = append(vendors, vendor)
+		}
+	}
+	return vendors, nil
+}
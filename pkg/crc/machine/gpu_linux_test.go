@@ -0,0 +1,28 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/code-ready/crc/pkg/os/linux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddPCIHostDevices(t *testing.T) {
+	devices := []linux.PCIDevice{
+		{Address: "0000:01:00.0", Vendor: "0x10de", Class: "0x030000"},
+		{Address: "0000:01:00.1", Vendor: "0x10de", Class: "0x040300"},
+	}
+	updatedXML, err := addPCIHostDevices(testDomainXML, devices)
+	require.NoError(t, err)
+	assert.Contains(t, updatedXML, "<address domain='0x0000' bus='0x01' slot='0x00' function='0x0'/>")
+	assert.Contains(t, updatedXML, "<address domain='0x0000' bus='0x01' slot='0x00' function='0x1'/>\n      </source>\n    </hostdev>\n  </devices>")
+
+	sameXML, err := addPCIHostDevices(updatedXML, devices)
+	require.NoError(t, err)
+	assert.Equal(t, updatedXML, sameXML)
+
+	removedXML, err := addPCIHostDevices(updatedXML, nil)
+	require.NoError(t, err)
+	assert.Equal(t, testDomainXML, removedXML)
+}
@@ -0,0 +1,17 @@
+//go:build !linux
+// +build !linux
+
+package machine
+
+import "errors"
+
+func configureGPU(vm *virtualMachine, enable bool) error {
+	if enable {
+		return errors.New("GPU passthrough is only supported on Linux")
+	}
+	return nil
+}
+
+func gpuVendors() ([]string, error) {
+	return nil, nil
+}
@@ -0,0 +1,52 @@
+package machine
+
+import (
+	"context"
+	"time"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/hooks"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+)
+
+const hooksTimeout = 10 * time.Minute
+
+// runHooks runs the hooks of phase with the credentials of the cluster in
+// their environment. A failing hook is only reported as a warning, it
+// doesn't fail the start or the stop.
+func (client *client) runHooks(ctx context.Context, phase hooks.Phase, clusterConfig *types.ClusterConfig) {
+	configKey := crcConfig.PostStartHook
+	if phase == hooks.PreStop {
+		configKey = crcConfig.PreStopHook
+	}
+	commands := hooks.Commands(phase, client.config.Get(configKey).AsString(), constants.HooksDir)
+	if len(commands) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, hooksTimeout)
+	defer cancel()
+	if err := hooks.Run(ctx, phase, commands, hooksEnv(clusterConfig)); err != nil {
+		logging.Warnf("%v\nThe output of the hooks is in the log file", err)
+	}
+}
+
+func hooksEnv(clusterConfig *types.ClusterConfig) map[string]string {
+	env := map[string]string{
+		"CRC_HOOK_PRESET": string(clusterConfig.ClusterType),
+	}
+	if clusterConfig.KubeConfig != "" {
+		env["KUBECONFIG"] = clusterConfig.KubeConfig
+	}
+	if clusterConfig.ClusterAPI != "" {
+		env["CRC_HOOK_API_URL"] = clusterConfig.ClusterAPI
+	}
+	if clusterConfig.WebConsoleURL != "" {
+		env["CRC_HOOK_CONSOLE_URL"] = clusterConfig.WebConsoleURL
+	}
+	if clusterConfig.KubeAdminPass != "" {
+		env["CRC_HOOK_KUBEADMIN_PASSWORD"] = clusterConfig.KubeAdminPass
+	}
+	return env
+}
@@ -0,0 +1,177 @@
+package machine
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/statefile"
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
+	"github.com/pkg/errors"
+)
+
+// The host ports used instead of the default ones, because other processes
+// used them, are recorded so that the URLs and the kubeconfig of the cluster
+// do not change at each start
+const hostPortsFile = "host-ports.json"
+
+var hostPortsSchema = statefile.Schema{
+	Kind:     "host-ports",
+	Version:  1,
+	Validate: validateHostPorts,
+}
+
+func validateHostPorts(data []byte) error {
+	var ports network.HostPorts
+	if err := json.Unmarshal(data, &ports); err != nil {
+		return err
+	}
+	for _, port := range []int{ports.API, ports.HTTP, ports.HTTPS} {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("invalid host port %d", port)
+		}
+	}
+	// the ssh port was not recorded by the previous versions
+	if ports.SSH < 0 || ports.SSH > 65535 {
+		return fmt.Errorf("invalid host port %d", ports.SSH)
+	}
+	return nil
+}
+
+func hostPortsPath(name string) string {
+	return filepath.Join(constants.MachineInstanceDir, name, hostPortsFile)
+}
+
+// loadHostPorts returns the default ports when the instance uses them
+func loadHostPorts(name string) (network.HostPorts, error) {
+	data, err := statefile.Load(hostPortsPath(name), hostPortsSchema)
+	if os.IsNotExist(err) {
+		return network.DefaultHostPorts(), nil
+	}
+	if err != nil {
+		return network.HostPorts{}, err
+	}
+	var ports network.HostPorts
+	if err := json.Unmarshal(data, &ports); err != nil {
+		return network.HostPorts{}, err
+	}
+	if ports.SSH == 0 {
+		ports.SSH = network.SSHPort
+	}
+	return ports, nil
+}
+
+func saveHostPorts(name string, ports network.HostPorts) error {
+	data, err := json.Marshal(ports)
+	if err != nil {
+		return err
+	}
+	return statefile.Save(hostPortsPath(name), hostPortsSchema, data)
+}
+
+// saveSSHHostPort records the port of the host forwarded to the SSH server of
+// the instance, when the ports of the cluster are not forwarded
+func saveSSHHostPort(name string, port int) error {
+	recorded, err := loadHostPorts(name)
+	if err != nil {
+		return err
+	}
+	if recorded.SSH == port {
+		return nil
+	}
+	recorded.SSH = port
+	return saveHostPorts(name, recorded)
+}
+
+// selectHostPorts returns the host ports forwarded to the cluster, the
+// configured ones or, when alternative is true and other processes use them,
+// free ones. The ports already exposed by the daemon are used by the instance
+// itself. The selected ports are recorded for the URLs and the kubeconfig.
+func selectHostPorts(name string, wanted network.HostPorts, exposed []types.ExposeRequest, loopbackAddresses []string, alternative bool) (network.HostPorts, error) {
+	recorded, err := loadHostPorts(name)
+	if err != nil {
+		return wanted, err
+	}
+	selected := wanted
+	for _, forward := range []struct {
+		guestPort int
+		hostPort  *int
+		recorded  int
+		addresses []string
+	}{
+		{network.APIPort, &selected.API, recorded.API, loopbackAddresses},
+		{network.HTTPPort, &selected.HTTP, recorded.HTTP, []string{""}},
+		{network.HTTPSPort, &selected.HTTPS, recorded.HTTPS, []string{""}},
+	} {
+		if !alternative || isExposedPort(exposed, *forward.hostPort) {
+			continue
+		}
+		conflict := network.CheckPort(forward.addresses, *forward.hostPort)
+		if conflict == nil {
+			continue
+		}
+		used := []int{selected.API, selected.HTTP, selected.HTTPS}
+		// the port chosen by a previous start is kept when it is still free
+		if !containsPort(used, forward.recorded) &&
+			(isExposedPort(exposed, forward.recorded) || network.CheckPort(forward.addresses, forward.recorded) == nil) {
+			*forward.hostPort = forward.recorded
+			continue
+		}
+		port, err := network.AlternativePort(forward.addresses, forward.guestPort, used)
+		if err != nil {
+			return wanted, err
+		}
+		logging.Warnf("%s, forwarding the port %d of the instance from the port %d of the host", conflict, forward.guestPort, port)
+		*forward.hostPort = port
+	}
+	if selected == recorded {
+		return selected, nil
+	}
+	return selected, saveHostPorts(name, selected)
+}
+
+func containsPort(ports []int, port int) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
+
+func isExposedPort(exposed []types.ExposeRequest, port int) bool {
+	for _, request := range exposed {
+		if _, p, err := net.SplitHostPort(request.Local); err == nil && p == strconv.Itoa(port) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPortConflicts returns an error listing the processes which listen on
+// the TCP addresses of the forwards
+func checkPortConflicts(forwards []types.ExposeRequest) error {
+	var conflicts []string
+	for _, forward := range forwards {
+		if forward.Protocol != types.TCP {
+			continue
+		}
+		if conflict := network.CheckAddress(forward.Local); conflict != nil {
+			conflicts = append(conflicts, conflict.String())
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	return errors.Errorf("Cannot forward the ports of the instance, %s. Stop these processes, choose other ports with the %s, %s and %s settings, "+
+		"or run 'crc config set %s true' to forward the ports of the cluster from free ports of the host",
+		strings.Join(conflicts, ", "), crcConfig.SSHPort, crcConfig.APIPort, crcConfig.IngressPorts, crcConfig.AlternativeHostPorts)
+}
@@ -0,0 +1,60 @@
+package machine
+
+import (
+	"net"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadHostPorts(t *testing.T) {
+	ports, err := loadHostPorts("not-existing-machine")
+	require.NoError(t, err)
+	assert.Equal(t, network.DefaultHostPorts(), ports)
+}
+
+func TestValidateHostPorts(t *testing.T) {
+	assert.NoError(t, validateHostPorts([]byte(`{"api": 16443, "http": 8080, "https": 8443}`)))
+	assert.NoError(t, validateHostPorts([]byte(`{"api": 16443, "http": 8080, "https": 8443, "ssh": 2022}`)))
+	assert.EqualError(t, validateHostPorts([]byte(`{"api": 16443, "http": 8080, "https": 8443, "ssh": 70000}`)), "invalid host port 70000")
+	assert.EqualError(t, validateHostPorts([]byte(`{"api": 16443, "http": 8080}`)), "invalid host port 0")
+	assert.Error(t, validateHostPorts([]byte(`[]`)))
+}
+
+func TestIsExposedPort(t *testing.T) {
+	exposed := []types.ExposeRequest{
+		{Protocol: types.TCP, Local: "127.0.0.1:6443", Remote: "192.168.127.2:6443"},
+		{Protocol: types.TCP, Local: ":443", Remote: "192.168.127.2:443"},
+	}
+	assert.True(t, isExposedPort(exposed, 6443))
+	assert.True(t, isExposedPort(exposed, 443))
+	assert.False(t, isExposedPort(exposed, 80))
+}
+
+func TestCheckPortConflicts(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	assert.NoError(t, checkPortConflicts([]types.ExposeRequest{{Protocol: types.UNIX, Local: ln.Addr().String()}}))
+	err = checkPortConflicts([]types.ExposeRequest{{Protocol: types.TCP, Local: ln.Addr().String()}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), ln.Addr().String())
+	assert.Contains(t, err.Error(), "crc config set alternative-host-ports true")
+}
+
+func TestHTTPSURL(t *testing.T) {
+	assert.Equal(t, "https://console-openshift-console.apps-crc.testing", httpsURL("console-openshift-console.apps-crc.testing", 443))
+	assert.Equal(t, "https://console-openshift-console.apps-crc.testing:8443", httpsURL("console-openshift-console.apps-crc.testing", 8443))
+}
+
+func TestSelectHostPortsWithoutAlternative(t *testing.T) {
+	// the conflicts are reported when the ports are exposed
+	wanted := network.DefaultHostPorts()
+	ports, err := selectHostPorts("not-existing-machine", wanted, nil, []string{"127.0.0.1"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, wanted, ports)
+}
@@ -1,7 +1,10 @@
 package machine
 
 import (
+	"fmt"
+
 	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
 	"github.com/pkg/errors"
 )
@@ -24,3 +27,62 @@ func (client *client) ConnectionDetails() (*types.ConnectionDetails, error) {
 		SSHKeys:     []string{constants.GetPrivateKeyPath(), constants.GetRsaPrivateKeyPath(), vm.bundle.GetSSHKeyPath()},
 	}, nil
 }
+
+// Addresses returns all the addresses relevant to the connectivity of the
+// instance: the ones used from the host, the addresses of the instance on
+// its network, and the addresses of the host seen from the instance
+func (client *client) Addresses() ([]types.Address, error) {
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot load machine")
+	}
+	defer vm.Close()
+
+	ip, err := vm.IP()
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot get IP")
+	}
+	addresses := []types.Address{
+		{Name: "host", IP: ip, Description: "Address of the instance used from the host and in the hosts file"},
+	}
+	if client.useVSock() {
+		addresses = append(addresses,
+			types.Address{Name: "instance", IP: constants.VSockVirtualMachineIP, Description: "Address of the instance on the user mode network"},
+			types.Address{Name: "gateway", IP: constants.VSockGateway, Description: "Gateway of the user mode network"},
+			types.Address{Name: "host-virtual", IP: constants.VSockHostVirtualIP, Description: "Address of the host from the instance (host.crc.testing)"},
+		)
+	} else {
+		addresses = append(addresses, types.Address{Name: "instance", IP: ip, Description: "Address of the instance on the hypervisor network"})
+		gateway, err := instanceGateway(vm)
+		if err != nil {
+			logging.Debugf("Cannot get the gateway of the instance: %v", err)
+		} else {
+			addresses = append(addresses, types.Address{Name: "gateway", IP: gateway, Description: "Gateway of the instance, the address of the host on the hypervisor network"})
+		}
+	}
+	if vm.bundle.IsOpenShift() {
+		if len(vm.bundle.Nodes) > 0 {
+			addresses = append(addresses, types.Address{Name: "node", IP: vm.bundle.Nodes[0].InternalIP, Description: "Internal IP of the OpenShift node"})
+		}
+		addresses = append(addresses, types.Address{Name: "apps", IP: ip, Description: fmt.Sprintf("Target of *.%s", vm.bundle.GetAppsDomain())})
+	}
+	return addresses, nil
+}
+
+func instanceGateway(vm *virtualMachine) (string, error) {
+	sshRunner, err := vm.SSHRunner()
+	if err != nil {
+		return "", errors.Wrap(err, "Error creating the ssh client")
+	}
+	defer sshRunner.Close()
+
+	route, _, err := sshRunner.Run("ip", "-4", "route", "show", "default")
+	if err != nil {
+		return "", err
+	}
+	gateway := defaultGateway(route)
+	if gateway == nil {
+		return "", errors.New("The instance has no default route")
+	}
+	return gateway.String(), nil
+}
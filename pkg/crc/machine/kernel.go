@@ -0,0 +1,183 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/ignition"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/pkg/errors"
+)
+
+const sysctlConfigPath = "/etc/sysctl.d/90-crc.conf"
+
+// managedKernelArguments returns the kernel arguments of the hugepages and
+// cgroups-version settings, an empty value means that the argument must not
+// be set
+func managedKernelArguments(hugePages int, cgroupsVersion string) map[string]string {
+	args := map[string]string{
+		"hugepagesz": "",
+		"hugepages":  "",
+	}
+	if hugePages > 0 {
+		args["hugepagesz"] = fmt.Sprintf("%dM", crcConfig.HugePageSize)
+		args["hugepages"] = strconv.Itoa(hugePages)
+	}
+	switch cgroupsVersion {
+	case crcConfig.CgroupsV1:
+		args["systemd.unified_cgroup_hierarchy"] = "0"
+		args["systemd.legacy_systemd_cgroup_controller"] = "1"
+	case crcConfig.CgroupsV2:
+		args["systemd.unified_cgroup_hierarchy"] = "1"
+		args["systemd.legacy_systemd_cgroup_controller"] = ""
+	}
+	return args
+}
+
+// kernelArgumentChanges returns the rpm-ostree kargs flags which turn the
+// current kernel arguments into the managed ones and the ones of the extra
+// Ignition configuration
+func kernelArgumentChanges(current []string, managed map[string]string, extra ignition.KernelArguments) []string {
+	present := map[string]bool{}
+	values := map[string][]string{}
+	for _, arg := range current {
+		present[arg] = true
+		fields := strings.SplitN(arg, "=", 2)
+		value := ""
+		if len(fields) == 2 {
+			value = fields[1]
+		}
+		values[fields[0]] = append(values[fields[0]], value)
+	}
+
+	var keys []string
+	for key := range managed {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var changes []string
+	for _, key := range keys {
+		wanted := managed[key]
+		if len(values[key]) == 1 && values[key][0] == wanted {
+			continue
+		}
+		for _, value := range values[key] {
+			changes = append(changes, fmt.Sprintf("--delete=%s=%s", key, value))
+		}
+		if wanted != "" {
+			changes = append(changes, fmt.Sprintf("--append=%s=%s", key, wanted))
+		}
+	}
+	for _, arg := range extra.ShouldExist {
+		if !present[arg] {
+			changes = append(changes, fmt.Sprintf("--append=%s", arg))
+		}
+	}
+	for _, arg := range extra.ShouldNotExist {
+		if present[arg] {
+			changes = append(changes, fmt.Sprintf("--delete=%s", arg))
+		}
+	}
+	return changes
+}
+
+// updateKernelArguments updates the kernel arguments of the ostree deployment
+// of the instance, it returns true when they changed. They are only used
+// once the instance is restarted.
+func updateKernelArguments(sshRunner *ssh.Runner, startConfig types.StartConfig) (bool, error) {
+	var extra ignition.KernelArguments
+	if startConfig.ExtraIgnition != "" {
+		ignitionConfig, err := ignition.Load(startConfig.ExtraIgnition)
+		if err != nil {
+			return false, err
+		}
+		extra = ignitionConfig.KernelArguments
+	}
+	cmdline, _, err := sshRunner.Run("cat", "/proc/cmdline")
+	if err != nil {
+		return false, err
+	}
+	changes := kernelArgumentChanges(strings.Fields(cmdline), managedKernelArguments(startConfig.HugePages, startConfig.CgroupsVersion), extra)
+	if len(changes) == 0 {
+		return false, nil
+	}
+	logging.Infof("Updating the kernel arguments of the instance: %s", strings.Join(changes, " "))
+	if _, _, err := sshRunner.RunPrivileged("Updating the kernel arguments", append([]string{"rpm-ostree", "kargs"}, changes...)...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// restartInstance stops the instance and starts it again, it returns a new
+// ssh runner since the IP of the instance may change
+func (client *client) restartInstance(ctx context.Context, vm *virtualMachine) (string, *ssh.Runner, error) {
+	// the watchdog must not report the restart as a crash
+	setExpectedRunning(client.name, false)
+	if err := shutdownInstance(vm); err != nil {
+		logging.Debugf("Cannot shut down the instance with SSH, falling back to the machine driver: %v", err)
+		if err := vm.Stop(); err != nil {
+			return "", nil, errors.Wrap(err, "Cannot stop machine")
+		}
+	}
+	if _, err := waitForShutdown(vm, DefaultStopTimeout); err != nil {
+		return "", nil, err
+	}
+	if err := startHost(ctx, vm, client.timeout(crcConfig.VMBootTimeout, defaultVMBootTimeout)); err != nil {
+		return "", nil, errors.Wrap(err, "Error starting machine")
+	}
+	instanceIP, err := vm.IP()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "Error getting the IP")
+	}
+	sshRunner, err := vm.SSHRunner()
+	if err != nil {
+		return "", nil, errors.Wrap(err, "Error creating the ssh client")
+	}
+	if err := sshRunner.WaitForConnectivity(ctx, client.timeout(crcConfig.SSHTimeout, defaultSSHTimeout)); err != nil {
+		sshRunner.Close()
+		return "", nil, errors.Wrap(err, "Failed to connect to the CRC VM with SSH -- virtual machine might be unreachable")
+	}
+	return instanceIP, sshRunner, nil
+}
+
+// configureSysctls writes the kernel parameters of the sysctls setting to a
+// sysctl.d file of the instance, and applies them when they changed
+func configureSysctls(sshRunner *ssh.Runner, sysctls map[string]string) error {
+	config := sysctlConfig(sysctls)
+	current, _, err := sshRunner.Run("cat", sysctlConfigPath)
+	if err != nil && config == "" {
+		// no parameters, and no file written by a previous start
+		return nil
+	}
+	if err == nil && current == config {
+		return nil
+	}
+	if err := sshRunner.CopyData([]byte(config), sysctlConfigPath, 0644); err != nil {
+		return err
+	}
+	_, _, err = sshRunner.RunPrivileged("Applying the kernel parameters", "sysctl", "--system")
+	return err
+}
+
+func sysctlConfig(sysctls map[string]string) string {
+	if len(sysctls) == 0 {
+		return ""
+	}
+	var keys []string
+	for key := range sysctls {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var config strings.Builder
+	config.WriteString("# Written by crc from the sysctls setting\n")
+	for _, key := range keys {
+		fmt.Fprintf(&config, "%s = %s\n", key, sysctls[key])
+	}
+	return config.String()
+}
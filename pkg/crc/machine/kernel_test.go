@@ -0,0 +1,39 @@
+package machine
+
+import (
+	"strings"
+	"testing"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/ignition"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKernelArgumentChanges(t *testing.T) {
+	cmdline := strings.Fields("BOOT_IMAGE=/vmlinuz root=UUID=1234 mitigations=off hugepagesz=2M hugepages=64")
+
+	assert.Empty(t, kernelArgumentChanges(cmdline, managedKernelArguments(64, crcConfig.CgroupsDefault), ignition.KernelArguments{}))
+
+	assert.Equal(t, []string{
+		"--delete=hugepages=64",
+		"--append=hugepages=128",
+		"--append=systemd.legacy_systemd_cgroup_controller=1",
+		"--append=systemd.unified_cgroup_hierarchy=0",
+	}, kernelArgumentChanges(cmdline, managedKernelArguments(128, crcConfig.CgroupsV1), ignition.KernelArguments{}))
+
+	assert.Equal(t, []string{
+		"--delete=hugepages=64",
+		"--delete=hugepagesz=2M",
+		"--append=nosmt",
+		"--delete=mitigations=off",
+	}, kernelArgumentChanges(cmdline, managedKernelArguments(0, crcConfig.CgroupsDefault), ignition.KernelArguments{
+		ShouldExist:    []string{"nosmt", "root=UUID=1234"},
+		ShouldNotExist: []string{"mitigations=off", "quiet"},
+	}))
+}
+
+func TestSysctlConfig(t *testing.T) {
+	assert.Empty(t, sysctlConfig(nil))
+	assert.Equal(t, "# Written by crc from the sysctls setting\nfs.inotify.max_user_watches = 524288\nvm.max_map_count = 262144\n",
+		sysctlConfig(map[string]string{"vm.max_map_count": "262144", "fs.inotify.max_user_watches": "524288"}))
+}
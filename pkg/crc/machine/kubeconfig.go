@@ -4,18 +4,25 @@ import (
 	gocontext "context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
 	"fmt"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/code-ready/crc/pkg/crc/cluster"
 	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine/state"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/openshift/oc/pkg/helpers/tokencmd"
+	"github.com/pkg/errors"
 	"k8s.io/apimachinery/third_party/forked/golang/netutil"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -46,45 +53,257 @@ func updateClientCrtAndKeyToKubeconfig(clientKey, clientCrt []byte, srcKubeconfi
 	return clientcmd.WriteToFile(*cfg, destKubeconfigPath)
 }
 
-func writeKubeconfig(ip string, clusterConfig *types.ClusterConfig) error {
-	kubeconfig := getGlobalKubeConfigPath()
-	dir := filepath.Dir(kubeconfig)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+// writeKubeconfig merges the contexts of the cluster into the default
+// kubeconfig
+func writeKubeconfig(ip string, hostPorts network.HostPorts, clusterConfig *types.ClusterConfig) error {
+	cfg, err := buildKubeconfig(ip, hostPorts, clusterConfig)
+	if err != nil {
 		return err
 	}
-	// Make sure .kube/config exist if not then this will create
-	_, _ = os.OpenFile(kubeconfig, os.O_RDONLY|os.O_CREATE, 0600)
+	_, err = MergeKubeconfig(cfg, getGlobalKubeConfigPath())
+	return err
+}
 
-	ca, err := certificateAuthority(clusterConfig.KubeConfig)
+// buildKubeconfig returns a kubeconfig with the crc-admin and crc-developer
+// contexts, it logs in the cluster to get the tokens of the users
+func buildKubeconfig(ip string, hostPorts network.HostPorts, clusterConfig *types.ClusterConfig) (*api.Config, error) {
+	ca, err := base64.StdEncoding.DecodeString(clusterConfig.ClusterCACert)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	host, err := hostname(clusterConfig.ClusterAPI)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	cfg, err := clientcmd.LoadFromFile(kubeconfig)
+	cfg := api.NewConfig()
+	cfg.Clusters[host] = &api.Cluster{
+		Server:                   clusterConfig.ClusterAPI,
+		CertificateAuthorityData: ca,
+	}
+	if err := addContext(cfg, ip, hostPorts, clusterConfig, ca, adminContext, "kubeadmin", clusterConfig.KubeAdminPass); err != nil {
+		return nil, err
+	}
+	if err := addContext(cfg, ip, hostPorts, clusterConfig, ca, developerContext, "developer", "developer"); err != nil {
+		return nil, err
+	}
+	cfg.CurrentContext = adminContext
+	return cfg, nil
+}
+
+// GetKubeconfig returns a kubeconfig with the contexts of the running cluster
+func (client *client) GetKubeconfig() (*api.Config, error) {
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
 	if err != nil {
-		return err
+		return nil, errors.Wrap(err, "Cannot load machine")
+	}
+	defer vm.Close()
+	if !vm.bundle.IsOpenShift() {
+		return nil, fmt.Errorf("Only supported with OpenShift bundles")
+	}
+	vmState, err := vm.State()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting the state for virtual machine")
+	}
+	if vmState != state.Running {
+		return nil, errors.New("The OpenShift cluster is not running, cannot log in")
+	}
+	ip, err := vm.IP()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting the IP")
 	}
+	clusterConfig, err := getClusterConfig(vm.name, vm.bundle)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error loading cluster configuration")
+	}
+	hostPorts, err := loadHostPorts(client.name)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot read the host ports of the instance")
+	}
+	return buildKubeconfig(ip, hostPorts, clusterConfig)
+}
+
+// ServiceAccountKubeconfig returns a kubeconfig with the crc-sa-<role>
+// context, which uses the token of the service account of role
+func ServiceAccountKubeconfig(clusterConfig *types.ClusterConfig, role string, token string) (*api.Config, error) {
+	ca, err := base64.StdEncoding.DecodeString(clusterConfig.ClusterCACert)
+	if err != nil {
+		return nil, err
+	}
+	host, err := hostname(clusterConfig.ClusterAPI)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := api.NewConfig()
 	cfg.Clusters[host] = &api.Cluster{
 		Server:                   clusterConfig.ClusterAPI,
 		CertificateAuthorityData: ca,
 	}
+	authInfo := fmt.Sprintf("system:serviceaccount:%s:%s/%s", cluster.ServiceAccountNamespace, cluster.ServiceAccountName(role), host)
+	cfg.AuthInfos[authInfo] = &api.AuthInfo{
+		Token: token,
+	}
+	context := fmt.Sprintf("crc-sa-%s", role)
+	cfg.Contexts[context] = &api.Context{
+		Cluster:   host,
+		AuthInfo:  authInfo,
+		Namespace: "default",
+	}
+	cfg.CurrentContext = context
+	return cfg, nil
+}
 
-	if err := addContext(cfg, ip, clusterConfig, ca, adminContext, "kubeadmin", clusterConfig.KubeAdminPass); err != nil {
-		return err
+// DefaultKubeconfigPath returns the kubeconfig updated by crc start and 'crc
+// kubeconfig --merge'
+func DefaultKubeconfigPath() string {
+	return getGlobalKubeConfigPath()
+}
+
+// RenameContexts replaces the 'crc' prefix of the names of the contexts of cfg
+// with prefix
+func RenameContexts(cfg *api.Config, prefix string) {
+	contexts := map[string]*api.Context{}
+	for name, context := range cfg.Contexts {
+		renamed := prefix + strings.TrimPrefix(name, "crc")
+		contexts[renamed] = context
+		if cfg.CurrentContext == name {
+			cfg.CurrentContext = renamed
+		}
 	}
-	if err := addContext(cfg, ip, clusterConfig, ca, developerContext, "developer", "developer"); err != nil {
-		return err
+	cfg.Contexts = contexts
+}
+
+// MergeKubeconfig adds the contexts, the clusters and the users of src to the
+// kubeconfig at path and returns the names of the merged contexts. The
+// entries of the same cluster are replaced, the entries of other clusters are
+// kept and the merged ones are renamed.
+func MergeKubeconfig(src *api.Config, path string) ([]string, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
 	}
+	dst, err := clientcmd.LoadFromFile(path)
+	switch {
+	case os.IsNotExist(err):
+		dst = api.NewConfig()
+	case err != nil:
+		return nil, err
+	}
+	contexts := mergeKubeconfig(dst, src)
+	return contexts, clientcmd.WriteToFile(*dst, path)
+}
 
-	if cfg.CurrentContext == "" {
-		cfg.CurrentContext = adminContext
+func mergeKubeconfig(dst, src *api.Config) []string {
+	ownCluster := func(name string) bool {
+		cluster, ok := dst.Clusters[name]
+		return ok && sameAPIServer(cluster.Server, src)
+	}
+	ownContext := func(name string) bool {
+		context, ok := dst.Contexts[name]
+		return ok && ownCluster(context.Cluster)
+	}
+	ownUser := func(name string) bool {
+		for _, context := range dst.Contexts {
+			if context.AuthInfo == name && !ownCluster(context.Cluster) {
+				return false
+			}
+		}
+		return true
 	}
 
-	return clientcmd.WriteToFile(*cfg, kubeconfig)
+	// the users of the replaced contexts are removed when they are no longer
+	// used
+	replacedUsers := map[string]bool{}
+	clusterNames := map[string]string{}
+	for name, cluster := range src.Clusters {
+		target := uniqueName(name, func(n string) bool { _, ok := dst.Clusters[n]; return ok && !ownCluster(n) })
+		dst.Clusters[target] = cluster
+		clusterNames[name] = target
+	}
+	userNames := map[string]string{}
+	for name, user := range src.AuthInfos {
+		target := uniqueName(name, func(n string) bool { _, ok := dst.AuthInfos[n]; return ok && !ownUser(n) })
+		dst.AuthInfos[target] = user
+		userNames[name] = target
+	}
+	var merged []string
+	for name, context := range src.Contexts {
+		targetName := uniqueName(name, func(n string) bool { _, ok := dst.Contexts[n]; return ok && !ownContext(n) })
+		if existing, ok := dst.Contexts[targetName]; ok {
+			replacedUsers[existing.AuthInfo] = true
+		}
+		dst.Contexts[targetName] = &api.Context{
+			Cluster:   clusterNames[context.Cluster],
+			AuthInfo:  userNames[context.AuthInfo],
+			Namespace: context.Namespace,
+		}
+		if name == src.CurrentContext && dst.CurrentContext == "" {
+			dst.CurrentContext = targetName
+		}
+		merged = append(merged, targetName)
+	}
+	removeUnusedEntries(dst, src, replacedUsers)
+	sort.Strings(merged)
+	return merged
+}
+
+// removeUnusedEntries removes the clusters of the API server of src and the
+// replaced users which are no longer used by a context, they were added by
+// a previous merge with another port of the API server or another user name
+func removeUnusedEntries(dst, src *api.Config, replacedUsers map[string]bool) {
+	usedClusters := map[string]bool{}
+	usedUsers := map[string]bool{}
+	for _, context := range dst.Contexts {
+		usedClusters[context.Cluster] = true
+		usedUsers[context.AuthInfo] = true
+	}
+	for name, cluster := range dst.Clusters {
+		if !usedClusters[name] && sameAPIServer(cluster.Server, src) {
+			delete(dst.Clusters, name)
+		}
+	}
+	for name := range replacedUsers {
+		if !usedUsers[name] {
+			delete(dst.AuthInfos, name)
+		}
+	}
+}
+
+// sameAPIServer returns true when server is the host of an API server of
+// cfg, on any port
+func sameAPIServer(server string, cfg *api.Config) bool {
+	for _, cluster := range cfg.Clusters {
+		if serverHost(cluster.Server) == serverHost(server) {
+			return true
+		}
+	}
+	return false
+}
+
+func serverHost(server string) string {
+	u, err := url.Parse(server)
+	if err != nil {
+		return server
+	}
+	return u.Hostname()
+}
+
+// uniqueName returns name, or name followed by a number when name is taken
+func uniqueName(name string, taken func(string) bool) string {
+	candidate := name
+	for i := 2; taken(candidate); i++ {
+		candidate = fmt.Sprintf("%s-%d", name, i)
+	}
+	return candidate
+}
+
+// UnmergeKubeconfig removes the contexts of the cluster from the kubeconfig
+// at path
+func UnmergeKubeconfig(path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	return cleanKubeconfig(path, path)
 }
 
 func certificateAuthority(kubeconfigFile string) ([]byte, error) {
@@ -122,7 +341,9 @@ func hostname(clusterAPI string) (string, error) {
 	return strings.ReplaceAll(h, ".", "-"), nil
 }
 
-func addContext(cfg *api.Config, ip string, clusterConfig *types.ClusterConfig, ca []byte, context, username, password string) error {
+// addContext requests a token for username, the OAuth server is reached with
+// the host port forwarded to the router
+func addContext(cfg *api.Config, ip string, hostPorts network.HostPorts, clusterConfig *types.ClusterConfig, ca []byte, context, username, password string) error {
 	host, err := hostname(clusterConfig.ClusterAPI)
 	if err != nil {
 		return err
@@ -142,6 +363,9 @@ func addContext(cfg *api.Config, ip string, clusterConfig *types.ClusterConfig,
 			},
 			DialContext: func(ctx gocontext.Context, network, address string) (net.Conn, error) {
 				port := strings.SplitN(address, ":", 2)[1]
+				if p, err := strconv.Atoi(port); err == nil {
+					port = strconv.Itoa(hostPorts.HostPort(p))
+				}
 				dialer := net.Dialer{
 					Timeout:   30 * time.Second,
 					KeepAlive: 30 * time.Second,
@@ -153,12 +377,14 @@ func addContext(cfg *api.Config, ip string, clusterConfig *types.ClusterConfig,
 	if err != nil {
 		return err
 	}
-	cfg.AuthInfos[username] = &api.AuthInfo{
+	// the users are named in the same way as by 'oc login'
+	authInfo := fmt.Sprintf("%s/%s", username, host)
+	cfg.AuthInfos[authInfo] = &api.AuthInfo{
 		Token: token,
 	}
 	cfg.Contexts[context] = &api.Context{
 		Cluster:   host,
-		AuthInfo:  username,
+		AuthInfo:  authInfo,
 		Namespace: "default",
 	}
 	return nil
@@ -182,9 +408,10 @@ func cleanKubeconfig(input, output string) error {
 		return err
 	}
 
+	// the API server can use another port of the host
 	var clusterNames []string
 	for name, cluster := range cfg.Clusters {
-		if cluster.Server == fmt.Sprintf("https://api%s:6443", constants.ClusterDomain) {
+		if serverHost(cluster.Server) == "api"+constants.ClusterDomain {
 			clusterNames = append(clusterNames, name)
 		}
 	}
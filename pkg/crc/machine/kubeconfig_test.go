@@ -6,7 +6,10 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/code-ready/crc/pkg/crc/machine/types"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
 )
 
 var dummyKubeconfigFileContent = `apiVersion: v1
@@ -62,3 +65,108 @@ func TestUpdateUserCaAndKeyToKubeconfig(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "dummycert", userClientCA)
 }
+
+func crcKubeconfig(server string) *api.Config {
+	cfg := api.NewConfig()
+	cfg.Clusters["api-crc-testing:6443"] = &api.Cluster{Server: server}
+	cfg.AuthInfos["kubeadmin/api-crc-testing:6443"] = &api.AuthInfo{Token: "admin-token"}
+	cfg.AuthInfos["developer/api-crc-testing:6443"] = &api.AuthInfo{Token: "developer-token"}
+	cfg.Contexts[adminContext] = &api.Context{Cluster: "api-crc-testing:6443", AuthInfo: "kubeadmin/api-crc-testing:6443", Namespace: "default"}
+	cfg.Contexts[developerContext] = &api.Context{Cluster: "api-crc-testing:6443", AuthInfo: "developer/api-crc-testing:6443", Namespace: "default"}
+	cfg.CurrentContext = adminContext
+	return cfg
+}
+
+func TestMergeKubeconfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	contexts, err := MergeKubeconfig(crcKubeconfig("https://api.crc.testing:6443"), path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{adminContext, developerContext}, contexts)
+
+	// merging again replaces the entries of the cluster
+	contexts, err = MergeKubeconfig(crcKubeconfig("https://api.crc.testing:6443"), path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{adminContext, developerContext}, contexts)
+
+	cfg, err := clientcmd.LoadFromFile(path)
+	assert.NoError(t, err)
+	assert.Len(t, cfg.Contexts, 2)
+	assert.Len(t, cfg.Clusters, 1)
+	assert.Len(t, cfg.AuthInfos, 2)
+	assert.Equal(t, adminContext, cfg.CurrentContext)
+}
+
+func TestMergeKubeconfigCollision(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	existing := api.NewConfig()
+	existing.Clusters["api-crc-testing:6443"] = &api.Cluster{Server: "https://api.example.com:6443"}
+	existing.AuthInfos["kubeadmin/api-crc-testing:6443"] = &api.AuthInfo{Token: "other-token"}
+	existing.Contexts[adminContext] = &api.Context{Cluster: "api-crc-testing:6443", AuthInfo: "kubeadmin/api-crc-testing:6443"}
+	existing.CurrentContext = adminContext
+	assert.NoError(t, clientcmd.WriteToFile(*existing, path))
+
+	contexts, err := MergeKubeconfig(crcKubeconfig("https://api.crc.testing:6443"), path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"crc-admin-2", developerContext}, contexts)
+
+	cfg, err := clientcmd.LoadFromFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.example.com:6443", cfg.Clusters["api-crc-testing:6443"].Server)
+	assert.Equal(t, "other-token", cfg.AuthInfos["kubeadmin/api-crc-testing:6443"].Token)
+	assert.Equal(t, "api-crc-testing:6443-2", cfg.Contexts["crc-admin-2"].Cluster)
+	assert.Equal(t, "kubeadmin/api-crc-testing:6443-2", cfg.Contexts["crc-admin-2"].AuthInfo)
+	assert.Equal(t, adminContext, cfg.CurrentContext)
+}
+
+func TestMergeKubeconfigOtherPort(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	_, err := MergeKubeconfig(crcKubeconfig("https://api.crc.testing:6443"), path)
+	assert.NoError(t, err)
+
+	cfg := crcKubeconfig("https://api.crc.testing:16443")
+	cfg.Clusters["api-crc-testing:16443"] = cfg.Clusters["api-crc-testing:6443"]
+	delete(cfg.Clusters, "api-crc-testing:6443")
+	for _, context := range cfg.Contexts {
+		context.Cluster = "api-crc-testing:16443"
+	}
+	_, err = MergeKubeconfig(cfg, path)
+	assert.NoError(t, err)
+
+	merged, err := clientcmd.LoadFromFile(path)
+	assert.NoError(t, err)
+	assert.Len(t, merged.Clusters, 1)
+	assert.Equal(t, "https://api.crc.testing:16443", merged.Clusters["api-crc-testing:16443"].Server)
+}
+
+func TestUnmergeKubeconfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	assert.NoError(t, UnmergeKubeconfig(path))
+
+	_, err := MergeKubeconfig(crcKubeconfig("https://api.crc.testing:6443"), path)
+	assert.NoError(t, err)
+	assert.NoError(t, UnmergeKubeconfig(path))
+	cfg, err := clientcmd.LoadFromFile(path)
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.Contexts)
+	assert.Empty(t, cfg.Clusters)
+	assert.Empty(t, cfg.AuthInfos)
+	assert.Empty(t, cfg.CurrentContext)
+}
+
+func TestRenameContexts(t *testing.T) {
+	cfg := crcKubeconfig("https://api.crc.testing:6443")
+	RenameContexts(cfg, "local")
+	assert.Contains(t, cfg.Contexts, "local-admin")
+	assert.Contains(t, cfg.Contexts, "local-developer")
+	assert.Equal(t, "local-admin", cfg.CurrentContext)
+}
+
+func TestServiceAccountKubeconfig(t *testing.T) {
+	cfg, err := ServiceAccountKubeconfig(&types.ClusterConfig{ClusterAPI: "https://api.crc.testing:6443"}, "viewer", "sa-token")
+	assert.NoError(t, err)
+	assert.Equal(t, "crc-sa-viewer", cfg.CurrentContext)
+	authInfo := cfg.Contexts["crc-sa-viewer"].AuthInfo
+	assert.Equal(t, "system:serviceaccount:crc-service-accounts:crc-viewer/api-crc-testing:6443", authInfo)
+	assert.Equal(t, "sa-token", cfg.AuthInfos[authInfo].Token)
+	assert.Equal(t, "https://api.crc.testing:6443", cfg.Clusters["api-crc-testing:6443"].Server)
+}
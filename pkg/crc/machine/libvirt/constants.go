@@ -3,7 +3,12 @@
 
 package libvirt
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+)
 
 const (
 	// Defaults
@@ -20,6 +25,17 @@ const (
 	MachineDriverVersion = "0.13.2"
 )
 
+// URI is the libvirt connection used by crc and by its libvirt machine
+// driver, the session of the user or the daemon of the system
+var URI = constants.LibvirtSystemURI
+
+// SetURI changes the libvirt connection, the machine driver started by crc
+// gets it from LIBVIRT_DEFAULT_URI
+func SetURI(uri string) error {
+	URI = uri
+	return os.Setenv("LIBVIRT_DEFAULT_URI", uri)
+}
+
 var (
 	MachineDriverDownloadURL = fmt.Sprintf("https://github.com/code-ready/machine-driver-libvirt/releases/download/%s/crc-driver-libvirt", MachineDriverVersion)
 )
@@ -0,0 +1,46 @@
+package machine
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/libvirt"
+	crcos "github.com/code-ready/crc/pkg/os"
+	"github.com/pkg/errors"
+)
+
+// updateLibvirtDomain applies update to the definition of the libvirt
+// domain, for the settings the libvirt machine driver does not support. The
+// domain is only defined again when update changed it.
+func updateLibvirtDomain(name, description string, update func(domainXML string) (string, error)) error {
+	domainXML, _, err := crcos.RunWithDefaultLocale("virsh", "--connect", libvirt.URI, "dumpxml", "--inactive", name)
+	if err != nil {
+		return errors.Wrap(err, "Cannot get the libvirt domain definition")
+	}
+	updatedXML, err := update(domainXML)
+	if err != nil {
+		return err
+	}
+	if updatedXML == domainXML {
+		return nil
+	}
+	logging.Debug(description)
+	tmpFile, err := ioutil.TempFile("", "crc-domain-*.xml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.WriteString(updatedXML); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	if _, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", libvirt.URI, "define", tmpFile.Name()); err != nil {
+		return fmt.Errorf("Cannot update the libvirt domain definition: %v: %s", err, stderr)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/preset"
+	"github.com/pkg/errors"
+)
+
+// bootstrapUnits are the systemd units preparing the node before kubelet
+// can register it
+var bootstrapUnits = []string{"crio", "NetworkManager-wait-online", "nodeip-configuration", "ovs-configuration"}
+
+const consoleLogPollInterval = 500 * time.Millisecond
+
+// Logs writes the logs of the component of the instance to writer. The serial
+// console is read from the host, so it is also available when the instance
+// doesn't boot. The kubelet and bootstrap logs are read from the journal of
+// the instance over SSH.
+func (client *client) Logs(ctx context.Context, logsConfig types.LogsConfig, writer io.Writer) error {
+	switch logsConfig.Component {
+	case types.SerialLogs:
+		return copyConsoleLog(ctx, constants.GetConsoleLogPath(), logsConfig.Follow, writer)
+	case types.KubeletLogs, types.BootstrapLogs:
+	default:
+		return fmt.Errorf("Unknown log component '%s'", logsConfig.Component)
+	}
+
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
+	if err != nil {
+		return errors.Wrap(err, "Error loading machine")
+	}
+	defer vm.Close()
+
+	vmState, err := vm.State()
+	if err != nil {
+		return errors.Wrap(err, "Cannot get machine state")
+	}
+	if vmState != state.Running {
+		return errors.New("machine is not running")
+	}
+	if logsConfig.Component == types.KubeletLogs && !vm.bundle.IsOpenShift() {
+		return fmt.Errorf("The kubelet logs are only available with the %s preset", preset.OpenShift)
+	}
+	sshRunner, err := vm.SSHRunner()
+	if err != nil {
+		return errors.Wrap(err, "Error creating the ssh client")
+	}
+	defer sshRunner.Close()
+
+	// journalctl --follow never exits, closing the connection ends the stream
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			sshRunner.Close()
+		case <-done:
+		}
+	}()
+
+	_, err = sshRunner.RunPrivilegedWithIO("reading the journal", nil, writer, journalctlCommand(logsConfig)...)
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+func journalctlCommand(logsConfig types.LogsConfig) []string {
+	cmd := []string{"journalctl", "--no-pager", "--boot"}
+	if logsConfig.Component == types.KubeletLogs {
+		cmd = append(cmd, "--unit", "kubelet")
+	} else {
+		for _, unit := range bootstrapUnits {
+			cmd = append(cmd, "--unit", unit)
+		}
+	}
+	if logsConfig.Follow {
+		cmd = append(cmd, "--follow")
+	}
+	return cmd
+}
+
+// copyConsoleLog copies the serial console log to writer. With follow, it
+// keeps polling the file for new data until ctx is cancelled.
+func copyConsoleLog(ctx context.Context, path string, follow bool, writer io.Writer) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("No serial console log found in %s, it is only available with the qemu and vfkit drivers", path)
+		}
+		return err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(writer, file); err != nil {
+		return err
+	}
+	if !follow {
+		return nil
+	}
+	ticker := time.NewTicker(consoleLogPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := io.Copy(writer, file); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package machine
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalctlCommand(t *testing.T) {
+	assert.Equal(t, []string{"journalctl", "--no-pager", "--boot", "--unit", "kubelet"},
+		journalctlCommand(types.LogsConfig{Component: types.KubeletLogs}))
+	assert.Equal(t, []string{"journalctl", "--no-pager", "--boot", "--unit", "kubelet", "--follow"},
+		journalctlCommand(types.LogsConfig{Component: types.KubeletLogs, Follow: true}))
+	assert.Equal(t, []string{"journalctl", "--no-pager", "--boot",
+		"--unit", "crio", "--unit", "NetworkManager-wait-online", "--unit", "nodeip-configuration", "--unit", "ovs-configuration"},
+		journalctlCommand(types.LogsConfig{Component: types.BootstrapLogs}))
+}
+
+func TestCopyConsoleLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "console.log")
+	require.NoError(t, os.WriteFile(path, []byte("booting\n"), 0600))
+
+	var out bytes.Buffer
+	assert.NoError(t, copyConsoleLog(context.Background(), path, false, &out))
+	assert.Equal(t, "booting\n", out.String())
+
+	missing := filepath.Join(t.TempDir(), "missing.log")
+	assert.EqualError(t, copyConsoleLog(context.Background(), missing, false, &out),
+		"No serial console log found in "+missing+", it is only available with the qemu and vfkit drivers")
+}
+
+type lockedBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.String()
+}
+
+func TestCopyConsoleLogFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "console.log")
+	require.NoError(t, os.WriteFile(path, []byte("booting\n"), 0600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := &lockedBuffer{}
+	errCh := make(chan error)
+	go func() {
+		errCh <- copyConsoleLog(ctx, path, true, out)
+	}()
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = file.WriteString("login:\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	assert.Eventually(t, func() bool {
+		return out.String() == "booting\nlogin:\n"
+	}, 5*time.Second, 100*time.Millisecond)
+	cancel()
+	assert.NoError(t, <-errCh)
+}
@@ -11,9 +11,10 @@ import (
 	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/libmachine"
 	"github.com/code-ready/machine/libmachine/drivers"
+	"github.com/pkg/errors"
 )
 
-func getClusterConfig(bundleInfo *bundle.CrcBundleInfo) (*types.ClusterConfig, error) {
+func getClusterConfig(name string, bundleInfo *bundle.CrcBundleInfo) (*types.ClusterConfig, error) {
 	if !bundleInfo.IsOpenShift() {
 		return &types.ClusterConfig{
 			ClusterType: bundleInfo.GetBundleType(),
@@ -25,7 +26,7 @@ func getClusterConfig(bundleInfo *bundle.CrcBundleInfo) (*types.ClusterConfig, e
 	if err != nil {
 		return nil, fmt.Errorf("Error reading kubeadmin password from bundle %v", err)
 	}
-	proxyConfig, err := getProxyConfig(bundleInfo.ClusterInfo.BaseDomain)
+	proxyConfig, err := getProxyConfig(bundleInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -33,17 +34,39 @@ func getClusterConfig(bundleInfo *bundle.CrcBundleInfo) (*types.ClusterConfig, e
 	if err != nil {
 		return nil, err
 	}
+	if bundleInfo.HasCustomClusterDomain() {
+		// the API server and the routes of the custom domain are signed by
+		// a CA generated by crc
+		clusterDomainCACert, err := clusterDomainCA(name)
+		if err != nil {
+			return nil, errors.Wrap(err, "Cannot read the CA of the cluster domain")
+		}
+		clusterCACert = append(clusterCACert, clusterDomainCACert...)
+	}
+	hostPorts, err := loadHostPorts(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot read the host ports of the instance")
+	}
 	return &types.ClusterConfig{
 		ClusterType:   bundleInfo.GetBundleType(),
 		ClusterCACert: base64.StdEncoding.EncodeToString(clusterCACert),
 		KubeConfig:    bundleInfo.GetKubeConfigPath(),
 		KubeAdminPass: kubeadminPassword,
-		WebConsoleURL: fmt.Sprintf("https://%s", bundleInfo.GetAppHostname("console-openshift-console")),
-		ClusterAPI:    fmt.Sprintf("https://%s:6443", bundleInfo.GetAPIHostname()),
+		WebConsoleURL: httpsURL(bundleInfo.GetAppHostname("console-openshift-console"), hostPorts.HTTPS),
+		ClusterAPI:    fmt.Sprintf("https://%s:%d", bundleInfo.GetAPIHostname(), hostPorts.API),
 		ProxyConfig:   proxyConfig,
 	}, nil
 }
 
+// httpsURL returns the URL of host, with the port when it is not the default
+// one
+func httpsURL(host string, port int) string {
+	if port == network.HTTPSPort {
+		return fmt.Sprintf("https://%s", host)
+	}
+	return fmt.Sprintf("https://%s:%d", host, port)
+}
+
 func getBundleMetadataFromDriver(driver drivers.Driver) (*bundle.CrcBundleInfo, error) {
 	bundleName, err := driver.GetBundleName()
 	if err != nil {
@@ -65,13 +88,16 @@ func createLibMachineClient() (libmachine.API, func()) {
 	}
 }
 
-func getProxyConfig(baseDomainName string) (*network.ProxyConfig, error) {
+func getProxyConfig(bundleInfo *bundle.CrcBundleInfo) (*network.ProxyConfig, error) {
 	proxy, err := network.NewProxyConfig()
 	if err != nil {
 		return nil, err
 	}
 	if proxy.IsEnabled() {
-		proxy.AddNoProxy(fmt.Sprintf(".%s", baseDomainName))
+		proxy.AddNoProxy(fmt.Sprintf(".%s", bundleInfo.ClusterInfo.BaseDomain))
+		if bundleInfo.HasCustomClusterDomain() {
+			proxy.AddNoProxy(fmt.Sprintf(".%s", bundleInfo.GetClusterDomain()))
+		}
 	}
 
 	return proxy, nil
@@ -0,0 +1,76 @@
+package machine
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	cpuRegexp           = regexp.MustCompile(`<cpu\b[^>]*?(/?)>`)
+	nestedFeatureRegexp = regexp.MustCompile(`\n?[ \t]*<feature policy='require' name='(vmx|svm)'/>`)
+)
+
+// setNestedVirtualization requires the virtualization extension of the host
+// processor in the CPU definition of the libvirt domain, the instance does
+// not start when the kvm module does not allow nested virtualization. The
+// qemu driver passes the host CPU through, it has the extension when the kvm
+// module allows it.
+func setNestedVirtualization(vm *virtualMachine, enable bool) error {
+	if usesQemuDriver(vm) {
+		return nil
+	}
+	feature := ""
+	if enable {
+		var err error
+		feature, err = virtualizationExtension()
+		if err != nil {
+			return err
+		}
+	}
+	return updateLibvirtDomain(vm.name, "Updating nested virtualization of the instance", func(domainXML string) (string, error) {
+		return setCPUFeature(domainXML, feature)
+	})
+}
+
+// setCPUFeature adds the required feature to the CPU definition, after
+// removing the one added previously. An empty feature only removes it.
+func setCPUFeature(domainXML, feature string) (string, error) {
+	updatedXML := nestedFeatureRegexp.ReplaceAllString(domainXML, "")
+	if feature == "" {
+		return updatedXML, nil
+	}
+	cpu := cpuRegexp.FindStringSubmatchIndex(updatedXML)
+	if cpu == nil {
+		return "", errors.New("Invalid libvirt domain definition, it has no CPU definition")
+	}
+	element := fmt.Sprintf("\n    <feature policy='require' name='%s'/>", feature)
+	if cpu[3] > cpu[2] {
+		// <cpu .../> becomes <cpu ...><feature/></cpu>
+		return updatedXML[:cpu[2]] + ">" + element + "\n  </cpu>" + updatedXML[cpu[1]:], nil
+	}
+	return updatedXML[:cpu[1]] + element + updatedXML[cpu[1]:], nil
+}
+
+// virtualizationExtension returns the flag of the processor virtualization
+// extension, vmx for Intel and svm for AMD
+func virtualizationExtension() (string, error) {
+	cpuinfo, err := ioutil.ReadFile("/proc/cpuinfo")
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(cpuinfo), "\n") {
+		if !strings.HasPrefix(line, "flags") {
+			continue
+		}
+		for _, flag := range strings.Fields(line) {
+			if flag == "vmx" || flag == "svm" {
+				return flag, nil
+			}
+		}
+	}
+	return "", errors.New("The processor has no virtualization extension")
+}
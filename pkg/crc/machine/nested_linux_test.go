@@ -0,0 +1,39 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetCPUFeature(t *testing.T) {
+	domainXML := `<domain type='kvm'>
+  <cpu mode='host-passthrough' check='none'>
+    <feature policy='disable' name='rdrand'/>
+  </cpu>
+</domain>`
+
+	updatedXML, err := setCPUFeature(domainXML, "vmx")
+	require.NoError(t, err)
+	assert.Equal(t, `<domain type='kvm'>
+  <cpu mode='host-passthrough' check='none'>
+    <feature policy='require' name='vmx'/>
+    <feature policy='disable' name='rdrand'/>
+  </cpu>
+</domain>`, updatedXML)
+
+	sameXML, err := setCPUFeature(updatedXML, "vmx")
+	require.NoError(t, err)
+	assert.Equal(t, updatedXML, sameXML)
+
+	removedXML, err := setCPUFeature(updatedXML, "")
+	require.NoError(t, err)
+	assert.Equal(t, domainXML, removedXML)
+}
+
+func TestSetCPUFeatureEmptyCPU(t *testing.T) {
+	updatedXML, err := setCPUFeature("<domain>\n  <cpu mode='host-passthrough'/>\n</domain>", "svm")
+	require.NoError(t, err)
+	assert.Equal(t, "<domain>\n  <cpu mode='host-passthrough'>\n    <feature policy='require' name='svm'/>\n  </cpu>\n</domain>", updatedXML)
+}
@@ -0,0 +1,128 @@
+package machine
+
+import (
+	"fmt"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/pkg/errors"
+)
+
+// DiagnoseNetwork lists the VPN clients of the host and, when the instance
+// is running, the conflicts of the network of the host with the instance
+func (client *client) DiagnoseNetwork() (*types.NetworkDiagnosis, error) {
+	vpns, err := network.VPNInterfaces()
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot list the network interfaces")
+	}
+	diagnosis := &types.NetworkDiagnosis{
+		NetworkMode:   client.networkMode(),
+		VPNCompat:     client.config.Get(crcConfig.VPNCompat).AsBool(),
+		VPNInterfaces: vpns,
+	}
+
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
+	if err != nil {
+		if errors.Is(err, errMissingHost(client.name)) {
+			return diagnosis, nil
+		}
+		return nil, errors.Wrap(err, fmt.Sprintf("Cannot load '%s' virtual machine", client.name))
+	}
+	defer vm.Close()
+	vmState, err := vm.State()
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot get machine state")
+	}
+	if vmState != state.Running {
+		return diagnosis, nil
+	}
+	if diagnosis.IP, err = vm.IP(); err != nil {
+		return nil, errors.Wrap(err, "Error getting ip")
+	}
+	diagnosis.Running = true
+	if diagnosis.NetworkMode == network.SystemNetworkingMode {
+		if diagnosis.RouteInterface, err = network.RouteInterface(diagnosis.IP); err != nil {
+			logging.Debugf("Cannot get the route to %s: %v", diagnosis.IP, err)
+		}
+	}
+	diagnosis.Conflicts = network.DetectConflicts(diagnosis.NetworkMode, diagnosis.IP, vm.bundle.GetDefaultAPIHostname())
+	return diagnosis, nil
+}
+
+// getNetworkConflicts returns the conflicts of the network of the host with
+// the running instance at ip, they are refreshed every minute
+func (client *client) getNetworkConflicts(vm *virtualMachine, ip string) []network.Conflict {
+	conflicts, err, _ := client.diskDetails.Memoize("network-conflicts", func() (interface{}, error) {
+		return network.DetectConflicts(client.networkMode(), ip, vm.bundle.GetDefaultAPIHostname()), nil
+	})
+	if err != nil {
+		return nil
+	}
+	return conflicts.([]network.Conflict)
+}
+
+// warnRouteConflicts logs the routes of the VPN clients which send the
+// traffic of the instance away from it, before crc waits for ssh
+func (client *client) warnRouteConflicts(ip string) {
+	if client.networkMode() != network.SystemNetworkingMode {
+		return
+	}
+	conflicts := network.RouteConflicts(ip)
+	for _, conflict := range conflicts {
+		logging.Warn(conflict.Description)
+	}
+	if len(conflicts) > 0 {
+		logging.Warnf("Disconnect the VPN, or use the %s network mode with 'crc config set %s true', 'crc cleanup' and 'crc setup'",
+			network.UserNetworkingMode, crcConfig.VPNCompat)
+	}
+}
+
+// TestDNS resolves name from the host, from the instance when it is running,
+// and with the DNS server of the daemon
+func (client *client) TestDNS(name string) (*types.DNSTest, error) {
+	upstreams, err := network.ParseNameServers(client.config.Get(crcConfig.NameServer).AsString())
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Invalid %s configuration", crcConfig.NameServer))
+	}
+	test := &types.DNSTest{
+		Name:        name,
+		NetworkMode: client.networkMode(),
+	}
+	test.Results = append(test.Results, network.ResolveFromHost(name))
+
+	instance, err := client.resolveInInstance(name)
+	if err != nil {
+		return nil, err
+	}
+	test.Results = append(test.Results, instance,
+		network.ResolveWithForwarder(test.NetworkMode, name, client.config.Get(crcConfig.ClusterDomain).AsString(), upstreams))
+	return test, nil
+}
+
+func (client *client) resolveInInstance(name string) (network.DNSTestResult, error) {
+	notRunning := network.DNSTestResult{Path: network.InstanceDNSPath, Skipped: true, Error: "The instance is not running"}
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
+	if err != nil {
+		if errors.Is(err, errMissingHost(client.name)) {
+			return notRunning, nil
+		}
+		return network.DNSTestResult{}, errors.Wrap(err, fmt.Sprintf("Cannot load '%s' virtual machine", client.name))
+	}
+	defer vm.Close()
+	vmState, err := vm.State()
+	if err != nil {
+		return network.DNSTestResult{}, errors.Wrap(err, "Cannot get machine state")
+	}
+	if vmState != state.Running {
+		return notRunning, nil
+	}
+	sshRunner, err := vm.SSHRunner()
+	if err != nil {
+		return network.DNSTestResult{}, errors.Wrap(err, "Error creating the ssh client")
+	}
+	defer sshRunner.Close()
+	return network.ResolveInInstance(sshRunner, name), nil
+}
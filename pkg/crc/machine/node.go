@@ -0,0 +1,197 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	crcPreset "github.com/code-ready/crc/pkg/crc/preset"
+	"github.com/code-ready/crc/pkg/crc/services"
+	"github.com/code-ready/crc/pkg/crc/services/dns"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/pkg/errors"
+)
+
+// Workers returns the state of the worker nodes
+func (client *client) Workers() ([]types.WorkerStatus, error) {
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
+	if err != nil {
+		if errors.Is(err, errMissingHost(client.name)) {
+			return client.workersStatus(nil), nil
+		}
+		return nil, errors.Wrap(err, "Cannot load machine")
+	}
+	defer vm.Close()
+
+	vmState, err := vm.State()
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot get machine state")
+	}
+	if vmState != state.Running {
+		return client.workersStatus(nil), nil
+	}
+	return client.workersStatus(vm), nil
+}
+
+// AddWorker creates a worker node with the given size and joins it to the
+// running cluster
+func (client *client) AddWorker(ctx context.Context, workerConfig types.WorkerConfig) (*types.WorkerStatus, error) {
+	if client.useVSock() {
+		return nil, fmt.Errorf("Worker nodes are only supported with the %s network mode", network.SystemNetworkingMode)
+	}
+	controlPlane, err := client.loadRunningControlPlane()
+	if err != nil {
+		return nil, err
+	}
+	defer controlPlane.Close()
+
+	existing, err := client.existingWorkers()
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= constants.MaxWorkers {
+		return nil, fmt.Errorf("The cluster already has %d worker nodes, which is the maximum", constants.MaxWorkers)
+	}
+	names, _ := selectWorkers(client.name, existing, len(existing)+1)
+	name := names[len(names)-1]
+
+	ip, err := controlPlane.IP()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting the IP")
+	}
+	sshRunner, err := controlPlane.SSHRunner()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating the ssh client")
+	}
+	defer sshRunner.Close()
+
+	worker, err := client.startWorker(ctx, name, workerConfig, controlPlane, ip)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot start worker node %s", name)
+	}
+	if err := client.updateWorkerRecords(controlPlane, sshRunner, ip); err != nil {
+		return nil, errors.Wrap(err, "Cannot add the worker node to the DNS server")
+	}
+	logging.Infof("Waiting for worker node %s to join the cluster...", name)
+	if err := cluster.JoinWorker(ctx, oc.UseOCWithSSH(sshRunner), name); err != nil {
+		return nil, errors.Wrapf(err, "Worker node %s did not join the cluster", name)
+	}
+	return &types.WorkerStatus{
+		Name:   name,
+		State:  state.Running,
+		IP:     worker.IP,
+		Ready:  true,
+		CPUs:   workerConfig.CPUs,
+		Memory: workerConfig.Memory,
+	}, nil
+}
+
+// RemoveWorker removes a worker node from the cluster and deletes its
+// instance. The last worker node is removed when name is empty. The name of
+// the removed worker node is returned.
+func (client *client) RemoveWorker(name string) (string, error) {
+	existing, err := client.existingWorkers()
+	if err != nil {
+		return "", err
+	}
+	if len(existing) == 0 {
+		return "", errors.New("There is no worker node")
+	}
+	if name == "" {
+		name = existing[len(existing)-1]
+	}
+	if !contains(existing, name) {
+		return "", fmt.Errorf("Unknown worker node %s", name)
+	}
+
+	controlPlane, err := client.loadRunningControlPlane()
+	if err != nil {
+		logging.Debugf("The worker node is not removed from the cluster: %v", err)
+		return name, removeWorker(name, nil)
+	}
+	defer controlPlane.Close()
+
+	ip, err := controlPlane.IP()
+	if err != nil {
+		return "", errors.Wrap(err, "Error getting the IP")
+	}
+	sshRunner, err := controlPlane.SSHRunner()
+	if err != nil {
+		return "", errors.Wrap(err, "Error creating the ssh client")
+	}
+	defer sshRunner.Close()
+
+	ocConfig := oc.UseOCWithSSH(sshRunner)
+	if err := removeWorker(name, &ocConfig); err != nil {
+		return "", err
+	}
+	if err := client.updateWorkerRecords(controlPlane, sshRunner, ip); err != nil {
+		logging.Warnf("Cannot remove the worker node from the DNS server: %v", err)
+	}
+	return name, nil
+}
+
+// StopWorker stops the instance of a worker node, the node stays in the
+// cluster and becomes not ready
+func (client *client) StopWorker(name string) error {
+	existing, err := client.existingWorkers()
+	if err != nil {
+		return err
+	}
+	if !contains(existing, name) {
+		return fmt.Errorf("Unknown worker node %s", name)
+	}
+	return stopWorker(name)
+}
+
+func (client *client) loadRunningControlPlane() (*virtualMachine, error) {
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot load machine")
+	}
+	vmState, err := vm.State()
+	if err != nil {
+		vm.Close()
+		return nil, errors.Wrap(err, "Cannot get machine state")
+	}
+	if vmState != state.Running {
+		vm.Close()
+		return nil, errors.New("machine is not running")
+	}
+	if !vm.bundle.IsOpenShift() {
+		vm.Close()
+		return nil, fmt.Errorf("Worker nodes are only supported with the %s preset", crcPreset.OpenShift)
+	}
+	return vm, nil
+}
+
+// updateWorkerRecords writes the addresses of the running worker nodes to the
+// DNS server of the control plane instance
+func (client *client) updateWorkerRecords(controlPlane *virtualMachine, sshRunner *ssh.Runner, controlPlaneIP string) error {
+	nameServers, err := network.ParseNameServers(client.config.Get(crcConfig.NameServer).AsString())
+	if err != nil {
+		return err
+	}
+	var workers []cluster.WorkerNode
+	for _, worker := range client.workersStatus(nil) {
+		if worker.IP != "" {
+			workers = append(workers, cluster.WorkerNode{Hostname: worker.Name, IP: worker.IP})
+		}
+	}
+	return dns.UpdateWorkerRecords(services.ServicePostStartConfig{
+		Name:           client.name,
+		SSHRunner:      sshRunner,
+		IP:             controlPlaneIP,
+		BundleMetadata: *controlPlane.bundle,
+		NetworkMode:    client.networkMode(),
+		NameServers:    nameServers,
+		Workers:        workers,
+	})
+}
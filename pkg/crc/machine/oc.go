@@ -0,0 +1,26 @@
+package machine
+
+import (
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/pkg/errors"
+)
+
+// InstallOpenShiftClient installs the oc executable of the bundle of the
+// instance next to the ones of the other OpenShift versions
+func (client *client) InstallOpenShiftClient() (*types.OpenShiftClient, error) {
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot load machine")
+	}
+	defer vm.Close()
+
+	path, err := vm.bundle.InstallOpenShiftClient(constants.OcVersionsDir)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot install the OpenShift client")
+	}
+	return &types.OpenShiftClient{
+		Version: vm.bundle.GetOpenshiftVersion(),
+		Path:    path,
+	}, nil
+}
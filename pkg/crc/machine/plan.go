@@ -0,0 +1,34 @@
+package machine
+
+import (
+	"path/filepath"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	crcPreset "github.com/code-ready/crc/pkg/crc/preset"
+)
+
+// StartFiles returns the files and directories of the host written by a start
+// of the instance name with startConfig, for 'crc start --dry-run'. exists
+// tells if the instance already exists, and bundleDir is the directory the
+// bundle is extracted to, or empty when it is already extracted.
+func StartFiles(name string, startConfig types.StartConfig, exists bool, bundleDir string) []string {
+	files := []string{}
+	if bundleDir != "" {
+		files = append(files, bundleDir)
+	}
+	if !exists {
+		files = append(files, filepath.Join(constants.MachineInstanceDir, name), constants.GetPrivateKeyPath())
+		if startConfig.Preset == crcPreset.OpenShift {
+			files = append(files, constants.GetKubeAdminPasswordPath())
+		}
+	}
+	files = append(files, provisioningProgressPath(name), constants.StatsPath, constants.CrcOcBinDir)
+	if startConfig.ImageCache {
+		files = append(files, constants.ImageCacheDir)
+	}
+	if startConfig.Preset == crcPreset.OpenShift {
+		files = append(files, getGlobalKubeConfigPath())
+	}
+	return files
+}
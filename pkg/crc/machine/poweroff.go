@@ -9,6 +9,8 @@ func (client *client) PowerOff() error {
 	}
 	defer vm.Close()
 
+	setExpectedRunning(client.name, false)
+	client.killWorkers()
 	if err := vm.Kill(); err != nil {
 		return errors.Wrap(err, "Cannot kill machine")
 	}
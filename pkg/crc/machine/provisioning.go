@@ -0,0 +1,160 @@
+package machine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/statefile"
+)
+
+type provisioningPhase string
+
+// The provisioning phases run by 'crc start' after the instance is created,
+// in order. A phase is only recorded as completed when all its steps
+// succeeded, 'crc start --resume' runs again the steps of the phase which
+// failed.
+const (
+	instancePhase      provisioningPhase = "instance"
+	dnsPhase           provisioningPhase = "dns"
+	kubeletPhase       provisioningPhase = "kubelet"
+	clusterConfigPhase provisioningPhase = "cluster-configuration"
+	kubeconfigPhase    provisioningPhase = "kubeconfig"
+)
+
+var provisioningPhases = []provisioningPhase{
+	instancePhase,
+	dnsPhase,
+	kubeletPhase,
+	clusterConfigPhase,
+	kubeconfigPhase,
+}
+
+// provisioningProgress is the checkpoint of a start which did not complete,
+// it is stored in the machine directory and removed once the start succeeds
+type provisioningProgress struct {
+	Bundle    string            `json:"bundle"`
+	Completed provisioningPhase `json:"completed,omitempty"`
+	// Created is true when the instance was created by a start which did
+	// not complete yet
+	Created bool `json:"created,omitempty"`
+	// Failure is the error of the last start, when it failed
+	Failure string `json:"failure,omitempty"`
+
+	path string
+}
+
+// provisioningSchema is the schema of the provisioning.json files, the files
+// of the releases which did not version them are the progress itself
+var provisioningSchema = statefile.Schema{
+	Kind:    "provisioning",
+	Version: 1,
+	Migrations: map[int]func([]byte) ([]byte, error){
+		0: func(data []byte) ([]byte, error) {
+			return data, nil
+		},
+	},
+	Validate: validateProvisioningProgress,
+}
+
+func validateProvisioningProgress(data []byte) error {
+	var progress provisioningProgress
+	if err := json.Unmarshal(data, &progress); err != nil {
+		return err
+	}
+	if progress.Bundle == "" {
+		return errors.New("the provisioning progress has no bundle")
+	}
+	if progress.Completed != "" && phaseIndex(progress.Completed) == -1 {
+		return fmt.Errorf("unknown provisioning phase '%s'", progress.Completed)
+	}
+	return nil
+}
+
+const provisioningProgressFile = "provisioning.json"
+
+func provisioningProgressPath(name string) string {
+	return filepath.Join(constants.MachineInstanceDir, name, provisioningProgressFile)
+}
+
+func newProvisioningProgress(name, bundleName string) *provisioningProgress {
+	return &provisioningProgress{
+		Bundle: bundleName,
+		path:   provisioningProgressPath(name),
+	}
+}
+
+// loadProvisioningProgress returns nil when the last start completed
+func loadProvisioningProgress(name string) (*provisioningProgress, error) {
+	path := provisioningProgressPath(name)
+	data, err := statefile.Load(path, provisioningSchema)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	progress := &provisioningProgress{path: path}
+	if err := json.Unmarshal(data, progress); err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+func phaseIndex(phase provisioningPhase) int {
+	for i, p := range provisioningPhases {
+		if p == phase {
+			return i
+		}
+	}
+	return -1
+}
+
+// done returns true when phase was completed by a previous start
+func (p *provisioningProgress) done(phase provisioningPhase) bool {
+	if p.Completed == "" {
+		return false
+	}
+	return phaseIndex(phase) <= phaseIndex(p.Completed)
+}
+
+// complete records that phase succeeded
+func (p *provisioningProgress) complete(phase provisioningPhase) {
+	p.Completed = phase
+	p.save()
+}
+
+// save writes the checkpoint. Failing to write it only prevents resuming,
+// so it is not an error.
+func (p *provisioningProgress) save() {
+	data, err := json.Marshal(p)
+	if err == nil {
+		err = statefile.Save(p.path, provisioningSchema, data)
+	}
+	if err != nil {
+		logging.Debugf("Cannot record the provisioning progress: %v", err)
+	}
+}
+
+// failureMessage tells how to recover from the start which did not complete
+func (p *provisioningProgress) failureMessage() string {
+	message := "The previous start did not complete"
+	if p.Failure != "" {
+		message = fmt.Sprintf("The previous start failed: %s", p.Failure)
+	}
+	if p.Created {
+		return message + ", run 'crc start --resume' to finish it or 'crc start --fresh' to create the instance again"
+	}
+	return message + ", run 'crc start --resume' to finish it"
+}
+
+// finish removes the checkpoint once the start completed
+func (p *provisioningProgress) finish() {
+	if err := statefile.Remove(p.path); err != nil {
+		logging.Debugf("Cannot remove %s: %v", p.path, err)
+	}
+}
@@ -0,0 +1,49 @@
+package machine
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvisioningProgress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "provisioning.json")
+	progress := &provisioningProgress{Bundle: "crc_libvirt_4.10.3_amd64", path: path}
+	for _, phase := range provisioningPhases {
+		assert.False(t, progress.done(phase))
+	}
+
+	progress.complete(dnsPhase)
+	assert.True(t, progress.done(instancePhase))
+	assert.True(t, progress.done(dnsPhase))
+	assert.False(t, progress.done(kubeletPhase))
+	assert.False(t, progress.done(kubeconfigPhase))
+	assert.FileExists(t, path)
+
+	progress.finish()
+	assert.NoFileExists(t, path)
+}
+
+func TestLoadProvisioningProgress(t *testing.T) {
+	progress, err := loadProvisioningProgress("not-existing-machine")
+	require.NoError(t, err)
+	assert.Nil(t, progress)
+}
+
+func TestProvisioningFailureMessage(t *testing.T) {
+	progress := &provisioningProgress{Bundle: "crc_libvirt_4.10.3_amd64"}
+	assert.Equal(t, "The previous start did not complete, run 'crc start --resume' to finish it", progress.failureMessage())
+
+	progress.Failure = "Error waiting for apiserver"
+	progress.Created = true
+	assert.Equal(t, "The previous start failed: Error waiting for apiserver, "+
+		"run 'crc start --resume' to finish it or 'crc start --fresh' to create the instance again", progress.failureMessage())
+}
+
+func TestValidateProvisioningProgress(t *testing.T) {
+	assert.NoError(t, validateProvisioningProgress([]byte(`{"bundle":"crc_libvirt_4.10.3_amd64","completed":"dns","created":true}`)))
+	assert.EqualError(t, validateProvisioningProgress([]byte(`{"completed":"dns"}`)), "the provisioning progress has no bundle")
+	assert.EqualError(t, validateProvisioningProgress([]byte(`{"bundle":"crc_libvirt_4.10.3_amd64","completed":"network"}`)), "unknown provisioning phase 'network'")
+}
@@ -0,0 +1,15 @@
+package qemu
+
+import (
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine/config"
+	"github.com/code-ready/crc/pkg/drivers/qemu"
+)
+
+func CreateHost(machineConfig config.MachineConfig) *qemu.Driver {
+	qemuDriver := qemu.NewDriver(machineConfig.Name, constants.MachineBaseDir)
+
+	config.InitVMDriverFromMachineConfig(machineConfig, qemuDriver.VMDriver)
+
+	return qemuDriver
+}
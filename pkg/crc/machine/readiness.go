@@ -0,0 +1,79 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/pkg/errors"
+)
+
+// CheckReadiness runs the checks of readinessConfig once, the cluster is
+// ready when all of them pass
+func (client *client) CheckReadiness(ctx context.Context, readinessConfig types.ReadinessConfig) (*types.ReadinessResult, error) {
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot load machine")
+	}
+	defer vm.Close()
+
+	if !vm.bundle.IsOpenShift() {
+		return nil, fmt.Errorf("Only supported with OpenShift bundles")
+	}
+	vmState, err := vm.State()
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot get machine state")
+	}
+	if vmState != state.Running {
+		return nil, errors.New("The OpenShift cluster is not running")
+	}
+	ip, err := vm.IP()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting ip")
+	}
+
+	result := &types.ReadinessResult{
+		Ready: true,
+	}
+	for _, check := range readinessConfig.Checks {
+		checkResult := types.ReadinessCheckResult{
+			Check: check,
+			Ready: true,
+		}
+		if err := runReadinessCheck(ctx, vm, ip, check, readinessConfig.Operators); err != nil {
+			checkResult.Ready = false
+			checkResult.Message = err.Error()
+			result.Ready = false
+		}
+		result.Checks = append(result.Checks, checkResult)
+	}
+	return result, nil
+}
+
+func runReadinessCheck(ctx context.Context, vm *virtualMachine, ip string, check types.ReadinessCheck, operators []string) error {
+	switch check {
+	case types.APIReadiness:
+		return cluster.CheckAPIServerReady(ctx, ip, constants.KubeconfigFilePath)
+	case types.IngressReadiness:
+		sshRunner, err := vm.SSHRunner()
+		if err != nil {
+			return errors.Wrap(err, "Error creating the ssh client")
+		}
+		defer sshRunner.Close()
+		return cluster.CheckIngressReady(sshRunner)
+	case types.OperatorsReadiness:
+		status, err := cluster.GetClusterOperatorsStatusByName(ctx, ip, constants.KubeconfigFilePath, operators)
+		if err != nil {
+			return err
+		}
+		if !status.IsReady() {
+			return errors.New(status.String())
+		}
+		return nil
+	default:
+		return fmt.Errorf("Unknown readiness check: %s", check)
+	}
+}
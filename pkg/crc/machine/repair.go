@@ -0,0 +1,44 @@
+package machine
+
+import (
+	"path/filepath"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/statefile"
+	"github.com/code-ready/crc/pkg/libmachine/persist"
+)
+
+// RepairState restores the corrupted state files of the instance name from
+// their backup. The files which cannot be restored are moved aside, when it
+// is the configuration of the instance, crc no longer considers that it
+// exists.
+func RepairState(name string) ([]types.StateRepair, error) {
+	return repairState(constants.MachineInstanceDir, name)
+}
+
+func repairState(machinesDir, name string) ([]types.StateRepair, error) {
+	store := persist.Filestore{MachinesDir: machinesDir}
+	files := []struct {
+		path   string
+		schema statefile.Schema
+	}{
+		{store.HostPath(name), persist.HostSchema},
+		{filepath.Join(machinesDir, name, provisioningProgressFile), provisioningSchema},
+		{filepath.Join(machinesDir, name, hostPortsFile), hostPortsSchema},
+	}
+	var repairs []types.StateRepair
+	for _, file := range files {
+		repair, err := statefile.RepairFile(file.path, file.schema)
+		if err != nil {
+			return repairs, err
+		}
+		repairs = append(repairs, types.StateRepair{Path: file.path, Repair: repair})
+		if repair == statefile.Discarded && file.schema.Kind == persist.HostSchema.Kind {
+			if err := store.UnsetExists(name); err != nil {
+				return repairs, err
+			}
+		}
+	}
+	return repairs, nil
+}
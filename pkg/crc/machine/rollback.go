@@ -0,0 +1,62 @@
+package machine
+
+import (
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/adminhelper"
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/pkg/errors"
+)
+
+// removeFailedCreation removes the instance created by a start which did not
+// complete, with the entries of its cluster in the hosts file. The instances
+// which completed a start are kept.
+func (client *client) removeFailedCreation() error {
+	logging.Info("Removing the instance created by the failed start...")
+	if err := client.Delete(); err != nil {
+		return errors.Wrap(err, "Cannot remove the instance created by the failed start")
+	}
+	if err := adminhelper.CleanHostsFile(); err != nil {
+		logging.Warnf("Failed to remove the entries of the cluster from the hosts file: %v", err)
+	}
+	return nil
+}
+
+// freshStart removes the instance before 'crc start --fresh' when it was
+// created by a start which did not complete
+func (client *client) freshStart() error {
+	exists, err := client.Exists()
+	if err != nil || !exists {
+		return err
+	}
+	progress, err := loadProvisioningProgress(client.name)
+	if err != nil {
+		return err
+	}
+	if progress == nil || !progress.Created {
+		return fmt.Errorf("The instance was created by a start which completed, run 'crc delete' to remove it")
+	}
+	return client.removeFailedCreation()
+}
+
+// recordStartFailure records the error of a failed start, or removes the
+// instance it created when the rollback-failed-start setting is enabled
+func (client *client) recordStartFailure(startErr error) {
+	progress, err := loadProvisioningProgress(client.name)
+	if err != nil {
+		logging.Debugf("Cannot read the provisioning progress: %v", err)
+		return
+	}
+	if progress == nil {
+		return
+	}
+	if progress.Created && client.config.Get(crcConfig.RollbackFailedStart).AsBool() {
+		if err := client.removeFailedCreation(); err != nil {
+			logging.Warn(err.Error())
+		}
+		return
+	}
+	progress.Failure = startErr.Error()
+	progress.save()
+}
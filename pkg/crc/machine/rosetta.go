@@ -0,0 +1,39 @@
+package machine
+
+import (
+	"fmt"
+
+	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
+)
+
+const (
+	rosettaMountTag = "rosetta"
+	rosettaDir      = "/var/mnt/rosetta"
+
+	// binfmt_misc rule matching the x86_64 ELF executables, the F flag
+	// opens the interpreter when the rule is registered so that it is found
+	// from the mount namespaces of the containers
+	rosettaBinfmtRule = `:rosetta:M::\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x3e\x00:` +
+		`\xff\xff\xff\xff\xff\xfe\xfe\x00\xff\xff\xff\xff\xff\xff\xff\xff\xfe\xff\xff\xff:` + rosettaDir + `/rosetta:OCF`
+)
+
+// registerRosetta mounts the Rosetta runtime shared by Virtualization.framework
+// and registers it as the interpreter of the x86_64 executables
+func registerRosetta(sshRunner *crcssh.Runner) error {
+	if _, _, err := sshRunner.RunPrivileged("creating the mount point of Rosetta", "mkdir", "-p", rosettaDir); err != nil {
+		return err
+	}
+	if _, _, err := sshRunner.Run("mountpoint", "-q", rosettaDir); err != nil {
+		if _, stderr, err := sshRunner.RunPrivileged("mounting Rosetta", "mount", "-t", "virtiofs", rosettaMountTag, rosettaDir); err != nil {
+			return fmt.Errorf("Failed to mount Rosetta %v: %s", err, stderr)
+		}
+	}
+	if _, _, err := sshRunner.Run("test", "-e", "/proc/sys/fs/binfmt_misc/rosetta"); err == nil {
+		return nil
+	}
+	if _, stderr, err := sshRunner.RunPrivileged("registering Rosetta for the x86_64 executables", "sh", "-c",
+		fmt.Sprintf("echo '%s' > /proc/sys/fs/binfmt_misc/register", rosettaBinfmtRule)); err != nil {
+		return fmt.Errorf("Failed to register Rosetta %v: %s", err, stderr)
+	}
+	return nil
+}
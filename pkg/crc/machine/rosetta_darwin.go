@@ -0,0 +1,26 @@
+package machine
+
+import "errors"
+
+// configureRosetta adds the Rosetta device to the vfkit configuration, or
+// removes it when enable is false. hyperkit has no such device.
+func configureRosetta(vm *virtualMachine, enable bool) error {
+	if !usesVfkitDriver(vm) {
+		if enable {
+			return errors.New("Rosetta is only supported by the vfkit driver")
+		}
+		return nil
+	}
+	driver, err := loadDriverConfig(vm.Host)
+	if err != nil {
+		return err
+	}
+	if driver.vfkit.Rosetta == enable {
+		return nil
+	}
+	driver.vfkit.Rosetta = enable
+	if err := updateDriverConfig(vm.Host, driver); err != nil {
+		return err
+	}
+	return vm.api.Save(vm.Host)
+}
@@ -0,0 +1,13 @@
+//go:build !darwin
+// +build !darwin
+
+package machine
+
+import "errors"
+
+func configureRosetta(vm *virtualMachine, enable bool) error {
+	if enable {
+		return errors.New("Rosetta is only available on macOS")
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package machine
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/pkg/errors"
+)
+
+// Routes lists the routes of all the namespaces of the cluster
+func (client *client) Routes() ([]types.Route, error) {
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot load machine")
+	}
+	defer vm.Close()
+
+	if !vm.bundle.IsOpenShift() {
+		return nil, fmt.Errorf("Routes are only available with the OpenShift preset")
+	}
+	vmState, err := vm.State()
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot get machine state")
+	}
+	if vmState != state.Running {
+		return nil, errors.New("machine is not running")
+	}
+
+	sshRunner, err := vm.SSHRunner()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating the ssh client")
+	}
+	defer sshRunner.Close()
+
+	stdout, stderr, err := oc.UseOCWithSSH(sshRunner).RunOcCommand("get", "routes", "--all-namespaces", "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("Cannot list the routes %v: %s", err, stderr)
+	}
+	return parseRoutes([]byte(stdout))
+}
+
+// parseRoutes parses the output of 'oc get routes -o json'
+func parseRoutes(data []byte) ([]types.Route, error) {
+	var list struct {
+		Items []struct {
+			Metadata struct {
+				Namespace string `json:"namespace"`
+				Name      string `json:"name"`
+			} `json:"metadata"`
+			Spec struct {
+				Host string `json:"host"`
+				TLS  *struct {
+					Termination string `json:"termination"`
+				} `json:"tls"`
+			} `json:"spec"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, errors.Wrap(err, "Cannot parse the routes")
+	}
+	var routes []types.Route
+	for _, item := range list.Items {
+		route := types.Route{
+			Namespace: item.Metadata.Namespace,
+			Name:      item.Metadata.Name,
+			Host:      item.Spec.Host,
+		}
+		if item.Spec.TLS != nil {
+			route.Termination = item.Spec.TLS.Termination
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
@@ -0,0 +1,31 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRoutes(t *testing.T) {
+	routes, err := parseRoutes([]byte(`{
+		"apiVersion": "v1",
+		"kind": "List",
+		"items": [
+			{
+				"metadata": {"name": "console", "namespace": "openshift-console"},
+				"spec": {"host": "console-openshift-console.apps-crc.testing", "tls": {"termination": "reencrypt"}}
+			},
+			{
+				"metadata": {"name": "hello", "namespace": "demo"},
+				"spec": {"host": "hello-demo.apps-crc.testing"}
+			}
+		]
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, []types.Route{
+		{Namespace: "openshift-console", Name: "console", Host: "console-openshift-console.apps-crc.testing", Termination: "reencrypt"},
+		{Namespace: "demo", Name: "hello", Host: "hello-demo.apps-crc.testing"},
+	}, routes)
+}
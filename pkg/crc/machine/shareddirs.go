@@ -0,0 +1,112 @@
+package machine
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/pkg/errors"
+)
+
+// sharedDirContext is the SELinux label of the mounted directories, it lets
+// the containers of the pods use them in hostPath volumes
+const sharedDirContext = "system_u:object_r:container_file_t:s0"
+
+// sharedDir is a host directory mounted in the instance
+type sharedDir struct {
+	Source string
+	Target string
+	// Tag names the virtiofs device or the SMB share of the directory
+	Tag string
+}
+
+func newSharedDirs(dirs []string) []sharedDir {
+	var shared []sharedDir
+	for i, dir := range dirs {
+		shared = append(shared, sharedDir{
+			Source: dir,
+			Target: sharedDirTarget(dir),
+			Tag:    fmt.Sprintf("crc-dir%d", i),
+		})
+	}
+	return shared
+}
+
+// instanceSharedDirs returns the directories mounted in the instance, the
+// configured directories and the image cache when it is enabled
+func instanceSharedDirs(startConfig types.StartConfig) ([]sharedDir, error) {
+	dirs := newSharedDirs(startConfig.SharedDirs)
+	if startConfig.ImageCache {
+		if err := os.MkdirAll(constants.ImageCacheDir, 0700); err != nil {
+			return nil, errors.Wrap(err, "Cannot create the image cache directory")
+		}
+		dirs = append(dirs, sharedDir{
+			Source: constants.ImageCacheDir,
+			Target: cluster.ImageCacheDir,
+			Tag:    "crc-dir-images",
+		})
+	}
+	return dirs, nil
+}
+
+// windowsMountPoint returns where a Windows directory is mounted in the
+// instance, C:\Users\crc\src is mounted at /mnt/c/Users/crc/src
+func windowsMountPoint(dir string) string {
+	dir = strings.ReplaceAll(dir, `\`, "/")
+	if len(dir) >= 2 && dir[1] == ':' {
+		dir = "/mnt/" + strings.ToLower(dir[:1]) + dir[2:]
+	}
+	return strings.TrimSuffix(dir, "/")
+}
+
+// mountSharedDir mounts dir at its target in the instance, mountArgs are the
+// arguments of mount before the mount point. The password of an SMB share is
+// given to mount.cifs on its standard input, so that it is not written in the
+// instance, it is empty for the other file systems. It does nothing when the
+// target is already a mount point.
+func mountSharedDir(sshRunner *crcssh.Runner, dir sharedDir, password string, mountArgs ...string) error {
+	target := fmt.Sprintf("'%s'", dir.Target)
+	if _, _, err := sshRunner.Run("mountpoint", "-q", target); err == nil {
+		return nil
+	}
+	logging.Infof("Mounting %s in the instance at %s", dir.Source, dir.Target)
+	if _, _, err := sshRunner.RunPrivileged("creating the mount point of a shared directory", "mkdir", "-p", target); err != nil {
+		// the root directory is immutable, the mount point cannot be
+		// created directly in it without lifting this temporarily
+		if err := createMountPointInRoot(sshRunner, target); err != nil {
+			return errors.Wrapf(err, "Cannot create the mount point %s", dir.Target)
+		}
+	}
+	args := append(append([]string{"mount"}, mountArgs...), target)
+	var stderr string
+	var err error
+	if password != "" {
+		args = append([]string{"env", "PASSWD_FD=0"}, args...)
+		stderr, err = sshRunner.RunPrivilegedWithIO("mounting a shared directory", strings.NewReader(password), ioutil.Discard, args...)
+	} else {
+		_, stderr, err = sshRunner.RunPrivileged("mounting a shared directory", args...)
+	}
+	if err != nil {
+		return fmt.Errorf("Cannot mount %s at %s: %v: %s", dir.Source, dir.Target, err, stderr)
+	}
+	return nil
+}
+
+func createMountPointInRoot(sshRunner *crcssh.Runner, target string) error {
+	if _, _, err := sshRunner.RunPrivileged("making the root directory mutable", "chattr", "-i", "/"); err != nil {
+		return err
+	}
+	defer func() {
+		if _, _, err := sshRunner.RunPrivileged("making the root directory immutable", "chattr", "+i", "/"); err != nil {
+			logging.Debugf("Cannot make the root directory immutable again: %v", err)
+		}
+	}()
+	_, _, err := sshRunner.RunPrivileged("creating the mount point of a shared directory", "mkdir", "-p", target)
+	return err
+}
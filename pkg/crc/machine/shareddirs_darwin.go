@@ -0,0 +1,31 @@
+package machine
+
+import (
+	"errors"
+
+	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
+)
+
+var errSharedDirsNotSupported = errors.New("Sharing directories with the instance is not supported by the hyperkit driver")
+
+func sharedDirTarget(dir string) string {
+	return dir
+}
+
+func configureSharedDirs(vm *virtualMachine, dirs []sharedDir) error {
+	if len(dirs) != 0 {
+		return errSharedDirsNotSupported
+	}
+	return nil
+}
+
+func (client *client) mountSharedDirs(sshRunner *crcssh.Runner, dirs []sharedDir, password string) error {
+	if len(dirs) != 0 {
+		return errSharedDirsNotSupported
+	}
+	return nil
+}
+
+func removeSharedDirs() error {
+	return nil
+}
@@ -0,0 +1,96 @@
+package machine
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+
+	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/pkg/errors"
+)
+
+var filesystemRegexp = regexp.MustCompile(`(?s)\n?[ \t]*<filesystem\b.*?</filesystem>`)
+
+const sharedMemoryBacking = `  <memoryBacking>
+    <source type='memfd'/>
+    <access mode='shared'/>
+  </memoryBacking>
+`
+
+func sharedDirTarget(dir string) string {
+	return dir
+}
+
+// configureSharedDirs adds a virtiofs device for each directory to the
+// libvirt domain, the libvirt machine driver does not support them. The
+// devices of the directories which are no longer shared are removed.
+func configureSharedDirs(vm *virtualMachine, dirs []sharedDir) error {
+	if usesQemuDriver(vm) {
+		if len(dirs) != 0 {
+			return errors.New("Sharing directories with the instance is not supported by the qemu driver")
+		}
+		return nil
+	}
+	return updateLibvirtDomain(vm.name, "Updating the shared directories of the instance", func(domainXML string) (string, error) {
+		return addSharedDirs(domainXML, dirs)
+	})
+}
+
+func addSharedDirs(domainXML string, dirs []sharedDir) (string, error) {
+	updatedXML := filesystemRegexp.ReplaceAllStringFunc(domainXML, func(filesystem string) string {
+		if strings.Contains(filesystem, "<target dir='crc-dir") {
+			return ""
+		}
+		return filesystem
+	})
+	if len(dirs) == 0 {
+		return updatedXML, nil
+	}
+
+	// virtiofs needs the memory of the instance to be shared with the host
+	if !strings.Contains(updatedXML, "<memoryBacking>") {
+		devices := strings.Index(updatedXML, "  <devices>")
+		if devices == -1 {
+			return "", errors.New("Invalid libvirt domain definition, it has no devices")
+		}
+		updatedXML = updatedXML[:devices] + sharedMemoryBacking + updatedXML[devices:]
+	} else if !strings.Contains(updatedXML, "<access mode='shared'/>") {
+		return "", errors.New("The memory of the libvirt domain cannot be shared with virtiofs")
+	}
+
+	var filesystems bytes.Buffer
+	for _, dir := range dirs {
+		var source bytes.Buffer
+		if err := xml.EscapeText(&source, []byte(dir.Source)); err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&filesystems, `
+    <filesystem type='mount' accessmode='passthrough'>
+      <driver type='virtiofs'/>
+      <source dir='%s'/>
+      <target dir='%s'/>
+    </filesystem>`, source.String(), dir.Tag)
+	}
+	end := strings.LastIndex(updatedXML, "\n  </devices>")
+	if end == -1 {
+		return "", errors.New("Invalid libvirt domain definition, it has no devices")
+	}
+	return updatedXML[:end] + filesystems.String() + updatedXML[end:], nil
+}
+
+func (client *client) mountSharedDirs(sshRunner *crcssh.Runner, dirs []sharedDir, password string) error {
+	for _, dir := range dirs {
+		if err := mountSharedDir(sshRunner, dir, "", "-t", "virtiofs", "-o", "context="+sharedDirContext, dir.Tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeSharedDirs is a no-op, the virtiofs devices are removed with the
+// libvirt domain
+func removeSharedDirs() error {
+	return nil
+}
@@ -0,0 +1,37 @@
+package machine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testDomainXML = `<domain type='kvm'>
+  <name>crc</name>
+  <memory unit='KiB'>9437184</memory>
+  <devices>
+    <disk type='file' device='disk'>
+      <source file='/home/user/.crc/machines/crc/crc.qcow2'/>
+    </disk>
+  </devices>
+</domain>
+`
+
+func TestAddSharedDirs(t *testing.T) {
+	dirs := []sharedDir{{Source: "/home/user/R&D", Target: "/home/user/R&D", Tag: "crc-dir0"}}
+	updatedXML, err := addSharedDirs(testDomainXML, dirs)
+	assert.NoError(t, err)
+	assert.Contains(t, updatedXML, "<access mode='shared'/>")
+	assert.Contains(t, updatedXML, "<source dir='/home/user/R&amp;D'/>\n      <target dir='crc-dir0'/>\n    </filesystem>\n  </devices>")
+
+	// the definition is unchanged when the directories did not change
+	sameXML, err := addSharedDirs(updatedXML, dirs)
+	assert.NoError(t, err)
+	assert.Equal(t, updatedXML, sameXML)
+
+	removedXML, err := addSharedDirs(updatedXML, nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, removedXML, "<filesystem")
+	assert.True(t, strings.HasSuffix(removedXML, "    </disk>\n  </devices>\n</domain>\n"))
+}
@@ -0,0 +1,21 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowsMountPoint(t *testing.T) {
+	assert.Equal(t, "/mnt/c/Users/crc/src", windowsMountPoint(`C:\Users\crc\src`))
+	assert.Equal(t, "/mnt/d/projects", windowsMountPoint(`D:\projects\`))
+	assert.Equal(t, "/mnt/c/Users/crc/my project", windowsMountPoint(`c:\Users\crc\my project`))
+}
+
+func TestNewSharedDirs(t *testing.T) {
+	dirs := newSharedDirs([]string{"/home/crc/src", "/srv/data"})
+	assert.Len(t, dirs, 2)
+	assert.Equal(t, "crc-dir0", dirs[0].Tag)
+	assert.Equal(t, "/home/crc/src", dirs[0].Source)
+	assert.Equal(t, "crc-dir1", dirs[1].Tag)
+}
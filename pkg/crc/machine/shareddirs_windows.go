@@ -0,0 +1,135 @@
+package machine
+
+import (
+	"fmt"
+	"os/user"
+	"strings"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/code-ready/crc/pkg/os/windows/powershell"
+	"github.com/pkg/errors"
+)
+
+const listSMBSharesCommand = `Get-SmbShare -Name 'crc-dir*' -ErrorAction SilentlyContinue`
+
+func sharedDirTarget(dir string) string {
+	return windowsMountPoint(dir)
+}
+
+// configureSharedDirs shares the directories over SMB, with the tag of the
+// directory as share name. The shares are only modified when they do not
+// match the directories, this needs administrator privileges.
+func configureSharedDirs(vm *virtualMachine, dirs []sharedDir) error {
+	// WSL mounts the drives of Windows in the distribution
+	if usesWSL2Driver(vm) {
+		return nil
+	}
+	stdout, _, err := powershell.Execute(listSMBSharesCommand + ` | ForEach-Object { $_.Name + '=' + $_.Path }`)
+	if err != nil {
+		logging.Debugf("Cannot list the SMB shares: %v", err)
+	}
+	if err == nil && smbSharesMatch(stdout, dirs) {
+		return nil
+	}
+	currentUser, err := user.Current()
+	if err != nil {
+		return err
+	}
+	cmds := []string{listSMBSharesCommand + " | Remove-SmbShare -Force"}
+	for _, dir := range dirs {
+		cmds = append(cmds, fmt.Sprintf("New-SmbShare -Name '%s' -Path '%s' -FullAccess '%s' | Out-Null",
+			quotePowerShell(dir.Tag), quotePowerShell(dir.Source), quotePowerShell(currentUser.Username)))
+	}
+	if _, stderr, err := powershell.ExecuteAsAdmin("sharing directories with the instance", strings.Join(cmds, "; ")); err != nil {
+		return fmt.Errorf("Failed to share the directories over SMB: %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// quotePowerShell escapes the single quotes of a value put in a single-quoted
+// PowerShell string
+func quotePowerShell(value string) string {
+	return strings.ReplaceAll(value, "'", "''")
+}
+
+// smbSharesMatch checks if the shares listed as name=path lines are the
+// shares of dirs
+func smbSharesMatch(shares string, dirs []sharedDir) bool {
+	existing := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(shares), "\n") {
+		if fields := strings.SplitN(strings.TrimSpace(line), "=", 2); len(fields) == 2 {
+			existing[strings.ToLower(fields[0])] = fields[1]
+		}
+	}
+	if len(existing) != len(dirs) {
+		return false
+	}
+	for _, dir := range dirs {
+		if !strings.EqualFold(existing[dir.Tag], dir.Source) {
+			return false
+		}
+	}
+	return true
+}
+
+func (client *client) mountSharedDirs(sshRunner *crcssh.Runner, dirs []sharedDir, password string) error {
+	if len(dirs) == 0 || crcConfig.GetVMDriver(client.config) == crcConfig.WSL2VMDriver {
+		return nil
+	}
+	if password == "" {
+		return errors.New("The password of the current user is needed to mount the shared directories, enter it when 'crc start' asks for it")
+	}
+	host, err := client.smbHost(sshRunner)
+	if err != nil {
+		return err
+	}
+	currentUser, err := user.Current()
+	if err != nil {
+		return err
+	}
+	options := fmt.Sprintf("dir_mode=0777,file_mode=0777,context=%s", sharedDirContext)
+	if fields := strings.SplitN(currentUser.Username, `\`, 2); len(fields) == 2 {
+		options += fmt.Sprintf(",username=%s,domain=%s", fields[1], fields[0])
+	} else {
+		options += fmt.Sprintf(",username=%s", currentUser.Username)
+	}
+	options = "'" + strings.ReplaceAll(options, "'", `'\''`) + "'"
+	for _, dir := range dirs {
+		if err := mountSharedDir(sshRunner, dir, password, "-t", "cifs", "-o", options, fmt.Sprintf("//%s/%s", host, dir.Tag)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// smbHost returns the address of the host seen from the instance. With the
+// user network mode, the daemon forwards it to the loopback of the host.
+func (client *client) smbHost(sshRunner *crcssh.Runner) (string, error) {
+	if client.useVSock() {
+		return constants.VSockHostVirtualIP, nil
+	}
+	route, _, err := sshRunner.Run("ip", "-4", "route", "show", "default")
+	if err != nil {
+		return "", errors.Wrap(err, "Cannot get the default gateway of the instance")
+	}
+	gateway := defaultGateway(route)
+	if gateway == nil {
+		return "", errors.New("The instance has no default gateway")
+	}
+	return gateway.String(), nil
+}
+
+// removeSharedDirs removes the SMB shares of the instance
+func removeSharedDirs() error {
+	stdout, _, err := powershell.Execute(listSMBSharesCommand)
+	if err != nil || strings.TrimSpace(stdout) == "" {
+		return nil
+	}
+	if _, stderr, err := powershell.ExecuteAsAdmin("removing the shared directories", listSMBSharesCommand+" | Remove-SmbShare -Force"); err != nil {
+		return fmt.Errorf("Failed to remove the SMB shares: %v: %s", err, stderr)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package machine
+
+import (
+	"context"
+	"os"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/pkg/errors"
+)
+
+// createInstanceSSHKey creates the key pair used to connect to the instance
+// at path, a copy of the key of the ssh-key setting, or a new key of the type
+// of the ssh-key-type setting
+func createInstanceSSHKey(cfg crcConfig.Storage, path string) error {
+	if customKey := cfg.Get(crcConfig.SSHKey).AsString(); customKey != "" {
+		logging.Infof("Using the SSH key pair %s...", customKey)
+		return crcssh.CopyKeyPair(customKey, path)
+	}
+	logging.Info("Generating new SSH Key pair...")
+	return crcssh.GenerateSSHKeyOfType(path, cfg.Get(crcConfig.SSHKeyType).AsString())
+}
+
+func removeKeyPair(path string) {
+	for _, file := range []string{path, path + ".pub"} {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			logging.Debugf("Cannot remove %s: %v", file, err)
+		}
+	}
+}
+
+// RotateSSHKey replaces the SSH key of the running instance with a key
+// created from the ssh-key and ssh-key-type settings. The previous key stays
+// in use when the instance cannot be reached with the new one, or when the
+// new key cannot be put in the machine config or on the host.
+func (client *client) RotateSSHKey(ctx context.Context) error {
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
+	if err != nil {
+		return errors.Wrap(err, "Cannot load machine")
+	}
+	defer vm.Close()
+	vmState, err := vm.State()
+	if err != nil {
+		return errors.Wrap(err, "Cannot get machine state")
+	}
+	// the key is authorized in the instance with the previous key
+	if vmState != state.Running {
+		return errors.New("The instance must be running to replace its SSH key")
+	}
+	sshRunner, err := vm.SSHRunner()
+	if err != nil {
+		return errors.Wrap(err, "Error creating the ssh client")
+	}
+	defer sshRunner.Close()
+
+	newKeyPath := constants.GetPrivateKeyPath() + ".new"
+	removeKeyPair(newKeyPath)
+	defer removeKeyPair(newKeyPath)
+	if err := createInstanceSSHKey(client.config, newKeyPath); err != nil {
+		return errors.Wrap(err, "Error creating the SSH key pair")
+	}
+	if err := authorizeSSHKey(sshRunner, newKeyPath+".pub"); err != nil {
+		return errors.Wrap(err, "Cannot authorize the new SSH key in the instance")
+	}
+	// the previous key is authorized again when the key cannot be replaced
+	// in the machine config or on the host
+	ocConfig := oc.UseOCWithSSH(sshRunner)
+	machineConfigUpdated := false
+	rollback := func() {
+		if err := authorizeSSHKey(sshRunner, constants.GetPublicKeyPath()); err != nil {
+			logging.Errorf("Cannot authorize the previous SSH key in the instance again: %v", err)
+		}
+		if !machineConfigUpdated {
+			return
+		}
+		if err := cluster.EnsureSSHKeyPresentInTheCluster(ctx, ocConfig, constants.GetPublicKeyPath()); err != nil {
+			logging.Errorf("Cannot put the previous SSH key in the machine config again: %v", err)
+		}
+	}
+	if err := checkSSHKey(vm, newKeyPath); err != nil {
+		rollback()
+		return errors.Wrap(err, "Cannot connect to the instance with the new SSH key")
+	}
+	if vm.bundle.IsOpenShift() {
+		// the machine config operator would authorize the previous key again
+		if err := cluster.EnsureSSHKeyPresentInTheCluster(ctx, ocConfig, newKeyPath+".pub"); err != nil {
+			rollback()
+			return errors.Wrap(err, "Failed to update ssh public key to machine config")
+		}
+		machineConfigUpdated = true
+	}
+	if err := replaceKeyPair(newKeyPath, constants.GetPrivateKeyPath()); err != nil {
+		rollback()
+		return errors.Wrap(err, "Cannot replace the SSH key pair")
+	}
+	// the key of the instances created by crc 1.20.0 is no longer authorized
+	removeKeyPair(constants.GetRsaPrivateKeyPath())
+	return nil
+}
+
+// replaceKeyPair moves the key pair at newPath to path. The previous key pair
+// is put back when a file cannot be moved, so that the private and the public
+// key at path always match.
+func replaceKeyPair(newPath, path string) error {
+	backupPath := path + ".old"
+	removeKeyPair(backupPath)
+	for _, suffix := range []string{"", ".pub"} {
+		if err := os.Rename(path+suffix, backupPath+suffix); err != nil && !os.IsNotExist(err) {
+			restoreKeyPair(backupPath, path)
+			return err
+		}
+	}
+	for _, suffix := range []string{"", ".pub"} {
+		if err := os.Rename(newPath+suffix, path+suffix); err != nil {
+			restoreKeyPair(backupPath, path)
+			return err
+		}
+	}
+	removeKeyPair(backupPath)
+	return nil
+}
+
+func restoreKeyPair(backupPath, path string) {
+	for _, suffix := range []string{"", ".pub"} {
+		if err := os.Rename(backupPath+suffix, path+suffix); err != nil && !os.IsNotExist(err) {
+			logging.Errorf("Cannot restore %s: %v", path+suffix, err)
+		}
+	}
+}
+
+// checkSSHKey connects to the instance with the private key at path
+func checkSSHKey(vm *virtualMachine, path string) error {
+	ip, err := vm.IP()
+	if err != nil {
+		return err
+	}
+	runner, err := crcssh.CreateRunner(ip, vm.SSHPort(), path)
+	if err != nil {
+		return err
+	}
+	defer runner.Close()
+	_, _, err = runner.Run("true")
+	return err
+}
@@ -0,0 +1,47 @@
+package machine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKeyPair(t *testing.T, path, content string) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	require.NoError(t, os.WriteFile(path+".pub", []byte(content+".pub"), 0600))
+}
+
+func assertKeyPair(t *testing.T, path, content string) {
+	private, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, content, string(private))
+	public, err := os.ReadFile(path + ".pub")
+	assert.NoError(t, err)
+	assert.Equal(t, content+".pub", string(public))
+}
+
+func TestReplaceKeyPair(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "id_ecdsa")
+	writeKeyPair(t, path, "old")
+	writeKeyPair(t, path+".new", "new")
+
+	assert.NoError(t, replaceKeyPair(path+".new", path))
+	assertKeyPair(t, path, "new")
+	assert.NoFileExists(t, path+".old")
+	assert.NoFileExists(t, path+".old.pub")
+}
+
+func TestReplaceKeyPairRestoresPreviousKey(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "id_ecdsa")
+	writeKeyPair(t, path, "old")
+	// the public key of the new pair is missing
+	require.NoError(t, os.WriteFile(path+".new", []byte("new"), 0600))
+
+	assert.Error(t, replaceKeyPair(path+".new", path))
+	assertKeyPair(t, path, "old")
+}
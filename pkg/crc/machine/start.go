@@ -14,8 +14,11 @@ import (
 	"time"
 
 	"github.com/code-ready/crc/pkg/crc/cluster"
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
 	"github.com/code-ready/crc/pkg/crc/constants"
 	crcerrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/hooks"
+	"github.com/code-ready/crc/pkg/crc/ignition"
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine/bundle"
 	"github.com/code-ready/crc/pkg/crc/machine/config"
@@ -30,6 +33,7 @@ import (
 	"github.com/code-ready/crc/pkg/crc/systemd"
 	"github.com/code-ready/crc/pkg/crc/telemetry"
 	crctls "github.com/code-ready/crc/pkg/crc/tls"
+	"github.com/code-ready/crc/pkg/libmachine"
 	"github.com/code-ready/crc/pkg/libmachine/host"
 	"github.com/code-ready/machine/libmachine/drivers"
 	libmachinestate "github.com/code-ready/machine/libmachine/state"
@@ -40,7 +44,16 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-const minimumMemoryForMonitoring = 14336
+const (
+	instanceCAFilePath = "/etc/pki/ca-trust/source/anchors/crc-user-ca.pem"
+	fstrimTimer        = "fstrim.timer"
+)
+
+// The default timeouts of the vm-boot-timeout and ssh-timeout settings
+const (
+	defaultVMBootTimeout = 3 * time.Minute
+	defaultSSHTimeout    = 5 * time.Minute
+)
 
 func getCrcBundleInfo(bundleName, bundlePath string) (*bundle.CrcBundleInfo, error) {
 	bundleInfo, err := bundle.Use(bundleName)
@@ -75,10 +88,42 @@ func (client *client) updateVMConfig(startConfig types.StartConfig, vm *virtualM
 			return err
 		}
 	}
+	if err := setNestedVirtualization(vm, startConfig.NestedVirtualization); err != nil {
+		logging.Debugf("Failed to update CRC VM configuration: %v", err)
+		if err == drivers.ErrNotImplemented {
+			logging.Warn("Nested virtualization has been ignored as the machine driver does not support it")
+		} else {
+			return err
+		}
+	}
 	if err := vm.api.Save(vm.Host); err != nil {
 		return err
 	}
 
+	/* Discard, so that the image shrinks when the instance trims its disk */
+	if err := enableDiskDiscard(vm); err != nil {
+		logging.Warnf("Failed to enable discard on the instance disk: %v", err)
+	}
+
+	/* GPU passthrough */
+	if err := configureGPU(vm, startConfig.EnableGPU); err != nil {
+		return err
+	}
+
+	/* Rosetta, it is registered once the instance is running */
+	if err := configureRosetta(vm, startConfig.EnableRosetta); err != nil {
+		return err
+	}
+
+	/* Shared directories, they are mounted once the instance is running */
+	sharedDirs, err := instanceSharedDirs(startConfig)
+	if err != nil {
+		return err
+	}
+	if err := configureSharedDirs(vm, sharedDirs); err != nil {
+		return err
+	}
+
 	/* Disk size */
 	if startConfig.DiskSize != constants.DefaultDiskSize {
 		if err := setDiskSize(vm.Host, startConfig.DiskSize); err != nil {
@@ -132,14 +177,14 @@ func growRootFileSystem(sshRunner *crcssh.Runner) error {
 
 	return nil
 }
-func (client *client) Start(ctx context.Context, startConfig types.StartConfig) (*types.StartResult, error) {
+func (client *client) start(ctx context.Context, startConfig types.StartConfig, startStats *startStats) (*types.StartResult, error) {
 	telemetry.SetCPUs(ctx, startConfig.CPUs)
 	telemetry.SetMemory(ctx, uint64(startConfig.Memory)*1024*1024)
 	telemetry.SetDiskSize(ctx, uint64(startConfig.DiskSize)*1024*1024*1024)
 	telemetry.SetPreset(ctx, startConfig.Preset)
 
 	if err := client.validateStartConfig(startConfig); err != nil {
-		return nil, err
+		return nil, crcerrors.WithClass(crcerrors.ValidationFailure, err)
 	}
 
 	// Pre-VM start
@@ -148,14 +193,21 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 		return nil, errors.Wrap(err, "Cannot determine if VM exists")
 	}
 
-	bundleName := bundle.GetBundleNameWithoutExtension(filepath.Base(startConfig.BundlePath))
-	crcBundleMetadata, err := getCrcBundleInfo(bundleName, startConfig.BundlePath)
+	bundlePath, ok := bundle.LocalPath(startConfig.BundlePath)
+	if !ok {
+		return nil, crcerrors.WithClass(crcerrors.BundleFailure,
+			fmt.Errorf("%s is not downloaded, run 'crc setup -b %s'", startConfig.BundlePath, startConfig.BundlePath))
+	}
+	bundleName := bundle.GetBundleNameWithoutExtension(filepath.Base(bundlePath))
+	startStats.bundle = bundleName
+	startStats.timer.Begin(bundlePhase)
+	crcBundleMetadata, err := getCrcBundleInfo(bundleName, bundlePath)
 	if err != nil {
-		return nil, errors.Wrap(err, "Error getting bundle metadata")
+		return nil, crcerrors.WithClass(crcerrors.BundleFailure, errors.Wrap(err, "Error getting bundle metadata"))
 	}
 
 	if err := bundleMismatchWithPreset(startConfig.Preset, crcBundleMetadata); err != nil {
-		return nil, err
+		return nil, crcerrors.WithClass(crcerrors.BundleFailure, err)
 	}
 
 	if !exists {
@@ -173,6 +225,11 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 			logging.Infof("Creating CodeReady Containers VM for Podman %s...", crcBundleMetadata.GetPodmanVersion())
 		}
 
+		vmDriver := crcConfig.GetVMDriver(client.config)
+		if valid, msg := crcConfig.ValidateVMDriver(vmDriver, client.networkMode()); !valid {
+			return nil, crcerrors.WithClass(crcerrors.ValidationFailure, fmt.Errorf("Cannot create the instance with the %s driver: %s", vmDriver, msg))
+		}
+
 		machineConfig := config.MachineConfig{
 			Name:            client.name,
 			BundleName:      bundleName,
@@ -180,6 +237,7 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 			Memory:          startConfig.Memory,
 			DiskSize:        startConfig.DiskSize,
 			NetworkMode:     client.networkMode(),
+			VMDriver:        vmDriver,
 			ImageSourcePath: crcBundleMetadata.GetDiskImagePath(),
 			ImageFormat:     crcBundleMetadata.GetDiskImageFormat(),
 			SSHKeyPath:      crcBundleMetadata.GetSSHKeyPath(),
@@ -190,8 +248,20 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 		if crcBundleMetadata.IsOpenShift() {
 			machineConfig.KubeConfig = crcBundleMetadata.GetKubeConfigPath()
 		}
-		if err := createHost(machineConfig, crcBundleMetadata.GetBundleType()); err != nil {
-			return nil, errors.Wrap(err, "Error creating machine")
+		startStats.creation = true
+		startStats.timer.Begin(creationPhase)
+		if err := createHost(machineConfig, client.config, crcBundleMetadata.GetBundleType()); err != nil {
+			return nil, crcerrors.WithClass(crcerrors.VMCreateFailure, errors.Wrap(err, "Error creating machine"))
+		}
+		// the instance is removed by 'crc start --fresh' until a start
+		// completes
+		creation := newProvisioningProgress(client.name, bundleName)
+		creation.Created = true
+		creation.save()
+		if crcBundleMetadata.IsOpenShift() && startConfig.ClusterDomain != "" {
+			if err := saveClusterDomain(client.name, startConfig.ClusterDomain); err != nil {
+				return nil, errors.Wrap(err, "Cannot record the cluster domain")
+			}
 		}
 	} else {
 		telemetry.SetStartType(ctx, telemetry.StartStartType)
@@ -207,15 +277,42 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 	if currentBundleName != bundleName {
 		logging.Debugf("Bundle '%s' was requested, but the existing VM is using '%s'",
 			bundleName, currentBundleName)
-		return nil, fmt.Errorf("Bundle '%s' was requested, but the existing VM is using '%s'. Please delete your existing cluster and start again",
-			bundleName,
-			currentBundleName)
+		return nil, crcerrors.WithClass(crcerrors.BundleFailure,
+			fmt.Errorf("Bundle '%s' was requested, but the existing VM is using '%s'. Please delete your existing cluster and start again",
+				bundleName,
+				currentBundleName))
+	}
+	if vm.bundle.IsOpenShift() {
+		warnClusterDomainMismatch(vm.bundle, startConfig.ClusterDomain)
 	}
 	vmState, err := vm.State()
 	if err != nil {
 		return nil, errors.Wrap(err, "Error getting the machine state")
 	}
-	if vmState == state.Running {
+	progress, err := loadProvisioningProgress(client.name)
+	if err != nil {
+		logging.Debugf("Cannot read the provisioning progress: %v", err)
+	}
+	if progress != nil && progress.Bundle != currentBundleName {
+		progress = nil
+	}
+	created := progress != nil && progress.Created
+	resuming := false
+	if vmState == state.Running && progress != nil {
+		if startConfig.Resume {
+			resuming = true
+			if progress.Completed == "" {
+				logging.Info("Resuming the start of the instance")
+			} else {
+				logging.Infof("Resuming the start of the instance after the '%s' phase", progress.Completed)
+			}
+		} else {
+			logging.Warn(progress.failureMessage())
+		}
+	} else if startConfig.Resume {
+		logging.Info("There is no start to resume, starting the instance from the beginning")
+	}
+	if vmState == state.Running && !resuming {
 		if !vm.bundle.IsOpenShift() {
 			logging.Infof("A CodeReady Containers VM for Podman %s is already running", vm.bundle.GetPodmanVersion())
 			return &types.StartResult{
@@ -223,7 +320,7 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 			}, nil
 		}
 		logging.Infof("A CodeReady Containers VM for OpenShift %s is already running", vm.bundle.GetOpenshiftVersion())
-		clusterConfig, err := getClusterConfig(vm.bundle)
+		clusterConfig, err := getClusterConfig(vm.name, vm.bundle)
 		if err != nil {
 			return nil, errors.Wrap(err, "Cannot create cluster configuration")
 		}
@@ -236,29 +333,39 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 		}, nil
 	}
 
+	// the starts of a running instance are not recorded
+	startStats.record = true
+
 	if _, err := bundle.Use(currentBundleName); err != nil {
 		return nil, err
 	}
 
-	if vm.bundle.IsOpenShift() {
-		logging.Infof("Starting CodeReady Containers VM for OpenShift %s...", vm.bundle.GetOpenshiftVersion())
-	}
-
 	if client.useVSock() {
-		if err := exposePorts(startConfig.Preset); err != nil {
+		if err := exposePorts(client.name, startConfig.Preset, startConfig.HostPorts, startConfig.AlternativeHostPorts); err != nil {
 			return nil, err
 		}
 	}
 
-	if err := client.updateVMConfig(startConfig, vm); err != nil {
-		return nil, errors.Wrap(err, "Could not update CRC VM configuration")
-	}
+	if !resuming {
+		if vm.bundle.IsOpenShift() {
+			logging.Infof("Starting CodeReady Containers VM for OpenShift %s...", vm.bundle.GetOpenshiftVersion())
+		}
+
+		startStats.timer.Begin(vmStartPhase)
+		if err := client.updateVMConfig(startConfig, vm); err != nil {
+			return nil, errors.Wrap(err, "Could not update CRC VM configuration")
+		}
 
-	if err := startHost(ctx, vm); err != nil {
-		return nil, errors.Wrap(err, "Error starting machine")
+		progress = newProvisioningProgress(client.name, currentBundleName)
+		progress.Created = created
+		progress.save()
+		if err := startHost(ctx, vm, client.timeout(crcConfig.VMBootTimeout, defaultVMBootTimeout)); err != nil {
+			return nil, errors.Wrap(err, "Error starting machine")
+		}
 	}
 
 	// Post-VM start
+	startStats.timer.Begin(sshPhase)
 	vmState, err = vm.State()
 	if err != nil {
 		return nil, errors.Wrap(err, "Error getting the state")
@@ -272,57 +379,135 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 		return nil, errors.Wrap(err, "Error getting the IP")
 	}
 	logging.Infof("CodeReady Containers instance is running with IP %s", instanceIP)
+	client.warnRouteConflicts(instanceIP)
 	sshRunner, err := vm.SSHRunner()
 	if err != nil {
 		return nil, errors.Wrap(err, "Error creating the ssh client")
 	}
-	defer sshRunner.Close()
+	// the runner is replaced when the instance is restarted
+	defer func() {
+		sshRunner.Close()
+	}()
 
 	logging.Debug("Waiting until ssh is available")
-	if err := sshRunner.WaitForConnectivity(ctx, 300*time.Second); err != nil {
+	if err := sshRunner.WaitForConnectivity(ctx, client.timeout(crcConfig.SSHTimeout, defaultSSHTimeout)); err != nil {
 		return nil, errors.Wrap(err, "Failed to connect to the CRC VM with SSH -- virtual machine might be unreachable")
 	}
 	logging.Info("CodeReady Containers VM is running")
 
-	// Post VM start immediately update SSH key and copy kubeconfig to instance
-	// dir and VM
-	if err := updateSSHKeyPair(sshRunner); err != nil {
-		return nil, errors.Wrap(err, "Error updating public key")
+	nameServers, err := network.ParseNameServers(startConfig.NameServer)
+	if err != nil {
+		return nil, err
 	}
 
-	// Trigger disk resize, this will be a no-op if no disk size change is needed
-	if err := growRootFileSystem(sshRunner); err != nil {
-		return nil, errors.Wrap(err, "Error updating filesystem size")
-	}
+	startStats.timer.Begin(string(instancePhase))
+	if !progress.done(instancePhase) {
+		// Post VM start immediately update SSH key and copy kubeconfig to instance
+		// dir and VM
+		if err := updateSSHKeyPair(sshRunner); err != nil {
+			return nil, errors.Wrap(err, "Error updating public key")
+		}
 
-	// Start network time synchronization if `CRC_DEBUG_ENABLE_STOP_NTP` is not set
-	if stopNtp, _ := strconv.ParseBool(os.Getenv("CRC_DEBUG_ENABLE_STOP_NTP")); stopNtp {
-		logging.Info("Stopping network time synchronization in CodeReady Containers VM")
-		if _, _, err := sshRunner.RunPrivileged("Turning off the ntp server", "timedatectl set-ntp off"); err != nil {
-			return nil, errors.Wrap(err, "Failed to stop network time synchronization")
+		// the kernel arguments are only used after a restart, so the
+		// instance is restarted before it is configured
+		kernelArgumentsChanged, err := updateKernelArguments(sshRunner, startConfig)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to update the kernel arguments")
 		}
-		logging.Info("Setting clock to vm clock (UTC timezone)")
-		dateCmd := fmt.Sprintf("date -s '%s'", time.Now().Format(time.UnixDate))
-		if _, _, err := sshRunner.RunPrivileged("Setting clock same as host", dateCmd); err != nil {
-			return nil, errors.Wrap(err, "Failed to set clock to same as host")
+		if kernelArgumentsChanged {
+			logging.Info("Restarting the instance to use its new kernel arguments...")
+			newIP, newRunner, err := client.restartInstance(ctx, vm)
+			if err != nil {
+				return nil, errors.Wrap(err, "Failed to restart the instance")
+			}
+			sshRunner.Close()
+			instanceIP, sshRunner = newIP, newRunner
 		}
-	}
 
-	// Add nameserver to VM if provided by User
-	if startConfig.NameServer != "" {
-		if err = addNameServerToInstance(sshRunner, startConfig.NameServer); err != nil {
-			return nil, errors.Wrap(err, "Failed to add nameserver to the VM")
+		// Trigger disk resize, this will be a no-op if no disk size change is needed
+		if err := growRootFileSystem(sshRunner); err != nil {
+			return nil, errors.Wrap(err, "Error updating filesystem size")
+		}
+
+		// Periodically release the unused blocks, this is what allows
+		// 'crc cleanup --compact-disk' to shrink the disk image
+		if err := enablePeriodicTrim(sshRunner); err != nil {
+			logging.Warnf("Failed to enable periodic fstrim: %v", err)
 		}
-	}
 
-	if _, _, err := sshRunner.RunPrivileged("make root Podman socket accessible", "chmod 777 /run/podman/ /run/podman/podman.sock"); err != nil {
-		return nil, errors.Wrap(err, "Failed to change permissions to root podman socket")
+		sharedDirs, err := instanceSharedDirs(startConfig)
+		if err != nil {
+			return nil, err
+		}
+		if err := client.mountSharedDirs(sshRunner, sharedDirs, startConfig.SharedDirPassword); err != nil {
+			return nil, errors.Wrap(err, "Failed to mount the shared directories")
+		}
+		if err := cluster.ConfigureImageCache(sshRunner, startConfig.ImageCache); err != nil {
+			return nil, errors.Wrap(err, "Failed to configure the image cache")
+		}
+
+		if startConfig.EnableRosetta {
+			if err := registerRosetta(sshRunner); err != nil {
+				return nil, errors.Wrap(err, "Failed to set up Rosetta in the instance")
+			}
+		}
+
+		// the pool is mounted before kubelet starts the pods using its volumes
+		if vm.bundle.IsOpenShift() {
+			if err := cluster.SetupPVPool(sshRunner, startConfig.PVPoolSize); err != nil {
+				return nil, errors.Wrap(err, "Failed to set up the pool of the persistent volumes")
+			}
+		}
+
+		// Start network time synchronization if `CRC_DEBUG_ENABLE_STOP_NTP` is not set
+		if stopNtp, _ := strconv.ParseBool(os.Getenv("CRC_DEBUG_ENABLE_STOP_NTP")); stopNtp {
+			logging.Info("Stopping network time synchronization in CodeReady Containers VM")
+			if _, _, err := sshRunner.RunPrivileged("Turning off the ntp server", "timedatectl set-ntp off"); err != nil {
+				return nil, errors.Wrap(err, "Failed to stop network time synchronization")
+			}
+			logging.Info("Setting clock to vm clock (UTC timezone)")
+			dateCmd := fmt.Sprintf("date -s '%s'", time.Now().Format(time.UnixDate))
+			if _, _, err := sshRunner.RunPrivileged("Setting clock same as host", dateCmd); err != nil {
+				return nil, errors.Wrap(err, "Failed to set clock to same as host")
+			}
+		} else if err := configureTimeSync(sshRunner); err != nil {
+			logging.Warnf("Failed to configure the time synchronization: %v", err)
+		}
+
+		if err := configureSysctls(sshRunner, startConfig.Sysctls); err != nil {
+			return nil, errors.Wrap(err, "Failed to set the kernel parameters")
+		}
+
+		// Add nameservers to VM if provided by User
+		for _, nameServer := range nameServers {
+			if nameServer.IsEncrypted() || nameServer.IsIPv6() {
+				// the instance reaches them through the DNS forwarder of the daemon
+				if !client.useVSock() {
+					return nil, fmt.Errorf("DNS-over-TLS, DNS-over-HTTPS and IPv6 nameservers are only supported with the %s network mode", network.UserNetworkingMode)
+				}
+				continue
+			}
+			if err = addNameServerToInstance(sshRunner, nameServer.NameServer().IPAddress); err != nil {
+				return nil, errors.Wrap(err, "Failed to add nameserver to the VM")
+			}
+		}
+
+		if _, _, err := sshRunner.RunPrivileged("make root Podman socket accessible", "chmod 777 /run/podman/ /run/podman/podman.sock"); err != nil {
+			return nil, errors.Wrap(err, "Failed to change permissions to root podman socket")
+		}
+
+		// applied last, so that its files and units take precedence
+		if err := applyExtraIgnition(sshRunner, startConfig.ExtraIgnition); err != nil {
+			return nil, errors.Wrap(err, "Failed to apply the extra Ignition configuration")
+		}
+		progress.complete(instancePhase)
 	}
 
 	if !vm.bundle.IsOpenShift() {
 		// **************************
 		//  END OF PODMAN START CODE
 		// **************************
+		startStats.timer.Begin(postStartPhase)
 		if err := dns.AddPodmanHosts(instanceIP); err != nil {
 			return nil, errors.Wrap(err, "Failed to add podman host dns entry")
 		}
@@ -331,17 +516,23 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 			return nil, fmt.Errorf("Failed to rotate bearer token for cockpit webconsole: %w", err)
 		}
 
+		progress.finish()
+		client.runHooks(ctx, hooks.PostStart, &types.ClusterConfig{ClusterType: vm.bundle.GetBundleType()})
 		return &types.StartResult{
 			Status: vmState,
 		}, nil
 	}
 
-	proxyConfig, err := getProxyConfig(vm.bundle.ClusterInfo.BaseDomain)
+	proxyConfig, err := getProxyConfig(vm.bundle)
 	if err != nil {
 		return nil, errors.Wrap(err, "Error getting proxy configuration")
 	}
 	proxyConfig.ApplyToEnvironment()
 	proxyConfig.AddNoProxy(instanceIP)
+	instanceProxyConfig, err := getInstanceProxyConfig(proxyConfig, client.useVSock())
+	if err != nil {
+		return nil, err
+	}
 
 	// Create servicePostStartConfig for DNS checks and DNS start.
 	servicePostStartConfig := services.ServicePostStartConfig{
@@ -352,33 +543,52 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 		// TODO: should be more finegrained
 		BundleMetadata: *vm.bundle,
 		NetworkMode:    client.networkMode(),
+		NameServers:    nameServers,
 	}
 
-	// Run the DNS server inside the VM
-	if err := dns.RunPostStart(servicePostStartConfig); err != nil {
-		return nil, errors.Wrap(err, "Error running post start")
-	}
+	startStats.timer.Begin(string(dnsPhase))
+	if !progress.done(dnsPhase) {
+		// Run the DNS server inside the VM
+		if err := dns.RunPostStart(servicePostStartConfig); err != nil {
+			return nil, errors.Wrap(err, "Error running post start")
+		}
 
-	// Check DNS lookup before starting the kubelet
-	if queryOutput, err := dns.CheckCRCLocalDNSReachable(ctx, servicePostStartConfig); err != nil {
-		if !client.useVSock() {
-			return nil, errors.Wrapf(err, "Failed internal DNS query: %s", queryOutput)
+		// Check DNS lookup before starting the kubelet
+		if queryOutput, err := dns.CheckCRCLocalDNSReachable(ctx, servicePostStartConfig); err != nil {
+			if !client.useVSock() {
+				return nil, errors.Wrapf(err, "Failed internal DNS query: %s", queryOutput)
+			}
 		}
-	}
-	logging.Info("Check internal and public DNS query...")
+		logging.Info("Check internal and public DNS query...")
 
-	if queryOutput, err := dns.CheckCRCPublicDNSReachable(servicePostStartConfig); err != nil {
-		logging.Warnf("Failed public DNS query from the cluster: %v : %s", err, queryOutput)
-	}
+		if queryOutput, err := dns.CheckCRCPublicDNSReachable(servicePostStartConfig); err != nil {
+			logging.Warnf("Failed public DNS query from the cluster: %v : %s", err, queryOutput)
+		}
+
+		// Check DNS lookup from host to VM
+		logging.Info("Check DNS query from host...")
+		if err := network.CheckCRCLocalDNSReachableFromHost(vm.bundle.GetDefaultAPIHostname(),
+			vm.bundle.GetDefaultAppHostname("foo"), vm.bundle.ClusterInfo.AppsDomain, instanceIP); err != nil {
+			if !client.useVSock() {
+				return nil, errors.Wrap(err, "Failed to query DNS from host")
+			}
+			logging.Warn(fmt.Sprintf("Failed to query DNS from host: %v", err))
+		}
+		if vm.bundle.HasCustomClusterDomain() {
+			// crc only configures the host DNS for the custom domain with
+			// NetworkManager, elsewhere the DNS of the user must resolve it
+			if err := network.CheckCRCLocalDNSReachableFromHost(vm.bundle.GetAPIHostname(),
+				vm.bundle.GetAppHostname("foo"), vm.bundle.GetAppsDomain(), instanceIP); err != nil {
+				logging.Warnf("Failed to query the cluster domain from the host: %v", err)
+				logging.Warnf("Configure your DNS server to resolve %s and *.%s to %s",
+					vm.bundle.GetAPIHostname(), vm.bundle.GetAppsDomain(), instanceIP)
+			}
+		}
 
-	// Check DNS lookup from host to VM
-	logging.Info("Check DNS query from host...")
-	if err := network.CheckCRCLocalDNSReachableFromHost(vm.bundle.GetAPIHostname(),
-		vm.bundle.GetAppHostname("foo"), vm.bundle.ClusterInfo.AppsDomain, instanceIP); err != nil {
-		if !client.useVSock() {
-			return nil, errors.Wrap(err, "Failed to query DNS from host")
+		if err := ensureCAIsTrustedInInstance(sshRunner, proxyConfig.CACert); err != nil {
+			return nil, errors.Wrap(err, "Failed to add the certificate authorities to the instance trust store")
 		}
-		logging.Warn(fmt.Sprintf("Failed to query DNS from host: %v", err))
+		progress.complete(dnsPhase)
 	}
 
 	// Check the certs validity inside the vm
@@ -388,112 +598,208 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 		return nil, errors.Wrap(err, "Failed to check certificate validity")
 	}
 
-	logging.Info("Starting OpenShift kubelet service")
-	sd := systemd.NewInstanceSystemdCommander(sshRunner)
-	if err := sd.Start("kubelet"); err != nil {
-		return nil, errors.Wrap(err, "Error starting kubelet")
-	}
-
 	ocConfig := oc.UseOCWithSSH(sshRunner)
 
-	if err := cluster.ApproveCSRAndWaitForCertsRenewal(ctx, sshRunner, ocConfig, certsExpired[cluster.KubeletClientCert], certsExpired[cluster.KubeletServerCert]); err != nil {
-		logBundleDate(vm.bundle)
-		return nil, errors.Wrap(err, "Failed to renew TLS certificates: please check if a newer CodeReady Containers release is available")
-	}
+	startStats.timer.Begin(string(kubeletPhase))
+	if !progress.done(kubeletPhase) {
+		logging.Info("Starting OpenShift kubelet service")
+		sd := systemd.NewInstanceSystemdCommander(sshRunner)
+		if err := sd.Start("kubelet"); err != nil {
+			return nil, errors.Wrap(err, "Error starting kubelet")
+		}
 
-	if err := cluster.WaitForAPIServer(ctx, ocConfig); err != nil {
-		return nil, errors.Wrap(err, "Error waiting for apiserver")
-	}
+		if err := cluster.ApproveCSRAndWaitForCertsRenewal(ctx, sshRunner, ocConfig, certsExpired[cluster.KubeletClientCert], certsExpired[cluster.KubeletServerCert]); err != nil {
+			logBundleDate(vm.bundle)
+			return nil, errors.Wrap(err, "Failed to renew TLS certificates: please check if a newer CodeReady Containers release is available")
+		}
 
-	if err := cluster.DeleteMCOLeaderLease(ctx, ocConfig); err != nil {
-		return nil, err
-	}
+		if err := cluster.WaitForAPIServer(ctx, ocConfig); err != nil {
+			return nil, crcerrors.WithClass(crcerrors.ClusterTimeout, errors.Wrap(err, "Error waiting for apiserver"))
+		}
 
-	if err := cluster.EnsurePullSecretPresentInTheCluster(ctx, ocConfig, startConfig.PullSecret); err != nil {
-		return nil, errors.Wrap(err, "Failed to update cluster pull secret")
+		// the nodes are cordoned when the cluster is stopped
+		if err := cluster.UncordonNodes(ocConfig); err != nil {
+			return nil, err
+		}
+		progress.complete(kubeletPhase)
 	}
 
-	if err := cluster.EnsureSSHKeyPresentInTheCluster(ctx, ocConfig, constants.GetPublicKeyPath()); err != nil {
-		return nil, errors.Wrap(err, "Failed to update ssh public key to machine config")
+	// the kubelet certificates can also expire while the cluster is running
+	if err := cluster.RenewExpiringCerts(ctx, sshRunner, ocConfig); err != nil {
+		logging.Warnf("Failed to renew the certificates which are about to expire: %v", err)
 	}
 
-	if err := cluster.WaitForPullSecretPresentOnInstanceDisk(ctx, sshRunner); err != nil {
-		return nil, errors.Wrap(err, "Failed to update pull secret on the disk")
-	}
+	startStats.timer.Begin(string(clusterConfigPhase))
+	if !progress.done(clusterConfigPhase) {
+		if err := cluster.DeleteMCOLeaderLease(ctx, ocConfig); err != nil {
+			return nil, err
+		}
 
-	if err := ensureProxyIsConfiguredInOpenShift(ctx, ocConfig, sshRunner, proxyConfig, instanceIP); err != nil {
-		return nil, errors.Wrap(err, "Failed to update cluster proxy configuration")
-	}
+		if err := cluster.EnsurePullSecretPresentInTheCluster(ctx, ocConfig, startConfig.PullSecret); err != nil {
+			return nil, errors.Wrap(err, "Failed to update cluster pull secret")
+		}
 
-	if err := cluster.UpdateKubeAdminUserPassword(ctx, ocConfig, startConfig.KubeAdminPassword); err != nil {
-		return nil, errors.Wrap(err, "Failed to update kubeadmin user password")
-	}
+		if err := cluster.EnsureSSHKeyPresentInTheCluster(ctx, ocConfig, constants.GetPublicKeyPath()); err != nil {
+			return nil, errors.Wrap(err, "Failed to update ssh public key to machine config")
+		}
 
-	if err := cluster.EnsureClusterIDIsNotEmpty(ctx, ocConfig); err != nil {
-		return nil, errors.Wrap(err, "Failed to update cluster ID")
-	}
+		if err := cluster.WaitForPullSecretPresentOnInstanceDisk(ctx, sshRunner); err != nil {
+			return nil, errors.Wrap(err, "Failed to update pull secret on the disk")
+		}
 
-	if client.useVSock() {
-		if err := ensureRoutesControllerIsRunning(sshRunner, ocConfig); err != nil {
-			return nil, err
+		if err := ensureProxyIsConfiguredInOpenShift(ctx, ocConfig, sshRunner, instanceProxyConfig, instanceIP); err != nil {
+			return nil, errors.Wrap(err, "Failed to update cluster proxy configuration")
 		}
-	}
 
-	if client.monitoringEnabled() {
-		logging.Info("Enabling cluster monitoring operator...")
-		if err := cluster.StartMonitoring(ocConfig); err != nil {
-			return nil, errors.Wrap(err, "Cannot start monitoring stack")
+		if vm.bundle.HasCustomClusterDomain() {
+			if err := configureClusterDomain(ctx, ocConfig, sshRunner, vm); err != nil {
+				return nil, errors.Wrap(err, "Failed to configure the cluster domain")
+			}
 		}
-	}
 
-	// In Openshift 4.3, when cluster comes up, the following happens
-	// 1. After the openshift-apiserver pod is started, its log contains multiple occurrences of `certificate has expired or is not yet valid`
-	// 2. Initially there is no request-header's client-ca crt available to `extension-apiserver-authentication` configmap
-	// 3. In the pod logs `missing content for CA bundle "client-ca::kube-system::extension-apiserver-authentication::requestheader-client-ca-file"`
-	// 4. After ~1 min /etc/kubernetes/static-pod-resources/kube-apiserver-certs/configmaps/aggregator-client-ca/ca-bundle.crt is regenerated
-	// 5. It is now also appear to `extension-apiserver-authentication` configmap as part of request-header's client-ca content
-	// 6. Openshift-apiserver is able to load the CA which was regenerated
-	// 7. Now apiserver pod log contains multiple occurrences of `error x509: certificate signed by unknown authority`
-	// When the openshift-apiserver is in this state, the cluster is non functional.
-	// A restart of the openshift-apiserver pod is enough to clear that error and get a working cluster.
-	// This is a work-around while the root cause is being identified.
-	// More info: https://bugzilla.redhat.com/show_bug.cgi?id=1795163
-	if certsExpired[cluster.AggregatorClientCert] {
-		logging.Debug("Waiting for the renewal of the request header client ca...")
-		if err := cluster.WaitForRequestHeaderClientCaFile(ctx, sshRunner); err != nil {
-			return nil, errors.Wrap(err, "Failed to wait for aggregator client ca renewal")
+		if startConfig.DualStack {
+			if err := cluster.EnableDualStack(ctx, ocConfig, sshRunner); err != nil {
+				return nil, errors.Wrap(err, "Failed to configure dual-stack networking")
+			}
 		}
 
-		if err := cluster.DeleteOpenshiftAPIServerPods(ctx, ocConfig); err != nil {
-			return nil, errors.Wrap(err, "Cannot delete OpenShift API Server pods")
+		if startConfig.EnableGPU {
+			vendors, err := gpuVendors()
+			if err != nil {
+				return nil, errors.Wrap(err, "Cannot list the GPUs assigned to the instance")
+			}
+			if err := cluster.LabelGPUNodes(ctx, ocConfig, vendors); err != nil {
+				return nil, err
+			}
+		}
+
+		if startConfig.PVPoolSize > 0 {
+			if err := cluster.DeployPVProvisioner(ctx, ocConfig, sshRunner); err != nil {
+				return nil, errors.Wrap(err, "Failed to deploy the provisioner of the persistent volumes")
+			}
 		}
-	}
 
-	if err := updateKubeconfig(ctx, ocConfig, sshRunner, vm.bundle.GetKubeConfigPath()); err != nil {
-		return nil, errors.Wrap(err, "Failed to update kubeconfig file")
+		if startConfig.RotateKubeAdminPassword && startConfig.KubeAdminPassword == "" {
+			if _, err := cluster.RotateKubeAdminUserPassword(ctx, ocConfig); err != nil {
+				return nil, errors.Wrap(err, "Failed to rotate kubeadmin password")
+			}
+		} else if err := cluster.UpdateKubeAdminUserPassword(ctx, ocConfig, startConfig.KubeAdminPassword); err != nil {
+			return nil, errors.Wrap(err, "Failed to update kubeadmin user password")
+		}
+
+		users, err := cluster.NewUserStore(constants.GetUsersPath()).List()
+		if err != nil {
+			return nil, errors.Wrap(err, "Cannot read the users of the cluster")
+		}
+		if len(users) > 0 {
+			if err := cluster.ApplyUsers(ctx, ocConfig, users); err != nil {
+				return nil, errors.Wrap(err, "Failed to apply the users of the cluster")
+			}
+		}
+
+		if err := cluster.EnsureClusterIDIsNotEmpty(ctx, ocConfig); err != nil {
+			return nil, errors.Wrap(err, "Failed to update cluster ID")
+		}
+
+		if client.useVSock() {
+			if err := ensureRoutesControllerIsRunning(sshRunner, ocConfig); err != nil {
+				return nil, err
+			}
+		}
+
+		if startConfig.EnableMonitoring {
+			logging.Info("Enabling cluster monitoring operator...")
+			if err := cluster.StartMonitoring(ctx, ocConfig); err != nil {
+				return nil, errors.Wrap(err, "Cannot start monitoring stack")
+			}
+		}
+		progress.complete(clusterConfigPhase)
+	}
+
+	startStats.timer.Begin(string(kubeconfigPhase))
+	if !progress.done(kubeconfigPhase) {
+		// In Openshift 4.3, when cluster comes up, the following happens
+		// 1. After the openshift-apiserver pod is started, its log contains multiple occurrences of `certificate has expired or is not yet valid`
+		// 2. Initially there is no request-header's client-ca crt available to `extension-apiserver-authentication` configmap
+		// 3. In the pod logs `missing content for CA bundle "client-ca::kube-system::extension-apiserver-authentication::requestheader-client-ca-file"`
+		// 4. After ~1 min /etc/kubernetes/static-pod-resources/kube-apiserver-certs/configmaps/aggregator-client-ca/ca-bundle.crt is regenerated
+		// 5. It is now also appear to `extension-apiserver-authentication` configmap as part of request-header's client-ca content
+		// 6. Openshift-apiserver is able to load the CA which was regenerated
+		// 7. Now apiserver pod log contains multiple occurrences of `error x509: certificate signed by unknown authority`
+		// When the openshift-apiserver is in this state, the cluster is non functional.
+		// A restart of the openshift-apiserver pod is enough to clear that error and get a working cluster.
+		// This is a work-around while the root cause is being identified.
+		// More info: https://bugzilla.redhat.com/show_bug.cgi?id=1795163
+		if certsExpired[cluster.AggregatorClientCert] {
+			logging.Debug("Waiting for the renewal of the request header client ca...")
+			if err := cluster.WaitForRequestHeaderClientCaFile(ctx, sshRunner); err != nil {
+				return nil, errors.Wrap(err, "Failed to wait for aggregator client ca renewal")
+			}
+
+			if err := cluster.DeleteOpenshiftAPIServerPods(ctx, ocConfig); err != nil {
+				return nil, errors.Wrap(err, "Cannot delete OpenShift API Server pods")
+			}
+		}
+
+		if err := updateKubeconfig(ctx, ocConfig, sshRunner, vm.bundle.GetKubeConfigPath()); err != nil {
+			return nil, errors.Wrap(err, "Failed to update kubeconfig file")
+		}
+
+		if err := client.startWorkers(ctx, startConfig, vm, ocConfig, servicePostStartConfig); err != nil {
+			return nil, errors.Wrap(err, "Failed to start the worker nodes")
+		}
+		progress.complete(kubeconfigPhase)
 	}
 
 	logging.Info("Starting OpenShift cluster... [waiting for the cluster to stabilize]")
-	if err := cluster.WaitForClusterStable(ctx, instanceIP, constants.KubeconfigFilePath, proxyConfig); err != nil {
+	startStats.timer.Begin(clusterStabilizationPhase)
+	slowestOperators, err := cluster.WaitForClusterStable(ctx, instanceIP, constants.KubeconfigFilePath, proxyConfig,
+		client.timeout(crcConfig.OperatorsTimeout, 0))
+	if err != nil {
 		logging.Errorf("Cluster is not ready: %v", err)
 	}
+	startStats.slowestOperators = slowestOperators
+
+	startStats.timer.Begin(postStartPhase)
+
+	waitForProxyPropagation(ctx, ocConfig, instanceProxyConfig)
+
+	var operators []cluster.OperatorInstallation
+	if len(startConfig.Operators) > 0 {
+		operators = cluster.InstallOperators(ctx, ocConfig, sshRunner, startConfig.Operators)
+	}
 
-	waitForProxyPropagation(ctx, ocConfig, proxyConfig)
+	var manifests *cluster.ManifestsApplication
+	if startConfig.Manifests != "" {
+		manifests = cluster.ApplyManifests(ctx, ocConfig, sshRunner, startConfig.Manifests)
+	}
 
-	clusterConfig, err := getClusterConfig(vm.bundle)
+	clusterConfig, err := getClusterConfig(vm.name, vm.bundle)
 	if err != nil {
 		return nil, errors.Wrap(err, "Cannot get cluster configuration")
 	}
 
-	logging.Info("Adding crc-admin and crc-developer contexts to kubeconfig...")
-	if err := writeKubeconfig(instanceIP, clusterConfig); err != nil {
-		logging.Errorf("Cannot update kubeconfig: %v", err)
+	hostPorts, err := loadHostPorts(client.name)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot read the host ports of the instance")
+	}
+	if startConfig.SkipKubeconfigUpdate {
+		logging.Info("Use 'crc kubeconfig --merge' to add the crc-admin and crc-developer contexts to kubeconfig")
+	} else {
+		logging.Info("Adding crc-admin and crc-developer contexts to kubeconfig...")
+		if err := writeKubeconfig(instanceIP, hostPorts, clusterConfig); err != nil {
+			logging.Errorf("Cannot update kubeconfig: %v", err)
+		}
 	}
 
+	progress.finish()
+	client.runHooks(ctx, hooks.PostStart, clusterConfig)
 	return &types.StartResult{
 		KubeletStarted: true,
 		ClusterConfig:  *clusterConfig,
 		Status:         vmState,
+		Operators:      operators,
+		Manifests:      manifests,
 	}, nil
 }
 
@@ -517,57 +823,74 @@ func (client *client) IsRunning() (bool, error) {
 }
 
 func (client *client) validateStartConfig(startConfig types.StartConfig) error {
-	if client.monitoringEnabled() && startConfig.Memory < minimumMemoryForMonitoring {
+	if startConfig.EnableMonitoring && startConfig.Preset == crcPreset.OpenShift && startConfig.Memory < constants.MonitoringMemory {
 		return fmt.Errorf("Too little memory (%s) allocated to the virtual machine to start the monitoring stack, %s is the minimum",
 			units.BytesSize(float64(startConfig.Memory)*1024*1024),
-			units.BytesSize(minimumMemoryForMonitoring*1024*1024))
+			units.BytesSize(constants.MonitoringMemory*1024*1024))
 	}
 	return nil
 }
 
-func createHost(machineConfig config.MachineConfig, preset crcPreset.Preset) error {
+func createHost(machineConfig config.MachineConfig, cfg crcConfig.Storage, preset crcPreset.Preset) error {
 	api, cleanup := createLibMachineClient()
 	defer cleanup()
 
+	vm, err := createVirtualMachine(api, machineConfig)
+	if err != nil {
+		return err
+	}
+
+	if err := createInstanceSSHKey(cfg, constants.GetPrivateKeyPath()); err != nil {
+		return fmt.Errorf("Error generating ssh key pair: %v", err)
+	}
+	if preset == crcPreset.OpenShift {
+		if err := cluster.GenerateKubeAdminUserPassword(); err != nil {
+			return errors.Wrap(err, "Error generating new kubeadmin password")
+		}
+	}
+	if err := api.SetExists(vm.Name); err != nil {
+		return fmt.Errorf("Failed to record VM existence: %s", err)
+	}
+
+	logging.Debug("Machine successfully created")
+	return nil
+}
+
+// createVirtualMachine creates the virtual machine with the driver, it is
+// not recorded as existing yet
+func createVirtualMachine(api libmachine.API, machineConfig config.MachineConfig) (*host.Host, error) {
 	vm, err := newHost(api, machineConfig)
 	if err != nil {
-		return fmt.Errorf("Error creating new host: %s", err)
+		return nil, fmt.Errorf("Error creating new host: %s", err)
 	}
 
 	logging.Debug("Running pre-create checks...")
 
 	if err := vm.Driver.PreCreateCheck(); err != nil {
-		return errors.Wrap(err, "error with pre-create check")
+		return nil, errors.Wrap(err, "error with pre-create check")
 	}
 
 	if err := api.Save(vm); err != nil {
-		return fmt.Errorf("Error saving host to store before attempting creation: %s", err)
+		return nil, fmt.Errorf("Error saving host to store before attempting creation: %s", err)
 	}
 
 	logging.Debug("Creating machine...")
 
 	if err := vm.Driver.Create(); err != nil {
-		return fmt.Errorf("Error in driver during machine creation: %s", err)
-	}
-
-	logging.Info("Generating new SSH Key pair...")
-	if err := crcssh.GenerateSSHKey(constants.GetPrivateKeyPath()); err != nil {
-		return fmt.Errorf("Error generating ssh key pair: %v", err)
-	}
-	if preset == crcPreset.OpenShift {
-		if err := cluster.GenerateKubeAdminUserPassword(); err != nil {
-			return errors.Wrap(err, "Error generating new kubeadmin password")
+		// the driver may have created some of the resources of the machine
+		if err := vm.Driver.Remove(); err != nil {
+			logging.Debugf("Cannot remove the partially created machine: %v", err)
 		}
-	}
-	if err := api.SetExists(vm.Name); err != nil {
-		return fmt.Errorf("Failed to record VM existence: %s", err)
+		if err := api.Remove(vm.Name); err != nil {
+			logging.Debugf("Cannot remove the partially created machine: %v", err)
+		}
+		return nil, fmt.Errorf("Error in driver during machine creation: %s", err)
 	}
 
-	logging.Debug("Machine successfully created")
-	return nil
+	return vm, nil
 }
 
-func startHost(ctx context.Context, vm *virtualMachine) error {
+func startHost(ctx context.Context, vm *virtualMachine, timeout time.Duration) error {
 	if err := vm.Driver.Start(); err != nil {
 		return fmt.Errorf("Error in driver during machine start: %s", err)
 	}
@@ -577,7 +900,7 @@ func startHost(ctx context.Context, vm *virtualMachine) error {
 	}
 
 	logging.Debug("Waiting for machine to be running, this may take a few minutes...")
-	if err := crcerrors.Retry(ctx, 3*time.Minute, host.MachineInState(vm.Driver, libmachinestate.Running), 3*time.Second); err != nil {
+	if err := crcerrors.Retry(ctx, timeout, host.MachineInState(vm.Driver, libmachinestate.Running), 3*time.Second); err != nil {
 		return fmt.Errorf("Error waiting for machine to be running: %s", err)
 	}
 
@@ -586,6 +909,30 @@ func startHost(ctx context.Context, vm *virtualMachine) error {
 	return nil
 }
 
+// applyExtraIgnition applies the Ignition configuration of the extra-ignition
+// setting, the bundles are provisioned when they are built so Ignition does
+// not run when the instance boots. Its kernel arguments are applied by
+// updateKernelArguments.
+func applyExtraIgnition(sshRunner *crcssh.Runner, path string) error {
+	if path == "" {
+		return nil
+	}
+	ignitionConfig, err := ignition.Load(path)
+	if err != nil {
+		return err
+	}
+	logging.Infof("Applying the Ignition configuration %s", path)
+	return ignition.Apply(sshRunner, ignitionConfig)
+}
+
+func enablePeriodicTrim(sshRunner *crcssh.Runner) error {
+	sd := systemd.NewInstanceSystemdCommander(sshRunner)
+	if err := sd.Enable(fstrimTimer); err != nil {
+		return err
+	}
+	return sd.Start(fstrimTimer)
+}
+
 func addNameServerToInstance(sshRunner *crcssh.Runner, ns string) error {
 	nameserver := network.NameServer{IPAddress: ns}
 	nameservers := []network.NameServer{nameserver}
@@ -600,9 +947,43 @@ func addNameServerToInstance(sshRunner *crcssh.Runner, ns string) error {
 	return nil
 }
 
+// ensureCAIsTrustedInInstance adds the user provided certificate authorities
+// to the trust store of the instance, so that cri-o can pull images through
+// a TLS-intercepting proxy. cri-o only reads the trust store when it starts.
+func ensureCAIsTrustedInInstance(sshRunner *crcssh.Runner, caCert string) error {
+	current, _, err := sshRunner.Run(fmt.Sprintf("sudo cat %s 2>/dev/null || true", instanceCAFilePath))
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(current) == strings.TrimSpace(caCert) {
+		return nil
+	}
+	if caCert == "" {
+		logging.Info("Removing additional certificate authorities from the instance...")
+		if _, _, err := sshRunner.RunPrivileged("Removing additional CAs", "rm", "-f", instanceCAFilePath); err != nil {
+			return err
+		}
+	} else {
+		logging.Info("Adding additional certificate authorities to the instance...")
+		if err := sshRunner.CopyData([]byte(caCert+"\n"), instanceCAFilePath, 0644); err != nil {
+			return err
+		}
+	}
+	if _, _, err := sshRunner.RunPrivileged("Updating the CA trust store", "update-ca-trust", "extract"); err != nil {
+		return err
+	}
+	_, _, err = sshRunner.RunPrivileged("Restarting cri-o to reload the CA trust store", "systemctl", "restart", "crio")
+	return err
+}
+
 func updateSSHKeyPair(sshRunner *crcssh.Runner) error {
-	// Read generated public key
-	publicKey, err := ioutil.ReadFile(constants.GetPublicKeyPath())
+	return authorizeSSHKey(sshRunner, constants.GetPublicKeyPath())
+}
+
+// authorizeSSHKey replaces the authorized keys of the core user of the
+// instance with the public key at publicKeyPath
+func authorizeSSHKey(sshRunner *crcssh.Runner, publicKeyPath string) error {
+	publicKey, err := ioutil.ReadFile(publicKeyPath)
 	if err != nil {
 		return err
 	}
@@ -633,8 +1014,22 @@ func copyKubeconfigFileWithUpdatedUserClientCertAndKey(selfSignedCAKey *rsa.Priv
 	return updateClientCrtAndKeyToKubeconfig(clientKey, clientCert, srcKubeConfigPath, dstKubeConfigPath)
 }
 
+// getInstanceProxyConfig returns the proxy configuration of the cluster,
+// OpenShift does not support SOCKS5 proxies and the authentication methods
+// other than basic, the cluster uses the relay of the daemon to reach them
+func getInstanceProxyConfig(proxy *network.ProxyConfig, useVSock bool) (*network.ProxyConfig, error) {
+	if !proxy.NeedsRelay() {
+		return proxy, nil
+	}
+	if !useVSock {
+		return nil, fmt.Errorf("SOCKS5 proxies and the %s proxy authentication are only supported with the %s network mode",
+			proxy.AuthMethod, network.UserNetworkingMode)
+	}
+	return proxy.ForInstance(fmt.Sprintf("http://%s:%d", constants.VSockHostVirtualIP, constants.ProxyRelayPort)), nil
+}
+
 func ensureProxyIsConfiguredInOpenShift(ctx context.Context, ocConfig oc.Config, sshRunner *crcssh.Runner, proxy *network.ProxyConfig, instanceIP string) (err error) {
-	if !proxy.IsEnabled() {
+	if !proxy.IsEnabled() && proxy.CACert == "" {
 		return nil
 	}
 	logging.Info("Adding proxy configuration to the cluster...")
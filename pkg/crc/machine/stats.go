@@ -0,0 +1,67 @@
+package machine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	crcerrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/stats"
+)
+
+// The phases of a start recorded in constants.StatsPath, with the
+// provisioning phases
+const (
+	bundlePhase               = "bundle"
+	creationPhase             = "creation"
+	vmStartPhase              = "vm-start"
+	sshPhase                  = "ssh"
+	clusterStabilizationPhase = "cluster-stabilization"
+	postStartPhase            = "post-start"
+)
+
+// startStats measures a start, it is only recorded when the instance was not
+// already running
+type startStats struct {
+	timer            *stats.Timer
+	bundle           string
+	creation         bool
+	record           bool
+	slowestOperators []string
+}
+
+func (client *client) Start(ctx context.Context, startConfig types.StartConfig) (*types.StartResult, error) {
+	timeout := client.timeout(crcConfig.StartTimeout, 0)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if startConfig.Fresh {
+		if err := client.freshStart(); err != nil {
+			return nil, err
+		}
+	}
+	startStats := &startStats{timer: stats.NewTimer()}
+	result, err := client.start(ctx, startConfig, startStats)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = crcerrors.WithClass(crcerrors.ClusterTimeout, fmt.Errorf("The start did not complete in %s: %w", timeout, err))
+	}
+	if err != nil {
+		client.recordStartFailure(err)
+	} else {
+		setExpectedRunning(client.name, true)
+	}
+	if startStats.record {
+		record := startStats.timer.Record(startStats.bundle, startStats.creation, err == nil)
+		record.SlowestOperators = startStats.slowestOperators
+		if err := stats.NewStore(constants.StatsPath).Add(record); err != nil {
+			logging.Debugf("Cannot record the duration of the start: %v", err)
+		}
+	}
+	return result, err
+}
@@ -3,6 +3,7 @@ package machine
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/code-ready/crc/pkg/crc/cluster"
 	"github.com/code-ready/crc/pkg/crc/constants"
@@ -43,6 +44,7 @@ func (client *client) Status() (*types.ClusterStatusResult, error) {
 			clusterStatusResult.PodmanVersion = vm.bundle.GetPodmanVersion()
 			clusterStatusResult.Preset = preset.Podman
 		}
+		clusterStatusResult.Workers = client.workersStatus(nil)
 		return clusterStatusResult, nil
 	}
 
@@ -52,15 +54,25 @@ func (client *client) Status() (*types.ClusterStatusResult, error) {
 	}
 
 	diskSize, diskUse := client.getDiskDetails(vm)
+	ramSize, ramUse := getRAMDetails(vm)
 	clusterStatusResult := &types.ClusterStatusResult{
 		CrcStatus: state.Running,
+		IP:        ip,
 		DiskUse:   diskUse,
 		DiskSize:  diskSize,
+		RAMUse:    ramUse,
+		RAMSize:   ramSize,
 	}
+	clusterStatusResult.NetworkConflicts = client.getNetworkConflicts(vm, ip)
+	clusterStatusResult.ClockSkew = getClockSkew(vm)
 	if vm.bundle.IsOpenShift() {
-		clusterStatusResult.OpenshiftStatus = getOpenShiftStatus(context.Background(), ip)
+		clusterStatusResult.PVPoolSize, clusterStatusResult.PVPoolUse = client.getPVPoolDetails(vm)
+		clusterStatusResult.OpenshiftStatus, clusterStatusResult.DegradedOperators = getOpenShiftStatus(context.Background(), ip)
+		clusterStatusResult.Certificates = client.getCertsExpiry(vm)
+		clusterStatusResult.ClusterMonitoring = getMonitoringEnabled(context.Background(), ip)
 		clusterStatusResult.OpenshiftVersion = vm.bundle.GetOpenshiftVersion()
 		clusterStatusResult.Preset = preset.OpenShift
+		clusterStatusResult.Workers = client.workersStatus(vm)
 	} else {
 		clusterStatusResult.PodmanVersion = vm.bundle.GetPodmanVersion()
 		clusterStatusResult.Preset = preset.Podman
@@ -88,19 +100,129 @@ func (client *client) getDiskDetails(vm *virtualMachine) (int64, int64) {
 	return disk.([]int64)[0], disk.([]int64)[1]
 }
 
-func getOpenShiftStatus(ctx context.Context, ip string) types.OpenshiftStatus {
+func (client *client) getPVPoolDetails(vm *virtualMachine) (int64, int64) {
+	pool, err, _ := client.diskDetails.Memoize("pv-pool", func() (interface{}, error) {
+		sshRunner, err := vm.SSHRunner()
+		if err != nil {
+			return nil, errors.Wrap(err, "Error creating the ssh client")
+		}
+		defer sshRunner.Close()
+		poolSize, poolUse, err := cluster.GetPVPoolUsage(sshRunner)
+		if err != nil {
+			return nil, err
+		}
+		return []int64{poolSize, poolUse}, nil
+	})
+	if err != nil {
+		logging.Debugf("Cannot get PV pool usage: %v", err)
+		return 0, 0
+	}
+	return pool.([]int64)[0], pool.([]int64)[1]
+}
+
+// The certificates are only renewed every few weeks, their expiry dates are
+// memoized for longer than the disk details
+func (client *client) getCertsExpiry(vm *virtualMachine) []cluster.CertExpiry {
+	certs, err, _ := client.certsExpiry.Memoize("certs", func() (interface{}, error) {
+		sshRunner, err := vm.SSHRunner()
+		if err != nil {
+			return nil, errors.Wrap(err, "Error creating the ssh client")
+		}
+		defer sshRunner.Close()
+		return cluster.GetCertsExpiry(sshRunner)
+	})
+	if err != nil {
+		logging.Debugf("Cannot get the expiry dates of the certificates: %v", err)
+		return nil
+	}
+	return certs.([]cluster.CertExpiry)
+}
+
+// The clock of the instance can be set at any time, the skew is not memoized
+func getClockSkew(vm *virtualMachine) time.Duration {
+	sshRunner, err := vm.SSHRunner()
+	if err != nil {
+		logging.Debugf("Error creating the ssh client: %v", err)
+		return 0
+	}
+	defer sshRunner.Close()
+	skew, _, err := measureClockSkew(sshRunner)
+	if err != nil {
+		logging.Debugf("Cannot get the clock skew of the instance: %v", err)
+		return 0
+	}
+	return skew
+}
+
+// topPods is the number of pods listed by ComponentsUsage in addition to the
+// control plane components
+const topPods = 5
+
+// ComponentsUsage returns the resource usage of kube-apiserver, etcd and of
+// the pods using the most memory
+func (client *client) ComponentsUsage() ([]cluster.ComponentUsage, error) {
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
+	if err != nil {
+		return nil, errors.Wrap(err, "Error loading machine")
+	}
+	defer vm.Close()
+
+	vmState, err := vm.State()
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot get machine state")
+	}
+	if vmState != state.Running {
+		return nil, errors.New("machine is not running")
+	}
+	if !vm.bundle.IsOpenShift() {
+		return nil, fmt.Errorf("The resource usage of the components is only available with the %s preset", preset.OpenShift)
+	}
+	sshRunner, err := vm.SSHRunner()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating the ssh client")
+	}
+	defer sshRunner.Close()
+	return cluster.GetComponentsUsage(sshRunner, topPods)
+}
+
+// RAM usage changes quickly, so unlike the disk details it is not memoized
+func getRAMDetails(vm *virtualMachine) (int64, int64) {
+	sshRunner, err := vm.SSHRunner()
+	if err != nil {
+		logging.Debugf("Error creating the ssh client: %v", err)
+		return 0, 0
+	}
+	defer sshRunner.Close()
+	ramSize, ramUse, err := cluster.GetRAMUsage(sshRunner)
+	if err != nil {
+		logging.Debugf("Cannot get RAM usage: %v", err)
+		return 0, 0
+	}
+	return ramSize, ramUse
+}
+
+func getMonitoringEnabled(ctx context.Context, ip string) bool {
+	enabled, err := cluster.IsMonitoringEnabled(ctx, ip, constants.KubeconfigFilePath)
+	if err != nil {
+		logging.Debugf("Cannot get the state of the cluster monitoring: %v", err)
+		return false
+	}
+	return enabled
+}
+
+func getOpenShiftStatus(ctx context.Context, ip string) (types.OpenshiftStatus, []string) {
 	status, err := cluster.GetClusterOperatorsStatus(ctx, ip, constants.KubeconfigFilePath)
 	if err != nil {
 		logging.Debugf("cannot get OpenShift status: %v", err)
-		return types.OpenshiftUnreachable
+		return types.OpenshiftUnreachable, nil
 	}
 	switch {
 	case status.Progressing:
-		return types.OpenshiftStarting
+		return types.OpenshiftStarting, status.DegradedOperators()
 	case status.Degraded:
-		return types.OpenshiftDegraded
+		return types.OpenshiftDegraded, status.DegradedOperators()
 	case status.Available:
-		return types.OpenshiftRunning
+		return types.OpenshiftRunning, nil
 	}
-	return types.OpenshiftStopped
+	return types.OpenshiftStopped, nil
 }
@@ -1,14 +1,43 @@
 package machine
 
 import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/hooks"
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/oc"
 	crcPreset "github.com/code-ready/crc/pkg/crc/preset"
 	"github.com/code-ready/crc/pkg/crc/systemd"
 	"github.com/pkg/errors"
 )
 
-func (client *client) Stop() (state.State, error) {
+// DefaultStopTimeout is how long the graceful shutdown of the instance can
+// take before the stop fails
+const DefaultStopTimeout = 3 * time.Minute
+
+// StopTimeoutError is returned when the instance is still running after the
+// graceful shutdown timeout
+type StopTimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *StopTimeoutError) Error() string {
+	return fmt.Sprintf("The instance did not shut down within %s", e.Timeout)
+}
+
+// Stop shuts the instance down gracefully: the nodes are cordoned, the
+// containers are stopped and the instance is powered off from the inside.
+// The stop fails if the instance is still running after stopConfig.Timeout,
+// it is then up to the caller to use PowerOff.
+func (client *client) Stop(stopConfig types.StopConfig) (state.State, error) {
+	// also when the instance crashed, the watchdog no longer reports it
+	setExpectedRunning(client.name, false)
 	if running, _ := client.IsRunning(); !running {
 		return state.Error, errors.New("Instance is already stopped")
 	}
@@ -17,6 +46,15 @@ func (client *client) Stop() (state.State, error) {
 		return state.Error, errors.Wrap(err, "Cannot load machine")
 	}
 	defer vm.Close()
+	if clusterConfig, err := getClusterConfig(vm.name, vm.bundle); err != nil {
+		logging.Debugf("Cannot get the cluster configuration for the pre-stop hooks: %v", err)
+	} else {
+		client.runHooks(context.Background(), hooks.PreStop, clusterConfig)
+	}
+	client.stopWorkers()
+	if client.config.Get(crcConfig.EnableImageCache).AsBool() {
+		saveImagesToCache(vm)
+	}
 	if client.GetPreset() == crcPreset.OpenShift {
 		if err := stopAllContainers(vm); err != nil {
 			logging.Warnf("Failed to stop all OpenShift containers.\nShutting down VM...")
@@ -24,18 +62,17 @@ func (client *client) Stop() (state.State, error) {
 		}
 	}
 	logging.Info("Stopping the instance, this may take a few minutes...")
-	if err := vm.Stop(); err != nil {
-		status, stateErr := vm.State()
-		if stateErr != nil {
-			logging.Debugf("Cannot get VM status after stopping it: %v", stateErr)
+	if err := shutdownInstance(vm); err != nil {
+		logging.Debugf("Cannot shut down the instance with SSH, falling back to the machine driver: %v", err)
+		if err := vm.Stop(); err != nil {
+			status, stateErr := vm.State()
+			if stateErr != nil {
+				logging.Debugf("Cannot get VM status after stopping it: %v", stateErr)
+			}
+			return status, errors.Wrap(err, "Cannot stop machine")
 		}
-		return status, errors.Wrap(err, "Cannot stop machine")
 	}
-	status, err := vm.State()
-	if err != nil {
-		return state.Error, errors.Wrap(err, "Cannot get VM status")
-	}
-	return status, nil
+	return waitForShutdown(vm, stopConfig.Timeout)
 }
 
 // This should be removed after https://bugzilla.redhat.com/show_bug.cgi?id=1965992
@@ -50,6 +87,10 @@ func stopAllContainers(vm *virtualMachine) error {
 	}
 	defer sshRunner.Close()
 
+	// the nodes are uncordoned during the next start
+	if err := cluster.CordonNodes(oc.UseOCWithSSH(sshRunner).WithFailFast()); err != nil {
+		logging.Debugf("%v", err)
+	}
 	if err := systemd.NewInstanceSystemdCommander(sshRunner).Stop("kubelet"); err != nil {
 		return err
 	}
@@ -60,3 +101,51 @@ func stopAllContainers(vm *virtualMachine) error {
 	}
 	return nil
 }
+
+// saveImagesToCache copies the pulled images to the image cache, errors are
+// logged as the instance must be stopped anyway
+func saveImagesToCache(vm *virtualMachine) {
+	sshRunner, err := vm.SSHRunner()
+	if err != nil {
+		logging.Debugf("Error creating the ssh client: %v", err)
+		return
+	}
+	defer sshRunner.Close()
+	if _, _, err := sshRunner.Run("mountpoint", "-q", cluster.ImageCacheDir); err != nil {
+		logging.Debugf("The image cache is not mounted in the instance")
+		return
+	}
+	if err := cluster.SaveImagesToCache(sshRunner); err != nil {
+		logging.Warnf("Cannot save the images to the image cache: %v", err)
+	}
+}
+
+func shutdownInstance(vm *virtualMachine) error {
+	sshRunner, err := vm.SSHRunner()
+	if err != nil {
+		return errors.Wrapf(err, "Error creating the ssh client")
+	}
+	defer sshRunner.Close()
+
+	// the connection is closed by the shutdown, so the error is only
+	// relevant when the shutdown did not start
+	_, _, err = sshRunner.RunPrivileged("Shutting down the instance", "systemctl", "--no-block", "poweroff")
+	return err
+}
+
+func waitForShutdown(vm *virtualMachine, timeout time.Duration) (state.State, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		status, err := vm.State()
+		if err != nil {
+			return state.Error, errors.Wrap(err, "Cannot get VM status")
+		}
+		if status == state.Stopped {
+			return status, nil
+		}
+		if time.Now().After(deadline) {
+			return status, &StopTimeoutError{Timeout: timeout}
+		}
+		time.Sleep(time.Second)
+	}
+}
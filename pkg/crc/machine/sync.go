@@ -3,13 +3,16 @@ package machine
 import (
 	"context"
 	"errors"
+	"io"
 	"sync"
 	"time"
 
+	"github.com/code-ready/crc/pkg/crc/cluster"
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine/state"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
 	crcPreset "github.com/code-ready/crc/pkg/crc/preset"
+	"k8s.io/client-go/tools/clientcmd/api"
 )
 
 const startCancelTimeout = 15 * time.Second
@@ -95,6 +98,18 @@ func (s *Synchronized) Start(ctx context.Context, startConfig types.StartConfig)
 	return startResult, err
 }
 
+// Upgrade is cancelled like a start, the instance is recreated by it
+func (s *Synchronized) Upgrade(ctx context.Context, startConfig types.StartConfig) (*types.UpgradeResult, error) {
+	ctx, startCancel := context.WithCancel(ctx)
+	if err := s.prepareStart(startCancel); err != nil {
+		return nil, err
+	}
+
+	upgradeResult, err := s.underlying.Upgrade(ctx, startConfig)
+	s.syncOperationDone <- Starting
+	return upgradeResult, err
+}
+
 /* cancel ongoing start, and wait until the start is fully cancelled. Time out if cancellation takes more than 'timeout'
  * s.stateLock must be locked before calling this function
  */
@@ -132,12 +147,12 @@ func (s *Synchronized) prepareStopDelete(state State) error {
 	return nil
 }
 
-func (s *Synchronized) Stop() (state.State, error) {
+func (s *Synchronized) Stop(stopConfig types.StopConfig) (state.State, error) {
 	if err := s.prepareStopDelete(Stopping); err != nil {
 		return state.Error, err
 	}
 
-	st, err := s.underlying.Stop()
+	st, err := s.underlying.Stop(stopConfig)
 	s.syncOperationDone <- Stopping
 
 	return st, err
@@ -155,14 +170,34 @@ func (s *Synchronized) GetConsoleURL() (*types.ConsoleResult, error) {
 	return s.underlying.GetConsoleURL()
 }
 
+func (s *Synchronized) GetKubeAdminToken() (string, error) {
+	return s.underlying.GetKubeAdminToken()
+}
+
+func (s *Synchronized) GetKubeconfig() (*api.Config, error) {
+	return s.underlying.GetKubeconfig()
+}
+
 func (s *Synchronized) ConnectionDetails() (*types.ConnectionDetails, error) {
 	return s.underlying.ConnectionDetails()
 }
 
+func (s *Synchronized) Addresses() ([]types.Address, error) {
+	return s.underlying.Addresses()
+}
+
+func (s *Synchronized) Routes() ([]types.Route, error) {
+	return s.underlying.Routes()
+}
+
 func (s *Synchronized) PowerOff() error {
 	return s.underlying.PowerOff()
 }
 
+func (s *Synchronized) CheckReadiness(ctx context.Context, readinessConfig types.ReadinessConfig) (*types.ReadinessResult, error) {
+	return s.underlying.CheckReadiness(ctx, readinessConfig)
+}
+
 func (s *Synchronized) Status() (*types.ClusterStatusResult, error) {
 	switch s.CurrentState() {
 	case Starting:
@@ -182,6 +217,14 @@ func (s *Synchronized) Status() (*types.ClusterStatusResult, error) {
 	}
 }
 
+func (s *Synchronized) ComponentsUsage() ([]cluster.ComponentUsage, error) {
+	return s.underlying.ComponentsUsage()
+}
+
+func (s *Synchronized) Logs(ctx context.Context, logsConfig types.LogsConfig, writer io.Writer) error {
+	return s.underlying.Logs(ctx, logsConfig, writer)
+}
+
 func (s *Synchronized) IsRunning() (bool, error) {
 	return s.underlying.IsRunning()
 }
@@ -190,6 +233,65 @@ func (s *Synchronized) GenerateBundle(forceStop bool) error {
 	return s.underlying.GenerateBundle(forceStop)
 }
 
+func (s *Synchronized) CompactDisk() (*types.CompactDiskResult, error) {
+	return s.underlying.CompactDisk()
+}
+
+func (s *Synchronized) ActiveConnections() (int, error) {
+	return s.underlying.ActiveConnections()
+}
+
+func (s *Synchronized) RenewExpiringCertificates(ctx context.Context) error {
+	return s.underlying.RenewExpiringCertificates(ctx)
+}
+
+func (s *Synchronized) InstallOpenShiftClient() (*types.OpenShiftClient, error) {
+	return s.underlying.InstallOpenShiftClient()
+}
+
 func (s *Synchronized) GetPreset() crcPreset.Preset {
 	return s.underlying.GetPreset()
 }
+
+func (s *Synchronized) DiagnoseNetwork() (*types.NetworkDiagnosis, error) {
+	return s.underlying.DiagnoseNetwork()
+}
+
+func (s *Synchronized) TestDNS(name string) (*types.DNSTest, error) {
+	return s.underlying.TestDNS(name)
+}
+
+func (s *Synchronized) SyncTime() (time.Duration, error) {
+	return s.underlying.SyncTime()
+}
+
+func (s *Synchronized) FlushDisks() error {
+	return s.underlying.FlushDisks()
+}
+
+func (s *Synchronized) RotateSSHKey(ctx context.Context) error {
+	return s.underlying.RotateSSHKey(ctx)
+}
+
+func (s *Synchronized) Workers() ([]types.WorkerStatus, error) {
+	return s.underlying.Workers()
+}
+
+// The worker nodes are not added or removed while the cluster starts or stops
+func (s *Synchronized) AddWorker(ctx context.Context, workerConfig types.WorkerConfig) (*types.WorkerStatus, error) {
+	if s.CurrentState() != Idle {
+		return nil, errors.New("cluster is busy")
+	}
+	return s.underlying.AddWorker(ctx, workerConfig)
+}
+
+func (s *Synchronized) RemoveWorker(name string) (string, error) {
+	if s.CurrentState() != Idle {
+		return "", errors.New("cluster is busy")
+	}
+	return s.underlying.RemoveWorker(name)
+}
+
+func (s *Synchronized) StopWorker(name string) error {
+	return s.underlying.StopWorker(name)
+}
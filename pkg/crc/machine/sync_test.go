@@ -3,13 +3,17 @@ package machine
 import (
 	"context"
 	"errors"
+	"io"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/code-ready/crc/pkg/crc/cluster"
 	"github.com/code-ready/crc/pkg/crc/machine/state"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
 	crcPreset "github.com/code-ready/crc/pkg/crc/preset"
 	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/clientcmd/api"
 )
 
 func TestOneStartAtTheSameTime(t *testing.T) {
@@ -62,7 +66,7 @@ func TestDeleteStop(t *testing.T) {
 	<-isRunning
 	assert.Equal(t, Deleting, syncMachine.CurrentState())
 	assert.EqualError(t, syncMachine.Delete(), "cluster is stopping or deleting")
-	_, err := syncMachine.Stop()
+	_, err := syncMachine.Stop(types.StopConfig{})
 	assert.EqualError(t, err, "cluster is stopping or deleting")
 	_, err = syncMachine.Start(context.Background(), types.StartConfig{})
 	assert.EqualError(t, err, "cluster is busy")
@@ -136,6 +140,18 @@ func (m *waitingMachine) GetConsoleURL() (*types.ConsoleResult, error) {
 	return nil, errors.New("not implemented")
 }
 
+func (m *waitingMachine) GetKubeAdminToken() (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (m *waitingMachine) GetKubeconfig() (*api.Config, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *waitingMachine) CheckReadiness(_ context.Context, _ types.ReadinessConfig) (*types.ReadinessResult, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (m *waitingMachine) ConnectionDetails() (*types.ConnectionDetails, error) {
 	return nil, errors.New("not implemented")
 }
@@ -144,6 +160,10 @@ func (m *waitingMachine) PowerOff() error {
 	return nil
 }
 
+func (m *waitingMachine) Upgrade(_ context.Context, _ types.StartConfig) (*types.UpgradeResult, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (m *waitingMachine) Start(context context.Context, _ types.StartConfig) (*types.StartResult, error) {
 	m.isRunning <- struct{}{}
 	select {
@@ -161,7 +181,15 @@ func (m *waitingMachine) Status() (*types.ClusterStatusResult, error) {
 	return nil, errors.New("not implemented")
 }
 
-func (m *waitingMachine) Stop() (state.State, error) {
+func (m *waitingMachine) ComponentsUsage() ([]cluster.ComponentUsage, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *waitingMachine) Logs(ctx context.Context, logsConfig types.LogsConfig, writer io.Writer) error {
+	return errors.New("not implemented")
+}
+
+func (m *waitingMachine) Stop(stopConfig types.StopConfig) (state.State, error) {
 	m.isRunning <- struct{}{}
 	<-m.stopCompleteCh
 	return state.Stopped, nil
@@ -171,6 +199,66 @@ func (m *waitingMachine) GenerateBundle(forceStop bool) error {
 	return errors.New("not implemented")
 }
 
+func (m *waitingMachine) CompactDisk() (*types.CompactDiskResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *waitingMachine) ActiveConnections() (int, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (m *waitingMachine) RenewExpiringCertificates(ctx context.Context) error {
+	return errors.New("not implemented")
+}
+
+func (m *waitingMachine) InstallOpenShiftClient() (*types.OpenShiftClient, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *waitingMachine) Addresses() ([]types.Address, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *waitingMachine) Routes() ([]types.Route, error) {
+	return nil, errors.New("not implemented")
+}
+
 func (m *waitingMachine) GetPreset() crcPreset.Preset {
 	return crcPreset.OpenShift
 }
+
+func (m *waitingMachine) DiagnoseNetwork() (*types.NetworkDiagnosis, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *waitingMachine) TestDNS(name string) (*types.DNSTest, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *waitingMachine) SyncTime() (time.Duration, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (m *waitingMachine) FlushDisks() error {
+	return errors.New("not implemented")
+}
+
+func (m *waitingMachine) RotateSSHKey(ctx context.Context) error {
+	return errors.New("not implemented")
+}
+
+func (m *waitingMachine) Workers() ([]types.WorkerStatus, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *waitingMachine) AddWorker(ctx context.Context, workerConfig types.WorkerConfig) (*types.WorkerStatus, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *waitingMachine) RemoveWorker(name string) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (m *waitingMachine) StopWorker(name string) error {
+	return errors.New("not implemented")
+}
@@ -1,11 +1,14 @@
 package types
 
 import (
+	"time"
+
 	"github.com/code-ready/crc/pkg/crc/cluster"
 	"github.com/code-ready/crc/pkg/crc/machine/state"
 	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/crc/preset"
 	crcpreset "github.com/code-ready/crc/pkg/crc/preset"
+	"github.com/code-ready/crc/pkg/crc/statefile"
 )
 
 type StartConfig struct {
@@ -26,8 +29,82 @@ type StartConfig struct {
 	// User defined kubeadmin password
 	KubeAdminPassword string
 
+	// Generate a new kubeadmin password on every start
+	RotateKubeAdminPassword bool
+
+	// Do not add the contexts of the cluster to the default kubeconfig
+	SkipKubeconfigUpdate bool
+
 	// Preset
 	Preset crcpreset.Preset
+
+	// Number of worker nodes, in addition to the control plane node
+	Workers int
+	// Size of the worker nodes created by the start
+	Worker WorkerConfig
+
+	// Domain of the cluster, empty to use the domain of the bundle
+	ClusterDomain string
+
+	// Add IPv6 networks to the cluster network configuration
+	DualStack bool
+
+	// Ports of the host forwarded to the cluster in the user network mode
+	HostPorts network.HostPorts
+
+	// Forward the ports of the cluster from free host ports when other
+	// processes use HostPorts, in the user network mode
+	AlternativeHostPorts bool
+
+	// Assign the GPUs bound to vfio-pci to the instance
+	EnableGPU bool
+
+	// Start the cluster monitoring of the OpenShift preset
+	EnableMonitoring bool
+
+	// Expose the virtualization extensions of the processor to the instance
+	NestedVirtualization bool
+
+	// Run the x86_64 executables of the instance with Rosetta
+	EnableRosetta bool
+
+	// Size in GiB of the pool of the persistent volumes, 0 without pool
+	PVPoolSize int
+
+	// Host directories mounted in the instance
+	SharedDirs []string
+
+	// Password of the host user, needed to mount the directories over SMB
+	SharedDirPassword string
+
+	// Keep the images pulled in the instance in a host directory
+	ImageCache bool
+
+	// Operator packages installed from the catalogs when the cluster is ready
+	Operators []string
+
+	// Host directory of the manifests applied when the cluster is ready
+	Manifests string
+
+	// Ignition configuration applied to the instance on every start
+	ExtraIgnition string
+
+	// Number of huge pages reserved when the instance boots
+	HugePages int
+
+	// Version of the control groups of the instance, default keeps the
+	// one of the bundle
+	CgroupsVersion string
+
+	// Kernel parameters set in the instance
+	Sysctls map[string]string
+
+	// Resume the provisioning of a running instance from the last phase
+	// completed by a failed start
+	Resume bool
+
+	// Remove the instance created by a failed start before starting
+	Fresh bool
 }
 
 type ClusterConfig struct {
@@ -44,6 +121,12 @@ type StartResult struct {
 	Status         state.State
 	ClusterConfig  ClusterConfig
 	KubeletStarted bool
+	// Operators are the results of the installation of the operators of
+	// the start configuration
+	Operators []cluster.OperatorInstallation
+	// Manifests is the result of the application of the manifests of the
+	// start configuration
+	Manifests *cluster.ManifestsApplication
 }
 
 type StopResult struct {
@@ -54,13 +137,43 @@ type StopResult struct {
 }
 
 type ClusterStatusResult struct {
-	CrcStatus        state.State
-	OpenshiftStatus  OpenshiftStatus
-	OpenshiftVersion string
-	PodmanVersion    string
-	DiskUse          int64
-	DiskSize         int64
-	Preset           crcpreset.Preset
+	CrcStatus         state.State
+	OpenshiftStatus   OpenshiftStatus
+	OpenshiftVersion  string
+	PodmanVersion     string
+	IP                string
+	DiskUse           int64
+	DiskSize          int64
+	RAMUse            int64
+	RAMSize           int64
+	PVPoolUse         int64
+	PVPoolSize        int64
+	ClusterMonitoring bool
+	DegradedOperators []string
+	Certificates      []cluster.CertExpiry
+	Preset            crcpreset.Preset
+	Workers           []WorkerStatus
+	NetworkConflicts  []network.Conflict
+	// ClockSkew is how far the clock of the instance is ahead of the clock
+	// of the host
+	ClockSkew time.Duration
+}
+
+// WorkerConfig is the size of a worker node instance
+type WorkerConfig struct {
+	CPUs   int
+	Memory int // Memory size in MiB
+}
+
+// WorkerStatus is the state of a worker node instance, Ready is true when its
+// node is ready in the cluster
+type WorkerStatus struct {
+	Name   string
+	State  state.State
+	IP     string
+	Ready  bool
+	CPUs   int `json:",omitempty"`
+	Memory int `json:",omitempty"`
 }
 
 type OpenshiftStatus string
@@ -74,14 +187,174 @@ const (
 	OpenshiftStopping    OpenshiftStatus = "Stopping"
 )
 
+type ReadinessCheck string
+
+const (
+	APIReadiness       ReadinessCheck = "api"
+	IngressReadiness   ReadinessCheck = "ingress"
+	OperatorsReadiness ReadinessCheck = "operators"
+)
+
+// ReadinessConfig defines when the cluster is considered ready
+type ReadinessConfig struct {
+	Checks []ReadinessCheck
+	// Operators are the cluster operators which must be available, all of
+	// them when empty
+	Operators []string
+}
+
+type ReadinessCheckResult struct {
+	Check   ReadinessCheck
+	Ready   bool
+	Message string
+}
+
+type ReadinessResult struct {
+	Ready  bool
+	Checks []ReadinessCheckResult
+}
+
 type ConsoleResult struct {
 	ClusterConfig ClusterConfig
 	State         state.State
 }
 
+// OpenShiftClient is the oc executable matching the OpenShift version of the
+// instance
+type OpenShiftClient struct {
+	Version string
+	Path    string
+}
+
 type ConnectionDetails struct {
 	IP          string
 	SSHPort     int
 	SSHUsername string
 	SSHKeys     []string
 }
+
+// Address is an IP address used to connect to the instance, or from the
+// instance to the host
+type Address struct {
+	Name        string
+	IP          string
+	Description string
+}
+
+// Route is an OpenShift route, Termination is empty for the routes without
+// TLS
+type Route struct {
+	Namespace   string
+	Name        string
+	Host        string
+	Termination string
+}
+
+type LogComponent string
+
+const (
+	KubeletLogs   LogComponent = "kubelet"
+	BootstrapLogs LogComponent = "bootstrap"
+	SerialLogs    LogComponent = "serial"
+)
+
+var LogComponents = []LogComponent{KubeletLogs, BootstrapLogs, SerialLogs}
+
+func (component LogComponent) IsValid() bool {
+	for _, known := range LogComponents {
+		if component == known {
+			return true
+		}
+	}
+	return false
+}
+
+type LogsConfig struct {
+	Component LogComponent
+	// Follow keeps streaming the new lines until the context is cancelled
+	Follow bool
+}
+
+// DeleteScope is what is removed by 'crc delete' besides the instance
+type DeleteScope string
+
+const (
+	// DeleteInstance removes the virtual machine and its directory
+	DeleteInstance DeleteScope = "instance"
+	// DeleteState also removes the state kept across instances: the
+	// leftovers of the machines directory, the port forwards and the
+	// upgrade backups
+	DeleteState DeleteScope = "state"
+	// DeleteAll also removes the cached bundles and binaries, the bundle
+	// chunks and the daemon socket
+	DeleteAll DeleteScope = "all"
+)
+
+var DeleteScopes = []DeleteScope{DeleteInstance, DeleteState, DeleteAll}
+
+func (scope DeleteScope) IsValid() bool {
+	for _, known := range DeleteScopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
+
+type DeleteConfig struct {
+	Scope DeleteScope
+	// KeepBundleCache keeps the bundles of the cache with DeleteAll
+	KeepBundleCache bool
+}
+
+type StopConfig struct {
+	// Timeout is how long to wait for the graceful shutdown of the instance
+	Timeout time.Duration
+}
+
+// NetworkDiagnosis is the state of the network of the host for the instance,
+// the IP, the route and the conflicts are only known when it is running
+type NetworkDiagnosis struct {
+	NetworkMode    network.Mode
+	VPNCompat      bool
+	VPNInterfaces  []network.VPNInterface
+	Running        bool
+	IP             string
+	RouteInterface string
+	Conflicts      []network.Conflict
+}
+
+// DNSTest is the resolution of Name from the host, from the instance and
+// with the DNS server of the daemon
+type DNSTest struct {
+	Name        string
+	NetworkMode network.Mode
+	Results     []network.DNSTestResult
+}
+
+// StateRepair is the outcome of the repair of a state file of the instance
+type StateRepair struct {
+	Path   string
+	Repair statefile.Repair
+}
+
+type CompactDiskResult struct {
+	// sizes of the disk image file on the host, in bytes
+	SizeBefore int64
+	SizeAfter  int64
+}
+
+type UpgradeResult struct {
+	PreviousBundle  string
+	PreviousVersion string
+	Bundle          string
+	Version         string
+	// BackupDir holds the data of the previous instance, it is kept
+	// until the next upgrade
+	BackupDir       string
+	RestoredVolumes bool
+	RestoredClaims  int
+	// users added to the cluster, developer and kubeadmin excluded
+	RestoredUsers []string
+	StartResult   *StartResult
+}
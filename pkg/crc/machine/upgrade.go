@@ -0,0 +1,274 @@
+package machine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	crcerrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/pkg/errors"
+)
+
+// files of the backup of the instance data in constants.UpgradeBackupDir
+const (
+	volumesBackupFile           = "volumes.tar.gz"
+	htpasswdBackupFile          = "htpasswd"
+	claimsBackupFile            = "claims.json"
+	kubeAdminPasswordBackupFile = "kubeadmin-password"
+	usersBackupFile             = "users.json"
+)
+
+// Upgrade replaces the instance with one created from the bundle of
+// startConfig. The persistent volumes, the claims of the user namespaces
+// and the users added to the cluster are backed up, and restored in the
+// new instance. The pull secret and the kubeadmin password are set again
+// by the start of the new instance.
+func (client *client) Upgrade(ctx context.Context, startConfig types.StartConfig) (*types.UpgradeResult, error) {
+	exists, err := client.Exists()
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot determine if VM exists")
+	}
+	if !exists {
+		return nil, crcerrors.VMNotExist
+	}
+
+	bundlePath, ok := bundle.LocalPath(startConfig.BundlePath)
+	if !ok {
+		return nil, fmt.Errorf("%s is not downloaded", startConfig.BundlePath)
+	}
+	bundleName := bundle.GetBundleNameWithoutExtension(filepath.Base(bundlePath))
+	newBundle, err := getCrcBundleInfo(bundleName, bundlePath)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting bundle metadata")
+	}
+	if err := bundleMismatchWithPreset(startConfig.Preset, newBundle); err != nil {
+		return nil, err
+	}
+
+	vm, err := loadVirtualMachine(client.name, client.useVSock())
+	if err != nil {
+		return nil, errors.Wrap(err, "Error loading machine")
+	}
+	currentBundle := vm.bundle
+	if err := checkUpgrade(currentBundle, newBundle); err != nil {
+		vm.Close()
+		return nil, err
+	}
+	vmState, err := vm.State()
+	if err != nil {
+		vm.Close()
+		return nil, errors.Wrap(err, "Error getting the machine state")
+	}
+	if vmState != state.Running {
+		vm.Close()
+		return nil, errors.New("The instance must be running to back up its data, start it with 'crc start'")
+	}
+	sshRunner, err := vm.SSHRunner()
+	if err != nil {
+		vm.Close()
+		return nil, errors.Wrap(err, "Error creating the ssh client")
+	}
+	err = backupInstance(sshRunner, constants.UpgradeBackupDir)
+	sshRunner.Close()
+	vm.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot back up the instance data")
+	}
+
+	result := &types.UpgradeResult{
+		PreviousBundle:  currentBundle.GetBundleName(),
+		PreviousVersion: currentBundle.GetOpenshiftVersion(),
+		Bundle:          newBundle.GetBundleName(),
+		Version:         newBundle.GetOpenshiftVersion(),
+		BackupDir:       constants.UpgradeBackupDir,
+	}
+	backupErr := func(err error) error {
+		return errors.Wrapf(err, "The data of the instance is backed up in %s", constants.UpgradeBackupDir)
+	}
+
+	logging.Infof("Replacing the OpenShift %s instance with OpenShift %s...", result.PreviousVersion, result.Version)
+	if _, err := client.Stop(types.StopConfig{Timeout: DefaultStopTimeout}); err != nil {
+		logging.Warnf("Graceful shutdown failed, powering off the instance: %v", err)
+		if err := client.PowerOff(); err != nil {
+			return nil, backupErr(err)
+		}
+	}
+	if err := client.Delete(); err != nil {
+		return nil, backupErr(err)
+	}
+
+	if startConfig.KubeAdminPassword == "" && !startConfig.RotateKubeAdminPassword {
+		password, err := ioutil.ReadFile(filepath.Join(constants.UpgradeBackupDir, kubeAdminPasswordBackupFile))
+		if err == nil {
+			startConfig.KubeAdminPassword = string(password)
+		}
+	}
+	startConfig.Resume = false
+	result.StartResult, err = client.Start(ctx, startConfig)
+	if err != nil {
+		return nil, backupErr(err)
+	}
+
+	bundleInfo, sshRunner, err := loadVM(client)
+	if err != nil {
+		return nil, backupErr(err)
+	}
+	defer sshRunner.Close()
+	if err := restoreInstance(ctx, sshRunner, bundleInfo, constants.UpgradeBackupDir, result); err != nil {
+		return nil, backupErr(err)
+	}
+	return result, nil
+}
+
+// checkUpgrade only allows the OpenShift bundles of the same or of a newer
+// version, the state of a cluster cannot be kept when it is downgraded
+func checkUpgrade(current, next *bundle.CrcBundleInfo) error {
+	if current.GetBundleName() == next.GetBundleName() {
+		return fmt.Errorf("The instance already uses %s", next.GetBundleName())
+	}
+	if !current.IsOpenShift() || !next.IsOpenShift() {
+		return errors.New("Only the instances of the OpenShift preset can be upgraded")
+	}
+	if next.ClusterInfo.OpenShiftVersion.LessThan(current.ClusterInfo.OpenShiftVersion) {
+		return fmt.Errorf("Cannot downgrade the instance from OpenShift %s to OpenShift %s",
+			current.GetOpenshiftVersion(), next.GetOpenshiftVersion())
+	}
+	return nil
+}
+
+func backupInstance(sshRunner *crcssh.Runner, backupDir string) error {
+	logging.Infof("Backing up the data of the instance to %s...", backupDir)
+	if err := os.RemoveAll(backupDir); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(backupDir, 0700); err != nil {
+		return err
+	}
+	ocConfig := oc.UseOCWithSSH(sshRunner)
+
+	htpasswd, err := cluster.GetHtpasswd(ocConfig)
+	if err != nil {
+		return errors.Wrap(err, "Cannot read the users of the cluster")
+	}
+	if err := ioutil.WriteFile(filepath.Join(backupDir, htpasswdBackupFile), []byte(htpasswd), 0600); err != nil {
+		return err
+	}
+
+	claims, err := cluster.BackupClaims(ocConfig, sshRunner)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(backupDir, claimsBackupFile), data, 0600); err != nil {
+		return err
+	}
+
+	users, err := ioutil.ReadFile(constants.GetUsersPath())
+	switch {
+	case err == nil:
+		if err := ioutil.WriteFile(filepath.Join(backupDir, usersBackupFile), users, 0600); err != nil {
+			return err
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	if password, err := cluster.GetKubeadminPassword(); err == nil {
+		if err := ioutil.WriteFile(filepath.Join(backupDir, kubeAdminPasswordBackupFile), []byte(password), 0600); err != nil {
+			return err
+		}
+	}
+
+	volumesFile, err := os.OpenFile(filepath.Join(backupDir, volumesBackupFile), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer volumesFile.Close()
+	hasVolumes, err := cluster.BackupVolumes(sshRunner, volumesFile)
+	if err != nil {
+		return err
+	}
+	if err := volumesFile.Close(); err != nil {
+		return err
+	}
+	if !hasVolumes {
+		return os.Remove(volumesFile.Name())
+	}
+	return nil
+}
+
+func restoreInstance(ctx context.Context, sshRunner *crcssh.Runner, bundleInfo *bundle.CrcBundleInfo, backupDir string, result *types.UpgradeResult) error {
+	ocConfig := oc.UseOCWithSSH(sshRunner)
+
+	volumesFile, err := os.Open(filepath.Join(backupDir, volumesBackupFile))
+	switch {
+	case err == nil:
+		defer volumesFile.Close()
+		if err := cluster.RestoreVolumes(sshRunner, volumesFile); err != nil {
+			return err
+		}
+		result.RestoredVolumes = true
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	htpasswd, err := ioutil.ReadFile(filepath.Join(backupDir, htpasswdBackupFile))
+	if err != nil {
+		return err
+	}
+	if result.RestoredUsers, err = cluster.AddedUsers(string(htpasswd)); err != nil {
+		return errors.Wrap(err, "Invalid backup of the users")
+	}
+	if len(result.RestoredUsers) > 0 {
+		if err := cluster.RestoreHtpasswd(ctx, ocConfig, string(htpasswd)); err != nil {
+			return err
+		}
+	}
+
+	// the roles of the users of 'crc users' are bound again
+	users, err := ioutil.ReadFile(filepath.Join(backupDir, usersBackupFile))
+	switch {
+	case err == nil:
+		if err := ioutil.WriteFile(constants.GetUsersPath(), users, 0600); err != nil {
+			return err
+		}
+		storedUsers, err := cluster.NewUserStore(constants.GetUsersPath()).List()
+		if err != nil {
+			return err
+		}
+		if err := cluster.ApplyUsers(ctx, ocConfig, storedUsers); err != nil {
+			return err
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(backupDir, claimsBackupFile))
+	if err != nil {
+		return err
+	}
+	var claims cluster.ClaimBackup
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return errors.Wrap(err, "Invalid backup of the persistent volume claims")
+	}
+	result.RestoredClaims, err = cluster.RestoreClaims(ctx, ocConfig, sshRunner, &claims)
+	if err != nil {
+		return err
+	}
+	logging.Infof("Upgraded the instance to %s", bundleInfo.GetBundleName())
+	return nil
+}
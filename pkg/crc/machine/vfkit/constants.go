@@ -0,0 +1,13 @@
+//go:build darwin || build
+// +build darwin build
+
+package vfkit
+
+import "fmt"
+
+const (
+	VfkitCommand = "vfkit"
+	VfkitVersion = "0.0.2"
+)
+
+var VfkitDownloadURL = fmt.Sprintf("https://github.com/code-ready/vfkit/releases/download/v%s/%s", VfkitVersion, VfkitCommand)
@@ -0,0 +1,23 @@
+package vfkit
+
+import (
+	"path/filepath"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine/config"
+	"github.com/code-ready/crc/pkg/drivers/vfkit"
+)
+
+func CreateHost(machineConfig config.MachineConfig) *vfkit.Driver {
+	vfkitDriver := vfkit.NewDriver(machineConfig.Name, constants.MachineBaseDir)
+
+	config.InitVMDriverFromMachineConfig(machineConfig, vfkitDriver.VMDriver)
+
+	vfkitDriver.VfkitPath = filepath.Join(constants.BinDir(), VfkitCommand)
+	vfkitDriver.Cmdline = machineConfig.KernelCmdLine
+	vfkitDriver.VmlinuzPath = machineConfig.Kernel
+	vfkitDriver.InitrdPath = machineConfig.Initramfs
+	vfkitDriver.VsockPath = constants.TapSocketPath
+
+	return vfkitDriver
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 
 	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine/bundle"
 	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/crc/ssh"
 	"github.com/code-ready/crc/pkg/libmachine"
 	libmachinehost "github.com/code-ready/crc/pkg/libmachine/host"
@@ -52,6 +54,12 @@ func loadVirtualMachine(name string, useVSock bool) (*virtualMachine, error) {
 	crcBundleMetadata, err := getBundleMetadataFromDriver(libmachineHost.Driver)
 	if err != nil {
 		err = errInvalidBundleMetadata
+	} else {
+		clusterDomain, domainErr := loadClusterDomain(name)
+		if domainErr != nil {
+			return nil, errors.Wrap(domainErr, "Cannot read the cluster domain")
+		}
+		crcBundleMetadata.SetClusterDomain(clusterDomain)
 	}
 
 	return &virtualMachine{
@@ -63,6 +71,25 @@ func loadVirtualMachine(name string, useVSock bool) (*virtualMachine, error) {
 	}, err
 }
 
+// InstanceBundleName returns the name of the bundle used by the instance, or
+// an empty string when the instance does not exist
+func InstanceBundleName(name string) (string, error) {
+	apiClient := libmachine.NewClient(constants.MachineBaseDir)
+	defer apiClient.Close()
+	exists, err := apiClient.Exists(name)
+	if err != nil {
+		return "", errors.Wrap(err, "Cannot check if machine exists")
+	}
+	if !exists {
+		return "", nil
+	}
+	libmachineHost, err := apiClient.Load(name)
+	if err != nil {
+		return "", errors.Wrap(err, "Cannot load machine")
+	}
+	return libmachineHost.Driver.GetBundleName()
+}
+
 func (vm *virtualMachine) Close() error {
 	return vm.api.Close()
 }
@@ -96,7 +123,12 @@ func (vm *virtualMachine) IP() (string, error) {
 
 func (vm *virtualMachine) SSHPort() int {
 	if vm.vsock {
-		return constants.VsockSSHPort
+		hostPorts, err := loadHostPorts(vm.name)
+		if err != nil {
+			logging.Debugf("Cannot read the host ports of the instance: %v", err)
+			return network.SSHPort
+		}
+		return hostPorts.SSH
 	}
 	return constants.DefaultSSHPort
 }
@@ -11,24 +11,47 @@ import (
 	"github.com/code-ready/crc/pkg/crc/daemonclient"
 	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
 	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/network"
 	crcPreset "github.com/code-ready/crc/pkg/crc/preset"
 	"github.com/containers/gvisor-tap-vsock/pkg/types"
 	"github.com/pkg/errors"
 )
 
-func exposePorts(preset crcPreset.Preset) error {
-	portsToExpose := vsockPorts(preset)
+// exposePorts forwards the ports of the instance from the host, the ports of
+// the cluster from hostPorts. It fails when other processes use the host
+// ports, unless alternativeHostPorts is true, then the ports of the cluster
+// are forwarded from other host ports.
+func exposePorts(name string, preset crcPreset.Preset, hostPorts network.HostPorts, alternativeHostPorts bool) error {
+	loopbackAddresses := network.LoopbackAddresses()
 	daemonClient := daemonclient.New()
 	alreadyOpenedPorts, err := listOpenPorts(daemonClient)
 	if err != nil {
 		return err
 	}
+	if preset == crcPreset.OpenShift {
+		if hostPorts, err = selectHostPorts(name, hostPorts, alreadyOpenedPorts, loopbackAddresses, alternativeHostPorts); err != nil {
+			return err
+		}
+	} else if err := saveSSHHostPort(name, hostPorts.SSH); err != nil {
+		return err
+	}
+	portsToExpose := vsockPorts(preset, loopbackAddresses, hostPorts)
+	portForwards, err := network.NewPortForwardStore(constants.PortForwardsPath).List()
+	if err != nil {
+		return errors.Wrap(err, "failed to load the port forwardings")
+	}
+	for _, portForward := range portForwards {
+		portsToExpose = append(portsToExpose, portForward.ExposeRequests(virtualMachineIP, loopbackAddresses)...)
+	}
 	var missingPorts []types.ExposeRequest
 	for _, port := range portsToExpose {
 		if !isOpened(alreadyOpenedPorts, port) {
 			missingPorts = append(missingPorts, port)
 		}
 	}
+	if err := checkPortConflicts(missingPorts); err != nil {
+		return err
+	}
 	for i := range missingPorts {
 		port := &missingPorts[i]
 		if err := daemonClient.NetworkClient.Expose(port); err != nil {
@@ -68,46 +91,49 @@ func unexposePorts() error {
 func listOpenPorts(daemonClient *daemonclient.Client) ([]types.ExposeRequest, error) {
 	alreadyOpenedPorts, err := daemonClient.NetworkClient.List()
 	if err != nil {
-		logging.Error("Is 'crc daemon' running? Network mode 'vsock' requires 'crc daemon' to be running, run it manually on different terminal/tab")
+		logging.Error("Is 'crc daemon' running? Network mode 'vsock' requires 'crc daemon' to be running, run 'crc daemon install' to start it automatically")
 		return nil, err
 	}
 	return alreadyOpenedPorts, nil
 }
 
 const (
-	virtualMachineIP = "192.168.127.2"
+	virtualMachineIP = constants.VSockVirtualMachineIP
 	internalSSHPort  = "22"
-	localIP          = "127.0.0.1"
 	httpPort         = "80"
 	httpsPort        = "443"
 	apiPort          = "6443"
 	cockpitPort      = "9090"
 )
 
-func vsockPorts(preset crcPreset.Preset) []types.ExposeRequest {
-	exposeRequest := []types.ExposeRequest{
-		{
+// loopbackForwards forwards port on all the host loopback addresses to
+// remotePort in the instance
+func loopbackForwards(port, remotePort string, loopbackAddresses []string) []types.ExposeRequest {
+	var exposeRequests []types.ExposeRequest
+	for _, address := range loopbackAddresses {
+		exposeRequests = append(exposeRequests, types.ExposeRequest{
 			Protocol: "tcp",
-			Local:    net.JoinHostPort(localIP, strconv.Itoa(constants.VsockSSHPort)),
-			Remote:   net.JoinHostPort(virtualMachineIP, internalSSHPort),
-		},
+			Local:    net.JoinHostPort(address, port),
+			Remote:   net.JoinHostPort(virtualMachineIP, remotePort),
+		})
 	}
+	return exposeRequests
+}
+
+func vsockPorts(preset crcPreset.Preset, loopbackAddresses []string, hostPorts network.HostPorts) []types.ExposeRequest {
+	exposeRequest := loopbackForwards(strconv.Itoa(hostPorts.SSH), internalSSHPort, loopbackAddresses)
 	switch preset {
 	case crcPreset.OpenShift:
+		exposeRequest = append(exposeRequest, loopbackForwards(strconv.Itoa(hostPorts.API), apiPort, loopbackAddresses)...)
 		exposeRequest = append(exposeRequest,
 			types.ExposeRequest{
 				Protocol: "tcp",
-				Local:    net.JoinHostPort(localIP, apiPort),
-				Remote:   net.JoinHostPort(virtualMachineIP, apiPort),
-			},
-			types.ExposeRequest{
-				Protocol: "tcp",
-				Local:    fmt.Sprintf(":%s", httpsPort),
+				Local:    fmt.Sprintf(":%d", hostPorts.HTTPS),
 				Remote:   net.JoinHostPort(virtualMachineIP, httpsPort),
 			},
 			types.ExposeRequest{
 				Protocol: "tcp",
-				Local:    fmt.Sprintf(":%s", httpPort),
+				Local:    fmt.Sprintf(":%d", hostPorts.HTTP),
 				Remote:   net.JoinHostPort(virtualMachineIP, httpPort),
 			})
 	case crcPreset.Podman:
@@ -117,12 +143,8 @@ func vsockPorts(preset crcPreset.Preset) []types.ExposeRequest {
 			socketProtocol = types.NPIPE
 			socketLocal = constants.DefaultPodmanNamedPipe
 		}
+		exposeRequest = append(exposeRequest, loopbackForwards(cockpitPort, cockpitPort, loopbackAddresses)...)
 		exposeRequest = append(exposeRequest,
-			types.ExposeRequest{
-				Protocol: "tcp",
-				Local:    net.JoinHostPort(localIP, cockpitPort),
-				Remote:   net.JoinHostPort(virtualMachineIP, cockpitPort),
-			},
 			types.ExposeRequest{
 				Protocol: socketProtocol,
 				Local:    socketLocal,
@@ -0,0 +1,386 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/config"
+	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/code-ready/crc/pkg/crc/services"
+	"github.com/code-ready/crc/pkg/crc/services/dns"
+	"github.com/code-ready/crc/pkg/crc/systemd"
+	"github.com/pkg/errors"
+)
+
+func workerName(name string, index int) string {
+	return fmt.Sprintf("%s-worker-%d", name, index)
+}
+
+// existingWorkers returns the names of the worker instances, whatever the
+// value of the workers setting
+func (client *client) existingWorkers() ([]string, error) {
+	api, cleanup := createLibMachineClient()
+	defer cleanup()
+
+	var workers []string
+	for i := 1; i <= constants.MaxWorkers; i++ {
+		name := workerName(client.name, i)
+		exists, err := api.Exists(name)
+		if err != nil {
+			return nil, fmt.Errorf("Error checking if the host exists: %s", err)
+		}
+		if exists {
+			workers = append(workers, name)
+		}
+	}
+	return workers, nil
+}
+
+// selectWorkers returns the names of the count worker instances to start and
+// the names of the existing instances which are no longer requested. The
+// existing instances are kept first, the new ones use the free indexes.
+func selectWorkers(name string, existing []string, count int) ([]string, []string) {
+	var keep, remove []string
+	for _, worker := range existing {
+		if len(keep) < count {
+			keep = append(keep, worker)
+		} else {
+			remove = append(remove, worker)
+		}
+	}
+	for i := 1; len(keep) < count; i++ {
+		if worker := workerName(name, i); !contains(existing, worker) {
+			keep = append(keep, worker)
+		}
+	}
+	return keep, remove
+}
+
+// startWorkers creates and starts the worker nodes, and joins them to the
+// cluster running in the control plane instance. The workers which are no
+// longer requested are removed.
+func (client *client) startWorkers(ctx context.Context, startConfig types.StartConfig, controlPlane *virtualMachine, ocConfig oc.Config, serviceConfig services.ServicePostStartConfig) error {
+	if err := client.removeWorkers(startConfig.Workers, ocConfig); err != nil {
+		return err
+	}
+	if startConfig.Workers == 0 {
+		return nil
+	}
+	if client.useVSock() {
+		return fmt.Errorf("Worker nodes are only supported with the %s network mode", network.SystemNetworkingMode)
+	}
+
+	existing, err := client.existingWorkers()
+	if err != nil {
+		return err
+	}
+	names, _ := selectWorkers(client.name, existing, startConfig.Workers)
+	var workers []cluster.WorkerNode
+	for _, name := range names {
+		worker, err := client.startWorker(ctx, name, startConfig.Worker, controlPlane, serviceConfig.IP)
+		if err != nil {
+			return errors.Wrapf(err, "Cannot start worker node %s", name)
+		}
+		workers = append(workers, *worker)
+	}
+
+	serviceConfig.Workers = workers
+	if err := dns.UpdateWorkerRecords(serviceConfig); err != nil {
+		return errors.Wrap(err, "Cannot add the worker nodes to the DNS server")
+	}
+
+	for _, worker := range workers {
+		logging.Infof("Waiting for worker node %s to join the cluster...", worker.Hostname)
+		if err := cluster.JoinWorker(ctx, ocConfig, worker.Hostname); err != nil {
+			return errors.Wrapf(err, "Worker node %s did not join the cluster", worker.Hostname)
+		}
+	}
+	return nil
+}
+
+func (client *client) startWorker(ctx context.Context, name string, workerConfig types.WorkerConfig, controlPlane *virtualMachine, controlPlaneIP string) (*cluster.WorkerNode, error) {
+	api, cleanup := createLibMachineClient()
+	defer cleanup()
+
+	exists, err := api.Exists(name)
+	if err != nil {
+		return nil, fmt.Errorf("Error checking if the host exists: %s", err)
+	}
+	if !exists {
+		logging.Infof("Creating worker node %s...", name)
+		bundleInfo := controlPlane.bundle
+		machineConfig := config.MachineConfig{
+			Name:            name,
+			BundleName:      bundleInfo.GetBundleName(),
+			CPUs:            workerConfig.CPUs,
+			Memory:          workerConfig.Memory,
+			DiskSize:        constants.DefaultDiskSize,
+			NetworkMode:     client.networkMode(),
+			ImageSourcePath: bundleInfo.GetDiskImagePath(),
+			ImageFormat:     bundleInfo.GetDiskImageFormat(),
+			SSHKeyPath:      bundleInfo.GetSSHKeyPath(),
+			KernelCmdLine:   bundleInfo.GetKernelCommandLine(),
+			Initramfs:       bundleInfo.GetInitramfsPath(),
+			Kernel:          bundleInfo.GetKernelPath(),
+		}
+		host, err := createVirtualMachine(api, machineConfig)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error creating machine")
+		}
+		if err := api.SetExists(host.Name); err != nil {
+			return nil, fmt.Errorf("Failed to record VM existence: %s", err)
+		}
+	}
+
+	vm, err := loadVirtualMachine(name, false)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error loading machine")
+	}
+	defer vm.Close()
+
+	vmState, err := vm.State()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting the machine state")
+	}
+	if vmState != state.Running {
+		logging.Infof("Starting worker node %s...", name)
+		if err := startHost(ctx, vm, client.timeout(crcConfig.VMBootTimeout, defaultVMBootTimeout)); err != nil {
+			return nil, errors.Wrap(err, "Error starting machine")
+		}
+	}
+
+	ip, err := vm.IP()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting the IP")
+	}
+	sshRunner, err := vm.SSHRunner()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating the ssh client")
+	}
+	defer sshRunner.Close()
+
+	if err := sshRunner.WaitForConnectivity(ctx, client.timeout(crcConfig.SSHTimeout, defaultSSHTimeout)); err != nil {
+		return nil, errors.Wrap(err, "Failed to connect to the worker node")
+	}
+	if err := updateSSHKeyPair(sshRunner); err != nil {
+		return nil, errors.Wrap(err, "Error updating public key")
+	}
+
+	// the cluster names are resolved by the DNS server of the control plane
+	resolvFileValues := network.ResolvFileValues{
+		SearchDomains: []network.SearchDomain{
+			{
+				Domain: fmt.Sprintf("%s.%s", client.name, controlPlane.bundle.ClusterInfo.BaseDomain),
+			},
+		},
+		NameServers: []network.NameServer{
+			{
+				IPAddress: controlPlaneIP,
+			},
+		},
+	}
+	if err := network.CreateResolvFileOnInstance(sshRunner, resolvFileValues); err != nil {
+		return nil, err
+	}
+
+	worker := cluster.WorkerNode{
+		Hostname:               name,
+		IP:                     ip,
+		ControlPlaneIP:         controlPlaneIP,
+		ControlPlaneInternalIP: controlPlane.bundle.Nodes[0].InternalIP,
+	}
+	if err := cluster.ConvertToWorker(sshRunner, worker); err != nil {
+		return nil, err
+	}
+	if err := systemd.NewInstanceSystemdCommander(sshRunner).Start("kubelet"); err != nil {
+		return nil, errors.Wrap(err, "Error starting kubelet")
+	}
+	return &worker, nil
+}
+
+// removeWorkers keeps count worker nodes, the other ones are removed from the
+// cluster and their instance is deleted
+func (client *client) removeWorkers(count int, ocConfig oc.Config) error {
+	existing, err := client.existingWorkers()
+	if err != nil {
+		return err
+	}
+	_, names := selectWorkers(client.name, existing, count)
+	for _, name := range names {
+		if err := removeWorker(name, &ocConfig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeWorker deletes the instance of a worker node, the node is removed
+// from the cluster when ocConfig is not nil
+func removeWorker(name string, ocConfig *oc.Config) error {
+	vm, err := loadVirtualMachine(name, false)
+	var missingHost *MissingHostError
+	if errors.As(err, &missingHost) {
+		return nil
+	}
+	if err != nil && !errors.Is(err, errInvalidBundleMetadata) {
+		return errors.Wrap(err, "Cannot load machine")
+	}
+	defer vm.Close()
+	logging.Infof("Removing worker node %s...", name)
+	if ocConfig != nil {
+		if err := cluster.DeleteNode(*ocConfig, name); err != nil {
+			logging.Warnf("Cannot remove worker node %s from the cluster: %v", name, err)
+		}
+	}
+	if err := vm.Remove(); err != nil {
+		return errors.Wrapf(err, "Cannot remove worker node %s", name)
+	}
+	return nil
+}
+
+// stopWorkers stops the worker instances, errors are logged as the control
+// plane instance must be stopped anyway
+func (client *client) stopWorkers() {
+	workers, err := client.existingWorkers()
+	if err != nil {
+		logging.Warnf("Cannot list the worker nodes: %v", err)
+		return
+	}
+	for _, name := range workers {
+		if err := stopWorker(name); err != nil {
+			logging.Warnf("Cannot stop worker node %s: %v", name, err)
+		}
+	}
+}
+
+func stopWorker(name string) error {
+	vm, err := loadVirtualMachine(name, false)
+	if err != nil {
+		return errors.Wrap(err, "Cannot load machine")
+	}
+	defer vm.Close()
+
+	vmState, err := vm.State()
+	if err != nil {
+		return errors.Wrap(err, "Cannot get VM status")
+	}
+	if vmState != state.Running {
+		return nil
+	}
+	logging.Infof("Stopping worker node %s...", name)
+	if err := stopAllContainers(vm); err != nil {
+		logging.Debugf("Failed to stop all containers of %s: %v", name, err)
+	}
+	return vm.Stop()
+}
+
+// killWorkers powers off the worker instances
+func (client *client) killWorkers() {
+	workers, err := client.existingWorkers()
+	if err != nil {
+		logging.Warnf("Cannot list the worker nodes: %v", err)
+		return
+	}
+	for _, name := range workers {
+		vm, err := loadVirtualMachine(name, false)
+		if err != nil {
+			logging.Warnf("Cannot load worker node %s: %v", name, err)
+			continue
+		}
+		if err := vm.Kill(); err != nil {
+			logging.Warnf("Cannot kill worker node %s: %v", name, err)
+		}
+		vm.Close()
+	}
+}
+
+// deleteWorkers removes all the worker instances
+func (client *client) deleteWorkers() error {
+	workers, err := client.existingWorkers()
+	if err != nil {
+		return err
+	}
+	for _, name := range workers {
+		vm, err := loadVirtualMachine(name, false)
+		if err != nil && !errors.Is(err, errInvalidBundleMetadata) {
+			return errors.Wrap(err, "Cannot load machine")
+		}
+		err = vm.Remove()
+		vm.Close()
+		if err != nil {
+			return errors.Wrapf(err, "Cannot remove worker node %s", name)
+		}
+	}
+	return nil
+}
+
+// workersStatus returns the state of the worker instances. When the control
+// plane instance is given, the readiness of the nodes is read from the
+// cluster.
+func (client *client) workersStatus(controlPlane *virtualMachine) []types.WorkerStatus {
+	workers, err := client.existingWorkers()
+	if err != nil {
+		logging.Debugf("Cannot list the worker nodes: %v", err)
+		return nil
+	}
+	if len(workers) == 0 {
+		return nil
+	}
+
+	var nodesReady map[string]bool
+	if controlPlane != nil {
+		nodesReady = getNodesReady(controlPlane)
+	}
+	var statuses []types.WorkerStatus
+	for _, name := range workers {
+		status := types.WorkerStatus{
+			Name:  name,
+			State: state.Error,
+			Ready: nodesReady[name],
+		}
+		vm, err := loadVirtualMachine(name, false)
+		if err != nil {
+			logging.Debugf("Cannot load worker node %s: %v", name, err)
+			statuses = append(statuses, status)
+			continue
+		}
+		if vmState, err := vm.State(); err != nil {
+			logging.Debugf("Cannot get the state of worker node %s: %v", name, err)
+		} else {
+			status.State = vmState
+		}
+		if status.State == state.Running {
+			if ip, err := vm.IP(); err == nil {
+				status.IP = ip
+			}
+		}
+		if cpus, memory, err := getVMSize(vm.Host); err == nil {
+			status.CPUs, status.Memory = cpus, memory
+		}
+		vm.Close()
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+func getNodesReady(controlPlane *virtualMachine) map[string]bool {
+	sshRunner, err := controlPlane.SSHRunner()
+	if err != nil {
+		logging.Debugf("Error creating the ssh client: %v", err)
+		return nil
+	}
+	defer sshRunner.Close()
+	ready, err := cluster.GetNodesReady(oc.UseOCWithSSH(sshRunner).WithFailFast())
+	if err != nil {
+		logging.Debugf("Cannot get the readiness of the nodes: %v", err)
+		return nil
+	}
+	return ready
+}
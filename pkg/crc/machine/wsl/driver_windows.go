@@ -0,0 +1,15 @@
+package wsl
+
+import (
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine/config"
+	"github.com/code-ready/crc/pkg/drivers/wsl"
+)
+
+func CreateHost(machineConfig config.MachineConfig) *wsl.Driver {
+	wslDriver := wsl.NewDriver(machineConfig.Name, constants.MachineBaseDir)
+
+	config.InitVMDriverFromMachineConfig(machineConfig, wslDriver.VMDriver)
+
+	return wslDriver
+}
@@ -0,0 +1,227 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
+	"github.com/miekg/dns"
+)
+
+const forwardTimeout = 5 * time.Second
+
+// DNSForwarder answers the queries for the crc zones, and forwards the other
+// ones to the configured nameservers, which can use DNS-over-TLS or
+// DNS-over-HTTPS. When no nameserver is configured, the host resolver is used.
+type DNSForwarder struct {
+	Zones []types.Zone
+	// Upstreams returns the nameservers to use, it is called for each query so
+	// that configuration changes are applied without restarting the daemon
+	Upstreams func() []Upstream
+	Transport http.RoundTripper
+}
+
+func (f *DNSForwarder) ServeDNS(w dns.ResponseWriter, query *dns.Msg) {
+	reply := f.reply(query)
+	if err := w.WriteMsg(reply); err != nil {
+		logging.Debugf("Cannot write DNS reply: %v", err)
+	}
+}
+
+func (f *DNSForwarder) reply(query *dns.Msg) *dns.Msg {
+	if len(query.Question) == 1 {
+		if reply, ok := zoneReply(f.Zones, query); ok {
+			return reply
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), forwardTimeout)
+	defer cancel()
+	upstreams := f.Upstreams()
+	if len(upstreams) == 0 {
+		return hostResolverReply(ctx, query)
+	}
+	for _, upstream := range upstreams {
+		reply, err := upstream.Exchange(ctx, query, f.Transport)
+		if err == nil {
+			return reply
+		}
+		logging.Debugf("DNS query to %s failed: %v", upstream, err)
+	}
+	reply := new(dns.Msg)
+	reply.SetRcode(query, dns.RcodeServerFailure)
+	return reply
+}
+
+// zoneReply answers the queries for the crc zones, in the same way as the
+// DNS server of the virtual network gateway
+func zoneReply(zones []types.Zone, query *dns.Msg) (*dns.Msg, bool) {
+	question := query.Question[0]
+	for _, zone := range zones {
+		zoneSuffix := fmt.Sprintf(".%s", zone.Name)
+		if !strings.HasSuffix(question.Name, zoneSuffix) {
+			continue
+		}
+		reply := new(dns.Msg)
+		reply.SetReply(query)
+		reply.RecursionAvailable = true
+		if question.Qtype != dns.TypeA {
+			return reply, true
+		}
+		name := strings.TrimSuffix(question.Name, zoneSuffix)
+		ip := zone.DefaultIP
+		for _, record := range zone.Records {
+			if (record.Name != "" && record.Name == name) ||
+				(record.Regexp != nil && record.Regexp.MatchString(name)) {
+				ip = record.IP
+				break
+			}
+		}
+		if ip == nil {
+			reply.Rcode = dns.RcodeNameError
+			return reply, true
+		}
+		reply.Answer = append(reply.Answer, aRecord(question.Name, ip))
+		return reply, true
+	}
+	return nil, false
+}
+
+// hostResolverReply answers the query with the resolver of the host. A name
+// which doesn't exist gets NXDOMAIN, a name without records of the type of the
+// query gets an empty answer, and the other errors get SERVFAIL.
+func hostResolverReply(ctx context.Context, query *dns.Msg) *dns.Msg {
+	reply := new(dns.Msg)
+	reply.SetReply(query)
+	reply.RecursionAvailable = true
+	for _, question := range query.Question {
+		answers, err := hostResolverAnswers(ctx, question)
+		if err != nil {
+			reply.Rcode = hostResolverRcode(ctx, question, err)
+			return reply
+		}
+		reply.Answer = append(reply.Answer, answers...)
+	}
+	return reply
+}
+
+// hostResolverAnswers looks up the records which the resolver of the host
+// gives access to, the other types get an empty answer
+func hostResolverAnswers(ctx context.Context, question dns.Question) ([]dns.RR, error) {
+	resolver := net.DefaultResolver
+	var answers []dns.RR
+	switch question.Qtype {
+	case dns.TypeA, dns.TypeAAAA:
+		ips, err := resolver.LookupIPAddr(ctx, question.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			switch {
+			case question.Qtype == dns.TypeA && ip.IP.To4() != nil:
+				answers = append(answers, aRecord(question.Name, ip.IP.To4()))
+			case question.Qtype == dns.TypeAAAA && ip.IP.To4() == nil:
+				answers = append(answers, aaaaRecord(question.Name, ip.IP))
+			}
+		}
+	case dns.TypeCNAME:
+		cname, err := resolver.LookupCNAME(ctx, question.Name)
+		if err != nil {
+			return nil, err
+		}
+		// the name itself is returned when it is not an alias
+		if !strings.EqualFold(dns.Fqdn(cname), question.Name) {
+			answers = append(answers, &dns.CNAME{Hdr: rrHeader(question.Name, dns.TypeCNAME), Target: dns.Fqdn(cname)})
+		}
+	case dns.TypeMX:
+		records, err := resolver.LookupMX(ctx, question.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range records {
+			answers = append(answers, &dns.MX{Hdr: rrHeader(question.Name, dns.TypeMX), Preference: mx.Pref, Mx: dns.Fqdn(mx.Host)})
+		}
+	case dns.TypeNS:
+		records, err := resolver.LookupNS(ctx, question.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range records {
+			answers = append(answers, &dns.NS{Hdr: rrHeader(question.Name, dns.TypeNS), Ns: dns.Fqdn(ns.Host)})
+		}
+	case dns.TypeSRV:
+		_, records, err := resolver.LookupSRV(ctx, "", "", question.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, srv := range records {
+			answers = append(answers, &dns.SRV{
+				Hdr:      rrHeader(question.Name, dns.TypeSRV),
+				Priority: srv.Priority,
+				Weight:   srv.Weight,
+				Port:     srv.Port,
+				Target:   dns.Fqdn(srv.Target),
+			})
+		}
+	}
+	return answers, nil
+}
+
+// hostResolverRcode returns the response code of a failed lookup. The
+// resolver of the host reports a name without records of the type of the
+// query as not found, it is NXDOMAIN only when the name has no address.
+func hostResolverRcode(ctx context.Context, question dns.Question, err error) int {
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		return dns.RcodeServerFailure
+	}
+	if question.Qtype == dns.TypeA || question.Qtype == dns.TypeAAAA {
+		return dns.RcodeNameError
+	}
+	if _, err := net.DefaultResolver.LookupIPAddr(ctx, question.Name); err != nil {
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return dns.RcodeNameError
+		}
+		return dns.RcodeServerFailure
+	}
+	return dns.RcodeSuccess
+}
+
+func rrHeader(name string, rrtype uint16) dns.RR_Header {
+	return dns.RR_Header{
+		Name:   name,
+		Rrtype: rrtype,
+		Class:  dns.ClassINET,
+		Ttl:    0,
+	}
+}
+
+func aRecord(name string, ip net.IP) dns.RR {
+	return &dns.A{
+		Hdr: dns.RR_Header{
+			Name:   name,
+			Rrtype: dns.TypeA,
+			Class:  dns.ClassINET,
+			Ttl:    0,
+		},
+		A: ip,
+	}
+}
+
+func aaaaRecord(name string, ip net.IP) dns.RR {
+	return &dns.AAAA{
+		Hdr: dns.RR_Header{
+			Name:   name,
+			Rrtype: dns.TypeAAAA,
+			Class:  dns.ClassINET,
+			Ttl:    0,
+		},
+		AAAA: ip,
+	}
+}
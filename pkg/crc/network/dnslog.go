@@ -0,0 +1,65 @@
+package network
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/miekg/dns"
+)
+
+// QueryLogger logs the queries answered by Handler and their replies, when
+// Enabled returns true. Enabled is called for each query so that the logging
+// can be turned on and off without restarting the daemon.
+type QueryLogger struct {
+	Server  string
+	Handler dns.Handler
+	Enabled func() bool
+}
+
+func (l *QueryLogger) ServeDNS(w dns.ResponseWriter, query *dns.Msg) {
+	if !l.Enabled() {
+		l.Handler.ServeDNS(w, query)
+		return
+	}
+	recorder := &replyRecorder{ResponseWriter: w}
+	start := time.Now()
+	l.Handler.ServeDNS(recorder, query)
+	logging.Infof("DNS %s: %s from %s (%s)", l.Server, formatQuery(query, recorder.reply), w.RemoteAddr(), time.Since(start).Round(time.Millisecond))
+}
+
+// replyRecorder keeps the reply written by a dns.Handler
+type replyRecorder struct {
+	dns.ResponseWriter
+	reply *dns.Msg
+}
+
+func (r *replyRecorder) WriteMsg(reply *dns.Msg) error {
+	r.reply = reply
+	return r.ResponseWriter.WriteMsg(reply)
+}
+
+// formatQuery describes a query and its reply on one line, like
+// 'A console.apps-crc.testing. -> NOERROR 127.0.0.1'
+func formatQuery(query *dns.Msg, reply *dns.Msg) string {
+	var questions []string
+	for _, question := range query.Question {
+		questions = append(questions, fmt.Sprintf("%s %s", dns.TypeToString[question.Qtype], question.Name))
+	}
+	if reply == nil {
+		return fmt.Sprintf("%s -> no reply", strings.Join(questions, ", "))
+	}
+	answers := []string{dns.RcodeToString[reply.Rcode]}
+	for _, answer := range reply.Answer {
+		switch rr := answer.(type) {
+		case *dns.A:
+			answers = append(answers, rr.A.String())
+		case *dns.AAAA:
+			answers = append(answers, rr.AAAA.String())
+		case *dns.CNAME:
+			answers = append(answers, rr.Target)
+		}
+	}
+	return fmt.Sprintf("%s -> %s", strings.Join(questions, ", "), strings.Join(answers, " "))
+}
@@ -0,0 +1,153 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/miekg/dns"
+)
+
+// DNSPath is one of the ways used to resolve the names of the cluster
+type DNSPath string
+
+const (
+	// HostDNSPath is the resolver of the operating system of the host
+	HostDNSPath DNSPath = "host"
+	// InstanceDNSPath is the resolver of the instance
+	InstanceDNSPath DNSPath = "instance"
+	// ForwarderDNSPath is the DNS server embedded in the daemon, its resolver
+	// for the host answers the names of the cluster, its forwarder sends the
+	// other names of the instance to the configured nameservers
+	ForwarderDNSPath DNSPath = "forwarder"
+)
+
+// DNSTestResult is the outcome of the resolution of a name with one path,
+// Error is the reason why the path was skipped when Skipped is true
+type DNSTestResult struct {
+	Path      DNSPath  `json:"path"`
+	Server    string   `json:"server,omitempty"`
+	Addresses []string `json:"addresses,omitempty"`
+	Skipped   bool     `json:"skipped,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+func (r DNSTestResult) Failed() bool {
+	return !r.Skipped && r.Error != ""
+}
+
+func newDNSTestResult(path DNSPath, server string, addresses []string, err error) DNSTestResult {
+	result := DNSTestResult{Path: path, Server: server, Addresses: addresses}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func skippedDNSTestResult(path DNSPath, reason string) DNSTestResult {
+	return DNSTestResult{Path: path, Skipped: true, Error: reason}
+}
+
+// ResolveFromHost resolves name with the resolver of the operating system
+func ResolveFromHost(name string) DNSTestResult {
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+	addresses, err := net.DefaultResolver.LookupHost(ctx, name)
+	return newDNSTestResult(HostDNSPath, "", addresses, err)
+}
+
+// ResolveInInstance resolves name with the resolver of the instance, which
+// uses the nameservers of its resolv.conf
+func ResolveInInstance(sshRunner *ssh.Runner, name string) DNSTestResult {
+	// getent exits with 2 when the name is not found
+	stdout, _, err := sshRunner.Run("getent", "ahostsv4", name)
+	if err != nil {
+		return newDNSTestResult(InstanceDNSPath, "", nil, fmt.Errorf("Cannot resolve %s in the instance: %w", name, err))
+	}
+	return newDNSTestResult(InstanceDNSPath, "", parseGetentHosts(stdout), nil)
+}
+
+// parseGetentHosts returns the addresses listed by 'getent ahostsv4', which
+// prints each address once per socket type
+func parseGetentHosts(output string) []string {
+	var addresses []string
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || contains(addresses, fields[0]) {
+			continue
+		}
+		addresses = append(addresses, fields[0])
+	}
+	return addresses
+}
+
+// ResolveWithForwarder resolves name with the DNS server embedded in the
+// daemon. The names of the cluster are sent to its resolver for the host, the
+// other names go through its forwarder, which only the instance can reach, so
+// the nameservers are queried in the same way by this process.
+func ResolveWithForwarder(mode Mode, name string, clusterDomain string, upstreams []Upstream) DNSTestResult {
+	query := new(dns.Msg)
+	query.SetQuestion(dns.Fqdn(name), dns.TypeA)
+
+	if inDomains(name, HostResolverDomains(clusterDomain)) {
+		address := net.JoinHostPort("127.0.0.1", strconv.Itoa(constants.HostDNSResolverPort))
+		client := &dns.Client{Timeout: resolveTimeout}
+		reply, _, err := client.Exchange(query, address)
+		if err != nil {
+			return newDNSTestResult(ForwarderDNSPath, address, nil, fmt.Errorf("Cannot query the DNS resolver of the daemon, is the daemon running? %w", err))
+		}
+		addresses, err := replyAddresses(reply)
+		return newDNSTestResult(ForwarderDNSPath, address, addresses, err)
+	}
+
+	if mode != UserNetworkingMode {
+		return skippedDNSTestResult(ForwarderDNSPath, fmt.Sprintf("The instance only uses the DNS forwarder in the %s network mode", UserNetworkingMode))
+	}
+	forwarder := &DNSForwarder{
+		Upstreams: func() []Upstream { return upstreams },
+		Transport: HTTPTransport(),
+	}
+	server := "host resolver"
+	if len(upstreams) > 0 {
+		var servers []string
+		for _, upstream := range upstreams {
+			servers = append(servers, upstream.String())
+		}
+		server = strings.Join(servers, ", ")
+	}
+	addresses, err := replyAddresses(forwarder.reply(query))
+	return newDNSTestResult(ForwarderDNSPath, server, addresses, err)
+}
+
+func inDomains(name string, domains []string) bool {
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	for _, domain := range domains {
+		if name == domain || strings.HasSuffix(name, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// replyAddresses returns the IPv4 addresses of a DNS reply, or an error when
+// the server failed or has no address for the name
+func replyAddresses(reply *dns.Msg) ([]string, error) {
+	if reply.Rcode != dns.RcodeSuccess {
+		return nil, fmt.Errorf("The DNS server answered %s", dns.RcodeToString[reply.Rcode])
+	}
+	var addresses []string
+	for _, answer := range reply.Answer {
+		if a, ok := answer.(*dns.A); ok {
+			addresses = append(addresses, a.A.String())
+		}
+	}
+	if len(addresses) == 0 {
+		return nil, errors.New("The DNS server has no IPv4 address for this name")
+	}
+	return addresses, nil
+}
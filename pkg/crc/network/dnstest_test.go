@@ -0,0 +1,71 @@
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGetentHosts(t *testing.T) {
+	output := `192.168.130.11  STREAM api.crc.testing
+192.168.130.11  DGRAM
+192.168.130.11  RAW
+10.0.0.2        STREAM
+10.0.0.2        DGRAM
+`
+	assert.Equal(t, []string{"192.168.130.11", "10.0.0.2"}, parseGetentHosts(output))
+	assert.Nil(t, parseGetentHosts(""))
+}
+
+func TestInDomains(t *testing.T) {
+	domains := HostResolverDomains("example.org")
+	assert.True(t, inDomains("api.crc.testing", domains))
+	assert.True(t, inDomains("console-openshift-console.apps-crc.testing.", domains))
+	assert.True(t, inDomains("Console.Apps.Example.Org", domains))
+	assert.False(t, inDomains("crc.testing.example.com", domains))
+	assert.False(t, inDomains("notcrc.testing", domains))
+}
+
+func TestReplyAddresses(t *testing.T) {
+	query := new(dns.Msg)
+	query.SetQuestion("api.crc.testing.", dns.TypeA)
+
+	reply := new(dns.Msg)
+	reply.SetReply(query)
+	reply.Answer = append(reply.Answer, aRecord("api.crc.testing.", net.ParseIP("127.0.0.1").To4()))
+	addresses, err := replyAddresses(reply)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1"}, addresses)
+
+	reply = new(dns.Msg)
+	reply.SetReply(query)
+	_, err = replyAddresses(reply)
+	assert.EqualError(t, err, "The DNS server has no IPv4 address for this name")
+
+	reply.SetRcode(query, dns.RcodeNameError)
+	_, err = replyAddresses(reply)
+	assert.EqualError(t, err, "The DNS server answered NXDOMAIN")
+}
+
+func TestFormatQuery(t *testing.T) {
+	query := new(dns.Msg)
+	query.SetQuestion("console.apps-crc.testing.", dns.TypeA)
+	assert.Equal(t, "A console.apps-crc.testing. -> no reply", formatQuery(query, nil))
+
+	reply := new(dns.Msg)
+	reply.SetReply(query)
+	reply.Answer = append(reply.Answer, aRecord("console.apps-crc.testing.", net.ParseIP("127.0.0.1").To4()))
+	assert.Equal(t, "A console.apps-crc.testing. -> NOERROR 127.0.0.1", formatQuery(query, reply))
+
+	reply = new(dns.Msg)
+	reply.SetRcode(query, dns.RcodeRefused)
+	assert.Equal(t, "A console.apps-crc.testing. -> REFUSED", formatQuery(query, reply))
+}
+
+func TestDNSTestResultFailed(t *testing.T) {
+	assert.False(t, DNSTestResult{Path: HostDNSPath, Addresses: []string{"127.0.0.1"}}.Failed())
+	assert.True(t, DNSTestResult{Path: HostDNSPath, Error: "no such host"}.Failed())
+	assert.False(t, skippedDNSTestResult(InstanceDNSPath, "The instance is not running").Failed())
+}
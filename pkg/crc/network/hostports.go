@@ -0,0 +1,215 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+)
+
+const (
+	// SSHPort is the default port of the host forwarded to the SSH server
+	// of the instance
+	SSHPort   = constants.VsockSSHPort
+	APIPort   = 6443
+	HTTPPort  = 80
+	HTTPSPort = 443
+
+	// maxAlternativePortAttempts bounds the search of a free host port
+	maxAlternativePortAttempts = 100
+)
+
+// HostPorts are the ports of the host forwarded to the API server and to the
+// router of the cluster, and to the SSH server of the instance, in the user
+// network mode
+type HostPorts struct {
+	API   int `json:"api"`
+	HTTP  int `json:"http"`
+	HTTPS int `json:"https"`
+	SSH   int `json:"ssh,omitempty"`
+}
+
+func DefaultHostPorts() HostPorts {
+	return HostPorts{
+		SSH:   SSHPort,
+		API:   APIPort,
+		HTTP:  HTTPPort,
+		HTTPS: HTTPSPort,
+	}
+}
+
+// HostPort returns the port of the host forwarded to guestPort
+func (p HostPorts) HostPort(guestPort int) int {
+	switch guestPort {
+	case APIPort:
+		return p.API
+	case HTTPPort:
+		return p.HTTP
+	case HTTPSPort:
+		return p.HTTPS
+	default:
+		return guestPort
+	}
+}
+
+// ParsePort parses a port number of the host
+func ParsePort(value string) (int, error) {
+	return parsePort(strings.TrimSpace(value))
+}
+
+// ParseIngressPorts parses the ports of the host forwarded to the router, in
+// the form <http port>,<https port>
+func ParseIngressPorts(value string) (int, int, error) {
+	ports := strings.SplitN(value, ",", 2)
+	if len(ports) != 2 {
+		return 0, 0, fmt.Errorf("Invalid ingress ports '%s', expected <http port>,<https port>", value)
+	}
+	http, err := ParsePort(ports[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	https, err := ParsePort(ports[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	if http == https {
+		return 0, 0, fmt.Errorf("Invalid ingress ports '%s', the http and https ports must be different", value)
+	}
+	return http, https, nil
+}
+
+// alternativePorts are the first ports tried when the default ones are used
+// by another process, they do not need privileges
+var alternativePorts = map[int]int{
+	APIPort:   16443,
+	HTTPPort:  8080,
+	HTTPSPort: 8443,
+}
+
+// PortConflict is a TCP address of the host on which another process listens
+type PortConflict struct {
+	Address string `json:"address"`
+	// Process is the name and the pid of the process, when it can be found
+	Process string `json:"process,omitempty"`
+}
+
+func (c PortConflict) String() string {
+	if c.Process == "" {
+		return fmt.Sprintf("%s is used by another process", c.Address)
+	}
+	return fmt.Sprintf("%s is used by %s", c.Address, c.Process)
+}
+
+// CheckAddress returns the process listening on the TCP address, or nil when
+// the address is free. The errors which are not a conflict, like the lack of
+// privileges to use the port, are left to the daemon.
+func CheckAddress(address string) *PortConflict {
+	ln, err := net.Listen("tcp", address)
+	if err == nil {
+		_ = ln.Close()
+		return nil
+	}
+	if !isAddrInUse(err) {
+		logging.Debugf("Cannot listen on %s: %v", address, err)
+		return nil
+	}
+	conflict := &PortConflict{Address: address}
+	_, port, _ := net.SplitHostPort(address)
+	if conflict.Process, err = listeningProcess(port); err != nil {
+		logging.Debugf("Cannot find the process listening on %s: %v", address, err)
+	}
+	return conflict
+}
+
+// CheckPort returns the first conflict of port on the addresses, or nil when
+// no other process uses it
+func CheckPort(addresses []string, port int) *PortConflict {
+	for _, address := range addresses {
+		if conflict := CheckAddress(net.JoinHostPort(address, strconv.Itoa(port))); conflict != nil {
+			return conflict
+		}
+	}
+	return nil
+}
+
+// AlternativePort returns a port free on all the addresses to use instead of
+// port, excluding the ports already chosen for the other forwards
+func AlternativePort(addresses []string, port int, excluded []int) (int, error) {
+	candidate, ok := alternativePorts[port]
+	if !ok {
+		candidate = port + 10000
+	}
+	for i := 0; i < maxAlternativePortAttempts && candidate <= 65535; i, candidate = i+1, candidate+1 {
+		if containsPort(excluded, candidate) {
+			continue
+		}
+		if CheckPort(addresses, candidate) == nil {
+			return candidate, nil
+		}
+	}
+	return 0, fmt.Errorf("Cannot find a free port of the host to use instead of %d", port)
+}
+
+func containsPort(ports []int, port int) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
+
+var ssProcessRegexp = regexp.MustCompile(`users:\(\("([^"]+)",pid=(\d+)`)
+
+// parseSSProcess returns the process of the output of 'ss -Hltnp', which
+// only lists the processes of the other users to root
+func parseSSProcess(output string) string {
+	matches := ssProcessRegexp.FindStringSubmatch(output)
+	if matches == nil {
+		return ""
+	}
+	return formatProcess(matches[1], matches[2])
+}
+
+// parseLsofProcess returns the process of the output of 'lsof -Fpc', which
+// lists the fields of each process on their own line, prefixed by their name
+func parseLsofProcess(output string) string {
+	var pid, command string
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "p") && pid == "":
+			pid = strings.TrimPrefix(line, "p")
+		case strings.HasPrefix(line, "c") && command == "":
+			command = strings.TrimPrefix(line, "c")
+		}
+	}
+	if pid == "" {
+		return ""
+	}
+	return formatProcess(command, pid)
+}
+
+// parsePowerShellProcess returns the process of the output of
+// listeningProcess on Windows, the pid followed by the name of the process
+func parsePowerShellProcess(output string) string {
+	fields := strings.SplitN(strings.TrimSpace(strings.Split(strings.TrimSpace(output), "\n")[0]), " ", 2)
+	if fields[0] == "" {
+		return ""
+	}
+	name := ""
+	if len(fields) == 2 {
+		name = strings.TrimSpace(fields[1])
+	}
+	return formatProcess(name, fields[0])
+}
+
+func formatProcess(name, pid string) string {
+	if name == "" {
+		return fmt.Sprintf("the process %s", pid)
+	}
+	return fmt.Sprintf("%s (pid %s)", name, pid)
+}
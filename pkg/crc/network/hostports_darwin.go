@@ -0,0 +1,13 @@
+package network
+
+import (
+	crcos "github.com/code-ready/crc/pkg/os"
+)
+
+func listeningProcess(port string) (string, error) {
+	stdout, _, err := crcos.RunWithDefaultLocale("lsof", "-nP", "-iTCP:"+port, "-sTCP:LISTEN", "-Fpc")
+	if err != nil {
+		return "", err
+	}
+	return parseLsofProcess(stdout), nil
+}
@@ -0,0 +1,13 @@
+package network
+
+import (
+	crcos "github.com/code-ready/crc/pkg/os"
+)
+
+func listeningProcess(port string) (string, error) {
+	stdout, _, err := crcos.RunWithDefaultLocale("ss", "-Hltnp", "sport = :"+port)
+	if err != nil {
+		return "", err
+	}
+	return parseSSProcess(stdout), nil
+}
@@ -0,0 +1,79 @@
+package network
+
+import (
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostPort(t *testing.T) {
+	ports := HostPorts{API: 16443, HTTP: 8080, HTTPS: 8443}
+	assert.Equal(t, 16443, ports.HostPort(APIPort))
+	assert.Equal(t, 8080, ports.HostPort(HTTPPort))
+	assert.Equal(t, 8443, ports.HostPort(HTTPSPort))
+	assert.Equal(t, 9090, ports.HostPort(9090))
+	assert.Equal(t, 443, DefaultHostPorts().HostPort(HTTPSPort))
+}
+
+func TestCheckAddress(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := ln.Addr().String()
+
+	conflict := CheckAddress(address)
+	require.NotNil(t, conflict)
+	assert.Equal(t, address, conflict.Address)
+
+	require.NoError(t, ln.Close())
+	assert.Nil(t, CheckAddress(address))
+}
+
+func TestAlternativePort(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	_, portString, _ := net.SplitHostPort(ln.Addr().String())
+	used, _ := strconv.Atoi(portString)
+	if used <= 10000 {
+		t.Skip("the ephemeral port is too low")
+	}
+
+	// the alternative of a port without a preferred alternative is 10000 above
+	port, err := AlternativePort([]string{"127.0.0.1"}, used-10000, []int{used + 1})
+	require.NoError(t, err)
+	assert.Greater(t, port, used+1)
+}
+
+func TestPortConflictString(t *testing.T) {
+	assert.Equal(t, "127.0.0.1:6443 is used by nginx (pid 42)", PortConflict{Address: "127.0.0.1:6443", Process: "nginx (pid 42)"}.String())
+	assert.Equal(t, ":443 is used by another process", PortConflict{Address: ":443"}.String())
+}
+
+func TestParseListeningProcess(t *testing.T) {
+	assert.Equal(t, "nginx (pid 1234)", parseSSProcess(`LISTEN 0      511          0.0.0.0:443       0.0.0.0:*    users:(("nginx",pid=1234,fd=6),("nginx",pid=1233,fd=6))`))
+	assert.Equal(t, "", parseSSProcess(`LISTEN 0      511          0.0.0.0:443       0.0.0.0:*`))
+
+	assert.Equal(t, "httpd (pid 87)", parseLsofProcess("p87\nchttpd\nf4\n"))
+	assert.Equal(t, "", parseLsofProcess(""))
+
+	assert.Equal(t, "System (pid 4)", parsePowerShellProcess("4 System\r\n"))
+	assert.Equal(t, "the process 5012", parsePowerShellProcess("5012 \r\n"))
+	assert.Equal(t, "", parsePowerShellProcess(""))
+}
+
+func TestParseIngressPorts(t *testing.T) {
+	http, https, err := ParseIngressPorts("8080, 8443")
+	require.NoError(t, err)
+	assert.Equal(t, 8080, http)
+	assert.Equal(t, 8443, https)
+
+	_, _, err = ParseIngressPorts("8443")
+	assert.EqualError(t, err, "Invalid ingress ports '8443', expected <http port>,<https port>")
+	_, _, err = ParseIngressPorts("80,https")
+	assert.EqualError(t, err, "Invalid port 'https', must be a number between 1 and 65535")
+	_, _, err = ParseIngressPorts("443,443")
+	assert.EqualError(t, err, "Invalid ingress ports '443,443', the http and https ports must be different")
+}
@@ -0,0 +1,21 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/os/windows/powershell"
+	"golang.org/x/sys/windows"
+)
+
+func isAddrInUse(err error) bool {
+	return errors.Is(err, windows.WSAEADDRINUSE)
+}
+
+func listeningProcess(port string) (string, error) {
+	stdout, _, err := powershell.Execute(fmt.Sprintf(`Get-NetTCPConnection -LocalPort %s -State Listen -ErrorAction SilentlyContinue | Select-Object -First 1 | ForEach-Object { "$($_.OwningProcess) $((Get-Process -Id $_.OwningProcess).ProcessName)" }`, port))
+	if err != nil {
+		return "", err
+	}
+	return parsePowerShellProcess(stdout), nil
+}
@@ -0,0 +1,65 @@
+package network
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
+	"github.com/miekg/dns"
+)
+
+const resolveTimeout = 5 * time.Second
+
+// HostDNSResolver answers the queries of the host for the names of the
+// cluster. The operating system is configured to only send it the queries
+// for the crc domains, so the other queries are refused instead of being
+// forwarded, which could send them back to the resolver.
+type HostDNSResolver struct {
+	Zones []types.Zone
+}
+
+func (r *HostDNSResolver) ServeDNS(w dns.ResponseWriter, query *dns.Msg) {
+	if err := w.WriteMsg(r.reply(query)); err != nil {
+		logging.Debugf("Cannot write DNS reply: %v", err)
+	}
+}
+
+func (r *HostDNSResolver) reply(query *dns.Msg) *dns.Msg {
+	if len(query.Question) == 1 {
+		if reply, ok := zoneReply(r.Zones, query); ok {
+			return reply
+		}
+	}
+	reply := new(dns.Msg)
+	reply.SetRcode(query, dns.RcodeRefused)
+	return reply
+}
+
+// HostResolverDomains lists the domains for which the operating system sends
+// the queries to the resolver of the daemon
+func HostResolverDomains(clusterDomain string) []string {
+	domains := []string{"crc.testing", "apps-crc.testing"}
+	if clusterDomain != "" {
+		domains = append(domains, clusterDomain)
+	}
+	return domains
+}
+
+// ResolvesTo returns true when the resolver of the host returns ip for
+// hostname
+func ResolvesTo(hostname string, ip string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+	addresses, err := net.DefaultResolver.LookupHost(ctx, hostname)
+	if err != nil {
+		return false
+	}
+	for _, address := range addresses {
+		if address == ip {
+			return true
+		}
+	}
+	return false
+}
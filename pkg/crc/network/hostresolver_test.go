@@ -0,0 +1,38 @@
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostDNSResolver(t *testing.T) {
+	resolver := &HostDNSResolver{
+		Zones: []types.Zone{
+			{
+				Name:      "apps-crc.testing.",
+				DefaultIP: net.ParseIP("127.0.0.1"),
+			},
+			{
+				Name: "crc.testing.",
+				Records: []types.Record{
+					{Name: "api", IP: net.ParseIP("127.0.0.1")},
+				},
+			},
+		},
+	}
+
+	lookup := func(name string) *dns.Msg {
+		query := new(dns.Msg)
+		query.SetQuestion(name, dns.TypeA)
+		return resolver.reply(query)
+	}
+
+	assert.Equal(t, "127.0.0.1", lookup("console-openshift-console.apps-crc.testing.").Answer[0].(*dns.A).A.String())
+	assert.Equal(t, "127.0.0.1", lookup("api.crc.testing.").Answer[0].(*dns.A).A.String())
+	assert.Equal(t, dns.RcodeNameError, lookup("unknown.crc.testing.").Rcode)
+	assert.Equal(t, dns.RcodeRefused, lookup("example.com.").Rcode)
+}
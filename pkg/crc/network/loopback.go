@@ -0,0 +1,31 @@
+package network
+
+import (
+	"net"
+)
+
+const (
+	IPv4Loopback = "127.0.0.1"
+	IPv6Loopback = "::1"
+)
+
+// LoopbackAddresses returns the addresses on which the ports forwarded to the
+// instance listen on the host. ::1 is used in addition to 127.0.0.1 when the
+// host supports IPv6, so that names resolving to ::1 on IPv6-only and
+// dual-stack hosts reach the instance too.
+func LoopbackAddresses() []string {
+	addresses := []string{IPv4Loopback}
+	if hasIPv6Loopback() {
+		addresses = append(addresses, IPv6Loopback)
+	}
+	return addresses
+}
+
+func hasIPv6Loopback() bool {
+	ln, err := net.Listen("tcp6", net.JoinHostPort(IPv6Loopback, "0"))
+	if err != nil {
+		return false
+	}
+	_ = ln.Close()
+	return true
+}
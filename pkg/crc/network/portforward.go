@@ -0,0 +1,187 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
+)
+
+const portForwardTarget = "machine"
+
+// PortForward is a host to VM port forwarding, used in user mode networking
+// where the ports of the VM are not reachable from the host
+type PortForward struct {
+	Protocol  string `json:"protocol"`
+	HostPort  int    `json:"hostPort"`
+	GuestPort int    `json:"guestPort"`
+}
+
+// ParsePortForward parses a port forwarding in the form
+// <host port>:machine:<guest port>[/tcp|/udp]
+func ParsePortForward(spec string) (PortForward, error) {
+	protocol := "tcp"
+	if i := strings.LastIndex(spec, "/"); i >= 0 {
+		protocol = spec[i+1:]
+		spec = spec[:i]
+	}
+	if protocol != "tcp" && protocol != "udp" {
+		return PortForward{}, fmt.Errorf("Invalid protocol '%s', must be tcp or udp", protocol)
+	}
+	parts := strings.Split(spec, ":")
+	if len(parts) != 3 || parts[1] != portForwardTarget {
+		return PortForward{}, fmt.Errorf("Invalid port forwarding '%s', expected <host port>:%s:<guest port>", spec, portForwardTarget)
+	}
+	hostPort, err := parsePort(parts[0])
+	if err != nil {
+		return PortForward{}, err
+	}
+	guestPort, err := parsePort(parts[2])
+	if err != nil {
+		return PortForward{}, err
+	}
+	return PortForward{
+		Protocol:  protocol,
+		HostPort:  hostPort,
+		GuestPort: guestPort,
+	}, nil
+}
+
+func parsePort(value string) (int, error) {
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("Invalid port '%s', must be a number between 1 and 65535", value)
+	}
+	return port, nil
+}
+
+func (pf PortForward) String() string {
+	return fmt.Sprintf("%d:%s:%d/%s", pf.HostPort, portForwardTarget, pf.GuestPort, pf.Protocol)
+}
+
+// ExposeRequests converts the port forwarding to gvisor-tap-vsock requests
+// forwarding <loopback address>:<host port> to vmIP:<guest port>, for each of
+// the host loopback addresses
+func (pf PortForward) ExposeRequests(vmIP string, loopbackAddresses []string) []types.ExposeRequest {
+	var requests []types.ExposeRequest
+	for _, address := range loopbackAddresses {
+		requests = append(requests, types.ExposeRequest{
+			Protocol: types.TransportProtocol(pf.Protocol),
+			Local:    net.JoinHostPort(address, strconv.Itoa(pf.HostPort)),
+			Remote:   net.JoinHostPort(vmIP, strconv.Itoa(pf.GuestPort)),
+		})
+	}
+	return requests
+}
+
+func (pf PortForward) UnexposeRequests(loopbackAddresses []string) []types.UnexposeRequest {
+	var requests []types.UnexposeRequest
+	for _, address := range loopbackAddresses {
+		requests = append(requests, types.UnexposeRequest{
+			Protocol: types.TransportProtocol(pf.Protocol),
+			Local:    net.JoinHostPort(address, strconv.Itoa(pf.HostPort)),
+		})
+	}
+	return requests
+}
+
+// PortForwardStore persists the port forwardings in a JSON file so that they
+// can be restored when the VM is started again
+type PortForwardStore struct {
+	path string
+	lock sync.Mutex
+}
+
+func NewPortForwardStore(path string) *PortForwardStore {
+	return &PortForwardStore{
+		path: path,
+	}
+}
+
+func (store *PortForwardStore) List() ([]PortForward, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	return store.load()
+}
+
+// Check fails if the host port of the port forwarding is already used by
+// another port forwarding with the same protocol
+func (store *PortForwardStore) Check(pf PortForward) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	forwards, err := store.load()
+	if err != nil {
+		return err
+	}
+	return checkConflict(forwards, pf)
+}
+
+// Add stores a new port forwarding, it fails if the host port is already used
+// by another port forwarding with the same protocol
+func (store *PortForwardStore) Add(pf PortForward) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	forwards, err := store.load()
+	if err != nil {
+		return err
+	}
+	if err := checkConflict(forwards, pf); err != nil {
+		return err
+	}
+	return store.save(append(forwards, pf))
+}
+
+func checkConflict(forwards []PortForward, pf PortForward) error {
+	for _, existing := range forwards {
+		if existing.HostPort == pf.HostPort && existing.Protocol == pf.Protocol {
+			return fmt.Errorf("Host port %d/%s is already forwarded (%s)", pf.HostPort, pf.Protocol, existing)
+		}
+	}
+	return nil
+}
+
+// Remove deletes the port forwarding and returns it, it fails if there is no
+// port forwarding for this host port
+func (store *PortForwardStore) Remove(hostPort int, protocol string) (PortForward, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	forwards, err := store.load()
+	if err != nil {
+		return PortForward{}, err
+	}
+	for i, existing := range forwards {
+		if existing.HostPort == hostPort && existing.Protocol == protocol {
+			return existing, store.save(append(forwards[:i], forwards[i+1:]...))
+		}
+	}
+	return PortForward{}, fmt.Errorf("No port forwarding for host port %d/%s", hostPort, protocol)
+}
+
+func (store *PortForwardStore) load() ([]PortForward, error) {
+	data, err := ioutil.ReadFile(store.path)
+	if os.IsNotExist(err) {
+		return []PortForward{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var forwards []PortForward
+	if err := json.Unmarshal(data, &forwards); err != nil {
+		return nil, fmt.Errorf("Cannot parse %s: %w", store.path, err)
+	}
+	return forwards, nil
+}
+
+func (store *PortForwardStore) save(forwards []PortForward) error {
+	data, err := json.MarshalIndent(forwards, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(store.path, data, 0600)
+}
@@ -0,0 +1,70 @@
+package network
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePortForward(t *testing.T) {
+	pf, err := ParsePortForward("8080:machine:80")
+	assert.NoError(t, err)
+	assert.Equal(t, PortForward{Protocol: "tcp", HostPort: 8080, GuestPort: 80}, pf)
+	assert.Equal(t, "8080:machine:80/tcp", pf.String())
+
+	pf, err = ParsePortForward("5353:machine:53/udp")
+	assert.NoError(t, err)
+	assert.Equal(t, PortForward{Protocol: "udp", HostPort: 5353, GuestPort: 53}, pf)
+
+	_, err = ParsePortForward("8080:80")
+	assert.EqualError(t, err, "Invalid port forwarding '8080:80', expected <host port>:machine:<guest port>")
+	_, err = ParsePortForward("8080:machine:80/sctp")
+	assert.EqualError(t, err, "Invalid protocol 'sctp', must be tcp or udp")
+	_, err = ParsePortForward("0:machine:80")
+	assert.EqualError(t, err, "Invalid port '0', must be a number between 1 and 65535")
+}
+
+func TestPortForwardExposeRequest(t *testing.T) {
+	pf := PortForward{Protocol: "tcp", HostPort: 8080, GuestPort: 80}
+	assert.Equal(t, []types.ExposeRequest{{Protocol: types.TCP, Local: "127.0.0.1:8080", Remote: "192.168.127.2:80"}}, pf.ExposeRequests("192.168.127.2", []string{"127.0.0.1"}))
+	assert.Equal(t, []types.UnexposeRequest{{Protocol: types.TCP, Local: "127.0.0.1:8080"}}, pf.UnexposeRequests([]string{"127.0.0.1"}))
+
+	assert.Equal(t, []types.ExposeRequest{
+		{Protocol: types.TCP, Local: "127.0.0.1:8080", Remote: "192.168.127.2:80"},
+		{Protocol: types.TCP, Local: "[::1]:8080", Remote: "192.168.127.2:80"},
+	}, pf.ExposeRequests("192.168.127.2", []string{"127.0.0.1", "::1"}))
+	assert.Equal(t, []types.UnexposeRequest{
+		{Protocol: types.TCP, Local: "127.0.0.1:8080"},
+		{Protocol: types.TCP, Local: "[::1]:8080"},
+	}, pf.UnexposeRequests([]string{"127.0.0.1", "::1"}))
+}
+
+func TestPortForwardStore(t *testing.T) {
+	store := NewPortForwardStore(filepath.Join(t.TempDir(), "port-forwards.json"))
+
+	forwards, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, forwards)
+
+	pf := PortForward{Protocol: "tcp", HostPort: 8080, GuestPort: 80}
+	require.NoError(t, store.Add(pf))
+	assert.EqualError(t, store.Add(PortForward{Protocol: "tcp", HostPort: 8080, GuestPort: 81}), "Host port 8080/tcp is already forwarded (8080:machine:80/tcp)")
+	require.NoError(t, store.Add(PortForward{Protocol: "udp", HostPort: 8080, GuestPort: 81}))
+
+	forwards, err = store.List()
+	require.NoError(t, err)
+	assert.Len(t, forwards, 2)
+
+	removed, err := store.Remove(8080, "tcp")
+	require.NoError(t, err)
+	assert.Equal(t, pf, removed)
+	_, err = store.Remove(8080, "tcp")
+	assert.EqualError(t, err, "No port forwarding for host port 8080/tcp")
+
+	forwards, err = store.List()
+	require.NoError(t, err)
+	assert.Equal(t, []PortForward{{Protocol: "udp", HostPort: 8080, GuestPort: 81}}, forwards)
+}
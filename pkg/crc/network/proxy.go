@@ -1,10 +1,12 @@
 package network
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -29,6 +31,14 @@ type ProxyConfig struct {
 	noProxy     []string
 	ProxyCACert string
 	ProxyCAFile string
+	// CACert contains additional certificate authorities to trust, for
+	// instance the one of a TLS-intercepting proxy which is not configured
+	// as an HTTPS proxy
+	CACert string
+	CAFile string
+	// AuthMethod is the authentication method of the proxy, basic when it
+	// is empty
+	AuthMethod string
 }
 
 func (p *ProxyConfig) String() string {
@@ -36,8 +46,15 @@ func (p *ProxyConfig) String() string {
 	if p.ProxyCAFile != "" {
 		caCertForDisplay = fmt.Sprintf(", proxyCAFile: %s", p.ProxyCAFile)
 	}
-	return fmt.Sprintf("HTTP-PROXY: %s, HTTPS-PROXY: %s, NO-PROXY: %s%s", p.HTTPProxyForDisplay(),
-		p.HTTPSProxyForDisplay(), p.GetNoProxyString(), caCertForDisplay)
+	if p.CAFile != "" {
+		caCertForDisplay = fmt.Sprintf("%s, caFile: %s", caCertForDisplay, p.CAFile)
+	}
+	var authForDisplay string
+	if p.AuthMethod != "" && p.AuthMethod != BasicProxyAuth {
+		authForDisplay = fmt.Sprintf(", auth: %s", p.AuthMethod)
+	}
+	return fmt.Sprintf("HTTP-PROXY: %s, HTTPS-PROXY: %s, NO-PROXY: %s%s%s", p.HTTPProxyForDisplay(),
+		p.HTTPSProxyForDisplay(), p.GetNoProxyString(), caCertForDisplay, authForDisplay)
 }
 
 func readProxyCAData(proxyCAFile string) (string, error) {
@@ -55,17 +72,24 @@ func trimTrailingEOL(s string) string {
 	return strings.TrimRight(s, "\n")
 }
 
-func NewProxyDefaults(httpProxy, httpsProxy, noProxy, proxyCAFile string) (*ProxyConfig, error) {
+func NewProxyDefaults(httpProxy, httpsProxy, noProxy, proxyCAFile, caFile, authMethod string) (*ProxyConfig, error) {
 	proxyCAData, err := readProxyCAData(proxyCAFile)
 	if err != nil {
 		return nil, errors.Wrapf(err, "not able to read proxy CA data from %s", proxyCAFile)
 	}
+	caData, err := readProxyCAData(caFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "not able to read CA data from %s", caFile)
+	}
 
 	DefaultProxy = ProxyConfig{
 		HTTPProxy:   httpProxy,
 		HTTPSProxy:  httpsProxy,
 		ProxyCACert: proxyCAData,
 		ProxyCAFile: proxyCAFile,
+		CACert:      caData,
+		CAFile:      caFile,
+		AuthMethod:  authMethod,
 	}
 	envProxy := httpproxy.FromEnvironment()
 
@@ -91,6 +115,9 @@ func NewProxyConfig() (*ProxyConfig, error) {
 		HTTPSProxy:  DefaultProxy.HTTPSProxy,
 		ProxyCACert: DefaultProxy.ProxyCACert,
 		ProxyCAFile: DefaultProxy.ProxyCAFile,
+		CACert:      DefaultProxy.CACert,
+		CAFile:      DefaultProxy.CAFile,
+		AuthMethod:  DefaultProxy.AuthMethod,
 	}
 
 	config.noProxy = defaultNoProxies
@@ -108,6 +135,12 @@ func NewProxyConfig() (*ProxyConfig, error) {
 		return nil, err
 	}
 
+	if config.AuthMethod != "" {
+		if err := ValidateProxyAuthMethod(config.AuthMethod); err != nil {
+			return nil, err
+		}
+	}
+
 	return &config, nil
 }
 
@@ -187,15 +220,20 @@ func ValidateProxyURL(proxyURL string, isHTTPSProxy bool) error {
 	}
 
 	// check URL scheme
-	// http proxy URLs must start with http://
-	// https proxy URLs must start with http://or https://
+	// http proxy URLs must start with http:// or socks5://
+	// https proxy URLs must start with http://, https:// or socks5://
 	httpScheme := strings.HasPrefix(proxyURL, "http://")
 	httpsScheme := strings.HasPrefix(proxyURL, "https://")
+	socksScheme := isSOCKSProxy(proxyURL)
 	switch {
-	case !isHTTPSProxy && !httpScheme:
-		return fmt.Errorf("HTTP proxy URL '%s' is not valid: url should start with http://", proxyURL)
-	case isHTTPSProxy && !httpScheme && !httpsScheme:
-		return fmt.Errorf("HTTPS proxy URL '%s' is not valid: url should start with http:// or https://", proxyURL)
+	case !isHTTPSProxy && !httpScheme && !socksScheme:
+		return fmt.Errorf("HTTP proxy URL '%s' is not valid: url should start with http:// or socks5://", proxyURL)
+	case isHTTPSProxy && !httpScheme && !httpsScheme && !socksScheme:
+		return fmt.Errorf("HTTPS proxy URL '%s' is not valid: url should start with http://, https:// or socks5://", proxyURL)
+	}
+
+	if socksScheme {
+		return validateSOCKSProxyURL(proxyURL)
 	}
 
 	if !govalidator.IsURL(proxyURL) {
@@ -204,8 +242,20 @@ func ValidateProxyURL(proxyURL string, isHTTPSProxy bool) error {
 	return nil
 }
 
+// TrustedCABundle returns the proxy CA and the additional CAs, in PEM format
+func (p *ProxyConfig) TrustedCABundle() string {
+	var certs []string
+	for _, cert := range []string{p.ProxyCACert, p.CACert} {
+		if cert != "" {
+			certs = append(certs, cert)
+		}
+	}
+	return strings.Join(certs, "\n")
+}
+
 func (p *ProxyConfig) tlsConfig() (*tls.Config, error) {
-	if p.ProxyCACert == "" {
+	bundle := p.TrustedCABundle()
+	if bundle == "" {
 		return nil, nil
 	}
 	caCertPool, err := x509.SystemCertPool()
@@ -213,7 +263,7 @@ func (p *ProxyConfig) tlsConfig() (*tls.Config, error) {
 		logging.Warnf("Could not load system CA pool: %v", err)
 		caCertPool = x509.NewCertPool()
 	}
-	ok := caCertPool.AppendCertsFromPEM([]byte(p.ProxyCACert))
+	ok := caCertPool.AppendCertsFromPEM([]byte(bundle))
 	if !ok {
 		return nil, fmt.Errorf("Failed to append proxy CA to system CAs")
 	}
@@ -224,7 +274,7 @@ func (p *ProxyConfig) tlsConfig() (*tls.Config, error) {
 }
 
 func (p *ProxyConfig) HTTPTransport() http.RoundTripper {
-	if !p.IsEnabled() {
+	if !p.IsEnabled() && p.CACert == "" {
 		return http.DefaultTransport
 	}
 
@@ -244,6 +294,16 @@ func (p *ProxyConfig) HTTPTransport() http.RoundTripper {
 	}
 	transport.TLSClientConfig = tlsConfig
 
+	// net/http only supports the basic authentication, the connections go
+	// through a tunnel opened after authenticating to the proxy
+	if p.IsEnabled() && p.authenticatesOnHost() {
+		transport.Proxy = nil
+		transport.DialContext = func(ctx context.Context, _, address string) (net.Conn, error) {
+			return p.dialThroughProxy(ctx, targetURL(ctx, address))
+		}
+		return &targetRoundTripper{transport: transport}
+	}
+
 	return transport
 }
 
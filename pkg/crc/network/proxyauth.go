@@ -0,0 +1,310 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/openshift/oc/pkg/helpers/tokencmd"
+)
+
+// The proxy authentication methods, with basic the credentials are the ones
+// of the proxy URL, with negotiate and ntlm crc authenticates with the
+// credentials of the user logged in on the host
+const (
+	BasicProxyAuth     = "basic"
+	NegotiateProxyAuth = "negotiate"
+	NTLMProxyAuth      = "ntlm"
+)
+
+// maxProxyAuthRounds bounds the challenges of a proxy, NTLM needs 2 rounds
+// and Kerberos usually 1
+const maxProxyAuthRounds = 4
+
+// proxyAuthenticator computes the tokens of the Proxy-Authorization header
+// of a connection to the proxy
+type proxyAuthenticator interface {
+	// scheme is the authentication scheme of the Proxy-Authorization and
+	// Proxy-Authenticate headers
+	scheme() string
+	// token returns the token answering challenge, challenge is nil for the
+	// first request
+	token(challenge []byte) ([]byte, error)
+	release()
+}
+
+type basicAuthenticator struct {
+	user  *url.Userinfo
+	asked bool
+}
+
+func (auth *basicAuthenticator) scheme() string {
+	return "Basic"
+}
+
+func (auth *basicAuthenticator) token(challenge []byte) ([]byte, error) {
+	if auth.user == nil {
+		// without credentials the first request is not authenticated
+		if auth.asked {
+			return nil, fmt.Errorf("The proxy requires a username and a password")
+		}
+		auth.asked = true
+		return nil, nil
+	}
+	password, _ := auth.user.Password()
+	return []byte(auth.user.Username() + ":" + password), nil
+}
+
+func (auth *basicAuthenticator) release() {
+}
+
+// newProxyAuthenticator returns the authenticator of the connections to the
+// proxy at proxyURL
+var newProxyAuthenticator = func(method string, proxyURL *url.URL) (proxyAuthenticator, error) {
+	if method == "" || method == BasicProxyAuth {
+		return &basicAuthenticator{user: proxyURL.User}, nil
+	}
+	return newHostProxyAuthenticator(method, proxyURL)
+}
+
+// authenticatesOnHost returns true if the credentials of the proxy are the
+// ones of the user of the host, the cluster cannot use them
+func (p *ProxyConfig) authenticatesOnHost() bool {
+	return p.AuthMethod == NegotiateProxyAuth || p.AuthMethod == NTLMProxyAuth
+}
+
+// ValidateProxyAuthMethod checks if crc supports the proxy authentication
+// method on this host
+func ValidateProxyAuthMethod(method string) error {
+	for _, supported := range ProxyAuthMethods() {
+		if method == supported {
+			return nil
+		}
+	}
+	if method == NegotiateProxyAuth {
+		return fmt.Errorf("Proxy authentication method '%s' is not supported, this crc executable was built without Kerberos support (the gssapi build tag)", method)
+	}
+	return fmt.Errorf("Proxy authentication method '%s' is not supported, use %s", method, strings.Join(ProxyAuthMethods(), " or "))
+}
+
+type targetURLKey struct{}
+
+// targetRoundTripper passes the URL of the request to the dialer of the
+// transport, which chooses the proxy of the connection with it
+type targetRoundTripper struct {
+	transport *http.Transport
+}
+
+func (rt *targetRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return rt.transport.RoundTrip(req.WithContext(context.WithValue(req.Context(), targetURLKey{}, req.URL)))
+}
+
+func targetURL(ctx context.Context, address string) *url.URL {
+	if target, ok := ctx.Value(targetURLKey{}).(*url.URL); ok {
+		return &url.URL{Scheme: target.Scheme, Host: address}
+	}
+	if _, port, err := net.SplitHostPort(address); err == nil && port == "80" {
+		return &url.URL{Scheme: "http", Host: address}
+	}
+	return &url.URL{Scheme: "https", Host: address}
+}
+
+func defaultPort(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	switch u.Scheme {
+	case "http":
+		return net.JoinHostPort(u.Hostname(), "80")
+	case "socks5":
+		return net.JoinHostPort(u.Hostname(), socks5DefaultPort)
+	default:
+		return net.JoinHostPort(u.Hostname(), "443")
+	}
+}
+
+// dialThroughProxy connects to the host of target with the proxy the
+// configuration uses for target. HTTP proxies are asked to open a tunnel
+// with CONNECT, after authenticating with the method of the configuration.
+func (p *ProxyConfig) dialThroughProxy(ctx context.Context, target *url.URL) (net.Conn, error) {
+	address := defaultPort(target)
+	proxyURL, err := p.ProxyFunc()(&http.Request{URL: target})
+	if err != nil {
+		return nil, err
+	}
+	var dialer net.Dialer
+	switch {
+	case proxyURL == nil:
+		return dialer.DialContext(ctx, "tcp", address)
+	case proxyURL.Scheme == "socks5":
+		return dialSOCKS5(ctx, proxyURL, address)
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", defaultPort(proxyURL))
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL.Scheme == "https" {
+		tlsConfig, err := p.tlsConfig()
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		tlsConfig.ServerName = proxyURL.Hostname()
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := handshake(ctx, tlsConn); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+	auth, err := newProxyAuthenticator(p.AuthMethod, proxyURL)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	defer auth.release()
+	if err := connectThroughProxy(conn, auth, address); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// handshake runs the TLS handshake before the deadline of ctx
+func handshake(ctx context.Context, conn *tls.Conn) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+		defer conn.SetDeadline(time.Time{}) //nolint:errcheck
+	}
+	return conn.Handshake()
+}
+
+// connectThroughProxy asks the proxy of conn to open a tunnel to address, it
+// answers the authentication challenges of the proxy with auth
+func connectThroughProxy(conn net.Conn, auth proxyAuthenticator, address string) error {
+	reader := bufio.NewReader(conn)
+	token, err := auth.token(nil)
+	if err != nil {
+		return err
+	}
+	for round := 0; round < maxProxyAuthRounds; round++ {
+		req := &http.Request{
+			Method: http.MethodConnect,
+			URL:    &url.URL{Opaque: address},
+			Host:   address,
+			Header: http.Header{},
+		}
+		if token != nil {
+			req.Header.Set("Proxy-Authorization", auth.scheme()+" "+base64.StdEncoding.EncodeToString(token))
+		}
+		if err := req.Write(conn); err != nil {
+			return err
+		}
+		resp, err := http.ReadResponse(reader, req)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode == http.StatusOK {
+			if reader.Buffered() > 0 {
+				return fmt.Errorf("The proxy sent unexpected data after opening the tunnel to %s", address)
+			}
+			return nil
+		}
+		// the connection is kept for the next round, NTLM authenticates it
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusProxyAuthRequired {
+			return fmt.Errorf("The proxy cannot open a tunnel to %s: %s", address, resp.Status)
+		}
+		challenge, ok := proxyChallenge(resp.Header, auth.scheme())
+		if !ok {
+			return unacceptedSchemeError(resp.Header, auth.scheme())
+		}
+		if challenge == nil && token != nil {
+			return fmt.Errorf("The proxy rejected the %s authentication", strings.ToLower(auth.scheme()))
+		}
+		if resp.Close {
+			return fmt.Errorf("The proxy closed the connection during the %s authentication", strings.ToLower(auth.scheme()))
+		}
+		if token, err = auth.token(challenge); err != nil {
+			return err
+		}
+	}
+	return fmt.Errorf("The proxy did not accept the %s authentication after %d attempts", strings.ToLower(auth.scheme()), maxProxyAuthRounds)
+}
+
+// unacceptedSchemeError explains why the proxy does not accept the scheme
+// of the authenticator, Kerberos is only supported by some crc executables
+func unacceptedSchemeError(header http.Header, scheme string) error {
+	if _, ok := proxyChallenge(header, "Negotiate"); ok {
+		if err := ValidateProxyAuthMethod(NegotiateProxyAuth); err != nil {
+			return fmt.Errorf("The proxy requires the negotiate authentication: %w", err)
+		}
+		return fmt.Errorf("The proxy requires the negotiate authentication, set the proxy-auth-method setting to %s", NegotiateProxyAuth)
+	}
+	return fmt.Errorf("The proxy does not accept the %s authentication", strings.ToLower(scheme))
+}
+
+// proxyChallenge returns the token of the Proxy-Authenticate header of
+// scheme, it is nil when the header has no token
+func proxyChallenge(header http.Header, scheme string) ([]byte, bool) {
+	for _, value := range header.Values("Proxy-Authenticate") {
+		fields := strings.Fields(value)
+		if len(fields) == 0 || !strings.EqualFold(fields[0], scheme) {
+			continue
+		}
+		if len(fields) == 1 || strings.Contains(fields[1], "=") && !strings.HasSuffix(fields[1], "=") {
+			// no token, or parameters like the realm of basic
+			return nil, true
+		}
+		token, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil {
+			return nil, true
+		}
+		return token, true
+	}
+	return nil, false
+}
+
+// negotiateAuthenticator uses the Kerberos ticket of the user, with SSPI on
+// Windows and GSSAPI on the other hosts
+type negotiateAuthenticator struct {
+	negotiator tokencmd.Negotiator
+	proxyURL   string
+	started    bool
+}
+
+func (auth *negotiateAuthenticator) scheme() string {
+	return "Negotiate"
+}
+
+func (auth *negotiateAuthenticator) token(challenge []byte) ([]byte, error) {
+	token, err := auth.negotiator.InitSecContext(auth.proxyURL, challenge)
+	if err != nil {
+		return nil, fmt.Errorf("Negotiate authentication failed: %w", err)
+	}
+	auth.started = true
+	return token, nil
+}
+
+func (auth *negotiateAuthenticator) release() {
+	// the negotiators only have resources to release once started
+	if auth.started {
+		_ = auth.negotiator.Release()
+	}
+}
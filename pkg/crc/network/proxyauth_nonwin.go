@@ -0,0 +1,34 @@
+//go:build !windows
+// +build !windows
+
+package network
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/openshift/oc/pkg/helpers/tokencmd"
+)
+
+// ProxyAuthMethods returns the proxy authentication methods supported on
+// this host, Kerberos needs a crc executable built with the gssapi tag
+func ProxyAuthMethods() []string {
+	if tokencmd.GSSAPIEnabled() {
+		return []string{BasicProxyAuth, NegotiateProxyAuth}
+	}
+	return []string{BasicProxyAuth}
+}
+
+func newHostProxyAuthenticator(method string, proxyURL *url.URL) (proxyAuthenticator, error) {
+	if method != NegotiateProxyAuth || !tokencmd.GSSAPIEnabled() {
+		return nil, ValidateProxyAuthMethod(method)
+	}
+	negotiator := tokencmd.NewGSSAPINegotiator("")
+	if err := negotiator.Load(); err != nil {
+		return nil, fmt.Errorf("Cannot load the GSSAPI library: %w", err)
+	}
+	return &negotiateAuthenticator{
+		negotiator: negotiator,
+		proxyURL:   proxyURL.String(),
+	}, nil
+}
@@ -0,0 +1,135 @@
+package network
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuthenticator sends the challenge it receives with a "-response"
+// suffix, like a 2 rounds NTLM authentication
+type fakeAuthenticator struct{}
+
+func (auth *fakeAuthenticator) scheme() string {
+	return "NTLM"
+}
+
+func (auth *fakeAuthenticator) token(challenge []byte) ([]byte, error) {
+	if challenge == nil {
+		return []byte("negotiate"), nil
+	}
+	return append(challenge, "-response"...), nil
+}
+
+func (auth *fakeAuthenticator) release() {
+}
+
+// fakeProxy answers the CONNECT requests of conn with the given responses
+// and records their Proxy-Authorization header
+func fakeProxy(conn net.Conn, responses ...string) <-chan []string {
+	headers := make(chan []string, 1)
+	go func() {
+		defer conn.Close()
+		var received []string
+		reader := bufio.NewReader(conn)
+		for _, response := range responses {
+			req, err := http.ReadRequest(reader)
+			if err != nil {
+				break
+			}
+			received = append(received, req.Header.Get("Proxy-Authorization"))
+			if _, err := fmt.Fprint(conn, response); err != nil {
+				break
+			}
+		}
+		headers <- received
+	}()
+	return headers
+}
+
+func TestConnectThroughProxy(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	headers := fakeProxy(server,
+		"HTTP/1.1 407 Proxy Authentication Required\r\nProxy-Authenticate: NTLM Y2hhbGxlbmdl\r\nContent-Length: 0\r\n\r\n",
+		"HTTP/1.1 200 Connection established\r\n\r\n")
+
+	require.NoError(t, connectThroughProxy(client, &fakeAuthenticator{}, "example.com:443"))
+	client.Close()
+	assert.Equal(t, []string{"NTLM bmVnb3RpYXRl", "NTLM Y2hhbGxlbmdlLXJlc3BvbnNl"}, <-headers)
+}
+
+func TestConnectThroughProxyRejected(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	_ = fakeProxy(server,
+		"HTTP/1.1 407 Proxy Authentication Required\r\nProxy-Authenticate: Basic realm=\"proxy\"\r\nContent-Length: 0\r\n\r\n")
+
+	assert.EqualError(t, connectThroughProxy(client, &fakeAuthenticator{}, "example.com:443"), "The proxy does not accept the ntlm authentication")
+}
+
+func TestConnectThroughProxyRequiresNegotiate(t *testing.T) {
+	if ValidateProxyAuthMethod(NegotiateProxyAuth) == nil {
+		t.Skip("negotiate is supported by this executable")
+	}
+	client, server := net.Pipe()
+	defer client.Close()
+	_ = fakeProxy(server,
+		"HTTP/1.1 407 Proxy Authentication Required\r\nProxy-Authenticate: Negotiate\r\nContent-Length: 0\r\n\r\n")
+
+	assert.EqualError(t, connectThroughProxy(client, &basicAuthenticator{}, "example.com:443"),
+		"The proxy requires the negotiate authentication: Proxy authentication method 'negotiate' is not supported, this crc executable was built without Kerberos support (the gssapi build tag)")
+}
+
+func TestConnectThroughProxyWithoutCredentials(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	headers := fakeProxy(server,
+		"HTTP/1.1 407 Proxy Authentication Required\r\nProxy-Authenticate: Basic realm=\"proxy\"\r\nContent-Length: 0\r\n\r\n")
+
+	assert.EqualError(t, connectThroughProxy(client, &basicAuthenticator{}, "example.com:443"), "The proxy requires a username and a password")
+	client.Close()
+	assert.Equal(t, []string{""}, <-headers)
+}
+
+func TestProxyChallenge(t *testing.T) {
+	header := http.Header{}
+	header.Add("Proxy-Authenticate", `Basic realm="proxy"`)
+	header.Add("Proxy-Authenticate", "Negotiate")
+	header.Add("Proxy-Authenticate", "NTLM dG9rZW4=")
+
+	challenge, ok := proxyChallenge(header, "Basic")
+	assert.True(t, ok)
+	assert.Nil(t, challenge)
+	challenge, ok = proxyChallenge(header, "negotiate")
+	assert.True(t, ok)
+	assert.Nil(t, challenge)
+	challenge, ok = proxyChallenge(header, "NTLM")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("token"), challenge)
+	_, ok = proxyChallenge(header, "Digest")
+	assert.False(t, ok)
+}
+
+func TestProxyConfigNeedsRelay(t *testing.T) {
+	proxy := &ProxyConfig{HTTPProxy: "http://proxy.example.com:3128", HTTPSProxy: "http://proxy.example.com:3128"}
+	assert.False(t, proxy.NeedsRelay())
+
+	proxy.AuthMethod = NTLMProxyAuth
+	assert.True(t, proxy.NeedsRelay())
+	instanceProxy := proxy.ForInstance("http://192.168.127.254:3128")
+	assert.Equal(t, "http://192.168.127.254:3128", instanceProxy.HTTPProxy)
+	assert.Equal(t, "http://192.168.127.254:3128", instanceProxy.HTTPSProxy)
+
+	assert.False(t, (&ProxyConfig{AuthMethod: NegotiateProxyAuth}).NeedsRelay())
+}
+
+func TestValidateProxyAuthMethod(t *testing.T) {
+	assert.NoError(t, ValidateProxyAuthMethod(BasicProxyAuth))
+	assert.Error(t, ValidateProxyAuthMethod("digest"))
+}
@@ -0,0 +1,98 @@
+package network
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/alexbrainman/sspi"
+	"github.com/openshift/oc/pkg/helpers/tokencmd"
+)
+
+// ProxyAuthMethods returns the proxy authentication methods supported on
+// this host, SSPI provides Kerberos and NTLM on Windows
+func ProxyAuthMethods() []string {
+	return []string{BasicProxyAuth, NegotiateProxyAuth, NTLMProxyAuth}
+}
+
+func newHostProxyAuthenticator(method string, proxyURL *url.URL) (proxyAuthenticator, error) {
+	switch method {
+	case NegotiateProxyAuth:
+		// the username and the password of the proxy URL replace the
+		// credentials of the current user, as DOMAIN\username
+		var username, password string
+		if proxyURL.User != nil {
+			if p, ok := proxyURL.User.Password(); ok {
+				username, password = proxyURL.User.Username(), p
+			}
+		}
+		return &negotiateAuthenticator{
+			negotiator: tokencmd.NewSSPINegotiator(username, password, proxyURL.Hostname(), nil),
+			proxyURL:   proxyURL.String(),
+		}, nil
+	case NTLMProxyAuth:
+		return newNTLMAuthenticator()
+	default:
+		return nil, ValidateProxyAuthMethod(method)
+	}
+}
+
+// ntlmAuthenticator uses the NTLM package of SSPI with the credentials of
+// the current user, the Negotiate package wraps the NTLM tokens in SPNEGO
+// which proxies announcing NTLM do not accept
+type ntlmAuthenticator struct {
+	credentials *sspi.Credentials
+	context     *sspi.Context
+	maxToken    uint32
+}
+
+func newNTLMAuthenticator() (proxyAuthenticator, error) {
+	info, err := sspi.QueryPackageInfo(sspi.NTLMSP_NAME)
+	if err != nil {
+		return nil, fmt.Errorf("NTLM is not available: %w", err)
+	}
+	credentials, err := sspi.AcquireCredentials("", sspi.NTLMSP_NAME, sspi.SECPKG_CRED_OUTBOUND, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot get the NTLM credentials of the user: %w", err)
+	}
+	return &ntlmAuthenticator{
+		credentials: credentials,
+		context:     sspi.NewClientContext(credentials, sspi.ISC_REQ_CONNECTION),
+		maxToken:    info.MaxToken,
+	}, nil
+}
+
+func (auth *ntlmAuthenticator) scheme() string {
+	return "NTLM"
+}
+
+func (auth *ntlmAuthenticator) token(challenge []byte) ([]byte, error) {
+	token := make([]byte, auth.maxToken)
+	var inBuf, outBuf [1]sspi.SecBuffer
+	inBuf[0].Set(sspi.SECBUFFER_TOKEN, challenge)
+	inBufs := &sspi.SecBufferDesc{
+		Version:      sspi.SECBUFFER_VERSION,
+		BuffersCount: 1,
+		Buffers:      &inBuf[0],
+	}
+	outBuf[0].Set(sspi.SECBUFFER_TOKEN, token)
+	outBufs := &sspi.SecBufferDesc{
+		Version:      sspi.SECBUFFER_VERSION,
+		BuffersCount: 1,
+		Buffers:      &outBuf[0],
+	}
+	switch ret := auth.context.Update(nil, outBufs, inBufs); ret {
+	case sspi.SEC_E_OK, sspi.SEC_I_CONTINUE_NEEDED:
+	case sspi.SEC_I_COMPLETE_NEEDED, sspi.SEC_I_COMPLETE_AND_CONTINUE:
+		if ret := sspi.CompleteAuthToken(auth.context.Handle, outBufs); ret != sspi.SEC_E_OK {
+			return nil, fmt.Errorf("NTLM authentication failed: %w", ret)
+		}
+	default:
+		return nil, fmt.Errorf("NTLM authentication failed: %w", ret)
+	}
+	return token[:outBuf[0].BufferSize], nil
+}
+
+func (auth *ntlmAuthenticator) release() {
+	_ = auth.context.Release()
+	_ = auth.credentials.Release()
+}
@@ -0,0 +1,113 @@
+package network
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+)
+
+// NeedsRelay returns true if the cluster cannot use the proxy of the host.
+// OpenShift only supports HTTP proxies with basic authentication, the
+// instance uses the relay of the daemon to reach the other ones.
+func (p *ProxyConfig) NeedsRelay() bool {
+	return p.UsesSOCKS() || (p.IsEnabled() && p.authenticatesOnHost())
+}
+
+// ForInstance returns the proxy configuration of the instance, the proxies
+// it cannot use are replaced by relayURL
+func (p *ProxyConfig) ForInstance(relayURL string) *ProxyConfig {
+	config := *p
+	config.noProxy = append([]string{}, p.noProxy...)
+	if isSOCKSProxy(config.HTTPProxy) || (config.HTTPProxy != "" && p.authenticatesOnHost()) {
+		config.HTTPProxy = relayURL
+	}
+	if isSOCKSProxy(config.HTTPSProxy) || (config.HTTPSProxy != "" && p.authenticatesOnHost()) {
+		config.HTTPSProxy = relayURL
+	}
+	return &config
+}
+
+type proxyRelay struct {
+	proxy     *ProxyConfig
+	transport http.RoundTripper
+}
+
+// NewProxyRelay returns an HTTP proxy which sends the requests it receives
+// to the proxy of the configuration. The instance uses it as the proxy of the
+// cluster when OpenShift cannot use the proxy of the host.
+func NewProxyRelay(proxy *ProxyConfig) http.Handler {
+	return &proxyRelay{
+		proxy:     proxy,
+		transport: proxy.HTTPTransport(),
+	}
+}
+
+func (relay *proxyRelay) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodConnect {
+		relay.tunnel(w, req)
+		return
+	}
+	if !req.URL.IsAbs() {
+		http.Error(w, "The relay only accepts proxy requests", http.StatusBadRequest)
+		return
+	}
+	outReq := req.Clone(req.Context())
+	outReq.RequestURI = ""
+	outReq.Header.Del("Proxy-Connection")
+	outReq.Header.Del("Proxy-Authorization")
+	resp, err := relay.transport.RoundTrip(outReq)
+	if err != nil {
+		logging.Debugf("Cannot relay the request to %s: %v", req.URL.Host, err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+func (relay *proxyRelay) tunnel(w http.ResponseWriter, req *http.Request) {
+	conn, err := relay.dial(req.Context(), req.Host)
+	if err != nil {
+		logging.Debugf("Cannot relay the connection to %s: %v", req.Host, err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		conn.Close()
+		http.Error(w, "The relay cannot take over the connection", http.StatusInternalServerError)
+		return
+	}
+	clientConn, buf, err := hijacker.Hijack()
+	if err != nil {
+		conn.Close()
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err := clientConn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n")); err != nil {
+		conn.Close()
+		clientConn.Close()
+		return
+	}
+	go func() {
+		defer conn.Close()
+		_, _ = io.Copy(conn, buf.Reader)
+	}()
+	defer clientConn.Close()
+	_, _ = io.Copy(clientConn, conn)
+}
+
+// dial connects to address with the proxy the configuration uses for HTTPS
+func (relay *proxyRelay) dial(ctx context.Context, address string) (net.Conn, error) {
+	return relay.proxy.dialThroughProxy(ctx, &url.URL{Scheme: "https", Host: address})
+}
@@ -0,0 +1,31 @@
+package network
+
+import (
+	"bufio"
+	"strings"
+)
+
+// parseIPRouteGet returns the interface of the output of 'ip route get', like
+// '192.168.130.11 dev crc src 192.168.130.1 uid 1000'
+func parseIPRouteGet(output string) string {
+	fields := strings.Fields(output)
+	for i := 0; i < len(fields)-1; i++ {
+		if fields[i] == "dev" {
+			return fields[i+1]
+		}
+	}
+	return ""
+}
+
+// parseRouteGet returns the interface of the output of 'route -n get' on
+// macOS, it has an 'interface: en0' line
+func parseRouteGet(output string) string {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.SplitN(strings.TrimSpace(scanner.Text()), ":", 2)
+		if len(fields) == 2 && fields[0] == "interface" {
+			return strings.TrimSpace(fields[1])
+		}
+	}
+	return ""
+}
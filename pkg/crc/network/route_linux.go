@@ -0,0 +1,14 @@
+package network
+
+import (
+	crcos "github.com/code-ready/crc/pkg/os"
+)
+
+// RouteInterface returns the interface used by the host to reach ip
+func RouteInterface(ip string) (string, error) {
+	stdout, _, err := crcos.RunWithDefaultLocale("ip", "route", "get", ip)
+	if err != nil {
+		return "", err
+	}
+	return parseIPRouteGet(stdout), nil
+}
@@ -0,0 +1,17 @@
+package network
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/os/windows/powershell"
+)
+
+// RouteInterface returns the interface used by the host to reach ip
+func RouteInterface(ip string) (string, error) {
+	stdout, _, err := powershell.Execute(fmt.Sprintf(`(Find-NetRoute -RemoteIPAddress '%s' | Where-Object { $_.InterfaceAlias } | Select-Object -First 1).InterfaceAlias`, ip))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(stdout), nil
+}
@@ -0,0 +1,186 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	socks5Version      = 0x05
+	socks5NoAuth       = 0x00
+	socks5UserPassword = 0x02
+	socks5NoAcceptable = 0xff
+	socks5Connect      = 0x01
+	socks5IPv4         = 0x01
+	socks5DomainName   = 0x03
+	socks5IPv6         = 0x04
+
+	socks5DefaultPort = "1080"
+)
+
+var socks5Replies = map[byte]string{
+	0x01: "general SOCKS server failure",
+	0x02: "connection not allowed by ruleset",
+	0x03: "network unreachable",
+	0x04: "host unreachable",
+	0x05: "connection refused",
+	0x06: "TTL expired",
+	0x07: "command not supported",
+	0x08: "address type not supported",
+}
+
+func isSOCKSProxy(proxyURL string) bool {
+	return strings.HasPrefix(proxyURL, "socks5://")
+}
+
+func validateSOCKSProxyURL(proxyURL string) error {
+	u, err := url.Parse(proxyURL)
+	if err != nil || u.Hostname() == "" || (u.Path != "" && u.Path != "/") {
+		return fmt.Errorf("Proxy URL '%s' is not valid", proxyURL)
+	}
+	return nil
+}
+
+// UsesSOCKS returns true if the HTTP or the HTTPS proxy is a SOCKS5 proxy
+func (p *ProxyConfig) UsesSOCKS() bool {
+	return isSOCKSProxy(p.HTTPProxy) || isSOCKSProxy(p.HTTPSProxy)
+}
+
+// dialSOCKS5 connects to address through the SOCKS5 proxy at proxyURL, with
+// the username and password of proxyURL when it has them
+func dialSOCKS5(ctx context.Context, proxyURL *url.URL, address string) (net.Conn, error) {
+	proxyAddress := proxyURL.Host
+	if proxyURL.Port() == "" {
+		proxyAddress = net.JoinHostPort(proxyURL.Hostname(), socks5DefaultPort)
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", proxyAddress)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer func() {
+			_ = conn.SetDeadline(time.Time{})
+		}()
+	}
+	if err := socks5Handshake(conn, proxyURL.User, address); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func socks5Handshake(conn io.ReadWriter, user *url.Userinfo, address string) error {
+	host, portString, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return fmt.Errorf("Invalid port in %s", address)
+	}
+
+	methods := []byte{socks5NoAuth}
+	if user != nil {
+		methods = append(methods, socks5UserPassword)
+	}
+	if _, err := conn.Write(append([]byte{socks5Version, byte(len(methods))}, methods...)); err != nil {
+		return err
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[0] != socks5Version {
+		return fmt.Errorf("Unexpected SOCKS version %d", reply[0])
+	}
+	switch reply[1] {
+	case socks5NoAuth:
+	case socks5UserPassword:
+		if user == nil {
+			return fmt.Errorf("The SOCKS5 proxy requires a username and a password")
+		}
+		if err := socks5Authenticate(conn, user); err != nil {
+			return err
+		}
+	case socks5NoAcceptable:
+		return fmt.Errorf("The SOCKS5 proxy does not accept the authentication methods of crc")
+	default:
+		return fmt.Errorf("Unexpected SOCKS5 authentication method %d", reply[1])
+	}
+
+	request := []byte{socks5Version, socks5Connect, 0x00}
+	ip := net.ParseIP(host)
+	switch {
+	case ip.To4() != nil:
+		request = append(append(request, socks5IPv4), ip.To4()...)
+	case ip != nil:
+		request = append(append(request, socks5IPv6), ip.To16()...)
+	case len(host) > 255:
+		return fmt.Errorf("Host name %s is too long for SOCKS5", host)
+	default:
+		request = append(append(request, socks5DomainName, byte(len(host))), host...)
+	}
+	request = append(request, byte(port>>8), byte(port))
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return err
+	}
+	if header[1] != 0x00 {
+		message, ok := socks5Replies[header[1]]
+		if !ok {
+			message = fmt.Sprintf("unknown error %d", header[1])
+		}
+		return fmt.Errorf("The SOCKS5 proxy cannot connect to %s: %s", address, message)
+	}
+	// skip the address bound by the proxy and its port
+	var length int
+	switch header[3] {
+	case socks5IPv4:
+		length = net.IPv4len
+	case socks5IPv6:
+		length = net.IPv6len
+	case socks5DomainName:
+		size := make([]byte, 1)
+		if _, err := io.ReadFull(conn, size); err != nil {
+			return err
+		}
+		length = int(size[0])
+	default:
+		return fmt.Errorf("Unexpected SOCKS5 address type %d", header[3])
+	}
+	_, err = io.ReadFull(conn, make([]byte, length+2))
+	return err
+}
+
+func socks5Authenticate(conn io.ReadWriter, user *url.Userinfo) error {
+	username := user.Username()
+	password, _ := user.Password()
+	if len(username) > 255 || len(password) > 255 {
+		return fmt.Errorf("The SOCKS5 username and password must be shorter than 256 bytes")
+	}
+	request := append([]byte{0x01, byte(len(username))}, username...)
+	request = append(append(request, byte(len(password))), password...)
+	if _, err := conn.Write(request); err != nil {
+		return err
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[1] != 0x00 {
+		return fmt.Errorf("The SOCKS5 proxy rejected the username and password")
+	}
+	return nil
+}
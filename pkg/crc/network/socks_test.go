@@ -0,0 +1,71 @@
+package network
+
+import (
+	"io"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSOCKS5Server accepts the username and password 'user:password' and
+// records the address of the connect request
+func fakeSOCKS5Server(t *testing.T, conn net.Conn, reply byte) <-chan []byte {
+	requests := make(chan []byte, 1)
+	go func() {
+		defer conn.Close()
+		greeting := make([]byte, 4)
+		if _, err := io.ReadFull(conn, greeting); err != nil {
+			return
+		}
+		assert.Equal(t, []byte{socks5Version, 2, socks5NoAuth, socks5UserPassword}, greeting)
+		_, _ = conn.Write([]byte{socks5Version, socks5UserPassword})
+
+		auth := make([]byte, 1+1+4+1+8)
+		if _, err := io.ReadFull(conn, auth); err != nil {
+			return
+		}
+		assert.Equal(t, "\x01\x04user\x08password", string(auth))
+		_, _ = conn.Write([]byte{0x01, 0x00})
+
+		request := make([]byte, 4+1+len("example.com")+2)
+		if _, err := io.ReadFull(conn, request); err != nil {
+			return
+		}
+		requests <- request
+		_, _ = conn.Write([]byte{socks5Version, reply, 0x00, socks5IPv4, 10, 0, 0, 1, 0x1f, 0x90})
+	}()
+	return requests
+}
+
+func TestSOCKS5Handshake(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	requests := fakeSOCKS5Server(t, server, 0x00)
+
+	require.NoError(t, socks5Handshake(client, url.UserPassword("user", "password"), "example.com:443"))
+	assert.Equal(t, append([]byte{socks5Version, socks5Connect, 0x00, socks5DomainName, 11}, "example.com\x01\xbb"...), <-requests)
+}
+
+func TestSOCKS5HandshakeRefused(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	_ = fakeSOCKS5Server(t, server, 0x05)
+
+	assert.EqualError(t, socks5Handshake(client, url.UserPassword("user", "password"), "example.com:443"),
+		"The SOCKS5 proxy cannot connect to example.com:443: connection refused")
+}
+
+func TestProxyConfigForInstance(t *testing.T) {
+	proxy := &ProxyConfig{HTTPProxy: "http://proxy.example.com:3128", HTTPSProxy: "socks5://proxy.example.com:1080"}
+	assert.True(t, proxy.UsesSOCKS())
+
+	instanceProxy := proxy.ForInstance("http://192.168.127.254:3128")
+	assert.False(t, instanceProxy.UsesSOCKS())
+	assert.Equal(t, "http://proxy.example.com:3128", instanceProxy.HTTPProxy)
+	assert.Equal(t, "http://192.168.127.254:3128", instanceProxy.HTTPSProxy)
+	assert.Equal(t, "socks5://proxy.example.com:1080", proxy.HTTPSProxy)
+	assert.False(t, (&ProxyConfig{HTTPProxy: "http://proxy.example.com:3128"}).UsesSOCKS())
+}
@@ -9,6 +9,7 @@ const (
 	resolvFileTemplate = `# Generated by CRC
 {{ range .SearchDomains }}search {{ .Domain }}{{ end }}
 {{ range .NameServers }}nameserver {{ .IPAddress }}
+{{ end }}{{ if .Options }}options{{ range .Options }} {{ . }}{{ end }}
 {{ end }}
 `
 )
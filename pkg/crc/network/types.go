@@ -18,6 +18,7 @@ type SearchDomain struct {
 type ResolvFileValues struct {
 	SearchDomains []SearchDomain
 	NameServers   []NameServer
+	Options       []string
 }
 
 type Mode string
@@ -0,0 +1,193 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+type UpstreamProtocol string
+
+const (
+	// plain DNS over UDP port 53
+	PlainDNS UpstreamProtocol = "dns"
+	// DNS-over-TLS, RFC 7858
+	DNSOverTLS UpstreamProtocol = "tls"
+	// DNS-over-HTTPS, RFC 8484
+	DNSOverHTTPS UpstreamProtocol = "https"
+
+	dnsOverTLSPort = "853"
+)
+
+// Upstream is a nameserver used to resolve the names which are not handled
+// by crc
+type Upstream struct {
+	Protocol UpstreamProtocol
+	// Address is the IP:port of the nameserver, for plain DNS and DNS-over-TLS
+	Address string
+	// ServerName is used to verify the certificate of a DNS-over-TLS nameserver
+	ServerName string
+	// URL is the URL of a DNS-over-HTTPS nameserver
+	URL string
+}
+
+// ParseNameServers parses a comma-separated list of nameservers. Each of them
+// can be an IP address, tls://<IP address>[:port][#server name] or an
+// https:// URL. IPv6 addresses must be in brackets when a port is given.
+func ParseNameServers(value string) ([]Upstream, error) {
+	var upstreams []Upstream
+	for _, spec := range strings.Split(value, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		upstream, err := parseUpstream(spec)
+		if err != nil {
+			return nil, err
+		}
+		upstreams = append(upstreams, upstream)
+	}
+	return upstreams, nil
+}
+
+func parseUpstream(spec string) (Upstream, error) {
+	switch {
+	case strings.HasPrefix(spec, "tls://"):
+		address := strings.TrimPrefix(spec, "tls://")
+		var serverName string
+		if i := strings.Index(address, "#"); i >= 0 {
+			serverName = address[i+1:]
+			address = address[:i]
+		}
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			host, port = strings.TrimSuffix(strings.TrimPrefix(address, "["), "]"), dnsOverTLSPort
+		}
+		if !isIPAddress(host) {
+			return Upstream{}, fmt.Errorf("Invalid DNS-over-TLS nameserver '%s', expected tls://<IP address>[:port][#server name]", spec)
+		}
+		if serverName == "" {
+			serverName = host
+		}
+		return Upstream{
+			Protocol:   DNSOverTLS,
+			Address:    net.JoinHostPort(host, port),
+			ServerName: serverName,
+		}, nil
+	case strings.HasPrefix(spec, "https://"):
+		u, err := url.Parse(spec)
+		if err != nil || u.Host == "" {
+			return Upstream{}, fmt.Errorf("Invalid DNS-over-HTTPS nameserver '%s'", spec)
+		}
+		return Upstream{
+			Protocol: DNSOverHTTPS,
+			URL:      spec,
+		}, nil
+	default:
+		if !isIPAddress(spec) {
+			return Upstream{}, fmt.Errorf("Invalid nameserver '%s', expected an IP address, tls://<IP address> or https://<url>", spec)
+		}
+		return Upstream{
+			Protocol: PlainDNS,
+			Address:  net.JoinHostPort(spec, "53"),
+		}, nil
+	}
+}
+
+func isIPAddress(value string) bool {
+	return net.ParseIP(value) != nil
+}
+
+// IsEncrypted returns true for DNS-over-TLS and DNS-over-HTTPS nameservers
+func (u Upstream) IsEncrypted() bool {
+	return u.Protocol != PlainDNS
+}
+
+// IsIPv6 returns true for the plain DNS and DNS-over-TLS nameservers with an
+// IPv6 address, the instance can only reach them through the DNS forwarder of
+// the daemon
+func (u Upstream) IsIPv6() bool {
+	if u.Protocol == DNSOverHTTPS {
+		return false
+	}
+	host, _, _ := net.SplitHostPort(u.Address)
+	ip := net.ParseIP(host)
+	return ip != nil && ip.To4() == nil
+}
+
+// NameServer returns the nameserver to add to resolv.conf for a plain DNS
+// upstream
+func (u Upstream) NameServer() NameServer {
+	host, _, _ := net.SplitHostPort(u.Address)
+	return NameServer{IPAddress: host}
+}
+
+func (u Upstream) String() string {
+	switch u.Protocol {
+	case DNSOverTLS:
+		return fmt.Sprintf("tls://%s#%s", u.Address, u.ServerName)
+	case DNSOverHTTPS:
+		return u.URL
+	default:
+		return u.NameServer().IPAddress
+	}
+}
+
+// Exchange sends the DNS query to the upstream nameserver and returns its reply
+func (u Upstream) Exchange(ctx context.Context, query *dns.Msg, transport http.RoundTripper) (*dns.Msg, error) {
+	switch u.Protocol {
+	case DNSOverHTTPS:
+		return u.exchangeHTTPS(ctx, query, transport)
+	case DNSOverTLS:
+		client := &dns.Client{
+			Net: "tcp-tls",
+			TLSConfig: &tls.Config{
+				ServerName: u.ServerName,
+				MinVersion: tls.VersionTLS12,
+			},
+		}
+		reply, _, err := client.ExchangeContext(ctx, query, u.Address)
+		return reply, err
+	default:
+		reply, _, err := new(dns.Client).ExchangeContext(ctx, query, u.Address)
+		return reply, err
+	}
+}
+
+func (u Upstream) exchangeHTTPS(ctx context.Context, query *dns.Msg, transport http.RoundTripper) (*dns.Msg, error) {
+	packed, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.URL, bytes.NewReader(packed))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", u.URL, resp.Status)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	reply := new(dns.Msg)
+	if err := reply.Unpack(body); err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
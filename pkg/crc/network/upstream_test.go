@@ -0,0 +1,175 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNameServers(t *testing.T) {
+	upstreams, err := ParseNameServers("1.1.1.1, tls://9.9.9.9#dns.quad9.net,tls://1.0.0.1:8853,https://cloudflare-dns.com/dns-query")
+	require.NoError(t, err)
+	assert.Equal(t, []Upstream{
+		{Protocol: PlainDNS, Address: "1.1.1.1:53"},
+		{Protocol: DNSOverTLS, Address: "9.9.9.9:853", ServerName: "dns.quad9.net"},
+		{Protocol: DNSOverTLS, Address: "1.0.0.1:8853", ServerName: "1.0.0.1"},
+		{Protocol: DNSOverHTTPS, URL: "https://cloudflare-dns.com/dns-query"},
+	}, upstreams)
+	assert.Equal(t, NameServer{IPAddress: "1.1.1.1"}, upstreams[0].NameServer())
+	assert.False(t, upstreams[0].IsEncrypted())
+	assert.True(t, upstreams[1].IsEncrypted())
+
+	upstreams, err = ParseNameServers("")
+	assert.NoError(t, err)
+	assert.Empty(t, upstreams)
+
+	_, err = ParseNameServers("1.1.1.1,dns.google")
+	assert.EqualError(t, err, "Invalid nameserver 'dns.google', expected an IP address, tls://<IP address> or https://<url>")
+	_, err = ParseNameServers("tls://dns.google")
+	assert.EqualError(t, err, "Invalid DNS-over-TLS nameserver 'tls://dns.google', expected tls://<IP address>[:port][#server name]")
+}
+
+func TestParseIPv6NameServers(t *testing.T) {
+	upstreams, err := ParseNameServers("2606:4700:4700::1111,tls://2620:fe::fe#dns.quad9.net,tls://[2620:fe::9]:8853,tls://[2001:4860:4860::8888]")
+	require.NoError(t, err)
+	assert.Equal(t, []Upstream{
+		{Protocol: PlainDNS, Address: "[2606:4700:4700::1111]:53"},
+		{Protocol: DNSOverTLS, Address: "[2620:fe::fe]:853", ServerName: "dns.quad9.net"},
+		{Protocol: DNSOverTLS, Address: "[2620:fe::9]:8853", ServerName: "2620:fe::9"},
+		{Protocol: DNSOverTLS, Address: "[2001:4860:4860::8888]:853", ServerName: "2001:4860:4860::8888"},
+	}, upstreams)
+	assert.Equal(t, NameServer{IPAddress: "2606:4700:4700::1111"}, upstreams[0].NameServer())
+	assert.Equal(t, "2606:4700:4700::1111", upstreams[0].String())
+	assert.True(t, upstreams[0].IsIPv6())
+	assert.True(t, upstreams[1].IsIPv6())
+
+	upstreams, err = ParseNameServers("1.1.1.1,tls://1.0.0.1,https://cloudflare-dns.com/dns-query")
+	require.NoError(t, err)
+	for _, upstream := range upstreams {
+		assert.False(t, upstream.IsIPv6())
+	}
+}
+
+func TestDNSOverHTTPSExchange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/dns-message", r.Header.Get("Content-Type"))
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		query := new(dns.Msg)
+		require.NoError(t, query.Unpack(body))
+		reply := new(dns.Msg)
+		reply.SetReply(query)
+		reply.Answer = append(reply.Answer, aRecord(query.Question[0].Name, net.ParseIP("10.0.0.1").To4()))
+		packed, err := reply.Pack()
+		require.NoError(t, err)
+		_, _ = w.Write(packed)
+	}))
+	defer server.Close()
+
+	query := new(dns.Msg)
+	query.SetQuestion("quay.io.", dns.TypeA)
+	upstream := Upstream{Protocol: DNSOverHTTPS, URL: server.URL}
+	reply, err := upstream.Exchange(context.Background(), query, http.DefaultTransport)
+	require.NoError(t, err)
+	require.Len(t, reply.Answer, 1)
+	assert.Equal(t, "10.0.0.1", reply.Answer[0].(*dns.A).A.String())
+}
+
+func TestDNSForwarder(t *testing.T) {
+	var forwarded []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		query := new(dns.Msg)
+		require.NoError(t, query.Unpack(body))
+		forwarded = append(forwarded, query.Question[0].Name)
+		reply := new(dns.Msg)
+		reply.SetRcode(query, dns.RcodeNameError)
+		packed, _ := reply.Pack()
+		_, _ = w.Write(packed)
+	}))
+	defer server.Close()
+
+	forwarder := &DNSForwarder{
+		Zones: []types.Zone{
+			{
+				Name:      "apps-crc.testing.",
+				DefaultIP: net.ParseIP("192.168.127.2"),
+			},
+			{
+				Name: "crc.testing.",
+				Records: []types.Record{
+					{Name: "host", IP: net.ParseIP("192.168.127.254")},
+					{Regexp: regexp.MustCompile("crc-(.*?)-master-0"), IP: net.ParseIP("192.168.126.11")},
+				},
+			},
+		},
+		Upstreams: func() []Upstream {
+			return []Upstream{{Protocol: DNSOverHTTPS, URL: server.URL}}
+		},
+		Transport: http.DefaultTransport,
+	}
+
+	lookup := func(name string) *dns.Msg {
+		query := new(dns.Msg)
+		query.SetQuestion(name, dns.TypeA)
+		return forwarder.reply(query)
+	}
+
+	assert.Equal(t, "192.168.127.2", lookup("foo.apps-crc.testing.").Answer[0].(*dns.A).A.String())
+	assert.Equal(t, "192.168.127.254", lookup("host.crc.testing.").Answer[0].(*dns.A).A.String())
+	assert.Equal(t, "192.168.126.11", lookup("crc-abcde-master-0.crc.testing.").Answer[0].(*dns.A).A.String())
+	assert.Equal(t, dns.RcodeNameError, lookup("unknown.crc.testing.").Rcode)
+	assert.Empty(t, forwarded)
+
+	// the crc zones only have IPv4 addresses, AAAA queries get an empty
+	// answer so that dual-stack clients use the A records
+	query := new(dns.Msg)
+	query.SetQuestion("foo.apps-crc.testing.", dns.TypeAAAA)
+	reply := forwarder.reply(query)
+	assert.Equal(t, dns.RcodeSuccess, reply.Rcode)
+	assert.Empty(t, reply.Answer)
+
+	assert.Equal(t, dns.RcodeNameError, lookup("example.com.").Rcode)
+	assert.Equal(t, []string{"example.com."}, forwarded)
+}
+
+func TestHostResolverReplyAAAA(t *testing.T) {
+	query := new(dns.Msg)
+	query.SetQuestion("localhost.", dns.TypeAAAA)
+	reply := hostResolverReply(context.Background(), query)
+	for _, answer := range reply.Answer {
+		assert.IsType(t, &dns.AAAA{}, answer)
+	}
+}
+
+func TestCreateResolvFileWithOptions(t *testing.T) {
+	resolvFile, err := CreateResolvFile(ResolvFileValues{
+		SearchDomains: []SearchDomain{{Domain: "crc.testing"}},
+		NameServers:   []NameServer{{IPAddress: "192.168.127.254"}},
+		Options:       []string{"use-vc"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "# Generated by CRC\nsearch crc.testing\nnameserver 192.168.127.254\noptions use-vc\n\n", resolvFile)
+}
+
+func TestHostResolverRcode(t *testing.T) {
+	question := func(qtype uint16) dns.Question {
+		return dns.Question{Name: "localhost", Qtype: qtype, Qclass: dns.ClassINET}
+	}
+	notFound := &net.DNSError{Err: "no such host", Name: "localhost", IsNotFound: true}
+
+	assert.Equal(t, dns.RcodeServerFailure, hostResolverRcode(context.Background(), question(dns.TypeA), errors.New("i/o timeout")))
+	assert.Equal(t, dns.RcodeNameError, hostResolverRcode(context.Background(), question(dns.TypeA), notFound))
+	// localhost has an address, it only has no MX record
+	assert.Equal(t, dns.RcodeSuccess, hostResolverRcode(context.Background(), question(dns.TypeMX), notFound))
+}
@@ -0,0 +1,53 @@
+package network
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"strconv"
+	"unsafe"
+
+	"github.com/containers/gvisor-tap-vsock/pkg/virtualnetwork"
+	"gvisor.dev/gvisor/pkg/tcpip"
+	"gvisor.dev/gvisor/pkg/tcpip/adapters/gonet"
+	"gvisor.dev/gvisor/pkg/tcpip/network/ipv4"
+	"gvisor.dev/gvisor/pkg/tcpip/stack"
+)
+
+// ListenUDP listens on an UDP address of the virtual network. The virtual
+// network only has TCP listeners, the UDP endpoint is bound on its network
+// stack, in the same way as the DNS server of its gateway.
+func ListenUDP(vn *virtualnetwork.VirtualNetwork, addr string) (net.PacketConn, error) {
+	host, portString, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.ParseUint(portString, 10, 16)
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(host).To4()
+	if ip == nil {
+		return nil, errors.New("invalid address, must be an IPv4 address")
+	}
+	s, err := networkStack(vn)
+	if err != nil {
+		return nil, err
+	}
+	return gonet.DialUDP(s, &tcpip.FullAddress{
+		NIC:  1,
+		Addr: tcpip.Address(ip),
+		Port: uint16(port),
+	}, nil, ipv4.ProtocolNumber)
+}
+
+// networkStack returns the network stack of the virtual network, which is a
+// private field of VirtualNetwork
+func networkStack(vn *virtualnetwork.VirtualNetwork) (*stack.Stack, error) {
+	field := reflect.ValueOf(vn).Elem().FieldByName("stack")
+	if !field.IsValid() || field.Type() != reflect.TypeOf(&stack.Stack{}) {
+		return nil, errors.New("cannot find the network stack of the virtual network")
+	}
+	// #nosec G103
+	return *(**stack.Stack)(unsafe.Pointer(field.UnsafeAddr())), nil
+}
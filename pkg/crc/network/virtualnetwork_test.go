@@ -0,0 +1,30 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
+	"github.com/containers/gvisor-tap-vsock/pkg/virtualnetwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenUDP(t *testing.T) {
+	vn, err := virtualnetwork.New(&types.Configuration{
+		MTU:               1500,
+		Subnet:            "192.168.127.0/24",
+		GatewayIP:         "192.168.127.1",
+		GatewayMacAddress: "5a:94:ef:e4:0c:dd",
+		GatewayVirtualIPs: []string{"192.168.127.254"},
+		Protocol:          types.HyperKitProtocol,
+	})
+	require.NoError(t, err)
+
+	conn, err := ListenUDP(vn, "192.168.127.254:53")
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, "192.168.127.254:53", conn.LocalAddr().String())
+
+	_, err = ListenUDP(vn, "localhost:53")
+	assert.Error(t, err)
+}
@@ -0,0 +1,166 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"unicode"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+)
+
+// ConflictKind is the part of the network of the host which breaks the access
+// to the instance
+type ConflictKind string
+
+const (
+	RouteConflict ConflictKind = "route"
+	DNSConflict   ConflictKind = "dns"
+)
+
+// Conflict is a change of the network of the host, usually made by a VPN
+// client, which breaks the access to the instance
+type Conflict struct {
+	Kind        ConflictKind `json:"kind"`
+	Interface   string       `json:"interface,omitempty"`
+	Description string       `json:"description"`
+}
+
+// VPNInterface is a network interface created by a VPN client
+type VPNInterface struct {
+	Name      string   `json:"name"`
+	Addresses []string `json:"addresses"`
+}
+
+// vpnInterfacePrefixes are the names of the interfaces of the VPN clients on
+// Linux and macOS, followed by a number
+var vpnInterfacePrefixes = []string{"tun", "utun", "wg", "ppp", "cscotun", "gpd", "ipsec", "tailscale", "nordlynx", "vpn"}
+
+// vpnInterfaceKeywords are found in the names of the interfaces of the VPN
+// clients on Windows
+var vpnInterfaceKeywords = []string{"vpn", "anyconnect", "globalprotect", "pangp", "fortinet", "forticlient", "wireguard", "tap-windows", "openvpn", "juniper", "pulse secure", "zscaler", "wintun"}
+
+func isVPNInterfaceName(name string) bool {
+	name = strings.ToLower(name)
+	for _, prefix := range vpnInterfacePrefixes {
+		if strings.HasPrefix(name, prefix) && strings.IndexFunc(name[len(prefix):], func(r rune) bool { return !unicode.IsDigit(r) }) == -1 {
+			return true
+		}
+	}
+	for _, keyword := range vpnInterfaceKeywords {
+		if strings.Contains(name, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// VPNInterfaces returns the interfaces of the VPN clients which are up and
+// have an IPv4 address. macOS always has utun interfaces, they only have IPv6
+// link-local addresses when no VPN uses them.
+func VPNInterfaces() ([]VPNInterface, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	var vpns []VPNInterface
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || !isVPNInterfaceName(iface.Name) {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			logging.Debugf("Cannot get the addresses of %s: %v", iface.Name, err)
+			continue
+		}
+		vpn := VPNInterface{Name: iface.Name}
+		for _, addr := range addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.To4() != nil && !ipNet.IP.IsLinkLocalUnicast() {
+				vpn.Addresses = append(vpn.Addresses, ipNet.String())
+			}
+		}
+		if len(vpn.Addresses) > 0 {
+			vpns = append(vpns, vpn)
+		}
+	}
+	return vpns, nil
+}
+
+// DetectConflicts checks that the routes of the host send the traffic of the
+// instance at ip to the instance, in the system network mode, and that the
+// host resolves hostname to ip
+func DetectConflicts(mode Mode, ip string, hostname string) []Conflict {
+	var conflicts []Conflict
+	if mode == SystemNetworkingMode {
+		conflicts = RouteConflicts(ip)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), resolveTimeout)
+	defer cancel()
+	addresses, err := net.DefaultResolver.LookupHost(ctx, hostname)
+	return append(conflicts, dnsConflicts(ip, hostname, addresses, err)...)
+}
+
+// RouteConflicts returns the VPN clients which route the traffic to ip away
+// from the instance, the user network mode does not use these routes
+func RouteConflicts(ip string) []Conflict {
+	vpns, err := VPNInterfaces()
+	if err != nil {
+		logging.Debugf("Cannot list the network interfaces: %v", err)
+	}
+	routeIface, err := RouteInterface(ip)
+	if err != nil {
+		logging.Debugf("Cannot get the route to %s: %v", ip, err)
+	}
+	return routeConflicts(ip, vpns, routeIface)
+}
+
+func routeConflicts(ip string, vpns []VPNInterface, routeIface string) []Conflict {
+	var conflicts []Conflict
+	instanceIP := net.ParseIP(ip)
+	for _, vpn := range vpns {
+		for _, address := range vpn.Addresses {
+			if _, subnet, err := net.ParseCIDR(address); err == nil && subnet.Contains(instanceIP) {
+				conflicts = append(conflicts, Conflict{
+					Kind:        RouteConflict,
+					Interface:   vpn.Name,
+					Description: fmt.Sprintf("The VPN interface %s uses the network %s of the instance", vpn.Name, subnet),
+				})
+			}
+		}
+	}
+	if routeIface != "" && isVPNInterfaceName(routeIface) {
+		conflicts = append(conflicts, Conflict{
+			Kind:        RouteConflict,
+			Interface:   routeIface,
+			Description: fmt.Sprintf("The traffic to the instance at %s is routed through the VPN interface %s", ip, routeIface),
+		})
+	}
+	return conflicts
+}
+
+func dnsConflicts(ip string, hostname string, addresses []string, lookupErr error) []Conflict {
+	switch {
+	case lookupErr != nil:
+		return []Conflict{{
+			Kind:        DNSConflict,
+			Description: fmt.Sprintf("The host cannot resolve %s: %v", hostname, lookupErr),
+		}}
+	case !contains(addresses, ip):
+		return []Conflict{{
+			Kind:        DNSConflict,
+			Description: fmt.Sprintf("The host resolves %s to %s instead of %s", hostname, strings.Join(addresses, ", "), ip),
+		}}
+	default:
+		return nil
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package network
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsVPNInterfaceName(t *testing.T) {
+	for _, name := range []string{"tun0", "utun3", "wg0", "cscotun0", "tailscale0", "nordlynx", "Cisco AnyConnect Secure Mobility Client Connection", "PANGP Virtual Ethernet Adapter"} {
+		assert.True(t, isVPNInterfaceName(name), name)
+	}
+	for _, name := range []string{"eth0", "crc", "tunl0", "virbr0", "vEthernet (Default Switch)", "en0"} {
+		assert.False(t, isVPNInterfaceName(name), name)
+	}
+}
+
+func TestRouteConflicts(t *testing.T) {
+	vpns := []VPNInterface{{Name: "tun0", Addresses: []string{"192.168.128.5/22"}}}
+	assert.Empty(t, routeConflicts("192.168.130.11", nil, "crc"))
+	assert.Empty(t, routeConflicts("192.168.140.11", vpns, "crc"))
+	assert.Equal(t, []Conflict{
+		{Kind: RouteConflict, Interface: "tun0", Description: "The VPN interface tun0 uses the network 192.168.128.0/22 of the instance"},
+		{Kind: RouteConflict, Interface: "tun0", Description: "The traffic to the instance at 192.168.130.11 is routed through the VPN interface tun0"},
+	}, routeConflicts("192.168.130.11", vpns, "tun0"))
+}
+
+func TestDNSConflicts(t *testing.T) {
+	assert.Empty(t, dnsConflicts("127.0.0.1", "api.crc.testing", []string{"127.0.0.1"}, nil))
+	assert.Equal(t, []Conflict{
+		{Kind: DNSConflict, Description: "The host resolves api.crc.testing to 10.0.0.1 instead of 192.168.130.11"},
+	}, dnsConflicts("192.168.130.11", "api.crc.testing", []string{"10.0.0.1"}, nil))
+	assert.Equal(t, []Conflict{
+		{Kind: DNSConflict, Description: "The host cannot resolve api.crc.testing: no such host"},
+	}, dnsConflicts("127.0.0.1", "api.crc.testing", nil, errors.New("no such host")))
+}
+
+func TestParseRouteInterface(t *testing.T) {
+	assert.Equal(t, "crc", parseIPRouteGet("192.168.130.11 dev crc src 192.168.130.1 uid 1000 \n    cache \n"))
+	assert.Equal(t, "", parseIPRouteGet(""))
+	assert.Equal(t, "utun4", parseRouteGet(`   route to: 192.168.64.2
+destination: default
+       mask: default
+    gateway: 10.8.0.1
+  interface: utun4
+      flags: <UP,GATEWAY,DONE,STATIC,PRCLONING>
+`))
+}
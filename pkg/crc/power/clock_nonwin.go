@@ -0,0 +1,43 @@
+//go:build !windows
+// +build !windows
+
+package power
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	clockCheckInterval = 10 * time.Second
+	// the clocks also drift apart when the wall clock is adjusted, which is
+	// done in much smaller steps than a sleep of the host
+	minSleepDuration = 30 * time.Second
+)
+
+// watchClockJumps sends Resume when the host slept, without any notification
+// of the OS. Resume is sent up to 10 seconds after the host woke up, and
+// Suspend is never sent.
+func watchClockJumps(ctx context.Context, handle func(Event)) error {
+	ticker := time.NewTicker(clockCheckInterval)
+	defer ticker.Stop()
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			now := time.Now()
+			if sleptFor(last, now) >= minSleepDuration {
+				handle(Resume)
+			}
+			last = now
+		}
+	}
+}
+
+// sleptFor returns how long the host slept between start and end, the wall
+// clock goes on while the host sleeps but the monotonic clock stops
+func sleptFor(start, end time.Time) time.Duration {
+	return end.Round(0).Sub(start.Round(0)) - end.Sub(start)
+}
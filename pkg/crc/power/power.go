@@ -0,0 +1,123 @@
+package power
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+)
+
+// Event is a change of the power state of the host
+type Event string
+
+const (
+	// Suspend is sent before the host sleeps
+	Suspend Event = "suspend"
+	// Resume is sent after the host woke up
+	Resume Event = "resume"
+)
+
+// The host sleeps a few seconds after Suspend even when the instance is not
+// done, the host is not blocked by the flush of the disks past this timeout
+const flushTimeout = 4 * time.Second
+
+// Handler prepares the instance for the sleep of the host, as selected by the
+// host-sleep-action setting, and restores it when the host resumes: the clock
+// of the instance, which stood still during the sleep, is set and the
+// certificates which expired in the meantime are renewed.
+type Handler struct {
+	client machine.Client
+	config crcConfig.Storage
+	start  func(ctx context.Context) error
+
+	lock sync.Mutex
+	// stopped is true when the instance was stopped by the handler before
+	// the host slept, it is started again on resume
+	stopped bool
+}
+
+// NewHandler returns a handler which starts the instance again with start
+func NewHandler(client machine.Client, config crcConfig.Storage, start func(ctx context.Context) error) *Handler {
+	return &Handler{
+		client: client,
+		config: config,
+		start:  start,
+	}
+}
+
+// Handle acts on event, it returns when the instance is ready for the sleep
+// of the host
+func (h *Handler) Handle(event Event) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	logging.Debugf("The host received the %s power event", event)
+	switch event {
+	case Suspend:
+		h.suspend()
+	case Resume:
+		h.resume()
+	}
+}
+
+func (h *Handler) suspend() {
+	action := crcConfig.GetHostSleepAction(h.config)
+	if action == crcConfig.HostSleepNone {
+		return
+	}
+	running, err := h.client.IsRunning()
+	if err != nil || !running {
+		return
+	}
+	switch action {
+	case crcConfig.HostSleepSync:
+		done := make(chan error, 1)
+		go func() {
+			done <- h.client.FlushDisks()
+		}()
+		select {
+		case err := <-done:
+			if err != nil {
+				logging.Warnf("Cannot flush the disks of the instance before the host sleeps: %v", err)
+			}
+		case <-time.After(flushTimeout):
+			logging.Warnf("The disks of the instance were not flushed in %s, the host sleeps anyway", flushTimeout)
+		}
+	case crcConfig.HostSleepStop:
+		logging.Infof("Stopping the instance before the host sleeps (%s is set to %s)", crcConfig.HostSleepAction, action)
+		if _, err := h.client.Stop(types.StopConfig{Timeout: machine.DefaultStopTimeout}); err != nil {
+			logging.Errorf("Cannot stop the instance before the host sleeps: %v", err)
+			return
+		}
+		h.stopped = true
+	}
+}
+
+func (h *Handler) resume() {
+	ctx := context.Background()
+	if h.stopped {
+		h.stopped = false
+		logging.Info("Starting the instance stopped before the host slept")
+		if err := h.start(ctx); err != nil {
+			logging.Errorf("Cannot start the instance after the host resumed: %v", err)
+		}
+		return
+	}
+	running, err := h.client.IsRunning()
+	if err != nil || !running {
+		return
+	}
+	skew, err := h.client.SyncTime()
+	if err != nil {
+		logging.Warnf("Cannot set the clock of the instance after the host resumed: %v", err)
+	} else if skew != 0 {
+		logging.Infof("Set the clock of the instance, which was off by %s after the host resumed", skew.Round(time.Second))
+	}
+	if err := h.client.RenewExpiringCertificates(ctx); err != nil {
+		logging.Warnf("Cannot renew the certificates after the host resumed: %v", err)
+	}
+}
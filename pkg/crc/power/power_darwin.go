@@ -0,0 +1,12 @@
+package power
+
+import (
+	"context"
+)
+
+// Watch calls handle with the power events of the host until ctx is done.
+// The sleep notifications of macOS are only sent through IOKit, the sleep of
+// the host is detected from the jump of its wall clock, after it woke up.
+func Watch(ctx context.Context, handle func(Event)) error {
+	return watchClockJumps(ctx, handle)
+}
@@ -0,0 +1,91 @@
+package power
+
+import (
+	"context"
+	"errors"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/godbus/dbus/v5"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	login1Name      = "org.freedesktop.login1"
+	login1Path      = "/org/freedesktop/login1"
+	login1Interface = "org.freedesktop.login1.Manager"
+)
+
+// Watch calls handle with the power events of the host until ctx is done.
+// systemd-logind sends PrepareForSleep before the host sleeps and after it
+// woke up, and waits before the sleep until handle returned for Suspend, up
+// to InhibitDelayMaxSec (5 seconds by default) of logind.conf. When logind is
+// not available, the sleep of the host is detected from the jump of its wall
+// clock, after it woke up.
+func Watch(ctx context.Context, handle func(Event)) error {
+	conn, err := dbus.ConnectSystemBus()
+	if err != nil {
+		logging.Debugf("Cannot connect to the system bus, falling back to the clock to detect the sleep of the host: %v", err)
+		return watchClockJumps(ctx, handle)
+	}
+	defer conn.Close()
+
+	if err := conn.AddMatchSignal(
+		dbus.WithMatchObjectPath(login1Path),
+		dbus.WithMatchInterface(login1Interface),
+		dbus.WithMatchMember("PrepareForSleep"),
+	); err != nil {
+		logging.Debugf("Cannot subscribe to the sleep signals of logind, falling back to the clock to detect the sleep of the host: %v", err)
+		return watchClockJumps(ctx, handle)
+	}
+	signals := make(chan *dbus.Signal, 10)
+	conn.Signal(signals)
+
+	manager := conn.Object(login1Name, login1Path)
+	lock := inhibitSleep(manager)
+	defer func() {
+		releaseSleep(lock)
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case signal, ok := <-signals:
+			if !ok {
+				return errors.New("the connection to the system bus was closed")
+			}
+			if signal.Name != login1Interface+".PrepareForSleep" || len(signal.Body) != 1 {
+				continue
+			}
+			sleeping, _ := signal.Body[0].(bool)
+			if sleeping {
+				handle(Suspend)
+				releaseSleep(lock)
+				lock = -1
+			} else {
+				lock = inhibitSleep(manager)
+				handle(Resume)
+			}
+		}
+	}
+}
+
+// inhibitSleep takes a delay lock, the host does not sleep before it is
+// released or the delay passed. It returns -1 when the lock was not taken, the
+// host may then sleep before handle returned for Suspend.
+func inhibitSleep(manager dbus.BusObject) dbus.UnixFD {
+	var fd dbus.UnixFD
+	if err := manager.Call(login1Interface+".Inhibit", 0, "sleep", "crc", "Preparing the instance for the sleep of the host", "delay").Store(&fd); err != nil {
+		logging.Debugf("Cannot delay the sleep of the host: %v", err)
+		return -1
+	}
+	return fd
+}
+
+func releaseSleep(lock dbus.UnixFD) {
+	if lock < 0 {
+		return
+	}
+	if err := unix.Close(int(lock)); err != nil {
+		logging.Debugf("Cannot release the sleep lock: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package power
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingClient struct {
+	*fakemachine.Client
+	calls []string
+}
+
+func (c *recordingClient) Stop(stopConfig types.StopConfig) (state.State, error) {
+	c.calls = append(c.calls, "stop")
+	c.Stopped = true
+	return c.Client.Stop(stopConfig)
+}
+
+func (c *recordingClient) FlushDisks() error {
+	c.calls = append(c.calls, "flush")
+	return c.Client.FlushDisks()
+}
+
+func (c *recordingClient) SyncTime() (time.Duration, error) {
+	c.calls = append(c.calls, "sync-time")
+	return c.Client.SyncTime()
+}
+
+func (c *recordingClient) RenewExpiringCertificates(ctx context.Context) error {
+	c.calls = append(c.calls, "renew")
+	return c.Client.RenewExpiringCertificates(ctx)
+}
+
+func newTestHandler(t *testing.T, action string) (*Handler, *recordingClient) {
+	cfg := crcConfig.New(crcConfig.NewEmptyInMemoryStorage())
+	crcConfig.RegisterSettings(cfg)
+	if action != "" {
+		_, err := cfg.Set(crcConfig.HostSleepAction, action)
+		require.NoError(t, err)
+	}
+	client := &recordingClient{Client: fakemachine.NewClient()}
+	handler := NewHandler(client, cfg, func(ctx context.Context) error {
+		client.calls = append(client.calls, "start")
+		client.Stopped = false
+		return nil
+	})
+	return handler, client
+}
+
+func TestSuspendFlushesTheDisksByDefault(t *testing.T) {
+	handler, client := newTestHandler(t, "")
+	handler.Handle(Suspend)
+	handler.Handle(Resume)
+	assert.Equal(t, []string{"flush", "sync-time", "renew"}, client.calls)
+}
+
+func TestSuspendWithNoneAction(t *testing.T) {
+	handler, client := newTestHandler(t, crcConfig.HostSleepNone)
+	handler.Handle(Suspend)
+	handler.Handle(Resume)
+	assert.Equal(t, []string{"sync-time", "renew"}, client.calls)
+}
+
+func TestSuspendStopsAndResumeStartsTheInstance(t *testing.T) {
+	handler, client := newTestHandler(t, crcConfig.HostSleepStop)
+	handler.Handle(Suspend)
+	assert.True(t, client.Stopped)
+	handler.Handle(Resume)
+	assert.False(t, client.Stopped)
+	assert.Equal(t, []string{"stop", "start"}, client.calls)
+
+	// the instance stopped by the user is not started on resume
+	client.calls = nil
+	client.Stopped = true
+	handler.Handle(Suspend)
+	handler.Handle(Resume)
+	assert.Empty(t, client.calls)
+}
+
+func TestResumeRenewsTheCertificatesWhenTheClockIsNotSet(t *testing.T) {
+	handler, client := newTestHandler(t, "")
+	client.Failing = true
+	handler.Handle(Resume)
+	assert.Equal(t, []string{"sync-time", "renew"}, client.calls)
+}
+
+func TestResumeStoppedInstance(t *testing.T) {
+	handler, client := newTestHandler(t, "")
+	client.Stopped = true
+	handler.Handle(Resume)
+	assert.Empty(t, client.calls)
+}
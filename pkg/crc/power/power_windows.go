@@ -0,0 +1,64 @@
+package power
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+	"unsafe"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"golang.org/x/sys/windows"
+)
+
+var (
+	powrprof = windows.NewLazySystemDLL("powrprof.dll")
+
+	procPowerRegisterSuspendResumeNotification   = powrprof.NewProc("PowerRegisterSuspendResumeNotification")
+	procPowerUnregisterSuspendResumeNotification = powrprof.NewProc("PowerUnregisterSuspendResumeNotification")
+)
+
+const (
+	deviceNotifyCallback = 2
+
+	pbtAPMSuspend         = 0x4
+	pbtAPMResumeAutomatic = 0x12
+)
+
+// deviceNotifySubscribeParameters is DEVICE_NOTIFY_SUBSCRIBE_PARAMETERS
+type deviceNotifySubscribeParameters struct {
+	callback uintptr
+	context  uintptr
+}
+
+// subscription is kept referenced while Windows can call its callback
+var subscription *deviceNotifySubscribeParameters
+
+// Watch calls handle with the power events of the host until ctx is done.
+// Windows waits before the sleep until handle returned for Suspend, for about
+// 2 seconds. Resume is sent for every wake up of the host, including the
+// automatic ones.
+func Watch(ctx context.Context, handle func(Event)) error {
+	subscription = &deviceNotifySubscribeParameters{
+		callback: syscall.NewCallback(func(context, eventType, setting uintptr) uintptr {
+			switch eventType {
+			case pbtAPMSuspend:
+				handle(Suspend)
+			case pbtAPMResumeAutomatic:
+				// the callback must return quickly after the host woke up
+				go handle(Resume)
+			}
+			return 0
+		}),
+	}
+	var registration uintptr
+	ret, _, _ := procPowerRegisterSuspendResumeNotification.Call(deviceNotifyCallback,
+		uintptr(unsafe.Pointer(subscription)), uintptr(unsafe.Pointer(&registration)))
+	if ret != 0 {
+		return fmt.Errorf("Cannot register for the power notifications: %v", syscall.Errno(ret))
+	}
+	<-ctx.Done()
+	if ret, _, _ := procPowerUnregisterSuspendResumeNotification.Call(registration); ret != 0 {
+		logging.Debugf("Cannot unregister from the power notifications: %v", syscall.Errno(ret))
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package preflight
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/logging"
+)
+
+// CleanupArea is the part of the host changed by a check, 'crc cleanup
+// --only' undoes the changes of some areas
+type CleanupArea string
+
+const (
+	DNSArea        CleanupArea = "dns"
+	NetworkArea    CleanupArea = "network"
+	VsockArea      CleanupArea = "vsock"
+	DaemonArea     CleanupArea = "daemon"
+	VMArea         CleanupArea = "vm"
+	ShellArea      CleanupArea = "shell"
+	PullSecretArea CleanupArea = "pull-secret"
+	LogsArea       CleanupArea = "logs"
+	AppArmorArea   CleanupArea = "apparmor"
+	FirewallArea   CleanupArea = "firewall"
+	SharedDirsArea CleanupArea = "shared-dirs"
+)
+
+var cleanupAreas = []CleanupArea{DNSArea, NetworkArea, VsockArea, DaemonArea, VMArea, ShellArea, PullSecretArea, LogsArea, AppArmorArea, FirewallArea, SharedDirsArea}
+
+// CleanupAreas returns the names of the areas accepted by 'crc cleanup --only'
+func CleanupAreas() []string {
+	var names []string
+	for _, area := range cleanupAreas {
+		names = append(names, string(area))
+	}
+	return names
+}
+
+// ParseCleanupAreas validates the names of areas given to 'crc cleanup --only'
+func ParseCleanupAreas(names []string) ([]CleanupArea, error) {
+	var areas []CleanupArea
+	for _, name := range names {
+		area := CleanupArea(strings.TrimSpace(name))
+		if !containsArea(cleanupAreas, area) {
+			return nil, fmt.Errorf("Unknown cleanup area '%s', valid areas are: %s", name, strings.Join(CleanupAreas(), ", "))
+		}
+		areas = append(areas, area)
+	}
+	return areas, nil
+}
+
+func containsArea(areas []CleanupArea, area CleanupArea) bool {
+	for _, a := range areas {
+		if a == area {
+			return true
+		}
+	}
+	return false
+}
+
+// undoAction is a change of the host made or found by 'crc setup', which
+// 'crc cleanup' undoes
+type undoAction struct {
+	Check       string      `json:"check"`
+	Area        CleanupArea `json:"area"`
+	Description string      `json:"description"`
+	Time        time.Time   `json:"time"`
+}
+
+// setupManifest records the undo actions of the checks run by 'crc setup'.
+// The checks which only clean up the instance are not recorded, they are
+// always run by 'crc cleanup'.
+type setupManifest struct {
+	path    string
+	Actions []undoAction `json:"actions"`
+}
+
+// loadSetupManifest reads the manifest at path, it returns nil when 'crc
+// setup' did not write one, which is the case of older releases
+func loadSetupManifest(path string) (*setupManifest, error) {
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	manifest := &setupManifest{path: path}
+	if err := json.Unmarshal(content, manifest); err != nil {
+		return nil, fmt.Errorf("Cannot parse %s: %w", path, err)
+	}
+	return manifest, nil
+}
+
+func newSetupManifest(path string) *setupManifest {
+	manifest, err := loadSetupManifest(path)
+	if err != nil {
+		logging.Warnf("Replacing the setup manifest: %v", err)
+	}
+	if manifest == nil {
+		manifest = &setupManifest{path: path}
+	}
+	return manifest
+}
+
+// record adds the undo action of check, it replaces the one recorded by a
+// previous 'crc setup'
+func (manifest *setupManifest) record(check Check) {
+	if manifest == nil || check.cleanup == nil || check.configKeySuffix == "" {
+		return
+	}
+	manifest.forget(check.configKeySuffix)
+	manifest.Actions = append(manifest.Actions, undoAction{
+		Check:       check.configKeySuffix,
+		Area:        check.cleanupArea,
+		Description: check.cleanupDescription,
+		Time:        time.Now(),
+	})
+}
+
+func (manifest *setupManifest) forget(checkID string) {
+	var actions []undoAction
+	for _, action := range manifest.Actions {
+		if action.Check != checkID {
+			actions = append(actions, action)
+		}
+	}
+	manifest.Actions = actions
+}
+
+func (manifest *setupManifest) has(checkID string) bool {
+	for _, action := range manifest.Actions {
+		if action.Check == checkID {
+			return true
+		}
+	}
+	return false
+}
+
+func (manifest *setupManifest) save() error {
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(manifest.path, content, 0600)
+}
+
+// CleanupAction is a change of the host undone by 'crc cleanup'
+type CleanupAction struct {
+	Area        CleanupArea `json:"area"`
+	Description string      `json:"description"`
+	Check       string      `json:"check,omitempty"`
+}
+
+// planCleanUp returns the checks whose cleanup is run, in the order they are
+// run. Without manifest, all the cleanups are run.
+func planCleanUp(checks []Check, manifest *setupManifest, only []CleanupArea) []Check {
+	var planned []Check
+	seen := map[string]bool{}
+	// Do the cleanup in reverse order to avoid any dependency during cleanup
+	for i := len(checks) - 1; i >= 0; i-- {
+		check := checks[i]
+		if check.cleanup == nil {
+			continue
+		}
+		if len(only) > 0 && !containsArea(only, check.cleanupArea) {
+			continue
+		}
+		if manifest != nil && check.configKeySuffix != "" && !manifest.has(check.configKeySuffix) {
+			continue
+		}
+		key := check.configKeySuffix + "/" + check.cleanupDescription
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		planned = append(planned, check)
+	}
+	return planned
+}
+
+func cleanupActions(checks []Check) []CleanupAction {
+	actions := []CleanupAction{}
+	for _, check := range checks {
+		actions = append(actions, CleanupAction{
+			Area:        check.cleanupArea,
+			Description: check.cleanupDescription,
+			Check:       check.configKeySuffix,
+		})
+	}
+	return actions
+}
+
+// doCleanUpPreflightChecks runs the cleanup of the planned checks, and
+// returns the actions which succeeded. The manifest, when there is one,
+// keeps the actions which were not run or failed.
+func doCleanUpPreflightChecks(planned []Check, manifest *setupManifest, only []CleanupArea) ([]CleanupAction, error) {
+	var (
+		mErr errors.MultiError
+		done []Check
+	)
+	for _, check := range planned {
+		err := check.doCleanUp()
+		if err != nil {
+			// If an error occurs in a cleanup function
+			// we log/collect it and  move to the  next
+			logging.Debug(err)
+			mErr.Collect(err)
+			continue
+		}
+		done = append(done, check)
+		if manifest != nil && check.configKeySuffix != "" {
+			manifest.forget(check.configKeySuffix)
+		}
+	}
+	if manifest != nil {
+		var err error
+		if len(only) == 0 && len(mErr.Errors) == 0 {
+			err = os.Remove(manifest.path)
+		} else {
+			err = manifest.save()
+		}
+		if err != nil && !os.IsNotExist(err) {
+			mErr.Collect(fmt.Errorf("Cannot update %s: %w", manifest.path, err))
+		}
+	}
+	if len(mErr.Errors) == 0 {
+		return cleanupActions(done), nil
+	}
+	return cleanupActions(done), mErr
+}
+
+// PlanCleanUpHost returns the changes 'crc cleanup' undoes, without undoing
+// them. When only is not empty, the changes of the other areas are kept.
+func PlanCleanUpHost(only []CleanupArea) []CleanupAction {
+	manifest, err := loadSetupManifest(constants.SetupManifestPath)
+	if err != nil {
+		logging.Warnf("Ignoring the setup manifest, all the changes are undone: %v", err)
+	}
+	return cleanupActions(planCleanUp(getAllPreflightChecks(), manifest, only))
+}
+
+// CleanUpHost undoes the changes made by 'crc setup' which are recorded in
+// its manifest, and removes the instance. When only is not empty, the
+// changes of the other areas are kept.
+func CleanUpHost(only []CleanupArea) ([]CleanupAction, error) {
+	manifest, err := loadSetupManifest(constants.SetupManifestPath)
+	if err != nil {
+		logging.Warnf("Ignoring the setup manifest, all the changes are undone: %v", err)
+	}
+	// A user can use setup with experiment flag
+	// and not use cleanup with same flag, to avoid
+	// any extra step/confusion we are just adding the checks
+	// which are behind the experiment flag. This way cleanup
+	// perform action in a sane way.
+	planned := planCleanUp(getAllPreflightChecks(), manifest, only)
+	return doCleanUpPreflightChecks(planned, manifest, only)
+}
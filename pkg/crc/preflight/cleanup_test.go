@@ -0,0 +1,103 @@
+package preflight
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func cleanupCheck(id string, area CleanupArea, cleanedUp *[]string) Check {
+	check, _ := sampleCheck(nil, nil)
+	check.configKeySuffix = id
+	check.cleanupDescription = "Removing " + id
+	check.cleanupArea = area
+	check.cleanup = func() error {
+		*cleanedUp = append(*cleanedUp, id)
+		return nil
+	}
+	if id == "" {
+		check.cleanupDescription = "Removing the instance"
+		check.flags = CleanUpOnly
+	}
+	return *check
+}
+
+func TestCleanupAreasOfAllChecks(t *testing.T) {
+	for _, check := range getAllPreflightChecks() {
+		if check.cleanup != nil {
+			assert.Contains(t, cleanupAreas, check.cleanupArea, check.cleanupDescription)
+		}
+	}
+}
+
+func TestParseCleanupAreas(t *testing.T) {
+	areas, err := ParseCleanupAreas([]string{"dns", " vsock"})
+	assert.NoError(t, err)
+	assert.Equal(t, []CleanupArea{DNSArea, VsockArea}, areas)
+
+	_, err = ParseCleanupAreas([]string{"printer"})
+	assert.EqualError(t, err, "Unknown cleanup area 'printer', valid areas are: dns, network, vsock, daemon, vm, shell, pull-secret, logs, apparmor, firewall, shared-dirs")
+}
+
+func TestCleanUpRecordedChecks(t *testing.T) {
+	var cleanedUp []string
+	dns := cleanupCheck("dns", DNSArea, &cleanedUp)
+	vsock := cleanupCheck("vsock", VsockArea, &cleanedUp)
+	instance := cleanupCheck("", VMArea, &cleanedUp)
+	checks := []Check{instance, dns, vsock}
+
+	path := filepath.Join(t.TempDir(), "setup-manifest.json")
+	cfg := config.New(config.NewEmptyInMemoryStorage())
+	doRegisterSettings(cfg, checks)
+	manifest := newSetupManifest(path)
+	require.NoError(t, doFixPreflightChecks(cfg, []Check{dns, instance}, false, manifest))
+	require.NoError(t, manifest.save())
+
+	manifest, err := loadSetupManifest(path)
+	require.NoError(t, err)
+	require.Len(t, manifest.Actions, 1)
+	assert.Equal(t, "dns", manifest.Actions[0].Check)
+	assert.Equal(t, DNSArea, manifest.Actions[0].Area)
+
+	// vsock was not set up, the instance is always removed
+	assert.Equal(t, []CleanupAction{
+		{Area: DNSArea, Description: "Removing dns", Check: "dns"},
+		{Area: VMArea, Description: "Removing the instance"},
+	}, cleanupActions(planCleanUp(checks, manifest, nil)))
+	assert.Equal(t, []CleanupAction{
+		{Area: VMArea, Description: "Removing the instance"},
+	}, cleanupActions(planCleanUp(checks, manifest, []CleanupArea{VMArea})))
+
+	actions, err := doCleanUpPreflightChecks(planCleanUp(checks, manifest, []CleanupArea{VMArea}), manifest, []CleanupArea{VMArea})
+	assert.NoError(t, err)
+	assert.Len(t, actions, 1)
+	assert.Equal(t, []string{""}, cleanedUp)
+	manifest, err = loadSetupManifest(path)
+	require.NoError(t, err)
+	assert.True(t, manifest.has("dns"))
+
+	_, err = doCleanUpPreflightChecks(planCleanUp(checks, manifest, nil), manifest, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"", "dns", ""}, cleanedUp)
+	manifest, err = loadSetupManifest(path)
+	assert.NoError(t, err)
+	assert.Nil(t, manifest)
+}
+
+func TestCleanUpWithoutManifest(t *testing.T) {
+	var cleanedUp []string
+	failing := cleanupCheck("failing", DNSArea, &cleanedUp)
+	failing.cleanup = func() error {
+		return errors.New("cleanup failed")
+	}
+	checks := []Check{cleanupCheck("dns", DNSArea, &cleanedUp), failing, cleanupCheck("vsock", VsockArea, &cleanedUp)}
+
+	actions, err := doCleanUpPreflightChecks(planCleanUp(checks, nil, nil), nil, nil)
+	assert.EqualError(t, err, "cleanup failed")
+	assert.Equal(t, []string{"vsock", "dns"}, cleanedUp)
+	assert.Len(t, actions, 2)
+}
@@ -0,0 +1,15 @@
+package preflight
+
+// DaemonServiceStatus describes how the daemon is registered with the service
+// manager of the host, so that it is started automatically
+type DaemonServiceStatus struct {
+	// Manager is the service manager used on this platform
+	Manager string
+	// Path is the file or the name registering the daemon with Manager
+	Path string
+	// SocketActivation is true when the service manager creates the
+	// listening sockets and starts the daemon on the first connection
+	SocketActivation bool
+	Installed        bool
+	Running          bool
+}
@@ -0,0 +1,73 @@
+package preflight
+
+import (
+	"os"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/os/launchd"
+)
+
+// daemonAgentLabel must not be crc.daemon, the plist of this label is removed
+// by the cleanup of the old tray configuration
+const daemonAgentLabel = "com.redhat.crc.daemon"
+
+func daemonAgentConfig() (launchd.AgentConfig, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return launchd.AgentConfig{}, err
+	}
+	return launchd.AgentConfig{
+		Label:          daemonAgentLabel,
+		ExecutablePath: executable,
+		StdOutFilePath: constants.DaemonLogFilePath,
+		Args:           []string{"daemon"},
+	}, nil
+}
+
+// InstallDaemonService registers the daemon as a launchd agent of the user,
+// started at login. launchd socket activation is not used, it requires the
+// daemon to be linked with the launch_activate_socket C API.
+func InstallDaemonService() error {
+	config, err := daemonAgentConfig()
+	if err != nil {
+		return err
+	}
+	if launchd.CheckPlist(config) == nil && launchd.AgentRunning(daemonAgentLabel) {
+		return nil
+	}
+	if launchd.PlistExists(daemonAgentLabel) {
+		_ = launchd.UnloadPlist(daemonAgentLabel)
+	}
+	if err := launchd.CreatePlist(config); err != nil {
+		return err
+	}
+	return launchd.LoadPlist(daemonAgentLabel)
+}
+
+func UninstallDaemonService() error {
+	if !launchd.PlistExists(daemonAgentLabel) {
+		return nil
+	}
+	if err := launchd.UnloadPlist(daemonAgentLabel); err != nil {
+		logging.Debugf("Cannot unload %s: %v", daemonAgentLabel, err)
+	}
+	return launchd.RemovePlist(daemonAgentLabel)
+}
+
+func StartDaemonService() error {
+	return launchd.StartAgent(daemonAgentLabel)
+}
+
+func RestartDaemonService() error {
+	return launchd.RestartAgent(daemonAgentLabel)
+}
+
+func GetDaemonServiceStatus() *DaemonServiceStatus {
+	return &DaemonServiceStatus{
+		Manager:   "launchd",
+		Path:      daemonAgentLabel,
+		Installed: launchd.PlistExists(daemonAgentLabel),
+		Running:   launchd.AgentRunning(daemonAgentLabel),
+	}
+}
@@ -0,0 +1,72 @@
+package preflight
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/systemd"
+	crcos "github.com/code-ready/crc/pkg/os"
+)
+
+func systemdUserSupported() bool {
+	filter := newFilter()
+	filter.SetSystemdUser(distro())
+	return filter[SystemdUser] == Supported
+}
+
+// InstallDaemonService registers the daemon as a systemd user service, started
+// on the first connection to its sockets
+func InstallDaemonService() error {
+	if !systemdUserSupported() {
+		return errors.New("systemd --user is not available on this distribution, 'crc daemon' must be run manually")
+	}
+	if err := fixDaemonSystemdService(); err != nil {
+		return err
+	}
+	return fixDaemonSystemdSockets()
+}
+
+func UninstallDaemonService() error {
+	if err := removeDaemonSystemdService(); err != nil {
+		return err
+	}
+	if err := removeDaemonSystemdSockets(); err != nil {
+		return err
+	}
+	return systemd.NewHostSystemdCommander().User().DaemonReload()
+}
+
+// StartDaemonService starts the socket units, the daemon itself is started by
+// the first request
+func StartDaemonService() error {
+	sd := systemd.NewHostSystemdCommander().User()
+	for _, unitName := range []string{httpUnitName, vsockUnitName} {
+		if err := sd.Start(unitName); err != nil {
+			return fmt.Errorf("Cannot start %s: %w", unitName, err)
+		}
+	}
+	return nil
+}
+
+// RestartDaemonService stops the daemon, the new executable is started by the
+// next request
+func RestartDaemonService() error {
+	return systemd.NewHostSystemdCommander().User().Stop(daemonUnitName)
+}
+
+func GetDaemonServiceStatus() *DaemonServiceStatus {
+	sd := systemd.NewHostSystemdCommander().User()
+	installed := true
+	for _, unitName := range []string{daemonUnitName, httpUnitName, vsockUnitName} {
+		if !crcos.FileExists(systemd.UserUnitPath(unitName)) {
+			installed = false
+		}
+	}
+	return &DaemonServiceStatus{
+		Manager:          "systemd",
+		Path:             systemd.UserUnitPath(daemonUnitName),
+		SocketActivation: true,
+		Installed:        installed,
+		Running:          systemdUnitRunning(sd, daemonUnitName),
+	}
+}
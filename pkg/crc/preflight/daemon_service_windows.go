@@ -0,0 +1,82 @@
+package preflight
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	crcos "github.com/code-ready/crc/pkg/os"
+)
+
+// The daemon is registered as a scheduled task started at logon rather than
+// as a Windows service: services run outside of the session of the user, and
+// the daemon needs the configuration and the named pipe of the user
+const daemonTaskName = "crcDaemon"
+
+// InstallDaemonService registers the daemon as a scheduled task started at
+// logon, and starts it
+func InstallDaemonService() error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if _, stderr, err := crcos.RunWithDefaultLocale("schtasks", "/Create", "/F", "/TN", daemonTaskName,
+		"/SC", "ONLOGON", "/RL", "LIMITED", "/TR", fmt.Sprintf(`"%s" daemon`, executable)); err != nil {
+		return fmt.Errorf("Cannot create the %s scheduled task: %s: %w", daemonTaskName, strings.TrimSpace(stderr), err)
+	}
+	return StartDaemonService()
+}
+
+func UninstallDaemonService() error {
+	if !GetDaemonServiceStatus().Installed {
+		return nil
+	}
+	if _, _, err := crcos.RunWithDefaultLocale("schtasks", "/End", "/TN", daemonTaskName); err != nil {
+		logging.Debugf("Cannot stop the %s scheduled task: %v", daemonTaskName, err)
+	}
+	if _, stderr, err := crcos.RunWithDefaultLocale("schtasks", "/Delete", "/F", "/TN", daemonTaskName); err != nil {
+		return fmt.Errorf("Cannot delete the %s scheduled task: %s: %w", daemonTaskName, strings.TrimSpace(stderr), err)
+	}
+	return nil
+}
+
+func StartDaemonService() error {
+	if _, stderr, err := crcos.RunWithDefaultLocale("schtasks", "/Run", "/TN", daemonTaskName); err != nil {
+		return fmt.Errorf("Cannot run the %s scheduled task: %s: %w", daemonTaskName, strings.TrimSpace(stderr), err)
+	}
+	return nil
+}
+
+func RestartDaemonService() error {
+	if _, stderr, err := crcos.RunWithDefaultLocale("schtasks", "/End", "/TN", daemonTaskName); err != nil {
+		return fmt.Errorf("Cannot stop the %s scheduled task: %s: %w", daemonTaskName, strings.TrimSpace(stderr), err)
+	}
+	return StartDaemonService()
+}
+
+func GetDaemonServiceStatus() *DaemonServiceStatus {
+	status := &DaemonServiceStatus{
+		Manager: "Task Scheduler",
+		Path:    daemonTaskName,
+	}
+	stdout, _, err := crcos.RunWithDefaultLocale("schtasks", "/Query", "/TN", daemonTaskName, "/FO", "CSV", "/NH")
+	if err != nil {
+		logging.Debugf("Cannot query the %s scheduled task: %v", daemonTaskName, err)
+		return status
+	}
+	status.Installed = true
+	status.Running = taskRunning(stdout)
+	return status
+}
+
+// taskRunning parses the "TaskName","Next Run Time","Status" CSV output of
+// schtasks /Query
+func taskRunning(output string) bool {
+	records, err := csv.NewReader(strings.NewReader(strings.TrimSpace(output))).ReadAll()
+	if err != nil || len(records) == 0 || len(records[0]) < 3 {
+		return false
+	}
+	return records[0][2] == "Running"
+}
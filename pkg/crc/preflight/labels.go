@@ -3,6 +3,7 @@ package preflight
 import (
 	"runtime"
 
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
 	"github.com/code-ready/crc/pkg/crc/network"
 )
 
@@ -11,6 +12,7 @@ type LabelName uint32
 const (
 	Os LabelName = iota
 	NetworkMode
+	VMDriver
 
 	// Keep it last
 	// will be used in OS-specific go files to extend LabelName
@@ -29,6 +31,14 @@ const (
 	User
 	System
 
+	// vm driver
+	Libvirt
+	Qemu
+	Hyperkit
+	Vfkit
+	Hyperv
+	WSL2
+
 	// Keep it last
 	// will be used in OS-specific go files to extend LabelValue
 	lastLabelValue // nolint
@@ -65,6 +75,23 @@ func (filter preflightFilter) SetNetworkMode(networkMode network.Mode) {
 	}
 }
 
+func (filter preflightFilter) SetVMDriver(driver string) {
+	switch driver {
+	case crcConfig.LibvirtVMDriver:
+		filter[VMDriver] = Libvirt
+	case crcConfig.QemuVMDriver:
+		filter[VMDriver] = Qemu
+	case crcConfig.HyperkitVMDriver:
+		filter[VMDriver] = Hyperkit
+	case crcConfig.VfkitVMDriver:
+		filter[VMDriver] = Vfkit
+	case crcConfig.HypervVMDriver:
+		filter[VMDriver] = Hyperv
+	case crcConfig.WSL2VMDriver:
+		filter[VMDriver] = WSL2
+	}
+}
+
 /* This will iterate over 'checks' and only keep the checks which match the filter:
  * - if a key is present in the filter and not in the check labels, the check is kept
  * - if a key is present in the check labels, but not in the filter, the check is kept
@@ -85,9 +112,13 @@ func (filter preflightFilter) Apply(checks []Check) []Check {
 }
 
 func skipCheck(check Check, filter preflightFilter) bool {
+	return filter.excludes(check.labels)
+}
+
+func (filter preflightFilter) excludes(labels labels) bool {
 	for filterKey, filterValue := range filter {
-		checkValue, present := check.labels[filterKey]
-		if present && checkValue != filterValue {
+		value, present := labels[filterKey]
+		if present && value != filterValue {
 			return true
 		}
 	}
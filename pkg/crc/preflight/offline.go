@@ -0,0 +1,46 @@
+package preflight
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+)
+
+// MissingArtifactsError lists the files which cannot be downloaded when
+// starting without network access
+type MissingArtifactsError struct {
+	Paths []string
+}
+
+func (e *MissingArtifactsError) Error() string {
+	return fmt.Sprintf("The following files are needed to start offline and are missing:\n- %s", strings.Join(e.Paths, "\n- "))
+}
+
+// CheckOfflineArtifacts verifies that the bundle and the executables needed
+// to start the instance are present locally, as they are usually downloaded
+// by 'crc setup'
+func CheckOfflineArtifacts(config crcConfig.Storage) error {
+	var missing []string
+	bundlePath := config.Get(crcConfig.Bundle).AsString()
+	localPath, ok := bundle.LocalPath(bundlePath)
+	if !ok {
+		missing = append(missing, bundlePath)
+	} else if _, err := bundle.Get(filepath.Base(localPath)); err != nil {
+		if _, err := os.Stat(localPath); err != nil {
+			missing = append(missing, localPath)
+		}
+	}
+	for _, executable := range offlineExecutables() {
+		if !executable.IsCached() {
+			missing = append(missing, executable.GetExecutablePath())
+		}
+	}
+	if len(missing) > 0 {
+		return &MissingArtifactsError{Paths: missing}
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package preflight
+
+import (
+	"path/filepath"
+	"testing"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckOfflineArtifactsMissingBundle(t *testing.T) {
+	storage := crcConfig.NewEmptyInMemoryStorage()
+	cfg := crcConfig.New(storage)
+	crcConfig.RegisterSettings(cfg)
+	// bypass the validation of the setting, which rejects missing files
+	bundlePath := filepath.Join(t.TempDir(), "crc_missing_4.10.3_amd64.crcbundle")
+	require.NoError(t, storage.Set(crcConfig.Bundle, bundlePath))
+
+	err := CheckOfflineArtifacts(cfg)
+	var missingErr *MissingArtifactsError
+	require.ErrorAs(t, err, &missingErr)
+	assert.Contains(t, missingErr.Paths, bundlePath)
+	assert.Contains(t, err.Error(), "- "+bundlePath)
+}
@@ -0,0 +1,182 @@
+package preflight
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	crcos "github.com/code-ready/crc/pkg/os"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+var pluginIDRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// pluginDefinition is the content of a YAML file of the preflight.d
+// directory
+//
+//	id: check-vpn
+//	description: Checking if the VPN is connected
+//	check: ["nmcli", "-t", "connection", "show", "--active", "corp-vpn"]
+//	fixDescription: Connecting to the VPN
+//	fix: ["nmcli", "connection", "up", "corp-vpn"]
+type pluginDefinition struct {
+	ID             string   `json:"id"`
+	Description    string   `json:"description"`
+	Check          []string `json:"check"`
+	FixDescription string   `json:"fixDescription"`
+	Fix            []string `json:"fix"`
+}
+
+// getPluginChecks returns the site-specific checks defined in the
+// preflight.d directory. Each entry is either a YAML check definition, or an
+// executable which is run with the 'check' argument, and with the 'fix'
+// argument when the check fails during 'crc setup'.
+func getPluginChecks(pluginDir string, builtinChecks []Check) []Check {
+	entries, err := ioutil.ReadDir(pluginDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logging.Warnf("Cannot read preflight plugins from %s: %v", pluginDir, err)
+		}
+		return nil
+	}
+
+	ids := map[string]bool{}
+	for _, check := range builtinChecks {
+		ids[check.configKeySuffix] = true
+	}
+
+	var checks []Check
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(pluginDir, entry.Name())
+		var (
+			definition *pluginDefinition
+			err        error
+		)
+		switch ext := filepath.Ext(entry.Name()); {
+		case ext == ".yaml" || ext == ".yml":
+			definition, err = loadPluginDefinition(path)
+		case isExecutable(entry):
+			definition = executablePluginDefinition(path)
+		default:
+			logging.Debugf("Ignoring %s, it is neither a YAML file nor an executable", path)
+			continue
+		}
+		if err == nil {
+			err = validatePluginDefinition(definition, ids)
+		}
+		if err != nil {
+			logging.Warnf("Ignoring preflight plugin %s: %v", path, err)
+			continue
+		}
+		ids[definition.ID] = true
+		checks = append(checks, definition.toCheck())
+	}
+	return checks
+}
+
+func loadPluginDefinition(path string) (*pluginDefinition, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var definition pluginDefinition
+	if err := yaml.Unmarshal(data, &definition); err != nil {
+		return nil, err
+	}
+	if definition.ID == "" {
+		definition.ID = pluginID(path)
+	}
+	if definition.Description == "" {
+		definition.Description = fmt.Sprintf("Running site-specific check %s", definition.ID)
+	}
+	return &definition, nil
+}
+
+func executablePluginDefinition(path string) *pluginDefinition {
+	id := pluginID(path)
+	return &pluginDefinition{
+		ID:             id,
+		Description:    fmt.Sprintf("Running site-specific check %s", id),
+		Check:          []string{path, "check"},
+		FixDescription: fmt.Sprintf("Running site-specific fix %s", id),
+		Fix:            []string{path, "fix"},
+	}
+}
+
+func pluginID(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return "check-" + strings.ToLower(name)
+}
+
+func validatePluginDefinition(definition *pluginDefinition, ids map[string]bool) error {
+	if !pluginIDRegexp.MatchString(definition.ID) {
+		return fmt.Errorf("invalid id '%s', only lowercase letters, digits and dashes are allowed", definition.ID)
+	}
+	if ids[definition.ID] {
+		return fmt.Errorf("a check with id '%s' already exists", definition.ID)
+	}
+	if len(definition.Check) == 0 {
+		return fmt.Errorf("no check command")
+	}
+	if len(definition.Fix) != 0 && definition.FixDescription == "" {
+		definition.FixDescription = fmt.Sprintf("Running site-specific fix %s", definition.ID)
+	}
+	return nil
+}
+
+func (definition *pluginDefinition) toCheck() Check {
+	check := Check{
+		configKeySuffix:  definition.ID,
+		checkDescription: definition.Description,
+		check:            runPluginCommand(definition.Check),
+		fixDescription:   definition.FixDescription,
+	}
+	if len(definition.Fix) == 0 {
+		check.flags = NoFix
+		if check.fixDescription == "" {
+			check.fixDescription = fmt.Sprintf("Site-specific check %s failed, contact your administrator", definition.ID)
+		}
+	} else {
+		check.fix = runPluginCommand(definition.Fix)
+	}
+	return check
+}
+
+func runPluginCommand(command []string) func() error {
+	return func() error {
+		stdout, stderr, err := crcos.RunWithDefaultLocale(command[0], command[1:]...)
+		if err != nil {
+			if output := strings.TrimSpace(stderr + stdout); output != "" {
+				return fmt.Errorf("%s: %v", output, err)
+			}
+			return err
+		}
+		return nil
+	}
+}
+
+func isExecutable(info os.FileInfo) bool {
+	if runtime.GOOS == "windows" {
+		switch strings.ToLower(filepath.Ext(info.Name())) {
+		case ".exe", ".bat", ".cmd":
+			return true
+		}
+		return false
+	}
+	return info.Mode().IsRegular() && info.Mode().Perm()&0111 != 0
+}
+
+// withPluginChecks appends the checks of the preflight.d directory to the
+// builtin checks
+func withPluginChecks(checks []Check) []Check {
+	return append(checks, getPluginChecks(constants.PreflightPluginDir, checks)...)
+}
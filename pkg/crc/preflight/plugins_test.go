@@ -0,0 +1,48 @@
+package preflight
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPluginChecks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("shell scripts are not executable on Windows")
+	}
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "vpn.yaml"), []byte(`id: check-vpn
+description: Checking if the VPN is connected
+check: ["sh", "-c", "echo VPN is disconnected >&2; exit 1"]
+`), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "encryption.sh"), []byte(`#!/bin/sh
+[ "$1" = "check" ]
+`), 0700))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "duplicate.yaml"), []byte(`id: sample
+check: ["true"]
+`), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README"), []byte("not a check"), 0600))
+
+	builtin, _ := sampleCheck(nil, nil)
+	checks := getPluginChecks(dir, []Check{*builtin})
+	require.Len(t, checks, 2)
+
+	assert.Equal(t, "check-encryption", checks[0].configKeySuffix)
+	assert.Equal(t, "Running site-specific check check-encryption", checks[0].checkDescription)
+	assert.NoError(t, checks[0].check())
+	assert.Error(t, checks[0].fix())
+
+	assert.Equal(t, "check-vpn", checks[1].configKeySuffix)
+	assert.Equal(t, "Checking if the VPN is connected", checks[1].checkDescription)
+	assert.Equal(t, NoFix, checks[1].flags)
+	assert.EqualError(t, checks[1].check(), "VPN is disconnected: exit status 1")
+	assert.Equal(t, "Site-specific check check-vpn failed, contact your administrator", checks[1].remediation())
+}
+
+func TestGetPluginChecksMissingDirectory(t *testing.T) {
+	assert.Empty(t, getPluginChecks(filepath.Join(t.TempDir(), "missing"), nil))
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/errors"
 	"github.com/code-ready/crc/pkg/crc/logging"
 )
@@ -31,6 +32,8 @@ type Check struct {
 	flags              Flags
 	cleanupDescription string
 	cleanup            CleanUpFunc
+	// cleanupArea is required when cleanup is set
+	cleanupArea CleanupArea
 
 	labels labels
 }
@@ -42,6 +45,25 @@ func (check *Check) getSkipConfigName() string {
 	return "skip-" + check.configKeySuffix
 }
 
+func (check *Check) getWarnConfigName() string {
+	if check.configKeySuffix == "" {
+		return ""
+	}
+	return "warn-" + check.configKeySuffix
+}
+
+// shouldWarn returns true when a failure of the check must only be logged
+func (check *Check) shouldWarn(config crcConfig.Storage) bool {
+	if check.configKeySuffix == "" {
+		return false
+	}
+	return config.Get(check.getWarnConfigName()).AsBool()
+}
+
+func (check *Check) warn(err error) {
+	logging.Warnf("%v (ignored as %s is set)", err, check.getWarnConfigName())
+}
+
 func (check *Check) shouldSkip(config crcConfig.Storage) bool {
 	if check.configKeySuffix == "" {
 		return false
@@ -96,19 +118,47 @@ func doPreflightChecks(config crcConfig.Storage, checks []Check) error {
 			continue
 		}
 		if err := check.doCheck(config); err != nil {
-			return err
+			if check.shouldWarn(config) {
+				check.warn(err)
+				continue
+			}
+			return &errors.PreflightError{
+				Err:         err,
+				CheckID:     check.configKeySuffix,
+				Remediation: check.remediation(),
+			}
 		}
 	}
 	return nil
 }
 
-func doFixPreflightChecks(config crcConfig.Storage, checks []Check, checkOnly bool) error {
+// remediation returns the hint displayed when the check fails before
+// starting the cluster
+func (check *Check) remediation() string {
+	if check.fixDescription == "" {
+		return ""
+	}
+	if check.flags&NoFix == NoFix {
+		return check.fixDescription
+	}
+	return fmt.Sprintf("Run 'crc setup' to fix it (%s)", check.fixDescription)
+}
+
+// doFixPreflightChecks runs the checks and their fixes, the checks which
+// pass or are fixed are recorded in manifest when it is not nil
+func doFixPreflightChecks(config crcConfig.Storage, checks []Check, checkOnly bool, manifest *setupManifest) error {
 	for _, check := range checks {
 		if check.flags&CleanUpOnly == CleanUpOnly || check.flags&StartUpOnly == StartUpOnly {
 			continue
 		}
 		err := check.doCheck(config)
 		if err == nil {
+			if !check.shouldSkip(config) {
+				manifest.record(check)
+			}
+			continue
+		} else if check.shouldWarn(config) {
+			check.warn(err)
 			continue
 		} else if checkOnly {
 			return err
@@ -116,51 +166,67 @@ func doFixPreflightChecks(config crcConfig.Storage, checks []Check, checkOnly bo
 		if err = check.doFix(); err != nil {
 			return err
 		}
+		manifest.record(check)
 	}
 	return nil
 }
 
-func doCleanUpPreflightChecks(checks []Check) error {
-	var mErr errors.MultiError
-	// Do the cleanup in reverse order to avoid any dependency during cleanup
-	for i := len(checks) - 1; i >= 0; i-- {
-		check := checks[i]
-		if check.cleanup == nil {
-			continue
-		}
-		err := check.doCleanUp()
-		if err != nil {
-			// If an error occurs in a cleanup function
-			// we log/collect it and  move to the  next
-			logging.Debug(err)
-			mErr.Collect(err)
-		}
-	}
-	if len(mErr.Errors) == 0 {
-		return nil
-	}
-	return mErr
-}
-
 func doRegisterSettings(cfg crcConfig.Schema, checks []Check) {
 	for _, check := range checks {
 		if check.configKeySuffix != "" {
 			cfg.AddSetting(check.getSkipConfigName(), false, crcConfig.ValidateBool, crcConfig.SuccessfullyApplied,
 				"Skip preflight check (true/false, default: false)")
+			cfg.AddSetting(check.getWarnConfigName(), false, crcConfig.ValidateBool, crcConfig.SuccessfullyApplied,
+				"Only warn when the preflight check fails (true/false, default: false)")
 		}
 	}
 }
 
 // StartPreflightChecks performs the preflight checks before starting the cluster
 func StartPreflightChecks(config crcConfig.Storage) error {
+	return doPreflightChecks(config, startChecks(config))
+}
+
+func startChecks(config crcConfig.Storage) []Check {
 	experimentalFeatures := config.Get(crcConfig.ExperimentalFeatures).AsBool()
 	mode := crcConfig.GetNetworkMode(config)
 	bundlePath := config.Get(crcConfig.Bundle).AsString()
 	preset := crcConfig.GetPreset(config)
-	if err := doPreflightChecks(config, getPreflightChecks(experimentalFeatures, mode, bundlePath, preset)); err != nil {
-		return &errors.PreflightError{Err: err}
+	skipBundleVerification := config.Get(crcConfig.SkipBundleVerification).AsBool()
+	clusterDomain := config.Get(crcConfig.ClusterDomain).AsString()
+	return withPluginChecks(withFeatureChecks(config, withVMDriverChecks(config, getPreflightChecks(experimentalFeatures, mode, bundlePath, preset, skipBundleVerification, clusterDomain))))
+}
+
+// PlannedCheck is a check which 'crc start' runs before starting the instance
+type PlannedCheck struct {
+	ID          string `json:"id,omitempty"`
+	Description string `json:"description"`
+	// Skipped is true when the skip-<ID> setting is set
+	Skipped bool `json:"skipped,omitempty"`
+	// Warn is true when a failure of the check is only a warning
+	Warn bool `json:"warn,omitempty"`
+}
+
+// PlanStartChecks returns the checks which 'crc start' runs, without running
+// them
+func PlanStartChecks(config crcConfig.Storage) []PlannedCheck {
+	return doPlanChecks(config, startChecks(config))
+}
+
+func doPlanChecks(config crcConfig.Storage, checks []Check) []PlannedCheck {
+	planned := []PlannedCheck{}
+	for _, check := range checks {
+		if check.flags&SetupOnly == SetupOnly || check.flags&CleanUpOnly == CleanUpOnly {
+			continue
+		}
+		planned = append(planned, PlannedCheck{
+			ID:          check.configKeySuffix,
+			Description: check.checkDescription,
+			Skipped:     check.shouldSkip(config),
+			Warn:        check.shouldWarn(config),
+		})
 	}
-	return nil
+	return planned
 }
 
 // SetupHost performs the prerequisite checks and setups the host to run the cluster
@@ -169,19 +235,85 @@ func SetupHost(config crcConfig.Storage, checkOnly bool) error {
 	mode := crcConfig.GetNetworkMode(config)
 	bundlePath := config.Get(crcConfig.Bundle).AsString()
 	preset := crcConfig.GetPreset(config)
+	skipBundleVerification := config.Get(crcConfig.SkipBundleVerification).AsBool()
+	clusterDomain := config.Get(crcConfig.ClusterDomain).AsString()
 	logging.Infof("Using bundle path %s", bundlePath)
-	return doFixPreflightChecks(config, getPreflightChecks(experimentalFeatures, mode, bundlePath, preset), checkOnly)
+	checks := withPluginChecks(withFeatureChecks(config, withVMDriverChecks(config, getPreflightChecks(experimentalFeatures, mode, bundlePath, preset, skipBundleVerification, clusterDomain))))
+	if checkOnly {
+		return doFixPreflightChecks(config, checks, true, nil)
+	}
+	// the manifest is saved even when a fix fails, so that 'crc cleanup'
+	// undoes the changes made before the failure
+	manifest := newSetupManifest(constants.SetupManifestPath)
+	err := doFixPreflightChecks(config, checks, false, manifest)
+	if saveErr := manifest.save(); saveErr != nil {
+		logging.Warnf("Cannot save the setup manifest: %v", saveErr)
+	}
+	return err
+}
+
+// withFeatureChecks adds the checks of the host prerequisites of the
+// optional features which are enabled
+func withFeatureChecks(config crcConfig.Storage, checks []Check) []Check {
+	if config.Get(crcConfig.EnableGPU).AsBool() {
+		checks = append(checks, getGPUPreflightChecks()...)
+	}
+	if config.Get(crcConfig.EnableNestedVirtualization).AsBool() {
+		checks = append(checks, getNestedVirtualizationPreflightChecks()...)
+	}
+	if config.Get(crcConfig.EnableRosetta).AsBool() {
+		checks = append(checks, getRosettaPreflightChecks()...)
+	}
+	if config.Get(crcConfig.InstallShellCompletion).AsBool() {
+		checks = append(checks, shellCompletionPreflightChecks...)
+	}
+	if config.Get(crcConfig.AddClientsToPath).AsBool() {
+		checks = append(checks, clientsInPathPreflightChecks...)
+	}
+	return checks
+}
+
+// withVMDriverChecks only keeps the checks of the virtual machine driver
+// which is configured
+func withVMDriverChecks(config crcConfig.Storage, checks []Check) []Check {
+	driver := crcConfig.GetVMDriver(config)
+	if driver == crcConfig.QemuVMDriver {
+		checks = append(checks, getQemuPreflightChecks()...)
+	}
+	filter := preflightFilter{}
+	filter.SetVMDriver(driver)
+	return filter.Apply(checks)
 }
 
 func RegisterSettings(config crcConfig.Schema) {
-	doRegisterSettings(config, getAllPreflightChecks())
+	doRegisterSettings(config, withPluginChecks(getAllPreflightChecks()))
 }
 
-func CleanUpHost() error {
-	// A user can use setup with experiment flag
-	// and not use cleanup with same flag, to avoid
-	// any extra step/confusion we are just adding the checks
-	// which are behind the experiment flag. This way cleanup
-	// perform action in a sane way.
-	return doCleanUpPreflightChecks(getAllPreflightChecks())
+// CheckInfo describes a preflight check which can be skipped or turned into
+// a warning with the skip-<ID> and warn-<ID> settings
+type CheckInfo struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+}
+
+// ListChecks returns the preflight checks of this platform which can be
+// configured
+func ListChecks() []CheckInfo {
+	return doListChecks(withPluginChecks(getAllPreflightChecks()))
+}
+
+func doListChecks(checks []Check) []CheckInfo {
+	var infos []CheckInfo
+	seen := map[string]bool{}
+	for _, check := range checks {
+		if check.configKeySuffix == "" || seen[check.configKeySuffix] {
+			continue
+		}
+		seen[check.configKeySuffix] = true
+		infos = append(infos, CheckInfo{
+			ID:          check.configKeySuffix,
+			Description: check.checkDescription,
+		})
+	}
+	return infos
 }
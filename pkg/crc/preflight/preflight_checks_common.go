@@ -7,6 +7,7 @@ import (
 
 	"github.com/code-ready/crc/pkg/crc/adminhelper"
 	"github.com/code-ready/crc/pkg/crc/cluster"
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine/bundle"
@@ -15,13 +16,13 @@ import (
 	"github.com/pkg/errors"
 )
 
-func bundleCheck(bundlePath string, preset preset.Preset) Check {
+func bundleCheck(bundlePath string, preset preset.Preset, skipBundleVerification bool) Check {
 	return Check{
 		configKeySuffix:  "check-bundle-extracted",
 		checkDescription: "Checking if CRC bundle is extracted in '$HOME/.crc'",
 		check:            checkBundleExtracted(bundlePath),
 		fixDescription:   "Getting bundle for the CRC executable",
-		fix:              fixBundleExtracted(bundlePath, preset),
+		fix:              fixBundleExtracted(bundlePath, preset, skipBundleVerification),
 		flags:            SetupOnly,
 
 		labels: None,
@@ -32,6 +33,7 @@ var genericCleanupChecks = []Check{
 	{
 		cleanupDescription: "Removing CRC Machine Instance directory",
 		cleanup:            removeCRCMachinesDir,
+		cleanupArea:        VMArea,
 		flags:              CleanUpOnly,
 
 		labels: None,
@@ -39,6 +41,7 @@ var genericCleanupChecks = []Check{
 	{
 		cleanupDescription: "Removing older logs",
 		cleanup:            removeOldLogs,
+		cleanupArea:        LogsArea,
 		flags:              CleanUpOnly,
 
 		labels: None,
@@ -46,6 +49,7 @@ var genericCleanupChecks = []Check{
 	{
 		cleanupDescription: "Removing pull secret from the keyring",
 		cleanup:            cluster.ForgetPullSecret,
+		cleanupArea:        PullSecretArea,
 		flags:              CleanUpOnly,
 
 		labels: None,
@@ -53,6 +57,7 @@ var genericCleanupChecks = []Check{
 	{
 		cleanupDescription: "Removing hosts file records added by CRC",
 		cleanup:            removeHostsFileEntry,
+		cleanupArea:        DNSArea,
 		flags:              CleanUpOnly,
 
 		labels: None,
@@ -62,7 +67,11 @@ var genericCleanupChecks = []Check{
 func checkBundleExtracted(bundlePath string) func() error {
 	return func() error {
 		logging.Infof("Checking if %s exists", bundlePath)
-		bundleName := filepath.Base(bundlePath)
+		localPath, ok := bundle.LocalPath(bundlePath)
+		if !ok {
+			return fmt.Errorf("%s is not downloaded", bundlePath)
+		}
+		bundleName := filepath.Base(localPath)
 		if _, err := bundle.Get(bundleName); err != nil {
 			logging.Debugf("error getting bundle info for %s: %v", bundleName, err)
 			return err
@@ -72,7 +81,7 @@ func checkBundleExtracted(bundlePath string) func() error {
 	}
 }
 
-func fixBundleExtracted(bundlePath string, preset preset.Preset) func() error {
+func fixBundleExtracted(bundlePath string, preset preset.Preset, skipBundleVerification bool) func() error {
 	// Should be removed after 1.19 release
 	// This check will ensure correct mode for `~/.crc/cache` directory
 	// in case it exists.
@@ -96,12 +105,26 @@ func fixBundleExtracted(bundlePath string, preset preset.Preset) func() error {
 				return fmt.Errorf("%s is invalid or missing, run 'crc setup' to download the bundle", bundlePath)
 			}
 		}
-		if bundlePath == constants.GetDefaultBundlePath(preset) {
+		if bundle.IsRemote(bundlePath) {
+			if skipBundleVerification {
+				logging.Warnf("Skipping the checksum verification of %s", bundlePath)
+			}
+			localPath, err := bundle.DownloadRemote(bundlePath, !skipBundleVerification)
+			if err != nil {
+				return errors.Wrapf(err, "Cannot get a verified bundle, use 'crc config set %s true' to skip the verification", crcConfig.SkipBundleVerification)
+			}
+			bundlePath = localPath
+		} else if bundlePath == constants.GetDefaultBundlePath(preset) {
 			logging.Infof("Downloading %s", constants.GetDefaultBundle(preset))
-			if err := bundle.Download(preset); err != nil {
-				return err
+			if skipBundleVerification {
+				logging.Warnf("Skipping the signature verification of %s", constants.GetDefaultBundle(preset))
+			}
+			if err := bundle.Download(preset, !skipBundleVerification); err != nil {
+				return errors.Wrapf(err, "Cannot get a verified bundle, use 'crc config set %s true' to skip the verification", crcConfig.SkipBundleVerification)
 			}
 			bundlePath = constants.GetDefaultBundlePath(preset)
+		} else {
+			logging.Warnf("Using %s without verifying it, only the downloaded bundles are verified", bundlePath)
 		}
 
 		logging.Infof("Uncompressing %s", bundlePath)
@@ -242,3 +242,61 @@ func stopCRCHyperkitProcess() error {
 	}
 	return nil
 }
+
+// hostResolverFileContent sends the queries for a domain to the DNS resolver
+// of the daemon, /etc/resolver/<domain> files are used for the domain and
+// its subdomains
+var hostResolverFileContent = fmt.Sprintf("nameserver 127.0.0.1\nport %d\n", constants.HostDNSResolverPort)
+
+func checkHostResolverFiles(clusterDomain string) func() error {
+	return func() error {
+		for _, domain := range network.HostResolverDomains(clusterDomain) {
+			path := filepath.Join(resolverDir, domain)
+			if err := crcos.FileContentMatches(path, []byte(hostResolverFileContent)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func fixHostResolverFiles(clusterDomain string) func() error {
+	return func() error {
+		if _, err := os.Stat(resolverDir); os.IsNotExist(err) {
+			stdOut, stdErr, err := crcos.RunPrivileged(fmt.Sprintf("Creating dir %s", resolverDir), "mkdir", resolverDir)
+			if err != nil {
+				return fmt.Errorf("Unable to create the resolver Dir: %s %v: %s", stdOut, err, stdErr)
+			}
+		}
+		for _, domain := range network.HostResolverDomains(clusterDomain) {
+			path := filepath.Join(resolverDir, domain)
+			if err := crcos.WriteToFileAsRoot(fmt.Sprintf("Writing the resolver configuration for %s", domain), hostResolverFileContent, path, 0644); err != nil {
+				return fmt.Errorf("Unable to write the resolver file %s: %v", path, err)
+			}
+		}
+		return nil
+	}
+}
+
+// removeHostResolverFiles removes all the files using the DNS resolver of the
+// daemon, including the one of a cluster domain which is no longer configured
+func removeHostResolverFiles() error {
+	entries, err := os.ReadDir(resolverDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		path := filepath.Join(resolverDir, entry.Name())
+		if crcos.FileContentMatches(path, []byte(hostResolverFileContent)) != nil {
+			continue
+		}
+		logging.Debugf("Removing %s file", path)
+		if err := crcos.RemoveFileAsRoot(fmt.Sprintf("Removing file %s", path), path); err != nil {
+			return fmt.Errorf("Unable to delete the resolver file: %s %v", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package preflight
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	crcos "github.com/code-ready/crc/pkg/os"
+)
+
+const (
+	socketFilterFw = "/usr/libexec/ApplicationFirewall/socketfilterfw"
+	// bootpd answers the DHCP requests of the vmnet network of HyperKit
+	bootpdPath = "/usr/libexec/bootpd"
+)
+
+// bootpdExceptionMarker is created when crc adds the firewall exception of
+// bootpd, so that 'crc cleanup' does not remove an exception added by the
+// user
+var bootpdExceptionMarker = filepath.Join(constants.CrcBaseDir, "bootpd-firewall-exception")
+
+var firewallPreflightChecks = []Check{
+	{
+		configKeySuffix:    "check-bootpd-firewall-exception",
+		checkDescription:   "Checking if the application firewall accepts the DHCP requests of the instance",
+		check:              checkBootpdFirewallException,
+		fixDescription:     "Adding an application firewall exception for bootpd",
+		fix:                fixBootpdFirewallException,
+		cleanupDescription: "Removing the application firewall exception for bootpd",
+		cleanup:            removeBootpdFirewallException,
+		cleanupArea:        FirewallArea,
+
+		labels: labels{Os: Darwin, NetworkMode: System, VMDriver: Hyperkit},
+	},
+}
+
+var errFirewallBlocksAll = errors.New("The application firewall blocks all the incoming connections, disable 'Block all incoming connections' in the firewall options of the system settings")
+
+// applicationFirewall is the state of the application firewall, as given by
+// socketfilterfw
+type applicationFirewall struct {
+	globalState   string
+	blockAll      string
+	allowSigned   string
+	bootpdBlocked string
+}
+
+func getApplicationFirewall() (*applicationFirewall, error) {
+	var fw applicationFirewall
+	for _, query := range []struct {
+		args   []string
+		output *string
+	}{
+		{[]string{"--getglobalstate"}, &fw.globalState},
+		{[]string{"--getblockall"}, &fw.blockAll},
+		{[]string{"--getallowsigned"}, &fw.allowSigned},
+		{[]string{"--getappblocked", bootpdPath}, &fw.bootpdBlocked},
+	} {
+		stdout, _, err := crcos.RunWithDefaultLocale(socketFilterFw, query.args...)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot get the state of the application firewall: %w", err)
+		}
+		*query.output = strings.ToLower(stdout)
+	}
+	return &fw, nil
+}
+
+// check returns an error when the firewall rejects the connections to bootpd.
+// bootpd is allowed by default as a built-in signed application.
+func (fw *applicationFirewall) check() error {
+	switch {
+	case !strings.Contains(fw.globalState, "enabled"):
+		return nil
+	case !strings.Contains(fw.blockAll, "disabled"):
+		return errFirewallBlocksAll
+	case strings.Contains(fw.allowSigned, "built-in signed software enabled"):
+		return nil
+	case strings.Contains(fw.bootpdBlocked, "is permitted"):
+		return nil
+	default:
+		return fmt.Errorf("The application firewall does not permit the incoming connections of %s", bootpdPath)
+	}
+}
+
+func checkBootpdFirewallException() error {
+	fw, err := getApplicationFirewall()
+	if err != nil {
+		return err
+	}
+	return fw.check()
+}
+
+func fixBootpdFirewallException() error {
+	fw, err := getApplicationFirewall()
+	if err != nil {
+		return err
+	}
+	if err := fw.check(); err == nil || errors.Is(err, errFirewallBlocksAll) {
+		return err
+	}
+	if _, _, err := crcos.RunPrivileged(fmt.Sprintf("Adding %s to the application firewall", bootpdPath), socketFilterFw, "--add", bootpdPath); err != nil {
+		return err
+	}
+	if _, _, err := crcos.RunPrivileged(fmt.Sprintf("Permitting the incoming connections of %s", bootpdPath), socketFilterFw, "--unblockapp", bootpdPath); err != nil {
+		return err
+	}
+	return os.WriteFile(bootpdExceptionMarker, nil, 0600)
+}
+
+func removeBootpdFirewallException() error {
+	if !crcos.FileExists(bootpdExceptionMarker) {
+		logging.Debugf("The application firewall exception for %s was not added by crc", bootpdPath)
+		return nil
+	}
+	if _, _, err := crcos.RunPrivileged(fmt.Sprintf("Removing %s from the application firewall", bootpdPath), socketFilterFw, "--remove", bootpdPath); err != nil {
+		return err
+	}
+	return os.Remove(bootpdExceptionMarker)
+}
@@ -0,0 +1,177 @@
+package preflight
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	crcos "github.com/code-ready/crc/pkg/os"
+)
+
+const (
+	// crcBridge is the interface of the libvirt 'crc' network
+	crcBridge            = "crc"
+	firewalldLibvirtZone = "libvirt"
+	firewalldTrustedZone = "trusted"
+)
+
+// firewallPreflightChecks make sure the firewall of the host accepts the DNS
+// and DHCP requests of the instance on the libvirt 'crc' network, they are
+// run after the checks of the network as they need its interface
+var firewallPreflightChecks = []Check{
+	{
+		configKeySuffix:    "check-firewalld-crc-zone",
+		checkDescription:   "Checking if firewalld accepts the traffic of the libvirt 'crc' network",
+		check:              checkFirewalldCrcZone,
+		fixDescription:     "Adding the 'crc' network interface to a firewalld zone accepting its traffic",
+		fix:                fixFirewalldCrcZone,
+		cleanupDescription: "Removing the 'crc' network interface from the firewalld configuration",
+		cleanup:            removeFirewalldCrcZone,
+		cleanupArea:        FirewallArea,
+
+		labels: labels{Os: Linux, NetworkMode: System, VMDriver: Libvirt, LibvirtConnection: LibvirtSystem},
+	},
+	{
+		configKeySuffix:  "check-nftables-crc-network",
+		checkDescription: "Checking if the nftables rules accept the traffic of the libvirt 'crc' network",
+		check:            checkNftablesCrcNetwork,
+		fixDescription:   fmt.Sprintf("The nftables rules drop the incoming traffic of the instance, add a rule accepting the traffic of the '%s' interface, for instance with 'nft insert rule inet filter input iifname %s accept'", crcBridge, crcBridge),
+		flags:            SetupOnly | NoFix,
+
+		labels: labels{Os: Linux, NetworkMode: System, VMDriver: Libvirt, LibvirtConnection: LibvirtSystem},
+	},
+}
+
+func firewalldRunning() bool {
+	stdout, _, err := crcos.RunWithDefaultLocale("firewall-cmd", "--state")
+	return err == nil && strings.TrimSpace(stdout) == "running"
+}
+
+func checkFirewalldCrcZone() error {
+	if !firewalldRunning() {
+		logging.Debug("firewalld is not running")
+		return nil
+	}
+	// firewall-cmd fails when the interface is not in a zone
+	stdout, _, _ := crcos.RunWithDefaultLocale("firewall-cmd", "--get-zone-of-interface="+crcBridge)
+	return checkFirewalldZone(strings.TrimSpace(stdout))
+}
+
+// checkFirewalldZone returns an error when zone is not one of the zones
+// accepting the DNS and DHCP requests of the instance. libvirt adds the
+// interfaces of its networks to the 'libvirt' zone, the older releases leave
+// them in the default zone.
+func checkFirewalldZone(zone string) error {
+	switch zone {
+	case firewalldLibvirtZone, firewalldTrustedZone:
+		return nil
+	case "", "no zone":
+		return fmt.Errorf("The '%s' interface is not in a firewalld zone, the default zone may reject the DNS and DHCP requests of the instance", crcBridge)
+	default:
+		return fmt.Errorf("The '%s' interface is in the firewalld zone '%s' instead of '%s'", crcBridge, zone, firewalldLibvirtZone)
+	}
+}
+
+func fixFirewalldCrcZone() error {
+	zone := firewalldTrustedZone
+	if zones, _, err := crcos.RunWithDefaultLocale("firewall-cmd", "--get-zones"); err == nil && containsWord(zones, firewalldLibvirtZone) {
+		zone = firewalldLibvirtZone
+	}
+	// the runtime configuration is changed for the running network, the
+	// permanent one for the next starts of firewalld
+	for _, permanent := range []bool{false, true} {
+		args := []string{"firewall-cmd", "--zone=" + zone, "--change-interface=" + crcBridge}
+		if permanent {
+			args = append(args, "--permanent")
+		}
+		if _, _, err := crcos.RunPrivileged(fmt.Sprintf("Adding the '%s' interface to the firewalld zone '%s'", crcBridge, zone), args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeFirewalldCrcZone only removes the interface from the permanent
+// configuration, the runtime binding goes away with the libvirt network
+func removeFirewalldCrcZone() error {
+	if !firewalldRunning() {
+		return nil
+	}
+	stdout, _, err := crcos.RunWithDefaultLocale("firewall-cmd", "--permanent", "--get-zone-of-interface="+crcBridge)
+	zone := strings.TrimSpace(stdout)
+	if err != nil || zone == "" || zone == "no zone" {
+		return nil
+	}
+	_, _, err = crcos.RunPrivileged(fmt.Sprintf("Removing the '%s' interface from the firewalld zone '%s'", crcBridge, zone),
+		"firewall-cmd", "--permanent", "--zone="+zone, "--remove-interface="+crcBridge)
+	return err
+}
+
+func containsWord(s string, word string) bool {
+	for _, field := range strings.Fields(s) {
+		if field == word {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNftablesCrcNetwork reads the nftables rules when firewalld does not
+// manage them. crc cannot fix them, a packet accepted by a chain of crc is
+// still dropped by the chains of the other tables.
+func checkNftablesCrcNetwork() error {
+	if firewalldRunning() {
+		return nil
+	}
+	if _, err := exec.LookPath("nft"); err != nil {
+		logging.Debug("nft is not installed")
+		return nil
+	}
+	ruleset, _, err := crcos.RunPrivileged("Reading the nftables rules", "nft", "list", "ruleset")
+	if err != nil {
+		return err
+	}
+	return checkNftablesRuleset(ruleset)
+}
+
+// checkNftablesRuleset returns an error when an input chain of ruleset, in the
+// format of 'nft list ruleset', drops the packets by default without
+// accepting the traffic of the 'crc' interface
+func checkNftablesRuleset(ruleset string) error {
+	var (
+		chain      string
+		depth      int
+		chainDepth int
+		drops      bool
+		accepts    bool
+	)
+	scanner := bufio.NewScanner(strings.NewReader(ruleset))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "chain ") {
+			chain = strings.TrimSuffix(strings.TrimSpace(strings.TrimPrefix(line, "chain ")), " {")
+			chainDepth = depth
+			drops, accepts = false, false
+		}
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		if chain == "" {
+			continue
+		}
+		if strings.Contains(line, "hook input") && strings.Contains(line, "policy drop") {
+			drops = true
+		}
+		if (strings.Contains(line, fmt.Sprintf(`iifname "%s"`, crcBridge)) || strings.Contains(line, fmt.Sprintf(`iif "%s"`, crcBridge))) &&
+			strings.HasSuffix(line, "accept") {
+			accepts = true
+		}
+		if depth == chainDepth {
+			if drops && !accepts {
+				return fmt.Errorf("The nftables chain '%s' drops the incoming traffic of the '%s' interface", chain, crcBridge)
+			}
+			chain = ""
+		}
+	}
+	return scanner.Err()
+}
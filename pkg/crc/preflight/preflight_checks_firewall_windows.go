@@ -0,0 +1,93 @@
+package preflight
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/machine/hyperv"
+	winnet "github.com/code-ready/crc/pkg/os/windows/network"
+	"github.com/code-ready/crc/pkg/os/windows/powershell"
+)
+
+// hypervFirewallRuleName is the name of the Windows Defender Firewall rule
+// accepting the DNS and DHCP requests of the instance on the Hyper-V switch,
+// they are answered by the Internet Connection Sharing service of the host
+const hypervFirewallRuleName = "crc-hyperv-dns-dhcp"
+
+var firewallPreflightChecks = []Check{
+	{
+		configKeySuffix:    "check-hyperv-firewall-rule",
+		checkDescription:   "Checking if Windows Defender Firewall accepts the DNS and DHCP requests of the instance",
+		check:              checkHypervFirewallRule,
+		fixDescription:     "Adding a Windows Defender Firewall rule for the DNS and DHCP requests of the instance",
+		fix:                fixHypervFirewallRule,
+		cleanupDescription: "Removing the Windows Defender Firewall rule of the instance",
+		cleanup:            removeHypervFirewallRule,
+		cleanupArea:        FirewallArea,
+
+		labels: labels{Os: Windows, NetworkMode: System, VMDriver: Hyperv},
+	},
+}
+
+// hypervSwitchInterface returns the name of the host interface of the
+// Hyper-V switch used by the instance
+func hypervSwitchInterface() (string, error) {
+	exists, switchName := winnet.SelectSwitchByNameOrDefault(hyperv.AlternativeNetwork)
+	if !exists {
+		return "", fmt.Errorf("Virtual Switch not found")
+	}
+	return fmt.Sprintf("vEthernet (%s)", switchName), nil
+}
+
+func checkHypervFirewallRule() error {
+	iface, err := hypervSwitchInterface()
+	if err != nil {
+		return err
+	}
+	stdout, _, err := powershell.Execute(fmt.Sprintf(`$rule = Get-NetFirewallRule -Name '%s' -ErrorAction SilentlyContinue; if ($rule) { "$($rule.Enabled) $(($rule | Get-NetFirewallInterfaceFilter).InterfaceAlias)" }`, hypervFirewallRuleName))
+	if err != nil {
+		return err
+	}
+	return checkFirewallRule(stdout, iface)
+}
+
+// checkFirewallRule checks the output of checkHypervFirewallRule, the state
+// of the rule followed by its interface
+func checkFirewallRule(output string, iface string) error {
+	output = strings.TrimSpace(output)
+	if output == "" {
+		return fmt.Errorf("The Windows Defender Firewall rule '%s' does not exist", hypervFirewallRuleName)
+	}
+	fields := strings.SplitN(output, " ", 2)
+	enabled, ruleIface := fields[0], ""
+	if len(fields) == 2 {
+		ruleIface = fields[1]
+	}
+	if enabled != "True" {
+		return fmt.Errorf("The Windows Defender Firewall rule '%s' is disabled", hypervFirewallRuleName)
+	}
+	if ruleIface != iface {
+		return fmt.Errorf("The Windows Defender Firewall rule '%s' is for the interface '%s' instead of '%s'", hypervFirewallRuleName, ruleIface, iface)
+	}
+	return nil
+}
+
+func fixHypervFirewallRule() error {
+	iface, err := hypervSwitchInterface()
+	if err != nil {
+		return err
+	}
+	cmd := fmt.Sprintf(`Remove-NetFirewallRule -Name '%s' -ErrorAction SilentlyContinue; New-NetFirewallRule -Name '%s' -DisplayName 'CodeReady Containers DNS and DHCP' -Direction Inbound -Action Allow -Protocol UDP -LocalPort 53,67 -InterfaceAlias '%s'`,
+		hypervFirewallRuleName, hypervFirewallRuleName, iface)
+	_, _, err = powershell.ExecuteAsAdmin("adding the Windows Defender Firewall rule of the instance", cmd)
+	return err
+}
+
+func removeHypervFirewallRule() error {
+	stdout, _, err := powershell.Execute(fmt.Sprintf(`Get-NetFirewallRule -Name '%s' -ErrorAction SilentlyContinue | ForEach-Object { $_.Name }`, hypervFirewallRuleName))
+	if err != nil || strings.TrimSpace(stdout) == "" {
+		return nil
+	}
+	_, _, err = powershell.ExecuteAsAdmin("removing the Windows Defender Firewall rule of the instance", fmt.Sprintf(`Remove-NetFirewallRule -Name '%s'`, hypervFirewallRuleName))
+	return err
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/code-ready/crc/pkg/crc/systemd/states"
 	crcos "github.com/code-ready/crc/pkg/os"
 	"github.com/code-ready/crc/pkg/os/linux"
+	"golang.org/x/sys/unix"
 	"libvirt.org/go/libvirtxml"
 )
 
@@ -43,6 +44,43 @@ func checkRunningInsideWSL2() error {
 	return nil
 }
 
+func checkIOMMUEnabled() error {
+	if !linux.IOMMUEnabled() {
+		return fmt.Errorf("The IOMMU is not enabled, it is needed to assign a GPU to the instance")
+	}
+	return nil
+}
+
+func checkPassthroughGPU() error {
+	devices, err := linux.PassthroughGPUs()
+	if err != nil {
+		return err
+	}
+	if len(devices) == 0 {
+		return fmt.Errorf("No GPU is bound to the vfio-pci driver")
+	}
+	for _, device := range devices {
+		logging.Debugf("Device %s (vendor %s, class %s) will be assigned to the instance", device.Address, device.Vendor, device.Class)
+	}
+	return nil
+}
+
+func checkKvmNested() error {
+	for _, module := range []string{"kvm_intel", "kvm_amd"} {
+		value, err := ioutil.ReadFile(filepath.Join("/sys/module", module, "parameters", "nested"))
+		if err != nil {
+			continue
+		}
+		switch strings.TrimSpace(string(value)) {
+		case "Y", "1":
+			return nil
+		default:
+			return fmt.Errorf("Nested virtualization is disabled in the %s module", module)
+		}
+	}
+	return fmt.Errorf("The kvm_intel or kvm_amd module is not loaded")
+}
+
 func checkVirtualizationEnabled() error {
 	logging.Debug("Checking if the vmx/svm flags are present in /proc/cpuinfo")
 	// Check if the cpu flags vmx or svm is present
@@ -102,9 +140,9 @@ func fixKvmEnabled() error {
 }
 
 func getLibvirtCapabilities() (*libvirtxml.Caps, error) {
-	stdOut, _, err := crcos.RunWithDefaultLocale("virsh", "--readonly", "--connect", "qemu:///system", "capabilities")
+	stdOut, _, err := crcos.RunWithDefaultLocale("virsh", "--readonly", "--connect", libvirt.URI, "capabilities")
 	if err != nil {
-		stdOut, _, err = crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///session", "capabilities")
+		stdOut, _, err = crcos.RunWithDefaultLocale("virsh", "--connect", constants.LibvirtSessionURI, "capabilities")
 		if err != nil {
 			return nil, fmt.Errorf("Failed to run 'virsh capabilities': %v", err)
 		}
@@ -118,6 +156,26 @@ func getLibvirtCapabilities() (*libvirtxml.Caps, error) {
 	return caps, nil
 }
 
+func checkQemuInstalled() error {
+	for _, executable := range []string{"qemu-system-x86_64", "qemu-img"} {
+		logging.Debugf("Checking if '%s' is available", executable)
+		path, err := exec.LookPath(executable)
+		if err != nil {
+			return fmt.Errorf("%s was not found in path", executable)
+		}
+		logging.Debugf("'%s' was found in %s", executable, path)
+	}
+	return nil
+}
+
+func checkKvmAccessible() error {
+	logging.Debug("Checking if /dev/kvm is readable and writable by the current user")
+	if err := unix.Access("/dev/kvm", unix.R_OK|unix.W_OK); err != nil {
+		return fmt.Errorf("/dev/kvm cannot be used by the current user: %v", err)
+	}
+	return nil
+}
+
 func checkLibvirtInstalled() error {
 	logging.Debug("Checking if 'virsh' is available")
 	path, err := exec.LookPath("virsh")
@@ -496,7 +554,7 @@ func fixMachineDriverLibvirtInstalled() error {
 
 func checkLibvirtCrcNetworkAvailable() error {
 	logging.Debug("Checking if libvirt 'crc' network exists")
-	_, _, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "net-info", "crc")
+	_, _, err := crcos.RunWithDefaultLocale("virsh", "--connect", libvirt.URI, "net-info", "crc")
 	if err != nil {
 		return fmt.Errorf("Libvirt network crc not found")
 	}
@@ -535,11 +593,11 @@ func fixLibvirtCrcNetworkAvailable() error {
 	// For time being we are going to override the crc network according what we have in our binary template.
 	// We also don't care about the error or output from those commands atm.
 	// #nosec G204
-	_, _, _ = crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "net-destroy", libvirt.DefaultNetwork)
+	_, _, _ = crcos.RunWithDefaultLocale("virsh", "--connect", libvirt.URI, "net-destroy", libvirt.DefaultNetwork)
 	// #nosec G204
-	_, _, _ = crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "net-undefine", libvirt.DefaultNetwork)
+	_, _, _ = crcos.RunWithDefaultLocale("virsh", "--connect", libvirt.URI, "net-undefine", libvirt.DefaultNetwork)
 	// Create the network according to our defined template
-	cmd := exec.Command("virsh", "--connect", "qemu:///system", "net-define", "/dev/stdin")
+	cmd := exec.Command("virsh", "--connect", libvirt.URI, "net-define", "/dev/stdin")
 	cmd.Stdin = strings.NewReader(netXMLDef)
 	buf := new(bytes.Buffer)
 	cmd.Stderr = buf
@@ -554,19 +612,19 @@ func fixLibvirtCrcNetworkAvailable() error {
 
 func removeLibvirtCrcNetwork() error {
 	logging.Debug("Removing libvirt 'crc' network")
-	_, _, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "net-info", libvirt.DefaultNetwork)
+	_, _, err := crcos.RunWithDefaultLocale("virsh", "--connect", libvirt.URI, "net-info", libvirt.DefaultNetwork)
 	if err != nil {
 		// Ignore if no crc network exists for libvirt
 		// User may have manually deleted the `crc` network from libvirt
 		return nil
 	}
-	_, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "net-destroy", libvirt.DefaultNetwork)
+	_, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", libvirt.URI, "net-destroy", libvirt.DefaultNetwork)
 	if err != nil {
 		logging.Debugf("%v : %s", err, stderr)
 		return fmt.Errorf("Failed to destroy libvirt 'crc' network")
 	}
 
-	_, stderr, err = crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "net-undefine", libvirt.DefaultNetwork)
+	_, stderr, err = crcos.RunWithDefaultLocale("virsh", "--connect", libvirt.URI, "net-undefine", libvirt.DefaultNetwork)
 	if err != nil {
 		logging.Debugf("%v : %s", err, stderr)
 		return fmt.Errorf("Failed to undefine libvirt 'crc' network")
@@ -576,20 +634,20 @@ func removeLibvirtCrcNetwork() error {
 }
 
 func removeCrcVM() error {
-	stdout, _, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "domstate", constants.DefaultName)
+	stdout, _, err := crcos.RunWithDefaultLocale("virsh", "--connect", libvirt.URI, "domstate", constants.DefaultName)
 	if err != nil {
 		//  User may have run `crc delete` before `crc cleanup`
 		//  in that case there is no crc vm so return early.
 		return nil
 	}
 	if strings.TrimSpace(stdout) == "running" {
-		_, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "destroy", constants.DefaultName)
+		_, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", libvirt.URI, "destroy", constants.DefaultName)
 		if err != nil {
 			logging.Debugf("%v : %s", err, stderr)
 			return fmt.Errorf("Failed to destroy 'crc' VM")
 		}
 	}
-	_, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "undefine", constants.DefaultName)
+	_, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", libvirt.URI, "undefine", constants.DefaultName)
 	if err != nil {
 		logging.Debugf("%v : %s", err, stderr)
 		return fmt.Errorf("Failed to undefine 'crc' VM")
@@ -599,18 +657,18 @@ func removeCrcVM() error {
 }
 
 func removeLibvirtStoragePool() error {
-	_, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "pool-info", constants.DefaultName)
+	_, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", libvirt.URI, "pool-info", constants.DefaultName)
 	if err != nil {
 		logging.Debugf("%v : %s", err, stderr)
 		// Pool does not exist
 		return nil
 	}
-	_, stderr, err = crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "pool-destroy", constants.DefaultName)
+	_, stderr, err = crcos.RunWithDefaultLocale("virsh", "--connect", libvirt.URI, "pool-destroy", constants.DefaultName)
 	if err != nil {
 		logging.Debugf("%v : %s", err, stderr)
 		// ignore error, we want to try to delete the pool regardless of success or not
 	}
-	_, stderr, err = crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "pool-undefine", constants.DefaultName)
+	_, stderr, err = crcos.RunWithDefaultLocale("virsh", "--connect", libvirt.URI, "pool-undefine", constants.DefaultName)
 	if err != nil {
 		logging.Debugf("%v : %s", err, stderr)
 		return fmt.Errorf("Failed to undefine 'crc' libvirt storage pool")
@@ -631,7 +689,7 @@ func trimSpacesFromXML(str string) string {
 
 func checkLibvirtCrcNetworkDefinition() error {
 	logging.Debug("Checking if libvirt 'crc' definition is up to date")
-	stdOut, _, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "net-dumpxml", "--inactive", "crc")
+	stdOut, _, err := crcos.RunWithDefaultLocale("virsh", "--connect", libvirt.URI, "net-dumpxml", "--inactive", "crc")
 	if err != nil {
 		return fmt.Errorf("Failed to get 'crc' network XML: %s", err)
 	}
@@ -655,7 +713,7 @@ func checkLibvirtCrcNetworkDefinition() error {
 
 func checkLibvirtCrcNetworkActive() error {
 	logging.Debug("Checking if libvirt 'crc' network is active")
-	stdOut, _, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "net-info", "crc")
+	stdOut, _, err := crcos.RunWithDefaultLocale("virsh", "--connect", libvirt.URI, "net-info", "crc")
 	if err != nil {
 		return fmt.Errorf("Failed to query 'crc' network information")
 	}
@@ -673,11 +731,11 @@ func checkLibvirtCrcNetworkActive() error {
 
 func fixLibvirtCrcNetworkActive() error {
 	logging.Debug("Starting libvirt 'crc' network")
-	stdOut, stdErr, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "net-start", "crc")
+	stdOut, stdErr, err := crcos.RunWithDefaultLocale("virsh", "--connect", libvirt.URI, "net-start", "crc")
 	if err != nil {
 		return fmt.Errorf("Failed to start libvirt 'crc' network %s %v: %s", stdOut, err, stdErr)
 	}
-	stdOut, stdErr, err = crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "net-autostart", "crc")
+	stdOut, stdErr, err = crcos.RunWithDefaultLocale("virsh", "--connect", libvirt.URI, "net-autostart", "crc")
 	if err != nil {
 		return fmt.Errorf("Failed to autostart libvirt 'crc' network %s %v: %s", stdOut, err, stdErr)
 	}
@@ -8,8 +8,12 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
 
+	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/crc/systemd"
 	"github.com/code-ready/crc/pkg/crc/systemd/states"
 	crcos "github.com/code-ready/crc/pkg/os"
@@ -45,29 +49,33 @@ var nmPreflightChecks = []Check{
 	},
 }
 
-var dnsmasqPreflightChecks = []Check{
-	{
-		configKeySuffix:    "check-network-manager-config",
-		checkDescription:   "Checking if /etc/NetworkManager/conf.d/crc-nm-dnsmasq.conf exists",
-		check:              checkCrcNetworkManagerConfig,
-		fixDescription:     "Writing Network Manager config for crc",
-		fix:                fixCrcNetworkManagerConfig,
-		cleanupDescription: "Removing /etc/NetworkManager/conf.d/crc-nm-dnsmasq.conf file",
-		cleanup:            removeCrcNetworkManagerConfig,
-
-		labels: labels{Os: Linux, NetworkMode: System, DNS: Dnsmasq},
-	},
-	{
-		configKeySuffix:    "check-crc-dnsmasq-file",
-		checkDescription:   "Checking if /etc/NetworkManager/dnsmasq.d/crc.conf exists",
-		check:              checkCrcDnsmasqConfigFile,
-		fixDescription:     "Writing dnsmasq config for crc",
-		fix:                fixCrcDnsmasqConfigFile,
-		cleanupDescription: "Removing /etc/NetworkManager/dnsmasq.d/crc.conf file",
-		cleanup:            removeCrcDnsmasqConfigFile,
-
-		labels: labels{Os: Linux, NetworkMode: System, DNS: Dnsmasq},
-	},
+func dnsmasqPreflightChecks(clusterDomain string) []Check {
+	return []Check{
+		{
+			configKeySuffix:    "check-network-manager-config",
+			checkDescription:   "Checking if /etc/NetworkManager/conf.d/crc-nm-dnsmasq.conf exists",
+			check:              checkCrcNetworkManagerConfig,
+			fixDescription:     "Writing Network Manager config for crc",
+			fix:                fixCrcNetworkManagerConfig,
+			cleanupDescription: "Removing /etc/NetworkManager/conf.d/crc-nm-dnsmasq.conf file",
+			cleanup:            removeCrcNetworkManagerConfig,
+			cleanupArea:        DNSArea,
+
+			labels: labels{Os: Linux, NetworkMode: System, DNS: Dnsmasq},
+		},
+		{
+			configKeySuffix:    "check-crc-dnsmasq-file",
+			checkDescription:   "Checking if /etc/NetworkManager/dnsmasq.d/crc.conf exists",
+			check:              checkCrcDnsmasqConfigFile(clusterDomain),
+			fixDescription:     "Writing dnsmasq config for crc",
+			fix:                fixCrcDnsmasqConfigFile(clusterDomain),
+			cleanupDescription: "Removing /etc/NetworkManager/dnsmasq.d/crc.conf file",
+			cleanup:            removeCrcDnsmasqConfigFile,
+			cleanupArea:        DNSArea,
+
+			labels: labels{Os: Linux, NetworkMode: System, DNS: Dnsmasq},
+		},
+	}
 }
 
 var (
@@ -76,6 +84,8 @@ var (
 	crcDnsmasqConfigPath = filepath.Join(crcNetworkManagerRootPath, "dnsmasq.d", "crc.conf")
 	crcDnsmasqConfig     = `server=/apps-crc.testing/192.168.130.11
 server=/crc.testing/192.168.130.11
+`
+	crcDnsmasqClusterDomainConfig = `server=/%s/192.168.130.11
 `
 
 	crcNetworkManagerConfigPath = filepath.Join(crcNetworkManagerRootPath, "conf.d", "crc-nm-dnsmasq.conf")
@@ -105,42 +115,62 @@ dns=dnsmasq
 
 export LC_ALL=C
 
-systemd-resolve --interface crc --set-dns 192.168.130.11 --set-domain ~testing
+systemd-resolve --interface crc --set-dns 192.168.130.11 --set-domain ~testing%s
 
 exit 0
 `
 )
 
-var systemdResolvedPreflightChecks = []Check{
-	{
-		configKeySuffix:  "check-dnsmasq-network-manager-config",
-		checkDescription: "Checking if dnsmasq configurations file exist for NetworkManager",
-		check:            checkCrcDnsmasqAndNetworkManagerConfigFile,
-		fixDescription:   "Removing dnsmasq configuration file for NetworkManager",
-		fix:              fixCrcDnsmasqAndNetworkManagerConfigFile,
+// getCrcDnsmasqConfig returns the dnsmasq configuration forwarding the
+// queries for the domains of the cluster to the instance
+func getCrcDnsmasqConfig(clusterDomain string) string {
+	if clusterDomain == "" {
+		return crcDnsmasqConfig
+	}
+	return crcDnsmasqConfig + fmt.Sprintf(crcDnsmasqClusterDomainConfig, clusterDomain)
+}
 
-		labels: labels{Os: Linux, NetworkMode: System, DNS: SystemdResolved},
-	},
-	{
-		configKeySuffix:  "check-systemd-resolved-running",
-		checkDescription: "Checking if the systemd-resolved service is running",
-		check:            checkSystemdResolvedIsRunning,
-		fixDescription:   "systemd-resolved is required on this distribution. Please make sure it is installed and running manually",
-		flags:            NoFix,
+func getCrcNetworkManagerDispatcherConfig(clusterDomain string) string {
+	extraDomain := ""
+	if clusterDomain != "" {
+		extraDomain = fmt.Sprintf(" --set-domain ~%s", clusterDomain)
+	}
+	return fmt.Sprintf(crcNetworkManagerDispatcherConfig, extraDomain)
+}
 
-		labels: labels{Os: Linux, NetworkMode: System, DNS: SystemdResolved},
-	},
-	{
-		configKeySuffix:    "check-network-manager-dispatcher-file",
-		checkDescription:   fmt.Sprintf("Checking if %s exists", crcNetworkManagerDispatcherPath),
-		check:              checkCrcNetworkManagerDispatcherFile,
-		fixDescription:     "Writing NetworkManager dispatcher file for crc",
-		fix:                fixCrcNetworkManagerDispatcherFile,
-		cleanupDescription: fmt.Sprintf("Removing %s file", crcNetworkManagerDispatcherPath),
-		cleanup:            removeCrcNetworkManagerDispatcherFile,
-
-		labels: labels{Os: Linux, NetworkMode: System, DNS: SystemdResolved},
-	},
+func systemdResolvedPreflightChecks(clusterDomain string) []Check {
+	return []Check{
+		{
+			configKeySuffix:  "check-dnsmasq-network-manager-config",
+			checkDescription: "Checking if dnsmasq configurations file exist for NetworkManager",
+			check:            checkCrcDnsmasqAndNetworkManagerConfigFile,
+			fixDescription:   "Removing dnsmasq configuration file for NetworkManager",
+			fix:              fixCrcDnsmasqAndNetworkManagerConfigFile,
+
+			labels: labels{Os: Linux, NetworkMode: System, DNS: SystemdResolved},
+		},
+		{
+			configKeySuffix:  "check-systemd-resolved-running",
+			checkDescription: "Checking if the systemd-resolved service is running",
+			check:            checkSystemdResolvedIsRunning,
+			fixDescription:   "systemd-resolved is required on this distribution. Please make sure it is installed and running manually",
+			flags:            NoFix,
+
+			labels: labels{Os: Linux, NetworkMode: System, DNS: SystemdResolved},
+		},
+		{
+			configKeySuffix:    "check-network-manager-dispatcher-file",
+			checkDescription:   fmt.Sprintf("Checking if %s exists", crcNetworkManagerDispatcherPath),
+			check:              checkCrcNetworkManagerDispatcherFile(clusterDomain),
+			fixDescription:     "Writing NetworkManager dispatcher file for crc",
+			fix:                fixCrcNetworkManagerDispatcherFile(clusterDomain),
+			cleanupDescription: fmt.Sprintf("Removing %s file", crcNetworkManagerDispatcherPath),
+			cleanup:            removeCrcNetworkManagerDispatcherFile,
+			cleanupArea:        DNSArea,
+
+			labels: labels{Os: Linux, NetworkMode: System, DNS: SystemdResolved},
+		},
+	}
 }
 
 func fixNetworkManagerConfigFile(path string, content string, perms os.FileMode) error {
@@ -187,25 +217,29 @@ func removeNetworkManagerConfigFile(path string) error {
 	return nil
 }
 
-func checkCrcDnsmasqConfigFile() error {
-	logging.Debug("Checking dnsmasq configuration")
-	err := crcos.FileContentMatches(crcDnsmasqConfigPath, []byte(crcDnsmasqConfig))
-	if err != nil {
-		return err
+func checkCrcDnsmasqConfigFile(clusterDomain string) func() error {
+	return func() error {
+		logging.Debug("Checking dnsmasq configuration")
+		err := crcos.FileContentMatches(crcDnsmasqConfigPath, []byte(getCrcDnsmasqConfig(clusterDomain)))
+		if err != nil {
+			return err
+		}
+		logging.Debug("dnsmasq configuration is good")
+		return nil
 	}
-	logging.Debug("dnsmasq configuration is good")
-	return nil
 }
 
-func fixCrcDnsmasqConfigFile() error {
-	logging.Debug("Fixing dnsmasq configuration")
-	err := fixNetworkManagerConfigFile(crcDnsmasqConfigPath, crcDnsmasqConfig, 0644)
-	if err != nil {
-		return err
-	}
+func fixCrcDnsmasqConfigFile(clusterDomain string) func() error {
+	return func() error {
+		logging.Debug("Fixing dnsmasq configuration")
+		err := fixNetworkManagerConfigFile(crcDnsmasqConfigPath, getCrcDnsmasqConfig(clusterDomain), 0644)
+		if err != nil {
+			return err
+		}
 
-	logging.Debug("dnsmasq configuration fixed")
-	return nil
+		logging.Debug("dnsmasq configuration fixed")
+		return nil
+	}
 }
 
 func removeCrcDnsmasqConfigFile() error {
@@ -278,29 +312,33 @@ func checkSystemdResolvedIsRunning() error {
 	return checkSystemdServiceRunning("systemd-resolved.service")
 }
 
-func checkCrcNetworkManagerDispatcherFile() error {
-	logging.Debug("Checking NetworkManager dispatcher file for crc network")
-	err := crcos.FileContentMatches(crcNetworkManagerDispatcherPath, []byte(crcNetworkManagerDispatcherConfig))
-	if err != nil {
-		return err
+func checkCrcNetworkManagerDispatcherFile(clusterDomain string) func() error {
+	return func() error {
+		logging.Debug("Checking NetworkManager dispatcher file for crc network")
+		err := crcos.FileContentMatches(crcNetworkManagerDispatcherPath, []byte(getCrcNetworkManagerDispatcherConfig(clusterDomain)))
+		if err != nil {
+			return err
+		}
+		logging.Debug("Dispatcher file has the expected content")
+		return nil
 	}
-	logging.Debug("Dispatcher file has the expected content")
-	return nil
 }
 
-func fixCrcNetworkManagerDispatcherFile() error {
-	logging.Debug("Fixing NetworkManager dispatcher configuration")
+func fixCrcNetworkManagerDispatcherFile(clusterDomain string) func() error {
+	return func() error {
+		logging.Debug("Fixing NetworkManager dispatcher configuration")
 
-	// Remove dispatcher script which was used in crc 1.20 - it's been moved to a new location
-	_ = removeNetworkManagerConfigFile(crcNetworkManagerOldDispatcherPath)
+		// Remove dispatcher script which was used in crc 1.20 - it's been moved to a new location
+		_ = removeNetworkManagerConfigFile(crcNetworkManagerOldDispatcherPath)
 
-	err := fixNetworkManagerConfigFile(crcNetworkManagerDispatcherPath, crcNetworkManagerDispatcherConfig, 0755)
-	if err != nil {
-		return err
-	}
+		err := fixNetworkManagerConfigFile(crcNetworkManagerDispatcherPath, getCrcNetworkManagerDispatcherConfig(clusterDomain), 0755)
+		if err != nil {
+			return err
+		}
 
-	logging.Debug("NetworkManager dispatcher configuration fixed")
-	return nil
+		logging.Debug("NetworkManager dispatcher configuration fixed")
+		return nil
+	}
 }
 
 func removeCrcNetworkManagerDispatcherFile() error {
@@ -332,3 +370,114 @@ func fixCrcDnsmasqAndNetworkManagerConfigFile() error {
 	}
 	return nil
 }
+
+func hostResolverPreflightChecks(clusterDomain string) []Check {
+	return []Check{
+		{
+			configKeySuffix:    "check-systemd-resolved-crc-config",
+			checkDescription:   fmt.Sprintf("Checking if %s exists", crcResolvedConfigPath),
+			check:              checkCrcResolvedConfig(clusterDomain),
+			fixDescription:     "Configuring systemd-resolved to use the crc daemon for the cluster domains",
+			fix:                fixCrcResolvedConfig(clusterDomain),
+			cleanupDescription: fmt.Sprintf("Removing %s file", crcResolvedConfigPath),
+			cleanup:            removeCrcResolvedConfig,
+			cleanupArea:        DNSArea,
+
+			labels: labels{Os: Linux, NetworkMode: User, DNS: SystemdResolved},
+		},
+	}
+}
+
+var crcResolvedConfigPath = filepath.Join(string(filepath.Separator), "etc", "systemd", "resolved.conf.d", "crc.conf")
+
+// systemd-resolved accepts a port in the DNS servers since version 246
+const minSystemdVersionForResolvedPort = 246
+
+// getCrcResolvedConfig returns the systemd-resolved configuration sending the
+// queries for the cluster domains to the DNS resolver of the daemon
+func getCrcResolvedConfig(clusterDomain string) string {
+	var routingDomains []string
+	for _, domain := range network.HostResolverDomains(clusterDomain) {
+		routingDomains = append(routingDomains, "~"+domain)
+	}
+	return fmt.Sprintf(`# Generated by CRC
+[Resolve]
+DNS=127.0.0.1:%d
+Domains=%s
+`, constants.HostDNSResolverPort, strings.Join(routingDomains, " "))
+}
+
+func systemdVersion() (int, error) {
+	stdout, _, err := crcos.RunWithDefaultLocale("systemctl", "--version")
+	if err != nil {
+		return 0, err
+	}
+	return parseSystemdVersion(stdout)
+}
+
+// parseSystemdVersion parses the first line of 'systemctl --version', such as
+// 'systemd 249 (v249.9-1.fc35)'
+func parseSystemdVersion(output string) (int, error) {
+	fields := strings.Fields(output)
+	if len(fields) < 2 || fields[0] != "systemd" {
+		return 0, fmt.Errorf("Unexpected systemctl --version output: %s", output)
+	}
+	return strconv.Atoi(fields[1])
+}
+
+func resolvedSupportsPort() bool {
+	version, err := systemdVersion()
+	if err != nil {
+		logging.Debugf("Cannot get the systemd version: %v", err)
+		return false
+	}
+	return version >= minSystemdVersionForResolvedPort
+}
+
+func checkCrcResolvedConfig(clusterDomain string) func() error {
+	return func() error {
+		if !resolvedSupportsPort() {
+			// the cluster hostnames are added to /etc/hosts instead
+			logging.Debugf("systemd-resolved is too old to use the crc daemon for the cluster domains")
+			return nil
+		}
+		return crcos.FileContentMatches(crcResolvedConfigPath, []byte(getCrcResolvedConfig(clusterDomain)))
+	}
+}
+
+func fixCrcResolvedConfig(clusterDomain string) func() error {
+	return func() error {
+		dir := filepath.Dir(crcResolvedConfigPath)
+		if _, _, err := crcos.RunPrivileged(fmt.Sprintf("Creating dir %s", dir), "mkdir", "-p", dir); err != nil {
+			return fmt.Errorf("Failed to create %s: %v", dir, err)
+		}
+		err := crcos.WriteToFileAsRoot(
+			fmt.Sprintf("Writing systemd-resolved configuration to %s", crcResolvedConfigPath),
+			getCrcResolvedConfig(clusterDomain),
+			crcResolvedConfigPath,
+			0644,
+		)
+		if err != nil {
+			return fmt.Errorf("Failed to write config file: %s: %v", crcResolvedConfigPath, err)
+		}
+		return restartSystemdResolved()
+	}
+}
+
+func removeCrcResolvedConfig() error {
+	if !crcos.FileExists(crcResolvedConfigPath) {
+		return nil
+	}
+	if err := crcos.RemoveFileAsRoot(fmt.Sprintf("Removing systemd-resolved configuration file %s", crcResolvedConfigPath), crcResolvedConfigPath); err != nil {
+		return fmt.Errorf("Failed to remove %s: %v", crcResolvedConfigPath, err)
+	}
+	return restartSystemdResolved()
+}
+
+func restartSystemdResolved() error {
+	logging.Debug("Restarting systemd-resolved")
+	if err := systemd.NewHostSystemdCommander().Restart("systemd-resolved"); err != nil {
+		return fmt.Errorf("Failed to restart systemd-resolved: %v", err)
+	}
+	return nil
+}
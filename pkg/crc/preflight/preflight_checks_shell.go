@@ -0,0 +1,133 @@
+package preflight
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// shellCompletionPreflightChecks are only run when the
+// install-shell-completion setting is enabled, their cleanup is always run
+var shellCompletionPreflightChecks = []Check{
+	{
+		configKeySuffix:    "check-shell-completion",
+		checkDescription:   "Checking if the completion of the crc commands is loaded by the user shell",
+		check:              checkShellCompletion,
+		fixDescription:     "Loading the completion of the crc commands in the profile of the user shell",
+		fix:                fixShellCompletion,
+		cleanupDescription: "Removing the completion of the crc commands from the profile of the user shell",
+		cleanup:            removeShellCompletion,
+		cleanupArea:        ShellArea,
+		flags:              SetupOnly,
+
+		labels: None,
+	},
+}
+
+// clientsInPathPreflightChecks are only run when the add-clients-to-path
+// setting is enabled, their cleanup is always run
+var clientsInPathPreflightChecks = []Check{
+	{
+		configKeySuffix:    "check-clients-in-path",
+		checkDescription:   "Checking if the oc and podman clients are in the PATH of the user",
+		check:              checkClientsInPath,
+		fixDescription:     "Adding the directory of the oc and podman clients to the PATH of the user",
+		fix:                fixClientsInPath,
+		cleanupDescription: "Removing the directory of the oc and podman clients from the PATH of the user",
+		cleanup:            removeClientsFromPath,
+		cleanupArea:        ShellArea,
+		flags:              SetupOnly,
+
+		labels: None,
+	},
+}
+
+const completionBlock = "completion"
+
+// The profile blocks are the lines crc manages in the shell profiles, they
+// are delimited by markers so that they can be updated and removed without
+// touching the lines of the user
+func profileBlockMarkers(name string) (string, string) {
+	return fmt.Sprintf("# >>> crc %s >>>", name), fmt.Sprintf("# <<< crc %s <<<", name)
+}
+
+func profileBlock(name, content string) string {
+	begin, end := profileBlockMarkers(name)
+	return fmt.Sprintf("%s\n%s\n%s\n", begin, strings.TrimRight(content, "\n"), end)
+}
+
+// splitProfile returns the lines of profile before and after the block name,
+// and the block itself, which is empty when profile does not have it
+func splitProfile(profile, name string) (string, string, string) {
+	begin, end := profileBlockMarkers(name)
+	start := strings.Index(profile, begin+"\n")
+	if start == -1 {
+		return profile, "", ""
+	}
+	length := strings.Index(profile[start:], end+"\n")
+	if length == -1 {
+		return profile, "", ""
+	}
+	stop := start + length + len(end) + 1
+	return profile[:start], profile[start:stop], profile[stop:]
+}
+
+// hasProfileBlock tells if the block name of the profile at path has the
+// given content
+func hasProfileBlock(path, name, content string) (bool, error) {
+	profile, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	_, block, _ := splitProfile(string(profile), name)
+	return block == profileBlock(name, content), nil
+}
+
+// writeProfileBlock adds the block name with the given content at the end
+// of the profile at path, or replaces it when the profile already has it
+func writeProfileBlock(path, name, content string) error {
+	profile, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	before, block, after := splitProfile(string(profile), name)
+	if block == "" && before != "" && !strings.HasSuffix(before, "\n") {
+		before += "\n"
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(before+profileBlock(name, content)+after), 0600)
+}
+
+// removeProfileBlock removes the block name from the profile at path, it
+// does nothing when the profile does not have it
+func removeProfileBlock(path, name string) error {
+	profile, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	before, block, after := splitProfile(string(profile), name)
+	if block == "" {
+		return nil
+	}
+	return os.WriteFile(path, []byte(before+after), 0600)
+}
+
+func checkProfileBlock(path, name, content string) error {
+	ok, err := hasProfileBlock(path, name, content)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%s does not have the crc %s lines", path, name)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+//go:build !windows
+// +build !windows
+
+package preflight
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/os/shell"
+)
+
+const pathBlock = "path"
+
+func fishProfile() string {
+	return filepath.Join(constants.GetHomeDir(), ".config", "fish", "conf.d", "crc.fish")
+}
+
+// userShellProfile returns the login shell of the user, and the profile in
+// which crc adds its lines
+func userShellProfile() (string, string, error) {
+	userShell := filepath.Base(os.Getenv("SHELL"))
+	switch userShell {
+	case "bash":
+		// Terminal.app starts login shells, which do not read ~/.bashrc
+		if runtime.GOOS == "darwin" {
+			return userShell, filepath.Join(constants.GetHomeDir(), ".bash_profile"), nil
+		}
+		return userShell, filepath.Join(constants.GetHomeDir(), ".bashrc"), nil
+	case "zsh":
+		return userShell, filepath.Join(constants.GetHomeDir(), ".zshrc"), nil
+	case "fish":
+		return userShell, fishProfile(), nil
+	case ".":
+		return "", "", fmt.Errorf("The login shell of the user is unknown, SHELL is not set")
+	default:
+		return "", "", fmt.Errorf("The profile of the %s shell cannot be updated, only bash, zsh and fish are supported", userShell)
+	}
+}
+
+// allShellProfiles are the profiles crc may have changed, whatever the
+// current login shell is
+func allShellProfiles() []string {
+	return []string{
+		filepath.Join(constants.GetHomeDir(), ".bashrc"),
+		filepath.Join(constants.GetHomeDir(), ".bash_profile"),
+		filepath.Join(constants.GetHomeDir(), ".zshrc"),
+		fishProfile(),
+	}
+}
+
+func completionScript(userShell string) string {
+	switch userShell {
+	case "fish":
+		return "type -q crc; and crc completion fish | source"
+	case "zsh":
+		return `if command -v crc >/dev/null 2>&1; then
+  (( $+functions[compdef] )) || { autoload -U compinit && compinit }
+  source <(crc completion zsh)
+fi`
+	default:
+		return fmt.Sprintf("command -v crc >/dev/null 2>&1 && source <(crc completion %s)", userShell)
+	}
+}
+
+func pathScript(userShell string) string {
+	if userShell == "fish" {
+		// shell.GetPathEnvString changes the universal fish_user_paths
+		// variable, which would outlive the removal of the profile lines
+		return fmt.Sprintf("contains %s $PATH; or set -gx PATH %s $PATH", constants.CrcOcBinDir, constants.CrcOcBinDir)
+	}
+	return shell.GetPathEnvString(userShell, constants.CrcOcBinDir)
+}
+
+func checkShellCompletion() error {
+	userShell, profile, err := userShellProfile()
+	if err != nil {
+		return err
+	}
+	return checkProfileBlock(profile, completionBlock, completionScript(userShell))
+}
+
+func fixShellCompletion() error {
+	userShell, profile, err := userShellProfile()
+	if err != nil {
+		return err
+	}
+	logging.Infof("Adding the completion of the crc commands to %s, open a new shell to use it", profile)
+	return writeProfileBlock(profile, completionBlock, completionScript(userShell))
+}
+
+func removeShellCompletion() error {
+	return removeProfileBlocks(completionBlock)
+}
+
+func checkClientsInPath() error {
+	userShell, profile, err := userShellProfile()
+	if err != nil {
+		return err
+	}
+	return checkProfileBlock(profile, pathBlock, pathScript(userShell))
+}
+
+func fixClientsInPath() error {
+	userShell, profile, err := userShellProfile()
+	if err != nil {
+		return err
+	}
+	logging.Infof("Adding %s to the PATH in %s, open a new shell to use the oc and podman clients", constants.CrcOcBinDir, profile)
+	return writeProfileBlock(profile, pathBlock, pathScript(userShell))
+}
+
+func removeClientsFromPath() error {
+	return removeProfileBlocks(pathBlock)
+}
+
+func removeProfileBlocks(name string) error {
+	for _, profile := range allShellProfiles() {
+		if err := removeProfileBlock(profile, name); err != nil {
+			return err
+		}
+	}
+	// the fish profile only has the lines of crc
+	if info, err := os.Stat(fishProfile()); err == nil && info.Size() == 0 {
+		return os.Remove(fishProfile())
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package preflight
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileBlock(t *testing.T) {
+	profile := filepath.Join(t.TempDir(), "profile.d", "bashrc")
+
+	ok, err := hasProfileBlock(profile, "path", "export PATH=/crc:$PATH")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.NoError(t, removeProfileBlock(profile, "path"))
+
+	require.NoError(t, writeProfileBlock(profile, "path", "export PATH=/crc:$PATH"))
+	ok, err = hasProfileBlock(profile, "path", "export PATH=/crc:$PATH")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Error(t, checkProfileBlock(profile, "path", "export PATH=/crc/bin:$PATH"))
+}
+
+func TestProfileBlockKeepsUserLines(t *testing.T) {
+	profile := filepath.Join(t.TempDir(), "bashrc")
+	require.NoError(t, os.WriteFile(profile, []byte("alias ll='ls -l'"), 0600))
+
+	require.NoError(t, writeProfileBlock(profile, "path", "export PATH=/crc:$PATH"))
+	require.NoError(t, writeProfileBlock(profile, "completion", "source <(crc completion bash)"))
+	require.NoError(t, writeProfileBlock(profile, "path", "export PATH=/crc/bin:$PATH"))
+	content, err := os.ReadFile(profile)
+	require.NoError(t, err)
+	assert.Equal(t, `alias ll='ls -l'
+# >>> crc path >>>
+export PATH=/crc/bin:$PATH
+# <<< crc path <<<
+# >>> crc completion >>>
+source <(crc completion bash)
+# <<< crc completion <<<
+`, string(content))
+
+	require.NoError(t, removeProfileBlock(profile, "path"))
+	require.NoError(t, removeProfileBlock(profile, "completion"))
+	content, err = os.ReadFile(profile)
+	require.NoError(t, err)
+	assert.Equal(t, "alias ll='ls -l'\n", string(content))
+}
@@ -0,0 +1,118 @@
+package preflight
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/os/windows/powershell"
+)
+
+const completionScript = "if (Get-Command crc -ErrorAction SilentlyContinue) { crc completion powershell | Out-String | Invoke-Expression }"
+
+// powershellProfile returns the profile of the current user loaded by all
+// the PowerShell hosts
+func powershellProfile() (string, error) {
+	stdOut, _, err := powershell.Execute("$PROFILE.CurrentUserAllHosts")
+	if err != nil {
+		return "", fmt.Errorf("Failed to get the path of the PowerShell profile: %w", err)
+	}
+	profile := strings.TrimSpace(stdOut)
+	if profile == "" {
+		return "", fmt.Errorf("Failed to get the path of the PowerShell profile")
+	}
+	return profile, nil
+}
+
+func checkShellCompletion() error {
+	profile, err := powershellProfile()
+	if err != nil {
+		return err
+	}
+	return checkProfileBlock(profile, completionBlock, completionScript)
+}
+
+func fixShellCompletion() error {
+	profile, err := powershellProfile()
+	if err != nil {
+		return err
+	}
+	logging.Infof("Adding the completion of the crc commands to %s, open a new PowerShell to use it", profile)
+	return writeProfileBlock(profile, completionBlock, completionScript)
+}
+
+func removeShellCompletion() error {
+	profile, err := powershellProfile()
+	if err != nil {
+		return err
+	}
+	return removeProfileBlock(profile, completionBlock)
+}
+
+// userPath returns the entries of the PATH of the user, stored in the
+// registry, without the ones of the system
+func userPath() ([]string, error) {
+	stdOut, _, err := powershell.Execute("[Environment]::GetEnvironmentVariable('Path', 'User')")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get the PATH of the user: %w", err)
+	}
+	var paths []string
+	for _, path := range strings.Split(strings.TrimSpace(stdOut), ";") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+func setUserPath(paths []string) error {
+	value := strings.ReplaceAll(strings.Join(paths, ";"), "'", "''")
+	if _, _, err := powershell.Execute(fmt.Sprintf("[Environment]::SetEnvironmentVariable('Path', '%s', 'User')", value)); err != nil {
+		return fmt.Errorf("Failed to set the PATH of the user: %w", err)
+	}
+	return nil
+}
+
+func isClientsDir(path string) bool {
+	return strings.EqualFold(strings.TrimRight(path, `\`), constants.CrcOcBinDir)
+}
+
+func checkClientsInPath() error {
+	paths, err := userPath()
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if isClientsDir(path) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not in the PATH of the user", constants.CrcOcBinDir)
+}
+
+func fixClientsInPath() error {
+	paths, err := userPath()
+	if err != nil {
+		return err
+	}
+	logging.Infof("Adding %s to the PATH of the user, open a new terminal to use the oc and podman clients", constants.CrcOcBinDir)
+	return setUserPath(append([]string{constants.CrcOcBinDir}, paths...))
+}
+
+func removeClientsFromPath() error {
+	paths, err := userPath()
+	if err != nil {
+		return err
+	}
+	var kept []string
+	for _, path := range paths {
+		if !isClientsDir(path) {
+			kept = append(kept, path)
+		}
+	}
+	if len(kept) == len(paths) {
+		return nil
+	}
+	return setUserPath(kept)
+}
@@ -0,0 +1,132 @@
+package preflight
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/code-ready/crc/pkg/crc/cache"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/version"
+	crcos "github.com/code-ready/crc/pkg/os"
+)
+
+const (
+	// virtualizationEntitlement allows vfkit to use Virtualization.framework,
+	// the process is killed when it is not in its code signature
+	virtualizationEntitlement = "com.apple.security.virtualization"
+
+	// rosettaRuntimePath is installed with Rosetta, Virtualization.framework
+	// shares its directory with the instance
+	rosettaRuntimePath = "/Library/Apple/usr/libexec/oah/RosettaLinux/rosetta"
+)
+
+const vfkitEntitlements = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>com.apple.security.virtualization</key>
+	<true/>
+</dict>
+</plist>
+`
+
+func checkMacOSVersion(minVersion string) func() error {
+	return func() error {
+		out, _, err := crcos.RunWithDefaultLocale("sw_vers", "-productVersion")
+		if err != nil {
+			return err
+		}
+		current, err := semver.NewVersion(strings.TrimSpace(out))
+		if err != nil {
+			return fmt.Errorf("Unexpected macOS version %s: %v", strings.TrimSpace(out), err)
+		}
+		logging.Debugf("Running macOS %s", current)
+		if current.LessThan(semver.MustParse(minVersion)) {
+			return fmt.Errorf("macOS %s or newer is needed, this is macOS %s", minVersion, current)
+		}
+		return nil
+	}
+}
+
+func checkVfkitInstalled() error {
+	if version.IsInstaller() {
+		return nil
+	}
+
+	vfkit := cache.NewVfkitCache()
+
+	logging.Debugf("Checking if %s is installed", vfkit.GetExecutableName())
+	if !vfkit.IsCached() {
+		return fmt.Errorf("%s executable is not cached", vfkit.GetExecutableName())
+	}
+
+	return vfkit.CheckVersion()
+}
+
+func fixVfkitInstalled() error {
+	if version.IsInstaller() {
+		return nil
+	}
+	vfkit := cache.NewVfkitCache()
+
+	logging.Debugf("Installing %s", vfkit.GetExecutableName())
+
+	if err := vfkit.EnsureIsCached(); err != nil {
+		return fmt.Errorf("Unable to download %s : %v", vfkit.GetExecutableName(), err)
+	}
+
+	return nil
+}
+
+func checkVfkitEntitlement() error {
+	vfkitPath := cache.NewVfkitCache().GetExecutablePath()
+	logging.Debugf("Checking the entitlements of %s", vfkitPath)
+	// codesign prints the entitlements on stdout and the other information
+	// on stderr
+	stdout, stderr, err := crcos.RunWithDefaultLocale("codesign", "--display", "--entitlements", "-", "--xml", vfkitPath)
+	if err != nil {
+		logging.Debugf("codesign failed: %v: %s", err, stderr)
+		return fmt.Errorf("%s is not signed", vfkitPath)
+	}
+	if !strings.Contains(stdout, virtualizationEntitlement) {
+		return fmt.Errorf("%s is not signed with the %s entitlement", vfkitPath, virtualizationEntitlement)
+	}
+	return nil
+}
+
+// fixVfkitEntitlement signs vfkit ad-hoc with the virtualization
+// entitlement, this replaces its existing signature
+func fixVfkitEntitlement() error {
+	entitlements, err := ioutil.TempFile("", "vfkit-entitlements-*.plist")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(entitlements.Name())
+	if _, err := entitlements.WriteString(vfkitEntitlements); err != nil {
+		entitlements.Close()
+		return err
+	}
+	if err := entitlements.Close(); err != nil {
+		return err
+	}
+	vfkitPath := cache.NewVfkitCache().GetExecutablePath()
+	if _, stderr, err := crcos.RunWithDefaultLocale("codesign", "--force", "--sign", "-", "--entitlements", entitlements.Name(), vfkitPath); err != nil {
+		return fmt.Errorf("Failed to sign %s: %v: %s", vfkitPath, err, stderr)
+	}
+	return nil
+}
+
+func checkRosettaInstalled() error {
+	if !crcos.FileExists(rosettaRuntimePath) {
+		return fmt.Errorf("Rosetta is not installed, %s does not exist", rosettaRuntimePath)
+	}
+	return nil
+}
+
+func fixRosettaInstalled() error {
+	_, _, err := crcos.RunPrivileged("Installing Rosetta", "softwareupdate", "--install-rosetta", "--agree-to-license")
+	return err
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/network"
 
 	winnet "github.com/code-ready/crc/pkg/os/windows/network"
 	"github.com/code-ready/crc/pkg/os/windows/powershell"
@@ -17,6 +18,9 @@ import (
 const (
 	// Fall Creators update comes with the "Default Switch"
 	minimumWindowsReleaseID = 1709
+
+	// Hyper-V supports nested virtualization on AMD processors since this build
+	minimumAMDNestedVirtualizationBuild = 19636
 )
 
 func checkVersionOfWindowsUpdate() error {
@@ -40,6 +44,36 @@ func checkVersionOfWindowsUpdate() error {
 	return nil
 }
 
+func checkNestedVirtualizationSupported() error {
+	stdOut, _, err := powershell.Execute(`(Get-CimInstance Win32_Processor | Select-Object -First 1).Manufacturer`)
+	if err != nil {
+		logging.Debug(err.Error())
+		return fmt.Errorf("Failed to get the processor manufacturer")
+	}
+	manufacturer := strings.TrimSpace(stdOut)
+	logging.Debugf("Processor manufacturer: %s", manufacturer)
+	switch manufacturer {
+	case "GenuineIntel":
+		return nil
+	case "AuthenticAMD":
+		stdOut, _, err := powershell.Execute(`(Get-ItemProperty -Path "HKLM:\SOFTWARE\Microsoft\Windows NT\CurrentVersion").CurrentBuild`)
+		if err != nil {
+			logging.Debug(err.Error())
+			return fmt.Errorf("Failed to get Windows build")
+		}
+		build, err := strconv.Atoi(strings.TrimSpace(stdOut))
+		if err != nil {
+			return fmt.Errorf("Failed to parse Windows build: %s", stdOut)
+		}
+		if build < minimumAMDNestedVirtualizationBuild {
+			return fmt.Errorf("Nested virtualization on AMD processors needs Windows build %d or newer, you are running %d", minimumAMDNestedVirtualizationBuild, build)
+		}
+		return nil
+	default:
+		return fmt.Errorf("Nested virtualization is not supported on %s processors", manufacturer)
+	}
+}
+
 func checkWindowsEdition() error {
 	windowsEditionCmd := `(Get-ItemProperty -Path "HKLM:\SOFTWARE\Microsoft\Windows NT\CurrentVersion").EditionID`
 
@@ -183,3 +217,65 @@ func removeCrcVM() (err error) {
 	logging.Debug("'crc' VM is removed")
 	return nil
 }
+
+// The NRPT rules send the queries for the cluster domains to the DNS resolver
+// of the daemon, they are identified by their comment
+const nrptRuleComment = "crc"
+
+var listNrptRulesCommand = fmt.Sprintf(`Get-DnsClientNrptRule | Where-Object { $_.Comment -eq '%s' }`, nrptRuleComment)
+
+func nrptNamespaces(clusterDomain string) []string {
+	var namespaces []string
+	for _, domain := range network.HostResolverDomains(clusterDomain) {
+		namespaces = append(namespaces, "."+domain)
+	}
+	return namespaces
+}
+
+func checkNrptRules(clusterDomain string) func() error {
+	return func() error {
+		stdout, _, err := powershell.Execute(listNrptRulesCommand + ` | ForEach-Object { $_.Namespace }`)
+		if err != nil {
+			return fmt.Errorf("Cannot list the NRPT rules: %v", err)
+		}
+		configured := strings.Fields(stdout)
+		for _, namespace := range nrptNamespaces(clusterDomain) {
+			found := false
+			for _, c := range configured {
+				if strings.EqualFold(c, namespace) {
+					found = true
+				}
+			}
+			if !found {
+				return fmt.Errorf("No NRPT rule for %s", namespace)
+			}
+		}
+		return nil
+	}
+}
+
+func fixNrptRules(clusterDomain string) func() error {
+	return func() error {
+		var quoted []string
+		for _, namespace := range nrptNamespaces(clusterDomain) {
+			quoted = append(quoted, fmt.Sprintf("'%s'", namespace))
+		}
+		cmd := fmt.Sprintf(`%s | Remove-DnsClientNrptRule -Force; Add-DnsClientNrptRule -Namespace %s -NameServers '127.0.0.1' -Comment '%s'`,
+			listNrptRulesCommand, strings.Join(quoted, ","), nrptRuleComment)
+		if _, stderr, err := powershell.ExecuteAsAdmin("adding NRPT rules for the cluster domains", cmd); err != nil {
+			return fmt.Errorf("Failed to add the NRPT rules: %v: %s", err, stderr)
+		}
+		return nil
+	}
+}
+
+func removeNrptRules() error {
+	stdout, _, err := powershell.Execute(listNrptRulesCommand)
+	if err != nil || strings.TrimSpace(stdout) == "" {
+		return nil
+	}
+	if _, stderr, err := powershell.ExecuteAsAdmin("removing the NRPT rules of the cluster domains", listNrptRulesCommand+" | Remove-DnsClientNrptRule -Force"); err != nil {
+		return fmt.Errorf("Failed to remove the NRPT rules: %v: %s", err, stderr)
+	}
+	return nil
+}
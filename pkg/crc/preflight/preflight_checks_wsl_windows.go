@@ -0,0 +1,55 @@
+package preflight
+
+import (
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/os/windows/powershell"
+	"github.com/code-ready/crc/pkg/os/windows/wsl"
+)
+
+func checkWSLInstalled() error {
+	if _, _, err := wsl.Execute("--status"); err != nil {
+		logging.Debug(err.Error())
+		return fmt.Errorf("WSL is not installed")
+	}
+	return nil
+}
+
+func fixWSLInstalled() error {
+	if _, _, err := powershell.ExecuteAsAdmin("installing WSL", "wsl.exe --install --no-distribution"); err != nil {
+		return err
+	}
+	return errReboot
+}
+
+// checkWSLVersion checks if WSL can boot the distribution with systemd, only
+// the WSL of the Microsoft Store supports it and has a --version option
+func checkWSLVersion() error {
+	stdout, _, err := wsl.Execute("--version")
+	if err != nil {
+		logging.Debug(err.Error())
+		return fmt.Errorf("WSL is too old to run systemd")
+	}
+	logging.Debugf("WSL version: %s", stdout)
+	return nil
+}
+
+func fixWSLVersion() error {
+	_, _, err := powershell.ExecuteAsAdmin("updating WSL", "wsl.exe --update")
+	return err
+}
+
+func removeCrcWSLDistribution() error {
+	registered, err := wsl.IsRegistered(constants.DefaultName)
+	if err != nil || !registered {
+		// WSL is not installed or there is no crc distribution
+		return nil
+	}
+	if _, stderr, err := wsl.Execute("--unregister", constants.DefaultName); err != nil {
+		return fmt.Errorf("Failed to unregister the %s WSL distribution: %v: %s", constants.DefaultName, err, stderr)
+	}
+	logging.Debugf("'%s' WSL distribution is removed", constants.DefaultName)
+	return nil
+}
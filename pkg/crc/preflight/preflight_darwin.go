@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/code-ready/crc/pkg/crc/cache"
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/crc/preset"
@@ -31,7 +32,7 @@ func hyperkitPreflightChecks(networkMode network.Mode) []Check {
 			fixDescription:   "Setting up virtualization with HyperKit",
 			fix:              fixHyperKitInstallation(networkMode),
 
-			labels: labels{Os: Darwin},
+			labels: labels{Os: Darwin, VMDriver: Hyperkit},
 		},
 		{
 			configKeySuffix:  "check-qcow-tool-installed",
@@ -40,7 +41,7 @@ func hyperkitPreflightChecks(networkMode network.Mode) []Check {
 			fixDescription:   "Installing qcow-tool",
 			fix:              fixQcowToolInstalled,
 
-			labels: labels{Os: Darwin},
+			labels: labels{Os: Darwin, VMDriver: Hyperkit},
 		},
 		{
 			configKeySuffix:  "check-hyperkit-driver",
@@ -49,18 +50,71 @@ func hyperkitPreflightChecks(networkMode network.Mode) []Check {
 			fixDescription:   "Installing crc-machine-hyperkit",
 			fix:              fixMachineDriverHyperKitInstalled(networkMode),
 
-			labels: labels{Os: Darwin},
+			labels: labels{Os: Darwin, VMDriver: Hyperkit},
 		},
 		{
 			cleanupDescription: "Stopping CRC Hyperkit process",
 			cleanup:            stopCRCHyperkitProcess,
+			cleanupArea:        VMArea,
 			flags:              CleanUpOnly,
 
-			labels: labels{Os: Darwin},
+			labels: labels{Os: Darwin, VMDriver: Hyperkit},
 		},
 	}
 }
 
+var vfkitPreflightChecks = []Check{
+	{
+		configKeySuffix:  "check-macos-version",
+		checkDescription: "Checking if running macOS 12 or newer",
+		check:            checkMacOSVersion("12.0"),
+		fixDescription:   "The vfkit driver needs macOS 12 or newer, macOS must be updated",
+		flags:            NoFix,
+
+		labels: labels{Os: Darwin, VMDriver: Vfkit},
+	},
+	{
+		configKeySuffix:  "check-vfkit-installed",
+		checkDescription: "Checking if vfkit is installed",
+		check:            checkVfkitInstalled,
+		fixDescription:   "Installing vfkit",
+		fix:              fixVfkitInstalled,
+
+		labels: labels{Os: Darwin, VMDriver: Vfkit},
+	},
+	{
+		configKeySuffix:  "check-vfkit-entitlement",
+		checkDescription: "Checking if vfkit is signed with the virtualization entitlement",
+		check:            checkVfkitEntitlement,
+		fixDescription:   "Signing vfkit with the virtualization entitlement",
+		fix:              fixVfkitEntitlement,
+
+		labels: labels{Os: Darwin, VMDriver: Vfkit},
+	},
+}
+
+// rosettaPreflightChecks are only run when the enable-rosetta setting is true
+var rosettaPreflightChecks = []Check{
+	{
+		configKeySuffix:  "check-rosetta-macos-version",
+		checkDescription: "Checking if running macOS 13 or newer",
+		check:            checkMacOSVersion("13.0"),
+		fixDescription:   "Rosetta can only be used by virtual machines with macOS 13 or newer, macOS must be updated",
+		flags:            NoFix,
+
+		labels: labels{Os: Darwin},
+	},
+	{
+		configKeySuffix:  "check-rosetta-installed",
+		checkDescription: "Checking if Rosetta is installed",
+		check:            checkRosettaInstalled,
+		fixDescription:   "Installing Rosetta",
+		fix:              fixRosettaInstalled,
+
+		labels: labels{Os: Darwin},
+	},
+}
+
 /*
  * Following check should be removed after 2-3 releases
  * since the tray now handles the autostart and it is unaware
@@ -101,39 +155,94 @@ var resolverPreflightChecks = []Check{
 		fix:                fixResolverFilePermissions,
 		cleanupDescription: fmt.Sprintf("Removing %s file", resolverFile),
 		cleanup:            removeResolverFile,
+		cleanupArea:        DNSArea,
 
 		labels: labels{Os: Darwin, NetworkMode: System},
 	},
 }
 
+func hostResolverPreflightChecks(clusterDomain string) []Check {
+	return []Check{
+		{
+			configKeySuffix:    "check-host-resolver-files",
+			checkDescription:   "Checking if the DNS resolver of the host uses the crc daemon for the cluster domains",
+			check:              checkHostResolverFiles(clusterDomain),
+			fixDescription:     fmt.Sprintf("Writing the resolver configuration for the cluster domains in %s", resolverDir),
+			fix:                fixHostResolverFiles(clusterDomain),
+			cleanupDescription: fmt.Sprintf("Removing the resolver configuration for the cluster domains from %s", resolverDir),
+			cleanup:            removeHostResolverFiles,
+			cleanupArea:        DNSArea,
+
+			labels: labels{Os: Darwin, NetworkMode: User},
+		},
+	}
+}
+
 // We want all preflight checks including
 // - experimental checks
 // - tray checks when using an installer, regardless of tray enabled or not
 // - both user and system networking checks
 //
-// Passing 'SystemNetworkingMode' to getPreflightChecks currently achieves this
-// as there are no user networking specific checks
+// The network mode is not set in the filter to keep the checks of both modes
 func getAllPreflightChecks() []Check {
-	return getPreflightChecks(true, network.SystemNetworkingMode, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift)
+	filter := newFilter()
+	checks := filter.Apply(getChecks(network.SystemNetworkingMode, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift, false, ""))
+	// the checks of the optional features are only run when they are enabled
+	checks = append(checks, rosettaPreflightChecks...)
+	checks = append(checks, shellCompletionPreflightChecks...)
+	return append(checks, clientsInPathPreflightChecks...)
 }
 
-func getChecks(mode network.Mode, bundlePath string, preset crcpreset.Preset) []Check {
+func getChecks(mode network.Mode, bundlePath string, preset crcpreset.Preset, skipBundleVerification bool, clusterDomain string) []Check {
 	checks := []Check{}
 
 	checks = append(checks, nonWinPreflightChecks...)
 	checks = append(checks, genericPreflightChecks(preset)...)
 	checks = append(checks, genericCleanupChecks...)
 	checks = append(checks, hyperkitPreflightChecks(mode)...)
+	checks = append(checks, vfkitPreflightChecks...)
 	checks = append(checks, resolverPreflightChecks...)
-	checks = append(checks, bundleCheck(bundlePath, preset))
+	checks = append(checks, firewallPreflightChecks...)
+	checks = append(checks, hostResolverPreflightChecks(clusterDomain)...)
+	checks = append(checks, bundleCheck(bundlePath, preset, skipBundleVerification))
 	checks = append(checks, trayLaunchdCleanupChecks...)
 
 	return checks
 }
 
-func getPreflightChecks(_ bool, mode network.Mode, bundlePath string, preset crcpreset.Preset) []Check {
+func getPreflightChecks(_ bool, mode network.Mode, bundlePath string, preset crcpreset.Preset, skipBundleVerification bool, clusterDomain string) []Check {
 	filter := newFilter()
 	filter.SetNetworkMode(mode)
 
-	return filter.Apply(getChecks(mode, bundlePath, preset))
+	return filter.Apply(getChecks(mode, bundlePath, preset, skipBundleVerification, clusterDomain))
+}
+
+func offlineExecutables() []*cache.Cache {
+	return []*cache.Cache{
+		cache.NewHyperKitCache(),
+		cache.NewMachineDriverHyperKitCache(),
+		cache.NewQcowToolCache(),
+		cache.NewVfkitCache(),
+		cache.NewAdminHelperCache(),
+	}
+}
+
+// getGPUPreflightChecks returns no checks, the enable-gpu setting is only
+// available on Linux
+func getGPUPreflightChecks() []Check {
+	return nil
+}
+
+func getQemuPreflightChecks() []Check {
+	return nil
+}
+
+func getRosettaPreflightChecks() []Check {
+	return rosettaPreflightChecks
+}
+
+// getNestedVirtualizationPreflightChecks returns no checks, the hyperkit
+// driver does not support nested virtualization
+func getNestedVirtualizationPreflightChecks() []Check {
+	return nil
 }
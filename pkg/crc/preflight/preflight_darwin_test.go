@@ -13,13 +13,38 @@ import (
 func TestCountConfigurationOptions(t *testing.T) {
 	cfg := config.New(config.NewEmptyInMemoryStorage())
 	RegisterSettings(cfg)
-	assert.Len(t, cfg.AllConfigs(), 12)
+	assert.Len(t, cfg.AllConfigs(), 38)
 }
 
 func TestCountPreflights(t *testing.T) {
-	assert.Len(t, getPreflightChecks(true, network.SystemNetworkingMode, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift), 17)
-	assert.Len(t, getPreflightChecks(true, network.SystemNetworkingMode, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift), 17)
+	assert.Len(t, getPreflightChecks(true, network.SystemNetworkingMode, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift, false, ""), 21)
+	assert.Len(t, getPreflightChecks(true, network.SystemNetworkingMode, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift, false, ""), 21)
 
-	assert.Len(t, getPreflightChecks(true, network.UserNetworkingMode, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift), 16)
-	assert.Len(t, getPreflightChecks(true, network.UserNetworkingMode, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift), 16)
+	assert.Len(t, getPreflightChecks(true, network.UserNetworkingMode, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift, false, ""), 20)
+	assert.Len(t, getPreflightChecks(true, network.UserNetworkingMode, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift, false, ""), 20)
+}
+
+func TestApplicationFirewallCheck(t *testing.T) {
+	assert.NoError(t, (&applicationFirewall{globalState: "firewall is disabled. (state = 0)"}).check())
+	assert.Equal(t, errFirewallBlocksAll, (&applicationFirewall{
+		globalState: "firewall is enabled. (state = 1)",
+		blockAll:    "firewall is set to block all non-essential incoming connections",
+	}).check())
+	assert.NoError(t, (&applicationFirewall{
+		globalState: "firewall is enabled. (state = 1)",
+		blockAll:    "block all disabled!",
+		allowSigned: "automatically allow built-in signed software enabled.",
+	}).check())
+	assert.NoError(t, (&applicationFirewall{
+		globalState:   "firewall is enabled. (state = 1)",
+		blockAll:      "block all disabled!",
+		allowSigned:   "automatically allow built-in signed software disabled.",
+		bootpdBlocked: "the application /usr/libexec/bootpd is permitted",
+	}).check())
+	assert.EqualError(t, (&applicationFirewall{
+		globalState:   "firewall is enabled. (state = 1)",
+		blockAll:      "block all disabled!",
+		allowSigned:   "automatically allow built-in signed software disabled.",
+		bootpdBlocked: "the application is not part of the firewall",
+	}).check(), "The application firewall does not permit the incoming connections of /usr/libexec/bootpd")
 }
@@ -4,11 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/code-ready/crc/pkg/crc/cache"
 	"github.com/code-ready/crc/pkg/crc/constants"
 	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
 	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/libvirt"
 	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/crc/preset"
 	crcpreset "github.com/code-ready/crc/pkg/crc/preset"
@@ -28,6 +31,7 @@ func libvirtPreflightChecks(distro *linux.OsRelease) []Check {
 			fix:                fixCrcSymlink,
 			cleanupDescription: "Removing crc executable symlink",
 			cleanup:            removeCrcSymlink,
+			cleanupArea:        ShellArea,
 
 			labels: labels{Os: Linux},
 		},
@@ -56,7 +60,7 @@ func libvirtPreflightChecks(distro *linux.OsRelease) []Check {
 			fixDescription:   "Installing libvirt service and dependencies",
 			fix:              fixLibvirtInstalled(distro),
 
-			labels: labels{Os: Linux},
+			labels: labels{Os: Linux, VMDriver: Libvirt},
 		},
 		{
 			configKeySuffix:  "check-user-in-libvirt-group",
@@ -65,7 +69,7 @@ func libvirtPreflightChecks(distro *linux.OsRelease) []Check {
 			fixDescription:   "Adding user to libvirt group",
 			fix:              fixUserPartOfLibvirtGroup,
 
-			labels: labels{Os: Linux},
+			labels: labels{Os: Linux, VMDriver: Libvirt, LibvirtConnection: LibvirtSystem},
 		},
 		{
 			configKeySuffix:  "check-libvirt-group-active",
@@ -74,7 +78,7 @@ func libvirtPreflightChecks(distro *linux.OsRelease) []Check {
 			fixDescription:   "You need to logout, re-login, and run crc setup again before the user is effectively a member of the 'libvirt' group.",
 			flags:            NoFix,
 
-			labels: labels{Os: Linux},
+			labels: labels{Os: Linux, VMDriver: Libvirt, LibvirtConnection: LibvirtSystem},
 		},
 		{
 			configKeySuffix:  "check-libvirt-running",
@@ -83,7 +87,16 @@ func libvirtPreflightChecks(distro *linux.OsRelease) []Check {
 			fixDescription:   "Starting libvirt service",
 			fix:              fixLibvirtServiceRunning,
 
-			labels: labels{Os: Linux},
+			labels: labels{Os: Linux, VMDriver: Libvirt, LibvirtConnection: LibvirtSystem},
+		},
+		{
+			configKeySuffix:  "check-libvirt-session-kvm-access",
+			checkDescription: "Checking if the user can use /dev/kvm",
+			check:            checkKvmAccessible,
+			fixDescription:   "The libvirt session of the user needs read and write access to /dev/kvm, add the user to the kvm group, then logout and re-login",
+			flags:            NoFix,
+
+			labels: labels{Os: Linux, VMDriver: Libvirt, LibvirtConnection: LibvirtSession},
 		},
 		{
 			configKeySuffix:  "check-libvirt-version",
@@ -92,7 +105,7 @@ func libvirtPreflightChecks(distro *linux.OsRelease) []Check {
 			fixDescription:   fmt.Sprintf("libvirt v%s or newer is required and must be updated manually", minSupportedLibvirtVersion),
 			flags:            NoFix,
 
-			labels: labels{Os: Linux},
+			labels: labels{Os: Linux, VMDriver: Libvirt},
 		},
 		{
 			configKeySuffix:  "check-libvirt-driver",
@@ -101,21 +114,23 @@ func libvirtPreflightChecks(distro *linux.OsRelease) []Check {
 			fixDescription:   "Installing crc-driver-libvirt",
 			fix:              fixMachineDriverLibvirtInstalled,
 
-			labels: labels{Os: Linux},
+			labels: labels{Os: Linux, VMDriver: Libvirt},
 		},
 		{
 			cleanupDescription: "Removing crc libvirt storage pool",
 			cleanup:            removeLibvirtStoragePool,
+			cleanupArea:        VMArea,
 			flags:              CleanUpOnly,
 
-			labels: labels{Os: Linux},
+			labels: labels{Os: Linux, VMDriver: Libvirt},
 		},
 		{
 			cleanupDescription: "Removing crc's virtual machine",
 			cleanup:            removeCrcVM,
+			cleanupArea:        VMArea,
 			flags:              CleanUpOnly,
 
-			labels: labels{Os: Linux},
+			labels: labels{Os: Linux, VMDriver: Libvirt},
 		},
 		{
 			configKeySuffix:    "check-daemon-systemd-unit",
@@ -125,6 +140,7 @@ func libvirtPreflightChecks(distro *linux.OsRelease) []Check {
 			fix:                fixDaemonSystemdService,
 			cleanupDescription: "Removing crc daemon systemd service",
 			cleanup:            removeDaemonSystemdService,
+			cleanupArea:        DaemonArea,
 			flags:              SetupOnly,
 
 			labels: labels{Os: Linux, SystemdUser: Supported},
@@ -137,6 +153,7 @@ func libvirtPreflightChecks(distro *linux.OsRelease) []Check {
 			fix:                fixDaemonSystemdSockets,
 			cleanupDescription: "Removing crc daemon systemd socket units",
 			cleanup:            removeDaemonSystemdSockets,
+			cleanupArea:        DaemonArea,
 
 			labels: labels{Os: Linux, SystemdUser: Supported},
 		},
@@ -159,8 +176,9 @@ var libvirtNetworkPreflightChecks = []Check{
 		fix:                fixLibvirtCrcNetworkAvailable,
 		cleanupDescription: "Removing 'crc' network from libvirt",
 		cleanup:            removeLibvirtCrcNetwork,
+		cleanupArea:        NetworkArea,
 
-		labels: labels{Os: Linux, NetworkMode: System},
+		labels: labels{Os: Linux, NetworkMode: System, VMDriver: Libvirt, LibvirtConnection: LibvirtSystem},
 	},
 	{
 		configKeySuffix:  "check-crc-network-active",
@@ -169,7 +187,7 @@ var libvirtNetworkPreflightChecks = []Check{
 		fixDescription:   "Starting libvirt 'crc' network",
 		fix:              fixLibvirtCrcNetworkActive,
 
-		labels: labels{Os: Linux, NetworkMode: System},
+		labels: labels{Os: Linux, NetworkMode: System, VMDriver: Libvirt, LibvirtConnection: LibvirtSystem},
 	},
 }
 
@@ -181,8 +199,92 @@ var vsockPreflightCheck = Check{
 	fix:                fixVsock,
 	cleanupDescription: "Removing vsock configuration",
 	cleanup:            removeVsockCrcSettings,
+	cleanupArea:        VsockArea,
 
-	labels: labels{Os: Linux, NetworkMode: User},
+	labels: labels{Os: Linux, NetworkMode: User, VMDriver: Libvirt},
+}
+
+// qemuPreflightChecks replace the libvirt checks when the vm-driver setting
+// is qemu. QEMU runs as the current user, so it needs to open the
+// vhost-vsock device itself.
+var qemuPreflightChecks = []Check{
+	{
+		configKeySuffix:  "check-qemu-installed",
+		checkDescription: "Checking if QEMU is installed",
+		check:            checkQemuInstalled,
+		fixDescription:   "Install QEMU with the package manager of the distribution (qemu-kvm on Fedora and RHEL, qemu-system-x86 on Ubuntu)",
+		flags:            NoFix,
+
+		labels: labels{Os: Linux, VMDriver: Qemu},
+	},
+	{
+		configKeySuffix:  "check-kvm-accessible",
+		checkDescription: "Checking if /dev/kvm can be used by the current user",
+		check:            checkKvmAccessible,
+		fixDescription:   "Add the current user to the 'kvm' group with 'usermod -a -G kvm', then logout and re-login",
+		flags:            NoFix,
+
+		labels: labels{Os: Linux, VMDriver: Qemu},
+	},
+	{
+		configKeySuffix:  "check-qemu-vsock",
+		checkDescription: "Checking if vsock is correctly configured for QEMU",
+		check:            checkQemuVsock,
+		fixDescription:   "Setting up vsock support for QEMU",
+		fix:              fixQemuVsock,
+
+		labels: labels{Os: Linux, NetworkMode: User, VMDriver: Qemu},
+	},
+}
+
+// gpuPreflightChecks are only run when the enable-gpu setting is true
+var gpuPreflightChecks = []Check{
+	{
+		configKeySuffix:  "check-iommu",
+		checkDescription: "Checking if the IOMMU is enabled",
+		check:            checkIOMMUEnabled,
+		fixDescription:   "Enable the IOMMU in the firmware settings and add 'intel_iommu=on' or 'amd_iommu=on' to the kernel command line, then reboot",
+		flags:            NoFix,
+
+		labels: labels{Os: Linux},
+	},
+	{
+		configKeySuffix:  "check-gpu-vfio",
+		checkDescription: "Checking if a GPU is bound to the vfio-pci driver",
+		check:            checkPassthroughGPU,
+		fixDescription:   "Bind the GPU and the other devices of its IOMMU group to the vfio-pci driver, for instance with 'driverctl set-override <PCI address> vfio-pci'",
+		flags:            NoFix,
+
+		labels: labels{Os: Linux},
+	},
+}
+
+// nestedVirtualizationPreflightChecks are only run when the
+// enable-nested-virtualization setting is true
+var nestedVirtualizationPreflightChecks = []Check{
+	{
+		configKeySuffix:  "check-kvm-nested",
+		checkDescription: "Checking if the kvm module allows nested virtualization",
+		check:            checkKvmNested,
+		fixDescription:   "Add 'options kvm_intel nested=1' (or 'options kvm_amd nested=1') to /etc/modprobe.d/kvm.conf, then stop all the virtual machines and reload the module",
+		flags:            NoFix,
+
+		labels: labels{Os: Linux},
+	},
+}
+
+func getNestedVirtualizationPreflightChecks() []Check {
+	return nestedVirtualizationPreflightChecks
+}
+
+func getQemuPreflightChecks() []Check {
+	return qemuPreflightChecks
+}
+
+// getRosettaPreflightChecks returns no checks, the enable-rosetta setting is
+// only available on macOS
+func getRosettaPreflightChecks() []Check {
+	return nil
 }
 
 var wsl2PreflightCheck = Check{
@@ -202,6 +304,14 @@ const (
 )
 
 func checkVsock() error {
+	return checkVsockDevices("/dev/vsock")
+}
+
+func checkQemuVsock() error {
+	return checkVsockDevices("/dev/vsock", "/dev/vhost-vsock")
+}
+
+func checkVsockDevices(devices ...string) error {
 	executable, err := os.Executable()
 	if err != nil {
 		return err
@@ -221,14 +331,35 @@ func checkVsock() error {
 		return errors.New("vsock udev rule does not exist")
 	}
 
-	err = unix.Access("/dev/vsock", unix.R_OK|unix.W_OK)
-	if err != nil {
-		return errors.New("/dev/vsock is not readable by the current user")
+	for _, device := range devices {
+		err = unix.Access(device, unix.R_OK|unix.W_OK)
+		if err != nil {
+			return fmt.Errorf("%s is not readable by the current user", device)
+		}
 	}
 	return nil
 }
 
+// vsockUdevRule gives read-write access to devices to the members of group
+func vsockUdevRule(group string, devices []string) string {
+	var rules []string
+	for _, device := range devices {
+		rules = append(rules, fmt.Sprintf(`KERNEL=="%s", MODE="0660", OWNER="root", GROUP="%s"`, filepath.Base(device), group))
+	}
+	return strings.Join(rules, "\n")
+}
+
 func fixVsock() error {
+	return fixVsockDevices("libvirt", "/dev/vsock")
+}
+
+// fixQemuVsock gives access to the vsock devices to the kvm group, the
+// current user needs to be part of it to use /dev/kvm
+func fixQemuVsock() error {
+	return fixVsockDevices("kvm", "/dev/vsock", "/dev/vhost-vsock")
+}
+
+func fixVsockDevices(group string, devices ...string) error {
 	executable, err := os.Executable()
 	if err != nil {
 		return err
@@ -246,7 +377,7 @@ func fixVsock() error {
 	if err != nil {
 		return err
 	}
-	udevRule := `KERNEL=="vsock", MODE="0660", OWNER="root", GROUP="libvirt"`
+	udevRule := vsockUdevRule(group, devices)
 	if crcos.FileContentMatches(vsockUdevLocalAdminRulesPath, []byte(udevRule)) != nil {
 		err = crcos.WriteToFileAsRoot("Creating udev rule for /dev/vsock", udevRule, vsockUdevLocalAdminRulesPath, 0644)
 		if err != nil {
@@ -257,15 +388,19 @@ func fixVsock() error {
 			return err
 		}
 	}
-	if crcos.FileExists("/dev/vsock") && unix.Access("/dev/vsock", unix.R_OK|unix.W_OK) != nil {
-		_, _, err = crcos.RunPrivileged("Applying udev rule to /dev/vsock", "udevadm", "trigger", "/dev/vsock")
-		if err != nil {
-			return err
-		}
-	} else {
-		_, _, err = crcos.RunPrivileged("Loading vhost_vsock kernel module", "modprobe", "vhost_vsock")
-		if err != nil {
-			return err
+	moduleLoaded := false
+	for _, device := range devices {
+		if crcos.FileExists(device) && unix.Access(device, unix.R_OK|unix.W_OK) != nil {
+			_, _, err = crcos.RunPrivileged(fmt.Sprintf("Applying udev rule to %s", device), "udevadm", "trigger", device)
+			if err != nil {
+				return err
+			}
+		} else if !crcos.FileExists(device) && !moduleLoaded {
+			_, _, err = crcos.RunPrivileged("Loading vhost_vsock kernel module", "modprobe", "vhost_vsock")
+			if err != nil {
+				return err
+			}
+			moduleLoaded = true
 		}
 	}
 
@@ -302,6 +437,7 @@ const (
 	Distro LabelName = iota + lastLabelName
 	DNS
 	SystemdUser
+	LibvirtConnection
 )
 
 const (
@@ -316,6 +452,10 @@ const (
 	// systemd user session
 	Supported
 	Unsupported
+
+	// libvirt connection
+	LibvirtSystem
+	LibvirtSession
 )
 
 func (filter preflightFilter) SetSystemdResolved(usingSystemdResolved bool) {
@@ -334,6 +474,14 @@ func (filter preflightFilter) SetDistro(distro *linux.OsRelease) {
 	}
 }
 
+func (filter preflightFilter) SetLibvirtURI(uri string) {
+	if uri == constants.LibvirtSessionURI {
+		filter[LibvirtConnection] = LibvirtSession
+	} else {
+		filter[LibvirtConnection] = LibvirtSystem
+	}
+}
+
 func (filter preflightFilter) SetSystemdUser(distro *linux.OsRelease) {
 	switch {
 	case distroIsLike(distro, linux.RHEL) && (distro.VersionID == "7" || strings.HasPrefix(distro.VersionID, "7.")):
@@ -354,26 +502,37 @@ func getAllPreflightChecks() []Check {
 	filter.SetDistro(distro())
 	filter.SetSystemdUser(distro())
 
-	return filter.Apply(getChecks(distro(), constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift))
+	checks := filter.Apply(getChecks(distro(), constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift, false, ""))
+	// the checks of the optional features are only run when they are enabled
+	checks = append(checks, gpuPreflightChecks...)
+	checks = append(checks, nestedVirtualizationPreflightChecks...)
+	checks = append(checks, qemuPreflightChecks...)
+	checks = append(checks, shellCompletionPreflightChecks...)
+	return append(checks, clientsInPathPreflightChecks...)
 }
 
-func getPreflightChecks(_ bool, networkMode network.Mode, bundlePath string, preset crcpreset.Preset) []Check {
+func getGPUPreflightChecks() []Check {
+	return gpuPreflightChecks
+}
+
+func getPreflightChecks(_ bool, networkMode network.Mode, bundlePath string, preset crcpreset.Preset, skipBundleVerification bool, clusterDomain string) []Check {
 	usingSystemdResolved := checkSystemdResolvedIsRunning()
 
-	return getPreflightChecksForDistro(distro(), networkMode, usingSystemdResolved == nil, bundlePath, preset)
+	return getPreflightChecksForDistro(distro(), networkMode, usingSystemdResolved == nil, bundlePath, preset, skipBundleVerification, clusterDomain)
 }
 
-func getPreflightChecksForDistro(distro *linux.OsRelease, networkMode network.Mode, usingSystemdResolved bool, bundlePath string, preset crcpreset.Preset) []Check {
+func getPreflightChecksForDistro(distro *linux.OsRelease, networkMode network.Mode, usingSystemdResolved bool, bundlePath string, preset crcpreset.Preset, skipBundleVerification bool, clusterDomain string) []Check {
 	filter := newFilter()
 	filter.SetDistro(distro)
 	filter.SetSystemdUser(distro)
 	filter.SetNetworkMode(networkMode)
 	filter.SetSystemdResolved(usingSystemdResolved)
+	filter.SetLibvirtURI(libvirt.URI)
 
-	return filter.Apply(getChecks(distro, bundlePath, preset))
+	return filter.Apply(getChecks(distro, bundlePath, preset, skipBundleVerification, clusterDomain))
 }
 
-func getChecks(distro *linux.OsRelease, bundlePath string, preset crcpreset.Preset) []Check {
+func getChecks(distro *linux.OsRelease, bundlePath string, preset crcpreset.Preset, skipBundleVerification bool, clusterDomain string) []Check {
 	var checks []Check
 	checks = append(checks, nonWinPreflightChecks...)
 	checks = append(checks, wsl2PreflightCheck)
@@ -382,11 +541,13 @@ func getChecks(distro *linux.OsRelease, bundlePath string, preset crcpreset.Pres
 	checks = append(checks, libvirtPreflightChecks(distro)...)
 	checks = append(checks, ubuntuPreflightChecks...)
 	checks = append(checks, nmPreflightChecks...)
-	checks = append(checks, systemdResolvedPreflightChecks...)
-	checks = append(checks, dnsmasqPreflightChecks...)
+	checks = append(checks, systemdResolvedPreflightChecks(clusterDomain)...)
+	checks = append(checks, dnsmasqPreflightChecks(clusterDomain)...)
+	checks = append(checks, hostResolverPreflightChecks(clusterDomain)...)
 	checks = append(checks, libvirtNetworkPreflightChecks...)
+	checks = append(checks, firewallPreflightChecks...)
 	checks = append(checks, vsockPreflightCheck)
-	checks = append(checks, bundleCheck(bundlePath, preset))
+	checks = append(checks, bundleCheck(bundlePath, preset, skipBundleVerification))
 
 	return checks
 }
@@ -418,3 +579,10 @@ func distro() *linux.OsRelease {
 	}
 	return distro
 }
+
+func offlineExecutables() []*cache.Cache {
+	return []*cache.Cache{
+		cache.NewMachineDriverLibvirtCache(),
+		cache.NewAdminHelperCache(),
+	}
+}
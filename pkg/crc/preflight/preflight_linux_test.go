@@ -22,7 +22,8 @@ func TestCountConfigurationOptions(t *testing.T) {
 	var preflightChecksCount int
 	for _, check := range getAllPreflightChecks() {
 		if check.configKeySuffix != "" {
-			preflightChecksCount++
+			// skip-<check> and warn-<check>
+			preflightChecksCount += 2
 		}
 	}
 	assert.True(t, options == preflightChecksCount, "Unexpected number of preflight configuration flags, got %d, expected %d", options, preflightChecksCount)
@@ -92,9 +93,11 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkNetworkManagerIsRunning},
 			{check: checkCrcDnsmasqAndNetworkManagerConfigFile},
 			{check: checkSystemdResolvedIsRunning},
-			{check: checkCrcNetworkManagerDispatcherFile},
+			{check: checkCrcNetworkManagerDispatcherFile("")},
 			{check: checkLibvirtCrcNetworkAvailable},
 			{check: checkLibvirtCrcNetworkActive},
+			{check: checkFirewalldCrcZone},
+			{check: checkNftablesCrcNetwork},
 			{check: checkBundleExtracted(constants.GetDefaultBundlePath(preset.OpenShift))},
 		},
 	},
@@ -130,9 +133,11 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkNetworkManagerInstalled},
 			{check: checkNetworkManagerIsRunning},
 			{check: checkCrcNetworkManagerConfig},
-			{check: checkCrcDnsmasqConfigFile},
+			{check: checkCrcDnsmasqConfigFile("")},
 			{check: checkLibvirtCrcNetworkAvailable},
 			{check: checkLibvirtCrcNetworkActive},
+			{check: checkFirewalldCrcZone},
+			{check: checkNftablesCrcNetwork},
 			{check: checkBundleExtracted(constants.GetDefaultBundlePath(preset.OpenShift))},
 		},
 	},
@@ -168,6 +173,39 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkBundleExtracted(constants.GetDefaultBundlePath(preset.OpenShift))},
 		},
 	},
+	{
+		distro:          &fedora,
+		networkMode:     network.UserNetworkingMode,
+		systemdResolved: true,
+		checks: []Check{
+			{check: checkIfRunningAsNormalUser},
+			{check: checkRunningInsideWSL2},
+			{check: checkAdminHelperExecutableCached},
+			{check: checkOldAdminHelperExecutableCached},
+			{check: checkSupportedCPUArch},
+			{configKeySuffix: "check-ram"},
+			{cleanup: removeCRCMachinesDir},
+			{cleanup: removeOldLogs},
+			{cleanup: cluster.ForgetPullSecret},
+			{cleanup: removeHostsFileEntry},
+			{check: checkCrcSymlink},
+			{check: checkVirtualizationEnabled},
+			{check: checkKvmEnabled},
+			{check: checkLibvirtInstalled},
+			{check: checkUserPartOfLibvirtGroup},
+			{configKeySuffix: "check-libvirt-group-active"},
+			{check: checkLibvirtServiceRunning},
+			{check: checkLibvirtVersion},
+			{check: checkMachineDriverLibvirtInstalled},
+			{cleanup: removeLibvirtStoragePool},
+			{cleanup: removeCrcVM},
+			{check: checkDaemonSystemdService},
+			{check: checkDaemonSystemdSockets},
+			{configKeySuffix: "check-systemd-resolved-crc-config"},
+			{check: checkVsock},
+			{check: checkBundleExtracted(constants.GetDefaultBundlePath(preset.OpenShift))},
+		},
+	},
 	{
 		distro:          &rhel,
 		networkMode:     network.SystemNetworkingMode,
@@ -201,9 +239,11 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkNetworkManagerIsRunning},
 			{check: checkCrcDnsmasqAndNetworkManagerConfigFile},
 			{check: checkSystemdResolvedIsRunning},
-			{check: checkCrcNetworkManagerDispatcherFile},
+			{check: checkCrcNetworkManagerDispatcherFile("")},
 			{check: checkLibvirtCrcNetworkAvailable},
 			{check: checkLibvirtCrcNetworkActive},
+			{check: checkFirewalldCrcZone},
+			{check: checkNftablesCrcNetwork},
 			{check: checkBundleExtracted(constants.GetDefaultBundlePath(preset.OpenShift))},
 		},
 	},
@@ -239,9 +279,11 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkNetworkManagerInstalled},
 			{check: checkNetworkManagerIsRunning},
 			{check: checkCrcNetworkManagerConfig},
-			{check: checkCrcDnsmasqConfigFile},
+			{check: checkCrcDnsmasqConfigFile("")},
 			{check: checkLibvirtCrcNetworkAvailable},
 			{check: checkLibvirtCrcNetworkActive},
+			{check: checkFirewalldCrcZone},
+			{check: checkNftablesCrcNetwork},
 			{check: checkBundleExtracted(constants.GetDefaultBundlePath(preset.OpenShift))},
 		},
 	},
@@ -310,9 +352,11 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkNetworkManagerIsRunning},
 			{check: checkCrcDnsmasqAndNetworkManagerConfigFile},
 			{check: checkSystemdResolvedIsRunning},
-			{check: checkCrcNetworkManagerDispatcherFile},
+			{check: checkCrcNetworkManagerDispatcherFile("")},
 			{check: checkLibvirtCrcNetworkAvailable},
 			{check: checkLibvirtCrcNetworkActive},
+			{check: checkFirewalldCrcZone},
+			{check: checkNftablesCrcNetwork},
 			{check: checkBundleExtracted(constants.GetDefaultBundlePath(preset.OpenShift))},
 		},
 	},
@@ -348,9 +392,11 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkNetworkManagerInstalled},
 			{check: checkNetworkManagerIsRunning},
 			{check: checkCrcNetworkManagerConfig},
-			{check: checkCrcDnsmasqConfigFile},
+			{check: checkCrcDnsmasqConfigFile("")},
 			{check: checkLibvirtCrcNetworkAvailable},
 			{check: checkLibvirtCrcNetworkActive},
+			{check: checkFirewalldCrcZone},
+			{check: checkNftablesCrcNetwork},
 			{check: checkBundleExtracted(constants.GetDefaultBundlePath(preset.OpenShift))},
 		},
 	},
@@ -420,9 +466,11 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkNetworkManagerIsRunning},
 			{check: checkCrcDnsmasqAndNetworkManagerConfigFile},
 			{check: checkSystemdResolvedIsRunning},
-			{check: checkCrcNetworkManagerDispatcherFile},
+			{check: checkCrcNetworkManagerDispatcherFile("")},
 			{check: checkLibvirtCrcNetworkAvailable},
 			{check: checkLibvirtCrcNetworkActive},
+			{check: checkFirewalldCrcZone},
+			{check: checkNftablesCrcNetwork},
 			{check: checkBundleExtracted(constants.GetDefaultBundlePath(preset.OpenShift))},
 		},
 	},
@@ -459,9 +507,11 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkNetworkManagerInstalled},
 			{check: checkNetworkManagerIsRunning},
 			{check: checkCrcNetworkManagerConfig},
-			{check: checkCrcDnsmasqConfigFile},
+			{check: checkCrcDnsmasqConfigFile("")},
 			{check: checkLibvirtCrcNetworkAvailable},
 			{check: checkLibvirtCrcNetworkActive},
+			{check: checkFirewalldCrcZone},
+			{check: checkNftablesCrcNetwork},
 			{check: checkBundleExtracted(constants.GetDefaultBundlePath(preset.OpenShift))},
 		},
 	},
@@ -509,7 +559,7 @@ func assertFuncEqual(t *testing.T, func1 interface{}, func2 interface{}) {
 }
 
 func assertExpectedPreflights(t *testing.T, distro *crcos.OsRelease, networkMode network.Mode, systemdResolved bool) {
-	preflights := getPreflightChecksForDistro(distro, networkMode, systemdResolved, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift)
+	preflights := getPreflightChecksForDistro(distro, networkMode, systemdResolved, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift, false, "")
 	var expected checkListForDistro
 	for _, expected = range checkListForDistros {
 		if expected.distro == distro && expected.networkMode == networkMode && expected.systemdResolved == systemdResolved {
@@ -540,6 +590,7 @@ func TestCountPreflights(t *testing.T) {
 	assertExpectedPreflights(t, &fedora, network.SystemNetworkingMode, true)
 	assertExpectedPreflights(t, &fedora, network.SystemNetworkingMode, false)
 	assertExpectedPreflights(t, &fedora, network.UserNetworkingMode, false)
+	assertExpectedPreflights(t, &fedora, network.UserNetworkingMode, true)
 
 	assertExpectedPreflights(t, &rhel, network.SystemNetworkingMode, true)
 	assertExpectedPreflights(t, &rhel, network.SystemNetworkingMode, false)
@@ -553,3 +604,70 @@ func TestCountPreflights(t *testing.T) {
 	assertExpectedPreflights(t, &ubuntu, network.SystemNetworkingMode, false)
 	assertExpectedPreflights(t, &ubuntu, network.UserNetworkingMode, false)
 }
+
+func TestClusterDomainDNSConfig(t *testing.T) {
+	assert.Equal(t, crcDnsmasqConfig, getCrcDnsmasqConfig(""))
+	assert.Equal(t, crcDnsmasqConfig+"server=/crc.example.com/192.168.130.11\n", getCrcDnsmasqConfig("crc.example.com"))
+
+	assert.Contains(t, getCrcNetworkManagerDispatcherConfig(""), "--set-domain ~testing\n")
+	assert.Contains(t, getCrcNetworkManagerDispatcherConfig("crc.example.com"), "--set-domain ~testing --set-domain ~crc.example.com\n")
+}
+
+func TestHostResolverConfig(t *testing.T) {
+	assert.Equal(t, "# Generated by CRC\n[Resolve]\nDNS=127.0.0.1:55353\nDomains=~crc.testing ~apps-crc.testing\n", getCrcResolvedConfig(""))
+	assert.Contains(t, getCrcResolvedConfig("crc.example.com"), "Domains=~crc.testing ~apps-crc.testing ~crc.example.com\n")
+
+	version, err := parseSystemdVersion("systemd 249 (v249.9-1.fc35)\n+PAM +AUDIT +SELINUX")
+	assert.NoError(t, err)
+	assert.Equal(t, 249, version)
+	_, err = parseSystemdVersion("")
+	assert.Error(t, err)
+}
+
+func TestLibvirtSessionPreflights(t *testing.T) {
+	filter := newFilter()
+	filter.SetDistro(&fedora)
+	filter.SetNetworkMode(network.UserNetworkingMode)
+	filter.SetLibvirtURI(constants.LibvirtSessionURI)
+
+	var checks []string
+	for _, check := range filter.Apply(libvirtPreflightChecks(&fedora)) {
+		checks = append(checks, check.configKeySuffix)
+	}
+	assert.Contains(t, checks, "check-libvirt-session-kvm-access")
+	assert.NotContains(t, checks, "check-user-in-libvirt-group")
+	assert.NotContains(t, checks, "check-libvirt-running")
+}
+
+func TestCheckFirewalldZone(t *testing.T) {
+	assert.NoError(t, checkFirewalldZone("libvirt"))
+	assert.NoError(t, checkFirewalldZone("trusted"))
+	assert.EqualError(t, checkFirewalldZone("public"), "The 'crc' interface is in the firewalld zone 'public' instead of 'libvirt'")
+	assert.Error(t, checkFirewalldZone("no zone"))
+}
+
+func TestCheckNftablesRuleset(t *testing.T) {
+	assert.NoError(t, checkNftablesRuleset(""))
+	assert.NoError(t, checkNftablesRuleset(`table inet filter {
+	chain input {
+		type filter hook input priority filter; policy accept;
+	}
+}`))
+	assert.NoError(t, checkNftablesRuleset(`table inet filter {
+	chain input {
+		type filter hook input priority filter; policy drop;
+		ct state established,related accept
+		iifname "crc" accept
+	}
+}`))
+	assert.EqualError(t, checkNftablesRuleset(`table inet filter {
+	chain forward {
+		type filter hook forward priority filter; policy accept;
+		iifname "crc" accept
+	}
+	chain input {
+		type filter hook input priority filter; policy drop;
+		ct state established,related accept
+	}
+}`), "The nftables chain 'input' drops the incoming traffic of the 'crc' interface")
+}
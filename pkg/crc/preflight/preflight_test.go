@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/code-ready/crc/pkg/crc/config"
+	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -18,6 +19,19 @@ func TestCheckPreflight(t *testing.T) {
 	assert.False(t, calls.fixed)
 }
 
+func TestCheckPreflightFailure(t *testing.T) {
+	check, _ := sampleCheck(errors.New("check failed"), nil)
+	cfg := config.New(config.NewEmptyInMemoryStorage())
+	doRegisterSettings(cfg, []Check{*check})
+
+	err := doPreflightChecks(cfg, []Check{*check})
+	var preflightErr *crcErrors.PreflightError
+	assert.ErrorAs(t, err, &preflightErr)
+	assert.EqualError(t, err, "check failed")
+	assert.Equal(t, "sample", preflightErr.CheckID)
+	assert.Equal(t, "Run 'crc setup' to fix it (sample fix)", preflightErr.Remediation)
+}
+
 func TestSkipPreflight(t *testing.T) {
 	check, calls := sampleCheck(nil, nil)
 	cfg := config.New(config.NewEmptyInMemoryStorage())
@@ -34,7 +48,7 @@ func TestFixPreflight(t *testing.T) {
 	cfg := config.New(config.NewEmptyInMemoryStorage())
 	doRegisterSettings(cfg, []Check{*check})
 
-	assert.NoError(t, doFixPreflightChecks(cfg, []Check{*check}, false))
+	assert.NoError(t, doFixPreflightChecks(cfg, []Check{*check}, false, nil))
 	assert.True(t, calls.checked)
 	assert.True(t, calls.fixed)
 }
@@ -44,11 +58,102 @@ func TestFixPreflightCheckOnly(t *testing.T) {
 	cfg := config.New(config.NewEmptyInMemoryStorage())
 	doRegisterSettings(cfg, []Check{*check})
 
-	assert.Error(t, doFixPreflightChecks(cfg, []Check{*check}, true))
+	assert.Error(t, doFixPreflightChecks(cfg, []Check{*check}, true, nil))
+	assert.True(t, calls.checked)
+	assert.False(t, calls.fixed)
+}
+
+func TestWarnPreflight(t *testing.T) {
+	check, calls := sampleCheck(errors.New("check failed"), nil)
+	cfg := config.New(config.NewEmptyInMemoryStorage())
+	doRegisterSettings(cfg, []Check{*check})
+	_, err := cfg.Set("warn-sample", true)
+	assert.NoError(t, err)
+
+	assert.NoError(t, doPreflightChecks(cfg, []Check{*check}))
 	assert.True(t, calls.checked)
+
+	assert.NoError(t, doFixPreflightChecks(cfg, []Check{*check}, false, nil))
 	assert.False(t, calls.fixed)
 }
 
+func TestListChecks(t *testing.T) {
+	check, _ := sampleCheck(nil, nil)
+	noID, _ := sampleCheck(nil, nil)
+	noID.configKeySuffix = ""
+
+	assert.Equal(t, []CheckInfo{
+		{
+			ID:          "sample",
+			Description: "Sample check",
+		},
+	}, doListChecks([]Check{*check, *noID, *check}))
+}
+
+func TestCheckHost(t *testing.T) {
+	failing, failingCalls := sampleCheck(errors.New("check failed"), nil)
+	passing, _ := sampleCheck(nil, nil)
+	passing.configKeySuffix = "passing"
+	skipped, skippedCalls := sampleCheck(errors.New("check failed"), nil)
+	skipped.configKeySuffix = "skipped"
+	skipped.flags = NoFix
+	checks := []Check{*failing, *passing, *skipped}
+
+	cfg := config.New(config.NewEmptyInMemoryStorage())
+	doRegisterSettings(cfg, checks)
+	_, err := cfg.Set("skip-skipped", true)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []CheckResult{
+		{
+			ID:          "sample",
+			Description: "Sample check",
+			Status:      CheckFailed,
+			Fixable:     true,
+			Error:       "check failed",
+			Remediation: "Run 'crc setup' to fix it (sample fix)",
+		},
+		{
+			ID:          "passing",
+			Description: "Sample check",
+			Status:      CheckPassed,
+			Fixable:     true,
+		},
+		{
+			ID:          "skipped",
+			Description: "Sample check",
+			Status:      CheckSkipped,
+			Fixable:     false,
+		},
+	}, doCheckHost(cfg, checks))
+	assert.True(t, failingCalls.checked)
+	assert.False(t, failingCalls.fixed)
+	assert.False(t, skippedCalls.checked)
+}
+
+func TestPlanChecks(t *testing.T) {
+	check, calls := sampleCheck(errors.New("check failed"), nil)
+	warned, _ := sampleCheck(nil, nil)
+	warned.configKeySuffix = "warned"
+	setupOnly, _ := sampleCheck(nil, nil)
+	setupOnly.configKeySuffix = "setup"
+	setupOnly.flags = SetupOnly
+	checks := []Check{*check, *warned, *setupOnly}
+
+	cfg := config.New(config.NewEmptyInMemoryStorage())
+	doRegisterSettings(cfg, checks)
+	_, err := cfg.Set("skip-sample", true)
+	assert.NoError(t, err)
+	_, err = cfg.Set("warn-warned", true)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []PlannedCheck{
+		{ID: "sample", Description: "Sample check", Skipped: true},
+		{ID: "warned", Description: "Sample check", Warn: true},
+	}, doPlanChecks(cfg, checks))
+	assert.False(t, calls.checked)
+}
+
 func sampleCheck(checkErr, fixErr error) (*Check, *status) {
 	status := &status{}
 	return &Check{
@@ -24,6 +24,7 @@ var ubuntuPreflightChecks = []Check{
 		fix:                addAppArmorExceptionForQcowDisks(ioutil.ReadFile, crcos.WriteToFileAsRoot),
 		cleanupDescription: "Cleaning up AppArmor configuration",
 		cleanup:            removeAppArmorExceptionForQcowDisks(ioutil.ReadFile, crcos.WriteToFileAsRoot),
+		cleanupArea:        AppArmorArea,
 
 		labels: labels{Os: Linux, Distro: UbuntuLike},
 	},
@@ -6,6 +6,8 @@ import (
 	"os"
 	"strings"
 
+	"github.com/code-ready/crc/pkg/crc/cache"
+	"github.com/code-ready/crc/pkg/crc/cluster"
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/crc/preset"
@@ -37,7 +39,7 @@ var hypervPreflightChecks = []Check{
 		check:            checkWindowsEdition,
 		flags:            StartUpOnly,
 
-		labels: labels{Os: Windows},
+		labels: labels{Os: Windows, VMDriver: Hyperv},
 	},
 	{
 		configKeySuffix:  "check-hyperv-installed",
@@ -45,7 +47,7 @@ var hypervPreflightChecks = []Check{
 		check:            checkHyperVInstalled,
 		flags:            StartUpOnly,
 
-		labels: labels{Os: Windows},
+		labels: labels{Os: Windows, VMDriver: Hyperv},
 	},
 	{
 		configKeySuffix:  "check-crc-users-group-exists",
@@ -67,7 +69,7 @@ var hypervPreflightChecks = []Check{
 		fixDescription:   "Adding current user to Hyper-V Admins group",
 		fix:              fixUserPartOfHyperVAdmins,
 
-		labels: labels{Os: Windows},
+		labels: labels{Os: Windows, VMDriver: Hyperv},
 	},
 	{
 		configKeySuffix:  "check-hyperv-service-running",
@@ -75,7 +77,7 @@ var hypervPreflightChecks = []Check{
 		check:            checkHyperVServiceRunning,
 		flags:            StartUpOnly,
 
-		labels: labels{Os: Windows},
+		labels: labels{Os: Windows, VMDriver: Hyperv},
 	},
 	{
 		configKeySuffix:  "check-hyperv-switch",
@@ -83,11 +85,12 @@ var hypervPreflightChecks = []Check{
 		check:            checkIfHyperVVirtualSwitchExists,
 		flags:            StartUpOnly,
 
-		labels: labels{Os: Windows},
+		labels: labels{Os: Windows, VMDriver: Hyperv},
 	},
 	{
 		cleanupDescription: "Removing dns server from interface",
 		cleanup:            removeDNSServerAddress,
+		cleanupArea:        DNSArea,
 		flags:              CleanUpOnly,
 
 		labels: labels{Os: Windows},
@@ -95,9 +98,47 @@ var hypervPreflightChecks = []Check{
 	{
 		cleanupDescription: "Removing crc's virtual machine",
 		cleanup:            removeCrcVM,
+		cleanupArea:        VMArea,
 		flags:              CleanUpOnly,
 
-		labels: labels{Os: Windows},
+		labels: labels{Os: Windows, VMDriver: Hyperv},
+	},
+	{
+		cleanupDescription: "Removing the password of the shared directories from the keyring",
+		cleanup:            cluster.ForgetSharedDirPassword,
+		cleanupArea:        SharedDirsArea,
+		flags:              CleanUpOnly,
+
+		labels: labels{Os: Windows, VMDriver: Hyperv},
+	},
+}
+
+var wslPreflightChecks = []Check{
+	{
+		configKeySuffix:  "check-wsl-installed",
+		checkDescription: "Checking if WSL is installed",
+		check:            checkWSLInstalled,
+		fixDescription:   "Installing WSL",
+		fix:              fixWSLInstalled,
+
+		labels: labels{Os: Windows, VMDriver: WSL2},
+	},
+	{
+		configKeySuffix:  "check-wsl-version",
+		checkDescription: "Checking if WSL supports systemd",
+		check:            checkWSLVersion,
+		fixDescription:   "Updating WSL",
+		fix:              fixWSLVersion,
+
+		labels: labels{Os: Windows, VMDriver: WSL2},
+	},
+	{
+		cleanupDescription: "Removing crc's WSL distribution",
+		cleanup:            removeCrcWSLDistribution,
+		cleanupArea:        VMArea,
+		flags:              CleanUpOnly,
+
+		labels: labels{Os: Windows, VMDriver: WSL2},
 	},
 }
 
@@ -112,6 +153,41 @@ var vsockChecks = []Check{
 	},
 }
 
+// nestedVirtualizationPreflightChecks are only run when the
+// enable-nested-virtualization setting is true
+var nestedVirtualizationPreflightChecks = []Check{
+	{
+		configKeySuffix:  "check-nested-virtualization",
+		checkDescription: "Checking if Hyper-V supports nested virtualization on this processor",
+		check:            checkNestedVirtualizationSupported,
+		fixDescription:   fmt.Sprintf("Nested virtualization needs an Intel processor, or an AMD processor with Windows build %d or newer", minimumAMDNestedVirtualizationBuild),
+		flags:            NoFix,
+
+		labels: labels{Os: Windows, VMDriver: Hyperv},
+	},
+}
+
+func getNestedVirtualizationPreflightChecks() []Check {
+	return nestedVirtualizationPreflightChecks
+}
+
+func hostResolverPreflightChecks(clusterDomain string) []Check {
+	return []Check{
+		{
+			configKeySuffix:    "check-nrpt-rules",
+			checkDescription:   "Checking if the DNS client uses the crc daemon for the cluster domains",
+			check:              checkNrptRules(clusterDomain),
+			fixDescription:     "Adding NRPT rules for the cluster domains",
+			fix:                fixNrptRules(clusterDomain),
+			cleanupDescription: "Removing the NRPT rules of the cluster domains",
+			cleanup:            removeNrptRules,
+			cleanupArea:        DNSArea,
+
+			labels: labels{Os: Windows, NetworkMode: User},
+		},
+	}
+}
+
 var errReboot = errors.New("Please reboot your system and run 'crc setup' to complete the setup process")
 
 func username() string {
@@ -145,24 +221,53 @@ func checkVsock() error {
 // - tray checks when using an installer, regardless of tray enabled or not
 // - both user and system networking checks
 //
-// Passing 'UserNetworkingMode' to getPreflightChecks currently achieves this
-// as there are no system networking specific checks
+// The network mode is not set in the filter to keep the checks of both modes
 func getAllPreflightChecks() []Check {
-	return getPreflightChecks(true, network.UserNetworkingMode, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift)
+	filter := newFilter()
+	checks := filter.Apply(getChecks(constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift, false, ""))
+	// the checks of the optional features are only run when they are enabled
+	checks = append(checks, nestedVirtualizationPreflightChecks...)
+	checks = append(checks, shellCompletionPreflightChecks...)
+	return append(checks, clientsInPathPreflightChecks...)
 }
 
-func getChecks(bundlePath string, preset crcpreset.Preset) []Check {
+func getChecks(bundlePath string, preset crcpreset.Preset, skipBundleVerification bool, clusterDomain string) []Check {
 	checks := []Check{}
 	checks = append(checks, hypervPreflightChecks...)
+	checks = append(checks, wslPreflightChecks...)
 	checks = append(checks, vsockChecks...)
-	checks = append(checks, bundleCheck(bundlePath, preset))
+	checks = append(checks, firewallPreflightChecks...)
+	checks = append(checks, hostResolverPreflightChecks(clusterDomain)...)
+	checks = append(checks, bundleCheck(bundlePath, preset, skipBundleVerification))
 	checks = append(checks, genericCleanupChecks...)
 	return checks
 }
 
-func getPreflightChecks(_ bool, networkMode network.Mode, bundlePath string, preset crcpreset.Preset) []Check {
+func getPreflightChecks(_ bool, networkMode network.Mode, bundlePath string, preset crcpreset.Preset, skipBundleVerification bool, clusterDomain string) []Check {
 	filter := newFilter()
 	filter.SetNetworkMode(networkMode)
 
-	return filter.Apply(getChecks(bundlePath, preset))
+	return filter.Apply(getChecks(bundlePath, preset, skipBundleVerification, clusterDomain))
+}
+
+func offlineExecutables() []*cache.Cache {
+	return []*cache.Cache{
+		cache.NewAdminHelperCache(),
+	}
+}
+
+// getGPUPreflightChecks returns no checks, the enable-gpu setting is only
+// available on Linux
+func getGPUPreflightChecks() []Check {
+	return nil
+}
+
+func getQemuPreflightChecks() []Check {
+	return nil
+}
+
+// getRosettaPreflightChecks returns no checks, the enable-rosetta setting is
+// only available on macOS
+func getRosettaPreflightChecks() []Check {
+	return nil
 }
@@ -13,13 +13,27 @@ import (
 func TestCountConfigurationOptions(t *testing.T) {
 	cfg := config.New(config.NewEmptyInMemoryStorage())
 	RegisterSettings(cfg)
-	assert.Len(t, cfg.AllConfigs(), 10)
+	assert.Len(t, cfg.AllConfigs(), 30)
 }
 
 func TestCountPreflights(t *testing.T) {
-	assert.Len(t, getPreflightChecks(false, network.SystemNetworkingMode, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift), 15)
-	assert.Len(t, getPreflightChecks(true, network.SystemNetworkingMode, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift), 15)
+	assert.Len(t, getPreflightChecks(false, network.SystemNetworkingMode, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift, false, ""), 20)
+	assert.Len(t, getPreflightChecks(true, network.SystemNetworkingMode, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift, false, ""), 20)
 
-	assert.Len(t, getPreflightChecks(false, network.UserNetworkingMode, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift), 16)
-	assert.Len(t, getPreflightChecks(true, network.UserNetworkingMode, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift), 16)
+	assert.Len(t, getPreflightChecks(false, network.UserNetworkingMode, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift, false, ""), 21)
+	assert.Len(t, getPreflightChecks(true, network.UserNetworkingMode, constants.GetDefaultBundlePath(preset.OpenShift), preset.OpenShift, false, ""), 21)
+}
+
+func TestTaskRunning(t *testing.T) {
+	assert.True(t, taskRunning(`"\crcDaemon","N/A","Running"`+"\r\n"))
+	assert.False(t, taskRunning(`"\crcDaemon","N/A","Ready"`+"\r\n"))
+	assert.False(t, taskRunning(""))
+}
+
+func TestCheckFirewallRule(t *testing.T) {
+	assert.NoError(t, checkFirewallRule("True vEthernet (Default Switch)\r\n", "vEthernet (Default Switch)"))
+	assert.EqualError(t, checkFirewallRule("", "vEthernet (Default Switch)"), "The Windows Defender Firewall rule 'crc-hyperv-dns-dhcp' does not exist")
+	assert.EqualError(t, checkFirewallRule("False vEthernet (Default Switch)", "vEthernet (Default Switch)"), "The Windows Defender Firewall rule 'crc-hyperv-dns-dhcp' is disabled")
+	assert.EqualError(t, checkFirewallRule("True vEthernet (crc)", "vEthernet (Default Switch)"),
+		"The Windows Defender Firewall rule 'crc-hyperv-dns-dhcp' is for the interface 'vEthernet (crc)' instead of 'vEthernet (Default Switch)'")
 }
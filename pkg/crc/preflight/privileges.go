@@ -0,0 +1,58 @@
+package preflight
+
+import (
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+)
+
+// privilegedOperation is an operation done by start, stop or delete which
+// needs administrator rights. 'crc setup' grants these rights once, so that
+// these commands never have to ask for elevation.
+type privilegedOperation struct {
+	description string
+	grantedBy   string
+	commands    []string
+	check       CheckFunc
+
+	labels labels
+}
+
+// PrivilegedOperation reports if an operation requiring administrator rights
+// can be done without elevation
+type PrivilegedOperation struct {
+	Description string   `json:"description"`
+	GrantedBy   string   `json:"grantedBy"`
+	Commands    []string `json:"commands"`
+	Granted     bool     `json:"granted"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// VerifyLeastPrivilege lists the operations of start, stop and delete which
+// need administrator rights on this platform and with the configured network
+// mode and virtual machine driver, and checks that 'crc setup' already
+// granted these rights
+func VerifyLeastPrivilege(config crcConfig.Storage) []PrivilegedOperation {
+	filter := newFilter()
+	filter.SetNetworkMode(crcConfig.GetNetworkMode(config))
+	filter.SetVMDriver(crcConfig.GetVMDriver(config))
+
+	var operations []PrivilegedOperation
+	for _, operation := range privilegedOperations() {
+		if filter.excludes(operation.labels) {
+			continue
+		}
+		result := PrivilegedOperation{
+			Description: operation.description,
+			GrantedBy:   operation.grantedBy,
+			Commands:    operation.commands,
+			Granted:     true,
+		}
+		if err := operation.check(); err != nil {
+			logging.Debugf("%s: %v", operation.description, err)
+			result.Granted = false
+			result.Error = err.Error()
+		}
+		operations = append(operations, result)
+	}
+	return operations
+}
@@ -0,0 +1,42 @@
+package preflight
+
+import (
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/network"
+)
+
+func privilegedOperations() []privilegedOperation {
+	return []privilegedOperation{
+		{
+			description: "Run the HyperKit virtual machine",
+			grantedBy:   "SUID bit of the hyperkit executable",
+			commands:    []string{"start", "stop", "delete"},
+			check:       checkHyperKitInstalled(network.SystemNetworkingMode),
+
+			labels: labels{NetworkMode: System},
+		},
+		{
+			description: "Configure the network of the HyperKit virtual machine",
+			grantedBy:   "SUID bit of the crc-driver-hyperkit executable",
+			commands:    []string{"start", "stop", "delete"},
+			check:       checkMachineDriverHyperKitInstalled(network.SystemNetworkingMode),
+
+			labels: labels{NetworkMode: System},
+		},
+		{
+			description: "Add the cluster hostnames to /etc/hosts",
+			grantedBy:   "SUID bit of the crc-admin-helper executable",
+			commands:    []string{"start"},
+			check:       checkAdminHelperExecutableCached,
+		},
+		{
+			description: fmt.Sprintf("Update the DNS resolver configuration in %s", resolverFile),
+			grantedBy:   fmt.Sprintf("ownership of %s by the current user", resolverFile),
+			commands:    []string{"start"},
+			check:       checkResolverFilePermissions,
+
+			labels: labels{NetworkMode: System},
+		},
+	}
+}
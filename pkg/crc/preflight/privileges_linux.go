@@ -0,0 +1,44 @@
+package preflight
+
+func privilegedOperations() []privilegedOperation {
+	return []privilegedOperation{
+		{
+			description: "Create, start, stop and delete the libvirt virtual machine",
+			grantedBy:   "membership of the current user in the libvirt group",
+			commands:    []string{"start", "stop", "delete"},
+			check:       checkUserPartOfLibvirtGroup,
+
+			labels: labels{VMDriver: Libvirt},
+		},
+		{
+			description: "Run the QEMU virtual machine with KVM acceleration",
+			grantedBy:   "access of the current user to /dev/kvm",
+			commands:    []string{"start"},
+			check:       checkKvmAccessible,
+
+			labels: labels{VMDriver: Qemu},
+		},
+		{
+			description: "Add the cluster hostnames to /etc/hosts",
+			grantedBy:   "SUID bit of the crc-admin-helper executable",
+			commands:    []string{"start"},
+			check:       checkAdminHelperExecutableCached,
+		},
+		{
+			description: "Forward the ports 80 and 443 of the host to the virtual machine",
+			grantedBy:   "CAP_NET_BIND_SERVICE capability of the crc executable, and access to /dev/vsock",
+			commands:    []string{"start"},
+			check:       checkVsock,
+
+			labels: labels{NetworkMode: User, VMDriver: Libvirt},
+		},
+		{
+			description: "Forward the ports 80 and 443 of the host to the virtual machine",
+			grantedBy:   "CAP_NET_BIND_SERVICE capability of the crc executable, and access to /dev/vsock and /dev/vhost-vsock",
+			commands:    []string{"start"},
+			check:       checkQemuVsock,
+
+			labels: labels{NetworkMode: User, VMDriver: Qemu},
+		},
+	}
+}
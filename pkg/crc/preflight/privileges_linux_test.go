@@ -0,0 +1,52 @@
+package preflight
+
+import (
+	"testing"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyLeastPrivilegeNetworkMode(t *testing.T) {
+	cfg := crcConfig.New(crcConfig.NewEmptyInMemoryStorage())
+	crcConfig.RegisterSettings(cfg)
+
+	descriptions := func() []string {
+		var descriptions []string
+		for _, operation := range VerifyLeastPrivilege(cfg) {
+			descriptions = append(descriptions, operation.Description)
+		}
+		return descriptions
+	}
+
+	_, err := cfg.Set(crcConfig.NetworkMode, string(network.SystemNetworkingMode))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"Create, start, stop and delete the libvirt virtual machine",
+		"Add the cluster hostnames to /etc/hosts",
+	}, descriptions())
+
+	_, err = cfg.Set(crcConfig.NetworkMode, string(network.UserNetworkingMode))
+	assert.NoError(t, err)
+	assert.Len(t, descriptions(), 3)
+}
+
+func TestVerifyLeastPrivilegeQemuDriver(t *testing.T) {
+	cfg := crcConfig.New(crcConfig.NewEmptyInMemoryStorage())
+	crcConfig.RegisterSettings(cfg)
+
+	_, err := cfg.Set(crcConfig.NetworkMode, string(network.UserNetworkingMode))
+	assert.NoError(t, err)
+	_, err = cfg.Set(crcConfig.VMDriver, crcConfig.QemuVMDriver)
+	assert.NoError(t, err)
+	var descriptions []string
+	for _, operation := range VerifyLeastPrivilege(cfg) {
+		descriptions = append(descriptions, operation.Description)
+	}
+	assert.Equal(t, []string{
+		"Run the QEMU virtual machine with KVM acceleration",
+		"Add the cluster hostnames to /etc/hosts",
+		"Forward the ports 80 and 443 of the host to the virtual machine",
+	}, descriptions)
+}
@@ -0,0 +1,49 @@
+package preflight
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/os/windows/powershell"
+	"golang.org/x/sys/windows"
+)
+
+func privilegedOperations() []privilegedOperation {
+	return []privilegedOperation{
+		{
+			description: "Create, start, stop and delete the Hyper-V virtual machine",
+			grantedBy:   "membership of the current user in the Hyper-V Administrators group",
+			commands:    []string{"start", "stop", "delete"},
+			check:       checkIfUserPartOfHyperVAdmins,
+			labels:      labels{VMDriver: Hyperv},
+		},
+		{
+			description: "Add the cluster hostnames to the hosts file",
+			grantedBy:   "membership of the current user in the crc-users group, allowed to use the crc-admin-helper service",
+			commands:    []string{"start"},
+			check:       checkIfUserPartOfCrcUsers,
+		},
+	}
+}
+
+// checkIfUserPartOfCrcUsers compares the SIDs of the members of the
+// crc-users group with the SID of the current user, the names of the members
+// are prefixed with the computer name or the domain, which username() doesn't
+// have on computers outside of a domain
+func checkIfUserPartOfCrcUsers() error {
+	tokenUser, err := windows.GetCurrentProcessToken().GetTokenUser()
+	if err != nil {
+		return fmt.Errorf("Failed to get the SID of the current user: %v", err)
+	}
+	userSID := tokenUser.User.Sid.String()
+	stdOut, _, err := powershell.Execute("Get-LocalGroupMember -Group crc-users | ForEach-Object { $_.SID.Value }")
+	if err != nil {
+		return fmt.Errorf("Failed to list the members of the crc-users group: %v", err)
+	}
+	for _, member := range strings.Split(stdOut, "\n") {
+		if strings.EqualFold(strings.TrimSpace(member), userSID) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is not a member of the crc-users group", username())
+}
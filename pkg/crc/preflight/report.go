@@ -0,0 +1,64 @@
+package preflight
+
+import (
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+)
+
+type CheckStatus string
+
+const (
+	CheckPassed  CheckStatus = "pass"
+	CheckFailed  CheckStatus = "fail"
+	CheckSkipped CheckStatus = "skipped"
+	CheckWarned  CheckStatus = "warn"
+)
+
+// CheckResult is the outcome of one of the checks run by 'crc setup'
+type CheckResult struct {
+	ID          string      `json:"id,omitempty"`
+	Description string      `json:"description"`
+	Status      CheckStatus `json:"status"`
+	// Fixable is true when 'crc setup' can fix the check automatically
+	Fixable     bool   `json:"fixable"`
+	Error       string `json:"error,omitempty"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// CheckHost runs all the checks of 'crc setup' without fixing anything, and
+// reports the outcome of each of them
+func CheckHost(config crcConfig.Storage) []CheckResult {
+	experimentalFeatures := config.Get(crcConfig.ExperimentalFeatures).AsBool()
+	mode := crcConfig.GetNetworkMode(config)
+	bundlePath := config.Get(crcConfig.Bundle).AsString()
+	preset := crcConfig.GetPreset(config)
+	skipBundleVerification := config.Get(crcConfig.SkipBundleVerification).AsBool()
+	clusterDomain := config.Get(crcConfig.ClusterDomain).AsString()
+	return doCheckHost(config, withPluginChecks(withVMDriverChecks(config, getPreflightChecks(experimentalFeatures, mode, bundlePath, preset, skipBundleVerification, clusterDomain))))
+}
+
+func doCheckHost(config crcConfig.Storage, checks []Check) []CheckResult {
+	var results []CheckResult
+	for _, check := range checks {
+		if check.flags&CleanUpOnly == CleanUpOnly || check.flags&StartUpOnly == StartUpOnly {
+			continue
+		}
+		result := CheckResult{
+			ID:          check.configKeySuffix,
+			Description: check.checkDescription,
+			Status:      CheckPassed,
+			Fixable:     check.fix != nil && check.flags&NoFix != NoFix,
+		}
+		if check.shouldSkip(config) {
+			result.Status = CheckSkipped
+		} else if err := check.doCheck(config); err != nil {
+			result.Status = CheckFailed
+			if check.shouldWarn(config) {
+				result.Status = CheckWarned
+			}
+			result.Error = err.Error()
+			result.Remediation = check.remediation()
+		}
+		results = append(results, result)
+	}
+	return results
+}
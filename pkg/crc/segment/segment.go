@@ -37,6 +37,8 @@ type Client struct {
 
 	identifyHash     uint64
 	identifyHashPath string
+
+	disabled bool
 }
 
 func NewClient(config *crcConfig.Config, transport http.RoundTripper) (*Client, error) {
@@ -82,6 +84,11 @@ func (c *Client) Close() error {
 	return c.segmentClient.Close()
 }
 
+// Disable prevents any further upload, regardless of the telemetry consent
+func (c *Client) Disable() {
+	c.disabled = true
+}
+
 func (c *Client) UploadAction(action, source, status string) error {
 	return c.upload(action, baseProperties(source).
 		Set("status", status))
@@ -161,6 +168,9 @@ func (c *Client) identifyNew() *analytics.Identify {
 }
 
 func (c *Client) upload(action string, a analytics.Properties) error {
+	if c.disabled {
+		return nil
+	}
 	if c.config.Get(crcConfig.ConsentTelemetry).AsString() != "yes" {
 		return nil
 	}
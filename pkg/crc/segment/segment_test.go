@@ -206,6 +206,32 @@ func TestClientUploadWithOutConsent(t *testing.T) {
 	}
 }
 
+func TestClientUploadWithConsentWhenDisabled(t *testing.T) {
+	body, server := mockServer()
+	defer server.Close()
+	defer close(body)
+
+	dir, err := ioutil.TempDir("", "cfg")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	config, err := newTestConfig("yes")
+	require.NoError(t, err)
+
+	c, err := newCustomClient(config, http.DefaultTransport, filepath.Join(dir, "telemetry"), "", server.URL)
+	require.NoError(t, err)
+	c.Disable()
+
+	require.NoError(t, c.UploadCmd(context.Background(), "start", time.Second, nil))
+	require.NoError(t, c.Close())
+
+	select {
+	case <-body:
+		require.Fail(t, "server should not receive data")
+	default:
+	}
+}
+
 func TestClientUploadWithConsentAndCachedIdentify(t *testing.T) {
 	body, server := mockServer()
 	defer server.Close()
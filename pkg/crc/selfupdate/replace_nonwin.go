@@ -0,0 +1,12 @@
+//go:build !windows
+// +build !windows
+
+package selfupdate
+
+import "os"
+
+// replaceExecutable renames newExecutable over the executable, the running
+// processes keep the old file open
+func replaceExecutable(executable, newExecutable string) error {
+	return os.Rename(newExecutable, executable)
+}
@@ -0,0 +1,28 @@
+package selfupdate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// replaceExecutable moves the executable aside before renaming
+// newExecutable to it, as Windows doesn't allow to replace the executable of
+// a running process, but allows to rename it. The old executable is removed
+// by the next update.
+func replaceExecutable(executable, newExecutable string) error {
+	oldExecutable := executable + ".old"
+	if err := os.Remove(oldExecutable); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("Cannot remove %s: %w", oldExecutable, err)
+	}
+	if err := os.Rename(executable, oldExecutable); err != nil {
+		return err
+	}
+	if err := os.Rename(newExecutable, executable); err != nil {
+		if restoreErr := os.Rename(oldExecutable, executable); restoreErr != nil {
+			return fmt.Errorf("Cannot restore %s after the failed update: %v: %w", executable, restoreErr, err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+// Package selfupdate replaces the crc executable with the executable of a
+// newer release of the mirror
+package selfupdate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/gpg"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	crcversion "github.com/code-ready/crc/pkg/crc/version"
+	"github.com/code-ready/crc/pkg/download"
+	"github.com/code-ready/crc/pkg/extract"
+	crcos "github.com/code-ready/crc/pkg/os"
+)
+
+// archiveExtensions are the archives of the releases which contain the crc
+// executable, the installers of macOS and Windows don't
+var archiveExtensions = []string{".tar.xz", ".tar.gz", ".zip"}
+
+// Supported returns an error when the executable cannot replace itself
+func Supported() error {
+	if crcversion.IsInstaller() {
+		return errors.New("crc was installed with the installer, download and run the installer of the new release to update it")
+	}
+	return nil
+}
+
+// ExecutableName is the name of the crc executable in the release archives
+func ExecutableName() string {
+	if runtime.GOOS == "windows" {
+		return "crc.exe"
+	}
+	return "crc"
+}
+
+// ArchiveURL returns the archive of the release for this OS, the links of
+// the release are indexed by GOOS
+func ArchiveURL(release *crcversion.CrcReleaseInfo) (string, error) {
+	link, ok := release.Links[runtime.GOOS]
+	if !ok {
+		return "", fmt.Errorf("the release has no download for %s", runtime.GOOS)
+	}
+	uri, err := url.Parse(link)
+	if err != nil {
+		return "", err
+	}
+	for _, extension := range archiveExtensions {
+		if strings.HasSuffix(uri.Path, extension) {
+			return link, nil
+		}
+	}
+	return "", fmt.Errorf("%s is not an archive of the crc executable, download and install it manually", link)
+}
+
+// Download downloads the archive of the release to destDir, checks it
+// against the sha256sum.txt file next to it, whose signature is checked with
+// keyRing, and extracts the crc executable from it
+func Download(archiveURL, destDir string, keyRing *gpg.KeyRing) (string, error) {
+	sha256sum, err := keyRing.RemoteChecksum(archiveURL)
+	if err != nil {
+		return "", fmt.Errorf("Cannot verify %s: %w", archiveURL, err)
+	}
+	uri, err := url.Parse(archiveURL)
+	if err != nil {
+		return "", err
+	}
+	logging.Infof("Downloading %s", archiveURL)
+	archive, err := download.Download(archiveURL, filepath.Join(destDir, path.Base(uri.Path)), 0600, sha256sum)
+	if err != nil {
+		return "", err
+	}
+	executableName := ExecutableName()
+	extractedFiles, err := extract.UncompressWithFilter(archive, destDir, false,
+		func(filename string) bool { return filepath.Base(filename) == executableName })
+	if err != nil {
+		return "", fmt.Errorf("Cannot uncompress %s: %w", filepath.Base(archive), err)
+	}
+	if len(extractedFiles) != 1 {
+		return "", fmt.Errorf("%s does not contain %s", filepath.Base(archive), executableName)
+	}
+	return extractedFiles[0], nil
+}
+
+// ExecutableVersion runs 'crc version' to get the version of a crc
+// executable
+func ExecutableVersion(executable string) (string, error) {
+	stdout, stderr, err := crcos.RunWithDefaultLocale(executable, "version", "--output", "json")
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %v: %s", executable, err, stderr)
+	}
+	var version struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &version); err != nil {
+		return "", fmt.Errorf("Cannot parse the version of %s: %w", executable, err)
+	}
+	return version.Version, nil
+}
+
+// Replace replaces the executable with newExecutable. The new executable is
+// first copied next to it, so that the replacement is a rename in the same
+// directory, and the executable is either the old or the new one if crc is
+// interrupted.
+func Replace(executable, newExecutable string) error {
+	info, err := os.Stat(executable)
+	if err != nil {
+		return err
+	}
+	tmpFile, err := ioutil.TempFile(filepath.Dir(executable), ".crc-update-")
+	if err != nil {
+		return fmt.Errorf("Cannot write to the directory of %s: %w", executable, err)
+	}
+	defer os.Remove(tmpFile.Name())
+	if err := copyFile(newExecutable, tmpFile); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpFile.Name(), info.Mode().Perm()|0100); err != nil {
+		return err
+	}
+	return replaceExecutable(executable, tmpFile.Name())
+}
+
+func copyFile(src string, dst *os.File) error {
+	in, err := os.Open(filepath.Clean(src))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	if _, err := io.Copy(dst, in); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
@@ -0,0 +1,143 @@
+package selfupdate
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/gpg"
+	"github.com/code-ready/crc/pkg/crc/gpg/gpgtest"
+	crcversion "github.com/code-ready/crc/pkg/crc/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// releaseArchive returns a tar.gz archive with the crc executable in a
+// directory, like the archives of the Linux releases
+func releaseArchive(t *testing.T, content string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Name: "crc-linux-2.1.0-amd64/" + ExecutableName(),
+		Mode: 0755,
+		Size: int64(len(content)),
+	}))
+	_, err := tw.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func serveRelease(t *testing.T, signer *gpgtest.Signer, archive []byte, checksum string) *httptest.Server {
+	checksums := fmt.Sprintf("%s  crc-linux-amd64.tar.gz\n", checksum)
+	signature, err := signer.DetachSign([]byte(checksums))
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/2.1.0/crc-linux-amd64.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(archive)
+	})
+	mux.HandleFunc("/2.1.0/sha256sum.txt", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(checksums))
+	})
+	mux.HandleFunc("/2.1.0/sha256sum.txt.sig", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(signature)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestArchiveURL(t *testing.T) {
+	release := &crcversion.CrcReleaseInfo{Links: map[string]string{
+		runtime.GOOS: "https://mirror.openshift.com/crc/2.1.0/crc-amd64.tar.xz",
+	}}
+	archiveURL, err := ArchiveURL(release)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://mirror.openshift.com/crc/2.1.0/crc-amd64.tar.xz", archiveURL)
+
+	release.Links[runtime.GOOS] = "https://mirror.openshift.com/crc/2.1.0/crc-macos-installer.pkg"
+	_, err = ArchiveURL(release)
+	assert.EqualError(t, err, "https://mirror.openshift.com/crc/2.1.0/crc-macos-installer.pkg is not an archive of the crc executable, download and install it manually")
+
+	_, err = ArchiveURL(&crcversion.CrcReleaseInfo{})
+	assert.EqualError(t, err, fmt.Sprintf("the release has no download for %s", runtime.GOOS))
+}
+
+func newSigner(t *testing.T) (*gpgtest.Signer, *gpg.KeyRing) {
+	signer, err := gpgtest.NewSigner()
+	require.NoError(t, err)
+	keyRing, err := signer.KeyRing()
+	require.NoError(t, err)
+	return signer, keyRing
+}
+
+func TestDownload(t *testing.T) {
+	signer, keyRing := newSigner(t)
+	archive := releaseArchive(t, "new crc")
+	sum := sha256.Sum256(archive)
+	server := serveRelease(t, signer, archive, hex.EncodeToString(sum[:]))
+
+	destDir := t.TempDir()
+	executable, err := Download(server.URL+"/2.1.0/crc-linux-amd64.tar.gz", destDir, keyRing)
+	require.NoError(t, err)
+	assert.Equal(t, ExecutableName(), filepath.Base(executable))
+	content, err := os.ReadFile(executable)
+	assert.NoError(t, err)
+	assert.Equal(t, "new crc", string(content))
+}
+
+func TestDownloadWithWrongChecksum(t *testing.T) {
+	signer, keyRing := newSigner(t)
+	sum := sha256.Sum256([]byte("another archive"))
+	server := serveRelease(t, signer, releaseArchive(t, "new crc"), hex.EncodeToString(sum[:]))
+
+	_, err := Download(server.URL+"/2.1.0/crc-linux-amd64.tar.gz", t.TempDir(), keyRing)
+	assert.Error(t, err)
+}
+
+func TestDownloadWithWrongSignature(t *testing.T) {
+	signer, _ := newSigner(t)
+	_, otherKeyRing := newSigner(t)
+	archive := releaseArchive(t, "new crc")
+	sum := sha256.Sum256(archive)
+	server := serveRelease(t, signer, archive, hex.EncodeToString(sum[:]))
+
+	destDir := t.TempDir()
+	_, err := Download(server.URL+"/2.1.0/crc-linux-amd64.tar.gz", destDir, otherKeyRing)
+	assert.Error(t, err)
+	entries, err := os.ReadDir(destDir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestReplace(t *testing.T) {
+	dir := t.TempDir()
+	executable := filepath.Join(dir, ExecutableName())
+	require.NoError(t, os.WriteFile(executable, []byte("old crc"), 0755))
+	newExecutable := filepath.Join(t.TempDir(), ExecutableName())
+	require.NoError(t, os.WriteFile(newExecutable, []byte("new crc"), 0600))
+
+	require.NoError(t, Replace(executable, newExecutable))
+	content, err := os.ReadFile(executable)
+	assert.NoError(t, err)
+	assert.Equal(t, "new crc", string(content))
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(executable)
+		assert.NoError(t, err)
+		assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+		entries, err := os.ReadDir(dir)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/code-ready/crc/pkg/crc/adminhelper"
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/crc/services"
 	"github.com/code-ready/crc/pkg/crc/systemd"
@@ -58,6 +59,18 @@ func setupDnsmasq(serviceConfig services.ServicePostStartConfig) error {
 	return sd.Start(crcDnsmasqService)
 }
 
+// UpdateWorkerRecords adds the worker nodes to the DNS server running in the
+// control plane instance
+func UpdateWorkerRecords(serviceConfig services.ServicePostStartConfig) error {
+	if serviceConfig.NetworkMode == network.UserNetworkingMode {
+		return nil
+	}
+	if err := createDnsmasqDNSConfig(serviceConfig); err != nil {
+		return err
+	}
+	return systemd.NewInstanceSystemdCommander(serviceConfig.SSHRunner).Restart(crcDnsmasqService)
+}
+
 func getResolvFileValues(serviceConfig services.ServicePostStartConfig) (network.ResolvFileValues, error) {
 	dnsServers, err := dnsServers(serviceConfig)
 	if err != nil {
@@ -73,7 +86,20 @@ func getResolvFileValues(serviceConfig services.ServicePostStartConfig) (network
 	}, nil
 }
 
+// useDNSForwarder returns true when the queries of the instance go through the
+// DNS forwarder of the daemon, which uses the user provided nameservers
+func useDNSForwarder(serviceConfig services.ServicePostStartConfig) bool {
+	return serviceConfig.NetworkMode == network.UserNetworkingMode && len(serviceConfig.NameServers) > 0
+}
+
 func dnsServers(serviceConfig services.ServicePostStartConfig) ([]network.NameServer, error) {
+	if useDNSForwarder(serviceConfig) {
+		return []network.NameServer{
+			{
+				IPAddress: constants.VSockHostVirtualIP,
+			},
+		}, nil
+	}
 	if serviceConfig.NetworkMode == network.UserNetworkingMode {
 		return []network.NameServer{
 			{
@@ -89,7 +115,7 @@ func dnsServers(serviceConfig services.ServicePostStartConfig) ([]network.NameSe
 }
 
 func CheckCRCLocalDNSReachable(ctx context.Context, serviceConfig services.ServicePostStartConfig) (string, error) {
-	appsURI := fmt.Sprintf("foo.%s", serviceConfig.BundleMetadata.ClusterInfo.AppsDomain)
+	appsURI := serviceConfig.BundleMetadata.GetAppHostname("foo")
 	// Try 30 times for 1 second interval, In nested environment most of time crc failed to get
 	// Internal dns query resolved for some time.
 	var queryOutput string
@@ -133,15 +159,27 @@ func CheckCRCPublicDNSReachable(serviceConfig services.ServicePostStartConfig) (
 	return stdout, err
 }
 
+// addOpenShiftHosts adds the hostnames of the cluster to the hosts file. The
+// admin helper only manages the names in the domain of the bundle, the names
+// in a custom cluster domain must be resolved by the DNS of the user.
+// The hosts file is left untouched when the host already resolves the names
+// with the DNS resolver of the daemon.
 func addOpenShiftHosts(serviceConfig services.ServicePostStartConfig) error {
-	return adminhelper.UpdateHostsFile(serviceConfig.IP, serviceConfig.BundleMetadata.GetAPIHostname(),
-		serviceConfig.BundleMetadata.GetAppHostname("oauth-openshift"),
-		serviceConfig.BundleMetadata.GetAppHostname("console-openshift-console"),
-		serviceConfig.BundleMetadata.GetAppHostname("downloads-openshift-console"),
-		serviceConfig.BundleMetadata.GetAppHostname("canary-openshift-ingress-canary"),
-		serviceConfig.BundleMetadata.GetAppHostname("default-route-openshift-image-registry"))
+	if serviceConfig.NetworkMode == network.UserNetworkingMode && network.ResolvesTo(serviceConfig.BundleMetadata.GetDefaultAPIHostname(), serviceConfig.IP) {
+		logging.Debug("The host resolves the names of the cluster, not updating the hosts file")
+		return nil
+	}
+	return adminhelper.UpdateHostsFile(serviceConfig.IP, serviceConfig.BundleMetadata.GetDefaultAPIHostname(),
+		serviceConfig.BundleMetadata.GetDefaultAppHostname("oauth-openshift"),
+		serviceConfig.BundleMetadata.GetDefaultAppHostname("console-openshift-console"),
+		serviceConfig.BundleMetadata.GetDefaultAppHostname("downloads-openshift-console"),
+		serviceConfig.BundleMetadata.GetDefaultAppHostname("canary-openshift-ingress-canary"),
+		serviceConfig.BundleMetadata.GetDefaultAppHostname("default-route-openshift-image-registry"))
 }
 
 func AddPodmanHosts(ip string) error {
+	if network.ResolvesTo("podman.crc.testing", ip) {
+		return nil
+	}
 	return adminhelper.UpdateHostsFile(ip, "podman.crc.testing")
 }
@@ -4,9 +4,13 @@ import (
 	"bytes"
 	"text/template"
 
+	"github.com/code-ready/crc/pkg/crc/cluster"
 	"github.com/code-ready/crc/pkg/crc/services"
 )
 
+// DnsmasqConfPath is the configuration file of the DNS server in the instance
+const DnsmasqConfPath = "/var/srv/dnsmasq.conf"
+
 const (
 	dnsmasqConfTemplate = `user=root
 port= {{ .Port }}
@@ -18,7 +22,14 @@ domain={{ .ClusterName}}.{{ .BaseDomain }}
 address=/{{ .AppsDomain }}/{{ .IP }}
 address=/api.{{ .ClusterName}}.{{ .BaseDomain }}/{{ .IP }}
 address=/api-int.{{ .ClusterName}}.{{ .BaseDomain }}/{{ .IP }}
+{{- if .ClusterDomain }}
+address=/apps.{{ .ClusterDomain }}/{{ .IP }}
+address=/api.{{ .ClusterDomain }}/{{ .IP }}
+{{- end }}
 address=/{{ .Hostname }}.{{ .ClusterName}}.{{ .BaseDomain }}/{{ .InternalIP }}
+{{- range .Workers }}
+address=/{{ .Hostname }}.{{ $.ClusterName}}.{{ $.BaseDomain }}/{{ .IP }}
+{{- end }}
 `
 )
 
@@ -30,6 +41,10 @@ type dnsmasqConfFileValues struct {
 	IP          string
 	AppsDomain  string
 	InternalIP  string
+	Workers     []cluster.WorkerNode
+	// ClusterDomain is only set when the user configured a domain other
+	// than the one of the bundle
+	ClusterDomain string
 }
 
 func createDnsmasqDNSConfig(serviceConfig services.ServicePostStartConfig) error {
@@ -43,6 +58,10 @@ func createDnsmasqDNSConfig(serviceConfig services.ServicePostStartConfig) error
 		ClusterName: serviceConfig.BundleMetadata.ClusterInfo.ClusterName,
 		IP:          serviceConfig.IP,
 		InternalIP:  serviceConfig.BundleMetadata.Nodes[0].InternalIP,
+		Workers:     serviceConfig.Workers,
+	}
+	if serviceConfig.BundleMetadata.HasCustomClusterDomain() {
+		dnsmasqConfFileValues.ClusterDomain = serviceConfig.BundleMetadata.GetClusterDomain()
 	}
 
 	dnsConfig, err := createDNSConfigFile(dnsmasqConfFileValues, dnsmasqConfTemplate)
@@ -50,7 +69,7 @@ func createDnsmasqDNSConfig(serviceConfig services.ServicePostStartConfig) error
 		return err
 	}
 
-	return serviceConfig.SSHRunner.CopyData([]byte(dnsConfig), "/var/srv/dnsmasq.conf", 0644)
+	return serviceConfig.SSHRunner.CopyData([]byte(dnsConfig), DnsmasqConfPath, 0644)
 }
 
 func createDNSConfigFile(values dnsmasqConfFileValues, tmpl string) (string, error) {
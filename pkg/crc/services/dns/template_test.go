@@ -0,0 +1,52 @@
+package dns
+
+import (
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDnsmasqConfigWithWorkers(t *testing.T) {
+	values := dnsmasqConfFileValues{
+		BaseDomain:  "testing",
+		Hostname:    "crc-dzk9v-master-0",
+		Port:        53,
+		ClusterName: "crc",
+		IP:          "192.168.130.11",
+		AppsDomain:  "apps-crc.testing",
+		InternalIP:  "192.168.126.11",
+		Workers: []cluster.WorkerNode{
+			{
+				Hostname: "crc-worker-1",
+				IP:       "192.168.130.12",
+			},
+		},
+	}
+	config, err := createDNSConfigFile(values, dnsmasqConfTemplate)
+	require.NoError(t, err)
+	assert.Contains(t, config, "address=/crc-dzk9v-master-0.crc.testing/192.168.126.11\naddress=/crc-worker-1.crc.testing/192.168.130.12\n")
+}
+
+func TestDnsmasqConfigWithClusterDomain(t *testing.T) {
+	values := dnsmasqConfFileValues{
+		BaseDomain:    "testing",
+		Hostname:      "crc-dzk9v-master-0",
+		Port:          53,
+		ClusterName:   "crc",
+		IP:            "192.168.130.11",
+		AppsDomain:    "apps-crc.testing",
+		InternalIP:    "192.168.126.11",
+		ClusterDomain: "crc.example.com",
+	}
+	config, err := createDNSConfigFile(values, dnsmasqConfTemplate)
+	require.NoError(t, err)
+	assert.Contains(t, config, "address=/apps-crc.testing/192.168.130.11\n")
+	assert.Contains(t, config, "address=/apps.crc.example.com/192.168.130.11\naddress=/api.crc.example.com/192.168.130.11\n")
+
+	values.ClusterDomain = ""
+	config, err = createDNSConfigFile(values, dnsmasqConfTemplate)
+	require.NoError(t, err)
+	assert.NotContains(t, config, "example.com")
+}
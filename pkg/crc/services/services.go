@@ -1,6 +1,7 @@
 package services
 
 import (
+	"github.com/code-ready/crc/pkg/crc/cluster"
 	"github.com/code-ready/crc/pkg/crc/machine/bundle"
 	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/crc/ssh"
@@ -12,4 +13,6 @@ type ServicePostStartConfig struct {
 	BundleMetadata bundle.CrcBundleInfo
 	IP             string
 	NetworkMode    network.Mode
+	NameServers    []network.Upstream
+	Workers        []cluster.WorkerNode
 }
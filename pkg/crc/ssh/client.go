@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"strconv"
@@ -15,6 +16,8 @@ import (
 
 type Client interface {
 	Run(command string) ([]byte, []byte, error)
+	RunWithIO(command string, stdin io.Reader, stdout io.Writer) ([]byte, error)
+	RunInteractive(command string, stdin io.Reader, stdout io.Writer, stderr io.Writer, tty bool) (int, error)
 	Close()
 }
 
@@ -91,6 +94,14 @@ func (client *NativeClient) session() (*ssh.Session, error) {
 }
 
 func (client *NativeClient) Run(command string) ([]byte, []byte, error) {
+	var stdout bytes.Buffer
+	stderr, err := client.RunWithIO(command, nil, &stdout)
+	return stdout.Bytes(), stderr, err
+}
+
+// RunWithIO runs the command with stdin as its standard input and stdout
+// as its standard output, it returns the standard error
+func (client *NativeClient) RunWithIO(command string, stdin io.Reader, stdout io.Writer) ([]byte, error) {
 	session, err := client.session()
 	if err != nil {
 		if client.conn != nil {
@@ -98,20 +109,18 @@ func (client *NativeClient) Run(command string) ([]byte, []byte, error) {
 			client.conn.Close()
 			client.conn = nil
 		}
-		return nil, nil, err
+		return nil, err
 	}
 	defer session.Close()
 
-	var (
-		stdout bytes.Buffer
-		stderr bytes.Buffer
-	)
-	session.Stdout = &stdout
+	var stderr bytes.Buffer
+	session.Stdin = stdin
+	session.Stdout = stdout
 	session.Stderr = &stderr
 
 	err = session.Run(command)
 
-	return stdout.Bytes(), stderr.Bytes(), err
+	return stderr.Bytes(), err
 }
 
 func (client *NativeClient) Close() {
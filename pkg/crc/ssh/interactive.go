@@ -0,0 +1,139 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+)
+
+const (
+	defaultTerminalWidth  = 80
+	defaultTerminalHeight = 24
+)
+
+// RunInteractive runs command, or a login shell when it is empty, with stdin,
+// stdout and stderr connected to the ones of crc. When tty is set and stdin is
+// a terminal, a pseudo-terminal is allocated and resized along with the local
+// one. It returns the exit status of the command.
+func (runner *Runner) RunInteractive(command string, tty bool) (int, error) {
+	return runner.client.RunInteractive(command, os.Stdin, os.Stdout, os.Stderr, tty)
+}
+
+func (client *NativeClient) RunInteractive(command string, stdin io.Reader, stdout io.Writer, stderr io.Writer, tty bool) (int, error) {
+	session, err := client.session()
+	if err != nil {
+		return -1, err
+	}
+	defer session.Close()
+
+	session.Stdin = stdin
+	session.Stdout = stdout
+	session.Stderr = stderr
+
+	if file, ok := stdin.(*os.File); ok && tty && term.IsTerminal(int(file.Fd())) {
+		restore, err := requestPty(session, int(file.Fd()), terminalSizeFd(file, stdout))
+		if err != nil {
+			return -1, err
+		}
+		defer restore()
+	}
+
+	if command == "" {
+		if err := session.Shell(); err != nil {
+			return -1, err
+		}
+		return ExitStatus(session.Wait())
+	}
+	return ExitStatus(session.Run(command))
+}
+
+// terminalSizeFd returns the file descriptor to query for the terminal size,
+// the size of the Windows console is only available from its output handle
+func terminalSizeFd(stdin *os.File, stdout io.Writer) int {
+	if file, ok := stdout.(*os.File); ok && term.IsTerminal(int(file.Fd())) {
+		return int(file.Fd())
+	}
+	return int(stdin.Fd())
+}
+
+func requestPty(session *ssh.Session, fd int, sizeFd int) (func(), error) {
+	width, height, err := term.GetSize(sizeFd)
+	if err != nil {
+		logging.Debugf("Cannot get the terminal size: %v", err)
+		width, height = defaultTerminalWidth, defaultTerminalHeight
+	}
+	termType := os.Getenv("TERM")
+	if termType == "" {
+		termType = "xterm-256color"
+	}
+	modes := ssh.TerminalModes{
+		ssh.ECHO:          1,
+		ssh.TTY_OP_ISPEED: 14400,
+		ssh.TTY_OP_OSPEED: 14400,
+	}
+	if err := session.RequestPty(termType, height, width, modes); err != nil {
+		return nil, fmt.Errorf("Cannot allocate a pseudo-terminal: %w", err)
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, err
+	}
+	stopResize := watchTerminalSize(sizeFd, func(width, height int) {
+		if err := session.WindowChange(height, width); err != nil {
+			logging.Debugf("Cannot resize the pseudo-terminal: %v", err)
+		}
+	})
+	return func() {
+		stopResize()
+		_ = term.Restore(fd, state)
+	}, nil
+}
+
+// ExitStatus returns the exit status of the remote command which returned
+// err. The error is only returned when the command didn't run or didn't exit.
+func ExitStatus(err error) (int, error) {
+	if err == nil {
+		return 0, nil
+	}
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitStatus(), nil
+	}
+	return -1, err
+}
+
+// CopyTo copies the content of src to the file destFilename in the instance.
+// When destFilename is a directory, the file is created in it with the base
+// name of srcFilename.
+func (runner *Runner) CopyTo(src io.Reader, srcFilename string, destFilename string, mode os.FileMode) error {
+	command := fmt.Sprintf(`dest=%[1]s; if [ -d "$dest" ]; then dest="$dest"/%[2]s; fi; install -m 0%[3]o /dev/null "$dest" && cat > "$dest"`,
+		shellQuote(destFilename), shellQuote(path.Base(srcFilename)), mode)
+	logging.Debugf("Running SSH command: %s", command)
+	stderr, err := runner.client.RunWithIO(command, src, io.Discard)
+	if err != nil {
+		return fmt.Errorf("Cannot copy %s to %s: %s", srcFilename, destFilename, strings.TrimSpace(string(stderr)))
+	}
+	return nil
+}
+
+// CopyFrom copies the file srcFilename of the instance to dest
+func (runner *Runner) CopyFrom(srcFilename string, dest io.Writer) error {
+	command := fmt.Sprintf("cat %s", shellQuote(srcFilename))
+	logging.Debugf("Running SSH command: %s", command)
+	stderr, err := runner.client.RunWithIO(command, nil, dest)
+	if err != nil {
+		return fmt.Errorf("Cannot copy %s: %s", srcFilename, strings.TrimSpace(string(stderr)))
+	}
+	return nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,61 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, `'/tmp/my file'`, shellQuote("/tmp/my file"))
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+}
+
+func TestRunInteractive(t *testing.T) {
+	clientKey, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+	clientKeyFile := filepath.Join(t.TempDir(), "private.key")
+	writePrivateKey(t, clientKeyFile, clientKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	createSSHServer(ctx, t, listener, clientKey, func(input string) (byte, string) {
+		switch input {
+		case "uptime":
+			return 0, "up 2 hours"
+		case "cat '/etc/hostname'":
+			return 0, "crc"
+		default:
+			return 3, "unexpected command: " + input
+		}
+	})
+	addr := listener.Addr().String()
+	runner, err := CreateRunner(ipFor(addr), portFor(addr), clientKeyFile)
+	require.NoError(t, err)
+	defer runner.Close()
+
+	var stdout, stderr bytes.Buffer
+	status, err := runner.client.RunInteractive("uptime", strings.NewReader(""), &stdout, &stderr, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, status)
+	assert.Equal(t, "up 2 hours", stdout.String())
+
+	status, err = runner.client.RunInteractive("false", strings.NewReader(""), &stdout, &stderr, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, status)
+
+	var hostname bytes.Buffer
+	assert.NoError(t, runner.CopyFrom("/etc/hostname", &hostname))
+	assert.Equal(t, "crc", hostname.String())
+}
@@ -0,0 +1,37 @@
+package ssh
+
+import (
+	"time"
+
+	"golang.org/x/term"
+)
+
+const terminalSizePollInterval = 250 * time.Millisecond
+
+// watchTerminalSize calls resize with the new size of the terminal fd each
+// time it is resized, until the returned function is called. There is no
+// SIGWINCH on Windows, the size of the console is polled instead.
+func watchTerminalSize(fd int, resize func(width, height int)) func() {
+	width, height, _ := term.GetSize(fd)
+	ticker := time.NewTicker(terminalSizePollInterval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				newWidth, newHeight, err := term.GetSize(fd)
+				if err != nil || (newWidth == width && newHeight == height) {
+					continue
+				}
+				width, height = newWidth, newHeight
+				resize(width, height)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
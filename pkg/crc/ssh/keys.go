@@ -1,17 +1,35 @@
 package ssh
 
 import (
+	"bytes"
+	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/rsa"
 	"crypto/x509"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 
 	gossh "golang.org/x/crypto/ssh"
 )
 
+// Types of the generated keys
+const (
+	ECDSAKey   = "ecdsa"
+	ED25519Key = "ed25519"
+	RSAKey     = "rsa"
+)
+
+// KeyTypes returns the types of the keys which can be generated
+func KeyTypes() []string {
+	return []string{ECDSAKey, ED25519Key, RSAKey}
+}
+
 var (
 	ErrKeyGeneration     = errors.New("Unable to generate key")
 	ErrPrivateKey        = errors.New("Unable to marshal private key")
@@ -24,11 +42,25 @@ type KeyPair struct {
 	PublicKey  []byte
 }
 
-// NewKeyPair generates a new SSH keypair
+// NewKeyPair generates a new ECDSA SSH keypair
 // This will return a private & public key encoded as DER.
 func NewKeyPair() (keyPair *KeyPair, err error) {
+	return NewKeyPairOfType(ECDSAKey)
+}
 
-	priv, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+// NewKeyPairOfType generates a new SSH keypair of keyType
+func NewKeyPairOfType(keyType string) (keyPair *KeyPair, err error) {
+	var priv crypto.Signer
+	switch keyType {
+	case ECDSAKey:
+		priv, err = ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	case ED25519Key:
+		_, priv, err = ed25519.GenerateKey(rand.Reader)
+	case RSAKey:
+		priv, err = rsa.GenerateKey(rand.Reader, 4096)
+	default:
+		return nil, fmt.Errorf("Unsupported key type '%s'", keyType)
+	}
 	if err != nil {
 		return nil, ErrKeyGeneration
 	}
@@ -38,7 +70,7 @@ func NewKeyPair() (keyPair *KeyPair, err error) {
 		return nil, ErrPrivateKey
 	}
 
-	pubSSH, err := gossh.NewPublicKey(&priv.PublicKey)
+	pubSSH, err := gossh.NewPublicKey(priv.Public())
 	if err != nil {
 		return nil, ErrPublicKey
 	}
@@ -49,15 +81,28 @@ func NewKeyPair() (keyPair *KeyPair, err error) {
 	}, nil
 }
 
+// WriteToFile writes keypair to files, they are only readable by the user
+func (kp *KeyPair) WriteToFile(privateKeyPath string, publicKeyPath string) error {
+	if err := writeKeyFile(privateKeyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Headers: nil, Bytes: kp.PrivateKey})); err != nil {
+		return err
+	}
+	return writeKeyFile(publicKeyPath, kp.PublicKey)
+}
+
 // GenerateSSHKey generates SSH keypair based on path of the private key
 // The public key would be generated to the same path with ".pub" added
 func GenerateSSHKey(path string) error {
+	return GenerateSSHKeyOfType(path, ECDSAKey)
+}
+
+// GenerateSSHKeyOfType generates a SSH keypair of keyType like GenerateSSHKey
+func GenerateSSHKeyOfType(path string, keyType string) error {
 	if _, err := os.Stat(path); err != nil {
 		if !os.IsNotExist(err) {
 			return fmt.Errorf("Desired directory for SSH keys does not exist: %s", err)
 		}
 
-		kp, err := NewKeyPair()
+		kp, err := NewKeyPairOfType(keyType)
 		if err != nil {
 			return fmt.Errorf("Error generating key pair: %s", err)
 		}
@@ -69,3 +114,51 @@ func GenerateSSHKey(path string) error {
 
 	return nil
 }
+
+// ValidateKeyPair checks that the private key at path is not encrypted, is
+// only readable by the user, and matches the public key at path with ".pub"
+// added
+func ValidateKeyPair(path string) error {
+	privateKey, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := checkKeyPermissions(path); err != nil {
+		return err
+	}
+	signer, err := gossh.ParsePrivateKey(privateKey)
+	if err != nil {
+		return fmt.Errorf("Invalid private key %s: %v", path, err)
+	}
+	publicKeyPath := fmt.Sprintf("%s.pub", path)
+	publicKey, err := ioutil.ReadFile(publicKeyPath)
+	if err != nil {
+		return err
+	}
+	parsed, _, _, _, err := gossh.ParseAuthorizedKey(publicKey)
+	if err != nil {
+		return fmt.Errorf("Invalid public key %s: %v", publicKeyPath, err)
+	}
+	if !bytes.Equal(parsed.Marshal(), signer.PublicKey().Marshal()) {
+		return fmt.Errorf("The public key %s does not match the private key %s", publicKeyPath, path)
+	}
+	return nil
+}
+
+// CopyKeyPair copies the keypair of the private key at src to dst, the
+// copies are only readable by the user
+func CopyKeyPair(src, dst string) error {
+	if err := ValidateKeyPair(src); err != nil {
+		return err
+	}
+	for _, suffix := range []string{"", ".pub"} {
+		data, err := ioutil.ReadFile(src + suffix)
+		if err != nil {
+			return err
+		}
+		if err := writeKeyFile(dst+suffix, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
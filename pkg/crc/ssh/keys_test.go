@@ -2,7 +2,16 @@ package ssh
 
 import (
 	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gossh "golang.org/x/crypto/ssh"
 )
 
 func TestNewKeyPair(t *testing.T) {
@@ -15,3 +24,44 @@ func TestNewKeyPair(t *testing.T) {
 		t.Fatal("No PEM returned")
 	}
 }
+
+func TestGenerateSSHKeyOfType(t *testing.T) {
+	for _, keyType := range KeyTypes() {
+		path := filepath.Join(t.TempDir(), "id_key")
+		require.NoError(t, GenerateSSHKeyOfType(path, keyType))
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		if runtime.GOOS != "windows" {
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		}
+		data, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		_, err = gossh.ParsePrivateKey(data)
+		assert.NoError(t, err, keyType)
+		assert.NoError(t, ValidateKeyPair(path), keyType)
+	}
+	assert.Error(t, GenerateSSHKeyOfType(filepath.Join(t.TempDir(), "id_key"), "dsa"))
+}
+
+func TestValidateKeyPair(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "id_key")
+	require.NoError(t, GenerateSSHKeyOfType(path, ED25519Key))
+	other := filepath.Join(dir, "id_other")
+	require.NoError(t, GenerateSSHKeyOfType(other, ED25519Key))
+
+	copied := filepath.Join(dir, "id_copy")
+	require.NoError(t, CopyKeyPair(path, copied))
+	assert.NoError(t, ValidateKeyPair(copied))
+
+	publicKey, err := ioutil.ReadFile(other + ".pub")
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(copied+".pub", publicKey, 0600))
+	assert.EqualError(t, ValidateKeyPair(copied), fmt.Sprintf("The public key %s.pub does not match the private key %s", copied, copied))
+
+	if runtime.GOOS != "windows" {
+		require.NoError(t, os.Chmod(path, 0644))
+		assert.Error(t, ValidateKeyPair(path))
+	}
+}
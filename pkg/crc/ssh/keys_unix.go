@@ -4,42 +4,36 @@
 package ssh
 
 import (
-	"encoding/pem"
+	"fmt"
 	"os"
 )
 
-// WriteToFile writes keypair to files
-func (kp *KeyPair) WriteToFile(privateKeyPath string, publicKeyPath string) error {
-	files := []struct {
-		File  string
-		Type  string
-		Value []byte
-	}{
-		{
-			File:  privateKeyPath,
-			Value: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Headers: nil, Bytes: kp.PrivateKey}),
-		},
-		{
-			File:  publicKeyPath,
-			Value: kp.PublicKey,
-		},
+// writeKeyFile writes data to path, the file is only readable by the user
+// from its creation
+func writeKeyFile(path string, data []byte) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return ErrUnableToWriteFile
 	}
-
-	for _, v := range files {
-		f, err := os.Create(v.File)
-		if err != nil {
-			return ErrUnableToWriteFile
-		}
-
-		if _, err := f.Write(v.Value); err != nil {
-			return ErrUnableToWriteFile
-		}
-
-		if err := f.Chmod(0600); err != nil {
-			return err
-		}
-
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return ErrUnableToWriteFile
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return ErrUnableToWriteFile
 	}
+	return nil
+}
 
+// checkKeyPermissions fails when the private key at path can be read by
+// other users, ssh refuses to use it then
+func checkKeyPermissions(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		return fmt.Errorf("The private key %s is accessible by other users, run 'chmod 600 %s'", path, path)
+	}
 	return nil
 }
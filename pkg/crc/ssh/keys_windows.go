@@ -1,44 +1,30 @@
 package ssh
 
 import (
-	"encoding/pem"
 	"os"
 
 	"github.com/hectane/go-acl"
 )
 
-// WriteToFile writes keypair to files
-func (kp *KeyPair) WriteToFile(privateKeyPath string, publicKeyPath string) error {
-	files := []struct {
-		File  string
-		Type  string
-		Value []byte
-	}{
-		{
-			File:  privateKeyPath,
-			Value: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Headers: nil, Bytes: kp.PrivateKey}),
-		},
-		{
-			File:  publicKeyPath,
-			Value: kp.PublicKey,
-		},
+// writeKeyFile writes data to path, the file is only readable by the user
+func writeKeyFile(path string, data []byte) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return ErrUnableToWriteFile
 	}
-
-	for _, v := range files {
-		f, err := os.Create(v.File)
-		if err != nil {
-			return ErrUnableToWriteFile
-		}
-
-		if _, err := f.Write(v.Value); err != nil {
-			return ErrUnableToWriteFile
-		}
-
-		if err := acl.Chmod(v.File, 0600); err != nil {
-			return err
-		}
-
+	// the file is empty until its ACL is set
+	if err := acl.Chmod(path, 0600); err != nil {
+		f.Close()
+		return err
 	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return ErrUnableToWriteFile
+	}
+	return nil
+}
 
+// checkKeyPermissions does nothing, the permissions of the files are ACLs
+func checkKeyPermissions(path string) error {
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"strings"
@@ -52,6 +53,35 @@ func (runner *Runner) RunPrivileged(reason string, cmdAndArgs ...string) (string
 	return runner.runSSHCommand(commandline, false)
 }
 
+// RunWithIO streams stdin to the command and its output to stdout, like
+// RunPrivilegedWithIO but without root access
+func (runner *Runner) RunWithIO(stdin io.Reader, stdout io.Writer, cmdAndArgs ...string) (string, error) {
+	commandline := strings.Join(cmdAndArgs, " ")
+	logging.Debugf("Running SSH command: %s", commandline)
+	stderr, err := runner.client.RunWithIO(commandline, stdin, stdout)
+	if err != nil {
+		return string(stderr), fmt.Errorf(`ssh command error:
+command : %s
+err     : %w`+"\n", commandline, err)
+	}
+	return string(stderr), nil
+}
+
+// RunPrivilegedWithIO streams stdin to the command and its output to
+// stdout, for the data which is too large to be kept in memory
+func (runner *Runner) RunPrivilegedWithIO(reason string, stdin io.Reader, stdout io.Writer, cmdAndArgs ...string) (string, error) {
+	logging.Debugf("Using root access: %s", reason)
+	commandline := fmt.Sprintf("sudo %s", strings.Join(cmdAndArgs, " "))
+	logging.Debugf("Running SSH command: %s", commandline)
+	stderr, err := runner.client.RunWithIO(commandline, stdin, stdout)
+	if err != nil {
+		return string(stderr), fmt.Errorf(`ssh command error:
+command : %s
+err     : %w`+"\n", commandline, err)
+	}
+	return string(stderr), nil
+}
+
 func (runner *Runner) CopyData(data []byte, destFilename string, mode os.FileMode) error {
 	logging.Debugf("Creating %s with permissions 0%o in the CRC VM", destFilename, mode)
 	base64Data := base64.StdEncoding.EncodeToString(data)
@@ -0,0 +1,269 @@
+// Package statefile reads and writes the versioned state files of crc and of
+// the machines of libmachine
+package statefile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+)
+
+// The state files of the instances are JSON documents with the kind and the
+// version of their content. They are checked with the schema of their kind
+// when they are loaded, and the documents of the older versions, or the files
+// written before the documents were versioned, are migrated. The files which
+// older releases also read keep their content as the whole document, see
+// Schema.Inline.
+
+const backupSuffix = ".bak"
+
+type document struct {
+	Kind    string          `json:"kind"`
+	Version int             `json:"version"`
+	Data    json.RawMessage `json:"data"`
+	// wrapped is set when an inline document was read from an envelope
+	wrapped bool
+}
+
+// Schema describes the documents of a kind
+type Schema struct {
+	Kind string
+	// Version is the current version of the documents
+	Version int
+	// Migrations convert the content of a version to the next one, the
+	// migration of version 0 gets the whole file written before the
+	// documents were versioned
+	Migrations map[int]func(data []byte) ([]byte, error)
+	// Validate checks the content of a document of the current version
+	Validate func(data []byte) error
+	// Inline documents are written without the kind and the version, so
+	// that the releases which do not version them can still read them. The
+	// content is always of the current version, it carries its own version
+	// if it needs one.
+	Inline bool
+}
+
+// CorruptedError is returned when a state file cannot be loaded, 'crc
+// repair-state' can recover it
+type CorruptedError struct {
+	Path string
+	Err  error
+}
+
+func (e *CorruptedError) Error() string {
+	return fmt.Sprintf("%s is corrupted: %v, run 'crc repair-state' to recover it", e.Path, e.Err)
+}
+
+func (e *CorruptedError) Unwrap() error {
+	return e.Err
+}
+
+// NewerVersionError is returned when a state file was written by a newer crc
+// release, the file is not corrupted and is left as is
+type NewerVersionError struct {
+	Path           string
+	Version        int
+	CurrentVersion int
+}
+
+func (e *NewerVersionError) Error() string {
+	return fmt.Sprintf("%s has the version %d, which is newer than the version %d of this crc release", e.Path, e.Version, e.CurrentVersion)
+}
+
+// Load returns the content of the document of schema at path. A document of
+// an older version is migrated and written again.
+func Load(path string, schema Schema) ([]byte, error) {
+	data, migrated, err := read(path, schema)
+	if err != nil {
+		return nil, err
+	}
+	if migrated {
+		logging.Debugf("Migrated %s to version %d", path, schema.Version)
+		if err := Save(path, schema, data); err != nil {
+			logging.Debugf("Cannot write the migrated %s: %v", path, err)
+		}
+	}
+	return data, nil
+}
+
+// read returns the content of the document at path, migrated to the current
+// version, and whether it had an older version
+func read(path string, schema Schema) ([]byte, bool, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	doc, err := decode(raw, schema)
+	if err != nil {
+		return nil, false, &CorruptedError{Path: path, Err: err}
+	}
+	if doc.Version > schema.Version {
+		return nil, false, &NewerVersionError{Path: path, Version: doc.Version, CurrentVersion: schema.Version}
+	}
+	data, err := schema.migrate(doc)
+	if err != nil {
+		return nil, false, &CorruptedError{Path: path, Err: err}
+	}
+	if err := schema.validate(data); err != nil {
+		// the inline documents carry their own version
+		var newer *NewerVersionError
+		if errors.As(err, &newer) {
+			return nil, false, &NewerVersionError{Path: path, Version: newer.Version, CurrentVersion: newer.CurrentVersion}
+		}
+		return nil, false, &CorruptedError{Path: path, Err: err}
+	}
+	return data, doc.Version != schema.Version || doc.wrapped, nil
+}
+
+// decode parses the document of raw, the files written before the documents
+// were versioned are documents of version 0
+func decode(raw []byte, schema Schema) (*document, error) {
+	var doc document
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if doc.Kind == "" && doc.Version == 0 {
+		if schema.Inline {
+			return &document{Kind: schema.Kind, Version: schema.Version, Data: raw}, nil
+		}
+		return &document{Kind: schema.Kind, Data: raw}, nil
+	}
+	if doc.Kind != schema.Kind {
+		return nil, fmt.Errorf("document of kind '%s' instead of '%s'", doc.Kind, schema.Kind)
+	}
+	if len(doc.Data) == 0 || string(doc.Data) == "null" {
+		return nil, fmt.Errorf("the %s document has no data", schema.Kind)
+	}
+	if schema.Inline {
+		// the document was written with an envelope, it is written again
+		// without it
+		return &document{Kind: schema.Kind, Version: schema.Version, Data: doc.Data, wrapped: true}, nil
+	}
+	return &doc, nil
+}
+
+func (schema Schema) migrate(doc *document) ([]byte, error) {
+	data := doc.Data
+	for version := doc.Version; version < schema.Version; version++ {
+		migration, ok := schema.Migrations[version]
+		if !ok {
+			return nil, fmt.Errorf("cannot migrate the %s document from version %d", schema.Kind, version)
+		}
+		var err error
+		if data, err = migration(data); err != nil {
+			return nil, fmt.Errorf("cannot migrate the %s document from version %d: %w", schema.Kind, version, err)
+		}
+	}
+	return data, nil
+}
+
+func (schema Schema) validate(data []byte) error {
+	if !json.Valid(data) {
+		return errors.New("invalid JSON data")
+	}
+	if schema.Validate == nil {
+		return nil
+	}
+	return schema.Validate(data)
+}
+
+// Save writes data as a document of schema at path. The file is replaced
+// atomically and the previous document is kept as a backup when it is valid.
+func Save(path string, schema Schema, data []byte) error {
+	if err := schema.validate(data); err != nil {
+		return fmt.Errorf("Invalid %s document: %w", schema.Kind, err)
+	}
+	raw, err := schema.encode(data)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmpFile, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(raw); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	if _, _, err := read(path, schema); err == nil {
+		if err := os.Rename(path, path+backupSuffix); err != nil {
+			return err
+		}
+	}
+	return os.Rename(tmpFile.Name(), path)
+}
+
+func (schema Schema) encode(data []byte) ([]byte, error) {
+	if schema.Inline {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, data, "", "    "); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return json.MarshalIndent(document{Kind: schema.Kind, Version: schema.Version, Data: data}, "", "    ")
+}
+
+// Remove removes the document at path and its backup
+func Remove(path string) error {
+	for _, file := range []string{path, path + backupSuffix} {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Repair is the outcome of the repair of a state file
+type Repair string
+
+const (
+	// Valid is the outcome of the files which can be loaded
+	Valid Repair = "valid"
+	// Restored is the outcome of the corrupted files replaced by their
+	// backup
+	Restored Repair = "restored"
+	// Discarded is the outcome of the corrupted files without a valid
+	// backup, they are renamed with the .corrupted suffix
+	Discarded Repair = "discarded"
+)
+
+// RepairFile restores the corrupted document of schema at path from its
+// backup, or moves it aside when the backup cannot be loaded either. A
+// missing file is valid.
+func RepairFile(path string, schema Schema) (Repair, error) {
+	_, err := Load(path, schema)
+	var corrupted *CorruptedError
+	switch {
+	case err == nil || os.IsNotExist(err):
+		return Valid, nil
+	case !errors.As(err, &corrupted):
+		return "", err
+	}
+	if data, _, err := read(path+backupSuffix, schema); err == nil {
+		if err := os.Rename(path, path+".corrupted"); err != nil {
+			return "", err
+		}
+		if err := Save(path, schema, data); err != nil {
+			return "", err
+		}
+		return Restored, nil
+	}
+	if err := os.Rename(path, path+".corrupted"); err != nil {
+		return "", err
+	}
+	return Discarded, nil
+}
@@ -0,0 +1,154 @@
+package statefile
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sample struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+}
+
+var sampleSchema = Schema{
+	Kind:    "sample",
+	Version: 2,
+	Migrations: map[int]func([]byte) ([]byte, error){
+		0: func(data []byte) ([]byte, error) {
+			return data, nil
+		},
+		// version 1 had the size in GiB, version 2 has it in MiB
+		1: func(data []byte) ([]byte, error) {
+			var s sample
+			if err := json.Unmarshal(data, &s); err != nil {
+				return nil, err
+			}
+			s.Size *= 1024
+			return json.Marshal(s)
+		},
+	},
+	Validate: func(data []byte) error {
+		var s sample
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		if s.Name == "" {
+			return errors.New("no name")
+		}
+		return nil
+	},
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sample.json")
+	require.NoError(t, Save(path, sampleSchema, []byte(`{"name":"crc","size":1024}`)))
+
+	data, err := Load(path, sampleSchema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"crc","size":1024}`, string(data))
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"kind":"sample","version":2,"data":{"name":"crc","size":1024}}`, string(raw))
+	assert.NoFileExists(t, path+backupSuffix)
+
+	require.NoError(t, Save(path, sampleSchema, []byte(`{"name":"crc","size":2048}`)))
+	backup, err := Load(path+backupSuffix, sampleSchema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"crc","size":1024}`, string(backup))
+
+	assert.EqualError(t, Save(path, sampleSchema, []byte(`{"size":2048}`)), "Invalid sample document: no name")
+
+	require.NoError(t, Remove(path))
+	assert.NoFileExists(t, path)
+	assert.NoFileExists(t, path+backupSuffix)
+}
+
+func TestLoadMigrates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sample.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"kind":"sample","version":1,"data":{"name":"crc","size":2}}`), 0600))
+	data, err := Load(path, sampleSchema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"crc","size":2048}`, string(data))
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"kind":"sample","version":2,"data":{"name":"crc","size":2048}}`, string(raw))
+
+	// the files written before the documents were versioned
+	require.NoError(t, os.WriteFile(path, []byte(`{"name":"crc","size":3}`), 0600))
+	data, err = Load(path, sampleSchema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"crc","size":3072}`, string(data))
+}
+
+func TestInlineDocuments(t *testing.T) {
+	schema := Schema{Kind: "sample", Validate: sampleSchema.Validate, Inline: true}
+	path := filepath.Join(t.TempDir(), "sample.json")
+	require.NoError(t, Save(path, schema, []byte(`{"name":"crc","size":1024}`)))
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"crc","size":1024}`, string(raw))
+
+	data, err := Load(path, schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"crc","size":1024}`, string(data))
+
+	// the documents written with an envelope are written again without it
+	require.NoError(t, os.WriteFile(path, []byte(`{"kind":"sample","version":1,"data":{"name":"crc","size":2048}}`), 0600))
+	data, err = Load(path, schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"crc","size":2048}`, string(data))
+	raw, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"crc","size":2048}`, string(raw))
+}
+
+func TestLoadRefusesCorruptedFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sample.json")
+	for content, message := range map[string]string{
+		`{"kind":"sample","version":2,"data":{"na`:         "invalid JSON: unexpected end of JSON input",
+		`{"kind":"other","version":2,"data":{}}`:           "document of kind 'other' instead of 'sample'",
+		`{"kind":"sample","version":2}`:                    "the sample document has no data",
+		`{"kind":"sample","version":2,"data":{"size":12}}`: "no name",
+	} {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		_, err := Load(path, sampleSchema)
+		var corrupted *CorruptedError
+		assert.ErrorAs(t, err, &corrupted, content)
+		assert.EqualError(t, err, path+" is corrupted: "+message+", run 'crc repair-state' to recover it")
+	}
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"kind":"sample","version":3,"data":{"name":"crc"}}`), 0600))
+	_, err := Load(path, sampleSchema)
+	assert.EqualError(t, err, path+" has the version 3, which is newer than the version 2 of this crc release")
+}
+
+func TestRepairFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sample.json")
+	repair, err := RepairFile(path, sampleSchema)
+	require.NoError(t, err)
+	assert.Equal(t, Valid, repair)
+
+	require.NoError(t, Save(path, sampleSchema, []byte(`{"name":"crc","size":1024}`)))
+	require.NoError(t, Save(path, sampleSchema, []byte(`{"name":"crc","size":2048}`)))
+	require.NoError(t, os.WriteFile(path, []byte(`{"kind":"sample"`), 0600))
+	repair, err = RepairFile(path, sampleSchema)
+	require.NoError(t, err)
+	assert.Equal(t, Restored, repair)
+	data, err := Load(path, sampleSchema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"crc","size":1024}`, string(data))
+	assert.FileExists(t, path+".corrupted")
+
+	require.NoError(t, os.WriteFile(path+backupSuffix, []byte(`{}`), 0600))
+	require.NoError(t, os.WriteFile(path, []byte(`{}`), 0600))
+	repair, err = RepairFile(path, sampleSchema)
+	require.NoError(t, err)
+	assert.Equal(t, Discarded, repair)
+	assert.NoFileExists(t, path)
+}
@@ -0,0 +1,279 @@
+// Package stats records the duration of the phases of the starts of the
+// instance, to find which phases get slower over time
+package stats
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// maxRecords is the number of starts kept in the store
+	maxRecords = 100
+	// baselineRecords is the number of previous starts a start is compared to
+	baselineRecords = 10
+	// a phase regressed when it took regressionFactor times the median of
+	// the previous starts, and at least regressionMinimum more
+	regressionFactor  = 1.5
+	regressionMinimum = 10 * time.Second
+)
+
+// Phase is the duration of a phase of a start
+type Phase struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Record is a start of the instance
+type Record struct {
+	Time   time.Time `json:"time"`
+	Bundle string    `json:"bundle"`
+	// Creation is true when the instance was created by the start, these
+	// starts are only compared to each other
+	Creation bool          `json:"creation"`
+	Success  bool          `json:"success"`
+	Duration time.Duration `json:"duration"`
+	Phases   []Phase       `json:"phases"`
+	// SlowestOperators are the cluster operators which were the last to
+	// become ready
+	SlowestOperators []string `json:"slowestOperators,omitempty"`
+}
+
+// Timer measures the phases of a start, a phase ends when the next one begins
+type Timer struct {
+	started time.Time
+	current string
+	began   time.Time
+	phases  []Phase
+	now     func() time.Time
+}
+
+func NewTimer() *Timer {
+	return newTimer(time.Now)
+}
+
+func newTimer(now func() time.Time) *Timer {
+	return &Timer{
+		started: now(),
+		now:     now,
+	}
+}
+
+// Begin ends the current phase and starts the phase name
+func (t *Timer) Begin(name string) {
+	now := t.now()
+	t.end(now)
+	t.current = name
+	t.began = now
+}
+
+// Record ends the current phase and returns the record of the start
+func (t *Timer) Record(bundle string, creation, success bool) Record {
+	now := t.now()
+	t.end(now)
+	return Record{
+		Time:     t.started,
+		Bundle:   bundle,
+		Creation: creation,
+		Success:  success,
+		Duration: now.Sub(t.started),
+		Phases:   t.phases,
+	}
+}
+
+func (t *Timer) end(now time.Time) {
+	if t.current != "" {
+		t.phases = append(t.phases, Phase{Name: t.current, Duration: now.Sub(t.began)})
+		t.current = ""
+	}
+}
+
+// Store keeps the records of the last starts in a JSON file
+type Store struct {
+	path string
+	lock sync.Mutex
+}
+
+func NewStore(path string) *Store {
+	return &Store{
+		path: path,
+	}
+}
+
+// Add appends record to the store, the oldest records are dropped
+func (store *Store) Add(record Record) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	records, err := store.load()
+	if err != nil {
+		return err
+	}
+	records = append(records, record)
+	if len(records) > maxRecords {
+		records = records[len(records)-maxRecords:]
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(store.path, data, 0600)
+}
+
+// List returns the records, the oldest first
+func (store *Store) List() ([]Record, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	return store.load()
+}
+
+func (store *Store) load() ([]Record, error) {
+	data, err := ioutil.ReadFile(store.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var records []Record
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Trend compares a phase of the last successful start to the previous ones
+type Trend struct {
+	Phase string        `json:"phase"`
+	Last  time.Duration `json:"last"`
+	// Median is the median duration of the phase in the previous starts
+	Median     time.Duration `json:"median"`
+	Starts     int           `json:"starts"`
+	Regression bool          `json:"regression"`
+}
+
+// SlowOperator is a cluster operator which is often the last to be ready
+type SlowOperator struct {
+	Name   string `json:"name"`
+	Count  int    `json:"count"`
+	Starts int    `json:"starts"`
+}
+
+// Analysis is the summary of the records
+type Analysis struct {
+	Last          *Record        `json:"last,omitempty"`
+	Trends        []Trend        `json:"trends"`
+	SlowOperators []SlowOperator `json:"slowOperators"`
+}
+
+// Analyze compares the last successful start to the previous successful
+// starts of the same kind, creations are only compared to creations
+func Analyze(records []Record) *Analysis {
+	analysis := &Analysis{
+		Trends:        []Trend{},
+		SlowOperators: []SlowOperator{},
+	}
+	var successful []Record
+	for _, record := range records {
+		if record.Success {
+			successful = append(successful, record)
+		}
+	}
+	if len(successful) == 0 {
+		return analysis
+	}
+	last := successful[len(successful)-1]
+	analysis.Last = &last
+
+	var previous []Record
+	for _, record := range successful[:len(successful)-1] {
+		if record.Creation == last.Creation {
+			previous = append(previous, record)
+		}
+	}
+	if len(previous) > baselineRecords {
+		previous = previous[len(previous)-baselineRecords:]
+	}
+
+	phases := append(append([]Phase{}, last.Phases...), Phase{Name: "total", Duration: last.Duration})
+	for _, phase := range phases {
+		var durations []time.Duration
+		for _, record := range previous {
+			if duration, ok := phaseDuration(record, phase.Name); ok {
+				durations = append(durations, duration)
+			}
+		}
+		trend := Trend{
+			Phase:  phase.Name,
+			Last:   phase.Duration,
+			Starts: len(durations),
+		}
+		if len(durations) > 0 {
+			trend.Median = median(durations)
+			trend.Regression = float64(trend.Last) > regressionFactor*float64(trend.Median) &&
+				trend.Last-trend.Median >= regressionMinimum
+		}
+		analysis.Trends = append(analysis.Trends, trend)
+	}
+
+	analysis.SlowOperators = slowOperators(append(previous, last))
+	return analysis
+}
+
+func phaseDuration(record Record, name string) (time.Duration, bool) {
+	if name == "total" {
+		return record.Duration, true
+	}
+	for _, phase := range record.Phases {
+		if phase.Name == name {
+			return phase.Duration, true
+		}
+	}
+	return 0, false
+}
+
+func median(durations []time.Duration) time.Duration {
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// slowOperators returns the operators which were the last to be ready in at
+// least half of the starts of the cluster, the most frequent first
+func slowOperators(records []Record) []SlowOperator {
+	counts := map[string]int{}
+	starts := 0
+	for _, record := range records {
+		if len(record.SlowestOperators) == 0 {
+			continue
+		}
+		starts++
+		for _, name := range record.SlowestOperators {
+			counts[name]++
+		}
+	}
+	operators := []SlowOperator{}
+	// a single start does not make a trend
+	if starts < 2 {
+		return operators
+	}
+	for name, count := range counts {
+		if 2*count >= starts {
+			operators = append(operators, SlowOperator{Name: name, Count: count, Starts: starts})
+		}
+	}
+	sort.Slice(operators, func(i, j int) bool {
+		if operators[i].Count != operators[j].Count {
+			return operators[i].Count > operators[j].Count
+		}
+		return operators[i].Name < operators[j].Name
+	})
+	return operators
+}
@@ -0,0 +1,89 @@
+package stats
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimer(t *testing.T) {
+	now := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	timer := newTimer(func() time.Time { return now })
+	now = now.Add(time.Second)
+	timer.Begin("bundle")
+	now = now.Add(2 * time.Second)
+	timer.Begin("vm-start")
+	now = now.Add(30 * time.Second)
+
+	record := timer.Record("crc_libvirt_4.10.3_amd64", false, true)
+	assert.Equal(t, Record{
+		Time:     time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC),
+		Bundle:   "crc_libvirt_4.10.3_amd64",
+		Success:  true,
+		Duration: 33 * time.Second,
+		Phases: []Phase{
+			{Name: "bundle", Duration: 2 * time.Second},
+			{Name: "vm-start", Duration: 30 * time.Second},
+		},
+	}, record)
+}
+
+func TestStore(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "stats.json"))
+	records, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	for i := 0; i < maxRecords+2; i++ {
+		require.NoError(t, store.Add(Record{Duration: time.Duration(i) * time.Second}))
+	}
+	records, err = store.List()
+	require.NoError(t, err)
+	assert.Len(t, records, maxRecords)
+	assert.Equal(t, 2*time.Second, records[0].Duration)
+	assert.Equal(t, time.Duration(maxRecords+1)*time.Second, records[maxRecords-1].Duration)
+}
+
+func start(creation, success bool, dns time.Duration, slowestOperators ...string) Record {
+	return Record{
+		Creation: creation,
+		Success:  success,
+		Duration: time.Minute + dns,
+		Phases: []Phase{
+			{Name: "vm-start", Duration: time.Minute},
+			{Name: "dns", Duration: dns},
+		},
+		SlowestOperators: slowestOperators,
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	analysis := Analyze(nil)
+	assert.Nil(t, analysis.Last)
+	assert.Empty(t, analysis.Trends)
+
+	records := []Record{
+		start(true, true, 5*time.Minute, "console"),
+		start(false, true, 10*time.Second, "kube-apiserver"),
+		start(false, true, 12*time.Second, "kube-apiserver", "console"),
+		start(false, true, 14*time.Second, "authentication"),
+		start(false, true, 40*time.Second, "kube-apiserver"),
+		start(false, false, time.Hour),
+	}
+	analysis = Analyze(records)
+	assert.Equal(t, &records[4], analysis.Last)
+	assert.Equal(t, []Trend{
+		{Phase: "vm-start", Last: time.Minute, Median: time.Minute, Starts: 3},
+		{Phase: "dns", Last: 40 * time.Second, Median: 12 * time.Second, Starts: 3, Regression: true},
+		{Phase: "total", Last: time.Minute + 40*time.Second, Median: time.Minute + 12*time.Second, Starts: 3, Regression: false},
+	}, analysis.Trends)
+	assert.Equal(t, []SlowOperator{{Name: "kube-apiserver", Count: 3, Starts: 4}}, analysis.SlowOperators)
+}
+
+func TestMedian(t *testing.T) {
+	assert.Equal(t, 2*time.Second, median([]time.Duration{3 * time.Second, time.Second, 2 * time.Second}))
+	assert.Equal(t, 1500*time.Millisecond, median([]time.Duration{2 * time.Second, time.Second}))
+}
@@ -0,0 +1,258 @@
+package tunnel
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+)
+
+const (
+	dialTimeout = 10 * time.Second
+	peekTimeout = 10 * time.Second
+
+	// maxPortAttempts bounds the search of a free local port for a route
+	maxPortAttempts = 100
+)
+
+// Mapping is a route forwarded from a local address
+type Mapping struct {
+	Route types.Route
+	Local string
+}
+
+// IsTLS returns true when the connections are passed through to the router,
+// which selects the route with the server name sent by the client
+func (m Mapping) IsTLS() bool {
+	return m.Route.Termination != ""
+}
+
+// Tunnel forwards a local port to the router of the cluster for each route.
+// The TLS connections are passed through unchanged, the plain HTTP requests
+// are sent with the hostname of the route so that they can be sent to the
+// local address.
+type Tunnel struct {
+	httpAddress  string
+	httpsAddress string
+	nextPort     int
+
+	mutex    sync.Mutex
+	forwards map[string]*forward
+}
+
+type forward struct {
+	mapping  Mapping
+	listener net.Listener
+}
+
+// New creates a tunnel to the router listening on ingressIP, the local ports
+// are allocated from basePort
+func New(ingressIP string, basePort int) *Tunnel {
+	return newTunnel(net.JoinHostPort(ingressIP, "80"), net.JoinHostPort(ingressIP, "443"), basePort)
+}
+
+func newTunnel(httpAddress, httpsAddress string, basePort int) *Tunnel {
+	return &Tunnel{
+		httpAddress:  httpAddress,
+		httpsAddress: httpsAddress,
+		nextPort:     basePort,
+		forwards:     make(map[string]*forward),
+	}
+}
+
+func routeKey(route types.Route) string {
+	return fmt.Sprintf("%s/%s", route.Namespace, route.Name)
+}
+
+// Update forwards the new routes and stops forwarding the deleted ones, it
+// returns true when the mappings changed
+func (t *Tunnel) Update(routes []types.Route) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	changed := false
+	current := make(map[string]bool)
+	for _, route := range routes {
+		key := routeKey(route)
+		current[key] = true
+		if existing, ok := t.forwards[key]; ok {
+			if existing.mapping.Route == route {
+				continue
+			}
+			existing.close()
+			delete(t.forwards, key)
+		}
+		fwd, err := t.listen(route)
+		if err != nil {
+			logging.Warnf("Cannot forward the route %s: %v", key, err)
+			continue
+		}
+		t.forwards[key] = fwd
+		changed = true
+	}
+	for key, fwd := range t.forwards {
+		if !current[key] {
+			fwd.close()
+			delete(t.forwards, key)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Mappings returns the forwarded routes sorted by namespace and name
+func (t *Tunnel) Mappings() []Mapping {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var mappings []Mapping
+	for _, fwd := range t.forwards {
+		mappings = append(mappings, fwd.mapping)
+	}
+	sort.Slice(mappings, func(i, j int) bool {
+		return routeKey(mappings[i].Route) < routeKey(mappings[j].Route)
+	})
+	return mappings
+}
+
+// Close stops forwarding all the routes
+func (t *Tunnel) Close() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for key, fwd := range t.forwards {
+		fwd.close()
+		delete(t.forwards, key)
+	}
+}
+
+func (t *Tunnel) listen(route types.Route) (*forward, error) {
+	var lastErr error
+	for attempt := 0; attempt < maxPortAttempts; attempt++ {
+		port := t.nextPort
+		t.nextPort++
+		listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		fwd := &forward{
+			mapping:  Mapping{Route: route, Local: listener.Addr().String()},
+			listener: listener,
+		}
+		if fwd.mapping.IsTLS() {
+			go t.servePassthrough(fwd)
+		} else {
+			go t.serveHTTP(fwd)
+		}
+		return fwd, nil
+	}
+	return nil, fmt.Errorf("no free local port: %v", lastErr)
+}
+
+func (f *forward) close() {
+	if err := f.listener.Close(); err != nil {
+		logging.Debugf("Cannot close %s: %v", f.mapping.Local, err)
+	}
+}
+
+func (t *Tunnel) serveHTTP(fwd *forward) {
+	proxy := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			req.URL.Scheme = "http"
+			req.URL.Host = t.httpAddress
+			req.Host = fwd.mapping.Route.Host
+		},
+	}
+	// the error is returned when the listener is closed
+	_ = http.Serve(fwd.listener, proxy)
+}
+
+func (t *Tunnel) servePassthrough(fwd *forward) {
+	for {
+		conn, err := fwd.listener.Accept()
+		if err != nil {
+			return
+		}
+		go t.passthrough(conn, fwd.mapping.Route)
+	}
+}
+
+func (t *Tunnel) passthrough(conn net.Conn, route types.Route) {
+	defer conn.Close()
+
+	_ = conn.SetReadDeadline(time.Now().Add(peekTimeout))
+	serverName, reader, err := peekServerName(conn)
+	if err != nil {
+		logging.Debugf("Cannot read the TLS client hello for %s: %v", route.Host, err)
+		return
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+	if serverName != route.Host {
+		logging.Debugf("The client connected to %s with the server name '%s', the router may not find the route", route.Host, serverName)
+	}
+
+	upstream, err := net.DialTimeout("tcp", t.httpsAddress, dialTimeout)
+	if err != nil {
+		logging.Debugf("Cannot connect to the router: %v", err)
+		return
+	}
+	defer upstream.Close()
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(upstream, reader)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(conn, upstream)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+var errServerNameRead = errors.New("server name read")
+
+// peekServerName returns the server name of the TLS client hello sent on
+// conn, and a reader returning all the data of conn including the client
+// hello
+func peekServerName(conn io.Reader) (string, io.Reader, error) {
+	var peeked bytes.Buffer
+	var serverName string
+	err := tls.Server(readOnlyConn{reader: io.TeeReader(conn, &peeked)}, &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			serverName = hello.ServerName
+			return nil, errServerNameRead
+		},
+	}).Handshake()
+	if !errors.Is(err, errServerNameRead) {
+		return "", nil, err
+	}
+	return serverName, io.MultiReader(&peeked, conn), nil
+}
+
+// readOnlyConn lets the TLS server parse the client hello without sending
+// anything to the client
+type readOnlyConn struct {
+	reader io.Reader
+}
+
+func (c readOnlyConn) Read(p []byte) (int, error)         { return c.reader.Read(p) }
+func (c readOnlyConn) Write(p []byte) (int, error)        { return 0, io.ErrClosedPipe }
+func (c readOnlyConn) Close() error                       { return nil }
+func (c readOnlyConn) LocalAddr() net.Addr                { return nil }
+func (c readOnlyConn) RemoteAddr() net.Addr               { return nil }
+func (c readOnlyConn) SetDeadline(t time.Time) error      { return nil }
+func (c readOnlyConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c readOnlyConn) SetWriteDeadline(t time.Time) error { return nil }
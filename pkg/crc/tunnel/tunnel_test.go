@@ -0,0 +1,66 @@
+package tunnel
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeekServerName(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go func() {
+		// the handshake fails as nothing answers the client hello
+		_ = tls.Client(client, &tls.Config{ServerName: "hello-demo.apps-crc.testing"}).Handshake() // #nosec G402
+	}()
+
+	serverName, reader, err := peekServerName(server)
+	require.NoError(t, err)
+	assert.Equal(t, "hello-demo.apps-crc.testing", serverName)
+
+	// the client hello is returned by the reader, it starts with a TLS
+	// handshake record
+	header := make([]byte, 1)
+	_, err = reader.Read(header)
+	require.NoError(t, err)
+	assert.Equal(t, byte(0x16), header[0])
+}
+
+func TestHTTPRoute(t *testing.T) {
+	var hosts []string
+	router := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts = append(hosts, r.Host)
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer router.Close()
+
+	tunnel := newTunnel(strings.TrimPrefix(router.URL, "http://"), "", 0)
+	defer tunnel.Close()
+	assert.True(t, tunnel.Update([]types.Route{{Namespace: "demo", Name: "hello", Host: "hello-demo.apps-crc.testing"}}))
+	assert.False(t, tunnel.Update([]types.Route{{Namespace: "demo", Name: "hello", Host: "hello-demo.apps-crc.testing"}}))
+
+	mappings := tunnel.Mappings()
+	require.Len(t, mappings, 1)
+	assert.False(t, mappings[0].IsTLS())
+
+	resp, err := http.Get("http://" + mappings[0].Local)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(body))
+	assert.Equal(t, []string{"hello-demo.apps-crc.testing"}, hosts)
+
+	assert.True(t, tunnel.Update(nil))
+	assert.Empty(t, tunnel.Mappings())
+}
@@ -0,0 +1,83 @@
+package upgrade
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+)
+
+type ChangeKind string
+
+const (
+	// the bundle of the new version is not on disk yet
+	BundleDownload ChangeKind = "bundle-download"
+	// the configuration file contains settings which no longer exist
+	ConfigRenamed ChangeKind = "config-renamed"
+	// the existing instance was created with the bundle of the previous version
+	VMRecreation ChangeKind = "vm-recreation"
+)
+
+// Change is a change between the previous and current crc versions which
+// requires an action from the user
+type Change struct {
+	Kind        ChangeKind `json:"kind"`
+	Description string     `json:"description"`
+}
+
+// Advisory lists the changes to be aware of after an upgrade, it is computed
+// on the first invocation of the new version
+type Advisory struct {
+	PreviousVersion string   `json:"previousVersion"`
+	CurrentVersion  string   `json:"currentVersion"`
+	Changes         []Change `json:"changes"`
+}
+
+// Environment describes the crc installation as it is when the upgrade is
+// detected
+type Environment struct {
+	// BundlePath is the bundle which will be used by the next 'crc start'
+	BundlePath   string
+	BundleExists bool
+	// InstanceBundleName is the bundle of the existing instance, it is empty
+	// when there is no instance
+	InstanceBundleName string
+	// UnknownSettings are the settings from the configuration file which are
+	// not supported by the current version
+	UnknownSettings []string
+}
+
+func newAdvisory(previousVersion, currentVersion string, env Environment) *Advisory {
+	advisory := &Advisory{
+		PreviousVersion: previousVersion,
+		CurrentVersion:  currentVersion,
+		Changes:         []Change{},
+	}
+	bundleName := filepath.Base(env.BundlePath)
+	if !env.BundleExists {
+		advisory.Changes = append(advisory.Changes, Change{
+			Kind:        BundleDownload,
+			Description: fmt.Sprintf("The bundle %s needs to be downloaded, run 'crc setup' before 'crc start'", bundleName),
+		})
+	}
+	if env.InstanceBundleName != "" && env.InstanceBundleName != bundleName {
+		advisory.Changes = append(advisory.Changes, Change{
+			Kind: VMRecreation,
+			Description: fmt.Sprintf("The existing instance uses the bundle %s, it must be deleted with 'crc delete' before 'crc start' can use %s",
+				env.InstanceBundleName, bundleName),
+		})
+	}
+	unknownSettings := append([]string{}, env.UnknownSettings...)
+	sort.Strings(unknownSettings)
+	for _, setting := range unknownSettings {
+		advisory.Changes = append(advisory.Changes, Change{
+			Kind: ConfigRenamed,
+			Description: fmt.Sprintf("The setting '%s' is not supported anymore, see 'crc config --help' for its replacement and remove it with 'crc config unset %s'",
+				setting, setting),
+		})
+	}
+	return advisory
+}
+
+func (a *Advisory) String() string {
+	return fmt.Sprintf("crc was upgraded from version %s to %s", a.PreviousVersion, a.CurrentVersion)
+}
@@ -0,0 +1,100 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+type storedState struct {
+	// Version is the version of the last crc invocation
+	Version  string    `json:"version"`
+	Advisory *Advisory `json:"advisory,omitempty"`
+	// Shown is true once the advisory was printed by the command line
+	Shown bool `json:"shown,omitempty"`
+}
+
+// Store records the version of the last crc invocation in a JSON file, to
+// detect upgrades and keep the advisory of the last one
+type Store struct {
+	path string
+	lock sync.Mutex
+}
+
+func NewStore(path string) *Store {
+	return &Store{
+		path: path,
+	}
+}
+
+// Detect records the current version. On the first invocation after an
+// upgrade, it computes and stores an advisory, environment is only called in
+// this case. There is no advisory when no previous version is recorded, on a
+// fresh install.
+func (store *Store) Detect(currentVersion string, environment func() Environment) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	state, err := store.load()
+	if err != nil {
+		return err
+	}
+	if state != nil && state.Version == currentVersion {
+		return nil
+	}
+
+	newState := &storedState{
+		Version: currentVersion,
+	}
+	if state != nil && state.Version != "" {
+		newState.Advisory = newAdvisory(state.Version, currentVersion, environment())
+	}
+	return store.save(newState)
+}
+
+// Advisory returns the advisory of the last upgrade, or nil if there is none
+func (store *Store) Advisory() (*Advisory, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	state, err := store.load()
+	if err != nil || state == nil {
+		return nil, err
+	}
+	return state.Advisory, nil
+}
+
+// Unshown returns the advisory of the last upgrade if it was not shown yet,
+// and marks it as shown
+func (store *Store) Unshown() (*Advisory, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	state, err := store.load()
+	if err != nil || state == nil || state.Advisory == nil || state.Shown {
+		return nil, err
+	}
+	state.Shown = true
+	return state.Advisory, store.save(state)
+}
+
+func (store *Store) load() (*storedState, error) {
+	data, err := ioutil.ReadFile(store.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state storedState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (store *Store) save(state *storedState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(store.path, data, 0600)
+}
@@ -0,0 +1,67 @@
+package upgrade
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectFirstInstall(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "version.json"))
+	require.NoError(t, store.Detect("2.1.0", func() Environment {
+		t.Fatal("environment must not be computed without a previous version")
+		return Environment{}
+	}))
+
+	advisory, err := store.Advisory()
+	require.NoError(t, err)
+	assert.Nil(t, advisory)
+}
+
+func TestDetectUpgrade(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "version.json"))
+	require.NoError(t, store.Detect("2.0.1", func() Environment {
+		return Environment{BundleExists: true}
+	}))
+	require.NoError(t, store.Detect("2.1.0", func() Environment {
+		return Environment{
+			BundlePath:         "/home/user/.crc/cache/crc_libvirt_4.10.3_amd64.crcbundle",
+			BundleExists:       false,
+			InstanceBundleName: "crc_libvirt_4.10.2_amd64.crcbundle",
+			UnknownSettings:    []string{"vsock-network", "disable-telemetry"},
+		}
+	}))
+
+	advisory, err := store.Unshown()
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.1", advisory.PreviousVersion)
+	assert.Equal(t, "2.1.0", advisory.CurrentVersion)
+	var kinds []ChangeKind
+	for _, change := range advisory.Changes {
+		kinds = append(kinds, change.Kind)
+	}
+	assert.Equal(t, []ChangeKind{BundleDownload, VMRecreation, ConfigRenamed, ConfigRenamed}, kinds)
+	assert.Contains(t, advisory.Changes[2].Description, "'disable-telemetry'")
+
+	advisory, err = store.Unshown()
+	require.NoError(t, err)
+	assert.Nil(t, advisory)
+
+	// the advisory is still available for the API once shown
+	advisory, err = store.Advisory()
+	require.NoError(t, err)
+	assert.Equal(t, "2.1.0", advisory.CurrentVersion)
+}
+
+func TestDetectSameVersion(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "version.json"))
+	require.NoError(t, store.Detect("2.0.1", func() Environment {
+		return Environment{BundleExists: true}
+	}))
+	require.NoError(t, store.Detect("2.0.1", func() Environment {
+		t.Fatal("environment must not be computed when the version is unchanged")
+		return Environment{}
+	}))
+}
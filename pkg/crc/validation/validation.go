@@ -7,6 +7,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/code-ready/crc/pkg/crc/constants"
@@ -15,6 +16,7 @@ import (
 	crcpreset "github.com/code-ready/crc/pkg/crc/preset"
 	"github.com/docker/go-units"
 	"github.com/pbnjay/memory"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
 )
 
 // ValidateCPUs checks if provided cpus count is valid
@@ -33,6 +35,38 @@ func ValidateMemory(value int, preset crcpreset.Preset) error {
 	return ValidateEnoughMemory(value)
 }
 
+// ValidateMonitoringMemory checks if the memory is enough to run the cluster
+// monitoring in addition to the cluster
+func ValidateMonitoringMemory(value int) error {
+	if value < constants.MonitoringMemory {
+		return fmt.Errorf("requires memory in MiB >= %d to run the cluster monitoring", constants.MonitoringMemory)
+	}
+	return nil
+}
+
+// AutoCPUs picks half of the host CPUs, between the default and the maximum
+// of the preset
+func AutoCPUs(preset crcpreset.Preset) int {
+	return clamp(runtime.NumCPU()/2, constants.GetDefaultCPUs(preset), constants.GetMaxAutoCPUs(preset))
+}
+
+// AutoMemory picks half of the host memory in MiB, between the default and
+// the maximum of the preset
+func AutoMemory(preset crcpreset.Preset) int {
+	totalMiB := int(memory.TotalMemory() / 1024 / 1024)
+	return clamp(totalMiB/2, constants.GetDefaultMemory(preset), constants.GetMaxAutoMemory(preset))
+}
+
+func clamp(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
 func ValidateDiskSize(value int) error {
 	if value < constants.DefaultDiskSize {
 		return fmt.Errorf("requires disk size in GiB >= %d", constants.DefaultDiskSize)
@@ -56,11 +90,21 @@ func ValidateEnoughMemory(value int) error {
 
 // ValidateBundlePath checks if the provided bundle path exist
 func ValidateBundlePath(bundlePath string, preset crcpreset.Preset) error {
-	if err := ValidatePath(bundlePath); err != nil {
+	userProvidedBundle := filepath.Base(bundlePath)
+	if bundle.IsRemote(bundlePath) {
+		if err := bundle.ValidateRemote(bundlePath); err != nil {
+			return err
+		}
+		// the name of a bundle of a registry is only known once it is
+		// pulled
+		userProvidedBundle = bundle.RemoteBundleName(bundlePath)
+		if userProvidedBundle == "" {
+			return nil
+		}
+	} else if err := ValidatePath(bundlePath); err != nil {
 		return err
 	}
 
-	userProvidedBundle := filepath.Base(bundlePath)
 	if userProvidedBundle != constants.GetDefaultBundle(preset) {
 		// Should append underscore (_) here, as we don't want crc_libvirt_4.7.15.crcbundle
 		// to be detected as a custom bundle for crc_libvirt_4.7.1.crcbundle
@@ -80,7 +124,12 @@ func ValidateBundlePath(bundlePath string, preset crcpreset.Preset) error {
 }
 
 func ValidateBundle(bundlePath string, preset crcpreset.Preset) error {
-	bundleName := filepath.Base(bundlePath)
+	localPath, ok := bundle.LocalPath(bundlePath)
+	if !ok {
+		/* remote bundle which is downloaded by the preflight checks */
+		return ValidateBundlePath(bundlePath, preset)
+	}
+	bundleName := filepath.Base(localPath)
 	_, err := bundle.Get(bundleName)
 	if err != nil {
 		return ValidateBundlePath(bundlePath, preset)
@@ -98,6 +147,21 @@ func ValidateIPAddress(ipAddress string) error {
 	return nil
 }
 
+// ValidateClusterDomain checks if domain can be used as the domain of the
+// cluster, the API server is then api.<domain> and the routes *.apps.<domain>
+func ValidateClusterDomain(domain string) error {
+	if errs := k8svalidation.IsDNS1123Subdomain(domain); len(errs) != 0 {
+		return fmt.Errorf("'%s' is not a valid domain: %s", domain, strings.Join(errs, ", "))
+	}
+	if !strings.Contains(domain, ".") {
+		return fmt.Errorf("'%s' is not a valid domain: it must have at least two labels", domain)
+	}
+	if len(domain) > 253-len("*.apps.") {
+		return fmt.Errorf("'%s' is too long", domain)
+	}
+	return nil
+}
+
 type InvalidPath struct {
 	path string
 }
@@ -115,6 +179,52 @@ func ValidatePath(path string) error {
 	return nil
 }
 
+// ValidateSharedDirs checks if dirs can be mounted in the instance: they must
+// be existing directories given with their absolute path, and they are
+// quoted in the mount commands so they cannot contain single quotes
+func ValidateSharedDirs(dirs []string) error {
+	if len(dirs) != 0 && runtime.GOOS == "darwin" {
+		return errors.New("sharing directories with the instance is not supported by the hyperkit driver")
+	}
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		if !filepath.IsAbs(dir) {
+			return fmt.Errorf("'%s' is not an absolute path", dir)
+		}
+		if strings.Contains(dir, "'") {
+			return fmt.Errorf("'%s' cannot be shared, it contains a single quote", dir)
+		}
+		info, err := os.Stat(dir)
+		if err != nil {
+			return &InvalidPath{path: dir}
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("'%s' is not a directory", dir)
+		}
+		if seen[filepath.Clean(dir)] {
+			return fmt.Errorf("'%s' is listed more than once", dir)
+		}
+		seen[filepath.Clean(dir)] = true
+	}
+	return nil
+}
+
+// ValidateOperators checks if the operator package names are valid, the
+// packages are only looked up in the catalogs of the cluster at start
+func ValidateOperators(names []string) error {
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if errs := k8svalidation.IsDNS1123Subdomain(name); len(errs) != 0 {
+			return fmt.Errorf("'%s' is not a valid operator package name: %s", name, strings.Join(errs, ", "))
+		}
+		if seen[name] {
+			return fmt.Errorf("'%s' is listed more than once", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
 type imagePullSecret struct {
 	Auths map[string]map[string]interface{} `json:"auths"`
 }
@@ -0,0 +1,76 @@
+package validation
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	crcpreset "github.com/code-ready/crc/pkg/crc/preset"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoSizeWithinPresetLimits(t *testing.T) {
+	for _, preset := range []crcpreset.Preset{crcpreset.OpenShift, crcpreset.Podman} {
+		cpus := AutoCPUs(preset)
+		assert.GreaterOrEqual(t, cpus, constants.GetDefaultCPUs(preset))
+		assert.LessOrEqual(t, cpus, constants.GetMaxAutoCPUs(preset))
+
+		memory := AutoMemory(preset)
+		assert.GreaterOrEqual(t, memory, constants.GetDefaultMemory(preset))
+		assert.LessOrEqual(t, memory, constants.GetMaxAutoMemory(preset))
+	}
+}
+
+func TestClamp(t *testing.T) {
+	assert.Equal(t, 4, clamp(2, 4, 8))
+	assert.Equal(t, 6, clamp(6, 4, 8))
+	assert.Equal(t, 8, clamp(16, 4, 8))
+}
+
+func TestValidateClusterDomain(t *testing.T) {
+	assert.NoError(t, ValidateClusterDomain("crc.example.com"))
+	assert.NoError(t, ValidateClusterDomain("my-crc.internal"))
+	assert.Error(t, ValidateClusterDomain("localdomain"))
+	assert.Error(t, ValidateClusterDomain("Crc.Example.com"))
+	assert.Error(t, ValidateClusterDomain("crc..example.com"))
+	assert.Error(t, ValidateClusterDomain("*.example.com"))
+}
+
+func TestValidateSharedDirs(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		assert.Error(t, ValidateSharedDirs([]string{t.TempDir()}))
+		return
+	}
+	dir := t.TempDir()
+	file := filepath.Join(dir, "file")
+	assert.NoError(t, os.WriteFile(file, []byte{}, 0600))
+
+	assert.NoError(t, ValidateSharedDirs(nil))
+	assert.NoError(t, ValidateSharedDirs([]string{dir}))
+	assert.Error(t, ValidateSharedDirs([]string{"src"}))
+	assert.Error(t, ValidateSharedDirs([]string{filepath.Join(dir, "missing")}))
+	assert.Error(t, ValidateSharedDirs([]string{file}))
+	assert.Error(t, ValidateSharedDirs([]string{dir, dir + string(filepath.Separator)}))
+}
+
+func TestValidateOperators(t *testing.T) {
+	assert.NoError(t, ValidateOperators(nil))
+	assert.NoError(t, ValidateOperators([]string{"serverless-operator", "openshift-pipelines-operator-rh"}))
+	assert.Error(t, ValidateOperators([]string{"Serverless"}))
+	assert.Error(t, ValidateOperators([]string{"serverless operator"}))
+	assert.Error(t, ValidateOperators([]string{"serverless-operator", "serverless-operator"}))
+}
+
+func TestValidateRemoteBundlePath(t *testing.T) {
+	assert.NoError(t, ValidateBundlePath("docker://quay.io/crcont/bundle:4.10.3", crcpreset.OpenShift))
+	assert.Error(t, ValidateBundlePath("docker://quay.io/crcont/bundle:", crcpreset.OpenShift))
+	assert.Error(t, ValidateBundlePath("https://mirror.openshift.com/bundles/", crcpreset.OpenShift))
+
+	customBundle := fmt.Sprintf("https://mirror.openshift.com/%s_custom.crcbundle",
+		bundle.GetBundleNameWithoutExtension(constants.GetDefaultBundle(crcpreset.OpenShift)))
+	assert.NoError(t, ValidateBundlePath(customBundle, crcpreset.OpenShift))
+}
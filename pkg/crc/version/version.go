@@ -2,11 +2,13 @@ package version
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
@@ -40,8 +42,17 @@ var (
 	linuxReleaseBuild = "false"
 )
 
+// release channels of the mirror, the value of the update-channel setting
 const (
-	releaseInfoLink = "https://developers.redhat.com/content-gateway/rest/mirror/pub/openshift-v4/clients/crc/latest/release-info.json"
+	StableChannel    = "stable"
+	CandidateChannel = "candidate"
+)
+
+const (
+	mirrorURL = "https://developers.redhat.com/content-gateway/rest/mirror/pub/openshift-v4/clients/crc"
+	// githubReleasesURL lists the releases of crc, release candidates
+	// included, the newest first
+	githubReleasesURL = "https://api.github.com/repos/code-ready/crc/releases"
 	// Tray version to be embedded in executable
 	crcTrayElectronVersion = "1.2.0"
 )
@@ -109,12 +120,42 @@ func InstallPath() string {
 	return filepath.Dir(src)
 }
 
-func GetCRCLatestVersionFromMirror(transport http.RoundTripper) (*CrcReleaseInfo, error) {
-	client := &http.Client{
-		Timeout:   5 * time.Second,
-		Transport: transport,
+// ReleaseInfoURL returns the release-info.json file of a release, in the
+// directory of its version on the mirror
+func ReleaseInfoURL(version string) string {
+	return fmt.Sprintf("%s/%s/release-info.json", mirrorURL, version)
+}
+
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	Draft   bool   `json:"draft"`
+}
+
+// newestRelease returns the version of the newest release, the tags of the
+// releases are their version with a 'v' prefix
+func newestRelease(releases []githubRelease) (string, error) {
+	var newest *semver.Version
+	for _, release := range releases {
+		if release.Draft {
+			continue
+		}
+		version, err := semver.NewVersion(strings.TrimPrefix(release.TagName, "v"))
+		if err != nil {
+			logging.Debugf("Ignoring the release %s: %v", release.TagName, err)
+			continue
+		}
+		if newest == nil || version.GreaterThan(newest) {
+			newest = version
+		}
+	}
+	if newest == nil {
+		return "", errors.New("no release found")
 	}
-	req, err := http.NewRequest(http.MethodGet, releaseInfoLink, nil)
+	return newest.String(), nil
+}
+
+func httpGet(client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -129,7 +170,36 @@ func GetCRCLatestVersionFromMirror(transport http.RoundTripper) (*CrcReleaseInfo
 		return nil, fmt.Errorf("HTTP error: %s: %d", response.Status, response.StatusCode)
 	}
 
-	releaseMetaData, err := ioutil.ReadAll(response.Body)
+	return ioutil.ReadAll(response.Body)
+}
+
+// GetCRCLatestVersionFromMirror returns the latest release of a channel. The
+// stable releases are published in the latest directory of the mirror, and
+// the release candidates are only listed on GitHub, their release-info.json
+// file is in the directory of their version on the mirror.
+func GetCRCLatestVersionFromMirror(transport http.RoundTripper, channel string) (*CrcReleaseInfo, error) {
+	client := &http.Client{
+		Timeout:   5 * time.Second,
+		Transport: transport,
+	}
+	releaseInfoURL := fmt.Sprintf("%s/latest/release-info.json", mirrorURL)
+	if channel == CandidateChannel {
+		data, err := httpGet(client, githubReleasesURL)
+		if err != nil {
+			return nil, err
+		}
+		var releases []githubRelease
+		if err := json.Unmarshal(data, &releases); err != nil {
+			return nil, fmt.Errorf("Error unmarshaling the releases of GitHub: %v", err)
+		}
+		version, err := newestRelease(releases)
+		if err != nil {
+			return nil, err
+		}
+		releaseInfoURL = ReleaseInfoURL(version)
+	}
+
+	releaseMetaData, err := httpGet(client, releaseInfoURL)
 	if err != nil {
 		return nil, err
 	}
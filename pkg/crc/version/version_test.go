@@ -0,0 +1,22 @@
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewestRelease(t *testing.T) {
+	version, err := newestRelease([]githubRelease{
+		{TagName: "v2.1.0"},
+		{TagName: "v2.2.0-rc.1"},
+		{TagName: "v2.3.0", Draft: true},
+		{TagName: "nightly"},
+		{TagName: "v2.0.1"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "2.2.0-rc.1", version)
+
+	_, err = newestRelease([]githubRelease{{TagName: "v2.3.0", Draft: true}})
+	assert.EqualError(t, err, "no release found")
+}
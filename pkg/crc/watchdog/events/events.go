@@ -0,0 +1,90 @@
+package events
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// maxEvents is the number of events kept in the store, the older ones are
+// dropped
+const maxEvents = 20
+
+type Kind string
+
+const (
+	// Crashed is recorded when the instance started by crc stopped without
+	// 'crc stop', its hypervisor process likely crashed
+	Crashed Kind = "crashed"
+	// Unreachable is recorded when the instance is running but does not
+	// answer ssh
+	Unreachable Kind = "unreachable"
+	// Recovered is recorded when the instance answers ssh again on its own
+	Recovered Kind = "recovered"
+	// Restarted is recorded when the watchdog restarted the instance
+	Restarted Kind = "restarted"
+	// RestartFailed is recorded when the watchdog could not restart the
+	// instance, or gave up restarting it
+	RestartFailed Kind = "restart-failed"
+)
+
+type Event struct {
+	Time    time.Time `json:"time"`
+	Kind    Kind      `json:"kind"`
+	Message string    `json:"message"`
+}
+
+// Store keeps the last events of the watchdog in a JSON file, so that the
+// status API and the next daemon can report them
+type Store struct {
+	path string
+	lock sync.Mutex
+}
+
+func NewStore(path string) *Store {
+	return &Store{
+		path: path,
+	}
+}
+
+func (store *Store) Add(event Event) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	events, err := store.load()
+	if err != nil {
+		return err
+	}
+	events = append(events, event)
+	if len(events) > maxEvents {
+		events = events[len(events)-maxEvents:]
+	}
+	data, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(store.path, data, 0600)
+}
+
+// List returns the events, the oldest first
+func (store *Store) List() ([]Event, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	return store.load()
+}
+
+func (store *Store) load() ([]Event, error) {
+	data, err := ioutil.ReadFile(store.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var events []Event
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,20 @@
+package events
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreKeepsLastEvents(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "events.json"))
+	for i := 0; i < maxEvents+5; i++ {
+		require.NoError(t, store.Add(Event{Kind: Crashed, Message: string(rune('a' + i))}))
+	}
+	events, err := store.List()
+	require.NoError(t, err)
+	assert.Len(t, events, maxEvents)
+	assert.Equal(t, "f", events[0].Message)
+}
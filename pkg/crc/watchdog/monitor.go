@@ -0,0 +1,175 @@
+package watchdog
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/code-ready/crc/pkg/crc/watchdog/events"
+)
+
+const (
+	checkInterval = 30 * time.Second
+	// the instance is unreachable after this number of failed checks, a
+	// loaded instance can miss one
+	unreachableChecks = 3
+	// the instance is not restarted more than maxRestarts times in
+	// restartWindow, a start failing in the same way every time would loop
+	maxRestarts   = 3
+	restartWindow = time.Hour
+)
+
+// Monitor watches the instance started by crc, and records an event when it
+// stopped without 'crc stop', which happens when its hypervisor process
+// crashed, or when it no longer answers ssh. With the restart-vm policy of
+// the vm-watchdog setting, the instance is also restarted.
+type Monitor struct {
+	client machine.Client
+	config crcConfig.Storage
+	events *events.Store
+	start  func(ctx context.Context) error
+
+	expectedRunning func() bool
+	probe           func() error
+	now             func() time.Time
+
+	failedChecks int
+	reported     bool
+	gaveUp       bool
+	restarts     []time.Time
+}
+
+// NewMonitor returns a monitor which restarts the instance with start
+func NewMonitor(client machine.Client, config crcConfig.Storage, store *events.Store, start func(ctx context.Context) error) *Monitor {
+	m := &Monitor{
+		client: client,
+		config: config,
+		events: store,
+		start:  start,
+		expectedRunning: func() bool {
+			return machine.ExpectedRunning(client.GetName())
+		},
+		now: time.Now,
+	}
+	m.probe = m.sshProbe
+	return m
+}
+
+// Run checks the instance every 30 seconds, it never returns
+func (m *Monitor) Run() {
+	for {
+		time.Sleep(checkInterval)
+		m.check()
+	}
+}
+
+func (m *Monitor) check() {
+	policy := crcConfig.GetWatchdogPolicy(m.config)
+	if policy == crcConfig.WatchdogOff || !m.expectedRunning() {
+		m.reset()
+		return
+	}
+	running, err := m.client.IsRunning()
+	if err != nil {
+		logging.Debugf("Cannot get the state of the instance: %v", err)
+		return
+	}
+	if !running {
+		m.incident(policy, events.Crashed, "The instance stopped unexpectedly, its hypervisor process may have crashed", false)
+		return
+	}
+	if err := m.probe(); err != nil {
+		m.failedChecks++
+		if m.failedChecks < unreachableChecks {
+			return
+		}
+		m.incident(policy, events.Unreachable, fmt.Sprintf("The instance is running but does not answer ssh: %v", err), true)
+		return
+	}
+	if m.reported {
+		m.record(events.Recovered, "The instance answers ssh again")
+	}
+	m.reset()
+}
+
+func (m *Monitor) reset() {
+	m.failedChecks = 0
+	m.reported = false
+	m.gaveUp = false
+}
+
+// incident reports the problem once, and restarts the instance with the
+// restart-vm policy. A running instance is powered off first.
+func (m *Monitor) incident(policy string, kind events.Kind, message string, powerOff bool) {
+	if !m.reported {
+		m.record(kind, message)
+		m.reported = true
+	}
+	if policy != crcConfig.WatchdogRestartVM || m.gaveUp {
+		return
+	}
+	if !m.canRestart() {
+		m.record(events.RestartFailed, fmt.Sprintf("The instance was restarted %d times in %s, it is no longer restarted until it runs again", maxRestarts, restartWindow))
+		m.gaveUp = true
+		return
+	}
+	m.restarts = append(m.restarts, m.now())
+
+	logging.Info("Restarting the instance...")
+	if powerOff {
+		if err := m.client.PowerOff(); err != nil {
+			m.record(events.RestartFailed, fmt.Sprintf("Cannot power off the instance: %v", err))
+			return
+		}
+	}
+	if err := m.start(context.Background()); err != nil {
+		m.record(events.RestartFailed, fmt.Sprintf("Cannot restart the instance: %v", err))
+		return
+	}
+	m.record(events.Restarted, "The instance was restarted")
+	m.failedChecks = 0
+	m.reported = false
+}
+
+func (m *Monitor) canRestart() bool {
+	var recent []time.Time
+	for _, restart := range m.restarts {
+		if m.now().Sub(restart) < restartWindow {
+			recent = append(recent, restart)
+		}
+	}
+	m.restarts = recent
+	return len(recent) < maxRestarts
+}
+
+// record logs the event and adds it to the store
+func (m *Monitor) record(kind events.Kind, message string) {
+	if kind == events.Restarted || kind == events.Recovered {
+		logging.Info(message)
+	} else {
+		logging.Warn(message)
+	}
+	if err := m.events.Add(events.Event{Time: m.now(), Kind: kind, Message: message}); err != nil {
+		logging.Debugf("Cannot record the watchdog event: %v", err)
+	}
+}
+
+// sshProbe runs a command in the instance, it fails when the instance hangs
+// or its network is down
+func (m *Monitor) sshProbe() error {
+	connectionDetails, err := m.client.ConnectionDetails()
+	if err != nil {
+		return err
+	}
+	runner, err := ssh.CreateRunner(connectionDetails.IP, connectionDetails.SSHPort, connectionDetails.SSHKeys...)
+	if err != nil {
+		return err
+	}
+	defer runner.Close()
+	_, _, err = runner.Run("true")
+	return err
+}
@@ -0,0 +1,115 @@
+package watchdog
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/code-ready/crc/pkg/crc/watchdog/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testMonitor struct {
+	*Monitor
+	client   *fakemachine.Client
+	starts   int
+	startErr error
+	probeErr error
+}
+
+func newTestMonitor(t *testing.T, policy string) *testMonitor {
+	cfg := crcConfig.New(crcConfig.NewEmptyInMemoryStorage())
+	crcConfig.RegisterSettings(cfg)
+	_, err := cfg.Set(crcConfig.VMWatchdog, policy)
+	require.NoError(t, err)
+
+	m := &testMonitor{client: fakemachine.NewClient()}
+	m.Monitor = NewMonitor(m.client, cfg, events.NewStore(filepath.Join(t.TempDir(), "events.json")), func(ctx context.Context) error {
+		m.starts++
+		if m.startErr == nil {
+			m.client.Stopped = false
+		}
+		return m.startErr
+	})
+	m.expectedRunning = func() bool { return true }
+	m.probe = func() error { return m.probeErr }
+	now := time.Date(2022, 3, 1, 20, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	return m
+}
+
+func (m *testMonitor) eventKinds(t *testing.T) []events.Kind {
+	recorded, err := m.events.List()
+	require.NoError(t, err)
+	var kinds []events.Kind
+	for _, event := range recorded {
+		kinds = append(kinds, event.Kind)
+	}
+	return kinds
+}
+
+func TestMonitorNotifiesCrashOnce(t *testing.T) {
+	m := newTestMonitor(t, crcConfig.WatchdogNotifyOnly)
+	m.check()
+	assert.Empty(t, m.eventKinds(t))
+
+	m.client.Stopped = true
+	m.check()
+	m.check()
+	assert.Equal(t, []events.Kind{events.Crashed}, m.eventKinds(t))
+	assert.Equal(t, 0, m.starts)
+}
+
+func TestMonitorRestartsCrashedInstance(t *testing.T) {
+	m := newTestMonitor(t, crcConfig.WatchdogRestartVM)
+	m.client.Stopped = true
+	m.check()
+	assert.Equal(t, 1, m.starts)
+	assert.Equal(t, []events.Kind{events.Crashed, events.Restarted}, m.eventKinds(t))
+}
+
+func TestMonitorUnreachable(t *testing.T) {
+	m := newTestMonitor(t, crcConfig.WatchdogNotifyOnly)
+	m.probeErr = errors.New("connection timed out")
+	m.check()
+	m.check()
+	assert.Empty(t, m.eventKinds(t))
+	m.check()
+	assert.Equal(t, []events.Kind{events.Unreachable}, m.eventKinds(t))
+
+	m.probeErr = nil
+	m.check()
+	assert.Equal(t, []events.Kind{events.Unreachable, events.Recovered}, m.eventKinds(t))
+}
+
+func TestMonitorGivesUpRestarting(t *testing.T) {
+	m := newTestMonitor(t, crcConfig.WatchdogRestartVM)
+	m.startErr = errors.New("start failed")
+	m.client.Stopped = true
+	for i := 0; i < 5; i++ {
+		m.check()
+	}
+	assert.Equal(t, maxRestarts, m.starts)
+	assert.Equal(t, []events.Kind{events.Crashed, events.RestartFailed, events.RestartFailed, events.RestartFailed, events.RestartFailed}, m.eventKinds(t))
+}
+
+func TestMonitorIgnoresStoppedInstance(t *testing.T) {
+	m := newTestMonitor(t, crcConfig.WatchdogRestartVM)
+	m.expectedRunning = func() bool { return false }
+	m.client.Stopped = true
+	m.check()
+	assert.Empty(t, m.eventKinds(t))
+	assert.Equal(t, 0, m.starts)
+}
+
+func TestMonitorOff(t *testing.T) {
+	m := newTestMonitor(t, crcConfig.WatchdogOff)
+	m.client.Stopped = true
+	m.check()
+	assert.Empty(t, m.eventKinds(t))
+}
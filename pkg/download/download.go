@@ -3,8 +3,13 @@ package download
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
+	"path"
+	"strings"
 	"time"
 
 	"github.com/code-ready/crc/pkg/crc/logging"
@@ -19,7 +24,7 @@ func doRequest(client *grab.Client, req *grab.Request) (string, error) {
 	const minSizeForProgressBar = 100_000_000
 
 	resp := client.Do(req)
-	if resp.Size() < minSizeForProgressBar {
+	if resp.Size() < minSizeForProgressBar || logging.IsQuiet() {
 		<-resp.Done
 		return resp.Filename, resp.Err()
 	}
@@ -46,6 +51,10 @@ loop:
 // Download function takes sha256sum as hex decoded byte
 // something like hex.DecodeString("33daf4c03f86120fdfdc66bddf6bfff4661c7ca11c5d")
 func Download(uri, destination string, mode os.FileMode, sha256sum []byte) (string, error) {
+	return downloadWithHeader(uri, destination, mode, sha256sum, nil)
+}
+
+func downloadWithHeader(uri, destination string, mode os.FileMode, sha256sum []byte, header http.Header) (string, error) {
 	logging.Debugf("Downloading %s to %s", uri, destination)
 
 	client := grab.NewClient()
@@ -54,6 +63,11 @@ func Download(uri, destination string, mode os.FileMode, sha256sum []byte) (stri
 	if err != nil {
 		return "", errors.Wrapf(err, "unable to get request from %s", uri)
 	}
+	for name, values := range header {
+		for _, value := range values {
+			req.HTTPRequest.Header.Add(name, value)
+		}
+	}
 	if sha256sum != nil {
 		req.SetChecksum(sha256.New(), sha256sum, true)
 	}
@@ -72,6 +86,73 @@ func Download(uri, destination string, mode os.FileMode, sha256sum []byte) (stri
 	return filename, nil
 }
 
+// InMemory downloads a small file, like a checksum list, without saving it
+func InMemory(uri string) ([]byte, error) {
+	logging.Debugf("Downloading %s", uri)
+
+	client := &http.Client{Transport: network.HTTPTransport()}
+	resp, err := client.Get(uri)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Cannot download %s: %s", uri, resp.Status)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// ChecksumsFilename lists the sha256 sums of the files of a directory of the
+// mirror, as sha256sum prints them
+const ChecksumsFilename = "sha256sum.txt"
+
+// ChecksumsURL returns the URL of the sha256sum.txt file of the directory of
+// fileURL
+func ChecksumsURL(fileURL string) (string, error) {
+	uri, err := url.Parse(fileURL)
+	if err != nil {
+		return "", err
+	}
+	uri.Path = path.Join(path.Dir(uri.Path), ChecksumsFilename)
+	uri.RawQuery = ""
+	return uri.String(), nil
+}
+
+// FindChecksum returns the sha256 sum of fileURL from checksums, the
+// content of the sha256sum.txt file of its directory
+func FindChecksum(checksums []byte, fileURL string) ([]byte, error) {
+	uri, err := url.Parse(fileURL)
+	if err != nil {
+		return nil, err
+	}
+	name := path.Base(uri.Path)
+	for _, line := range strings.Split(string(checksums), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return hex.DecodeString(fields[0])
+		}
+	}
+	return nil, fmt.Errorf("%s is not listed in %s", name, ChecksumsFilename)
+}
+
+// RemoteChecksum returns the sha256 sum of a file from the sha256sum.txt
+// file of its directory
+func RemoteChecksum(fileURL string) ([]byte, error) {
+	checksumsURL, err := ChecksumsURL(fileURL)
+	if err != nil {
+		return nil, err
+	}
+	checksums, err := InMemory(checksumsURL)
+	if err != nil {
+		return nil, err
+	}
+	sha256sum, err := FindChecksum(checksums, fileURL)
+	if err != nil {
+		return nil, fmt.Errorf("%w at %s", err, checksumsURL)
+	}
+	return sha256sum, nil
+}
+
 type RemoteFile struct {
 	uri       string
 	sha256sum string
@@ -93,6 +174,10 @@ func (r *RemoteFile) Download(bundlePath string, mode os.FileMode) (string, erro
 	return Download(r.uri, bundlePath, mode, sha256)
 }
 
+func (r *RemoteFile) GetURI() string {
+	return r.uri
+}
+
 func (r *RemoteFile) GetSha256Sum() string {
 	return r.sha256sum
 }
@@ -0,0 +1,422 @@
+package download
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/pkg/errors"
+)
+
+// OCIReferencePrefix is the prefix of the references to the artifacts of a
+// container registry, as used by skopeo
+const OCIReferencePrefix = "docker://"
+
+const (
+	defaultRegistry       = "docker.io"
+	defaultRegistryServer = "registry-1.docker.io"
+	defaultTag            = "latest"
+
+	ociManifestMediaType    = "application/vnd.oci.image.manifest.v1+json"
+	ociIndexMediaType       = "application/vnd.oci.image.index.v1+json"
+	dockerManifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+	dockerListMediaType     = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+	// OCITitleAnnotation is the file name of a layer pushed with oras
+	OCITitleAnnotation = "org.opencontainers.image.title"
+)
+
+// OCIReference is an artifact of a container registry,
+// docker://quay.io/crcont/bundle:4.10.3 or docker://quay.io/crcont/bundle@sha256:...
+type OCIReference struct {
+	Registry   string
+	Repository string
+	// Reference is a tag or a digest
+	Reference string
+}
+
+func ParseOCIReference(ref string) (*OCIReference, error) {
+	name := strings.TrimPrefix(ref, OCIReferencePrefix)
+	if name == ref || name == "" {
+		return nil, fmt.Errorf("'%s' is not a %s reference", ref, OCIReferencePrefix)
+	}
+	parsed := &OCIReference{Registry: defaultRegistry}
+	if i := strings.Index(name, "/"); i != -1 {
+		host := name[:i]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			parsed.Registry = host
+			name = name[i+1:]
+		}
+	}
+	if repository, digest, ok := cut(name, "@"); ok {
+		name = repository
+		parsed.Reference = digest
+	} else if i := strings.LastIndex(name, ":"); i != -1 {
+		parsed.Reference = name[i+1:]
+		name = name[:i]
+	} else {
+		parsed.Reference = defaultTag
+	}
+	if name == "" || parsed.Reference == "" {
+		return nil, fmt.Errorf("'%s' is not a valid reference", ref)
+	}
+	if parsed.Registry == defaultRegistry && !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	parsed.Repository = name
+	return parsed, nil
+}
+
+func (ref *OCIReference) String() string {
+	separator := ":"
+	if strings.HasPrefix(ref.Reference, "sha256:") {
+		separator = "@"
+	}
+	return fmt.Sprintf("%s%s/%s%s%s", OCIReferencePrefix, ref.Registry, ref.Repository, separator, ref.Reference)
+}
+
+func (ref *OCIReference) server() string {
+	if ref.Registry == defaultRegistry {
+		return defaultRegistryServer
+	}
+	return ref.Registry
+}
+
+func (ref *OCIReference) url(kind, reference string) string {
+	return fmt.Sprintf("https://%s/v2/%s/%s/%s", ref.server(), ref.Repository, kind, reference)
+}
+
+// OCILayer is a layer of an artifact manifest
+type OCILayer struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type ociManifest struct {
+	MediaType string     `json:"mediaType"`
+	Config    OCILayer   `json:"config"`
+	Layers    []OCILayer `json:"layers"`
+	Manifests []struct {
+		MediaType string `json:"mediaType"`
+		Digest    string `json:"digest"`
+		Platform  struct {
+			OS           string `json:"os"`
+			Architecture string `json:"architecture"`
+		} `json:"platform"`
+	} `json:"manifests"`
+}
+
+// OCIRegistry pulls the artifacts of a repository, it authenticates with
+// the credentials of podman or docker when the registry asks for them
+type OCIRegistry struct {
+	ref           *OCIReference
+	client        *http.Client
+	authorization string
+}
+
+func NewOCIRegistry(ref *OCIReference) *OCIRegistry {
+	return &OCIRegistry{
+		ref:    ref,
+		client: &http.Client{Transport: network.HTTPTransport()},
+	}
+}
+
+// Layers returns the layers of the artifact, the manifest of this platform
+// is used when the reference is a multi-platform index
+func (r *OCIRegistry) Layers() ([]OCILayer, error) {
+	manifest, err := r.manifest(r.ref.Reference)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.MediaType == ociIndexMediaType || manifest.MediaType == dockerListMediaType {
+		for _, platformManifest := range manifest.Manifests {
+			if platformManifest.Platform.OS == runtime.GOOS && platformManifest.Platform.Architecture == runtime.GOARCH {
+				manifest, err = r.manifest(platformManifest.Digest)
+				if err != nil {
+					return nil, err
+				}
+				return manifest.Layers, nil
+			}
+		}
+		return nil, fmt.Errorf("%s has no manifest for %s/%s", r.ref, runtime.GOOS, runtime.GOARCH)
+	}
+	return manifest.Layers, nil
+}
+
+// OCIImage is the manifest of a container image for one platform, Manifest
+// holds its bytes as served by the registry so that its digest is kept
+type OCIImage struct {
+	Manifest  []byte
+	MediaType string
+	Config    OCILayer
+	Layers    []OCILayer
+}
+
+// Image returns the manifest of the container image for the goos/goarch
+// platform, it is picked from the index of a multi-platform image
+func (r *OCIRegistry) Image(goos, goarch string) (*OCIImage, error) {
+	data, manifest, err := r.rawManifest(r.ref.Reference)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.MediaType == ociIndexMediaType || manifest.MediaType == dockerListMediaType {
+		found := false
+		for _, platformManifest := range manifest.Manifests {
+			if platformManifest.Platform.OS == goos && platformManifest.Platform.Architecture == goarch {
+				if data, manifest, err = r.rawManifest(platformManifest.Digest); err != nil {
+					return nil, err
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%s has no image for %s/%s", r.ref, goos, goarch)
+		}
+	}
+	if manifest.Config.Digest == "" {
+		return nil, fmt.Errorf("%s is not a container image", r.ref)
+	}
+	return &OCIImage{
+		Manifest:  data,
+		MediaType: manifest.MediaType,
+		Config:    manifest.Config,
+		Layers:    manifest.Layers,
+	}, nil
+}
+
+func (r *OCIRegistry) manifest(reference string) (*ociManifest, error) {
+	_, manifest, err := r.rawManifest(reference)
+	return manifest, err
+}
+
+func (r *OCIRegistry) rawManifest(reference string) ([]byte, *ociManifest, error) {
+	accept := strings.Join([]string{ociManifestMediaType, ociIndexMediaType, dockerManifestMediaType, dockerListMediaType}, ", ")
+	resp, err := r.get(r.ref.url("manifests", reference), accept)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("Cannot get the manifest of %s: %s", r.ref, resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "Cannot read the manifest of %s", r.ref)
+	}
+	var manifest ociManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, nil, errors.Wrapf(err, "Invalid manifest for %s", r.ref)
+	}
+	if manifest.MediaType == "" {
+		manifest.MediaType = resp.Header.Get("Content-Type")
+	}
+	return data, &manifest, nil
+}
+
+// DownloadLayer saves the layer to destination, its content is checked
+// against its digest
+func (r *OCIRegistry) DownloadLayer(layer OCILayer, destination string, mode os.FileMode) (string, error) {
+	algorithm, hexDigest, _ := cut(layer.Digest, ":")
+	if algorithm != "sha256" {
+		return "", fmt.Errorf("Unsupported digest %s", layer.Digest)
+	}
+	sha256sum, err := hex.DecodeString(hexDigest)
+	if err != nil {
+		return "", errors.Wrapf(err, "Invalid digest %s", layer.Digest)
+	}
+	// the token was requested with the manifest, it is not sent when the
+	// registry redirects to the host storing the layers
+	header := http.Header{}
+	if r.authorization != "" {
+		header.Set("Authorization", r.authorization)
+	}
+	return downloadWithHeader(r.ref.url("blobs", layer.Digest), destination, mode, sha256sum, header)
+}
+
+// get sends a GET request, it is sent again with an authorization when the
+// registry rejects it
+func (r *OCIRegistry) get(uri, accept string) (*http.Response, error) {
+	resp, err := r.doGet(uri, accept)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+	if err := r.authorize(challenge); err != nil {
+		return nil, err
+	}
+	return r.doGet(uri, accept)
+}
+
+func (r *OCIRegistry) doGet(uri, accept string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	if r.authorization != "" {
+		req.Header.Set("Authorization", r.authorization)
+	}
+	return r.client.Do(req)
+}
+
+func (r *OCIRegistry) authorize(challenge string) error {
+	credentials := registryCredentials(r.ref.Registry)
+	scheme, params := parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if credentials == "" {
+			return fmt.Errorf("%s needs credentials, log in with 'podman login %s'", r.ref.Registry, r.ref.Registry)
+		}
+		r.authorization = "Basic " + credentials
+		return nil
+	case "bearer":
+		token, err := r.token(params, credentials)
+		if err != nil {
+			return err
+		}
+		r.authorization = "Bearer " + token
+		return nil
+	default:
+		return fmt.Errorf("Unsupported authentication of %s: %s", r.ref.Registry, challenge)
+	}
+}
+
+// token requests a pull token from the authorization server of the
+// registry, anonymously when there are no credentials
+func (r *OCIRegistry) token(params map[string]string, credentials string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("Invalid authentication realm of %s", r.ref.Registry)
+	}
+	query := realm.Query()
+	if service, ok := params["service"]; ok {
+		query.Set("service", service)
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull", r.ref.Repository)
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if credentials != "" {
+		req.Header.Set("Authorization", "Basic "+credentials)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Cannot get a token to pull %s: %s", r.ref, resp.Status)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", errors.Wrap(err, "Invalid token")
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	return token.AccessToken, nil
+}
+
+// cut slices s around the first instance of sep, like strings.Cut of Go 1.18
+func cut(s, sep string) (string, string, bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// parseChallenge splits a WWW-Authenticate header like
+// Bearer realm="https://quay.io/v2/auth",service="quay.io"
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := cut(strings.TrimSpace(challenge), " ")
+	params := make(map[string]string)
+	for rest != "" {
+		var key, value string
+		key, rest, _ = cut(strings.TrimLeft(rest, ", "), "=")
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = cut(rest, ",")
+		}
+		if key = strings.TrimSpace(key); key != "" {
+			params[strings.ToLower(key)] = value
+		}
+	}
+	return scheme, params
+}
+
+// registryCredentials returns the base64 encoded user:password of the
+// registry from the authentication files of podman and docker
+func registryCredentials(registry string) string {
+	for _, path := range authFiles() {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var authFile struct {
+			Auths map[string]struct {
+				Auth string `json:"auth"`
+			} `json:"auths"`
+		}
+		if err := json.Unmarshal(data, &authFile); err != nil {
+			logging.Debugf("Cannot parse %s: %v", path, err)
+			continue
+		}
+		keys := []string{registry, "https://" + registry}
+		if registry == defaultRegistry {
+			keys = append(keys, "https://index.docker.io/v1/")
+		}
+		for _, key := range keys {
+			auth, ok := authFile.Auths[key]
+			if !ok || auth.Auth == "" {
+				continue
+			}
+			if _, err := base64.StdEncoding.DecodeString(auth.Auth); err != nil {
+				continue
+			}
+			logging.Debugf("Using the credentials of %s from %s", registry, path)
+			return auth.Auth
+		}
+	}
+	return ""
+}
+
+func authFiles() []string {
+	var paths []string
+	if path := os.Getenv("REGISTRY_AUTH_FILE"); path != "" {
+		paths = append(paths, path)
+	}
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		paths = append(paths, filepath.Join(runtimeDir, "containers", "auth.json"))
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		paths = append(paths,
+			filepath.Join(homeDir, ".config", "containers", "auth.json"),
+			filepath.Join(homeDir, ".docker", "config.json"))
+	}
+	return paths
+}
@@ -0,0 +1,47 @@
+package download
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOCIReference(t *testing.T) {
+	ref, err := ParseOCIReference("docker://quay.io/crcont/bundle:4.10.3")
+	assert.NoError(t, err)
+	assert.Equal(t, &OCIReference{Registry: "quay.io", Repository: "crcont/bundle", Reference: "4.10.3"}, ref)
+	assert.Equal(t, "https://quay.io/v2/crcont/bundle/manifests/4.10.3", ref.url("manifests", ref.Reference))
+
+	ref, err = ParseOCIReference("docker://localhost:5000/bundle")
+	assert.NoError(t, err)
+	assert.Equal(t, &OCIReference{Registry: "localhost:5000", Repository: "bundle", Reference: "latest"}, ref)
+
+	ref, err = ParseOCIReference("docker://crcont/bundle@sha256:0123")
+	assert.NoError(t, err)
+	assert.Equal(t, &OCIReference{Registry: "docker.io", Repository: "crcont/bundle", Reference: "sha256:0123"}, ref)
+	assert.Equal(t, "docker://docker.io/crcont/bundle@sha256:0123", ref.String())
+	assert.Equal(t, "https://registry-1.docker.io/v2/crcont/bundle/blobs/sha256:0123", ref.url("blobs", ref.Reference))
+
+	ref, err = ParseOCIReference("docker://bundle")
+	assert.NoError(t, err)
+	assert.Equal(t, "library/bundle", ref.Repository)
+
+	_, err = ParseOCIReference("quay.io/crcont/bundle")
+	assert.Error(t, err)
+	_, err = ParseOCIReference("docker://quay.io/crcont/bundle:")
+	assert.Error(t, err)
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://quay.io/v2/auth",service="quay.io",scope="repository:crcont/bundle:pull"`)
+	assert.Equal(t, "Bearer", scheme)
+	assert.Equal(t, map[string]string{
+		"realm":   "https://quay.io/v2/auth",
+		"service": "quay.io",
+		"scope":   "repository:crcont/bundle:pull",
+	}, params)
+
+	scheme, params = parseChallenge(`Basic realm="registry"`)
+	assert.Equal(t, "Basic", scheme)
+	assert.Equal(t, map[string]string{"realm": "registry"}, params)
+}
@@ -18,6 +18,7 @@ type Driver struct {
 	VirtualSwitch        string
 	MacAddress           string
 	DisableDynamicMemory bool
+	NestedVirtualization bool
 }
 
 const (
@@ -69,6 +70,13 @@ func (d *Driver) UpdateConfigRaw(rawConfig []byte) error {
 			return err
 		}
 	}
+	if newDriver.NestedVirtualization != d.NestedVirtualization {
+		log.Debugf("Updating nested virtualization from %t to %t", d.NestedVirtualization, newDriver.NestedVirtualization)
+		if err := exposeVirtualizationExtensions(d.MachineName, newDriver.NestedVirtualization); err != nil {
+			log.Warnf("Failed to set nested virtualization to %t", newDriver.NestedVirtualization)
+			return err
+		}
+	}
 	if newDriver.DiskCapacity != d.DiskCapacity {
 		log.Debugf("Resizing disk from %d bytes to %d bytes", d.DiskCapacity, newDriver.DiskCapacity)
 		err := cmd("Hyper-V\\Resize-VHD", "-Path", quote(d.getDiskPath()), "-SizeBytes", fmt.Sprintf("%d", newDriver.DiskCapacity))
@@ -191,6 +199,12 @@ func (d *Driver) Create() error {
 		}
 	}
 
+	if d.NestedVirtualization {
+		if err := exposeVirtualizationExtensions(d.MachineName, true); err != nil {
+			return err
+		}
+	}
+
 	if d.VirtualSwitch != "" && d.MacAddress != "" {
 		if err := cmd("Hyper-V\\Set-VMNetworkAdapter",
 			"-VMName", d.MachineName,
@@ -214,6 +228,18 @@ func (d *Driver) Create() error {
 		"-Path", quote(d.getDiskPath()))
 }
 
+// exposeVirtualizationExtensions enables nested virtualization, the VM must be
+// off and must not use dynamic memory
+func exposeVirtualizationExtensions(machineName string, expose bool) error {
+	value := "$false"
+	if expose {
+		value = "$true"
+	}
+	return cmd("Hyper-V\\Set-VMProcessor",
+		machineName,
+		"-ExposeVirtualizationExtensions", value)
+}
+
 func (d *Driver) chooseVirtualSwitch() (string, error) {
 	if d.VirtualSwitch == "" {
 		return "", errors.New("no virtual switch given")
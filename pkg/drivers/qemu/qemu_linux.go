@@ -0,0 +1,289 @@
+package qemu
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	log "github.com/code-ready/crc/pkg/crc/logging"
+	crcos "github.com/code-ready/crc/pkg/os"
+	"github.com/code-ready/machine/libmachine/drivers"
+	"github.com/code-ready/machine/libmachine/state"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// DriverName is the name of the driver running QEMU directly, without
+// libvirt
+const DriverName = "qemu"
+
+const (
+	qemuBinary    = "qemu-system-x86_64"
+	qemuImgBinary = "qemu-img"
+
+	defaultMemory   = 8192
+	defaultCPU      = 4
+	defaultVSockCID = 3
+)
+
+// Driver runs the instance in a QEMU process started by crc and controlled
+// over QMP. The instance has no network interface, it is only reachable over
+// vsock with the user network mode.
+type Driver struct {
+	*drivers.VMDriver
+	VSockCID uint32
+	// PCIDevices are the addresses of the host devices assigned to the
+	// instance with vfio-pci
+	PCIDevices []string
+}
+
+// NewDriver creates a new QEMU driver with default settings.
+func NewDriver(hostName, storePath string) *Driver {
+	return &Driver{
+		VMDriver: &drivers.VMDriver{
+			BaseDriver: &drivers.BaseDriver{
+				MachineName: hostName,
+				StorePath:   storePath,
+			},
+			Memory: defaultMemory,
+			CPU:    defaultCPU,
+		},
+		VSockCID: defaultVSockCID,
+	}
+}
+
+// DriverName returns the name of the driver
+func (d *Driver) DriverName() string {
+	return DriverName
+}
+
+func (d *Driver) DriverVersion() string {
+	out, err := exec.Command(qemuBinary, "--version").Output()
+	if err != nil {
+		return "unknown"
+	}
+	return strings.TrimPrefix(strings.SplitN(string(out), "\n", 2)[0], "QEMU emulator version ")
+}
+
+func (d *Driver) getDiskPath() string {
+	return d.ResolveStorePath(fmt.Sprintf("%s.%s", d.MachineName, d.ImageFormat))
+}
+
+func (d *Driver) pidFilePath() string {
+	return d.ResolveStorePath("qemu.pid")
+}
+
+func (d *Driver) qmpSocketPath() string {
+	return d.ResolveStorePath("qmp.sock")
+}
+
+func (d *Driver) consoleLogPath() string {
+	return d.ResolveStorePath("console.log")
+}
+
+// PreCreateCheck checks that QEMU is installed and that KVM can be used
+func (d *Driver) PreCreateCheck() error {
+	for _, binary := range []string{qemuBinary, qemuImgBinary} {
+		if _, err := exec.LookPath(binary); err != nil {
+			return fmt.Errorf("%s is not installed: %v", binary, err)
+		}
+	}
+	if err := unix.Access("/dev/kvm", unix.R_OK|unix.W_OK); err != nil {
+		return fmt.Errorf("Cannot use /dev/kvm: %v", err)
+	}
+	return nil
+}
+
+// Create copies the disk image of the bundle and grows it to the disk
+// capacity, the instance is started separately
+func (d *Driver) Create() error {
+	if d.ImageFormat != "qcow2" {
+		return fmt.Errorf("Unsupported disk image format %s", d.ImageFormat)
+	}
+	if err := os.MkdirAll(d.ResolveStorePath("."), 0750); err != nil {
+		return err
+	}
+	log.Debugf("Copying %s to %s", d.ImageSourcePath, d.getDiskPath())
+	if err := crcos.CopyFileContents(d.ImageSourcePath, d.getDiskPath(), 0600); err != nil {
+		return errors.Wrap(err, "Cannot copy the disk image")
+	}
+	if d.DiskCapacity != 0 {
+		return d.resizeDisk(d.DiskCapacity)
+	}
+	return nil
+}
+
+func (d *Driver) resizeDisk(capacity uint64) error {
+	if _, stderr, err := crcos.RunWithDefaultLocale(qemuImgBinary, "resize", "-f", d.ImageFormat, d.getDiskPath(), strconv.FormatUint(capacity, 10)); err != nil {
+		return fmt.Errorf("Failed to resize the disk image: %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// qemuArgs returns the command line of the QEMU process of the instance,
+// its configuration is only read when it starts
+func (d *Driver) qemuArgs() []string {
+	args := []string{
+		"-name", d.MachineName,
+		"-machine", "q35,accel=kvm",
+		// the virtualization extensions are only exposed when the kvm
+		// module allows nested virtualization
+		"-cpu", "host",
+		"-smp", strconv.Itoa(d.CPU),
+		"-m", strconv.Itoa(d.Memory),
+		"-drive", fmt.Sprintf("if=virtio,file=%s,format=%s,discard=unmap", d.getDiskPath(), d.ImageFormat),
+		"-device", fmt.Sprintf("vhost-vsock-pci,guest-cid=%d", d.VSockCID),
+		"-nic", "none",
+	}
+	for _, address := range d.PCIDevices {
+		args = append(args, "-device", fmt.Sprintf("vfio-pci,host=%s", address))
+	}
+	return append(args,
+		"-display", "none",
+		"-serial", fmt.Sprintf("file:%s", d.consoleLogPath()),
+		"-qmp", fmt.Sprintf("unix:%s,server=on,wait=off", d.qmpSocketPath()),
+		"-pidfile", d.pidFilePath(),
+		"-daemonize",
+	)
+}
+
+// Start starts the QEMU process, it keeps running in the background
+func (d *Driver) Start() error {
+	if err := os.Remove(d.qmpSocketPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	log.Debugf("Running %s %s", qemuBinary, strings.Join(d.qemuArgs(), " "))
+	if _, stderr, err := crcos.RunWithDefaultLocale(qemuBinary, d.qemuArgs()...); err != nil {
+		return fmt.Errorf("Failed to start QEMU: %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// pid returns the process ID of the QEMU process of the instance, it is 0
+// when the instance is not running
+func (d *Driver) pid() (int, error) {
+	data, err := ioutil.ReadFile(d.pidFilePath())
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("Invalid QEMU pid file %s", d.pidFilePath())
+	}
+	// the pid file remains when QEMU is killed, and its pid can be reused
+	comm, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/comm", pid))
+	if err != nil || !strings.HasPrefix(string(comm), "qemu") {
+		return 0, nil
+	}
+	return pid, nil
+}
+
+func (d *Driver) GetState() (state.State, error) {
+	pid, err := d.pid()
+	if err != nil {
+		return state.Error, err
+	}
+	if pid == 0 {
+		return state.Stopped, nil
+	}
+	qmp, err := dialQMP(d.qmpSocketPath())
+	if err != nil {
+		// QEMU is starting and did not create its socket yet
+		log.Debugf("Cannot connect to QMP: %v", err)
+		return state.Running, nil
+	}
+	defer qmp.Close()
+	status, err := qmp.status()
+	if err != nil {
+		return state.Error, err
+	}
+	switch status {
+	case "running", "prelaunch", "inmigrate", "postmigrate", "finish-migrate":
+		return state.Running, nil
+	case "paused", "suspended":
+		return state.Paused, nil
+	case "shutdown":
+		return state.Stopping, nil
+	default:
+		return state.Error, fmt.Errorf("unexpected QEMU state %s", status)
+	}
+}
+
+// Stop powers the instance off from the inside, QEMU exits once the
+// instance is shut down
+func (d *Driver) Stop() error {
+	qmp, err := dialQMP(d.qmpSocketPath())
+	if err != nil {
+		return errors.Wrap(err, "Cannot connect to QEMU")
+	}
+	defer qmp.Close()
+	_, err = qmp.execute("system_powerdown")
+	return err
+}
+
+// Kill terminates QEMU, without shutting the instance down
+func (d *Driver) Kill() error {
+	pid, err := d.pid()
+	if err != nil || pid == 0 {
+		return err
+	}
+	if qmp, err := dialQMP(d.qmpSocketPath()); err == nil {
+		defer qmp.Close()
+		if _, err := qmp.execute("quit"); err == nil {
+			return nil
+		}
+	}
+	return unix.Kill(pid, unix.SIGKILL)
+}
+
+// Remove kills the instance and removes its disk
+func (d *Driver) Remove() error {
+	s, err := d.GetState()
+	if err != nil {
+		return err
+	}
+	if s != state.Stopped {
+		if err := d.Kill(); err != nil {
+			return err
+		}
+	}
+	for _, path := range []string{d.getDiskPath(), d.pidFilePath(), d.qmpSocketPath(), d.consoleLogPath()} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Driver) GetIP() (string, error) {
+	// only the user network mode is supported, the instance is reached
+	// through the forwarded ports of the host
+	return "127.0.0.1", nil
+}
+
+// UpdateConfigRaw updates the configuration of the stopped instance, it is
+// applied when QEMU starts, except the disk capacity
+func (d *Driver) UpdateConfigRaw(rawConfig []byte) error {
+	var newDriver Driver
+	if err := json.Unmarshal(rawConfig, &newDriver); err != nil {
+		return err
+	}
+	if newDriver.DiskCapacity != d.DiskCapacity {
+		if newDriver.DiskCapacity < d.DiskCapacity {
+			return fmt.Errorf("The disk cannot shrink from %d bytes to %d bytes", d.DiskCapacity, newDriver.DiskCapacity)
+		}
+		log.Debugf("Resizing disk from %d bytes to %d bytes", d.DiskCapacity, newDriver.DiskCapacity)
+		if err := d.resizeDisk(newDriver.DiskCapacity); err != nil {
+			return err
+		}
+	}
+	*d = newDriver
+	return nil
+}
@@ -0,0 +1,33 @@
+package qemu
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQemuArgs(t *testing.T) {
+	driver := NewDriver("crc", "/home/user/.crc")
+	driver.ImageFormat = "qcow2"
+	driver.CPU = 6
+	driver.Memory = 12288
+	driver.PCIDevices = []string{"0000:01:00.0", "0000:01:00.1"}
+
+	assert.Equal(t, "-name crc -machine q35,accel=kvm -cpu host -smp 6 -m 12288 "+
+		"-drive if=virtio,file=/home/user/.crc/machines/crc/crc.qcow2,format=qcow2,discard=unmap "+
+		"-device vhost-vsock-pci,guest-cid=3 -nic none "+
+		"-device vfio-pci,host=0000:01:00.0 -device vfio-pci,host=0000:01:00.1 "+
+		"-display none -serial file:/home/user/.crc/machines/crc/console.log "+
+		"-qmp unix:/home/user/.crc/machines/crc/qmp.sock,server=on,wait=off "+
+		"-pidfile /home/user/.crc/machines/crc/qemu.pid -daemonize", strings.Join(driver.qemuArgs(), " "))
+}
+
+func TestUpdateConfigRawCannotShrinkDisk(t *testing.T) {
+	driver := NewDriver("crc", "/home/user/.crc")
+	driver.DiskCapacity = 64 << 30
+
+	assert.Error(t, driver.UpdateConfigRaw([]byte(`{"DiskCapacity": 34359738368}`)))
+	assert.NoError(t, driver.UpdateConfigRaw([]byte(`{"DiskCapacity": 68719476736, "CPU": 8}`)))
+	assert.Equal(t, 8, driver.CPU)
+}
@@ -0,0 +1,109 @@
+package qemu
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const qmpTimeout = 10 * time.Second
+
+// qmpClient sends commands to QEMU over its QEMU Machine Protocol socket
+type qmpClient struct {
+	conn    net.Conn
+	decoder *json.Decoder
+}
+
+type qmpError struct {
+	Class string `json:"class"`
+	Desc  string `json:"desc"`
+}
+
+type qmpMessage struct {
+	QMP    json.RawMessage `json:"QMP"`
+	Return json.RawMessage `json:"return"`
+	Error  *qmpError       `json:"error"`
+	Event  string          `json:"event"`
+}
+
+func dialQMP(socketPath string) (*qmpClient, error) {
+	conn, err := net.DialTimeout("unix", socketPath, qmpTimeout)
+	if err != nil {
+		return nil, err
+	}
+	client, err := newQMPClient(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// newQMPClient reads the greeting of QEMU and leaves the capabilities
+// negotiation mode, the commands are only accepted after it
+func newQMPClient(conn net.Conn) (*qmpClient, error) {
+	client := &qmpClient{
+		conn:    conn,
+		decoder: json.NewDecoder(conn),
+	}
+	if err := conn.SetDeadline(time.Now().Add(qmpTimeout)); err != nil {
+		return nil, err
+	}
+	var greeting qmpMessage
+	if err := client.decoder.Decode(&greeting); err != nil {
+		return nil, errors.Wrap(err, "Cannot read the QMP greeting")
+	}
+	if greeting.QMP == nil {
+		return nil, errors.New("Unexpected QMP greeting")
+	}
+	if _, err := client.execute("qmp_capabilities"); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// execute runs command and returns its result, the asynchronous events sent
+// by QEMU meanwhile are ignored
+func (c *qmpClient) execute(command string) (json.RawMessage, error) {
+	if err := c.conn.SetDeadline(time.Now().Add(qmpTimeout)); err != nil {
+		return nil, err
+	}
+	if err := json.NewEncoder(c.conn).Encode(map[string]string{"execute": command}); err != nil {
+		return nil, errors.Wrapf(err, "Cannot send the QMP command %s", command)
+	}
+	for {
+		var message qmpMessage
+		if err := c.decoder.Decode(&message); err != nil {
+			return nil, errors.Wrapf(err, "Cannot read the result of the QMP command %s", command)
+		}
+		switch {
+		case message.Error != nil:
+			return nil, fmt.Errorf("QMP command %s failed: %s", command, message.Error.Desc)
+		case message.Return != nil:
+			return message.Return, nil
+		}
+	}
+}
+
+// status returns the run state of the virtual machine, like running,
+// paused or shutdown
+func (c *qmpClient) status() (string, error) {
+	result, err := c.execute("query-status")
+	if err != nil {
+		return "", err
+	}
+	var status struct {
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(result, &status); err != nil {
+		return "", errors.Wrap(err, "Unexpected result of the QMP command query-status")
+	}
+	return status.Status, nil
+}
+
+func (c *qmpClient) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,65 @@
+package qemu
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeQMPServer answers the commands with the responses, and sends an event
+// before each of them
+func fakeQMPServer(t *testing.T, conn net.Conn, responses map[string]string) {
+	defer conn.Close()
+	fmt.Fprintln(conn, `{"QMP": {"version": {"qemu": {"micro": 0, "minor": 2, "major": 6}}, "capabilities": []}}`)
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var command struct {
+			Execute string `json:"execute"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &command); err != nil {
+			t.Errorf("invalid command: %v", err)
+			return
+		}
+		response, ok := responses[command.Execute]
+		if !ok {
+			response = `{"error": {"class": "CommandNotFound", "desc": "The command does not exist"}}`
+		}
+		fmt.Fprintln(conn, `{"timestamp": {"seconds": 1, "microseconds": 2}, "event": "RTC_CHANGE", "data": {"offset": 0}}`)
+		fmt.Fprintln(conn, response)
+	}
+}
+
+func TestQMPStatus(t *testing.T) {
+	client, server := net.Pipe()
+	go fakeQMPServer(t, server, map[string]string{
+		"qmp_capabilities": `{"return": {}}`,
+		"query-status":     `{"return": {"status": "running", "singlestep": false, "running": true}}`,
+	})
+
+	qmp, err := newQMPClient(client)
+	require.NoError(t, err)
+	defer qmp.Close()
+
+	status, err := qmp.status()
+	assert.NoError(t, err)
+	assert.Equal(t, "running", status)
+
+	_, err = qmp.execute("foo")
+	assert.EqualError(t, err, "QMP command foo failed: The command does not exist")
+}
+
+func TestQMPInvalidGreeting(t *testing.T) {
+	client, server := net.Pipe()
+	go func() {
+		defer server.Close()
+		fmt.Fprintln(server, `{"return": {}}`)
+	}()
+
+	_, err := newQMPClient(client)
+	assert.EqualError(t, err, "Unexpected QMP greeting")
+}
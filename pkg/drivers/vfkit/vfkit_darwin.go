@@ -0,0 +1,353 @@
+package vfkit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/code-ready/crc/pkg/crc/logging"
+	crcos "github.com/code-ready/crc/pkg/os"
+	"github.com/code-ready/machine/libmachine/drivers"
+	"github.com/code-ready/machine/libmachine/state"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+// DriverName is the name of the driver running the instance with
+// Virtualization.framework through vfkit
+const DriverName = "vfkit"
+
+const (
+	defaultMemory = 8192
+	defaultCPU    = 4
+
+	// the guest connects to this vsock port of the host to reach the
+	// virtual network of the crc daemon
+	vsockPort = 1024
+
+	// RosettaMountTag is the virtiofs tag of the directory of the Rosetta
+	// runtime in the instance
+	RosettaMountTag = "rosetta"
+
+	restTimeout = 10 * time.Second
+)
+
+// Driver runs the instance in a vfkit process started by crc and controlled
+// over its REST API. The instance has no network interface, it is only
+// reachable over vsock with the user network mode.
+type Driver struct {
+	*drivers.VMDriver
+	VfkitPath   string
+	VmlinuzPath string
+	InitrdPath  string
+	Cmdline     string
+	// VsockPath is the unix socket vfkit connects to when the instance
+	// connects to the vsock port of the host
+	VsockPath string
+	// Rosetta exposes the Rosetta runtime of macOS to the instance, to run
+	// x86_64 executables
+	Rosetta bool
+}
+
+// NewDriver creates a new vfkit driver with default settings.
+func NewDriver(hostName, storePath string) *Driver {
+	return &Driver{
+		VMDriver: &drivers.VMDriver{
+			BaseDriver: &drivers.BaseDriver{
+				MachineName: hostName,
+				StorePath:   storePath,
+			},
+			Memory: defaultMemory,
+			CPU:    defaultCPU,
+		},
+	}
+}
+
+// DriverName returns the name of the driver
+func (d *Driver) DriverName() string {
+	return DriverName
+}
+
+func (d *Driver) DriverVersion() string {
+	out, err := exec.Command(d.VfkitPath, "--version").Output()
+	if err != nil {
+		return "unknown"
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return "unknown"
+	}
+	return strings.TrimPrefix(fields[len(fields)-1], "v")
+}
+
+func (d *Driver) getDiskPath() string {
+	return d.ResolveStorePath(fmt.Sprintf("%s.%s", d.MachineName, d.ImageFormat))
+}
+
+func (d *Driver) pidFilePath() string {
+	return d.ResolveStorePath("vfkit.pid")
+}
+
+func (d *Driver) restSocketPath() string {
+	return d.ResolveStorePath("vfkit-rest.sock")
+}
+
+func (d *Driver) consoleLogPath() string {
+	return d.ResolveStorePath("console.log")
+}
+
+func (d *Driver) vfkitLogPath() string {
+	return d.ResolveStorePath("vfkit.log")
+}
+
+// PreCreateCheck checks that vfkit is installed
+func (d *Driver) PreCreateCheck() error {
+	if err := unix.Access(d.VfkitPath, unix.X_OK); err != nil {
+		return fmt.Errorf("%s is not executable: %v", d.VfkitPath, err)
+	}
+	return nil
+}
+
+// Create copies the disk image of the bundle and grows it to the disk
+// capacity, the instance is started separately
+func (d *Driver) Create() error {
+	// Virtualization.framework only supports raw disk images
+	if d.ImageFormat != "raw" {
+		return fmt.Errorf("Unsupported disk image format %s, the vfkit driver needs a raw disk image", d.ImageFormat)
+	}
+	if err := os.MkdirAll(d.ResolveStorePath("."), 0750); err != nil {
+		return err
+	}
+	log.Debugf("Copying %s to %s", d.ImageSourcePath, d.getDiskPath())
+	if err := crcos.CopyFileContents(d.ImageSourcePath, d.getDiskPath(), 0600); err != nil {
+		return errors.Wrap(err, "Cannot copy the disk image")
+	}
+	if d.DiskCapacity != 0 {
+		return d.resizeDisk(d.DiskCapacity)
+	}
+	return nil
+}
+
+// resizeDisk grows the raw disk image, the file is sparse so the new space
+// is only allocated when the instance writes to it
+func (d *Driver) resizeDisk(capacity uint64) error {
+	if err := os.Truncate(d.getDiskPath(), int64(capacity)); err != nil {
+		return errors.Wrap(err, "Failed to resize the disk image")
+	}
+	return nil
+}
+
+// vfkitArgs returns the command line of the vfkit process of the instance,
+// its configuration is only read when it starts
+func (d *Driver) vfkitArgs() []string {
+	args := []string{
+		"--cpus", strconv.Itoa(d.CPU),
+		"--memory", strconv.Itoa(d.Memory),
+		"--bootloader", fmt.Sprintf("linux,kernel=%s,initrd=%s,cmdline=\"%s\"", d.VmlinuzPath, d.InitrdPath, d.Cmdline),
+		"--device", fmt.Sprintf("virtio-blk,path=%s", d.getDiskPath()),
+		"--device", fmt.Sprintf("virtio-vsock,port=%d,socketURL=%s", vsockPort, d.VsockPath),
+		"--device", fmt.Sprintf("virtio-serial,logFilePath=%s", d.consoleLogPath()),
+	}
+	if d.Rosetta {
+		args = append(args, "--device", fmt.Sprintf("rosetta,mountTag=%s", RosettaMountTag))
+	}
+	return append(args, "--restful-uri", fmt.Sprintf("unix://%s", d.restSocketPath()))
+}
+
+// Start starts the vfkit process, it keeps running in the background
+func (d *Driver) Start() error {
+	if err := os.Remove(d.restSocketPath()); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	logFile, err := os.OpenFile(d.vfkitLogPath(), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	log.Debugf("Running %s %s", d.VfkitPath, strings.Join(d.vfkitArgs(), " "))
+	cmd := exec.Command(d.VfkitPath, d.vfkitArgs()...) // #nosec G204
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	// vfkit must not be killed with crc when the user hits Ctrl+C
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("Failed to start vfkit: %v", err)
+	}
+	if err := ioutil.WriteFile(d.pidFilePath(), []byte(strconv.Itoa(cmd.Process.Pid)), 0600); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
+
+// pid returns the process ID of the vfkit process of the instance, it is 0
+// when the instance is not running
+func (d *Driver) pid() (int, error) {
+	data, err := ioutil.ReadFile(d.pidFilePath())
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		return 0, fmt.Errorf("Invalid vfkit pid file %s", d.pidFilePath())
+	}
+	// the pid file remains when vfkit is killed, and its pid can be reused
+	comm, _, err := crcos.RunWithDefaultLocale("ps", "-o", "comm=", "-p", strconv.Itoa(pid))
+	if err != nil || !strings.HasSuffix(strings.TrimSpace(comm), "vfkit") {
+		return 0, nil
+	}
+	return pid, nil
+}
+
+// restClient sends requests to the REST API of vfkit over its unix socket
+func (d *Driver) restClient() *http.Client {
+	return &http.Client{
+		Timeout: restTimeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, "unix", d.restSocketPath())
+			},
+		},
+	}
+}
+
+type vmState struct {
+	State string `json:"state"`
+}
+
+func (d *Driver) getVMState() (string, error) {
+	resp, err := d.restClient().Get("http://vfkit/vm/state")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Unexpected status of the vfkit REST API: %s", resp.Status)
+	}
+	var current vmState
+	if err := json.NewDecoder(resp.Body).Decode(&current); err != nil {
+		return "", errors.Wrap(err, "Unexpected state of the vfkit REST API")
+	}
+	return current.State, nil
+}
+
+func (d *Driver) setVMState(newState string) error {
+	body, err := json.Marshal(vmState{State: newState})
+	if err != nil {
+		return err
+	}
+	resp, err := d.restClient().Post("http://vfkit/vm/state", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("Failed to change the state of the instance to %s: %s", newState, resp.Status)
+	}
+	return nil
+}
+
+func (d *Driver) GetState() (state.State, error) {
+	pid, err := d.pid()
+	if err != nil {
+		return state.Error, err
+	}
+	if pid == 0 {
+		return state.Stopped, nil
+	}
+	vfkitState, err := d.getVMState()
+	if err != nil {
+		// vfkit is starting and did not create its socket yet
+		log.Debugf("Cannot get the state from vfkit: %v", err)
+		return state.Running, nil
+	}
+	switch vfkitState {
+	case "VirtualMachineStateRunning", "VirtualMachineStateStarting", "VirtualMachineStateResuming":
+		return state.Running, nil
+	case "VirtualMachineStatePaused", "VirtualMachineStatePausing":
+		return state.Paused, nil
+	case "VirtualMachineStateStopping":
+		return state.Stopping, nil
+	case "VirtualMachineStateStopped":
+		return state.Stopped, nil
+	default:
+		return state.Error, fmt.Errorf("unexpected vfkit state %s", vfkitState)
+	}
+}
+
+// Stop requests the instance to power off, vfkit exits once the instance is
+// shut down
+func (d *Driver) Stop() error {
+	return d.setVMState("Stop")
+}
+
+// Kill terminates vfkit, without shutting the instance down
+func (d *Driver) Kill() error {
+	pid, err := d.pid()
+	if err != nil || pid == 0 {
+		return err
+	}
+	if err := d.setVMState("HardStop"); err == nil {
+		return nil
+	}
+	return unix.Kill(pid, unix.SIGKILL)
+}
+
+// Remove kills the instance and removes its disk
+func (d *Driver) Remove() error {
+	s, err := d.GetState()
+	if err != nil {
+		return err
+	}
+	if s != state.Stopped {
+		if err := d.Kill(); err != nil {
+			return err
+		}
+	}
+	for _, path := range []string{d.getDiskPath(), d.pidFilePath(), d.restSocketPath(), d.consoleLogPath(), d.vfkitLogPath()} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Driver) GetIP() (string, error) {
+	// only the user network mode is supported, the instance is reached
+	// through the forwarded ports of the host
+	return "127.0.0.1", nil
+}
+
+// UpdateConfigRaw updates the configuration of the stopped instance, it is
+// applied when vfkit starts, except the disk capacity
+func (d *Driver) UpdateConfigRaw(rawConfig []byte) error {
+	var newDriver Driver
+	if err := json.Unmarshal(rawConfig, &newDriver); err != nil {
+		return err
+	}
+	if newDriver.DiskCapacity != d.DiskCapacity {
+		if newDriver.DiskCapacity < d.DiskCapacity {
+			return fmt.Errorf("The disk cannot shrink from %d bytes to %d bytes", d.DiskCapacity, newDriver.DiskCapacity)
+		}
+		log.Debugf("Resizing disk from %d bytes to %d bytes", d.DiskCapacity, newDriver.DiskCapacity)
+		if err := d.resizeDisk(newDriver.DiskCapacity); err != nil {
+			return err
+		}
+	}
+	*d = newDriver
+	return nil
+}
@@ -0,0 +1,35 @@
+package vfkit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVfkitArgs(t *testing.T) {
+	driver := NewDriver("crc", "/Users/user/.crc")
+	driver.ImageFormat = "raw"
+	driver.CPU = 6
+	driver.Memory = 12288
+	driver.VmlinuzPath = "/bundle/vmlinuz"
+	driver.InitrdPath = "/bundle/initramfs.img"
+	driver.Cmdline = "console=hvc0 root=/dev/vda4"
+	driver.VsockPath = "/Users/user/.crc/tap.sock"
+	driver.Rosetta = true
+
+	assert.Equal(t, "--cpus 6 --memory 12288 "+
+		"--bootloader linux,kernel=/bundle/vmlinuz,initrd=/bundle/initramfs.img,cmdline=\"console=hvc0 root=/dev/vda4\" "+
+		"--device virtio-blk,path=/Users/user/.crc/machines/crc/crc.raw "+
+		"--device virtio-vsock,port=1024,socketURL=/Users/user/.crc/tap.sock "+
+		"--device virtio-serial,logFilePath=/Users/user/.crc/machines/crc/console.log "+
+		"--device rosetta,mountTag=rosetta "+
+		"--restful-uri unix:///Users/user/.crc/machines/crc/vfkit-rest.sock", strings.Join(driver.vfkitArgs(), " "))
+}
+
+func TestCreateNeedsRawDiskImage(t *testing.T) {
+	driver := NewDriver("crc", t.TempDir())
+	driver.ImageFormat = "qcow2"
+
+	assert.EqualError(t, driver.Create(), "Unsupported disk image format qcow2, the vfkit driver needs a raw disk image")
+}
@@ -0,0 +1,238 @@
+package wsl
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	log "github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/os/windows/wsl"
+	"github.com/code-ready/machine/libmachine/drivers"
+	"github.com/code-ready/machine/libmachine/state"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/windows"
+)
+
+// DriverName is the name of the driver running the instance as a WSL2
+// distribution
+const DriverName = "wsl2"
+
+const (
+	defaultMemory = 8192
+	defaultCPU    = 4
+)
+
+// wslConf is the WSL configuration of the distribution, systemd boots the
+// instance as with the other drivers. The Windows drives are mounted at
+// /mnt/<drive> where the shared directories are expected.
+const wslConf = `[boot]
+systemd=true
+
+[automount]
+enabled=true
+root=/mnt/
+
+[interop]
+appendWindowsPath=false
+`
+
+// Driver imports the root filesystem of the bundle as a WSL2 distribution.
+// The distribution runs in the utility virtual machine of WSL2 with its
+// kernel, its memory and its processors, they are not set per distribution.
+type Driver struct {
+	*drivers.VMDriver
+}
+
+// NewDriver creates a new WSL2 driver with default settings.
+func NewDriver(hostName, storePath string) *Driver {
+	return &Driver{
+		VMDriver: &drivers.VMDriver{
+			BaseDriver: &drivers.BaseDriver{
+				MachineName: hostName,
+				StorePath:   storePath,
+			},
+			Memory: defaultMemory,
+			CPU:    defaultCPU,
+		},
+	}
+}
+
+// DriverName returns the name of the driver
+func (d *Driver) DriverName() string {
+	return DriverName
+}
+
+func (d *Driver) DriverVersion() string {
+	stdout, _, err := wsl.Execute("--version")
+	if err != nil {
+		return "unknown"
+	}
+	return parseVersion(stdout)
+}
+
+// parseVersion returns the version of WSL from the output of wsl --version,
+// its first line is 'WSL version: 1.0.3.0'
+func parseVersion(output string) string {
+	firstLine := strings.SplitN(strings.TrimSpace(output), "\n", 2)[0]
+	fields := strings.Fields(firstLine)
+	if len(fields) == 0 {
+		return "unknown"
+	}
+	return fields[len(fields)-1]
+}
+
+// PreCreateCheck checks that WSL can run the distribution, the WSL version
+// of the Microsoft Store is needed for systemd
+func (d *Driver) PreCreateCheck() error {
+	if _, _, err := wsl.Execute("--version"); err != nil {
+		return errors.Wrap(err, "WSL is not installed or too old, run 'wsl --update'")
+	}
+	registered, err := wsl.IsRegistered(d.MachineName)
+	if err != nil {
+		return err
+	}
+	if registered {
+		return fmt.Errorf("A WSL distribution named %s already exists", d.MachineName)
+	}
+	return nil
+}
+
+// Create imports the root filesystem of the bundle as a WSL2 distribution
+// stored in the directory of the machine, the instance is started
+// separately
+func (d *Driver) Create() error {
+	if d.ImageFormat != "tar" {
+		return fmt.Errorf("Unsupported disk image format %s, the wsl2 driver needs a root filesystem archive", d.ImageFormat)
+	}
+	if err := os.MkdirAll(d.ResolveStorePath("."), 0750); err != nil {
+		return err
+	}
+	log.Debugf("Importing %s as the %s WSL distribution", d.ImageSourcePath, d.MachineName)
+	if _, stderr, err := wsl.Execute("--import", d.MachineName, d.ResolveStorePath("."), d.ImageSourcePath, "--version", "2"); err != nil {
+		return fmt.Errorf("Failed to import the WSL distribution: %v: %s", err, stderr)
+	}
+	// the disk of the distribution grows on demand, WSL gives the space
+	// freed by the instance back to Windows when it is sparse
+	if _, _, err := wsl.Execute("--manage", d.MachineName, "--set-sparse", "true"); err != nil {
+		log.Debugf("Cannot make the disk of the WSL distribution sparse: %v", err)
+	}
+	// wsl.exe mangles the newlines of the arguments of the command
+	encoded := base64.StdEncoding.EncodeToString([]byte(wslConf))
+	if _, stderr, err := d.run("sh", "-c", fmt.Sprintf("echo %s | base64 -d > /etc/wsl.conf", encoded)); err != nil {
+		return fmt.Errorf("Failed to configure the WSL distribution: %v: %s", err, stderr)
+	}
+	// /etc/wsl.conf is read when the distribution starts
+	_, _, err := wsl.Execute("--terminate", d.MachineName)
+	return err
+}
+
+// run runs a command as root in the distribution, this starts it when it is
+// not running
+func (d *Driver) run(args ...string) (string, string, error) {
+	return wsl.Execute(append([]string{"--distribution", d.MachineName, "--user", "root", "--"}, args...)...)
+}
+
+// Start boots the distribution with a process which keeps it running, WSL
+// stops a distribution a few seconds after its last process exits
+func (d *Driver) Start() error {
+	wslPath, err := exec.LookPath("wsl.exe")
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(wslPath, "--distribution", d.MachineName, "--user", "root", "--", "sleep", "infinity") // #nosec G204
+	// the process must not be stopped with crc when the user hits Ctrl+C
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow:    true,
+		CreationFlags: windows.CREATE_NEW_PROCESS_GROUP | windows.DETACHED_PROCESS,
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("Failed to start the WSL distribution: %v", err)
+	}
+	return cmd.Process.Release()
+}
+
+func (d *Driver) GetState() (state.State, error) {
+	running, err := wsl.Distributions(true)
+	if err != nil {
+		return state.Error, err
+	}
+	for _, distribution := range running {
+		if strings.EqualFold(distribution, d.MachineName) {
+			return state.Running, nil
+		}
+	}
+	return state.Stopped, nil
+}
+
+// Stop powers the instance off, systemd stops its services before WSL
+// terminates the distribution
+func (d *Driver) Stop() error {
+	if _, _, err := d.run("systemctl", "poweroff"); err != nil {
+		log.Debugf("Cannot power the instance off: %v", err)
+		return d.Kill()
+	}
+	return nil
+}
+
+// Kill terminates the distribution without shutting the instance down
+func (d *Driver) Kill() error {
+	if _, stderr, err := wsl.Execute("--terminate", d.MachineName); err != nil {
+		return fmt.Errorf("Failed to terminate the WSL distribution: %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// Remove unregisters the distribution, WSL deletes its disk
+func (d *Driver) Remove() error {
+	registered, err := wsl.IsRegistered(d.MachineName)
+	if err != nil {
+		return err
+	}
+	if !registered {
+		return nil
+	}
+	if _, stderr, err := wsl.Execute("--unregister", d.MachineName); err != nil {
+		return fmt.Errorf("Failed to unregister the WSL distribution: %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// GetIP returns the address of the instance in the NAT network of WSL2, it
+// is shared by all the distributions
+func (d *Driver) GetIP() (string, error) {
+	stdout, _, err := d.run("ip", "-4", "-brief", "address", "show", "dev", "eth0")
+	if err != nil {
+		return "", errors.Wrap(err, "Cannot get the address of the WSL distribution")
+	}
+	return parseIP(stdout)
+}
+
+// parseIP returns the address from the output of ip -brief, which is
+// 'eth0 UP 172.22.112.5/20'
+func parseIP(output string) (string, error) {
+	fields := strings.Fields(output)
+	if len(fields) < 3 {
+		return "", fmt.Errorf("The WSL distribution has no IPv4 address: %s", strings.TrimSpace(output))
+	}
+	return strings.SplitN(fields[2], "/", 2)[0], nil
+}
+
+// UpdateConfigRaw updates the configuration of the distribution. The
+// memory, the processors and the disk are managed by WSL for all the
+// distributions, changing them is not supported.
+func (d *Driver) UpdateConfigRaw(rawConfig []byte) error {
+	var newDriver Driver
+	if err := json.Unmarshal(rawConfig, &newDriver); err != nil {
+		return err
+	}
+	if newDriver.Memory != d.Memory || newDriver.CPU != d.CPU || newDriver.DiskCapacity != d.DiskCapacity {
+		log.Debugf("The memory, the processors and the disk of WSL2 are set in %%UserProfile%%\\.wslconfig")
+		return drivers.ErrNotImplemented
+	}
+	*d = newDriver
+	return nil
+}
@@ -0,0 +1,40 @@
+package wsl
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/code-ready/machine/libmachine/drivers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVersion(t *testing.T) {
+	assert.Equal(t, "1.0.3.0", parseVersion("WSL version: 1.0.3.0\r\nKernel version: 5.15.79.1\r\n"))
+	assert.Equal(t, "unknown", parseVersion(""))
+}
+
+func TestParseIP(t *testing.T) {
+	ip, err := parseIP("eth0             UP             172.22.112.5/20 \n")
+	assert.NoError(t, err)
+	assert.Equal(t, "172.22.112.5", ip)
+
+	_, err = parseIP("eth0             DOWN\n")
+	assert.Error(t, err)
+}
+
+func TestCreateNeedsRootFilesystemArchive(t *testing.T) {
+	driver := NewDriver("crc", t.TempDir())
+	driver.ImageFormat = "vhdx"
+
+	assert.EqualError(t, driver.Create(), "Unsupported disk image format vhdx, the wsl2 driver needs a root filesystem archive")
+}
+
+func TestUpdateMemoryNotSupported(t *testing.T) {
+	driver := NewDriver("crc", t.TempDir())
+	updated := NewDriver("crc", t.TempDir())
+	updated.Memory = 16384
+	raw, err := json.Marshal(updated)
+	assert.NoError(t, err)
+
+	assert.Equal(t, drivers.ErrNotImplemented, driver.UpdateConfigRaw(raw))
+}
@@ -22,11 +22,11 @@ import (
 const minSizeForProgressBar = 100_000_000
 
 func UncompressWithFilter(tarball, targetDir string, showProgress bool, fileFilter func(string) bool) ([]string, error) {
-	return uncompress(tarball, targetDir, fileFilter, showProgress && terminal.IsTerminal(int(os.Stdout.Fd())))
+	return uncompress(tarball, targetDir, fileFilter, showProgress && !logging.IsQuiet() && terminal.IsTerminal(int(os.Stdout.Fd())))
 }
 
 func Uncompress(tarball, targetDir string, showProgress bool) ([]string, error) {
-	return uncompress(tarball, targetDir, nil, showProgress && terminal.IsTerminal(int(os.Stdout.Fd())))
+	return uncompress(tarball, targetDir, nil, showProgress && !logging.IsQuiet() && terminal.IsTerminal(int(os.Stdout.Fd())))
 }
 
 func uncompress(tarball, targetDir string, fileFilter func(string) bool, showProgress bool) ([]string, error) {
@@ -66,6 +66,7 @@ func uncompress(tarball, targetDir string, fileFilter func(string) bool, showPro
 		if err != nil {
 			return nil, err
 		}
+		defer reader.Close()
 		return untar(reader, targetDir, fileFilter, showProgress)
 	case filetype.Is(header, "gz"):
 		reader, err := gzip.NewReader(file)
@@ -156,10 +157,14 @@ func uncompressFile(tarReader io.Reader, fileInfo os.FileInfo, path string, show
 	reader, cleanup := progressBarReader(tarReader, fileInfo, showProgress)
 	defer cleanup()
 
-	// copy over contents
+	// copy over contents, the data is written directly to the destination
+	// and the blocks of zeros are left as holes
 	// #nosec G110
-	_, err = io.Copy(file, reader)
-	if err != nil {
+	writer := &sparseWriter{file: file}
+	if _, err := io.Copy(writer, reader); err != nil {
+		return err
+	}
+	if err := writer.finish(); err != nil {
 		return err
 	}
 	return file.Close()
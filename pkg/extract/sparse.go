@@ -0,0 +1,55 @@
+package extract
+
+import (
+	"bytes"
+	"os"
+)
+
+// sparseBlockSize is the size of the blocks of zeros which are not written
+const sparseBlockSize = 4096
+
+var zeroBlock = make([]byte, sparseBlockSize)
+
+// sparseWriter writes to file without writing the blocks of zeros, so that the
+// disk images of the bundles, which are mostly empty, are extracted as sparse
+// files on the filesystems which support them. The file must be empty.
+type sparseWriter struct {
+	file   *os.File
+	offset int64
+}
+
+func (w *sparseWriter) Write(data []byte) (int, error) {
+	written := 0
+	for written < len(data) {
+		end := written + sparseBlockSize
+		if end > len(data) {
+			end = len(data)
+		}
+		block := data[written:end]
+		if !bytes.Equal(block, zeroBlock[:len(block)]) {
+			// write the following blocks which are not empty at once
+			for end < len(data) {
+				next := end + sparseBlockSize
+				if next > len(data) {
+					next = len(data)
+				}
+				if bytes.Equal(data[end:next], zeroBlock[:next-end]) {
+					break
+				}
+				end = next
+			}
+			if _, err := w.file.WriteAt(data[written:end], w.offset); err != nil {
+				return written, err
+			}
+		}
+		w.offset += int64(end - written)
+		written = end
+	}
+	return written, nil
+}
+
+// finish sets the size of the file, it is smaller than the data written when
+// the data ends with zeros
+func (w *sparseWriter) finish() error {
+	return w.file.Truncate(w.offset)
+}
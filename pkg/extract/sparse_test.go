@@ -0,0 +1,37 @@
+package extract
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSparseWriter(t *testing.T) {
+	var data []byte
+	data = append(data, make([]byte, 3*sparseBlockSize)...)
+	data = append(data, bytes.Repeat([]byte("crc"), 5000)...)
+	data = append(data, make([]byte, sparseBlockSize+10)...)
+	data = append(data, 'x')
+	data = append(data, make([]byte, 2*sparseBlockSize)...)
+
+	path := filepath.Join(t.TempDir(), "disk.img")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	writer := &sparseWriter{file: file}
+	// io.Copy writes in chunks which are not aligned on the blocks
+	_, err = io.CopyBuffer(writer, struct{ io.Reader }{bytes.NewReader(data)}, make([]byte, 10000))
+	require.NoError(t, err)
+	require.NoError(t, writer.finish())
+	require.NoError(t, file.Close())
+
+	written, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, len(data), len(written))
+	assert.True(t, bytes.Equal(data, written))
+}
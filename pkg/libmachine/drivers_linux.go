@@ -0,0 +1,21 @@
+package libmachine
+
+import (
+	"encoding/json"
+
+	"github.com/code-ready/crc/pkg/drivers/qemu"
+	"github.com/code-ready/machine/libmachine/drivers"
+)
+
+// newInProcessDriver returns the drivers which run in the crc process instead
+// of a machine driver plugin, it returns nil for the other drivers
+func newInProcessDriver(driverName string, rawDriver []byte) (drivers.Driver, error) {
+	if driverName != qemu.DriverName {
+		return nil, nil
+	}
+	driver := qemu.NewDriver("", "")
+	if err := json.Unmarshal(rawDriver, &driver); err != nil {
+		return nil, err
+	}
+	return driver, nil
+}
@@ -5,10 +5,19 @@ package libmachine
 
 import (
 	"github.com/code-ready/crc/pkg/libmachine/host"
+	"github.com/code-ready/machine/libmachine/drivers"
 )
 
+func (api *Client) newDriver(driverName string, driverPath string, rawDriver []byte) (drivers.Driver, error) {
+	driver, err := newInProcessDriver(driverName, rawDriver)
+	if err != nil || driver != nil {
+		return driver, err
+	}
+	return api.clientDriverFactory.NewRPCClientDriver(driverName, driverPath, rawDriver)
+}
+
 func (api *Client) NewHost(driverName string, driverPath string, rawDriver []byte) (*host.Host, error) {
-	driver, err := api.clientDriverFactory.NewRPCClientDriver(driverName, driverPath, rawDriver)
+	driver, err := api.newDriver(driverName, driverPath, rawDriver)
 	if err != nil {
 		return nil, err
 	}
@@ -29,7 +38,7 @@ func (api *Client) Load(name string) (*host.Host, error) {
 		return nil, err
 	}
 
-	d, err := api.clientDriverFactory.NewRPCClientDriver(h.DriverName, h.DriverPath, h.RawDriver)
+	d, err := api.newDriver(h.DriverName, h.DriverPath, h.RawDriver)
 	if err != nil {
 		return nil, err
 	}
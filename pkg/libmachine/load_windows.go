@@ -4,12 +4,23 @@ import (
 	"encoding/json"
 
 	"github.com/code-ready/crc/pkg/drivers/hyperv"
+	"github.com/code-ready/crc/pkg/drivers/wsl"
 	"github.com/code-ready/crc/pkg/libmachine/host"
+	"github.com/code-ready/machine/libmachine/drivers"
 )
 
-func (api *Client) NewHost(driverName string, driverPath string, rawDriver []byte) (*host.Host, error) {
+func newDriver(driverName string, rawDriver []byte) (drivers.Driver, error) {
+	if driverName == wsl.DriverName {
+		driver := wsl.NewDriver("", "")
+		return driver, json.Unmarshal(rawDriver, &driver)
+	}
 	driver := hyperv.NewDriver("", "")
-	if err := json.Unmarshal(rawDriver, &driver); err != nil {
+	return driver, json.Unmarshal(rawDriver, &driver)
+}
+
+func (api *Client) NewHost(driverName string, driverPath string, rawDriver []byte) (*host.Host, error) {
+	driver, err := newDriver(driverName, rawDriver)
+	if err != nil {
 		return nil, err
 	}
 
@@ -29,8 +40,8 @@ func (api *Client) Load(name string) (*host.Host, error) {
 		return nil, err
 	}
 
-	driver := hyperv.NewDriver("", "")
-	if err := json.Unmarshal(h.RawDriver, &driver); err != nil {
+	driver, err := newDriver(h.DriverName, h.RawDriver)
+	if err != nil {
 		return nil, err
 	}
 	h.Driver = driver
@@ -2,12 +2,13 @@ package persist
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 
 	log "github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/statefile"
 	"github.com/code-ready/crc/pkg/libmachine/host"
 )
 
@@ -21,47 +22,49 @@ func NewFilestore(path string) *Filestore {
 	}
 }
 
-func (s Filestore) saveToFile(data []byte, file string) error {
-	if _, err := os.Stat(file); os.IsNotExist(err) {
-		return ioutil.WriteFile(file, data, 0600)
-	}
+// HostSchema is the schema of the config.json files of the machines. They
+// are the host itself, like in the previous releases, which can still read
+// them, and their version is the ConfigVersion of the host.
+var HostSchema = statefile.Schema{
+	Kind:     "host",
+	Validate: validateHost,
+	Inline:   true,
+}
 
-	tmpfi, err := ioutil.TempFile(filepath.Dir(file), "config.json.tmp")
-	if err != nil {
+func validateHost(data []byte) error {
+	var metadata host.Metadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
 		return err
 	}
-	defer os.Remove(tmpfi.Name())
-
-	if err = ioutil.WriteFile(tmpfi.Name(), data, 0600); err != nil {
-		return err
+	if metadata.ConfigVersion > host.Version {
+		return &statefile.NewerVersionError{Version: metadata.ConfigVersion, CurrentVersion: host.Version}
 	}
-
-	if err = tmpfi.Close(); err != nil {
+	h, err := host.MigrateHost("", data)
+	if err != nil {
 		return err
 	}
-
-	if err = os.Remove(file); err != nil {
-		return err
+	switch {
+	case h.Name == "":
+		return errors.New("the host has no name")
+	case h.DriverName == "":
+		return errors.New("the host has no driver name")
+	case len(h.RawDriver) == 0 || string(h.RawDriver) == "null":
+		return errors.New("the host has no driver configuration")
 	}
+	return nil
+}
 
-	err = os.Rename(tmpfi.Name(), file)
-	return err
+// HostPath returns the path of the config.json file of the machine name
+func (s Filestore) HostPath(name string) string {
+	return filepath.Join(s.MachinesDir, name, "config.json")
 }
 
 func (s Filestore) Save(host *host.Host) error {
-	data, err := json.MarshalIndent(host, "", "    ")
+	data, err := json.Marshal(host)
 	if err != nil {
 		return err
 	}
-
-	hostPath := filepath.Join(s.MachinesDir, host.Name)
-
-	// Ensure that the directory we want to save to exists.
-	if err := os.MkdirAll(hostPath, 0700); err != nil {
-		return err
-	}
-
-	return s.saveToFile(data, filepath.Join(hostPath, "config.json"))
+	return statefile.Save(s.HostPath(host.Name), HostSchema, data)
 }
 
 func (s Filestore) Remove(name string) error {
@@ -81,6 +84,15 @@ func (s Filestore) SetExists(name string) error {
 	return nil
 }
 
+// UnsetExists marks the machine as not existing, without removing its files
+func (s Filestore) UnsetExists(name string) error {
+	filename := filepath.Join(s.MachinesDir, name, fmt.Sprintf(".%s-exist", name))
+	if err := os.Remove(filename); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
 func (s Filestore) Exists(name string) (bool, error) {
 	filename := filepath.Join(s.MachinesDir, name, fmt.Sprintf(".%s-exist", name))
 	_, err := os.Stat(filename)
@@ -101,7 +113,7 @@ func (s Filestore) Load(name string) (*host.Host, error) {
 	if _, err := os.Stat(hostPath); os.IsNotExist(err) {
 		return nil, fmt.Errorf("machine %s does not exist", name)
 	}
-	data, err := ioutil.ReadFile(filepath.Join(s.MachinesDir, name, "config.json"))
+	data, err := statefile.Load(s.HostPath(name), HostSchema)
 	if err != nil {
 		return nil, err
 	}
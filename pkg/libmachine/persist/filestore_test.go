@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/code-ready/crc/pkg/crc/statefile"
 	"github.com/code-ready/crc/pkg/drivers/none"
 	"github.com/code-ready/crc/pkg/libmachine/host"
 	"github.com/stretchr/testify/assert"
@@ -122,6 +123,67 @@ func TestStoreLoad(t *testing.T) {
 	assert.NoError(t, json.Unmarshal(rawDataDriver.Data, &realDriver))
 }
 
+func TestStoreLoadUnversionedConfig(t *testing.T) {
+	store, cleanup, err := getTestStore()
+	assert.NoError(t, err)
+	defer cleanup()
+
+	hostPath := filepath.Join(store.MachinesDir, "crc")
+	assert.NoError(t, os.MkdirAll(hostPath, 0700))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(hostPath, "config.json"), []byte(`{
+    "ConfigVersion": 3,
+    "Driver": {"MachineName": "crc"},
+    "DriverName": "libvirt",
+    "Name": "crc"
+}`), 0600))
+
+	h, err := store.Load("crc")
+	assert.NoError(t, err)
+	assert.Equal(t, "libvirt", h.DriverName)
+
+	configData, err := ioutil.ReadFile(filepath.Join(hostPath, "config.json"))
+	assert.NoError(t, err)
+	document := make(map[string]interface{})
+	assert.NoError(t, json.Unmarshal(configData, &document))
+	assert.Equal(t, "crc", document["Name"])
+	assert.NotContains(t, document, "kind")
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(hostPath, "config.json"), []byte(`{"ConfigVersion": 3, "Name": "crc"}`), 0600))
+	_, err = store.Load("crc")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "the host has no driver name")
+}
+
+func TestStoreSaveKeepsTheHostFormat(t *testing.T) {
+	store, cleanup, err := getTestStore()
+	assert.NoError(t, err)
+	defer cleanup()
+
+	h := testHost()
+	assert.NoError(t, store.Save(h))
+	configData, err := ioutil.ReadFile(store.HostPath(h.Name))
+	assert.NoError(t, err)
+	// the previous releases read config.json with host.MigrateHost
+	loaded, err := host.MigrateHost(h.Name, configData)
+	assert.NoError(t, err)
+	assert.Equal(t, h.DriverName, loaded.DriverName)
+}
+
+func TestStoreLoadNewerConfig(t *testing.T) {
+	store, cleanup, err := getTestStore()
+	assert.NoError(t, err)
+	defer cleanup()
+
+	hostPath := filepath.Join(store.MachinesDir, "crc")
+	assert.NoError(t, os.MkdirAll(hostPath, 0700))
+	assert.NoError(t, ioutil.WriteFile(store.HostPath("crc"), []byte(`{"ConfigVersion": 4, "Driver": {}, "DriverName": "libvirt", "Name": "crc"}`), 0600))
+
+	_, err = store.Load("crc")
+	var newer *statefile.NewerVersionError
+	assert.ErrorAs(t, err, &newer)
+	assert.EqualError(t, err, store.HostPath("crc")+" has the version 4, which is newer than the version 3 of this crc release")
+}
+
 func testHost() *host.Host {
 	return &host.Host{
 		ConfigVersion: host.Version,
@@ -0,0 +1,102 @@
+//go:build linux
+// +build linux
+
+package linux
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const pciDevicesDir = "/sys/bus/pci/devices"
+
+// PCIDevice is a device of the PCI bus, as described in sysfs
+type PCIDevice struct {
+	Address    string
+	Vendor     string
+	Class      string
+	Driver     string
+	IOMMUGroup string
+}
+
+// IsGPU returns true for display controllers
+func (d PCIDevice) IsGPU() bool {
+	return strings.HasPrefix(d.Class, "0x03")
+}
+
+// PassthroughGPUs returns the GPUs bound to the vfio-pci driver, with the
+// other devices of their IOMMU groups which are also bound to vfio-pci, like
+// the audio function of the graphics card. They can be assigned to a virtual
+// machine.
+func PassthroughGPUs() ([]PCIDevice, error) {
+	return passthroughGPUs(pciDevicesDir)
+}
+
+func passthroughGPUs(devicesDir string) ([]PCIDevice, error) {
+	devices, err := pciDevices(devicesDir)
+	if err != nil {
+		return nil, err
+	}
+	groups := make(map[string]bool)
+	for _, device := range devices {
+		if device.IsGPU() && device.Driver == "vfio-pci" && device.IOMMUGroup != "" {
+			groups[device.IOMMUGroup] = true
+		}
+	}
+	var passthrough []PCIDevice
+	for _, device := range devices {
+		if groups[device.IOMMUGroup] && device.Driver == "vfio-pci" {
+			passthrough = append(passthrough, device)
+		}
+	}
+	return passthrough, nil
+}
+
+// IOMMUEnabled returns true when the kernel created IOMMU groups, which
+// requires the IOMMU to be enabled in the firmware and on the kernel command
+// line
+func IOMMUEnabled() bool {
+	groups, err := ioutil.ReadDir("/sys/kernel/iommu_groups")
+	return err == nil && len(groups) > 0
+}
+
+func pciDevices(devicesDir string) ([]PCIDevice, error) {
+	entries, err := ioutil.ReadDir(devicesDir)
+	if err != nil {
+		return nil, err
+	}
+	var devices []PCIDevice
+	for _, entry := range entries {
+		dir := filepath.Join(devicesDir, entry.Name())
+		devices = append(devices, PCIDevice{
+			Address:    entry.Name(),
+			Vendor:     readSysfsValue(filepath.Join(dir, "vendor")),
+			Class:      readSysfsValue(filepath.Join(dir, "class")),
+			Driver:     linkName(filepath.Join(dir, "driver")),
+			IOMMUGroup: linkName(filepath.Join(dir, "iommu_group")),
+		})
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].Address < devices[j].Address
+	})
+	return devices, nil
+}
+
+func readSysfsValue(path string) string {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+func linkName(path string) string {
+	target, err := os.Readlink(path)
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(target)
+}
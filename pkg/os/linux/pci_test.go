@@ -0,0 +1,42 @@
+//go:build linux
+// +build linux
+
+package linux
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addPCIDevice(t *testing.T, devicesDir, address, vendor, class, driver, group string) {
+	dir := filepath.Join(devicesDir, address)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "vendor"), []byte(vendor+"\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "class"), []byte(class+"\n"), 0600))
+	if driver != "" {
+		require.NoError(t, os.Symlink("../../../bus/pci/drivers/"+driver, filepath.Join(dir, "driver")))
+	}
+	require.NoError(t, os.Symlink("../../../kernel/iommu_groups/"+group, filepath.Join(dir, "iommu_group")))
+}
+
+func TestPassthroughGPUs(t *testing.T) {
+	devicesDir := t.TempDir()
+	// integrated GPU used by the host
+	addPCIDevice(t, devicesDir, "0000:00:02.0", "0x8086", "0x030000", "i915", "0")
+	// discrete GPU and its audio function, bound to vfio-pci
+	addPCIDevice(t, devicesDir, "0000:01:00.0", "0x10de", "0x030000", "vfio-pci", "12")
+	addPCIDevice(t, devicesDir, "0000:01:00.1", "0x10de", "0x040300", "vfio-pci", "12")
+	// vfio-pci device which is not a GPU
+	addPCIDevice(t, devicesDir, "0000:03:00.0", "0x8086", "0x020000", "vfio-pci", "14")
+
+	gpus, err := passthroughGPUs(devicesDir)
+	require.NoError(t, err)
+	assert.Equal(t, []PCIDevice{
+		{Address: "0000:01:00.0", Vendor: "0x10de", Class: "0x030000", Driver: "vfio-pci", IOMMUGroup: "12"},
+		{Address: "0000:01:00.1", Vendor: "0x10de", Class: "0x040300", Driver: "vfio-pci", IOMMUGroup: "12"},
+	}, gpus)
+}
@@ -2,6 +2,8 @@ package shell
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -12,6 +14,12 @@ type Config struct {
 	PathSuffix string
 }
 
+// SupportedShells returns the shells for which the environment can be
+// generated
+func SupportedShells() []string {
+	return supportedShell
+}
+
 func GetShell(userShell string) (string, error) {
 	if userShell != "" {
 		if !isSupportedShell(userShell) {
@@ -53,6 +61,14 @@ func GenerateUsageHint(userShell, cmdLine string) string {
 		return fmt.Sprintf("& %s | Invoke-Expression", cmdLine)
 	case "cmd":
 		return fmt.Sprintf("@FOR /f \"tokens=*\" %%i IN ('%s') DO @call %%i", cmdLine)
+	case "tcsh":
+		return fmt.Sprintf("eval `%s`", cmdLine)
+	case "xonsh":
+		return fmt.Sprintf("execx($(%s))", cmdLine)
+	case "nushell":
+		// nushell cannot evaluate a script at runtime, the NAME=value lines
+		// it gets are loaded in its environment
+		return fmt.Sprintf("%s | lines | where $it !~ '^#' | parse '{name}={value}' | transpose --header-row --as-record | load-env", cmdLine)
 	default:
 		return fmt.Sprintf("eval $(%s)", cmdLine)
 	}
@@ -65,17 +81,50 @@ func GetEnvString(userShell string, envName string, envValue string) string {
 	case "cmd":
 		return fmt.Sprintf("SET %s=%s", envName, envValue)
 	case "fish":
-		return fmt.Sprintf("contains %s $fish_user_paths; or set -U fish_user_paths %s $fish_user_paths", envValue, envValue)
+		return fmt.Sprintf("set -gx %s \"%s\";", envName, envValue)
+	case "tcsh":
+		return fmt.Sprintf("setenv %s \"%s\";", envName, envValue)
+	case "xonsh":
+		return fmt.Sprintf("$%s = \"%s\"", envName, envValue)
+	case "nushell":
+		return fmt.Sprintf("%s=%s", envName, envValue)
 	default:
 		return fmt.Sprintf("export %s=\"%s\"", envName, envValue)
 	}
 }
 
+// GetUnsetEnvString returns the command removing envName from the
+// environment of userShell
+func GetUnsetEnvString(userShell string, envName string) string {
+	switch userShell {
+	case "powershell":
+		return fmt.Sprintf("Remove-Item Env:\\%s -ErrorAction SilentlyContinue", envName)
+	case "cmd":
+		return fmt.Sprintf("SET %s=", envName)
+	case "fish":
+		return fmt.Sprintf("set -e %s;", envName)
+	case "tcsh":
+		return fmt.Sprintf("unsetenv %s;", envName)
+	case "xonsh":
+		return fmt.Sprintf("del $%s", envName)
+	case "nushell":
+		// load-env cannot remove a variable, an empty value is ignored by
+		// the programs using it
+		return fmt.Sprintf("%s=", envName)
+	default:
+		return fmt.Sprintf("unset %s", envName)
+	}
+}
+
 func GetPathEnvString(userShell string, prependedPath string) string {
 	var pathStr string
 	switch userShell {
 	case "fish":
-		pathStr = prependedPath
+		return fmt.Sprintf("contains %s $fish_user_paths; or set -U fish_user_paths %s $fish_user_paths;", prependedPath, prependedPath)
+	case "xonsh":
+		return fmt.Sprintf("$PATH.insert(0, '%s')", prependedPath)
+	case "nushell":
+		pathStr = strings.Join(append([]string{prependedPath}, filepath.SplitList(os.Getenv("PATH"))...), string(os.PathListSeparator))
 	case "powershell":
 		pathStr = fmt.Sprintf("%s;$Env:PATH", prependedPath)
 	case "cmd":
@@ -86,3 +135,30 @@ func GetPathEnvString(userShell string, prependedPath string) string {
 
 	return GetEnvString(userShell, "PATH", pathStr)
 }
+
+// GetUnsetPathEnvString returns the command removing removedPath from the
+// PATH of userShell
+func GetUnsetPathEnvString(userShell string, removedPath string) string {
+	switch userShell {
+	case "fish":
+		return fmt.Sprintf("set -U fish_user_paths (string match -v '%s' $fish_user_paths);", removedPath)
+	case "xonsh":
+		return fmt.Sprintf("$PATH.remove('%s') if '%s' in $PATH else None", removedPath, removedPath)
+	case "nushell":
+		var paths []string
+		for _, path := range filepath.SplitList(os.Getenv("PATH")) {
+			if path != removedPath {
+				paths = append(paths, path)
+			}
+		}
+		return GetEnvString(userShell, "PATH", strings.Join(paths, string(os.PathListSeparator)))
+	case "powershell":
+		return fmt.Sprintf("$Env:PATH = ($Env:PATH -split ';' | Where-Object { $_ -ne '%s' }) -join ';'", removedPath)
+	case "cmd":
+		return fmt.Sprintf("SET PATH=%%PATH:%s;=%%", removedPath)
+	case "tcsh":
+		return fmt.Sprintf("setenv PATH `echo \"$PATH\" | tr ':' '\\n' | grep -vxF '%s' | paste -s -d : -`;", removedPath)
+	default:
+		return fmt.Sprintf("export PATH=\"$(echo \"$PATH\" | tr ':' '\\n' | grep -vxF '%s' | paste -s -d : -)\"", removedPath)
+	}
+}
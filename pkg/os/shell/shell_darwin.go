@@ -1,5 +1,5 @@
 package shell
 
 var (
-	supportedShell = []string{"bash", "zsh", "fish"}
+	supportedShell = []string{"bash", "zsh", "fish", "tcsh", "nushell", "xonsh"}
 )
@@ -29,3 +29,44 @@ func TestDetectFish(t *testing.T) {
 	assert.Equal(t, "fish", shell)
 	assert.NoError(t, err)
 }
+
+func TestDetectNushell(t *testing.T) {
+	defer func(version string) { os.Setenv("NU_VERSION", version) }(os.Getenv("NU_VERSION"))
+	os.Setenv("NU_VERSION", "0.80.0")
+
+	shell, err := detect()
+
+	assert.Equal(t, "nushell", shell)
+	assert.NoError(t, err)
+}
+
+func TestGetEnvString(t *testing.T) {
+	assert.Equal(t, `export HTTP_PROXY="http://proxy:3128"`, GetEnvString("bash", "HTTP_PROXY", "http://proxy:3128"))
+	assert.Equal(t, `set -gx HTTP_PROXY "http://proxy:3128";`, GetEnvString("fish", "HTTP_PROXY", "http://proxy:3128"))
+	assert.Equal(t, `setenv HTTP_PROXY "http://proxy:3128";`, GetEnvString("tcsh", "HTTP_PROXY", "http://proxy:3128"))
+	assert.Equal(t, `$HTTP_PROXY = "http://proxy:3128"`, GetEnvString("xonsh", "HTTP_PROXY", "http://proxy:3128"))
+	assert.Equal(t, `HTTP_PROXY=http://proxy:3128`, GetEnvString("nushell", "HTTP_PROXY", "http://proxy:3128"))
+}
+
+func TestGetUnsetEnvString(t *testing.T) {
+	assert.Equal(t, "unset HTTP_PROXY", GetUnsetEnvString("zsh", "HTTP_PROXY"))
+	assert.Equal(t, "set -e HTTP_PROXY;", GetUnsetEnvString("fish", "HTTP_PROXY"))
+	assert.Equal(t, "unsetenv HTTP_PROXY;", GetUnsetEnvString("tcsh", "HTTP_PROXY"))
+	assert.Equal(t, "del $HTTP_PROXY", GetUnsetEnvString("xonsh", "HTTP_PROXY"))
+	assert.Equal(t, `Remove-Item Env:\HTTP_PROXY -ErrorAction SilentlyContinue`, GetUnsetEnvString("powershell", "HTTP_PROXY"))
+	assert.Equal(t, "SET HTTP_PROXY=", GetUnsetEnvString("cmd", "HTTP_PROXY"))
+}
+
+func TestGetPathEnvStringNushell(t *testing.T) {
+	defer func(path string) { os.Setenv("PATH", path) }(os.Getenv("PATH"))
+	os.Setenv("PATH", "/usr/bin:/home/user/.crc/bin/oc:/bin")
+
+	assert.Equal(t, "PATH=/home/user/.crc/bin/oc:/usr/bin:/home/user/.crc/bin/oc:/bin", GetPathEnvString("nushell", "/home/user/.crc/bin/oc"))
+	assert.Equal(t, "PATH=/usr/bin:/bin", GetUnsetPathEnvString("nushell", "/home/user/.crc/bin/oc"))
+}
+
+func TestGenerateUsageHint(t *testing.T) {
+	assert.Equal(t, "eval `crc oc-env`", GenerateUsageHint("tcsh", "crc oc-env"))
+	assert.Equal(t, "execx($(crc oc-env))", GenerateUsageHint("xonsh", "crc oc-env"))
+	assert.Equal(t, "eval $(crc oc-env --unset)", GenerateUsageHint("bash", "crc oc-env --unset"))
+}
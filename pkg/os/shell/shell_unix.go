@@ -16,6 +16,14 @@ var (
 
 // detect detects user's current shell.
 func detect() (string, error) {
+	// nushell and xonsh are rarely the login shell, so $SHELL is not theirs
+	if os.Getenv("NU_VERSION") != "" {
+		return "nushell", nil
+	}
+	if os.Getenv("XONSH_VERSION") != "" {
+		return "xonsh", nil
+	}
+
 	shell := os.Getenv("SHELL")
 
 	if shell == "" {
@@ -23,5 +31,12 @@ func detect() (string, error) {
 		return "", ErrUnknownShell
 	}
 
-	return filepath.Base(shell), nil
+	switch name := filepath.Base(shell); name {
+	case "nu":
+		return "nushell", nil
+	case "csh":
+		return "tcsh", nil
+	default:
+		return name, nil
+	}
 }
@@ -0,0 +1,89 @@
+package wsl
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+)
+
+// Execute runs wsl.exe with args. Its own messages are printed in UTF-16
+// unless WSL_UTF8 is set, which older versions ignore, so NUL bytes are
+// dropped from the output.
+func Execute(args ...string) (string, string, error) {
+	logging.Debugf("Running 'wsl.exe %s'", strings.Join(args, " "))
+
+	wsl, err := exec.LookPath("wsl.exe")
+	if err != nil {
+		return "", "", err
+	}
+	cmd := exec.Command(wsl, args...) // #nosec G204
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	cmd.Env = append(os.Environ(), "WSL_UTF8=1")
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	if err != nil {
+		logging.Debugf("Command failed: %v", err)
+		logging.Debugf("stdout: %s", decode(stdout.Bytes()))
+		logging.Debugf("stderr: %s", decode(stderr.Bytes()))
+	}
+	return decode(stdout.Bytes()), decode(stderr.Bytes()), err
+}
+
+func decode(output []byte) string {
+	output = bytes.TrimPrefix(output, []byte{0xff, 0xfe})
+	return string(bytes.ReplaceAll(output, []byte{0}, nil))
+}
+
+// Distributions returns the names of the registered distributions, or of
+// the running ones
+func Distributions(running bool) ([]string, error) {
+	args := []string{"--list", "--quiet"}
+	if running {
+		args = append(args, "--running")
+	}
+	stdout, _, err := Execute(args...)
+	if err != nil {
+		// wsl.exe exits with an error when no distribution is registered
+		// or running
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return parseDistributions(stdout), nil
+}
+
+func parseDistributions(output string) []string {
+	var distributions []string
+	for _, line := range strings.Split(output, "\n") {
+		if name := strings.TrimSpace(line); name != "" {
+			distributions = append(distributions, name)
+		}
+	}
+	return distributions
+}
+
+// IsRegistered checks if the distribution is imported in WSL
+func IsRegistered(name string) (bool, error) {
+	distributions, err := Distributions(false)
+	if err != nil {
+		return false, err
+	}
+	for _, distribution := range distributions {
+		if strings.EqualFold(distribution, name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,17 @@
+package wsl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	assert.Equal(t, "crc\r\nUbuntu\r\n", decode([]byte{0xff, 0xfe, 'c', 0, 'r', 0, 'c', 0, '\r', 0, '\n', 0, 'U', 0, 'b', 0, 'u', 0, 'n', 0, 't', 0, 'u', 0, '\r', 0, '\n', 0}))
+	assert.Equal(t, "crc\n", decode([]byte("crc\n")))
+}
+
+func TestParseDistributions(t *testing.T) {
+	assert.Equal(t, []string{"crc", "Ubuntu"}, parseDistributions("crc\r\nUbuntu\r\n\r\n"))
+	assert.Empty(t, parseDistributions(""))
+}